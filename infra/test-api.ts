@@ -1,6 +1,7 @@
 import { api } from "./api";
 import { table } from "./storage";
-import { ouraClientId, ouraClientSecret } from "./secrets";
+import { web } from "./web";
+import { apiToken, ouraClientId, ouraClientSecret, nightscoutUrl, nightscoutApiSecret } from "./secrets";
 
 // HTTP API for test endpoints
 // Domain is configured via SIGNAGE_DOMAIN environment variable
@@ -13,12 +14,27 @@ export const testApi = new sst.aws.ApiGatewayV2("SignageTestApi", {
         ? `api.signage.${baseDomain}`
         : `api.${$app.stage}.signage.${baseDomain}`,
   }),
+  // Browsers only from the web emulator; devices and the CLIs don't need CORS
   cors: {
-    allowOrigins: ["*"],
-    allowMethods: ["GET", "POST"],
+    allowOrigins: [web.url],
+    allowMethods: ["GET", "POST", "DELETE"],
+    allowHeaders: ["Authorization", "Content-Type"],
   },
 });
 
+// Bearer token check for routes with health data or that change the display
+const tokenAuthorizer = testApi.addAuthorizer({
+  name: "ApiToken",
+  lambda: {
+    function: {
+      handler: "packages/functions/src/auth/authorizer.handler",
+      link: [apiToken],
+    },
+    identitySources: ["$request.header.Authorization"],
+  },
+});
+const tokenAuth = { auth: { lambda: tokenAuthorizer.id } };
+
 // Test bitmap endpoint
 testApi.route("GET /test-bitmap", {
   handler: "packages/functions/src/test-bitmap.handler",
@@ -28,11 +44,40 @@ testApi.route("GET /test-bitmap", {
   },
 });
 
-// Health check
+// Health check - storage, glucose freshness, Dexcom login, devices
 testApi.route("GET /health", {
-  handler: "packages/functions/src/health.handler",
+  handler: "packages/functions/src/health/api.handler",
+  link: [table],
+});
+
+// Status summary - devices, last frame, glucose, alerts, source freshness
+testApi.route("GET /status", {
+  handler: "packages/functions/src/status/api.handler",
+  link: [table],
+});
+
+// Per-device send success rate and latency
+testApi.route("GET /devices", {
+  handler: "packages/functions/src/devices/api.handler",
+  link: [table],
+});
+
+// Per-device minimum interval between frames
+testApi.route("POST /devices", {
+  handler: "packages/functions/src/devices/api.handler",
+  link: [table],
 });
 
+// Push a picture to one device for a while (PNG or raw RGB body)
+testApi.route(
+  "POST /devices/{id}/frame",
+  {
+    handler: "packages/functions/src/devices/frame-api.handler",
+    link: [table, api],
+  },
+  tokenAuth
+);
+
 // News digest endpoint - AI-powered news with web grounding
 testApi.route("GET /news-digest", {
   handler: "packages/functions/src/news-digest.handler",
@@ -75,3 +120,196 @@ testApi.route("GET /oura/auth/callback", {
   link: [table, ouraClientId, ouraClientSecret],
   timeout: "30 seconds",
 });
+
+// Display layout - get or switch the active layout and schedule
+testApi.route("GET /layout", {
+  handler: "packages/functions/src/display/layout-api.handler",
+  link: [table],
+});
+
+testApi.route("POST /layout", {
+  handler: "packages/functions/src/display/layout-api.handler",
+  link: [table],
+});
+
+// Display lock - freeze the current frame for a photo or demo
+testApi.route("GET /lock", {
+  handler: "packages/functions/src/display/lock-api.handler",
+  link: [table],
+});
+
+testApi.route(
+  "POST /lock",
+  {
+    handler: "packages/functions/src/display/lock-api.handler",
+    link: [table],
+  },
+  tokenAuth
+);
+
+testApi.route(
+  "DELETE /lock",
+  {
+    handler: "packages/functions/src/display/lock-api.handler",
+    link: [table],
+  },
+  tokenAuth
+);
+
+// Pomodoro timer - focus/break countdown over the insight rows or the whole display
+testApi.route("GET /pomodoro", {
+  handler: "packages/functions/src/pomodoro/api.handler",
+  link: [table],
+});
+
+testApi.route("POST /pomodoro", {
+  handler: "packages/functions/src/pomodoro/api.handler",
+  link: [table],
+});
+
+testApi.route("DELETE /pomodoro", {
+  handler: "packages/functions/src/pomodoro/api.handler",
+  link: [table],
+});
+
+// Notifications - queued messages over the insight rows for a few seconds
+testApi.route("GET /notify", {
+  handler: "packages/functions/src/notifications/api.handler",
+  link: [table],
+});
+
+testApi.route("POST /notify", {
+  handler: "packages/functions/src/notifications/api.handler",
+  link: [table],
+});
+
+testApi.route("DELETE /notify", {
+  handler: "packages/functions/src/notifications/api.handler",
+  link: [table],
+});
+
+// Text banner - a templated status line filled in from pushed values
+testApi.route("GET /banner", {
+  handler: "packages/functions/src/banner/api.handler",
+  link: [table],
+});
+
+testApi.route("POST /banner", {
+  handler: "packages/functions/src/banner/api.handler",
+  link: [table],
+});
+
+testApi.route("DELETE /banner", {
+  handler: "packages/functions/src/banner/api.handler",
+  link: [table],
+});
+
+// History aggregates - hourly/daily min, max and average for long-range charts
+testApi.route("GET /history/aggregates", {
+  handler: "packages/functions/src/widgets/aggregates-api.handler",
+  link: [table],
+});
+
+// Export - stored readings as CSV or JSON
+testApi.route(
+  "GET /export",
+  {
+    handler: "packages/functions/src/export/api.handler",
+    link: [table],
+  },
+  tokenAuth
+);
+
+// Import - glucose readings from Dexcom Clarity or Nightscout exports
+testApi.route(
+  "POST /import",
+  {
+    handler: "packages/functions/src/import/api.handler",
+    link: [table],
+    timeout: "30 seconds",
+  },
+  tokenAuth
+);
+
+// Widget config - turn widgets on or off and change their settings
+testApi.route("GET /widgets", {
+  handler: "packages/functions/src/widgets/config-api.handler",
+  link: [table],
+});
+
+testApi.route("POST /widgets", {
+  handler: "packages/functions/src/widgets/config-api.handler",
+  link: [table],
+});
+
+// Event log - alerts, device connections, settings changes and buzzer sounds
+testApi.route("GET /events", {
+  handler: "packages/functions/src/events/api.handler",
+  link: [table],
+});
+
+// Annotations - named notes on the glucose timeline (sensor change, travel day)
+testApi.route("GET /annotations", {
+  handler: "packages/functions/src/annotations/api.handler",
+  link: [table],
+});
+
+testApi.route("POST /annotations", {
+  handler: "packages/functions/src/annotations/api.handler",
+  link: [table],
+});
+
+testApi.route("DELETE /annotations", {
+  handler: "packages/functions/src/annotations/api.handler",
+  link: [table],
+});
+
+// Manual treatments - quick carb/insulin entry, optionally forwarded to Nightscout
+testApi.route(
+  "GET /treatments",
+  {
+    handler: "packages/functions/src/treatments/api.handler",
+    link: [table, nightscoutUrl, nightscoutApiSecret],
+  },
+  tokenAuth
+);
+
+testApi.route(
+  "POST /treatments",
+  {
+    handler: "packages/functions/src/treatments/api.handler",
+    link: [table, nightscoutUrl, nightscoutApiSecret],
+    timeout: "10 seconds",
+  },
+  tokenAuth
+);
+
+// Alert rules - quiet hours and which alerts are currently suppressed
+testApi.route("GET /alerts", {
+  handler: "packages/functions/src/alerts/api.handler",
+  link: [table],
+});
+
+testApi.route("POST /alerts", {
+  handler: "packages/functions/src/alerts/api.handler",
+  link: [table],
+});
+
+// Alert acknowledgment - snooze showing alerts until glucose gets worse
+testApi.route(
+  "POST /alerts/ack",
+  {
+    handler: "packages/functions/src/alerts/acknowledgment-api.handler",
+    link: [table],
+  },
+  tokenAuth
+);
+
+testApi.route(
+  "DELETE /alerts/ack",
+  {
+    handler: "packages/functions/src/alerts/acknowledgment-api.handler",
+    link: [table],
+  },
+  tokenAuth
+);
@@ -8,10 +8,17 @@ import { api } from "./api";
 import {
   dexcomUsername,
   dexcomPassword,
+  dexcomFollowers,
   ouraClientId,
   ouraClientSecret,
   glookoEmail,
   glookoPassword,
+  nightscoutUrl,
+  nightscoutApiSecret,
+  mqttUrl,
+  spotifyClientId,
+  spotifyClientSecret,
+  spotifyRefreshToken,
 } from "./secrets";
 
 // Display compositor - combines all widgets into a single frame
@@ -20,7 +27,17 @@ export const compositorCron = new sst.aws.Cron("DisplayCompositor", {
   schedule: "rate(1 minute)",
   function: {
     handler: "packages/functions/src/compositor.scheduled",
-    link: [table, api, dexcomUsername, dexcomPassword],
+    link: [
+      table,
+      api,
+      dexcomUsername,
+      dexcomPassword,
+      dexcomFollowers,
+      mqttUrl,
+      spotifyClientId,
+      spotifyClientSecret,
+      spotifyRefreshToken,
+    ],
     timeout: "30 seconds",
     memory: "256 MB",
   },
@@ -66,6 +83,29 @@ export const glookoScraperCron = new sst.aws.Cron("GlookoScraper", {
   },
 });
 
+// Repair gaps in stored glucose history daily at 3 AM Pacific (11:00 UTC)
+// Re-fetches from Dexcom (last 24h) or Nightscout (older, when configured)
+export const glucoseBackfillCron = new sst.aws.Cron("GlucoseBackfillRepair", {
+  schedule: "cron(0 11 * * ? *)",
+  function: {
+    handler: "packages/functions/src/backfill/repair.scheduled",
+    link: [table, dexcomUsername, dexcomPassword, nightscoutUrl, nightscoutApiSecret],
+    timeout: "120 seconds",
+    memory: "256 MB",
+  },
+});
+
+// Run deferred jobs (Nightscout write-back retries, backfill retries) every 5 minutes
+export const jobQueueCron = new sst.aws.Cron("JobQueueWorker", {
+  schedule: "rate(5 minutes)",
+  function: {
+    handler: "packages/functions/src/jobs/worker.scheduled",
+    link: [table, dexcomUsername, dexcomPassword, nightscoutUrl, nightscoutApiSecret],
+    timeout: "120 seconds",
+    memory: "256 MB",
+  },
+});
+
 // Check Lightsail instance health hourly and auto-reboot if status checks fail
 // Only provisioned in prod — the relay is a single shared instance
 export const lightsailHealthCheckCron =
@@ -0,0 +1,61 @@
+import { describe, it, expect, vi } from "vitest";
+import { createAwtrixCustomApp, createAwtrixDisplay, createAwtrixMqttMessage } from "./awtrix";
+import { createSolidFrame, setPixel } from "./pixoo";
+
+function setup(status = 200) {
+  const fetch = vi.fn(async (_url: string, _init: RequestInit) => ({ ok: status < 400, status }) as Response);
+  const display = createAwtrixDisplay("192.168.1.60", { fetch: fetch as unknown as typeof globalThis.fetch });
+  return { display, fetch };
+}
+
+describe("createAwtrixCustomApp", () => {
+  it("draws the frame as one RGB888 bitmap", () => {
+    const frame = createSolidFrame(2, 1);
+    setPixel(frame, 1, 0, { r: 0x12, g: 0x34, b: 0x56 });
+
+    expect(createAwtrixCustomApp(frame)).toEqual({ draw: [{ db: [0, 0, 2, 1, [0, 0x123456]] }] });
+  });
+
+  it("includes the duration when given", () => {
+    expect(createAwtrixCustomApp(createSolidFrame(1, 1), { duration: 10 })).toMatchObject({ duration: 10 });
+  });
+});
+
+describe("createAwtrixMqttMessage", () => {
+  it("publishes to the custom app topic, resized to the clock", () => {
+    const message = createAwtrixMqttMessage(createSolidFrame(64, 64), { prefix: "clock", app: "bg" });
+
+    expect(message.topic).toBe("clock/custom/bg");
+    expect(JSON.parse(message.payload).draw[0].db.slice(0, 4)).toEqual([0, 0, 32, 8]);
+  });
+});
+
+describe("createAwtrixDisplay", () => {
+  it("posts frames to the custom app endpoint at the clock's size", async () => {
+    const { display, fetch } = setup();
+
+    await display.sendFrame(createSolidFrame(64, 64));
+
+    const [url, init] = fetch.mock.calls[0];
+    expect(url).toBe("http://192.168.1.60/api/custom?name=signage");
+    const { draw } = JSON.parse(String(init.body));
+    expect(draw[0].db.slice(0, 4)).toEqual([0, 0, 32, 8]);
+    expect(draw[0].db[4]).toHaveLength(32 * 8);
+  });
+
+  it("sets a fixed brightness on the 0-255 scale", async () => {
+    const { display, fetch } = setup();
+
+    await display.setBrightness(50);
+
+    const [url, init] = fetch.mock.calls[0];
+    expect(url).toBe("http://192.168.1.60/api/settings");
+    expect(JSON.parse(String(init.body))).toEqual({ ABRI: false, BRI: 128 });
+  });
+
+  it("fails on an HTTP error", async () => {
+    const { display } = setup(500);
+
+    await expect(display.sendFrame(createSolidFrame(32, 8))).rejects.toThrow("HTTP 500");
+  });
+});
@@ -0,0 +1,99 @@
+/**
+ * AWTRIX 3 driver
+ *
+ * AWTRIX 3 is the open firmware for 32x8 clocks such as the Ulanzi TC001.
+ * A frame is shown as a custom app: one bitmap drawn over the whole
+ * matrix, which the clock rotates with its own apps (or stays on, when
+ * it is the only one). The same payload works over HTTP and MQTT.
+ *
+ * Frames are resized like any other display, but a 64x64 frame squeezed
+ * to 32x8 is unreadable; the compositor renders a compact layout for the
+ * "ulanzi" model instead.
+ */
+
+import type { Frame } from "./types.js";
+import { DISPLAY_MODELS, fitFrame, type Display } from "./display.js";
+
+/** Custom app name used when none is given */
+export const AWTRIX_DEFAULT_APP = "signage";
+
+/** A custom app update, as posted to /api/custom or published over MQTT */
+export interface AwtrixCustomApp {
+  /** Draw instructions; `db` is [x, y, width, height, RGB888 pixels] */
+  draw: Array<{ db: [number, number, number, number, number[]] }>;
+  /** Seconds the app stays on screen each rotation */
+  duration?: number;
+}
+
+export interface AwtrixDisplayOptions {
+  /** Custom app name (default: "signage") */
+  app?: string;
+  /** Seconds on screen each rotation (default: the clock's setting) */
+  duration?: number;
+  /** Per-request timeout (default: 5000) */
+  timeoutMs?: number;
+  /** Injected for tests */
+  fetch?: typeof fetch;
+}
+
+/**
+ * Build the custom app payload that draws a frame
+ */
+export function createAwtrixCustomApp(frame: Frame, options: { duration?: number } = {}): AwtrixCustomApp {
+  const colors: number[] = [];
+  for (let i = 0; i < frame.pixels.length; i += 3) {
+    colors.push((frame.pixels[i] << 16) | (frame.pixels[i + 1] << 8) | frame.pixels[i + 2]);
+  }
+  return {
+    draw: [{ db: [0, 0, frame.width, frame.height, colors] }],
+    ...(options.duration !== undefined && { duration: options.duration }),
+  };
+}
+
+/**
+ * Topic and payload for sending a frame over MQTT, for setups where the
+ * clock is only reachable through a broker. `prefix` is the MQTT prefix
+ * set on the clock (default: "awtrix").
+ */
+export function createAwtrixMqttMessage(
+  frame: Frame,
+  options: { prefix?: string; app?: string; duration?: number } = {}
+): { topic: string; payload: string } {
+  const { prefix = "awtrix", app = AWTRIX_DEFAULT_APP, duration } = options;
+  return {
+    topic: `${prefix}/custom/${app}`,
+    payload: JSON.stringify(createAwtrixCustomApp(fitFrame(frame, DISPLAY_MODELS.ulanzi), { duration })),
+  };
+}
+
+/**
+ * Create a display for the AWTRIX clock at `host` (e.g. "192.168.1.60"),
+ * using its HTTP API
+ */
+export function createAwtrixDisplay(host: string, options: AwtrixDisplayOptions = {}): Display {
+  const { app = AWTRIX_DEFAULT_APP, duration, timeoutMs = 5000, fetch: send = fetch } = options;
+  const size = DISPLAY_MODELS.ulanzi;
+
+  async function post(path: string, body: unknown): Promise<void> {
+    const response = await send(`http://${host}${path}`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body),
+      signal: AbortSignal.timeout(timeoutMs),
+    });
+    if (!response.ok) {
+      throw new Error(`AWTRIX ${path} failed: HTTP ${response.status}`);
+    }
+  }
+
+  return {
+    size,
+    sendFrame: (frame) =>
+      post(`/api/custom?name=${encodeURIComponent(app)}`, createAwtrixCustomApp(fitFrame(frame, size), { duration })),
+    async setBrightness(percent) {
+      // The clock takes 0-255, and ignores it while automatic brightness is on
+      const brightness = Math.round((Math.max(0, Math.min(100, percent)) / 100) * 255);
+      await post("/api/settings", { ABRI: false, BRI: brightness });
+    },
+  };
+}
@@ -0,0 +1,178 @@
+import { describe, it, expect, vi } from "vitest";
+import { createPixooClient, pixooRetryDelayMs, PixooUnavailableError, type PixooClientOptions } from "./client";
+import { PixooError, createSolidFrame } from "./pixoo";
+
+function reply(body: string, status = 200) {
+  return { ok: status < 400, status, text: async () => body } as Response;
+}
+
+const OK = reply('{"error_code":0}');
+
+function setup(responses: Array<Response | Error>, options: PixooClientOptions = {}) {
+  let time = 0;
+  const fetch = vi.fn(async (_url: string, _init: RequestInit) => {
+    const next = responses.length > 1 ? responses.shift() : responses[0];
+    if (next instanceof Error) throw next;
+    return next as Response;
+  });
+  const sleep = vi.fn(async (ms: number) => {
+    time += ms;
+  });
+  const onHealthChange = vi.fn();
+  const client = createPixooClient("192.168.1.50", {
+    fetch: fetch as unknown as typeof globalThis.fetch,
+    sleep,
+    random: () => 0,
+    now: () => time,
+    onHealthChange,
+    ...options,
+  });
+  return { client, fetch, sleep, onHealthChange, advance: (ms: number) => (time += ms) };
+}
+
+const command = { Command: "Channel/SetBrightness", Brightness: 50 };
+
+describe("pixooRetryDelayMs", () => {
+  it("doubles per retry with jitter between half and the full delay", () => {
+    expect(pixooRetryDelayMs(0, 250, 0)).toBe(125);
+    expect(pixooRetryDelayMs(2, 250, 0)).toBe(500);
+    expect(pixooRetryDelayMs(2, 250, 0.999)).toBeLessThan(1000);
+  });
+});
+
+describe("createPixooClient", () => {
+  it("posts the command to the device", async () => {
+    const { client, fetch } = setup([OK]);
+
+    await expect(client.sendCommand(command)).resolves.toEqual({ error_code: 0 });
+    expect(fetch).toHaveBeenCalledWith(
+      "http://192.168.1.50/post",
+      expect.objectContaining({ method: "POST", body: JSON.stringify(command) })
+    );
+  });
+
+  it("retries network failures with backoff", async () => {
+    const { client, fetch, sleep } = setup([new TypeError("fetch failed"), reply("", 503), OK]);
+
+    await expect(client.sendCommand(command)).resolves.toEqual({ error_code: 0 });
+    expect(fetch).toHaveBeenCalledTimes(3);
+    expect(sleep.mock.calls.map(([ms]) => ms)).toEqual([125, 250]);
+  });
+
+  it("doesn't retry a command the device refused", async () => {
+    const { client, fetch } = setup([reply('{"error_code":1}')]);
+
+    await expect(client.sendCommand(command)).rejects.toBeInstanceOf(PixooError);
+    expect(fetch).toHaveBeenCalledTimes(1);
+    expect(client.health()).toBe("healthy");
+  });
+
+  it("goes unhealthy after consecutive failures and fails fast", async () => {
+    const { client, fetch, onHealthChange } = setup([new TypeError("fetch failed")], {
+      retries: 0,
+      failureThreshold: 3,
+    });
+
+    for (let i = 0; i < 3; i++) {
+      await expect(client.sendCommand(command)).rejects.toThrow("fetch failed");
+    }
+    await expect(client.sendCommand(command)).rejects.toBeInstanceOf(PixooUnavailableError);
+
+    expect(fetch).toHaveBeenCalledTimes(3);
+    expect(client.health()).toBe("unhealthy");
+    expect(onHealthChange).toHaveBeenCalledTimes(1);
+    expect(onHealthChange).toHaveBeenCalledWith("unhealthy", expect.any(TypeError));
+  });
+
+  it("tries once after the cooldown and recovers", async () => {
+    const responses: Array<Response | Error> = [new TypeError("fetch failed"), new TypeError("fetch failed"), OK];
+    const { client, fetch, onHealthChange, advance } = setup(responses, {
+      retries: 1,
+      failureThreshold: 1,
+      cooldownMs: 30_000,
+    });
+
+    await expect(client.sendCommand(command)).rejects.toThrow();
+    expect(fetch).toHaveBeenCalledTimes(2);
+    advance(30_000);
+
+    await expect(client.sendCommand(command)).resolves.toEqual({ error_code: 0 });
+    expect(fetch).toHaveBeenCalledTimes(3);
+    expect(client.health()).toBe("healthy");
+    expect(onHealthChange).toHaveBeenLastCalledWith("healthy");
+  });
+
+  it("reopens without retrying when the attempt after the cooldown fails", async () => {
+    const { client, fetch, onHealthChange, advance } = setup([new TypeError("fetch failed")], {
+      failureThreshold: 1,
+      retries: 0,
+    });
+
+    await expect(client.sendCommand(command)).rejects.toThrow();
+    advance(30_000);
+    await expect(client.sendCommand(command)).rejects.toThrow("fetch failed");
+    await expect(client.sendCommand(command)).rejects.toBeInstanceOf(PixooUnavailableError);
+
+    expect(fetch).toHaveBeenCalledTimes(2);
+    expect(onHealthChange).toHaveBeenCalledTimes(1);
+  });
+
+  describe("sendFrame", () => {
+    const frame = createSolidFrame(64, 64);
+    const sent = (fetch: ReturnType<typeof vi.fn>) =>
+      fetch.mock.calls.map(([, init]) => {
+        const label = (c: { Command: string; PicID?: number }) => (c.PicID ? `${c.Command}#${c.PicID}` : c.Command);
+        const body = JSON.parse((init as { body: string }).body);
+        return body.CommandList ? body.CommandList.map(label) : label(body);
+      });
+
+    const SETUP = ["Channel/SetIndex", "Draw/ResetHttpGifId", "Draw/SendHttpGif#1"];
+
+    it("sends the setup with the first frame in one request, then frames alone", async () => {
+      const { client, fetch } = setup([OK]);
+
+      await client.sendFrame(frame);
+      await client.sendFrame(frame);
+
+      expect(sent(fetch)).toEqual([SETUP, "Draw/SendHttpGif#2"]);
+    });
+
+    it("sets up again after a failed frame", async () => {
+      const { client, fetch } = setup([OK, reply('{"error_code":1}'), OK], { retries: 0 });
+
+      await client.sendFrame(frame);
+      await expect(client.sendFrame(frame)).rejects.toBeInstanceOf(PixooError);
+      await client.sendFrame(frame);
+
+      expect(sent(fetch)).toEqual([SETUP, "Draw/SendHttpGif#2", SETUP]);
+    });
+
+    it("resets the PicID sequence every resetEvery frames", async () => {
+      const { client, fetch } = setup([OK], { resetEvery: 2 });
+
+      for (let i = 0; i < 3; i++) await client.sendFrame(frame);
+
+      expect(sent(fetch)).toEqual([SETUP, "Draw/SendHttpGif#2", SETUP]);
+    });
+  });
+
+  it("sends a command list in one request", async () => {
+    const { client, fetch } = setup([OK]);
+
+    await client.sendCommandList([command, { Command: "Channel/OnOffScreen", OnOff: 1 }]);
+
+    expect(fetch).toHaveBeenCalledTimes(1);
+    expect(JSON.parse((fetch.mock.calls[0][1] as { body: string }).body)).toEqual({
+      Command: "Draw/CommandList",
+      CommandList: [command, { Command: "Channel/OnOffScreen", OnOff: 1 }],
+    });
+  });
+
+  it("asks the device to close the connection when keep-alive is off", async () => {
+    const { client, fetch } = setup([OK], { keepAlive: false });
+
+    await client.sendCommand(command);
+
+    expect(fetch.mock.calls[0][1]).toMatchObject({ headers: { Connection: "close" } });
+  });
+});
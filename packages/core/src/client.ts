@@ -0,0 +1,215 @@
+/**
+ * Pixoo HTTP client
+ *
+ * Sends commands to a device's local `/post` endpoint. Network failures
+ * (timeouts, refused connections, 5xx) are retried with jittered
+ * exponential backoff; a device that keeps failing trips a circuit
+ * breaker, and commands fail fast until a cooldown passes. That way a
+ * WiFi blip costs a retry, and a device that's unplugged costs one log
+ * line instead of one per frame.
+ *
+ * Frames need the device on its custom channel with a fresh PicID
+ * sequence. The client sends that setup together with the first frame in
+ * one Draw/CommandList, then sends frames alone, doing the setup again
+ * after a failure or every `resetEvery` frames.
+ */
+
+import {
+  createPixooCommandListCommand,
+  createPixooFrameCommand,
+  createPixooResetGifIdCommand,
+  createPixooSelectChannelCommand,
+  parsePixooResponse,
+  PixooError,
+  type PixooResponse,
+} from "./pixoo.js";
+import type { Frame } from "./types.js";
+
+/** Health of the device as the client sees it */
+export type PixooHealth = "healthy" | "unhealthy";
+
+export interface PixooClientOptions {
+  /** Retries per command after the first attempt (default: 2) */
+  retries?: number;
+  /** Delay before the first retry; doubles per retry, with jitter (default: 250) */
+  retryDelayMs?: number;
+  /** Per-attempt timeout (default: 5000) */
+  timeoutMs?: number;
+  /**
+   * Reuse the connection between commands (default: true). Turn off for
+   * firmware that drops idle connections badly. For finer pool tuning
+   * (e.g. max idle connections), pass a `fetch` bound to your own agent.
+   */
+  keepAlive?: boolean;
+  /**
+   * Frames sent before the PicID sequence is reset (default: 32). The
+   * device is known to stop updating when PicIDs climb too high.
+   */
+  resetEvery?: number;
+  /** Consecutive failed commands before the device is marked unhealthy (default: 5) */
+  failureThreshold?: number;
+  /** How long an unhealthy device is left alone before trying again (default: 30000) */
+  cooldownMs?: number;
+  /** Called when the device goes unhealthy or recovers */
+  onHealthChange?: (health: PixooHealth, error?: unknown) => void;
+  /** Injected for tests */
+  fetch?: typeof fetch;
+  sleep?: (ms: number) => Promise<void>;
+  random?: () => number;
+  now?: () => number;
+}
+
+/** A command refused without trying, because the device is unhealthy */
+export class PixooUnavailableError extends Error {
+  /** When the next attempt will be allowed */
+  retryAt: number;
+
+  constructor(host: string, retryAt: number) {
+    super(`Pixoo at ${host} is unavailable until ${new Date(retryAt).toISOString()}`);
+    this.name = "PixooUnavailableError";
+    this.retryAt = retryAt;
+  }
+}
+
+export interface PixooClient {
+  /** Send a command and return the parsed response */
+  sendCommand<T extends PixooResponse = PixooResponse>(command: { Command: string }): Promise<T>;
+  /** Send several commands in one request (Draw/CommandList) */
+  sendCommandList(commands: Array<{ Command: string }>): Promise<PixooResponse>;
+  /** Show a frame, selecting the channel and resetting PicIDs only when needed */
+  sendFrame(frame: Frame, options?: { speed?: number }): Promise<void>;
+  health(): PixooHealth;
+}
+
+/**
+ * Retry delay for the given retry (0-based): base * 2^retry, scaled by a
+ * random factor in [0.5, 1) so devices on one network don't retry in step
+ */
+export function pixooRetryDelayMs(retry: number, baseMs: number, random: number = Math.random()): number {
+  return Math.round(baseMs * 2 ** retry * (0.5 + random / 2));
+}
+
+/**
+ * Whether an error is worth retrying. A PixooError means the device
+ * answered and refused; asking again won't change its mind.
+ */
+function isTransient(error: unknown): boolean {
+  return !(error instanceof PixooError);
+}
+
+/**
+ * Create a client for the device at `host` (e.g. "192.168.1.50")
+ */
+export function createPixooClient(host: string, options: PixooClientOptions = {}): PixooClient {
+  const {
+    retries = 2,
+    retryDelayMs = 250,
+    timeoutMs = 5000,
+    keepAlive = true,
+    resetEvery = 32,
+    failureThreshold = 5,
+    cooldownMs = 30_000,
+    onHealthChange,
+    fetch: send = fetch,
+    sleep = (ms: number) => new Promise<void>((resolve) => setTimeout(resolve, ms)),
+    random = Math.random,
+    now = Date.now,
+  } = options;
+  const url = `http://${host}/post`;
+
+  let consecutiveFailures = 0;
+  let openUntil = 0;
+  /** Last PicID sent; 0 when the device needs the channel and PicID setup */
+  let picId = 0;
+
+  async function attempt<T extends PixooResponse>(command: { Command: string }): Promise<T> {
+    const response = await send(url, {
+      method: "POST",
+      headers: keepAlive ? undefined : { Connection: "close" },
+      body: JSON.stringify(command),
+      signal: AbortSignal.timeout(timeoutMs),
+    });
+    const body = await response.text();
+    if (!response.ok) {
+      throw new Error(`Pixoo ${command.Command} failed: HTTP ${response.status}`);
+    }
+    return parsePixooResponse<T>(command.Command, body);
+  }
+
+  function recordFailure(error: unknown): void {
+    consecutiveFailures += 1;
+    if (consecutiveFailures < failureThreshold) return;
+    const wasHealthy = openUntil === 0;
+    openUntil = now() + cooldownMs;
+    if (wasHealthy) onHealthChange?.("unhealthy", error);
+  }
+
+  function recordSuccess(): void {
+    const wasUnhealthy = openUntil !== 0;
+    consecutiveFailures = 0;
+    openUntil = 0;
+    if (wasUnhealthy) onHealthChange?.("healthy");
+  }
+
+  async function sendCommand<T extends PixooResponse = PixooResponse>(command: { Command: string }): Promise<T> {
+    if (openUntil > now()) {
+      throw new PixooUnavailableError(host, openUntil);
+    }
+    // Past the cooldown a single attempt decides: one more failure
+    // reopens the breaker straight away
+    const attempts = openUntil === 0 ? retries + 1 : 1;
+
+    for (let i = 0; ; i++) {
+      try {
+        const result = await attempt<T>(command);
+        recordSuccess();
+        return result;
+      } catch (error) {
+        if (!isTransient(error)) {
+          // The device is up and answering
+          recordSuccess();
+          throw error;
+        }
+        if (i + 1 >= attempts) {
+          recordFailure(error);
+          throw error;
+        }
+        await sleep(pixooRetryDelayMs(i, retryDelayMs, random()));
+      }
+    }
+  }
+
+  return {
+    sendCommand,
+
+    sendCommandList(commands) {
+      return sendCommand(createPixooCommandListCommand(commands));
+    },
+
+    async sendFrame(frame, { speed } = {}) {
+      try {
+        if (picId === 0 || picId >= resetEvery) {
+          picId = 0;
+          await sendCommand(
+            createPixooCommandListCommand([
+              createPixooSelectChannelCommand(),
+              createPixooResetGifIdCommand(),
+              createPixooFrameCommand(frame, { picId: 1, speed }),
+            ])
+          );
+        } else {
+          await sendCommand(createPixooFrameCommand(frame, { picId: picId + 1, speed }));
+        }
+        picId += 1;
+      } catch (error) {
+        // The device may have rebooted onto another channel
+        picId = 0;
+        throw error;
+      }
+    },
+
+    health() {
+      return openUntil === 0 ? "healthy" : "unhealthy";
+    },
+  };
+}
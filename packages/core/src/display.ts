@@ -0,0 +1,66 @@
+/**
+ * Display abstraction
+ *
+ * Anything that can show frames: a Pixoo of any size, or another LED
+ * matrix such as an AWTRIX clock. Frames are composed at 64x64; a display resizes them to its own
+ * size, so the composer doesn't need to know what it is drawing for.
+ */
+
+import type { Frame } from "./types.js";
+import type { PixooClient } from "./client.js";
+import { resizeFrame } from "./frame.js";
+
+export interface DisplaySize {
+  width: number;
+  height: number;
+}
+
+/** Panel sizes of supported models */
+export const DISPLAY_MODELS = {
+  pixoo16: { width: 16, height: 16 },
+  pixooMax: { width: 32, height: 32 },
+  pixoo64: { width: 64, height: 64 },
+  // Ulanzi TC001 and other AWTRIX 3 clocks
+  ulanzi: { width: 32, height: 8 },
+} as const satisfies Record<string, DisplaySize>;
+
+export type DisplayModel = keyof typeof DISPLAY_MODELS;
+
+export interface Display {
+  readonly size: DisplaySize;
+  /** Show a frame, resizing it first if it isn't the display's size */
+  sendFrame(frame: Frame): Promise<void>;
+  /** 0-100 */
+  setBrightness(percent: number): Promise<void>;
+}
+
+/**
+ * Check a requested model name
+ */
+export function isDisplayModel(value: unknown): value is DisplayModel {
+  return typeof value === "string" && Object.hasOwn(DISPLAY_MODELS, value);
+}
+
+/**
+ * Resize a frame to a display size, leaving it alone when it already fits
+ */
+export function fitFrame(frame: Frame, size: DisplaySize): Frame {
+  return frame.width === size.width && frame.height === size.height
+    ? frame
+    : resizeFrame(frame, size.width, size.height);
+}
+
+/**
+ * Adapt a Pixoo client to the Display interface
+ */
+export function createPixooDisplay(client: PixooClient, model: DisplayModel = "pixoo64"): Display {
+  const size = DISPLAY_MODELS[model];
+  return {
+    size,
+    sendFrame: (frame) => client.sendFrame(fitFrame(frame, size)),
+    async setBrightness(percent) {
+      const brightness = Math.round(Math.max(0, Math.min(100, percent)));
+      await client.sendCommand({ Command: "Channel/SetBrightness", Brightness: brightness });
+    },
+  };
+}
@@ -0,0 +1,254 @@
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, setPixel, getPixel } from "./pixoo";
+import { subFrame, blitFrame, blendColors, blendPixel, fillRect, rotateFrame, resizeFrame, diffFrames } from "./frame";
+
+const RED = { r: 255, g: 0, b: 0 };
+const BLUE = { r: 0, g: 0, b: 255 };
+const BLACK = { r: 0, g: 0, b: 0 };
+
+/** 4x4 frame where each pixel encodes its own position */
+function numberedFrame() {
+  const frame = createSolidFrame(4, 4);
+  for (let y = 0; y < 4; y++) {
+    for (let x = 0; x < 4; x++) {
+      setPixel(frame, x, y, { r: x + 1, g: y + 1, b: 0 });
+    }
+  }
+  return frame;
+}
+
+describe("frame", () => {
+  describe("subFrame", () => {
+    it("copies an interior rectangle", () => {
+      const sub = subFrame(numberedFrame(), 1, 2, 2, 2);
+      expect(sub.width).toBe(2);
+      expect(sub.height).toBe(2);
+      expect(getPixel(sub, 0, 0)).toEqual({ r: 2, g: 3, b: 0 });
+      expect(getPixel(sub, 1, 1)).toEqual({ r: 3, g: 4, b: 0 });
+    });
+
+    it("leaves parts outside the source black", () => {
+      const sub = subFrame(numberedFrame(), -1, -1, 3, 3);
+      expect(getPixel(sub, 0, 0)).toEqual(BLACK);
+      expect(getPixel(sub, 2, 0)).toEqual(BLACK);
+      expect(getPixel(sub, 1, 1)).toEqual({ r: 1, g: 1, b: 0 });
+      expect(getPixel(sub, 2, 2)).toEqual({ r: 2, g: 2, b: 0 });
+    });
+
+    it("clips at the right and bottom edges", () => {
+      const sub = subFrame(numberedFrame(), 3, 3, 2, 2);
+      expect(getPixel(sub, 0, 0)).toEqual({ r: 4, g: 4, b: 0 });
+      expect(getPixel(sub, 1, 0)).toEqual(BLACK);
+      expect(getPixel(sub, 0, 1)).toEqual(BLACK);
+    });
+
+    it("returns an all-black frame for a rectangle fully outside", () => {
+      const sub = subFrame(numberedFrame(), 10, 10, 2, 2);
+      expect(sub.pixels.every((v) => v === 0)).toBe(true);
+    });
+
+    it("does not share pixel memory with the source", () => {
+      const source = numberedFrame();
+      const sub = subFrame(source, 0, 0, 2, 2);
+      setPixel(sub, 0, 0, RED);
+      expect(getPixel(source, 0, 0)).toEqual({ r: 1, g: 1, b: 0 });
+    });
+  });
+
+  describe("blitFrame", () => {
+    it("draws the source at the destination offset", () => {
+      const dst = createSolidFrame(4, 4);
+      blitFrame(dst, createSolidFrame(2, 2, RED), 1, 1);
+      expect(getPixel(dst, 0, 0)).toEqual(BLACK);
+      expect(getPixel(dst, 1, 1)).toEqual(RED);
+      expect(getPixel(dst, 2, 2)).toEqual(RED);
+      expect(getPixel(dst, 3, 3)).toEqual(BLACK);
+    });
+
+    it("clips at negative offsets", () => {
+      const dst = createSolidFrame(4, 4);
+      blitFrame(dst, numberedFrame(), -2, -3);
+      expect(getPixel(dst, 0, 0)).toEqual({ r: 3, g: 4, b: 0 });
+      expect(getPixel(dst, 1, 0)).toEqual({ r: 4, g: 4, b: 0 });
+      expect(getPixel(dst, 2, 0)).toEqual(BLACK);
+      expect(getPixel(dst, 0, 1)).toEqual(BLACK);
+    });
+
+    it("clips at the right and bottom edges", () => {
+      const dst = createSolidFrame(4, 4);
+      blitFrame(dst, createSolidFrame(3, 3, RED), 2, 3);
+      expect(getPixel(dst, 3, 3)).toEqual(RED);
+      expect(getPixel(dst, 2, 2)).toEqual(BLACK);
+      // Row wrapping would leak into the start of the next row
+      expect(getPixel(dst, 0, 3)).toEqual(BLACK);
+    });
+
+    it("ignores a source placed fully off-screen", () => {
+      const dst = createSolidFrame(4, 4);
+      blitFrame(dst, createSolidFrame(2, 2, RED), 4, 0);
+      blitFrame(dst, createSolidFrame(2, 2, RED), 0, -2);
+      expect(dst.pixels.every((v) => v === 0)).toBe(true);
+    });
+
+    it("skips the transparent color", () => {
+      const dst = createSolidFrame(2, 1, BLUE);
+      const src = createSolidFrame(2, 1);
+      setPixel(src, 1, 0, RED);
+      blitFrame(dst, src, 0, 0, { transparentColor: BLACK });
+      expect(getPixel(dst, 0, 0)).toEqual(BLUE);
+      expect(getPixel(dst, 1, 0)).toEqual(RED);
+    });
+  });
+
+  describe("blendColors", () => {
+    it("interpolates between base and color", () => {
+      expect(blendColors(BLACK, { r: 200, g: 100, b: 50 }, 0.5)).toEqual({ r: 100, g: 50, b: 25 });
+    });
+
+    it("clamps alpha to 0-1", () => {
+      expect(blendColors(BLACK, RED, 2)).toEqual(RED);
+      expect(blendColors(BLUE, RED, -1)).toEqual(BLUE);
+    });
+  });
+
+  describe("blendPixel", () => {
+    it("mixes into the existing pixel", () => {
+      const frame = createSolidFrame(2, 2, BLUE);
+      blendPixel(frame, 1, 1, RED, 0.25);
+      expect(getPixel(frame, 1, 1)).toEqual({ r: 64, g: 0, b: 191 });
+      expect(getPixel(frame, 0, 0)).toEqual(BLUE);
+    });
+
+    it("ignores out-of-bounds coordinates", () => {
+      const frame = createSolidFrame(2, 2);
+      blendPixel(frame, -1, 0, RED, 1);
+      blendPixel(frame, 0, 2, RED, 1);
+      expect(frame.pixels.every((v) => v === 0)).toBe(true);
+    });
+  });
+
+  describe("fillRect", () => {
+    it("fills a clipped rectangle at partial opacity", () => {
+      const frame = createSolidFrame(3, 3);
+      fillRect(frame, 1, -1, 5, 2, { r: 100, g: 100, b: 100 }, 0.5);
+      expect(getPixel(frame, 1, 0)).toEqual({ r: 50, g: 50, b: 50 });
+      expect(getPixel(frame, 2, 0)).toEqual({ r: 50, g: 50, b: 50 });
+      expect(getPixel(frame, 0, 0)).toEqual(BLACK);
+      expect(getPixel(frame, 1, 1)).toEqual(BLACK);
+    });
+  });
+
+  describe("blitFrame with alpha", () => {
+    it("blends the source over the destination", () => {
+      const dst = createSolidFrame(2, 1, { r: 0, g: 0, b: 200 });
+      blitFrame(dst, createSolidFrame(2, 1, { r: 200, g: 0, b: 0 }), 0, 0, { alpha: 0.5 });
+      expect(getPixel(dst, 0, 0)).toEqual({ r: 100, g: 0, b: 100 });
+    });
+
+    it("does nothing at zero alpha", () => {
+      const dst = createSolidFrame(2, 1, BLUE);
+      blitFrame(dst, createSolidFrame(2, 1, RED), 0, 0, { alpha: 0 });
+      expect(getPixel(dst, 1, 0)).toEqual(BLUE);
+    });
+
+    it("combines alpha with color-key transparency", () => {
+      const dst = createSolidFrame(2, 1, { r: 0, g: 0, b: 200 });
+      const src = createSolidFrame(2, 1);
+      setPixel(src, 1, 0, { r: 200, g: 0, b: 0 });
+      blitFrame(dst, src, 0, 0, { alpha: 0.5, transparentColor: BLACK });
+      expect(getPixel(dst, 0, 0)).toEqual({ r: 0, g: 0, b: 200 });
+      expect(getPixel(dst, 1, 0)).toEqual({ r: 100, g: 0, b: 100 });
+    });
+  });
+
+  describe("rotateFrame", () => {
+    /** 3x2 frame: top row red, black, black; bottom row all black */
+    const marked = () => {
+      const frame = createSolidFrame(3, 2);
+      setPixel(frame, 0, 0, RED);
+      return frame;
+    };
+
+    it("turns the top-left corner to the top-right at 90 degrees", () => {
+      const rotated = rotateFrame(marked(), 90);
+      expect(rotated.width).toBe(2);
+      expect(rotated.height).toBe(3);
+      expect(getPixel(rotated, 1, 0)).toEqual(RED);
+    });
+
+    it("moves the top-left corner to the bottom-right at 180 degrees", () => {
+      const rotated = rotateFrame(marked(), 180);
+      expect(rotated.width).toBe(3);
+      expect(getPixel(rotated, 2, 1)).toEqual(RED);
+    });
+
+    it("turns the top-left corner to the bottom-left at 270 degrees", () => {
+      const rotated = rotateFrame(marked(), 270);
+      expect(getPixel(rotated, 0, 2)).toEqual(RED);
+    });
+
+    it("returns to the original after four quarter turns", () => {
+      const frame = numberedFrame();
+      let rotated = frame;
+      for (let i = 0; i < 4; i++) rotated = rotateFrame(rotated, 90);
+      expect(rotated.pixels).toEqual(frame.pixels);
+    });
+
+    it("copies the frame at 0 degrees", () => {
+      const frame = marked();
+      const copy = rotateFrame(frame, 0);
+      expect(copy.pixels).toEqual(frame.pixels);
+      expect(copy.pixels).not.toBe(frame.pixels);
+    });
+  });
+
+  describe("resizeFrame", () => {
+    it("averages the pixels each target pixel covers when shrinking", () => {
+      const frame = createSolidFrame(4, 4);
+      setPixel(frame, 0, 0, RED);
+      const small = resizeFrame(frame, 2, 2);
+      expect(small.width).toBe(2);
+      expect(getPixel(small, 0, 0)).toEqual({ r: 64, g: 0, b: 0 });
+      expect(getPixel(small, 1, 1)).toEqual(BLACK);
+    });
+
+    it("repeats pixels when growing", () => {
+      const frame = createSolidFrame(2, 2);
+      setPixel(frame, 1, 0, BLUE);
+      const big = resizeFrame(frame, 4, 4);
+      expect(getPixel(big, 2, 0)).toEqual(BLUE);
+      expect(getPixel(big, 3, 1)).toEqual(BLUE);
+      expect(getPixel(big, 1, 0)).toEqual(BLACK);
+    });
+
+    it("resizes to a different aspect ratio", () => {
+      const resized = resizeFrame(numberedFrame(), 4, 1);
+      expect(resized.height).toBe(1);
+      expect(getPixel(resized, 3, 0)).toEqual({ r: 4, g: 3, b: 0 });
+    });
+
+    it("copies a frame that is already the right size", () => {
+      const frame = numberedFrame();
+      const copy = resizeFrame(frame, 4, 4);
+      expect(copy.pixels).toEqual(frame.pixels);
+      expect(copy.pixels).not.toBe(frame.pixels);
+    });
+  });
+
+  describe("diffFrames", () => {
+    it("returns null for identical frames", () => {
+      expect(diffFrames(numberedFrame(), numberedFrame())).toBeNull();
+    });
+
+    it("bounds every changed pixel", () => {
+      const changed = numberedFrame();
+      setPixel(changed, 1, 2, RED);
+      setPixel(changed, 2, 1, BLUE);
+      expect(diffFrames(numberedFrame(), changed)).toEqual({ x: 1, y: 1, width: 2, height: 2 });
+    });
+
+    it("covers the whole frame when the sizes differ", () => {
+      expect(diffFrames(createSolidFrame(2, 2), numberedFrame())).toEqual({ x: 0, y: 0, width: 4, height: 4 });
+    });
+  });
+});
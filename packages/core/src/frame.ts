@@ -0,0 +1,294 @@
+/**
+ * Frame copy and blending operations
+ *
+ * Building blocks for compositing: copy a rectangle out of a frame, draw one
+ * frame onto another, and draw with partial opacity. Everything clips to
+ * frame bounds, so callers can pass partially or fully off-screen rectangles.
+ *
+ * Alpha is a per-draw opacity from 0 (no change) to 1 (replace). Frames stay
+ * RGB; blending mixes the new color into what is already there.
+ */
+
+import type { Frame, RGB, Rotation } from "./types.js";
+import { BYTES_PER_PIXEL, createSolidFrame } from "./pixoo.js";
+
+/**
+ * Options for blitFrame
+ */
+export interface BlitOptions {
+  /** Source pixels of exactly this color are skipped (color-key transparency) */
+  transparentColor?: RGB;
+  /** Opacity of the source, 0-1 (default: 1) */
+  alpha?: number;
+}
+
+/**
+ * Rectangle on a frame
+ */
+export interface FrameRect {
+  x: number;
+  y: number;
+  width: number;
+  height: number;
+}
+
+/**
+ * Clamp an alpha value to 0-1 (NaN counts as 0)
+ */
+function clampAlpha(alpha: number): number {
+  return alpha > 0 ? Math.min(1, alpha) : 0;
+}
+
+/**
+ * Mix color over base at the given opacity.
+ */
+export function blendColors(base: RGB, color: RGB, alpha: number): RGB {
+  const a = clampAlpha(alpha);
+  return {
+    r: Math.round(base.r + (color.r - base.r) * a),
+    g: Math.round(base.g + (color.g - base.g) * a),
+    b: Math.round(base.b + (color.b - base.b) * a),
+  };
+}
+
+/**
+ * Blend a color into a single pixel at the given opacity.
+ * Out-of-bounds coordinates are ignored, like setPixel.
+ */
+export function blendPixel(
+  frame: Frame,
+  x: number,
+  y: number,
+  color: RGB,
+  alpha: number
+): void {
+  if (x < 0 || x >= frame.width || y < 0 || y >= frame.height) {
+    return;
+  }
+  const a = clampAlpha(alpha);
+  if (a === 0) return;
+  const offset = (y * frame.width + x) * BYTES_PER_PIXEL;
+  frame.pixels[offset] = Math.round(frame.pixels[offset] + (color.r - frame.pixels[offset]) * a);
+  frame.pixels[offset + 1] = Math.round(frame.pixels[offset + 1] + (color.g - frame.pixels[offset + 1]) * a);
+  frame.pixels[offset + 2] = Math.round(frame.pixels[offset + 2] + (color.b - frame.pixels[offset + 2]) * a);
+}
+
+/**
+ * Fill a rectangle, optionally semi-transparent (e.g. a dimmed backdrop
+ * behind overlay text).
+ */
+export function fillRect(
+  frame: Frame,
+  x: number,
+  y: number,
+  width: number,
+  height: number,
+  color: RGB,
+  alpha = 1
+): void {
+  const x0 = Math.max(0, x);
+  const y0 = Math.max(0, y);
+  const x1 = Math.min(frame.width, x + width);
+  const y1 = Math.min(frame.height, y + height);
+  for (let py = y0; py < y1; py++) {
+    for (let px = x0; px < x1; px++) {
+      blendPixel(frame, px, py, color, alpha);
+    }
+  }
+}
+
+/**
+ * Copy a rectangle of a frame into a new w x h frame.
+ * Parts of the rectangle outside the source are left black.
+ */
+export function subFrame(
+  frame: Frame,
+  x: number,
+  y: number,
+  width: number,
+  height: number
+): Frame {
+  const result = createSolidFrame(Math.max(0, width), Math.max(0, height));
+
+  const startX = Math.max(0, -x);
+  const endX = Math.min(width, frame.width - x);
+  if (endX <= startX) return result;
+
+  for (let row = Math.max(0, -y); row < height; row++) {
+    const sy = y + row;
+    if (sy >= frame.height) break;
+    const srcStart = (sy * frame.width + x + startX) * BYTES_PER_PIXEL;
+    const srcEnd = (sy * frame.width + x + endX) * BYTES_PER_PIXEL;
+    result.pixels.set(frame.pixels.subarray(srcStart, srcEnd), (row * width + startX) * BYTES_PER_PIXEL);
+  }
+
+  return result;
+}
+
+/**
+ * Draw src onto dst with its top-left corner at (dstX, dstY).
+ * Pixels falling outside dst are clipped. With alpha < 1 the source is
+ * blended over dst (e.g. for fades).
+ */
+export function blitFrame(
+  dst: Frame,
+  src: Frame,
+  dstX: number,
+  dstY: number,
+  options: BlitOptions = {}
+): void {
+  const { transparentColor } = options;
+  const alpha = clampAlpha(options.alpha ?? 1);
+  if (alpha === 0) return;
+
+  const startX = Math.max(0, -dstX);
+  const endX = Math.min(src.width, dst.width - dstX);
+  if (endX <= startX) return;
+
+  for (let row = Math.max(0, -dstY); row < src.height; row++) {
+    const ty = dstY + row;
+    if (ty >= dst.height) break;
+
+    const srcRow = row * src.width;
+    const dstRow = ty * dst.width + dstX;
+
+    if (!transparentColor && alpha === 1) {
+      // Opaque: copy the whole clipped row at once
+      dst.pixels.set(
+        src.pixels.subarray((srcRow + startX) * BYTES_PER_PIXEL, (srcRow + endX) * BYTES_PER_PIXEL),
+        (dstRow + startX) * BYTES_PER_PIXEL
+      );
+      continue;
+    }
+
+    for (let col = startX; col < endX; col++) {
+      const si = (srcRow + col) * BYTES_PER_PIXEL;
+      const r = src.pixels[si];
+      const g = src.pixels[si + 1];
+      const b = src.pixels[si + 2];
+      if (transparentColor && r === transparentColor.r && g === transparentColor.g && b === transparentColor.b) continue;
+      const di = (dstRow + col) * BYTES_PER_PIXEL;
+      if (alpha === 1) {
+        dst.pixels[di] = r;
+        dst.pixels[di + 1] = g;
+        dst.pixels[di + 2] = b;
+      } else {
+        dst.pixels[di] = Math.round(dst.pixels[di] + (r - dst.pixels[di]) * alpha);
+        dst.pixels[di + 1] = Math.round(dst.pixels[di + 1] + (g - dst.pixels[di + 1]) * alpha);
+        dst.pixels[di + 2] = Math.round(dst.pixels[di + 2] + (b - dst.pixels[di + 2]) * alpha);
+      }
+    }
+  }
+}
+
+/**
+ * Rotate a frame clockwise by a quarter-turn multiple, into a new frame.
+ * Used for panels mounted sideways or upside down.
+ */
+export function rotateFrame(frame: Frame, degrees: Rotation): Frame {
+  if (degrees === 0) {
+    return { width: frame.width, height: frame.height, pixels: frame.pixels.slice() };
+  }
+
+  const turned = degrees !== 180;
+  const result = createSolidFrame(turned ? frame.height : frame.width, turned ? frame.width : frame.height);
+
+  for (let y = 0; y < frame.height; y++) {
+    for (let x = 0; x < frame.width; x++) {
+      let tx: number;
+      let ty: number;
+      if (degrees === 90) {
+        tx = frame.height - 1 - y;
+        ty = x;
+      } else if (degrees === 180) {
+        tx = frame.width - 1 - x;
+        ty = frame.height - 1 - y;
+      } else {
+        tx = y;
+        ty = frame.width - 1 - x;
+      }
+      const si = (y * frame.width + x) * BYTES_PER_PIXEL;
+      const di = (ty * result.width + tx) * BYTES_PER_PIXEL;
+      result.pixels[di] = frame.pixels[si];
+      result.pixels[di + 1] = frame.pixels[si + 1];
+      result.pixels[di + 2] = frame.pixels[si + 2];
+    }
+  }
+
+  return result;
+}
+
+/**
+ * Resize a frame into a new one. Each target pixel averages the source
+ * pixels it covers, so shrinking a 64x64 frame for a 16x16 or 32x32 panel
+ * keeps thin lines visible as dimmer ones instead of dropping them;
+ * growing repeats pixels.
+ */
+export function resizeFrame(frame: Frame, width: number, height: number): Frame {
+  if (width === frame.width && height === frame.height) {
+    return { width, height, pixels: frame.pixels.slice() };
+  }
+
+  const result = createSolidFrame(width, height);
+  for (let y = 0; y < height; y++) {
+    const sy0 = Math.floor((y * frame.height) / height);
+    const sy1 = Math.max(sy0 + 1, Math.floor(((y + 1) * frame.height) / height));
+    for (let x = 0; x < width; x++) {
+      const sx0 = Math.floor((x * frame.width) / width);
+      const sx1 = Math.max(sx0 + 1, Math.floor(((x + 1) * frame.width) / width));
+      let r = 0;
+      let g = 0;
+      let b = 0;
+      for (let sy = sy0; sy < sy1; sy++) {
+        for (let sx = sx0; sx < sx1; sx++) {
+          const si = (sy * frame.width + sx) * BYTES_PER_PIXEL;
+          r += frame.pixels[si];
+          g += frame.pixels[si + 1];
+          b += frame.pixels[si + 2];
+        }
+      }
+      const count = (sy1 - sy0) * (sx1 - sx0);
+      const di = (y * width + x) * BYTES_PER_PIXEL;
+      result.pixels[di] = Math.round(r / count);
+      result.pixels[di + 1] = Math.round(g / count);
+      result.pixels[di + 2] = Math.round(b / count);
+    }
+  }
+
+  return result;
+}
+
+/**
+ * Smallest rectangle holding every pixel that differs between two frames
+ * of the same size, or null when they're identical. Sending just that
+ * rectangle is what keeps a ticking seconds display cheap.
+ */
+export function diffFrames(from: Frame, to: Frame): FrameRect | null {
+  if (from.width !== to.width || from.height !== to.height) {
+    return { x: 0, y: 0, width: to.width, height: to.height };
+  }
+
+  let minX = to.width;
+  let minY = to.height;
+  let maxX = -1;
+  let maxY = -1;
+  for (let y = 0; y < to.height; y++) {
+    for (let x = 0; x < to.width; x++) {
+      const i = (y * to.width + x) * BYTES_PER_PIXEL;
+      if (
+        from.pixels[i] === to.pixels[i] &&
+        from.pixels[i + 1] === to.pixels[i + 1] &&
+        from.pixels[i + 2] === to.pixels[i + 2]
+      ) {
+        continue;
+      }
+      minX = Math.min(minX, x);
+      maxX = Math.max(maxX, x);
+      minY = Math.min(minY, y);
+      maxY = y;
+    }
+  }
+
+  if (maxX < 0) return null;
+  return { x: minX, y: minY, width: maxX - minX + 1, height: maxY - minY + 1 };
+}
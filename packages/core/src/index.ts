@@ -2,3 +2,9 @@
 
 export * from "./types.js";
 export * from "./pixoo.js";
+export * from "./frame.js";
+export * from "./shapes.js";
+export * from "./transitions.js";
+export * from "./client.js";
+export * from "./display.js";
+export * from "./awtrix.js";
@@ -0,0 +1,22 @@
+/**
+ * Frame buffer benchmarks: `pnpm bench`
+ */
+
+import { bench, describe } from "vitest";
+import { createSolidFrame, encodeFrameToBase64, fillFrame } from "./pixoo";
+
+const frame = createSolidFrame(64, 64, { r: 12, g: 200, b: 80 });
+
+describe("frame buffers", () => {
+  bench("encodeFrameToBase64 (64x64)", () => {
+    encodeFrameToBase64(frame);
+  });
+
+  bench("createSolidFrame (64x64, black)", () => {
+    createSolidFrame(64, 64);
+  });
+
+  bench("fillFrame (64x64, color)", () => {
+    fillFrame(frame, { r: 12, g: 200, b: 80 });
+  });
+});
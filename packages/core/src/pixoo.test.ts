@@ -1,11 +1,25 @@
 import { describe, it, expect } from "vitest";
 import {
   createSolidFrame,
+  fillFrame,
   setPixel,
   getPixel,
+  getPixelValue,
+  packRgb,
   encodeFrameToBase64,
   decodeBase64ToPixels,
   PIXOO64_SIZE,
+  createPixooSetUtcCommand,
+  planClockSync,
+  createPixooTimezoneCommand,
+  pixooTimezoneValue,
+  parsePixooDeviceConfig,
+  createPixooRestoreCommands,
+  createPixooScreenCommand,
+  createPixooBuzzerCommand,
+  createPixooRotationCommand,
+  parsePixooResponse,
+  PixooError,
 } from "./pixoo";
 
 describe("pixoo", () => {
@@ -22,6 +36,22 @@ describe("pixoo", () => {
       expect(frame.pixels[0]).toBe(255);
       expect(frame.pixels[1]).toBe(128);
       expect(frame.pixels[2]).toBe(64);
+      expect(Array.from(frame.pixels.subarray(9))).toEqual([255, 128, 64]);
+    });
+  });
+
+  describe("fillFrame", () => {
+    it("refills every pixel of an existing frame", () => {
+      const frame = createSolidFrame(3, 3, { r: 255, g: 128, b: 64 });
+      setPixel(frame, 1, 1, { r: 1, g: 2, b: 3 });
+
+      fillFrame(frame, { r: 10, g: 20, b: 30 });
+
+      for (let i = 0; i < 9; i++) {
+        expect(getPixel(frame, i % 3, Math.floor(i / 3))).toEqual({ r: 10, g: 20, b: 30 });
+      }
+      fillFrame(frame, { r: 0, g: 0, b: 0 });
+      expect(frame.pixels.every((v) => v === 0)).toBe(true);
     });
   });
 
@@ -45,6 +75,15 @@ describe("pixoo", () => {
       expect(getPixel(frame, -1, 0)).toBeNull();
       expect(getPixel(frame, 100, 0)).toBeNull();
     });
+
+    it("reads a pixel as a packed value without allocating", () => {
+      const frame = createSolidFrame(10, 10, { r: 0, g: 0, b: 0 });
+      setPixel(frame, 5, 5, { r: 255, g: 128, b: 64 });
+      expect(getPixelValue(frame, 5, 5)).toBe(0xff8040);
+      expect(getPixelValue(frame, 5, 5)).toBe(packRgb({ r: 255, g: 128, b: 64 }));
+      expect(getPixelValue(frame, 0, 0)).toBe(0);
+      expect(getPixelValue(frame, 10, 0)).toBe(-1);
+    });
   });
 
   describe("encodeFrameToBase64/decodeBase64ToPixels", () => {
@@ -54,6 +93,12 @@ describe("pixoo", () => {
       const decoded = decodeBase64ToPixels(encoded, 4, 4);
       expect(decoded.pixels).toEqual(frame.pixels);
     });
+
+    it("encodes only the frame's own bytes when its pixels are a view", () => {
+      const backing = new Uint8Array([9, 9, 9, 1, 2, 3, 9, 9, 9]);
+      const frame = { width: 1, height: 1, pixels: backing.subarray(3, 6) };
+      expect(encodeFrameToBase64(frame)).toBe(Buffer.from([1, 2, 3]).toString("base64"));
+    });
   });
 
   describe("PIXOO64_SIZE", () => {
@@ -61,4 +106,134 @@ describe("pixoo", () => {
       expect(PIXOO64_SIZE).toBe(64);
     });
   });
+
+  describe("parsePixooResponse", () => {
+    it("returns a successful response", () => {
+      const response = parsePixooResponse("Device/GetDeviceTime", '{"error_code":0,"UTCTime":1792152000}');
+      expect(response).toEqual({ error_code: 0, UTCTime: 1792152000 });
+    });
+
+    it("throws a PixooError with the code and command for a device error", () => {
+      let error: unknown;
+      try {
+        parsePixooResponse("Channel/SetBrightness", '{"error_code":1}');
+      } catch (e) {
+        error = e;
+      }
+      expect(error).toBeInstanceOf(PixooError);
+      expect(error).toMatchObject({ command: "Channel/SetBrightness", code: 1 });
+    });
+
+    it("rejects a body that isn't a Pixoo response", () => {
+      expect(() => parsePixooResponse("Sys/TimeZone", "<html>")).toThrow("invalid JSON");
+      expect(() => parsePixooResponse("Sys/TimeZone", "{}")).toThrow("without error_code");
+      expect(() => parsePixooResponse("Sys/TimeZone", "null")).toThrow("without error_code");
+    });
+  });
+
+  describe("clock sync", () => {
+    const now = Date.UTC(2026, 9, 16, 12, 0, 0, 400);
+
+    it("sets the device clock in whole seconds", () => {
+      expect(createPixooSetUtcCommand(now)).toEqual({ Command: "Device/SetUTC", Utc: 1792152000 });
+    });
+
+    it("leaves a clock within the threshold alone", () => {
+      const plan = planClockSync({ error_code: 0, UTCTime: 1792152002 }, now);
+      expect(plan).toEqual({ driftMs: 1600, command: null });
+    });
+
+    it("corrects a clock that has drifted too far", () => {
+      const plan = planClockSync({ error_code: 0, UTCTime: 1792151990 }, now);
+      expect(plan?.driftMs).toBe(-10400);
+      expect(plan?.command).toEqual({ Command: "Device/SetUTC", Utc: 1792152000 });
+    });
+
+    it("ignores error responses", () => {
+      expect(planClockSync({ error_code: 1, UTCTime: 0 }, now)).toBeNull();
+    });
+
+    it("sets the timezone as the current offset", () => {
+      expect(createPixooTimezoneCommand("America/Los_Angeles", now)).toEqual({
+        Command: "Sys/TimeZone",
+        TimeZoneValue: "GMT-7",
+      });
+      expect(pixooTimezoneValue("America/Los_Angeles", Date.UTC(2026, 0, 15))).toBe("GMT-8");
+    });
+
+    it("keeps half-hour offsets and writes UTC as +0", () => {
+      expect(pixooTimezoneValue("Asia/Kolkata", now)).toBe("GMT+5:30");
+      expect(pixooTimezoneValue("UTC", now)).toBe("GMT+0");
+    });
+
+    it("rejects an unknown timezone", () => {
+      expect(() => createPixooTimezoneCommand("Mars/Olympus_Mons", now)).toThrow(RangeError);
+    });
+  });
+
+  describe("device config", () => {
+    const response = {
+      error_code: 0,
+      Brightness: 80,
+      RotationFlag: 1,
+      ClockTime: 60,
+      GyrateAngle: 2,
+      TemperatureMode: 1,
+      LightSwitch: 1,
+      MirrorFlag: 0,
+      Time24Flag: 1,
+    };
+
+    it("reads the settings from GetAllConf", () => {
+      expect(parsePixooDeviceConfig(response)).toEqual({
+        brightness: 80,
+        rotation: 180,
+        temperatureUnit: "fahrenheit",
+        screenOn: true,
+        mirrored: false,
+        time24h: true,
+      });
+    });
+
+    it("returns null for errors and incomplete responses", () => {
+      expect(parsePixooDeviceConfig({ ...response, error_code: 1 })).toBeNull();
+      expect(parsePixooDeviceConfig({ error_code: 0, Brightness: 80 })).toBeNull();
+    });
+
+    it("restores every setting it reads", () => {
+      const config = parsePixooDeviceConfig(response)!;
+      const commands = createPixooRestoreCommands(config);
+
+      expect(commands).toContainEqual({ Command: "Channel/SetBrightness", Brightness: 80 });
+      expect(commands).toContainEqual({ Command: "Device/SetScreenRotationAngle", Mode: 2 });
+      expect(commands).toContainEqual({ Command: "Device/SetDisTempMode", Mode: 1 });
+      // Screen state goes last, once the settings it shows are in place
+      expect(commands[commands.length - 1]).toEqual({ Command: "Channel/OnOffScreen", OnOff: 1 });
+    });
+  });
+
+  describe("createPixooScreenCommand", () => {
+    it("turns the screen on and off", () => {
+      expect(createPixooScreenCommand(true)).toEqual({ Command: "Channel/OnOffScreen", OnOff: 1 });
+      expect(createPixooScreenCommand(false)).toEqual({ Command: "Channel/OnOffScreen", OnOff: 0 });
+    });
+  });
+
+  describe("createPixooBuzzerCommand", () => {
+    it("beeps in on/off cycles for the total time", () => {
+      expect(createPixooBuzzerCommand({ activeMs: 500, offMs: 500, totalMs: 3000 })).toEqual({
+        Command: "Device/PlayBuzzer",
+        ActiveTimeInCycle: 500,
+        OffTimeInCycle: 500,
+        PlayTotalTime: 3000,
+      });
+    });
+  });
+
+  describe("createPixooRotationCommand", () => {
+    it("sends quarter turns", () => {
+      expect(createPixooRotationCommand(0)).toEqual({ Command: "Device/SetScreenRotationAngle", Mode: 0 });
+      expect(createPixooRotationCommand(270)).toEqual({ Command: "Device/SetScreenRotationAngle", Mode: 3 });
+    });
+  });
 });
@@ -11,7 +11,7 @@
  * - Total: 64 * 64 * 3 = 12,288 bytes raw, ~16KB base64
  */
 
-import type { Frame, RGB } from "./types.js";
+import type { BuzzerPayload, Frame, RGB, Rotation } from "./types.js";
 
 /** Default Pixoo64 display size */
 export const PIXOO64_SIZE = 64;
@@ -27,14 +27,33 @@ export function createSolidFrame(
   height: number,
   color: RGB = { r: 0, g: 0, b: 0 }
 ): Frame {
-  const pixels = new Uint8Array(width * height * BYTES_PER_PIXEL);
-  for (let i = 0; i < width * height; i++) {
-    const offset = i * BYTES_PER_PIXEL;
-    pixels[offset] = color.r;
-    pixels[offset + 1] = color.g;
-    pixels[offset + 2] = color.b;
+  const frame = { width, height, pixels: new Uint8Array(width * height * BYTES_PER_PIXEL) };
+  // New buffers are already zeroed
+  if (color.r !== 0 || color.g !== 0 || color.b !== 0) {
+    fillFrame(frame, color);
+  }
+  return frame;
+}
+
+/**
+ * Fill a whole frame with one color, reusing its buffer. Renderers that
+ * draw every minute (or every animation step) clear a scratch frame with
+ * this instead of allocating a new one.
+ */
+export function fillFrame(frame: Frame, color: RGB): void {
+  const { pixels } = frame;
+  if (color.r === color.g && color.g === color.b) {
+    pixels.fill(color.r);
+    return;
+  }
+  if (pixels.length < BYTES_PER_PIXEL) return;
+  pixels[0] = color.r;
+  pixels[1] = color.g;
+  pixels[2] = color.b;
+  // Double the filled span each copy rather than writing pixel by pixel
+  for (let filled = BYTES_PER_PIXEL; filled < pixels.length; filled *= 2) {
+    pixels.copyWithin(filled, 0, Math.min(filled, pixels.length - filled));
   }
-  return { width, height, pixels };
 }
 
 /**
@@ -70,13 +89,35 @@ export function getPixel(frame: Frame, x: number, y: number): RGB | null {
   };
 }
 
+/**
+ * Get a pixel color as a packed 0xRRGGBB number, or -1 out of bounds.
+ * Unlike getPixel this allocates nothing, for loops over many pixels;
+ * any lit pixel is > 0, and colors compare with packRgb.
+ */
+export function getPixelValue(frame: Frame, x: number, y: number): number {
+  if (x < 0 || x >= frame.width || y < 0 || y >= frame.height) {
+    return -1;
+  }
+  const offset = (y * frame.width + x) * BYTES_PER_PIXEL;
+  return (frame.pixels[offset] << 16) | (frame.pixels[offset + 1] << 8) | frame.pixels[offset + 2];
+}
+
+/**
+ * Pack a color as 0xRRGGBB, to compare with getPixelValue
+ */
+export function packRgb(color: RGB): number {
+  return (color.r << 16) | (color.g << 8) | color.b;
+}
+
 /**
  * Encode frame pixels to base64 for Pixoo API
  */
 export function encodeFrameToBase64(frame: Frame): string {
   // In Node.js, use Buffer; in browser, use btoa
   if (typeof Buffer !== "undefined") {
-    return Buffer.from(frame.pixels).toString("base64");
+    // A view over the pixels, not a copy
+    const { buffer, byteOffset, byteLength } = frame.pixels;
+    return Buffer.from(buffer, byteOffset, byteLength).toString("base64");
   }
   // Browser fallback
   let binary = "";
@@ -143,3 +184,274 @@ export function createPixooFrameCommand(
     PicData: encodeFrameToBase64(frame),
   };
 }
+
+/** Channel the device shows frames sent over HTTP on */
+export const PIXOO_CUSTOM_CHANNEL = 3;
+
+/**
+ * Create a Pixoo Channel/SetIndex command (default: the custom channel
+ * that shows HTTP frames)
+ */
+export function createPixooSelectChannelCommand(index: number = PIXOO_CUSTOM_CHANNEL): PixooSettingCommand {
+  return { Command: "Channel/SetIndex", SelectIndex: index };
+}
+
+/**
+ * Create a Pixoo Draw/ResetHttpGifId command. PicIDs start again from 1
+ * after it.
+ */
+export function createPixooResetGifIdCommand(): { Command: "Draw/ResetHttpGifId" } {
+  return { Command: "Draw/ResetHttpGifId" };
+}
+
+/** Pixoo Draw/CommandList command: several commands in one request */
+export interface PixooCommandList {
+  Command: "Draw/CommandList";
+  CommandList: Array<{ Command: string }>;
+}
+
+/**
+ * Create a Pixoo Draw/CommandList command. The device runs the commands
+ * in order and answers with a single error_code.
+ */
+export function createPixooCommandListCommand(commands: Array<{ Command: string }>): PixooCommandList {
+  return { Command: "Draw/CommandList", CommandList: commands };
+}
+
+/** Fields every Pixoo response carries */
+export interface PixooResponse {
+  /** 0 on success */
+  error_code: number;
+}
+
+/**
+ * A command the device rejected. The device answers HTTP 200 either way,
+ * so this is the only sign a command failed.
+ */
+export class PixooError extends Error {
+  /** Command that failed, e.g. "Channel/SetBrightness" */
+  command: string;
+  /** The device's error_code */
+  code: number;
+
+  constructor(command: string, code: number) {
+    super(`Pixoo ${command} failed: error_code ${code}`);
+    this.name = "PixooError";
+    this.command = command;
+    this.code = code;
+  }
+}
+
+/**
+ * Parse the body of a Pixoo response. Throws a PixooError when the device
+ * reports an error_code, and a plain Error when the body isn't a response.
+ */
+export function parsePixooResponse<T extends PixooResponse = PixooResponse>(command: string, body: string): T {
+  let parsed: unknown;
+  try {
+    parsed = JSON.parse(body);
+  } catch {
+    throw new Error(`Pixoo ${command} returned invalid JSON`);
+  }
+  if (typeof parsed !== "object" || parsed === null || typeof (parsed as PixooResponse).error_code !== "number") {
+    throw new Error(`Pixoo ${command} returned a response without error_code`);
+  }
+  const response = parsed as T;
+  if (response.error_code !== 0) {
+    throw new PixooError(command, response.error_code);
+  }
+  return response;
+}
+
+/** Pixoo Device/GetDeviceTime command */
+export interface PixooGetTimeCommand {
+  Command: "Device/GetDeviceTime";
+}
+
+/** Pixoo Device/SetUTC command */
+export interface PixooSetUtcCommand {
+  Command: "Device/SetUTC";
+  /** Unix time in seconds */
+  Utc: number;
+}
+
+/** Response to Device/GetDeviceTime */
+export interface PixooDeviceTime extends PixooResponse {
+  /** Device clock as Unix time in seconds */
+  UTCTime: number;
+  /** Device clock in its configured timezone, "YYYY-MM-DD HH:MM:SS" */
+  LocalTime?: string;
+}
+
+/**
+ * Clock drift the device is allowed before it is corrected. The device
+ * reports whole seconds, so a reading is up to 1s off on its own; a leap
+ * second (which Unix time on both sides ignores) stays under it too.
+ */
+export const CLOCK_DRIFT_THRESHOLD_MS = 3000;
+
+/**
+ * Create a Pixoo Device/GetDeviceTime command
+ */
+export function createPixooGetTimeCommand(): PixooGetTimeCommand {
+  return { Command: "Device/GetDeviceTime" };
+}
+
+/**
+ * Create a Pixoo Device/SetUTC command setting the device clock to `now`
+ */
+export function createPixooSetUtcCommand(now: number = Date.now()): PixooSetUtcCommand {
+  return { Command: "Device/SetUTC", Utc: Math.round(now / 1000) };
+}
+
+/**
+ * Compare the device clock with the host clock. Returns the drift
+ * (positive when the device is ahead) and the command to correct it, or
+ * null when the drift is within the threshold or the response is an error.
+ */
+export function planClockSync(
+  deviceTime: PixooDeviceTime,
+  now: number = Date.now(),
+  thresholdMs: number = CLOCK_DRIFT_THRESHOLD_MS
+): { driftMs: number; command: PixooSetUtcCommand | null } | null {
+  if (deviceTime.error_code !== 0 || !Number.isFinite(deviceTime.UTCTime)) {
+    return null;
+  }
+  const driftMs = deviceTime.UTCTime * 1000 - now;
+  return {
+    driftMs,
+    command: Math.abs(driftMs) > thresholdMs ? createPixooSetUtcCommand(now) : null,
+  };
+}
+
+/** Pixoo Sys/TimeZone command */
+export interface PixooTimezoneCommand {
+  Command: "Sys/TimeZone";
+  /** Offset from UTC, e.g. "GMT-7" or "GMT+5:30" */
+  TimeZoneValue: string;
+}
+
+/**
+ * The device's timezone value for an IANA timezone at `now`. The device
+ * only knows a fixed offset, so it needs setting again after a DST change.
+ */
+export function pixooTimezoneValue(timeZone: string, now: number = Date.now()): string {
+  const offset = new Intl.DateTimeFormat("en-US", { timeZone, timeZoneName: "longOffset" })
+    .formatToParts(now)
+    .find((part) => part.type === "timeZoneName")?.value;
+  // "GMT-07:00", or plain "GMT" for UTC
+  const match = offset?.match(/^GMT([+-])(\d{2}):(\d{2})$/);
+  if (!match) return "GMT+0";
+  const [, sign, hours, minutes] = match;
+  return `GMT${sign}${Number(hours)}${minutes === "00" ? "" : `:${minutes}`}`;
+}
+
+/**
+ * Create a Pixoo Sys/TimeZone command for an IANA timezone.
+ * Throws a RangeError for an unknown timezone.
+ */
+export function createPixooTimezoneCommand(timeZone: string, now: number = Date.now()): PixooTimezoneCommand {
+  return { Command: "Sys/TimeZone", TimeZoneValue: pixooTimezoneValue(timeZone, now) };
+}
+
+/** Response to Channel/GetAllConf */
+export interface PixooAllConfResponse extends PixooResponse {
+  /** 0-100 */
+  Brightness?: number;
+  /** 0-3, quarter turns clockwise */
+  GyrateAngle?: number;
+  /** 0 = Celsius, 1 = Fahrenheit */
+  TemperatureMode?: number;
+  /** 1 = screen on */
+  LightSwitch?: number;
+  /** 1 = mirrored */
+  MirrorFlag?: number;
+  /** 1 = 24-hour clock */
+  Time24Flag?: number;
+}
+
+/** Device settings that survive (or need restoring after) a power cycle */
+export interface PixooDeviceConfig {
+  /** 0-100 */
+  brightness: number;
+  rotation: Rotation;
+  temperatureUnit: "celsius" | "fahrenheit";
+  screenOn: boolean;
+  mirrored: boolean;
+  time24h: boolean;
+}
+
+/** A settings command with a single value */
+export interface PixooSettingCommand {
+  Command: string;
+  [field: string]: string | number;
+}
+
+/**
+ * Create a Pixoo Channel/GetAllConf command
+ */
+export function createPixooGetAllConfCommand(): { Command: "Channel/GetAllConf" } {
+  return { Command: "Channel/GetAllConf" };
+}
+
+/**
+ * Read the settings from a Channel/GetAllConf response.
+ * Returns null for an error response or one missing a setting.
+ */
+export function parsePixooDeviceConfig(response: PixooAllConfResponse): PixooDeviceConfig | null {
+  const { error_code, Brightness, GyrateAngle, TemperatureMode, LightSwitch, MirrorFlag, Time24Flag } = response;
+  const fields = [Brightness, GyrateAngle, TemperatureMode, LightSwitch, MirrorFlag, Time24Flag];
+  if (error_code !== 0 || fields.some((value) => typeof value !== "number")) {
+    return null;
+  }
+  const quarterTurns = (((GyrateAngle as number) % 4) + 4) % 4;
+  return {
+    brightness: Math.max(0, Math.min(100, Brightness as number)),
+    rotation: (quarterTurns * 90) as PixooDeviceConfig["rotation"],
+    temperatureUnit: TemperatureMode === 1 ? "fahrenheit" : "celsius",
+    screenOn: LightSwitch === 1,
+    mirrored: MirrorFlag === 1,
+    time24h: Time24Flag === 1,
+  };
+}
+
+/**
+ * Commands that put a device back to a saved configuration
+ * (e.g. after a power loss reset it to defaults)
+ */
+export function createPixooRestoreCommands(config: PixooDeviceConfig): PixooSettingCommand[] {
+  return [
+    { Command: "Channel/SetBrightness", Brightness: config.brightness },
+    createPixooRotationCommand(config.rotation),
+    { Command: "Device/SetDisTempMode", Mode: config.temperatureUnit === "fahrenheit" ? 1 : 0 },
+    { Command: "Device/SetMirrorMode", Mode: config.mirrored ? 1 : 0 },
+    { Command: "Device/SetTime24Flag", Mode: config.time24h ? 1 : 0 },
+    createPixooScreenCommand(config.screenOn),
+  ];
+}
+
+/**
+ * Create a Pixoo Channel/OnOffScreen command to turn the screen on or off
+ */
+export function createPixooScreenCommand(on: boolean): PixooSettingCommand {
+  return { Command: "Channel/OnOffScreen", OnOff: on ? 1 : 0 };
+}
+
+/**
+ * Create a Pixoo Device/PlayBuzzer command
+ */
+export function createPixooBuzzerCommand(buzz: BuzzerPayload): PixooSettingCommand {
+  return {
+    Command: "Device/PlayBuzzer",
+    ActiveTimeInCycle: buzz.activeMs,
+    OffTimeInCycle: buzz.offMs,
+    PlayTotalTime: buzz.totalMs,
+  };
+}
+
+/**
+ * Create a Pixoo Device/SetScreenRotationAngle command (clockwise)
+ */
+export function createPixooRotationCommand(degrees: Rotation): PixooSettingCommand {
+  return { Command: "Device/SetScreenRotationAngle", Mode: degrees / 90 };
+}
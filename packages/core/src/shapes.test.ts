@@ -0,0 +1,249 @@
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "./pixoo";
+import {
+  drawArc,
+  drawCircle,
+  drawEllipse,
+  drawLine,
+  drawPolygon,
+  fillCircle,
+  fillEllipse,
+  fillPolygon,
+} from "./shapes";
+
+const RED = { r: 255, g: 0, b: 0 };
+const BLACK = { r: 0, g: 0, b: 0 };
+
+describe("drawArc", () => {
+  it("draws a full ring at the radius", () => {
+    const frame = createSolidFrame(11, 11);
+    drawArc(frame, 5, 5, 3, 0, 360, RED);
+
+    for (const [x, y] of [
+      [5, 2],
+      [8, 5],
+      [5, 8],
+      [2, 5],
+    ]) {
+      expect(getPixel(frame, x, y)).toEqual(RED);
+    }
+    expect(getPixel(frame, 5, 5)).toEqual(BLACK);
+    expect(getPixel(frame, 5, 3)).toEqual(BLACK);
+    expect(getPixel(frame, 5, 1)).toEqual(BLACK);
+  });
+
+  it("sweeps clockwise from 12 o'clock", () => {
+    const frame = createSolidFrame(11, 11);
+    drawArc(frame, 5, 5, 3, 0, 90, RED);
+
+    expect(getPixel(frame, 5, 2)).toEqual(RED);
+    expect(getPixel(frame, 7, 3)).toEqual(RED);
+    expect(getPixel(frame, 8, 5)).toEqual(RED);
+    expect(getPixel(frame, 5, 8)).toEqual(BLACK);
+    expect(getPixel(frame, 2, 5)).toEqual(BLACK);
+  });
+
+  it("wraps sweeps that cross 12 o'clock", () => {
+    const frame = createSolidFrame(11, 11);
+    drawArc(frame, 5, 5, 3, -90, 0, RED);
+
+    expect(getPixel(frame, 2, 5)).toEqual(RED);
+    expect(getPixel(frame, 5, 2)).toEqual(RED);
+    expect(getPixel(frame, 8, 5)).toEqual(BLACK);
+  });
+
+  it("thickens inward", () => {
+    const frame = createSolidFrame(11, 11);
+    drawArc(frame, 5, 5, 3, 0, 360, RED, 2);
+
+    expect(getPixel(frame, 5, 2)).toEqual(RED);
+    expect(getPixel(frame, 5, 3)).toEqual(RED);
+    expect(getPixel(frame, 5, 4)).toEqual(BLACK);
+  });
+
+  it("draws nothing for an empty sweep, and clips to the frame", () => {
+    const frame = createSolidFrame(11, 11);
+    drawArc(frame, 5, 5, 3, 90, 90, RED);
+    expect(frame.pixels.every((v) => v === 0)).toBe(true);
+
+    drawArc(frame, 0, 0, 3, 0, 360, RED);
+    expect(getPixel(frame, 3, 0)).toEqual(RED);
+    expect(getPixel(frame, 0, 3)).toEqual(RED);
+  });
+});
+
+/** Lit pixels as "x,y" strings */
+function litPixels(frame: ReturnType<typeof createSolidFrame>): Set<string> {
+  const lit = new Set<string>();
+  for (let y = 0; y < frame.height; y++) {
+    for (let x = 0; x < frame.width; x++) {
+      const p = getPixel(frame, x, y);
+      if (p && (p.r || p.g || p.b)) lit.add(`${x},${y}`);
+    }
+  }
+  return lit;
+}
+
+describe("drawLine", () => {
+  it("draws both ends and one pixel per column of a shallow line", () => {
+    const frame = createSolidFrame(11, 11);
+    drawLine(frame, 0, 0, 10, 3, RED);
+
+    expect(getPixel(frame, 0, 0)).toEqual(RED);
+    expect(getPixel(frame, 10, 3)).toEqual(RED);
+    expect(litPixels(frame).size).toBe(11);
+  });
+
+  it("draws nothing when an end isn't finite", () => {
+    const frame = createSolidFrame(11, 11);
+    drawLine(frame, 0, 0, NaN, 3, RED);
+    drawLine(frame, 0, 0, 10, Infinity, RED);
+    drawPolygon(frame, [{ x: 1, y: 1 }, { x: -Infinity, y: 5 }, { x: 9, y: 9 }], RED);
+    drawCircle(frame, 5, 5, Infinity, RED);
+    fillEllipse(frame, 5, 5, 3, NaN, RED);
+
+    // The polygon's one finite edge is still drawn
+    expect(getPixel(frame, 5, 5)).toEqual(RED);
+    expect(getPixel(frame, 0, 0)).toEqual(BLACK);
+  });
+});
+
+describe("drawCircle", () => {
+  it("draws a gap-free, symmetric outline", () => {
+    const frame = createSolidFrame(11, 11);
+    drawCircle(frame, 5, 5, 4, RED);
+    const lit = litPixels(frame);
+
+    for (const p of ["5,1", "9,5", "5,9", "1,5"]) expect(lit.has(p)).toBe(true);
+    expect(lit.has("5,5")).toBe(false);
+    // Mirror images
+    for (const p of lit) {
+      const [x, y] = p.split(",").map(Number);
+      expect(lit.has(`${10 - x},${y}`)).toBe(true);
+      expect(lit.has(`${y},${x}`)).toBe(true);
+    }
+    // Every outline pixel touches two others (a closed 8-connected loop)
+    for (const p of lit) {
+      const [x, y] = p.split(",").map(Number);
+      let neighbours = 0;
+      for (let dy = -1; dy <= 1; dy++) {
+        for (let dx = -1; dx <= 1; dx++) {
+          if ((dx || dy) && lit.has(`${x + dx},${y + dy}`)) neighbours++;
+        }
+      }
+      expect(neighbours).toBeGreaterThanOrEqual(2);
+    }
+  });
+
+  it("draws a single pixel for radius 0", () => {
+    const frame = createSolidFrame(3, 3);
+    drawCircle(frame, 1, 1, 0, RED);
+    expect([...litPixels(frame)]).toEqual(["1,1"]);
+  });
+});
+
+describe("fillCircle", () => {
+  it("covers the outline and everything inside it", () => {
+    const outline = createSolidFrame(11, 11);
+    const filled = createSolidFrame(11, 11);
+    drawCircle(outline, 5, 5, 4, RED);
+    fillCircle(filled, 5, 5, 4, RED);
+    const lit = litPixels(filled);
+
+    for (const p of litPixels(outline)) expect(lit.has(p)).toBe(true);
+    expect(lit.has("5,5")).toBe(true);
+    expect(lit.has("0,0")).toBe(false);
+    // Each row is one unbroken span
+    for (let y = 1; y <= 9; y++) {
+      const xs = [...lit].filter((p) => p.endsWith(`,${y}`)).map((p) => Number(p.split(",")[0]));
+      expect(Math.max(...xs) - Math.min(...xs) + 1).toBe(xs.length);
+    }
+  });
+
+  it("clips to the frame", () => {
+    const frame = createSolidFrame(4, 4);
+    fillCircle(frame, 0, 0, 3, RED);
+    expect(getPixel(frame, 0, 0)).toEqual(RED);
+    expect(getPixel(frame, 3, 3)).toEqual(BLACK);
+  });
+});
+
+describe("ellipses", () => {
+  it("draws the outline to each radius", () => {
+    const frame = createSolidFrame(13, 9);
+    drawEllipse(frame, 6, 4, 6, 3, RED);
+    const lit = litPixels(frame);
+
+    for (const p of ["0,4", "12,4", "6,1", "6,7"]) expect(lit.has(p)).toBe(true);
+    expect(lit.has("6,4")).toBe(false);
+    expect(lit.has("6,0")).toBe(false);
+  });
+
+  it("fills over its outline", () => {
+    const outline = createSolidFrame(13, 9);
+    const filled = createSolidFrame(13, 9);
+    drawEllipse(outline, 6, 4, 6, 3, RED);
+    fillEllipse(filled, 6, 4, 6, 3, RED);
+    const lit = litPixels(filled);
+
+    for (const p of litPixels(outline)) expect(lit.has(p)).toBe(true);
+    expect(lit.has("6,4")).toBe(true);
+    expect(lit.has("0,1")).toBe(false);
+  });
+
+  it("draws a line for a zero radius", () => {
+    const frame = createSolidFrame(9, 9);
+    drawEllipse(frame, 4, 4, 3, 0, RED);
+    expect(litPixels(frame)).toEqual(new Set(["1,4", "2,4", "3,4", "4,4", "5,4", "6,4", "7,4"]));
+  });
+});
+
+describe("polygons", () => {
+  const triangle = [
+    { x: 1, y: 1 },
+    { x: 9, y: 1 },
+    { x: 5, y: 9 },
+  ];
+
+  it("draws a closed outline", () => {
+    const frame = createSolidFrame(11, 11);
+    drawPolygon(frame, triangle, RED);
+    const lit = litPixels(frame);
+
+    for (const p of ["1,1", "9,1", "5,9", "5,1"]) expect(lit.has(p)).toBe(true);
+    expect(lit.has("5,4")).toBe(false);
+  });
+
+  it("fills the inside along with the outline", () => {
+    const frame = createSolidFrame(11, 11);
+    fillPolygon(frame, triangle, RED);
+    const lit = litPixels(frame);
+
+    for (const p of ["1,1", "5,9", "5,4", "5,2"]) expect(lit.has(p)).toBe(true);
+    expect(lit.has("1,8")).toBe(false);
+    expect(lit.has("9,8")).toBe(false);
+  });
+
+  it("leaves the notch of a concave shape empty", () => {
+    // A "U": notch from x=4 to x=6, down to y=5
+    const frame = createSolidFrame(11, 11);
+    fillPolygon(
+      frame,
+      [
+        { x: 1, y: 1 },
+        { x: 3, y: 1 },
+        { x: 3, y: 5 },
+        { x: 7, y: 5 },
+        { x: 7, y: 1 },
+        { x: 9, y: 1 },
+        { x: 9, y: 9 },
+        { x: 1, y: 9 },
+      ],
+      RED
+    );
+
+    expect(getPixel(frame, 5, 3)).toEqual(BLACK);
+    expect(getPixel(frame, 2, 3)).toEqual(RED);
+    expect(getPixel(frame, 5, 7)).toEqual(RED);
+  });
+});
@@ -0,0 +1,315 @@
+/**
+ * Shape drawing
+ *
+ * Primitives that aren't rectangles: lines, circles, ellipses, polygons
+ * and arcs. Like the frame operations, everything clips to frame bounds.
+ * Circles and ellipses use the midpoint algorithms, so outlines are
+ * exactly one pixel thick with no gaps, and fills cover the same pixels
+ * as their outlines.
+ *
+ * Arc angles are in degrees, clockwise from 12 o'clock, so a gauge reads
+ * the way a clock face does: 90 is 3 o'clock, 180 is 6.
+ */
+
+import type { Frame, RGB } from "./types.js";
+import { setPixel } from "./pixoo.js";
+
+/**
+ * Angle of a point around a center, 0-360 clockwise from 12 o'clock
+ */
+function angleAround(cx: number, cy: number, x: number, y: number): number {
+  const degrees = (Math.atan2(x - cx, cy - y) * 180) / Math.PI;
+  return degrees < 0 ? degrees + 360 : degrees;
+}
+
+/** Slack for floating-point angles landing a hair either side of an arc's ends */
+const ANGLE_EPSILON = 1e-9;
+
+/**
+ * Draw an arc of a ring, clockwise from startAngle to endAngle.
+ * The ring is `thickness` pixels deep, inward from `radius`. A sweep of
+ * 360 degrees or more draws the whole ring; none or less draws nothing.
+ * The center may be fractional (31.5 centers a ring on a 64-pixel frame).
+ */
+export function drawArc(
+  frame: Frame,
+  cx: number,
+  cy: number,
+  radius: number,
+  startAngle: number,
+  endAngle: number,
+  color: RGB,
+  thickness = 1
+): void {
+  const sweep = endAngle - startAngle;
+  if (sweep <= 0 || radius <= 0 || thickness <= 0) return;
+  const start = ((startAngle % 360) + 360) % 360;
+
+  const outer = radius + 0.5;
+  const inner = Math.max(0, radius - thickness + 0.5);
+  const x0 = Math.max(0, Math.floor(cx - outer));
+  const x1 = Math.min(frame.width - 1, Math.ceil(cx + outer));
+  const y0 = Math.max(0, Math.floor(cy - outer));
+  const y1 = Math.min(frame.height - 1, Math.ceil(cy + outer));
+
+  for (let py = y0; py <= y1; py++) {
+    for (let px = x0; px <= x1; px++) {
+      const distance = Math.hypot(px - cx, py - cy);
+      if (distance < inner || distance >= outer) continue;
+      if (sweep < 360) {
+        const offset = (angleAround(cx, cy, px, py) - start + 360) % 360;
+        if (offset > sweep + ANGLE_EPSILON && offset < 360 - ANGLE_EPSILON) continue;
+      }
+      setPixel(frame, px, py, color);
+    }
+  }
+}
+
+/**
+ * A point of a polygon
+ */
+export interface Point {
+  x: number;
+  y: number;
+}
+
+/**
+ * Fill pixels x0..x1 (inclusive) of row y, clipped to the frame
+ */
+function drawSpan(frame: Frame, x0: number, x1: number, y: number, color: RGB): void {
+  if (y < 0 || y >= frame.height) return;
+  for (let x = Math.max(0, x0); x <= Math.min(frame.width - 1, x1); x++) {
+    setPixel(frame, x, y, color);
+  }
+}
+
+/**
+ * Draw a 1px line between two points (Bresenham's algorithm). Nothing is
+ * drawn if an end isn't a finite number, since the line could never reach it.
+ */
+export function drawLine(frame: Frame, x0: number, y0: number, x1: number, y1: number, color: RGB): void {
+  if (![x0, y0, x1, y1].every(Number.isFinite)) return;
+  let x = Math.round(x0);
+  let y = Math.round(y0);
+  const endX = Math.round(x1);
+  const endY = Math.round(y1);
+  const dx = Math.abs(endX - x);
+  const dy = Math.abs(endY - y);
+  const sx = x < endX ? 1 : -1;
+  const sy = y < endY ? 1 : -1;
+  let err = dx - dy;
+
+  while (true) {
+    setPixel(frame, x, y, color);
+    if (x === endX && y === endY) break;
+    const e2 = 2 * err;
+    if (e2 > -dy) {
+      err -= dy;
+      x += sx;
+    }
+    if (e2 < dx) {
+      err += dx;
+      y += sy;
+    }
+  }
+}
+
+/**
+ * Visit one octant of a circle (midpoint algorithm) as offsets from its
+ * center, from 3 o'clock down to 4:30 (x >= y); the other seven mirror it
+ */
+function circleOctant(radius: number, visit: (x: number, y: number) => void): void {
+  let x = radius;
+  let y = 0;
+  let err = 1 - radius;
+  while (x >= y) {
+    visit(x, y);
+    y++;
+    if (err < 0) {
+      err += 2 * y + 1;
+    } else {
+      x--;
+      err += 2 * (y - x) + 1;
+    }
+  }
+}
+
+/**
+ * Draw a 1px circle outline (midpoint algorithm), 2 * radius + 1 pixels
+ * across. Centers and radii are rounded to whole pixels.
+ */
+export function drawCircle(frame: Frame, cx: number, cy: number, radius: number, color: RGB): void {
+  const x0 = Math.round(cx);
+  const y0 = Math.round(cy);
+  const r = Math.round(radius);
+  if (!Number.isFinite(r) || r < 0) return;
+
+  circleOctant(r, (x, y) => {
+    for (const [dx, dy] of [
+      [x, y],
+      [y, x],
+      [-y, x],
+      [-x, y],
+      [-x, -y],
+      [-y, -x],
+      [y, -x],
+      [x, -y],
+    ]) {
+      setPixel(frame, x0 + dx, y0 + dy, color);
+    }
+  });
+}
+
+/**
+ * Fill a circle, covering exactly the pixels drawCircle outlines and
+ * everything inside them
+ */
+export function fillCircle(frame: Frame, cx: number, cy: number, radius: number, color: RGB): void {
+  const x0 = Math.round(cx);
+  const y0 = Math.round(cy);
+  const r = Math.round(radius);
+  if (!Number.isFinite(r) || r < 0) return;
+
+  circleOctant(r, (x, y) => {
+    drawSpan(frame, x0 - x, x0 + x, y0 + y, color);
+    drawSpan(frame, x0 - x, x0 + x, y0 - y, color);
+    drawSpan(frame, x0 - y, x0 + y, y0 + x, color);
+    drawSpan(frame, x0 - y, x0 + y, y0 - x, color);
+  });
+}
+
+/**
+ * Visit one quadrant of an ellipse (midpoint algorithm) as offsets from
+ * its center, from 6 o'clock round to 3; the other three mirror it
+ */
+function ellipseQuadrant(rx: number, ry: number, visit: (x: number, y: number) => void): void {
+  const rx2 = rx * rx;
+  const ry2 = ry * ry;
+  let x = 0;
+  let y = ry;
+  let stepX = 0;
+  let stepY = 2 * rx2 * y;
+
+  // Where the curve is flatter than 45 degrees: step along x
+  let p = ry2 - rx2 * ry + rx2 / 4;
+  while (stepX < stepY) {
+    visit(x, y);
+    x++;
+    stepX += 2 * ry2;
+    if (p < 0) {
+      p += ry2 + stepX;
+    } else {
+      y--;
+      stepY -= 2 * rx2;
+      p += ry2 + stepX - stepY;
+    }
+  }
+
+  // Where it's steeper: step along y
+  p = ry2 * (x + 0.5) * (x + 0.5) + rx2 * (y - 1) * (y - 1) - rx2 * ry2;
+  while (y >= 0) {
+    visit(x, y);
+    y--;
+    stepY -= 2 * rx2;
+    if (p > 0) {
+      p += rx2 - stepY;
+    } else {
+      x++;
+      stepX += 2 * ry2;
+      p += rx2 - stepY + stepX;
+    }
+  }
+}
+
+/**
+ * Draw a 1px ellipse outline (midpoint algorithm), 2 * rx + 1 pixels
+ * across and 2 * ry + 1 tall. A zero radius draws a straight line.
+ */
+export function drawEllipse(frame: Frame, cx: number, cy: number, rx: number, ry: number, color: RGB): void {
+  const x0 = Math.round(cx);
+  const y0 = Math.round(cy);
+  const a = Math.round(rx);
+  const b = Math.round(ry);
+  if (!Number.isFinite(a) || !Number.isFinite(b) || a < 0 || b < 0) return;
+  if (a === 0 || b === 0) {
+    drawLine(frame, x0 - a, y0 - b, x0 + a, y0 + b, color);
+    return;
+  }
+
+  ellipseQuadrant(a, b, (x, y) => {
+    setPixel(frame, x0 + x, y0 + y, color);
+    setPixel(frame, x0 - x, y0 + y, color);
+    setPixel(frame, x0 + x, y0 - y, color);
+    setPixel(frame, x0 - x, y0 - y, color);
+  });
+}
+
+/**
+ * Fill an ellipse, covering exactly the pixels drawEllipse outlines and
+ * everything inside them
+ */
+export function fillEllipse(frame: Frame, cx: number, cy: number, rx: number, ry: number, color: RGB): void {
+  const x0 = Math.round(cx);
+  const y0 = Math.round(cy);
+  const a = Math.round(rx);
+  const b = Math.round(ry);
+  if (!Number.isFinite(a) || !Number.isFinite(b) || a < 0 || b < 0) return;
+  if (a === 0 || b === 0) {
+    drawLine(frame, x0 - a, y0 - b, x0 + a, y0 + b, color);
+    return;
+  }
+
+  ellipseQuadrant(a, b, (x, y) => {
+    drawSpan(frame, x0 - x, x0 + x, y0 + y, color);
+    drawSpan(frame, x0 - x, x0 + x, y0 - y, color);
+  });
+}
+
+/**
+ * Draw a closed polygon's outline: a line from each point to the next,
+ * and from the last back to the first
+ */
+export function drawPolygon(frame: Frame, points: Point[], color: RGB): void {
+  for (let i = 0; i < points.length; i++) {
+    const from = points[i];
+    const to = points[(i + 1) % points.length];
+    drawLine(frame, from.x, from.y, to.x, to.y, color);
+  }
+}
+
+/**
+ * Fill a polygon (scanline, even-odd rule). A pixel is filled when its
+ * center is inside; the outline is drawn too, so thin shapes don't lose
+ * their edges. Works for concave shapes; self-intersecting ones leave
+ * their overlaps empty.
+ */
+export function fillPolygon(frame: Frame, points: Point[], color: RGB): void {
+  if (points.length < 3) {
+    drawPolygon(frame, points, color);
+    return;
+  }
+
+  const ys = points.map((p) => p.y);
+  const top = Math.max(0, Math.floor(Math.min(...ys)));
+  const bottom = Math.min(frame.height - 1, Math.ceil(Math.max(...ys)));
+
+  for (let row = top; row <= bottom; row++) {
+    const scanY = row + 0.5;
+    const crossings: number[] = [];
+    for (let i = 0; i < points.length; i++) {
+      const a = points[i];
+      const b = points[(i + 1) % points.length];
+      // Half-open, so a vertex shared by two edges counts once
+      if ((a.y <= scanY && b.y > scanY) || (b.y <= scanY && a.y > scanY)) {
+        crossings.push(a.x + ((scanY - a.y) / (b.y - a.y)) * (b.x - a.x));
+      }
+    }
+    crossings.sort((m, n) => m - n);
+    for (let i = 0; i + 1 < crossings.length; i += 2) {
+      // Pixel centers between the crossings
+      drawSpan(frame, Math.ceil(crossings[i] - 0.5), Math.floor(crossings[i + 1] - 0.5), row, color);
+    }
+  }
+
+  drawPolygon(frame, points, color);
+}
@@ -0,0 +1,88 @@
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "./pixoo";
+import { createTransitionFrames, createSceneFrames } from "./transitions";
+
+const BLACK = { r: 0, g: 0, b: 0 };
+const WHITE = { r: 200, g: 200, b: 200 };
+
+describe("transitions", () => {
+  const from = createSolidFrame(4, 2, BLACK);
+  const to = createSolidFrame(4, 2, WHITE);
+
+  it("ends on the target frame", () => {
+    for (const type of ["fade", "wipe", "slide"] as const) {
+      const frames = createTransitionFrames(from, to, { type, steps: 4 });
+      expect(frames).toHaveLength(4);
+      expect(frames[3]).toBe(to);
+    }
+  });
+
+  it("fades through intermediate colors", () => {
+    const frames = createTransitionFrames(from, to, { type: "fade", steps: 2 });
+    expect(getPixel(frames[0], 0, 0)).toEqual({ r: 100, g: 100, b: 100 });
+  });
+
+  it("wipes left to right", () => {
+    const frames = createTransitionFrames(from, to, { type: "wipe", steps: 2 });
+    expect(getPixel(frames[0], 1, 1)).toEqual(WHITE);
+    expect(getPixel(frames[0], 2, 1)).toEqual(BLACK);
+  });
+
+  it("slides the new frame in from the right", () => {
+    const marked = createSolidFrame(4, 2, BLACK);
+    marked.pixels.set([9, 9, 9], 0); // top-left pixel of the old frame
+    const frames = createTransitionFrames(marked, to, { type: "slide", steps: 4 });
+    // After 1/4 of the width, the old top-left is off-screen and the new frame fills the last column
+    expect(getPixel(frames[0], 0, 0)).toEqual(BLACK);
+    expect(getPixel(frames[0], 3, 0)).toEqual(WHITE);
+    expect(getPixel(frames[0], 2, 0)).toEqual(BLACK);
+  });
+
+  it("does not modify the input frames", () => {
+    createTransitionFrames(from, to, { type: "fade", steps: 4 });
+    expect(getPixel(from, 0, 0)).toEqual(BLACK);
+  });
+
+  it("returns only the target for mismatched sizes or a single step", () => {
+    expect(createTransitionFrames(createSolidFrame(2, 2), to, { type: "fade" })).toEqual([to]);
+    expect(createTransitionFrames(from, to, { type: "fade", steps: 1 })).toEqual([to]);
+  });
+});
+
+describe("scenes", () => {
+  const frame = createSolidFrame(16, 8, BLACK);
+  const GREEN = { r: 0, g: 255, b: 0 };
+
+  it("ends on the frame itself", () => {
+    for (const type of ["sweep", "confetti"] as const) {
+      const frames = createSceneFrames(frame, { type, steps: 5 });
+      expect(frames).toHaveLength(5);
+      expect(frames[4]).toBe(frame);
+    }
+  });
+
+  it("sweeps a band of color left to right", () => {
+    const frames = createSceneFrames(frame, { type: "sweep", color: GREEN, steps: 4 });
+    const lit = (f: (typeof frames)[number]) =>
+      Array.from({ length: 16 }, (_, x) => getPixel(f, x, 4)!.g).findIndex((g) => g > 100);
+
+    expect(lit(frames[0])).toBeGreaterThanOrEqual(0);
+    expect(lit(frames[1])).toBeGreaterThan(lit(frames[0]));
+    expect(getPixel(frames[0], 0, 0)!.r).toBe(0);
+  });
+
+  it("scatters the same confetti for the same seed", () => {
+    const a = createSceneFrames(frame, { type: "confetti", seed: 7 });
+    const b = createSceneFrames(frame, { type: "confetti", seed: 7 });
+    const c = createSceneFrames(frame, { type: "confetti", seed: 8 });
+
+    expect(a[2].pixels).toEqual(b[2].pixels);
+    expect(a[2].pixels).not.toEqual(c[2].pixels);
+    expect(a[2].pixels.some((v) => v > 0)).toBe(true);
+  });
+
+  it("does not modify the frame", () => {
+    createSceneFrames(frame, { type: "confetti" });
+    expect(frame.pixels.every((v) => v === 0)).toBe(true);
+  });
+});
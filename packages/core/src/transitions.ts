@@ -0,0 +1,175 @@
+/**
+ * Frame transitions
+ *
+ * Generate the in-between frames for a transition from one composed frame
+ * to the next. The returned frames end with the target frame, so playing
+ * them in order lands exactly on `to`.
+ */
+
+import type { Frame, RGB } from "./types.js";
+import { blitFrame, blendPixel } from "./frame.js";
+import { BYTES_PER_PIXEL } from "./pixoo.js";
+
+export type TransitionType = "fade" | "wipe" | "slide";
+
+export const TRANSITION_TYPES: TransitionType[] = ["fade", "wipe", "slide"];
+
+export interface TransitionOptions {
+  type: TransitionType;
+  /** Number of frames to generate, including the final frame (default: 4) */
+  steps?: number;
+}
+
+function cloneFrame(frame: Frame): Frame {
+  return { width: frame.width, height: frame.height, pixels: new Uint8Array(frame.pixels) };
+}
+
+/**
+ * Render a single transition step at progress t (0 = from, 1 = to)
+ */
+function renderStep(from: Frame, to: Frame, type: TransitionType, t: number): Frame {
+  const result = cloneFrame(from);
+  switch (type) {
+    case "fade":
+      blitFrame(result, to, 0, 0, { alpha: t });
+      break;
+    case "wipe": {
+      // Reveal the new frame left to right
+      const edge = Math.round(to.width * t);
+      for (let y = 0; y < to.height; y++) {
+        const start = y * to.width * BYTES_PER_PIXEL;
+        result.pixels.set(to.pixels.subarray(start, start + edge * BYTES_PER_PIXEL), start);
+      }
+      break;
+    }
+    case "slide": {
+      // Old frame moves out to the left as the new one enters from the right
+      const offset = Math.round(to.width * t);
+      result.pixels.fill(0);
+      blitFrame(result, from, -offset, 0);
+      blitFrame(result, to, to.width - offset, 0);
+      break;
+    }
+  }
+  return result;
+}
+
+/**
+ * Generate transition frames from `from` to `to`.
+ * Frames of different sizes can't be blended, so the result is just `to`.
+ */
+export function createTransitionFrames(from: Frame, to: Frame, options: TransitionOptions): Frame[] {
+  const steps = Math.max(1, Math.floor(options.steps ?? 4));
+  if (from.width !== to.width || from.height !== to.height || steps === 1) {
+    return [to];
+  }
+
+  const frames: Frame[] = [];
+  for (let i = 1; i < steps; i++) {
+    frames.push(renderStep(from, to, options.type, i / steps));
+  }
+  frames.push(to);
+  return frames;
+}
+
+/**
+ * Short animations played over a frame to mark an event, ending on the
+ * frame itself - the same shape as a transition, so they are delivered the
+ * same way.
+ */
+export type SceneType = "sweep" | "confetti";
+
+export const SCENE_TYPES: SceneType[] = ["sweep", "confetti"];
+
+export interface SceneOptions {
+  type: SceneType;
+  /** Sweep color; confetti uses its own palette (default: white) */
+  color?: RGB;
+  /** Number of frames to generate, including the final frame (default: 6) */
+  steps?: number;
+  /** Seed for the confetti layout, so a scene can be reproduced (default: 1) */
+  seed?: number;
+}
+
+/** Width of the sweep band in pixels */
+const SWEEP_WIDTH = 10;
+
+/** Confetti pieces per frame */
+const CONFETTI_PIECES = 48;
+
+const CONFETTI_COLORS: RGB[] = [
+  { r: 255, g: 60, b: 60 },
+  { r: 255, g: 200, b: 0 },
+  { r: 0, g: 220, b: 90 },
+  { r: 0, g: 160, b: 255 },
+  { r: 200, g: 80, b: 255 },
+];
+
+/**
+ * Small seeded PRNG (mulberry32) - scenes must not depend on Math.random
+ * so tests and replays are stable
+ */
+function seededRandom(seed: number): () => number {
+  let state = seed >>> 0;
+  return () => {
+    state = (state + 0x6d2b79f5) >>> 0;
+    let t = state;
+    t = Math.imul(t ^ (t >>> 15), t | 1);
+    t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+    return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+  };
+}
+
+/**
+ * A soft vertical band crossing the frame left to right, brightest in the
+ * middle
+ */
+function renderSweepStep(frame: Frame, color: RGB, t: number): void {
+  const center = Math.round(-SWEEP_WIDTH / 2 + (frame.width + SWEEP_WIDTH) * t);
+  for (let dx = -SWEEP_WIDTH / 2; dx < SWEEP_WIDTH / 2; dx++) {
+    const alpha = 0.7 * (1 - Math.abs(dx) / (SWEEP_WIDTH / 2));
+    for (let y = 0; y < frame.height; y++) {
+      blendPixel(frame, center + dx, y, color, alpha);
+    }
+  }
+}
+
+/**
+ * Generate scene frames over `frame`. The result ends with `frame`.
+ */
+export function createSceneFrames(frame: Frame, options: SceneOptions): Frame[] {
+  const steps = Math.max(1, Math.floor(options.steps ?? 6));
+  const color = options.color ?? { r: 255, g: 255, b: 255 };
+  const frames: Frame[] = [];
+
+  if (options.type === "sweep") {
+    for (let i = 1; i < steps; i++) {
+      const step = cloneFrame(frame);
+      renderSweepStep(step, color, i / steps);
+      frames.push(step);
+    }
+  } else {
+    // Pieces start above and across the top, and fall at their own speed
+    const random = seededRandom(options.seed ?? 1);
+    const pieces = Array.from({ length: CONFETTI_PIECES }, () => ({
+      x: Math.floor(random() * frame.width),
+      y: Math.floor(random() * frame.height * 0.6) - frame.height * 0.3,
+      speed: (0.6 + random() * 0.8) * (frame.height / steps),
+      drift: Math.round(random() * 2 - 1),
+      color: CONFETTI_COLORS[Math.floor(random() * CONFETTI_COLORS.length)],
+    }));
+    for (let i = 1; i < steps; i++) {
+      const step = cloneFrame(frame);
+      for (const piece of pieces) {
+        const y = Math.round(piece.y + piece.speed * i);
+        const x = piece.x + piece.drift * i;
+        blendPixel(step, x, y, piece.color, 1);
+        blendPixel(step, x, y + 1, piece.color, 0.5);
+      }
+      frames.push(step);
+    }
+  }
+
+  frames.push(frame);
+  return frames;
+}
@@ -32,6 +32,9 @@ export interface Frame {
   pixels: Uint8Array;
 }
 
+/** Clockwise screen rotation in degrees */
+export type Rotation = 0 | 90 | 180 | 270;
+
 /** Terminal configuration */
 export interface Terminal {
   id: TerminalId;
@@ -50,7 +53,7 @@ export interface WidgetUpdate {
 }
 
 /** WebSocket message types */
-export type WsMessageType = "frame" | "connect" | "disconnect" | "ping" | "pong";
+export type WsMessageType = "frame" | "screen" | "buzzer" | "connect" | "disconnect" | "ping" | "pong";
 
 /** WebSocket message envelope */
 export interface WsMessage {
@@ -70,6 +73,18 @@ export interface FramePayload {
   };
 }
 
+/** Screen message payload: turn device screens on or off */
+export interface ScreenPayload {
+  on: boolean;
+}
+
+/** Buzzer message payload: beep for `activeMs`, pause for `offMs`, for `totalMs` in all */
+export interface BuzzerPayload {
+  activeMs: number;
+  offMs: number;
+  totalMs: number;
+}
+
 /** Widget configuration */
 export interface WidgetConfig {
   widgetId: WidgetId;
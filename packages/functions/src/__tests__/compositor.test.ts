@@ -0,0 +1,402 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import { createSolidFrame, setPixel } from "@signage/core";
+import type { GlucoseFetcher } from "../dexcom/fetcher";
+import type { FrameSender } from "../devices/frame-sender";
+
+// Create mock functions using vi.hoisted to ensure they're available during mock hoisting
+const { mockSend, mockBackoff, mockRateLimit, mockHistory, mockDeviceStats } = vi.hoisted(() => ({
+  mockSend: vi.fn(),
+  mockBackoff: {
+    getDexcomBackoff: vi.fn(),
+    recordDexcomFailure: vi.fn(),
+    clearDexcomBackoff: vi.fn(),
+  },
+  mockRateLimit: { takeDexcomTokens: vi.fn() },
+  mockHistory: { queryHistory: vi.fn(), storeDataPoint: vi.fn(), storeDataPoints: vi.fn() },
+  mockDeviceStats: { recordSendResults: vi.fn(), getDeviceStats: vi.fn() },
+}));
+
+vi.mock("sst", () => ({
+  Resource: {
+    SignageTable: { name: "test-table" },
+    SignageApi: { url: "wss://ws.example.com/prod" },
+    DexcomUsername: { value: "user" },
+    DexcomPassword: { value: "pass" },
+    DexcomFollowers: { value: "" },
+  },
+}));
+
+vi.mock("@aws-sdk/client-dynamodb", () => ({
+  DynamoDBClient: vi.fn(() => ({})),
+  DescribeTableCommand: vi.fn(),
+  paginateQuery: vi.fn(),
+}));
+vi.mock("@aws-sdk/lib-dynamodb", () => ({
+  DynamoDBDocumentClient: {
+    from: vi.fn(() => ({ send: mockSend })),
+  },
+  GetCommand: vi.fn((params) => ({ type: "Get", params })),
+  PutCommand: vi.fn((params) => ({ type: "Put", params })),
+  QueryCommand: vi.fn((params) => ({ type: "Query", params })),
+  DeleteCommand: vi.fn((params) => ({ type: "Delete", params })),
+  UpdateCommand: vi.fn((params) => ({ type: "Update", params })),
+}));
+vi.mock("@aws-sdk/client-apigatewaymanagementapi", () => ({
+  ApiGatewayManagementApiClient: vi.fn(() => ({})),
+  PostToConnectionCommand: vi.fn(),
+  GoneException: class GoneException extends Error {},
+}));
+vi.mock("@diabetes/core", () => ({
+  createDocClient: () => ({}),
+  storeRecords: vi.fn().mockResolvedValue({ written: 0, duplicates: 0, errors: [] }),
+}));
+
+vi.mock("../dexcom/backoff-store.js", () => mockBackoff);
+vi.mock("../dexcom/rate-limit-store.js", () => mockRateLimit);
+vi.mock("../widgets/history-store.js", () => mockHistory);
+vi.mock("../devices/stats-store.js", () => mockDeviceStats);
+
+import { broadcastFrame, fetchBloodSugarData } from "../compositor";
+
+const now = Date.now();
+
+function reading(minutesAgo: number, value: number) {
+  return { WT: `Date(${now - minutesAgo * 60_000})`, ST: "", DT: "", Value: value, Trend: "Flat" };
+}
+
+function fakeFetcher(overrides: Partial<GlucoseFetcher> = {}): GlucoseFetcher {
+  return {
+    authenticate: vi.fn().mockResolvedValue(undefined),
+    fetchReadings: vi.fn().mockResolvedValue([]),
+    ...overrides,
+  };
+}
+
+function fakeSender(overrides: Partial<FrameSender> = {}): FrameSender {
+  return {
+    send: vi.fn().mockResolvedValue(undefined),
+    isGone: vi.fn().mockReturnValue(false),
+    ...overrides,
+  };
+}
+
+const CACHED = {
+  glucose: 140,
+  trend: "Flat",
+  delta: 0,
+  timestamp: now - 2 * 60_000,
+  rangeStatus: "normal",
+  isStale: false,
+};
+
+describe("fetchBloodSugarData", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    vi.spyOn(console, "log").mockImplementation(() => {});
+    vi.spyOn(console, "warn").mockImplementation(() => {});
+    vi.spyOn(console, "error").mockImplementation(() => {});
+    mockSend.mockImplementation(async (command: { type: string; params: { Key?: { pk: string } } }) =>
+      command.type === "Get" && command.params.Key?.pk === "BG_CACHE"
+        ? { Item: { current: CACHED, history: [] } }
+        : {}
+    );
+    mockBackoff.getDexcomBackoff.mockResolvedValue(null);
+    mockBackoff.recordDexcomFailure.mockResolvedValue({ failures: 1, retryAt: now + 60_000 });
+    mockRateLimit.takeDexcomTokens.mockResolvedValue(true);
+    mockHistory.queryHistory.mockResolvedValue([]);
+    mockHistory.storeDataPoints.mockResolvedValue(undefined);
+  });
+
+  it("builds the current reading from the two latest readings", async () => {
+    const fetcher = fakeFetcher({
+      fetchReadings: vi.fn().mockResolvedValue([reading(1, 110), reading(6, 104)]),
+    });
+
+    const { current } = await fetchBloodSugarData(undefined, fetcher);
+
+    expect(current).toMatchObject({ glucose: 110, delta: 6, isStale: false });
+    expect(mockBackoff.recordDexcomFailure).not.toHaveBeenCalled();
+  });
+
+  it("falls back to cached data without fetching when login fails", async () => {
+    const fetcher = fakeFetcher({ authenticate: vi.fn().mockRejectedValue(new Error("AccountPasswordInvalid")) });
+
+    const { current, dexcomUnreachable } = await fetchBloodSugarData(undefined, fetcher);
+
+    expect(current?.glucose).toBe(140);
+    expect(dexcomUnreachable).toBe(true);
+    expect(fetcher.fetchReadings).not.toHaveBeenCalled();
+    expect(mockBackoff.recordDexcomFailure).toHaveBeenCalledWith(null, "AccountPasswordInvalid");
+  });
+
+  it("skips Dexcom entirely while backing off", async () => {
+    mockBackoff.getDexcomBackoff.mockResolvedValue({ failures: 2, retryAt: now + 60_000 });
+    const fetcher = fakeFetcher();
+
+    const { current } = await fetchBloodSugarData(undefined, fetcher);
+
+    expect(current?.glucose).toBe(140);
+    expect(fetcher.authenticate).not.toHaveBeenCalled();
+    expect(mockRateLimit.takeDexcomTokens).not.toHaveBeenCalled();
+  });
+
+  it("marks the cached reading as rate-limited when the budget is spent", async () => {
+    mockRateLimit.takeDexcomTokens.mockResolvedValue(false);
+    const fetcher = fakeFetcher();
+
+    const { current, dexcomUnreachable } = await fetchBloodSugarData(undefined, fetcher);
+
+    expect(current).toMatchObject({ glucose: 140, rateLimited: true });
+    expect(dexcomUnreachable).toBe(false);
+    expect(fetcher.authenticate).not.toHaveBeenCalled();
+  });
+
+  it("calls Dexcom when the budget can't be checked", async () => {
+    mockRateLimit.takeDexcomTokens.mockRejectedValue(new Error("throttled"));
+    const fetcher = fakeFetcher({ fetchReadings: vi.fn().mockResolvedValue([reading(1, 110)]) });
+
+    const { current } = await fetchBloodSugarData(undefined, fetcher);
+
+    expect(current?.glucose).toBe(110);
+  });
+
+  it("keeps a fresh current reading when the history fetch fails, and backs off", async () => {
+    const fetcher = fakeFetcher({
+      fetchReadings: vi
+        .fn()
+        .mockResolvedValueOnce([reading(1, 110), reading(6, 104)])
+        .mockRejectedValueOnce(new Error("Dexcom fetch failed: 500")),
+    });
+
+    const { current, history } = await fetchBloodSugarData(undefined, fetcher);
+
+    expect(current?.glucose).toBe(110);
+    expect(history).toEqual([]);
+    expect(mockBackoff.recordDexcomFailure).toHaveBeenCalledWith(null, "Dexcom fetch failed: 500");
+  });
+
+  it("falls back to cached data when the current fetch fails", async () => {
+    const fetcher = fakeFetcher({
+      fetchReadings: vi.fn().mockRejectedValue(new Error("Dexcom fetch failed: 503")),
+    });
+
+    const { current, dexcomUnreachable } = await fetchBloodSugarData(undefined, fetcher);
+
+    expect(current?.glucose).toBe(140);
+    expect(dexcomUnreachable).toBe(true);
+    expect(mockBackoff.recordDexcomFailure).toHaveBeenCalledOnce();
+  });
+
+  it("clears the backoff after a clean run", async () => {
+    const backoff = { failures: 3, retryAt: now - 1 };
+    mockBackoff.getDexcomBackoff.mockResolvedValue(backoff);
+    const fetcher = fakeFetcher({ fetchReadings: vi.fn().mockResolvedValue([reading(1, 110)]) });
+
+    await fetchBloodSugarData(undefined, fetcher);
+
+    expect(mockBackoff.clearDexcomBackoff).toHaveBeenCalledOnce();
+  });
+});
+
+describe("broadcastFrame", () => {
+  const frame = createSolidFrame(64, 64);
+  setPixel(frame, 0, 0, { r: 255, g: 0, b: 0 });
+
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockSend.mockResolvedValue({});
+    mockDeviceStats.recordSendResults.mockResolvedValue(undefined);
+  });
+
+  it("sends the frame to every connection", async () => {
+    const sender = fakeSender();
+
+    const result = await broadcastFrame(sender, [{ connectionId: "a" }, { connectionId: "b" }], frame);
+
+    expect(result).toMatchObject({ success: 2, failed: 0, cleaned: 0 });
+    expect(JSON.parse((sender.send as ReturnType<typeof vi.fn>).mock.calls[0][1]).type).toBe("frame");
+  });
+
+  it("removes a connection the sender reports gone", async () => {
+    const sender = fakeSender({
+      send: vi.fn().mockRejectedValue(new Error("Gone")),
+      isGone: vi.fn().mockReturnValue(true),
+    });
+    vi.spyOn(console, "log").mockImplementation(() => {});
+
+    const result = await broadcastFrame(sender, [{ connectionId: "a" }], frame);
+
+    expect(result).toMatchObject({ success: 0, failed: 1, cleaned: 1 });
+    expect(mockSend).toHaveBeenCalledWith(
+      expect.objectContaining({ type: "Delete", params: expect.objectContaining({ Key: { pk: "CONNECTIONS", sk: "a" } }) })
+    );
+  });
+
+  it("counts other failures without removing the connection", async () => {
+    const sender = fakeSender({ send: vi.fn().mockRejectedValue(new Error("Internal")) });
+
+    const result = await broadcastFrame(sender, [{ connectionId: "a" }], frame);
+
+    expect(result).toMatchObject({ failed: 1, cleaned: 0 });
+    expect(mockSend).not.toHaveBeenCalledWith(expect.objectContaining({ type: "Delete" }));
+  });
+
+  it("sends rotated devices a rotated frame", async () => {
+    const sender = fakeSender();
+
+    await broadcastFrame(
+      sender,
+      [
+        { connectionId: "a", terminalId: "kitchen" },
+        { connectionId: "b", terminalId: "hallway" },
+      ],
+      frame,
+      [],
+      { minIntervalMs: {}, maintenanceWindows: {}, rotations: { hallway: 90 }, models: {}, layouts: {} }
+    );
+
+    const data = (sender.send as ReturnType<typeof vi.fn>).mock.calls.map(
+      ([, message]: [string, string]) => JSON.parse(message).payload.frame.data
+    );
+    expect(data[0]).not.toBe(data[1]);
+  });
+
+  it("caches the frame each device was sent, under its device ID", async () => {
+    const sender = fakeSender();
+
+    await broadcastFrame(sender, [{ connectionId: "a", terminalId: "desk" }], frame, [], {
+      minIntervalMs: {},
+      maintenanceWindows: {},
+      rotations: {},
+      models: { desk: "pixoo16" },
+      layouts: {},
+    });
+
+    const { payload } = JSON.parse((sender.send as ReturnType<typeof vi.fn>).mock.calls[0][1]);
+    expect(mockSend).toHaveBeenCalledWith(
+      expect.objectContaining({
+        type: "Put",
+        params: expect.objectContaining({
+          Item: expect.objectContaining({
+            pk: "FRAME_CACHE",
+            sk: "DEVICE#desk",
+            width: 16,
+            height: 16,
+            frameData: payload.frame.data,
+          }),
+        }),
+      })
+    );
+  });
+
+  it("doesn't cache a frame that failed to send", async () => {
+    const sender = fakeSender({ send: vi.fn().mockRejectedValue(new Error("Internal")) });
+
+    await broadcastFrame(sender, [{ connectionId: "a", terminalId: "desk" }], frame);
+
+    expect(mockSend).not.toHaveBeenCalledWith(
+      expect.objectContaining({ params: expect.objectContaining({ Item: expect.objectContaining({ pk: "FRAME_CACHE" }) }) })
+    );
+  });
+
+  it("sends smaller panels a downscaled frame", async () => {
+    const sender = fakeSender();
+
+    await broadcastFrame(sender, [{ connectionId: "a", terminalId: "desk" }], frame, [], {
+      minIntervalMs: {},
+      maintenanceWindows: {},
+      rotations: {},
+      models: { desk: "pixoo16" },
+      layouts: {},
+    });
+
+    const { payload } = JSON.parse((sender.send as ReturnType<typeof vi.fn>).mock.calls[0][1]);
+    expect(payload.frame).toMatchObject({ width: 16, height: 16 });
+    expect(Buffer.from(payload.frame.data, "base64")).toHaveLength(16 * 16 * 3);
+  });
+
+  it("sends a model its own frame, without the transition", async () => {
+    const sender = fakeSender();
+    const compact = createSolidFrame(32, 8, { r: 0, g: 255, b: 0 });
+
+    await broadcastFrame(
+      sender,
+      [
+        { connectionId: "a", terminalId: "desk" },
+        { connectionId: "b", terminalId: "kitchen" },
+      ],
+      frame,
+      [frame],
+      { minIntervalMs: {}, maintenanceWindows: {}, rotations: {}, models: { desk: "ulanzi" }, layouts: {} },
+      { ulanzi: compact }
+    );
+
+    const [desk, kitchen] = (sender.send as ReturnType<typeof vi.fn>).mock.calls.map(
+      ([, message]: [string, string]) => JSON.parse(message).payload
+    );
+    expect(desk.frame).toMatchObject({ width: 32, height: 8 });
+    expect(Buffer.from(desk.frame.data, "base64")[1]).toBe(255);
+    expect(desk.animation).toBeUndefined();
+    expect(kitchen.animation).toBeDefined();
+  });
+
+  it("sends a device its assigned layout's frame, without the transition or ticks", async () => {
+    const sender = fakeSender();
+    const night = createSolidFrame(64, 64, { r: 0, g: 0, b: 90 });
+    const patch = { delayMs: 1000, x: 60, y: 1, width: 3, height: 5, data: "AAAA" };
+
+    await broadcastFrame(
+      sender,
+      [
+        { connectionId: "a", terminalId: "bedroom" },
+        { connectionId: "b", terminalId: "kitchen" },
+      ],
+      frame,
+      [frame],
+      { minIntervalMs: {}, maintenanceWindows: {}, rotations: {}, models: {}, layouts: { bedroom: "night" } },
+      {},
+      { stepMs: 1000, patches: [patch] },
+      { night }
+    );
+
+    const [bedroom, kitchen] = (sender.send as ReturnType<typeof vi.fn>).mock.calls.map(
+      ([, message]: [string, string]) => JSON.parse(message).payload
+    );
+    expect(Buffer.from(bedroom.frame.data, "base64")[2]).toBe(90);
+    expect(bedroom.animation).toBeUndefined();
+    expect(bedroom.ticks).toBeUndefined();
+    expect(kitchen.frame.data).not.toBe(bedroom.frame.data);
+    expect(kitchen.animation).toBeDefined();
+  });
+
+  it("sends clock ticks only to panels showing the frame as it is, within their limit", async () => {
+    const sender = fakeSender();
+    const patch = { delayMs: 1000, x: 60, y: 1, width: 3, height: 5, data: "AAAA" };
+
+    await broadcastFrame(
+      sender,
+      [
+        { connectionId: "a", terminalId: "kitchen" },
+        { connectionId: "b", terminalId: "hallway" },
+        { connectionId: "c", terminalId: "desk" },
+        { connectionId: "d", terminalId: "slow" },
+      ],
+      frame,
+      [],
+      {
+        minIntervalMs: { slow: 1500 },
+        maintenanceWindows: {},
+        rotations: { hallway: 90 },
+        models: { desk: "pixoo16" },
+        layouts: {},
+      },
+      {},
+      { stepMs: 1000, patches: [patch] }
+    );
+
+    const ticks = (sender.send as ReturnType<typeof vi.fn>).mock.calls.map(
+      ([id, message]: [string, string]) => [id, JSON.parse(message).payload.ticks]
+    );
+    expect(Object.fromEntries(ticks)).toEqual({ a: [patch], b: undefined, c: undefined, d: undefined });
+  });
+});
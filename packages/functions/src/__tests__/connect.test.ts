@@ -1,8 +1,9 @@
 import { describe, it, expect, vi, beforeEach } from "vitest";
 
 // Create mock functions using vi.hoisted to ensure they're available during mock hoisting
-const { mockSend } = vi.hoisted(() => ({
+const { mockSend, mockRecordEvent } = vi.hoisted(() => ({
   mockSend: vi.fn(),
+  mockRecordEvent: vi.fn(),
 }));
 
 // Mock SST Resource before importing handler
@@ -24,6 +25,10 @@ vi.mock("@aws-sdk/lib-dynamodb", () => ({
   UpdateCommand: vi.fn((params) => ({ type: "Update", params })),
 }));
 
+vi.mock("../events/store.js", () => ({
+  recordEvent: mockRecordEvent,
+}));
+
 import { handler } from "../connect";
 import type { APIGatewayProxyWebsocketEventV2 } from "aws-lambda";
 
@@ -110,4 +115,12 @@ describe("connect handler", () => {
     const putCall = mockSend.mock.calls[0][0];
     expect(putCall.params.Item.terminalType).toBe("unknown");
   });
+
+  it("logs the device coming online", async () => {
+    await handler(createEvent("conn-123", { terminalId: "kitchen", type: "pixoo64" }), {} as never, () => {});
+
+    expect(mockRecordEvent).toHaveBeenCalledWith(
+      expect.objectContaining({ category: "device", type: "online", subject: "kitchen", detail: "conn-123" })
+    );
+  });
 });
@@ -1,8 +1,9 @@
 import { describe, it, expect, vi, beforeEach } from "vitest";
 
 // Create mock functions using vi.hoisted to ensure they're available during mock hoisting
-const { mockSend } = vi.hoisted(() => ({
+const { mockSend, mockRecordEvent } = vi.hoisted(() => ({
   mockSend: vi.fn(),
+  mockRecordEvent: vi.fn(),
 }));
 
 // Mock SST Resource before importing handler
@@ -24,6 +25,10 @@ vi.mock("@aws-sdk/lib-dynamodb", () => ({
   UpdateCommand: vi.fn((params) => ({ type: "Update", params })),
 }));
 
+vi.mock("../events/store.js", () => ({
+  recordEvent: mockRecordEvent,
+}));
+
 import { handler } from "../disconnect";
 import type { APIGatewayProxyWebsocketEventV2 } from "aws-lambda";
 
@@ -118,4 +123,21 @@ describe("disconnect handler", () => {
       "DynamoDB error"
     );
   });
+
+  it("logs the device going offline", async () => {
+    mockSend.mockResolvedValueOnce({ Attributes: { terminalId: "kitchen", terminalType: "pixoo64" } });
+
+    await handler(createEvent("conn-123"), {} as never, () => {});
+
+    expect(mockSend.mock.calls[0][0].params.ReturnValues).toBe("ALL_OLD");
+    expect(mockRecordEvent).toHaveBeenCalledWith(
+      expect.objectContaining({ category: "device", type: "offline", subject: "kitchen", detail: "conn-123" })
+    );
+  });
+
+  it("doesn't log a connection that was already removed", async () => {
+    await handler(createEvent("conn-123"), {} as never, () => {});
+
+    expect(mockRecordEvent).not.toHaveBeenCalled();
+  });
 });
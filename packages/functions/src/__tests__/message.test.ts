@@ -23,6 +23,7 @@ vi.mock("@aws-sdk/lib-dynamodb", () => ({
   },
   QueryCommand: vi.fn((params) => ({ type: "Query", params })),
   GetCommand: vi.fn((params) => ({ type: "Get", params })),
+  PutCommand: vi.fn((params) => ({ type: "Put", params })),
 }));
 
 // Mock API Gateway Management API
@@ -80,14 +81,16 @@ describe("message handler", () => {
   });
 
   describe("connect message type", () => {
-    it("sends cached frame to client on connect", async () => {
-      mockDdbSend.mockResolvedValueOnce({
-        Item: {
-          width: 64,
-          height: 64,
-          frameData: "base64-frame-data",
-        },
-      });
+    it("sends the device its own cached frame on connect", async () => {
+      mockDdbSend
+        .mockResolvedValueOnce({ Item: { connectionId: "conn-123", terminalId: "kitchen" } })
+        .mockResolvedValueOnce({
+          Item: {
+            width: 64,
+            height: 64,
+            frameData: "base64-frame-data",
+          },
+        });
 
       const event = createEvent(
         "conn-123",
@@ -100,9 +103,12 @@ describe("message handler", () => {
         body: "Registered",
       });
 
-      // Should fetch cached frame
+      // Should fetch the device's cached frame
       expect(mockDdbSend).toHaveBeenCalledWith(
-        expect.objectContaining({ type: "Get" })
+        expect.objectContaining({
+          type: "Get",
+          params: expect.objectContaining({ Key: { pk: "FRAME_CACHE", sk: "DEVICE#kitchen" } }),
+        })
       );
 
       // Should send frame to client
@@ -116,6 +122,22 @@ describe("message handler", () => {
       );
     });
 
+    it("falls back to the latest frame for a device without one", async () => {
+      mockDdbSend
+        .mockResolvedValueOnce({ Item: { connectionId: "conn-123", terminalId: "new-panel" } })
+        .mockResolvedValueOnce({ Item: undefined })
+        .mockResolvedValueOnce({ Item: { width: 64, height: 64, frameData: "latest-frame-data" } });
+
+      const event = createEvent(
+        "conn-123",
+        JSON.stringify({ type: "connect", payload: {}, timestamp: Date.now() })
+      );
+      await handler(event, {} as never, () => {});
+
+      const data = JSON.parse(mockApiSend.mock.calls[0][0].params.Data);
+      expect(data.payload.frame).toEqual({ width: 64, height: 64, data: "latest-frame-data" });
+    });
+
     it("handles missing cached frame gracefully", async () => {
       mockDdbSend.mockResolvedValueOnce({ Item: undefined });
 
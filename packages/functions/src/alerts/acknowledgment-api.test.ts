@@ -0,0 +1,80 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockGetStatus, mockGetAcknowledgments, mockSaveAcknowledgments } = vi.hoisted(() => ({
+  mockGetStatus: vi.fn(),
+  mockGetAcknowledgments: vi.fn(),
+  mockSaveAcknowledgments: vi.fn(),
+}));
+
+vi.mock("../status/store.js", () => ({
+  getCompositorStatus: mockGetStatus,
+}));
+
+vi.mock("./acknowledgment-store.js", () => ({
+  getAlertAcknowledgments: mockGetAcknowledgments,
+  saveAlertAcknowledgments: mockSaveAcknowledgments,
+}));
+
+import { handler } from "./acknowledgment-api";
+
+function createEvent(method: string, body?: unknown, query?: Record<string, string>): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    body: body === undefined ? undefined : JSON.stringify(body),
+    queryStringParameters: query,
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("alert acknowledgment API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGetStatus.mockResolvedValue({
+      updatedAt: Date.now(),
+      alerts: [{ type: "urgentLowSoon", title: "LOW SOON", detail: "BELOW 55 IN 12M" }],
+    });
+    mockGetAcknowledgments.mockResolvedValue([]);
+    mockSaveAcknowledgments.mockResolvedValue(undefined);
+  });
+
+  it("snoozes the showing alerts on POST", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { minutes: 20 }));
+
+    expect(statusCode).toBe(200);
+    expect(body.acknowledgments).toEqual([expect.objectContaining({ type: "urgentLowSoon" })]);
+    expect(mockSaveAcknowledgments).toHaveBeenCalledWith(body.acknowledgments);
+  });
+
+  it("returns 409 for an alert that isn't showing", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { type: "high" }));
+
+    expect(statusCode).toBe(409);
+    expect(mockSaveAcknowledgments).not.toHaveBeenCalled();
+  });
+
+  it("rejects invalid requests", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { minutes: -1 }));
+    expect(statusCode).toBe(400);
+  });
+
+  it("ends a snooze on DELETE", async () => {
+    mockGetAcknowledgments.mockResolvedValue([
+      { type: "urgentLowSoon", acknowledgedAt: 0, until: 1 },
+      { type: "high", acknowledgedAt: 0, until: 1 },
+    ]);
+
+    const { body } = await invoke(createEvent("DELETE", undefined, { type: "high" }));
+
+    expect(body.acknowledgments.map((a: { type: string }) => a.type)).toEqual(["urgentLowSoon"]);
+  });
+
+  it("rejects other methods", async () => {
+    const { statusCode } = await invoke(createEvent("GET"));
+    expect(statusCode).toBe(405);
+  });
+});
@@ -0,0 +1,80 @@
+/**
+ * Alert acknowledgment API
+ *
+ * POST   /alerts/ack          - snooze showing alerts
+ * DELETE /alerts/ack?type=... - end a snooze (every snooze without a type)
+ *
+ * Body: { "type": "urgentLowSoon", "minutes": 30 }
+ * Both optional: without a type, every alert on the display is snoozed;
+ * minutes defaults to 30 (max 240, and 60 for urgent alerts). Only alerts
+ * the display is showing can be snoozed. A snoozed alert comes back early
+ * if glucose moves another 10 mg/dL the wrong way, and the snooze ends
+ * when the alert clears.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { getCompositorStatus } from "../status/store.js";
+import { acknowledgeAlert, parseAcknowledgeRequest } from "./acknowledgment.js";
+import { getAlertAcknowledgments, saveAlertAcknowledgments } from "./acknowledgment-store.js";
+import { ALERT_TYPES, type AlertType } from "./types.js";
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  if (method === "DELETE") {
+    const type = event.queryStringParameters?.type;
+    if (type !== undefined && !ALERT_TYPES.includes(type as AlertType)) {
+      return json(400, { error: `type must be one of: ${ALERT_TYPES.join(", ")}` });
+    }
+    const current = await getAlertAcknowledgments();
+    const acknowledgments = type ? current.filter((a) => a.type !== type) : [];
+    await saveAlertAcknowledgments(acknowledgments);
+    console.log(type ? `Alert snooze ended: ${type}` : "Alert snoozes ended");
+    return json(200, { acknowledgments });
+  }
+
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  let body: Parameters<typeof parseAcknowledgeRequest>[0];
+  try {
+    body = JSON.parse(event.body || "{}");
+  } catch {
+    return json(400, { error: "Invalid JSON" });
+  }
+
+  const request = parseAcknowledgeRequest(body);
+  if (typeof request === "string") {
+    return json(400, { error: request });
+  }
+
+  // Snoozing only what's showing keeps an alert from being silenced
+  // before it ever goes off
+  const showing = ((await getCompositorStatus())?.alerts ?? []).map((a) => a.type as AlertType);
+  const types = request.type ? showing.filter((t) => t === request.type) : showing;
+  if (types.length === 0) {
+    return json(409, { error: request.type ? `No ${request.type} alert is showing` : "No alert is showing" });
+  }
+
+  const now = Date.now();
+  const snoozed = types.map((type) => acknowledgeAlert(type, request.minutes, now));
+  const acknowledgments = [
+    ...(await getAlertAcknowledgments()).filter((a) => !types.includes(a.type)),
+    ...snoozed,
+  ];
+  await saveAlertAcknowledgments(acknowledgments);
+  console.log(`Alerts snoozed: ${snoozed.map((a) => `${a.type} until ${new Date(a.until).toISOString()}`).join(", ")}`);
+  return json(200, { acknowledgments });
+};
@@ -0,0 +1,45 @@
+/**
+ * Alert acknowledgment store
+ * Persists snoozed alerts in DynamoDB, alongside the alert rules.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { AlertAcknowledgment } from "./types.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** DynamoDB key for the acknowledgments item */
+const ACKNOWLEDGMENTS_KEY = { pk: "ALERT_CONFIG", sk: "ACKNOWLEDGMENTS" };
+
+/**
+ * Get the current snoozes, one per alert type at most
+ */
+export async function getAlertAcknowledgments(): Promise<AlertAcknowledgment[]> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: ACKNOWLEDGMENTS_KEY,
+    })
+  );
+
+  return (result.Item?.acknowledgments ?? []) as AlertAcknowledgment[];
+}
+
+/**
+ * Save the snoozes, replacing the stored ones
+ */
+export async function saveAlertAcknowledgments(acknowledgments: AlertAcknowledgment[]): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        ...ACKNOWLEDGMENTS_KEY,
+        acknowledgments,
+        updatedAt: new Date().toISOString(),
+      },
+    })
+  );
+}
@@ -0,0 +1,113 @@
+import { describe, it, expect } from "vitest";
+import {
+  acknowledgeAlert,
+  applyAcknowledgments,
+  hasWorsened,
+  parseAcknowledgeRequest,
+  MAX_URGENT_SNOOZE_MINUTES,
+  REALERT_MARGIN_MGDL,
+} from "./acknowledgment";
+import type { GlucoseAlert } from "./types";
+
+const NOW = new Date("2026-03-02T12:00:00Z").getTime();
+const MINUTE = 60 * 1000;
+
+const lowSoon: GlucoseAlert = {
+  type: "urgentLowSoon",
+  severity: "urgent",
+  title: "LOW SOON",
+  detail: "BELOW 55 IN 12M",
+  raisedAt: NOW,
+};
+const urgentLow: GlucoseAlert = {
+  type: "urgentLow",
+  severity: "urgent",
+  title: "URGENT LOW",
+  detail: "BELOW 55",
+  raisedAt: NOW,
+};
+const high: GlucoseAlert = { type: "high", severity: "warning", title: "HIGH", detail: "ABOVE 250", raisedAt: NOW };
+
+describe("parseAcknowledgeRequest", () => {
+  it("defaults to every showing alert for 30 minutes", () => {
+    expect(parseAcknowledgeRequest({})).toEqual({ minutes: 30 });
+    expect(parseAcknowledgeRequest({ type: "high", minutes: 90 })).toEqual({ type: "high", minutes: 90 });
+  });
+
+  it("rejects unknown types and bad durations", () => {
+    expect(parseAcknowledgeRequest({ type: "party" })).toMatch(/type/);
+    expect(parseAcknowledgeRequest({ minutes: 0 })).toMatch(/minutes/);
+    expect(parseAcknowledgeRequest({ minutes: 500 })).toMatch(/minutes/);
+  });
+});
+
+describe("acknowledgeAlert", () => {
+  it("caps snoozes of urgent alerts", () => {
+    expect(acknowledgeAlert("high", 120, NOW).until).toBe(NOW + 120 * MINUTE);
+    expect(acknowledgeAlert("urgentLowSoon", 120, NOW).until).toBe(NOW + MAX_URGENT_SNOOZE_MINUTES * MINUTE);
+    expect(acknowledgeAlert("urgentLow", 240, NOW).until).toBe(NOW + MAX_URGENT_SNOOZE_MINUTES * MINUTE);
+  });
+});
+
+describe("hasWorsened", () => {
+  it("looks down for lows and up for highs", () => {
+    const low = { ...acknowledgeAlert("urgentLowSoon", 30, NOW), glucose: 80 };
+    expect(hasWorsened(low, 80 - REALERT_MARGIN_MGDL)).toBe(true);
+    expect(hasWorsened(low, 75)).toBe(false);
+    expect(hasWorsened(low, 120)).toBe(false);
+
+    const rising = { ...acknowledgeAlert("high", 30, NOW), glucose: 260 };
+    expect(hasWorsened(rising, 260 + REALERT_MARGIN_MGDL)).toBe(true);
+    expect(hasWorsened(rising, 240)).toBe(false);
+  });
+
+  it("never ends a snooze without a baseline", () => {
+    expect(hasWorsened(acknowledgeAlert("urgentLowSoon", 30, NOW), 40)).toBe(false);
+  });
+});
+
+describe("applyAcknowledgments", () => {
+  it("hides snoozed alerts and pins the baseline", () => {
+    const state = applyAcknowledgments([lowSoon, high], [acknowledgeAlert("high", 30, NOW)], 270, NOW);
+
+    expect(state.alerts).toEqual([lowSoon]);
+    expect(state.acknowledgments).toEqual([expect.objectContaining({ type: "high", glucose: 270 })]);
+    expect(state.changed).toBe(true);
+  });
+
+  it("keeps a pinned snooze unchanged", () => {
+    const snooze = { ...acknowledgeAlert("urgentLowSoon", 30, NOW), glucose: 80 };
+    const state = applyAcknowledgments([lowSoon], [snooze], 76, NOW + MINUTE);
+
+    expect(state.alerts).toEqual([]);
+    expect(state.changed).toBe(false);
+  });
+
+  it("re-alerts when glucose keeps falling", () => {
+    const snooze = { ...acknowledgeAlert("urgentLowSoon", 30, NOW), glucose: 80 };
+    const state = applyAcknowledgments([lowSoon], [snooze], 68, NOW + 10 * MINUTE);
+
+    expect(state.alerts).toEqual([lowSoon]);
+    expect(state.acknowledgments).toEqual([]);
+    expect(state.changed).toBe(true);
+  });
+
+  it("brings an urgent low back once the capped snooze runs out, or it falls further", () => {
+    const snooze = { ...acknowledgeAlert("urgentLow", 240, NOW), glucose: 52 };
+
+    expect(applyAcknowledgments([urgentLow], [snooze], 52, NOW + 30 * MINUTE).alerts).toEqual([]);
+    expect(applyAcknowledgments([urgentLow], [snooze], 52, NOW + 60 * MINUTE).alerts).toEqual([urgentLow]);
+    expect(applyAcknowledgments([urgentLow], [snooze], 42, NOW + 5 * MINUTE).alerts).toEqual([urgentLow]);
+  });
+
+  it("drops snoozes that ran out or whose alert cleared", () => {
+    const snooze = { ...acknowledgeAlert("high", 30, NOW), glucose: 260 };
+
+    expect(applyAcknowledgments([high], [snooze], 260, NOW + 30 * MINUTE).alerts).toEqual([high]);
+    expect(applyAcknowledgments([], [snooze], 200, NOW + MINUTE)).toEqual({
+      alerts: [],
+      acknowledgments: [],
+      changed: true,
+    });
+  });
+});
@@ -0,0 +1,122 @@
+/**
+ * Alert acknowledgment and snooze
+ *
+ * Acknowledging an alert hides it for a snooze period. The snooze ends
+ * early if glucose keeps going the wrong way - down for a low, up for a
+ * high - by more than a margin from where it was when the snooze started,
+ * and it's dropped once the alert clears, so the next episode alerts
+ * straight away.
+ */
+
+import type { AlertAcknowledgment, AlertType, GlucoseAlert } from "./types.js";
+import { ALERT_TYPES } from "./types.js";
+import { URGENT_ALERT_TYPES } from "./rules.js";
+
+export const DEFAULT_SNOOZE_MINUTES = 30;
+export const MAX_SNOOZE_MINUTES = 240;
+/** Urgent alerts can only be put off this long */
+export const MAX_URGENT_SNOOZE_MINUTES = 60;
+
+/** How much further glucose can move the wrong way before a snooze ends (mg/dL) */
+export const REALERT_MARGIN_MGDL = 10;
+
+/** Which way glucose moving makes each alert worse (0: it can't, there are no readings) */
+const WORSENING: Record<AlertType, -1 | 0 | 1> = {
+  urgentLow: -1,
+  urgentLowSoon: -1,
+  fallingFast: -1,
+  high: 1,
+  risingFast: 1,
+  signalLoss: 0,
+};
+
+/**
+ * A validated acknowledgment request: the alert types to snooze (every
+ * showing one when not given) and for how long
+ */
+export interface AcknowledgeRequest {
+  type?: AlertType;
+  minutes: number;
+}
+
+/**
+ * Validate a POST /alerts/ack body.
+ * Returns the request, or an error message.
+ */
+export function parseAcknowledgeRequest(body: { type?: unknown; minutes?: unknown }): AcknowledgeRequest | string {
+  if (body.type !== undefined && !ALERT_TYPES.includes(body.type as AlertType)) {
+    return `type must be one of: ${ALERT_TYPES.join(", ")}`;
+  }
+  const type = body.type as AlertType | undefined;
+
+  const minutes = body.minutes ?? DEFAULT_SNOOZE_MINUTES;
+  if (typeof minutes !== "number" || !Number.isInteger(minutes) || minutes < 1 || minutes > MAX_SNOOZE_MINUTES) {
+    return `minutes must be a whole number from 1 to ${MAX_SNOOZE_MINUTES}`;
+  }
+
+  return { ...(type && { type }), minutes };
+}
+
+/**
+ * Snooze an alert type for some minutes (shortened for urgent alerts)
+ */
+export function acknowledgeAlert(type: AlertType, minutes: number, now: number): AlertAcknowledgment {
+  const capped = URGENT_ALERT_TYPES.has(type) ? Math.min(minutes, MAX_URGENT_SNOOZE_MINUTES) : minutes;
+  return { type, acknowledgedAt: now, until: now + capped * 60 * 1000 };
+}
+
+/**
+ * Whether glucose has moved far enough the wrong way to end a snooze
+ */
+export function hasWorsened(acknowledgment: AlertAcknowledgment, glucose: number): boolean {
+  if (acknowledgment.glucose === undefined) return false;
+  return (glucose - acknowledgment.glucose) * WORSENING[acknowledgment.type] >= REALERT_MARGIN_MGDL;
+}
+
+/**
+ * What became of the snoozes after an evaluation
+ */
+export interface AcknowledgmentState {
+  /** Alerts left to show */
+  alerts: GlucoseAlert[];
+  /** Snoozes still holding, with their baselines pinned */
+  acknowledgments: AlertAcknowledgment[];
+  /** True if a snooze ended or got its baseline, so the stored ones need saving */
+  changed: boolean;
+}
+
+/**
+ * Hide snoozed alerts. Snoozes that ran out, whose alert has cleared or
+ * whose glucose has worsened are dropped; a new one takes the current
+ * reading as its baseline.
+ */
+export function applyAcknowledgments(
+  alerts: GlucoseAlert[],
+  acknowledgments: AlertAcknowledgment[],
+  glucose: number | null,
+  now: number
+): AcknowledgmentState {
+  const raised = new Set(alerts.map((a) => a.type));
+  let changed = false;
+  const holding: AlertAcknowledgment[] = [];
+
+  for (const acknowledgment of acknowledgments) {
+    const ended =
+      now >= acknowledgment.until ||
+      !raised.has(acknowledgment.type) ||
+      (glucose !== null && hasWorsened(acknowledgment, glucose));
+    if (ended) {
+      changed = true;
+      continue;
+    }
+    if (acknowledgment.glucose === undefined && glucose !== null) {
+      holding.push({ ...acknowledgment, glucose });
+      changed = true;
+    } else {
+      holding.push(acknowledgment);
+    }
+  }
+
+  const snoozed = new Set(holding.map((a) => a.type));
+  return { alerts: alerts.filter((a) => !snoozed.has(a.type)), acknowledgments: holding, changed };
+}
@@ -0,0 +1,115 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockGetRules, mockSaveRules, mockRecordEvent } = vi.hoisted(() => ({
+  mockGetRules: vi.fn(),
+  mockSaveRules: vi.fn(),
+  mockRecordEvent: vi.fn(),
+}));
+
+vi.mock("./rules-store.js", () => ({
+  getAlertRules: mockGetRules,
+  saveAlertRules: mockSaveRules,
+}));
+
+vi.mock("./acknowledgment-store.js", () => ({
+  getAlertAcknowledgments: vi.fn().mockResolvedValue([
+    { type: "high", acknowledgedAt: 0, until: 1 },
+    { type: "urgentLowSoon", acknowledgedAt: 0, until: Number.MAX_SAFE_INTEGER },
+  ]),
+}));
+
+vi.mock("../events/store.js", () => ({
+  recordEvent: mockRecordEvent,
+}));
+
+vi.mock("../display/config-store.js", () => ({
+  getDisplayConfig: vi.fn().mockResolvedValue({ activeLayout: "day" }),
+}));
+
+import { handler, applyRulesUpdate } from "./api";
+import { DEFAULT_ALERT_RULES } from "./rules";
+
+function createEvent(method: string, body?: unknown): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("applyRulesUpdate", () => {
+  it("merges a partial update", () => {
+    const result = applyRulesUpdate(DEFAULT_ALERT_RULES, { high: { quietHours: { start: "23:00", end: "06:00" } } });
+    expect(result).toMatchObject({ high: { enabled: true, quietHours: { start: "23:00", end: "06:00" } } });
+  });
+
+  it("removes quiet hours with null", () => {
+    const result = applyRulesUpdate(DEFAULT_ALERT_RULES, { high: { quietHours: null } });
+    expect(typeof result !== "string" && result.high.quietHours).toBeFalsy();
+  });
+
+  it("rejects unknown types, bad times, and disabling urgent alerts", () => {
+    expect(applyRulesUpdate(DEFAULT_ALERT_RULES, { party: {} })).toMatch(/unknown/);
+    expect(applyRulesUpdate(DEFAULT_ALERT_RULES, { high: { quietHours: { start: "25:00", end: "07:00" } } })).toMatch(/HH:MM/);
+    expect(applyRulesUpdate(DEFAULT_ALERT_RULES, { urgentLowSoon: { enabled: false } })).toMatch(/cannot be disabled/);
+  });
+
+  it("sets rate alert speeds, and only on rate alerts", () => {
+    const result = applyRulesUpdate(DEFAULT_ALERT_RULES, { fallingFast: { ratePerMinute: 2 } });
+    expect(result).toMatchObject({ fallingFast: { enabled: true, ratePerMinute: 2 } });
+    expect(applyRulesUpdate(DEFAULT_ALERT_RULES, { fallingFast: { ratePerMinute: 0.5 } })).toMatch(/ratePerMinute/);
+    expect(applyRulesUpdate(DEFAULT_ALERT_RULES, { high: { ratePerMinute: 2 } })).toMatch(/no ratePerMinute/);
+  });
+
+  it("sets and validates the signal loss gap", () => {
+    expect(applyRulesUpdate(DEFAULT_ALERT_RULES, { signalLoss: { enabled: true, gapMinutes: 30 } }))
+      .toMatchObject({ signalLoss: { enabled: true, gapMinutes: 30 } });
+    expect(applyRulesUpdate(DEFAULT_ALERT_RULES, { signalLoss: { gapMinutes: 5 } })).toMatch(/gapMinutes/);
+    expect(applyRulesUpdate(DEFAULT_ALERT_RULES, { high: { gapMinutes: 30 } })).toMatch(/no gapMinutes/);
+  });
+});
+
+describe("alerts API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGetRules.mockResolvedValue(DEFAULT_ALERT_RULES);
+    mockSaveRules.mockResolvedValue(undefined);
+  });
+
+  it("returns rules and suppression status on GET", async () => {
+    const { statusCode, body } = await invoke(createEvent("GET"));
+
+    expect(statusCode).toBe(200);
+    expect(body.rules.high.quietHours).toEqual({ start: "22:00", end: "07:00" });
+    expect(body.status.map((s: { type: string }) => s.type)).toEqual(["urgentLow", "urgentLowSoon", "fallingFast", "high", "risingFast", "signalLoss"]);
+  });
+
+  it("lists snoozes that haven't run out on GET", async () => {
+    const { body } = await invoke(createEvent("GET"));
+
+    expect(body.acknowledgments.map((a: { type: string }) => a.type)).toEqual(["urgentLowSoon"]);
+  });
+
+  it("saves updated rules on POST", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { rules: { high: { enabled: false } } }));
+
+    expect(statusCode).toBe(200);
+    expect(mockSaveRules).toHaveBeenCalledWith(expect.objectContaining({ high: expect.objectContaining({ enabled: false }) }));
+    expect(mockRecordEvent).toHaveBeenCalledWith(
+      expect.objectContaining({ category: "config", subject: "alerts", detail: '{"high":{"enabled":false}}' })
+    );
+  });
+
+  it("rejects invalid updates", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { rules: "nope" }));
+
+    expect(statusCode).toBe(400);
+    expect(mockSaveRules).not.toHaveBeenCalled();
+    expect(mockRecordEvent).not.toHaveBeenCalled();
+  });
+});
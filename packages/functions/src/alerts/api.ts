@@ -0,0 +1,163 @@
+/**
+ * Alerts API
+ *
+ * GET  /alerts - alert rules, which are currently suppressed and why, and
+ *                snoozed alerts (see acknowledgment-api.ts)
+ * POST /alerts - update rules
+ *
+ * Body: { "rules": { "high": { "enabled": true, "quietHours": { "start": "22:00", "end": "07:00" } } } }
+ * Pass "quietHours": null to remove a rule's quiet hours. Quiet hours are in
+ * the display's timezone. The rate alerts (fallingFast, risingFast) also take
+ * "ratePerMinute", the speed in mg/dL per minute that raises them, and
+ * signalLoss (off by default) takes "gapMinutes", how long without a reading.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { parseTimeOfDay } from "../rendering/layouts.js";
+import { DEFAULT_TIMEZONE } from "../rendering/zoned-time.js";
+import { getDisplayConfig } from "../display/config-store.js";
+import { getRuleStatus, URGENT_ALERT_TYPES } from "./rules.js";
+import { getAlertRules, saveAlertRules } from "./rules-store.js";
+import { getAlertAcknowledgments } from "./acknowledgment-store.js";
+import { recordEvent } from "../events/store.js";
+import { configChangedEvent } from "../events/transitions.js";
+import {
+  ALERT_TYPES,
+  RATE_ALERT_TYPES,
+  type AlertRule,
+  type AlertRules,
+  type AlertType,
+  type RateAlertType,
+} from "./types.js";
+
+/** Rate alert speeds outside this range are noise or never fire (mg/dL per minute) */
+const MIN_RATE_PER_MINUTE = 1;
+const MAX_RATE_PER_MINUTE = 10;
+
+/** Shorter gaps are a missed reading or two; longer ones are too late to help */
+const MIN_GAP_MINUTES = 15;
+const MAX_GAP_MINUTES = 240;
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * Apply a rules update from a request body on top of the current rules.
+ * Returns the updated rules, or an error message.
+ */
+export function applyRulesUpdate(current: AlertRules, update: unknown): AlertRules | string {
+  if (typeof update !== "object" || update === null || Array.isArray(update)) {
+    return "rules must be an object keyed by alert type";
+  }
+
+  const next: AlertRules = { ...current };
+  for (const [type, patch] of Object.entries(update as Record<string, unknown>)) {
+    if (!ALERT_TYPES.includes(type as AlertType)) {
+      return `unknown alert type: ${type}`;
+    }
+    if (typeof patch !== "object" || patch === null) {
+      return `rule for ${type} must be an object`;
+    }
+
+    const { enabled, quietHours, ratePerMinute, gapMinutes } = patch as {
+      enabled?: unknown;
+      quietHours?: unknown;
+      ratePerMinute?: unknown;
+      gapMinutes?: unknown;
+    };
+    const rule: AlertRule = { ...current[type as AlertType] };
+
+    if (enabled !== undefined) {
+      if (typeof enabled !== "boolean") return `${type}.enabled must be a boolean`;
+      if (!enabled && URGENT_ALERT_TYPES.has(type as AlertType)) {
+        return `${type} is urgent and cannot be disabled`;
+      }
+      rule.enabled = enabled;
+    }
+
+    if (quietHours === null) {
+      delete rule.quietHours;
+    } else if (quietHours !== undefined) {
+      const { start, end } = quietHours as { start?: unknown; end?: unknown };
+      if (typeof start !== "string" || parseTimeOfDay(start) === null ||
+          typeof end !== "string" || parseTimeOfDay(end) === null) {
+        return `${type}.quietHours needs start and end in HH:MM`;
+      }
+      rule.quietHours = { start, end };
+    }
+
+    if (ratePerMinute !== undefined) {
+      if (!RATE_ALERT_TYPES.includes(type as RateAlertType)) {
+        return `${type} has no ratePerMinute`;
+      }
+      if (typeof ratePerMinute !== "number" || ratePerMinute < MIN_RATE_PER_MINUTE || ratePerMinute > MAX_RATE_PER_MINUTE) {
+        return `${type}.ratePerMinute must be from ${MIN_RATE_PER_MINUTE} to ${MAX_RATE_PER_MINUTE} mg/dL per minute`;
+      }
+      rule.ratePerMinute = ratePerMinute;
+    }
+
+    if (gapMinutes !== undefined) {
+      if (type !== "signalLoss") return `${type} has no gapMinutes`;
+      if (typeof gapMinutes !== "number" || !Number.isInteger(gapMinutes) ||
+          gapMinutes < MIN_GAP_MINUTES || gapMinutes > MAX_GAP_MINUTES) {
+        return `${type}.gapMinutes must be a whole number from ${MIN_GAP_MINUTES} to ${MAX_GAP_MINUTES}`;
+      }
+      rule.gapMinutes = gapMinutes;
+    }
+
+    next[type as AlertType] = rule;
+  }
+  return next;
+}
+
+async function displayTimezone(): Promise<string> {
+  return (await getDisplayConfig()).timezone ?? DEFAULT_TIMEZONE;
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  if (method === "GET") {
+    const [rules, timezone, acknowledgments] = await Promise.all([
+      getAlertRules(),
+      displayTimezone(),
+      getAlertAcknowledgments(),
+    ]);
+    const now = Date.now();
+    return json(200, {
+      rules,
+      status: getRuleStatus(rules, now, timezone),
+      acknowledgments: acknowledgments.filter((a) => a.until > now),
+    });
+  }
+
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  let body: { rules?: unknown };
+  try {
+    body = JSON.parse(event.body || "{}");
+  } catch {
+    return json(400, { error: "Invalid JSON" });
+  }
+
+  const result = applyRulesUpdate(await getAlertRules(), body.rules);
+  if (typeof result === "string") {
+    return json(400, { error: result });
+  }
+
+  await saveAlertRules(result);
+  await recordEvent(configChangedEvent("alerts", body.rules));
+  console.log(`Alert rules updated: ${JSON.stringify(result)}`);
+
+  return json(200, { rules: result, status: getRuleStatus(result, Date.now(), await displayTimezone()) });
+};
@@ -0,0 +1,134 @@
+import { describe, it, expect } from "vitest";
+import {
+  calculateRateOfChange,
+  predictUrgentLow,
+  evaluateAlerts,
+  DEFAULT_RATE_THRESHOLDS,
+  type AlertReading,
+} from "./engine";
+
+const NOW = new Date("2026-03-02T12:00:00Z").getTime();
+const minutesAgo = (n: number) => NOW - n * 60 * 1000;
+
+describe("calculateRateOfChange", () => {
+  it("fits a slope over the last 15 minutes", () => {
+    const samples = [
+      { timestamp: minutesAgo(10), glucose: 100 },
+      { timestamp: minutesAgo(5), glucose: 90 },
+      { timestamp: minutesAgo(0), glucose: 80 },
+    ];
+    expect(calculateRateOfChange(samples, NOW)).toBeCloseTo(-2);
+  });
+
+  it("ignores readings outside the window", () => {
+    const samples = [
+      { timestamp: minutesAgo(60), glucose: 300 },
+      { timestamp: minutesAgo(5), glucose: 100 },
+      { timestamp: minutesAgo(0), glucose: 105 },
+    ];
+    expect(calculateRateOfChange(samples, NOW)).toBeCloseTo(1);
+  });
+
+  it("returns null with too little data", () => {
+    expect(calculateRateOfChange([], NOW)).toBeNull();
+    expect(calculateRateOfChange([{ timestamp: NOW, glucose: 100 }], NOW)).toBeNull();
+    expect(
+      calculateRateOfChange([{ timestamp: minutesAgo(1), glucose: 100 }, { timestamp: NOW, glucose: 90 }], NOW)
+    ).toBeNull();
+  });
+});
+
+describe("predictUrgentLow", () => {
+  it("returns minutes until crossing within the horizon", () => {
+    expect(predictUrgentLow(85, -2)).toBe(15);
+  });
+
+  it("returns null beyond the horizon or when not falling", () => {
+    expect(predictUrgentLow(120, -2)).toBeNull();
+    expect(predictUrgentLow(80, 0)).toBeNull();
+    expect(predictUrgentLow(80, 1)).toBeNull();
+  });
+
+  it("returns null for an actual urgent low", () => {
+    expect(predictUrgentLow(50, -3)).toBeNull();
+  });
+});
+
+describe("evaluateAlerts", () => {
+  const falling: AlertReading = { glucose: 75, timestamp: NOW, delta: -10, isStale: false };
+  const history = [
+    { timestamp: minutesAgo(10), glucose: 95 },
+    { timestamp: minutesAgo(5), glucose: 85 },
+  ];
+
+  it("raises urgentLowSoon when the projection crosses 55", () => {
+    const alerts = evaluateAlerts(falling, history, NOW);
+    expect(alerts).toHaveLength(1);
+    expect(alerts[0]).toMatchObject({ type: "urgentLowSoon", severity: "urgent", detail: "BELOW 55 IN 10M" });
+  });
+
+  it("falls back to the reading delta without history", () => {
+    expect(evaluateAlerts(falling, [], NOW)[0]?.type).toBe("urgentLowSoon");
+  });
+
+  it("does not use the delta when the trend is not computable", () => {
+    expect(evaluateAlerts({ ...falling, trendComputable: false }, [], NOW)).toEqual([]);
+  });
+
+  it("raises urgentLow below 55", () => {
+    const alerts = evaluateAlerts({ glucose: 54, timestamp: NOW, delta: 0, isStale: false }, [], NOW);
+    expect(alerts).toEqual([
+      expect.objectContaining({ type: "urgentLow", severity: "urgent", title: "URGENT LOW", detail: "BELOW 55" }),
+    ]);
+    expect(evaluateAlerts({ glucose: 55, timestamp: NOW, delta: 0, isStale: false }, [], NOW)).toEqual([]);
+  });
+
+  it("raises a high warning above 250", () => {
+    const alerts = evaluateAlerts({ glucose: 280, timestamp: NOW, delta: 0, isStale: false }, [], NOW);
+    expect(alerts).toEqual([expect.objectContaining({ type: "high", severity: "warning" })]);
+  });
+
+  it("raises fallingFast and risingFast on a sustained 3 mg/dL/min trend", () => {
+    const fast = [
+      { timestamp: minutesAgo(10), glucose: 180 },
+      { timestamp: minutesAgo(5), glucose: 160 },
+    ];
+    const alerts = evaluateAlerts({ glucose: 145, timestamp: NOW, delta: -15, isStale: false }, fast, NOW);
+    expect(alerts).toEqual([
+      expect.objectContaining({ type: "fallingFast", title: "FALLING FAST", detail: "-3.5 MG/DL/MIN" }),
+    ]);
+
+    const rising = fast.map((s) => ({ ...s, glucose: 300 - s.glucose }));
+    const up = evaluateAlerts({ glucose: 155, timestamp: NOW, delta: 15, isStale: false }, rising, NOW);
+    expect(up).toEqual([expect.objectContaining({ type: "risingFast", detail: "+3.5 MG/DL/MIN" })]);
+  });
+
+  it("needs stored history for rate alerts, and honors custom speeds", () => {
+    const reading: AlertReading = { glucose: 145, timestamp: NOW, delta: -20, isStale: false };
+    expect(evaluateAlerts(reading, [], NOW)).toEqual([]);
+    expect(evaluateAlerts(reading, [{ timestamp: minutesAgo(5), glucose: 165 }], NOW)).toEqual([]);
+
+    const gentle = [
+      { timestamp: minutesAgo(10), glucose: 165 },
+      { timestamp: minutesAgo(5), glucose: 155 },
+    ];
+    expect(evaluateAlerts(reading, gentle, NOW)).toEqual([]);
+    expect(evaluateAlerts(reading, gentle, NOW, { fallingFast: 2, risingFast: 3 })[0]?.type).toBe("fallingFast");
+  });
+
+  it("raises signal loss once readings have stopped for the gap", () => {
+    const quiet: AlertReading = { glucose: 120, timestamp: minutesAgo(75), delta: 0, isStale: true };
+
+    expect(evaluateAlerts(quiet, history, NOW)).toEqual([
+      expect.objectContaining({ type: "signalLoss", title: "NO READINGS", detail: "FOR 1H15M" }),
+    ]);
+    expect(evaluateAlerts(quiet, history, NOW, DEFAULT_RATE_THRESHOLDS, 90)).toEqual([]);
+    expect(evaluateAlerts({ ...quiet, sourceUnreachable: true }, history, NOW)).toEqual([]);
+  });
+
+  it("raises nothing for stable, stale, or missing readings", () => {
+    expect(evaluateAlerts({ ...falling, delta: 0 }, [], NOW)).toEqual([]);
+    expect(evaluateAlerts({ ...falling, isStale: true }, history, NOW)).toEqual([]);
+    expect(evaluateAlerts(null, history, NOW)).toEqual([]);
+  });
+});
@@ -0,0 +1,218 @@
+/**
+ * Glucose alert engine
+ *
+ * Evaluates the current reading and recent history into a list of active
+ * alerts, most important first. Pure functions - the compositor calls this
+ * each minute and passes the result to the frame composer.
+ */
+
+import { formatGap } from "../rendering/glucose-state.js";
+import type { GlucoseAlert, GlucoseSample, RateAlertType } from "./types.js";
+
+/** Urgent low threshold (mg/dL), matches the display range classification */
+export const URGENT_LOW_MGDL = 55;
+
+/** High alert threshold (mg/dL), the display's "very high" range */
+export const HIGH_MGDL = 250;
+
+/** How far ahead the predictive low alert looks */
+export const PREDICTION_HORIZON_MINUTES = 20;
+
+/** Readings used for the rate of change */
+const RATE_WINDOW_MINUTES = 15;
+
+/** Minimum span of readings for a usable rate (one CGM interval) */
+const MIN_RATE_SPAN_MINUTES = 4;
+
+/** Rate alerts need a sustained trend: readings across most of the window */
+const SUSTAINED_RATE_SPAN_MINUTES = 10;
+
+/** Default speeds for the rate alerts (mg/dL per minute) */
+export const DEFAULT_RATE_THRESHOLDS: Record<RateAlertType, number> = {
+  fallingFast: 3,
+  risingFast: 3,
+};
+
+/** Default gap in readings that raises the signal loss alert */
+export const DEFAULT_SIGNAL_LOSS_MINUTES = 60;
+
+/**
+ * Current reading as seen by the alert engine
+ */
+export interface AlertReading {
+  glucose: number;
+  timestamp: number;
+  /** Change from the previous reading (mg/dL per ~5 min) */
+  delta: number;
+  isStale: boolean;
+  /** False when Dexcom reports NotComputable/RateOutOfRange */
+  trendComputable?: boolean;
+  /** Dexcom wasn't reached, so an old reading says nothing about the sensor */
+  sourceUnreachable?: boolean;
+}
+
+/**
+ * Calculate the rate of change in mg/dL per minute using a least-squares fit
+ * over readings in the last 15 minutes. Returns null with too little data,
+ * or readings spanning less than `minSpanMinutes`.
+ */
+export function calculateRateOfChange(
+  samples: GlucoseSample[],
+  now: number = Date.now(),
+  minSpanMinutes: number = MIN_RATE_SPAN_MINUTES
+): number | null {
+  const cutoff = now - RATE_WINDOW_MINUTES * 60 * 1000;
+  const recent = samples.filter((s) => s.timestamp >= cutoff && s.timestamp <= now);
+  if (recent.length < 2) return null;
+
+  const minutes = recent.map((s) => (s.timestamp - cutoff) / 60000);
+  const span = Math.max(...minutes) - Math.min(...minutes);
+  if (span < minSpanMinutes) return null;
+
+  const n = recent.length;
+  const meanX = minutes.reduce((sum, x) => sum + x, 0) / n;
+  const meanY = recent.reduce((sum, s) => sum + s.glucose, 0) / n;
+
+  let num = 0;
+  let den = 0;
+  for (let i = 0; i < n; i++) {
+    num += (minutes[i] - meanX) * (recent[i].glucose - meanY);
+    den += (minutes[i] - meanX) ** 2;
+  }
+  return den === 0 ? null : num / den;
+}
+
+/**
+ * Rate of change for a reading: from history when available, otherwise from
+ * the reading's own delta (5-minute CGM interval).
+ */
+function readingRate(reading: AlertReading, history: GlucoseSample[], now: number): number | null {
+  const samples = [...history, { timestamp: reading.timestamp, glucose: reading.glucose }];
+  const rate = calculateRateOfChange(samples, now);
+  if (rate !== null) return rate;
+  return reading.trendComputable === false ? null : reading.delta / 5;
+}
+
+/**
+ * Predict whether glucose will cross the urgent-low threshold within the
+ * prediction horizon. Already-urgent-low readings don't count - that's an
+ * actual low, raised as urgentLow.
+ * Returns minutes until the crossing, or null.
+ */
+export function predictUrgentLow(glucose: number, ratePerMinute: number): number | null {
+  if (glucose < URGENT_LOW_MGDL || ratePerMinute >= 0) return null;
+  const minutes = (glucose - URGENT_LOW_MGDL) / -ratePerMinute;
+  return minutes <= PREDICTION_HORIZON_MINUTES ? Math.max(1, Math.round(minutes)) : null;
+}
+
+/**
+ * Format a rate for a banner, e.g. "-3.4 MG/DL/MIN"
+ */
+function formatRate(ratePerMinute: number): string {
+  return `${ratePerMinute > 0 ? "+" : ""}${ratePerMinute.toFixed(1)} MG/DL/MIN`;
+}
+
+/**
+ * Alert for a sensor that has gone quiet (sensor change, warm-up, lost
+ * signal) for at least `gapMinutes`, or null
+ */
+export function evaluateSignalLoss(reading: AlertReading, now: number, gapMinutes: number): GlucoseAlert | null {
+  if (!reading.isStale || reading.sourceUnreachable) return null;
+  const gap = now - reading.timestamp;
+  if (gap < gapMinutes * 60 * 1000) return null;
+  return {
+    type: "signalLoss",
+    severity: "warning",
+    title: "NO READINGS",
+    detail: `FOR ${formatGap(gap).toUpperCase()}`,
+    raisedAt: now,
+  };
+}
+
+/**
+ * Evaluate all alerts for the current reading. Rate alerts are raised at
+ * `rateThresholds` (mg/dL per minute), signal loss after `signalLossMinutes`
+ * without a reading. A stale reading raises nothing else.
+ */
+export function evaluateAlerts(
+  reading: AlertReading | null,
+  history: GlucoseSample[] = [],
+  now: number = Date.now(),
+  rateThresholds: Record<RateAlertType, number> = DEFAULT_RATE_THRESHOLDS,
+  signalLossMinutes: number = DEFAULT_SIGNAL_LOSS_MINUTES
+): GlucoseAlert[] {
+  if (!reading) return [];
+  if (reading.isStale) {
+    const signalLoss = evaluateSignalLoss(reading, now, signalLossMinutes);
+    return signalLoss ? [signalLoss] : [];
+  }
+
+  const alerts: GlucoseAlert[] = [];
+  const rate = readingRate(reading, history, now);
+  // Rate alerts only trust a trend across the stored readings, not one
+  // reading's delta, so a single noisy reading doesn't raise them
+  const sustainedRate = calculateRateOfChange(
+    [...history, { timestamp: reading.timestamp, glucose: reading.glucose }],
+    now,
+    SUSTAINED_RATE_SPAN_MINUTES
+  );
+
+  if (reading.glucose < URGENT_LOW_MGDL) {
+    alerts.push({
+      type: "urgentLow",
+      severity: "urgent",
+      title: "URGENT LOW",
+      detail: `BELOW ${URGENT_LOW_MGDL}`,
+      raisedAt: now,
+    });
+  }
+
+  if (rate !== null) {
+    const minutes = predictUrgentLow(reading.glucose, rate);
+    if (minutes !== null) {
+      alerts.push({
+        type: "urgentLowSoon",
+        severity: "urgent",
+        title: "LOW SOON",
+        detail: `BELOW ${URGENT_LOW_MGDL} IN ${minutes}M`,
+        raisedAt: now,
+      });
+    }
+  }
+
+  if (sustainedRate !== null && sustainedRate <= -rateThresholds.fallingFast) {
+    alerts.push({
+      type: "fallingFast",
+      severity: "warning",
+      title: "FALLING FAST",
+      detail: formatRate(sustainedRate),
+      raisedAt: now,
+    });
+  }
+
+  if (reading.glucose > HIGH_MGDL) {
+    alerts.push({
+      type: "high",
+      severity: "warning",
+      title: "HIGH",
+      detail: `ABOVE ${HIGH_MGDL}`,
+      raisedAt: now,
+    });
+  }
+
+  if (sustainedRate !== null && sustainedRate >= rateThresholds.risingFast) {
+    alerts.push({
+      type: "risingFast",
+      severity: "warning",
+      title: "RISING FAST",
+      detail: formatRate(sustainedRate),
+      raisedAt: now,
+    });
+  }
+
+  return alerts.sort((a, b) => severityRank(a) - severityRank(b));
+}
+
+function severityRank(alert: GlucoseAlert): number {
+  return alert.severity === "urgent" ? 0 : 1;
+}
@@ -0,0 +1,47 @@
+/**
+ * Alert rules store
+ * Persists per-alert-type rules (enabled, quiet hours) in DynamoDB.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import { DEFAULT_ALERT_RULES } from "./rules.js";
+import type { AlertRules } from "./types.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** DynamoDB key for the alert rules item */
+const RULES_KEY = { pk: "ALERT_CONFIG", sk: "RULES" };
+
+/**
+ * Get alert rules, with defaults for any type not saved yet.
+ */
+export async function getAlertRules(): Promise<AlertRules> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: RULES_KEY,
+    })
+  );
+
+  const saved = (result.Item?.rules ?? {}) as Partial<AlertRules>;
+  return { ...DEFAULT_ALERT_RULES, ...saved };
+}
+
+/**
+ * Save alert rules.
+ */
+export async function saveAlertRules(rules: AlertRules): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        ...RULES_KEY,
+        rules,
+        updatedAt: new Date().toISOString(),
+      },
+    })
+  );
+}
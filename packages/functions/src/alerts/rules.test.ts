@@ -0,0 +1,120 @@
+import { describe, it, expect } from "vitest";
+import {
+  isInQuietHours,
+  getRuleStatus,
+  applySuppression,
+  DEFAULT_ALERT_RULES,
+  rateThresholds,
+  signalLossMinutes,
+} from "./rules";
+import type { AlertRules, GlucoseAlert } from "./types";
+
+const TZ = "America/Los_Angeles";
+const at = (time: string) => new Date(`2026-03-02T${time}:00-08:00`).getTime();
+
+const high: GlucoseAlert = { type: "high", severity: "warning", title: "HIGH", detail: "ABOVE 250", raisedAt: 0 };
+const urgentLow: GlucoseAlert = {
+  type: "urgentLow",
+  severity: "urgent",
+  title: "URGENT LOW",
+  detail: "BELOW 55",
+  raisedAt: 0,
+};
+const lowSoon: GlucoseAlert = {
+  type: "urgentLowSoon",
+  severity: "urgent",
+  title: "LOW SOON",
+  detail: "BELOW 55 IN 10M",
+  raisedAt: 0,
+};
+
+describe("isInQuietHours", () => {
+  it("handles same-day windows", () => {
+    const window = { start: "13:00", end: "15:00" };
+    expect(isInQuietHours(window, at("13:00"), TZ)).toBe(true);
+    expect(isInQuietHours(window, at("15:00"), TZ)).toBe(false);
+  });
+
+  it("handles windows that wrap past midnight", () => {
+    const window = { start: "22:00", end: "07:00" };
+    expect(isInQuietHours(window, at("23:30"), TZ)).toBe(true);
+    expect(isInQuietHours(window, at("03:00"), TZ)).toBe(true);
+    expect(isInQuietHours(window, at("12:00"), TZ)).toBe(false);
+  });
+
+  it("never matches malformed or empty windows", () => {
+    expect(isInQuietHours({ start: "bad", end: "07:00" }, at("03:00"), TZ)).toBe(false);
+    expect(isInQuietHours({ start: "07:00", end: "07:00" }, at("07:00"), TZ)).toBe(false);
+  });
+});
+
+describe("getRuleStatus", () => {
+  it("reports highs suppressed during default quiet hours", () => {
+    const status = getRuleStatus(DEFAULT_ALERT_RULES, at("23:00"), TZ);
+    expect(status.find((s) => s.type === "high")).toMatchObject({
+      suppressed: true,
+      reason: "quiet hours 22:00-07:00",
+    });
+  });
+
+  it("lets urgent alerts break through quiet hours", () => {
+    const rules: AlertRules = {
+      ...DEFAULT_ALERT_RULES,
+      urgentLowSoon: { enabled: false, quietHours: { start: "22:00", end: "07:00" } },
+    };
+    const status = getRuleStatus(rules, at("23:00"), TZ);
+    expect(status.find((s) => s.type === "urgentLowSoon")).toMatchObject({
+      suppressed: false,
+      reason: "urgent - breaks through quiet hours",
+    });
+  });
+
+  it("never suppresses an actual urgent low", () => {
+    const rules: AlertRules = {
+      ...DEFAULT_ALERT_RULES,
+      urgentLow: { enabled: false, quietHours: { start: "22:00", end: "07:00" } },
+    };
+    expect(getRuleStatus(rules, at("23:00"), TZ).find((s) => s.type === "urgentLow")).toMatchObject({
+      enabled: true,
+      suppressed: false,
+    });
+    expect(applySuppression([urgentLow, high], rules, at("23:00"), TZ)).toEqual([urgentLow]);
+  });
+
+  it("reports disabled rules", () => {
+    const rules: AlertRules = { ...DEFAULT_ALERT_RULES, high: { enabled: false } };
+    expect(getRuleStatus(rules, at("12:00"), TZ).find((s) => s.type === "high")).toMatchObject({
+      suppressed: true,
+      reason: "disabled",
+    });
+  });
+});
+
+describe("applySuppression", () => {
+  it("drops suppressed alerts and keeps urgent ones", () => {
+    expect(applySuppression([lowSoon, high], DEFAULT_ALERT_RULES, at("23:00"), TZ)).toEqual([lowSoon]);
+    expect(applySuppression([lowSoon, high], DEFAULT_ALERT_RULES, at("12:00"), TZ)).toEqual([lowSoon, high]);
+  });
+});
+
+describe("rateThresholds", () => {
+  it("reads the rate alert speeds from the rules", () => {
+    expect(rateThresholds(DEFAULT_ALERT_RULES)).toEqual({ fallingFast: 3, risingFast: 3 });
+    expect(
+      rateThresholds({ ...DEFAULT_ALERT_RULES, fallingFast: { enabled: true, ratePerMinute: 2 } })
+    ).toEqual({ fallingFast: 2, risingFast: 3 });
+  });
+});
+
+describe("signalLossMinutes", () => {
+  it("reads the gap from the rules", () => {
+    expect(signalLossMinutes(DEFAULT_ALERT_RULES)).toBe(60);
+    expect(signalLossMinutes({ ...DEFAULT_ALERT_RULES, signalLoss: { enabled: true, gapMinutes: 30 } })).toBe(30);
+  });
+
+  it("is off until enabled", () => {
+    const signalLoss: GlucoseAlert = { type: "signalLoss", severity: "warning", title: "NO READINGS", detail: "FOR 1H", raisedAt: 0 };
+
+    expect(applySuppression([signalLoss], DEFAULT_ALERT_RULES, 0, TZ)).toEqual([]);
+  });
+});
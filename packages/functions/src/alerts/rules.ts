@@ -0,0 +1,122 @@
+/**
+ * Alert rules: enabling and quiet hours
+ *
+ * Quiet hours hide non-urgent alerts (e.g. highs overnight). Urgent alerts
+ * always break through, whatever the rule says.
+ */
+
+import { minutesOfDay, parseTimeOfDay } from "../rendering/layouts.js";
+import type {
+  AlertRules,
+  AlertRuleStatus,
+  AlertType,
+  GlucoseAlert,
+  QuietHours,
+  RateAlertType,
+} from "./types.js";
+import { ALERT_TYPES } from "./types.js";
+import { DEFAULT_RATE_THRESHOLDS, DEFAULT_SIGNAL_LOSS_MINUTES } from "./engine.js";
+
+/** Alert types that can never be disabled or silenced */
+export const URGENT_ALERT_TYPES: ReadonlySet<AlertType> = new Set(["urgentLow", "urgentLowSoon"]);
+
+/** Rules used before anything has been saved */
+export const DEFAULT_ALERT_RULES: AlertRules = {
+  urgentLow: { enabled: true },
+  urgentLowSoon: { enabled: true },
+  fallingFast: { enabled: true, ratePerMinute: DEFAULT_RATE_THRESHOLDS.fallingFast },
+  high: { enabled: true, quietHours: { start: "22:00", end: "07:00" } },
+  risingFast: {
+    enabled: true,
+    quietHours: { start: "22:00", end: "07:00" },
+    ratePerMinute: DEFAULT_RATE_THRESHOLDS.risingFast,
+  },
+  // Off until asked for: the display already shows "NO SIGNAL" for a gap
+  signalLoss: { enabled: false, gapMinutes: DEFAULT_SIGNAL_LOSS_MINUTES },
+};
+
+/**
+ * Speeds that raise the rate alerts, from the rules
+ */
+export function rateThresholds(rules: AlertRules): Record<RateAlertType, number> {
+  return {
+    fallingFast: rules.fallingFast?.ratePerMinute ?? DEFAULT_RATE_THRESHOLDS.fallingFast,
+    risingFast: rules.risingFast?.ratePerMinute ?? DEFAULT_RATE_THRESHOLDS.risingFast,
+  };
+}
+
+/**
+ * Gap in readings that raises the signal loss alert, from the rules
+ */
+export function signalLossMinutes(rules: AlertRules): number {
+  return rules.signalLoss?.gapMinutes ?? DEFAULT_SIGNAL_LOSS_MINUTES;
+}
+
+/**
+ * Check whether a time falls inside a quiet window.
+ * The window includes start and excludes end; start > end wraps past midnight.
+ * Malformed windows never match.
+ */
+export function isInQuietHours(quietHours: QuietHours, now: number, timezone: string): boolean {
+  const start = parseTimeOfDay(quietHours.start);
+  const end = parseTimeOfDay(quietHours.end);
+  if (start === null || end === null || start === end) return false;
+
+  const minutes = minutesOfDay(now, timezone);
+  return start < end
+    ? minutes >= start && minutes < end
+    : minutes >= start || minutes < end;
+}
+
+/**
+ * Describe the current suppression state of every rule.
+ */
+export function getRuleStatus(
+  rules: AlertRules,
+  now: number,
+  timezone: string
+): AlertRuleStatus[] {
+  return ALERT_TYPES.map((type) => {
+    const rule = rules[type] ?? DEFAULT_ALERT_RULES[type];
+    const urgent = URGENT_ALERT_TYPES.has(type);
+    const quiet = rule.quietHours && isInQuietHours(rule.quietHours, now, timezone);
+
+    if (urgent) {
+      return {
+        type,
+        enabled: true,
+        suppressed: false,
+        reason: quiet ? "urgent - breaks through quiet hours" : null,
+      };
+    }
+    if (!rule.enabled) {
+      return { type, enabled: false, suppressed: true, reason: "disabled" };
+    }
+    if (quiet && rule.quietHours) {
+      return {
+        type,
+        enabled: true,
+        suppressed: true,
+        reason: `quiet hours ${rule.quietHours.start}-${rule.quietHours.end}`,
+      };
+    }
+    return { type, enabled: true, suppressed: false, reason: null };
+  });
+}
+
+/**
+ * Drop alerts whose rule is currently suppressed.
+ */
+export function applySuppression(
+  alerts: GlucoseAlert[],
+  rules: AlertRules,
+  now: number,
+  timezone: string
+): GlucoseAlert[] {
+  const suppressed = new Set(
+    getRuleStatus(rules, now, timezone)
+      .filter((status) => status.suppressed)
+      .map((status) => status.type)
+  );
+  return alerts.filter((alert) => !suppressed.has(alert.type));
+}
@@ -0,0 +1,84 @@
+/**
+ * Glucose alert types
+ */
+
+/** Kinds of alert the engine can raise */
+export type AlertType = "urgentLow" | "urgentLowSoon" | "fallingFast" | "high" | "risingFast" | "signalLoss";
+
+/** All alert types, in display priority order */
+export const ALERT_TYPES: AlertType[] = ["urgentLow", "urgentLowSoon", "fallingFast", "high", "risingFast", "signalLoss"];
+
+/** Alerts raised by how fast glucose is moving rather than where it is */
+export type RateAlertType = "fallingFast" | "risingFast";
+
+export const RATE_ALERT_TYPES: RateAlertType[] = ["fallingFast", "risingFast"];
+
+/**
+ * An active alert to show on the display
+ */
+export interface GlucoseAlert {
+  type: AlertType;
+  /** Urgent alerts take over the banner; warnings can be outranked */
+  severity: "urgent" | "warning";
+  /** Short banner headline (tiny font, fits 64px) */
+  title: string;
+  /** Second banner line with detail */
+  detail: string;
+  /** When the alert condition was evaluated (ms) */
+  raisedAt: number;
+}
+
+/**
+ * A glucose sample used for alert evaluation
+ */
+export interface GlucoseSample {
+  timestamp: number;
+  glucose: number;
+}
+
+/**
+ * Daily quiet window in local "HH:MM" times; may wrap past midnight
+ */
+export interface QuietHours {
+  start: string;
+  end: string;
+}
+
+/**
+ * Per-alert-type configuration
+ */
+export interface AlertRule {
+  enabled: boolean;
+  /** Non-urgent alerts are hidden during this window */
+  quietHours?: QuietHours;
+  /** Rate alerts only: speed that raises the alert (mg/dL per minute) */
+  ratePerMinute?: number;
+  /** Signal loss only: minutes without a reading that raise the alert */
+  gapMinutes?: number;
+}
+
+export type AlertRules = Record<AlertType, AlertRule>;
+
+/**
+ * A snoozed alert type. The alert stays hidden until `until`, or until
+ * glucose moves further the wrong way than it was when first seen snoozed.
+ */
+export interface AlertAcknowledgment {
+  type: AlertType;
+  acknowledgedAt: number;
+  until: number;
+  /** Reading when the compositor first applied the snooze; the re-alert baseline */
+  glucose?: number;
+}
+
+/**
+ * Whether a rule is currently suppressed, and why
+ */
+export interface AlertRuleStatus {
+  type: AlertType;
+  enabled: boolean;
+  /** True if alerts of this type are hidden right now */
+  suppressed: boolean;
+  /** Human-readable reason for the current state, null if nothing applies */
+  reason: string | null;
+}
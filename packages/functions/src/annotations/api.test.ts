@@ -0,0 +1,124 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockAdd, mockGet, mockDelete } = vi.hoisted(() => ({
+  mockAdd: vi.fn(),
+  mockGet: vi.fn(),
+  mockDelete: vi.fn(),
+}));
+
+vi.mock("./store.js", () => ({
+  addAnnotation: mockAdd,
+  getAnnotations: mockGet,
+  deleteAnnotation: mockDelete,
+}));
+
+import { handler, parseAnnotation } from "./api";
+import { DEFAULT_PROFILE_ID } from "../profiles/profile";
+
+const NOW = new Date("2026-03-02T12:00:00Z").getTime();
+
+function createEvent(
+  method: string,
+  body?: unknown,
+  query?: Record<string, string>
+): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    queryStringParameters: query,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("parseAnnotation", () => {
+  it("defaults to now and trims the label", () => {
+    expect(parseAnnotation({ label: " sensor change " }, NOW)).toEqual({ timestamp: NOW, label: "sensor change" });
+  });
+
+  it("accepts a past ISO time", () => {
+    const result = parseAnnotation({ label: "site change", time: "2026-03-01T08:00:00Z" }, NOW);
+    expect(result).toEqual({ timestamp: Date.parse("2026-03-01T08:00:00Z"), label: "site change" });
+  });
+
+  it("rejects missing, long, and future entries", () => {
+    expect(parseAnnotation({}, NOW)).toMatch(/label/);
+    expect(parseAnnotation({ label: "x".repeat(41) }, NOW)).toMatch(/at most/);
+    expect(parseAnnotation({ label: "travel day", time: NOW + 60 * 60 * 1000 }, NOW)).toMatch(/future/);
+    expect(parseAnnotation({ label: "travel day", time: "yesterday" }, NOW)).toMatch(/ISO/);
+  });
+});
+
+describe("annotations API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGet.mockResolvedValue([]);
+    mockDelete.mockResolvedValue(true);
+    mockAdd.mockImplementation(async (timestamp: number, label: string) => ({
+      id: `${timestamp}-abc123`,
+      timestamp,
+      label,
+      createdAt: new Date().toISOString(),
+    }));
+  });
+
+  it("adds an annotation on POST", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { label: "sensor change" }));
+
+    expect(statusCode).toBe(201);
+    expect(body.annotation.label).toBe("sensor change");
+    expect(mockAdd).toHaveBeenCalledWith(expect.any(Number), "sensor change", DEFAULT_PROFILE_ID);
+  });
+
+  it("rejects invalid annotations", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { label: "" }));
+
+    expect(statusCode).toBe(400);
+    expect(mockAdd).not.toHaveBeenCalled();
+  });
+
+  it("lists annotations for the requested window", async () => {
+    const { statusCode, body } = await invoke(createEvent("GET", undefined, { hours: "48" }));
+
+    expect(statusCode).toBe(200);
+    expect(body.annotations).toEqual([]);
+    const since = mockGet.mock.calls[0][0] as number;
+    expect(Date.now() - since).toBeGreaterThanOrEqual(48 * 60 * 60 * 1000);
+  });
+
+  it("rejects an out-of-range window", async () => {
+    const { statusCode } = await invoke(createEvent("GET", undefined, { hours: "10000" }));
+    expect(statusCode).toBe(400);
+  });
+
+  it("deletes by id", async () => {
+    const { statusCode } = await invoke(createEvent("DELETE", undefined, { id: "1772400000000-abc123" }));
+
+    expect(statusCode).toBe(200);
+    expect(mockDelete).toHaveBeenCalledWith("1772400000000-abc123", DEFAULT_PROFILE_ID);
+  });
+
+  it("uses the profile from the query", async () => {
+    await invoke(createEvent("POST", { label: "site change" }, { profile: "household-2" }));
+
+    expect(mockAdd).toHaveBeenCalledWith(expect.any(Number), "site change", "household-2");
+  });
+
+  it("rejects an invalid profile", async () => {
+    const { statusCode } = await invoke(createEvent("GET", undefined, { profile: "../john" }));
+
+    expect(statusCode).toBe(400);
+    expect(mockGet).not.toHaveBeenCalled();
+  });
+
+  it("rejects a delete without a valid id", async () => {
+    mockDelete.mockResolvedValue(false);
+    const { statusCode } = await invoke(createEvent("DELETE", undefined, { id: "nope" }));
+
+    expect(statusCode).toBe(400);
+  });
+});
@@ -0,0 +1,110 @@
+/**
+ * Annotations API
+ *
+ * GET    /annotations?hours=24 - annotations from the last N hours (max 30 days)
+ * POST   /annotations          - add an annotation
+ * DELETE /annotations?id=...   - remove an annotation
+ *
+ * Body: { "label": "sensor change", "time": "2026-03-02T08:15:00Z" }
+ * `time` is optional (defaults to now) and accepts ISO strings or epoch ms.
+ * Add `?profile=<id>` to any request to use another household's annotations.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { addAnnotation, deleteAnnotation, getAnnotations } from "./store.js";
+import { parseProfileParam } from "../profiles/profile.js";
+
+const MAX_LABEL_LENGTH = 40;
+const DEFAULT_HOURS = 24;
+const MAX_HOURS = 30 * 24;
+
+/** Allow small clock skew for "now" entries */
+const MAX_FUTURE_MS = 5 * 60 * 1000;
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * Validate an annotation from a request body.
+ * Returns the timestamp and trimmed label, or an error message.
+ */
+export function parseAnnotation(
+  body: { label?: unknown; time?: unknown },
+  now: number = Date.now()
+): { timestamp: number; label: string } | string {
+  if (typeof body.label !== "string" || body.label.trim() === "") {
+    return "label is required";
+  }
+  const label = body.label.trim();
+  if (label.length > MAX_LABEL_LENGTH) {
+    return `label must be at most ${MAX_LABEL_LENGTH} characters`;
+  }
+
+  let timestamp = now;
+  if (body.time !== undefined) {
+    timestamp = typeof body.time === "number" ? body.time : Date.parse(String(body.time));
+    if (!Number.isFinite(timestamp) || timestamp <= 0) {
+      return "time must be an ISO timestamp or epoch milliseconds";
+    }
+    if (timestamp > now + MAX_FUTURE_MS) {
+      return "time is in the future";
+    }
+  }
+
+  return { timestamp: Math.round(timestamp), label };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  const profile = parseProfileParam(event.queryStringParameters);
+  if (typeof profile !== "string") {
+    return json(400, profile);
+  }
+
+  if (method === "GET") {
+    const hours = Number(event.queryStringParameters?.hours ?? DEFAULT_HOURS);
+    if (!Number.isFinite(hours) || hours <= 0 || hours > MAX_HOURS) {
+      return json(400, { error: `hours must be between 1 and ${MAX_HOURS}` });
+    }
+    const annotations = await getAnnotations(Date.now() - hours * 60 * 60 * 1000, Date.now(), profile);
+    return json(200, { annotations });
+  }
+
+  if (method === "DELETE") {
+    const id = event.queryStringParameters?.id;
+    if (!id || !(await deleteAnnotation(id, profile))) {
+      return json(400, { error: "Valid id query parameter required" });
+    }
+    return json(200, { deleted: id });
+  }
+
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  let body: { label?: unknown; time?: unknown };
+  try {
+    body = JSON.parse(event.body || "{}");
+  } catch {
+    return json(400, { error: "Invalid JSON" });
+  }
+
+  const result = parseAnnotation(body);
+  if (typeof result === "string") {
+    return json(400, { error: result });
+  }
+
+  const annotation = await addAnnotation(result.timestamp, result.label, profile);
+  console.log(`Annotation added: "${annotation.label}" at ${new Date(annotation.timestamp).toISOString()}`);
+
+  return json(201, { annotation });
+};
@@ -0,0 +1,17 @@
+import { describe, it, expect } from "vitest";
+import { formatAnnotationNotes } from "./format";
+
+describe("formatAnnotationNotes", () => {
+  it("returns null when there are no annotations", () => {
+    expect(formatAnnotationNotes([])).toBeNull();
+  });
+
+  it("lists labels with local day and time", () => {
+    const notes = formatAnnotationNotes([
+      { id: "1-a", timestamp: Date.parse("2026-03-02T16:15:00Z"), label: "sensor change", createdAt: "" },
+      { id: "2-b", timestamp: Date.parse("2026-03-03T17:00:00Z"), label: "travel day", createdAt: "" },
+    ]);
+
+    expect(notes).toBe("sensor change (Mon 8:15 AM); travel day (Tue 9:00 AM)");
+  });
+});
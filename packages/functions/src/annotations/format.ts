@@ -0,0 +1,27 @@
+/**
+ * Annotation formatting for reports
+ */
+
+import type { Annotation } from "./types.js";
+
+/**
+ * Format annotations as a single line for report prompts,
+ * e.g. "sensor change (Mon 8:15 AM); travel day (Tue 9:00 AM)".
+ * Returns null when there are none.
+ */
+export function formatAnnotationNotes(
+  annotations: Annotation[],
+  timezone: string = "America/Los_Angeles"
+): string | null {
+  if (annotations.length === 0) return null;
+
+  const formatter = new Intl.DateTimeFormat("en-US", {
+    timeZone: timezone,
+    weekday: "short",
+    hour: "numeric",
+    minute: "2-digit",
+  });
+  return annotations
+    .map((a) => `${a.label} (${formatter.format(new Date(a.timestamp)).replace(",", "").replace(/\s+/g, " ")})`)
+    .join("; ");
+}
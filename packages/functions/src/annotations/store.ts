@@ -0,0 +1,106 @@
+/**
+ * Annotation store
+ * Named notes on the glucose timeline ("sensor change", "site change",
+ * "travel day"), shown as chart markers and included in reports.
+ */
+
+import { randomUUID } from "crypto";
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DeleteCommand, DynamoDBDocumentClient, PutCommand, QueryCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { Annotation } from "./types.js";
+import { DEFAULT_PROFILE_ID, profilePk } from "../profiles/profile.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const ANNOTATIONS_PK = "ANNOTATIONS";
+
+function sortKey(timestamp: number, id: string): string {
+  return `TS#${new Date(timestamp).toISOString()}#${id}`;
+}
+
+/**
+ * Timestamp encoded in an annotation ID, or null if the ID is malformed.
+ */
+export function parseAnnotationId(id: string): number | null {
+  const match = /^(\d+)-[a-z0-9]+$/.exec(id);
+  return match ? Number(match[1]) : null;
+}
+
+/**
+ * Store an annotation.
+ */
+export async function addAnnotation(
+  timestamp: number,
+  label: string,
+  profileId: string = DEFAULT_PROFILE_ID
+): Promise<Annotation> {
+  const annotation: Annotation = {
+    id: `${timestamp}-${randomUUID().slice(0, 8)}`,
+    timestamp,
+    label,
+    createdAt: new Date().toISOString(),
+  };
+
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        pk: profilePk(profileId, ANNOTATIONS_PK),
+        sk: sortKey(timestamp, annotation.id),
+        ...annotation,
+      },
+    })
+  );
+
+  return annotation;
+}
+
+/**
+ * Get annotations in a time range, oldest first.
+ */
+export async function getAnnotations(
+  since: number,
+  until: number = Date.now(),
+  profileId: string = DEFAULT_PROFILE_ID
+): Promise<Annotation[]> {
+  const result = await ddb.send(
+    new QueryCommand({
+      TableName: Resource.SignageTable.name,
+      KeyConditionExpression: "pk = :pk AND sk BETWEEN :since AND :until",
+      ExpressionAttributeValues: {
+        ":pk": profilePk(profileId, ANNOTATIONS_PK),
+        ":since": `TS#${new Date(since).toISOString()}`,
+        // "~" sorts after any ID suffix at the same instant
+        ":until": `TS#${new Date(until).toISOString()}~`,
+      },
+    })
+  );
+
+  return (result.Items || []).map((item) => ({
+    id: item.id as string,
+    timestamp: item.timestamp as number,
+    label: item.label as string,
+    createdAt: item.createdAt as string,
+  }));
+}
+
+/**
+ * Delete an annotation by ID. Returns false if the ID is malformed.
+ */
+export async function deleteAnnotation(
+  id: string,
+  profileId: string = DEFAULT_PROFILE_ID
+): Promise<boolean> {
+  const timestamp = parseAnnotationId(id);
+  if (timestamp === null) return false;
+
+  await ddb.send(
+    new DeleteCommand({
+      TableName: Resource.SignageTable.name,
+      Key: { pk: profilePk(profileId, ANNOTATIONS_PK), sk: sortKey(timestamp, id) },
+    })
+  );
+  return true;
+}
@@ -0,0 +1,14 @@
+/**
+ * Time-series annotation types
+ */
+
+/**
+ * A named note attached to a point in time (e.g. "sensor change")
+ */
+export interface Annotation {
+  /** Stable ID: `<timestamp>-<suffix>` */
+  id: string;
+  timestamp: number;
+  label: string;
+  createdAt: string;
+}
@@ -0,0 +1,63 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayRequestAuthorizerEventV2 } from "aws-lambda";
+
+const { mockResource } = vi.hoisted(() => ({
+  mockResource: { ApiToken: { value: "test-token" } },
+}));
+
+vi.mock("sst", () => ({
+  Resource: mockResource,
+}));
+
+import { handler, hasApiToken } from "./authorizer";
+
+function createEvent(routeKey: string, authorization?: string): APIGatewayRequestAuthorizerEventV2 {
+  return {
+    type: "REQUEST",
+    routeKey,
+    headers: authorization === undefined ? {} : { authorization },
+  } as unknown as APIGatewayRequestAuthorizerEventV2;
+}
+
+describe("hasApiToken", () => {
+  it("accepts the bearer token", () => {
+    expect(hasApiToken("Bearer test-token", "test-token")).toBe(true);
+  });
+
+  it("rejects a missing or malformed header", () => {
+    expect(hasApiToken(undefined, "test-token")).toBe(false);
+    expect(hasApiToken("test-token", "test-token")).toBe(false);
+    expect(hasApiToken("Basic test-token", "test-token")).toBe(false);
+  });
+
+  it("rejects a wrong token of any length", () => {
+    expect(hasApiToken("Bearer test-tokem", "test-token")).toBe(false);
+    expect(hasApiToken("Bearer test", "test-token")).toBe(false);
+  });
+
+  it("rejects everything when no token is configured", () => {
+    expect(hasApiToken("Bearer ", "")).toBe(false);
+  });
+});
+
+describe("API token authorizer", () => {
+  beforeEach(() => {
+    mockResource.ApiToken.value = "test-token";
+  });
+
+  it("authorizes a request with the token", async () => {
+    const result = await handler(createEvent("GET /export", "Bearer test-token"));
+    expect(result.isAuthorized).toBe(true);
+  });
+
+  it("rejects an export without the token", async () => {
+    const result = await handler(createEvent("GET /export"));
+    expect(result.isAuthorized).toBe(false);
+  });
+
+  it("rejects an alert snooze without the token or with a wrong one", async () => {
+    expect((await handler(createEvent("POST /alerts/ack"))).isAuthorized).toBe(false);
+    expect((await handler(createEvent("POST /alerts/ack", "Bearer guess"))).isAuthorized).toBe(false);
+    expect((await handler(createEvent("DELETE /alerts/ack"))).isAuthorized).toBe(false);
+  });
+});
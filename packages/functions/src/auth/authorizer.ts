@@ -0,0 +1,40 @@
+/**
+ * API token authorizer
+ *
+ * Lambda authorizer for test API routes that return health data or change
+ * what the display shows. Requests must send the ApiToken secret as
+ * `Authorization: Bearer <token>`. With no token configured, every request
+ * is refused rather than let through.
+ */
+
+import { timingSafeEqual } from "node:crypto";
+import type {
+  APIGatewayRequestAuthorizerEventV2,
+  APIGatewaySimpleAuthorizerResult,
+} from "aws-lambda";
+import { Resource } from "sst";
+
+const BEARER_PREFIX = "Bearer ";
+
+/**
+ * Whether an Authorization header carries the expected bearer token
+ */
+export function hasApiToken(authorization: string | undefined, token: string): boolean {
+  if (!token || !authorization?.startsWith(BEARER_PREFIX)) {
+    return false;
+  }
+  const given = Buffer.from(authorization.slice(BEARER_PREFIX.length).trim());
+  const expected = Buffer.from(token);
+  // timingSafeEqual throws on a length mismatch; the length isn't secret
+  return given.length === expected.length && timingSafeEqual(given, expected);
+}
+
+export async function handler(
+  event: APIGatewayRequestAuthorizerEventV2
+): Promise<APIGatewaySimpleAuthorizerResult> {
+  const isAuthorized = hasApiToken(event.headers?.authorization, Resource.ApiToken.value);
+  if (!isAuthorized) {
+    console.log(`Unauthorized request: ${event.routeKey}`);
+  }
+  return { isAuthorized };
+}
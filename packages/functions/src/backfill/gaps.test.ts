@@ -0,0 +1,41 @@
+import { describe, it, expect } from "vitest";
+import { findGaps, GAP_THRESHOLD_MS } from "./gaps";
+
+const MIN = 60 * 1000;
+
+describe("findGaps", () => {
+  it("returns nothing for a complete series", () => {
+    const timestamps = Array.from({ length: 13 }, (_, i) => i * 5 * MIN);
+    expect(findGaps(timestamps, 0, 60 * MIN)).toEqual([]);
+  });
+
+  it("finds gaps longer than the threshold", () => {
+    const timestamps = [0, 5 * MIN, 40 * MIN, 45 * MIN, 60 * MIN];
+    expect(findGaps(timestamps, 0, 60 * MIN)).toEqual([
+      { start: 5 * MIN, end: 40 * MIN, durationMs: 35 * MIN },
+    ]);
+  });
+
+  it("treats exactly the threshold as no gap", () => {
+    expect(findGaps([0, GAP_THRESHOLD_MS], 0, GAP_THRESHOLD_MS)).toEqual([]);
+  });
+
+  it("reports missing data at the window edges", () => {
+    const gaps = findGaps([30 * MIN, 35 * MIN], 0, 60 * MIN);
+    expect(gaps.map((g) => [g.start, g.end])).toEqual([
+      [0, 30 * MIN],
+      [35 * MIN, 60 * MIN],
+    ]);
+  });
+
+  it("reports the whole window when there is no data", () => {
+    expect(findGaps([], 0, 60 * MIN)).toEqual([{ start: 0, end: 60 * MIN, durationMs: 60 * MIN }]);
+  });
+
+  it("sorts input and ignores points outside the window", () => {
+    const timestamps = [45 * MIN, -100 * MIN, 5 * MIN, 0, 50 * MIN, 55 * MIN, 60 * MIN, 999 * MIN];
+    expect(findGaps(timestamps, 0, 60 * MIN)).toEqual([
+      { start: 5 * MIN, end: 45 * MIN, durationMs: 40 * MIN },
+    ]);
+  });
+});
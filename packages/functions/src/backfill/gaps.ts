@@ -0,0 +1,41 @@
+/**
+ * Glucose gap detection
+ * Finds stretches of stored history with no CGM readings.
+ */
+
+/** Readings arrive every 5 minutes; a gap is anything longer than 15 */
+export const GAP_THRESHOLD_MS = 15 * 60 * 1000;
+
+/**
+ * A stretch with no readings. start and end are the readings (or window
+ * edges) on either side, so the missing readings lie strictly between.
+ */
+export interface GlucoseGap {
+  start: number;
+  end: number;
+  durationMs: number;
+}
+
+/**
+ * Find gaps longer than the threshold within [since, until].
+ * The window edges count as boundaries, so missing data at the start or end
+ * of the window is reported too. Timestamps may be in any order.
+ */
+export function findGaps(
+  timestamps: number[],
+  since: number,
+  until: number,
+  thresholdMs: number = GAP_THRESHOLD_MS
+): GlucoseGap[] {
+  const points = timestamps.filter((ts) => ts >= since && ts <= until).sort((a, b) => a - b);
+  const edges = [since, ...points, until];
+
+  const gaps: GlucoseGap[] = [];
+  for (let i = 1; i < edges.length; i++) {
+    const durationMs = edges[i] - edges[i - 1];
+    if (durationMs > thresholdMs) {
+      gaps.push({ start: edges[i - 1], end: edges[i], durationMs });
+    }
+  }
+  return gaps;
+}
@@ -0,0 +1,172 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+
+const { mockQuery, mockStore, mockSession, mockReserve, mockDexcomWindow, mockNightscoutWindow, mockResource } =
+  vi.hoisted(() => ({
+    mockQuery: vi.fn(),
+    mockStore: vi.fn(),
+    mockSession: vi.fn(),
+    mockReserve: vi.fn(),
+    mockDexcomWindow: vi.fn(),
+    mockNightscoutWindow: vi.fn(),
+    mockResource: {
+      SignageTable: { name: "test-table" },
+      DexcomUsername: { value: "test-user" },
+      DexcomPassword: { value: "test-pass" },
+      NightscoutUrl: { value: "" },
+      NightscoutApiSecret: { value: "" },
+    },
+  }));
+
+vi.mock("sst", () => ({ Resource: mockResource }));
+
+vi.mock("@diabetes/core", () => ({
+  createDocClient: () => ({}),
+  storeRecords: mockStore,
+  queryByTypeAndDateRange: mockQuery,
+  formatDateInTimezone: (ts: number) => new Date(ts).toISOString().slice(0, 10),
+}));
+
+vi.mock("../dexcom/client.js", () => ({
+  getSessionId: mockSession,
+  parseDexcomTimestamp: (wt: string) => parseInt(wt.match(/\d+/)?.[0] ?? "0", 10),
+}));
+
+vi.mock("../dexcom/rate-limit-store.js", () => ({
+  reserveDexcomCalls: mockReserve,
+}));
+
+vi.mock("../jobs/store.js", () => ({
+  enqueueJob: vi.fn(),
+}));
+
+vi.mock("./sources.js", async (importOriginal) => ({
+  ...(await importOriginal<typeof import("./sources")>()),
+  fetchDexcomWindow: mockDexcomWindow,
+  fetchNightscoutWindow: mockNightscoutWindow,
+}));
+
+import { repairGaps, SCAN_WINDOW_DAYS } from "./repair";
+
+const MIN = 60 * 1000;
+const DAY = 24 * 60 * MIN;
+const NOW = new Date("2026-03-02T12:00:00Z").getTime();
+const SINCE = NOW - SCAN_WINDOW_DAYS * DAY;
+
+const reading = (ms: number, value = 120) => ({
+  WT: `Date(${ms})`,
+  ST: `Date(${ms})`,
+  DT: `Date(${ms})`,
+  Value: value,
+  Trend: "Flat",
+});
+
+/** Stored readings every 5 minutes across the window, minus the given ranges */
+function storedExcept(...holes: Array<[number, number]>) {
+  const records = [];
+  for (let ts = SINCE; ts <= NOW; ts += 5 * MIN) {
+    if (!holes.some(([start, end]) => ts > start && ts < end)) {
+      records.push({ type: "cgm", timestamp: ts, glucoseMgDl: 120 });
+    }
+  }
+  return records;
+}
+
+describe("repairGaps", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockResource.NightscoutUrl.value = "";
+    mockSession.mockResolvedValue("session");
+    mockReserve.mockResolvedValue(true);
+    mockStore.mockImplementation(async (_c, _t, _u, records: unknown[]) => ({
+      written: records.length,
+      duplicates: 0,
+      errors: [],
+    }));
+  });
+
+  it("does nothing when history is complete", async () => {
+    const records = storedExcept();
+    mockQuery.mockResolvedValueOnce(records).mockResolvedValue([]);
+
+    const report = await repairGaps(NOW);
+
+    expect(report.gaps).toEqual([]);
+    expect(report.scannedReadings).toBe(records.length);
+    expect(mockSession).not.toHaveBeenCalled();
+  });
+
+  it("repairs recent gaps from Dexcom", async () => {
+    const start = NOW - 2 * 60 * MIN;
+    const end = start + 30 * MIN;
+    mockQuery.mockResolvedValueOnce(storedExcept([start, end])).mockResolvedValue([]);
+    mockDexcomWindow.mockResolvedValue([reading(start + 10 * MIN), reading(start + 20 * MIN, 999)]);
+
+    const report = await repairGaps(NOW);
+
+    expect(report.gaps).toEqual([
+      expect.objectContaining({ start, end, source: "dexcom", repaired: 1 }),
+    ]);
+    expect(mockDexcomWindow).toHaveBeenCalledWith("session", start, end, NOW);
+    // Login plus the fetch, from the shared budget
+    expect(mockReserve).toHaveBeenCalledWith(3, NOW);
+    // The implausible 999 is filtered out before storage
+    expect(mockStore.mock.calls[0][3]).toEqual([
+      expect.objectContaining({ timestamp: start + 10 * MIN, glucoseMgDl: 120 }),
+    ]);
+  });
+
+  it("skips old gaps when Nightscout is not configured", async () => {
+    const start = NOW - 3 * DAY;
+    mockQuery.mockResolvedValueOnce(storedExcept([start, start + 60 * MIN])).mockResolvedValue([]);
+
+    const report = await repairGaps(NOW);
+
+    expect(report.gaps).toEqual([expect.objectContaining({ source: null, repaired: 0 })]);
+    expect(mockNightscoutWindow).not.toHaveBeenCalled();
+  });
+
+  it("repairs old gaps from Nightscout when configured", async () => {
+    mockResource.NightscoutUrl.value = "https://nightscout.example.com";
+    const start = NOW - 3 * DAY;
+    mockQuery.mockResolvedValueOnce(storedExcept([start, start + 60 * MIN])).mockResolvedValue([]);
+    mockNightscoutWindow.mockResolvedValue([reading(start + 5 * MIN), reading(start + 10 * MIN)]);
+
+    const report = await repairGaps(NOW);
+
+    expect(report.totalRepaired).toBe(2);
+    expect(report.gaps[0].source).toBe("nightscout");
+    expect(mockStore.mock.calls[0][3][0].sourceFile).toBe("nightscout-backfill");
+  });
+
+  it("records a failed fetch and keeps going", async () => {
+    const first = NOW - 5 * 60 * MIN;
+    const second = NOW - 2 * 60 * MIN;
+    mockQuery
+      .mockResolvedValueOnce(storedExcept([first, first + 30 * MIN], [second, second + 30 * MIN]))
+      .mockResolvedValue([]);
+    mockDexcomWindow
+      .mockRejectedValueOnce(new Error("Dexcom fetch failed: 500"))
+      .mockResolvedValueOnce([reading(second + 10 * MIN)]);
+
+    const report = await repairGaps(NOW);
+
+    expect(report.gaps.map((g) => [g.repaired, g.error])).toEqual([
+      [0, "Dexcom fetch failed: 500"],
+      [1, undefined],
+    ]);
+  });
+
+  it("leaves Dexcom gaps failed for a retry when the budget is spent", async () => {
+    const start = NOW - 2 * 60 * MIN;
+    mockQuery.mockResolvedValueOnce(storedExcept([start, start + 30 * MIN])).mockResolvedValue([]);
+    mockReserve.mockResolvedValue(false);
+
+    const report = await repairGaps(NOW);
+
+    expect(report.gaps).toEqual([
+      expect.objectContaining({ source: "dexcom", repaired: 0, error: "Dexcom request budget spent" }),
+    ]);
+    expect(mockSession).not.toHaveBeenCalled();
+    expect(mockDexcomWindow).not.toHaveBeenCalled();
+  });
+});
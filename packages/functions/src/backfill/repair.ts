@@ -0,0 +1,183 @@
+/**
+ * Glucose backfill repair job
+ *
+ * Runs daily. Scans the stored CGM history for gaps longer than 15 minutes
+ * and re-fetches those windows: from Dexcom Share when the gap is within its
+ * 24-hour reach, otherwise from Nightscout when configured. Repaired readings
+ * go through the same sanity filters and dedupe as live ingestion.
+ */
+
+import { Resource } from "sst";
+import type { ScheduledHandler } from "aws-lambda";
+import {
+  createDocClient,
+  storeRecords,
+  queryByTypeAndDateRange,
+  formatDateInTimezone,
+} from "@diabetes/core";
+import type { CgmReading } from "@diabetes/core";
+import type { DynamoDBDocumentClient } from "@aws-sdk/lib-dynamodb";
+import { getSessionId, parseDexcomTimestamp, type DexcomReading } from "../dexcom/client.js";
+import { DEXCOM_LOGIN_CALLS } from "../dexcom/rate-limit.js";
+import { reserveDexcomCalls } from "../dexcom/rate-limit-store.js";
+import { filterGlucoseReadings, recordRejections } from "../ingest/sanity-filters.js";
+import { findGaps, type GlucoseGap } from "./gaps.js";
+import { fetchDexcomWindow, fetchNightscoutWindow, isWithinDexcomReach } from "./sources.js";
+import { enqueueJob } from "../jobs/store.js";
+import { DEFAULT_PROFILE_ID, profileUserId } from "../profiles/profile.js";
+
+/** How far back to scan */
+export const SCAN_WINDOW_DAYS = 7;
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+export type RepairSource = "dexcom" | "nightscout";
+
+/**
+ * Outcome for one gap
+ */
+export interface GapRepair extends GlucoseGap {
+  /** Where the readings came from, or null when no source could reach the gap */
+  source: RepairSource | null;
+  /** New readings written */
+  repaired: number;
+  error?: string;
+}
+
+export interface RepairReport {
+  scannedReadings: number;
+  gaps: GapRepair[];
+  totalRepaired: number;
+}
+
+let docClient: DynamoDBDocumentClient | null = null;
+
+function getDocClient(): DynamoDBDocumentClient {
+  if (!docClient) {
+    docClient = createDocClient();
+  }
+  return docClient;
+}
+
+/**
+ * Load stored CGM timestamps in the window, one day per query so each
+ * stays well under DynamoDB's 1MB page.
+ */
+async function loadStoredTimestamps(since: number, until: number): Promise<number[]> {
+  const dates = new Set<string>();
+  for (let ts = since; ts < until + DAY_MS; ts += DAY_MS) {
+    dates.add(formatDateInTimezone(Math.min(ts, until)));
+  }
+
+  const timestamps: number[] = [];
+  for (const date of dates) {
+    const records = await queryByTypeAndDateRange(
+      getDocClient(),
+      Resource.SignageTable.name,
+      profileUserId(DEFAULT_PROFILE_ID),
+      "cgm",
+      date,
+      date
+    );
+    timestamps.push(...records.map((record) => record.timestamp));
+  }
+  return timestamps;
+}
+
+function toCgmRecord(reading: DexcomReading, source: RepairSource): CgmReading {
+  return {
+    type: "cgm",
+    timestamp: parseDexcomTimestamp(reading.WT),
+    glucoseMgDl: reading.Value,
+    importedAt: Date.now(),
+    sourceFile: source === "dexcom" ? "dexcom-share-api" : "nightscout-backfill",
+  };
+}
+
+function formatGap(gap: GlucoseGap): string {
+  return `${new Date(gap.start).toISOString()} -> ${new Date(gap.end).toISOString()} (${Math.round(gap.durationMs / 60000)} min)`;
+}
+
+/**
+ * Find and repair gaps in the last SCAN_WINDOW_DAYS of CGM history.
+ */
+export async function repairGaps(now: number = Date.now()): Promise<RepairReport> {
+  const since = now - SCAN_WINDOW_DAYS * DAY_MS;
+  const timestamps = await loadStoredTimestamps(since, now);
+  const gaps = findGaps(timestamps, since, now);
+
+  const nightscout = Resource.NightscoutUrl.value
+    ? { url: Resource.NightscoutUrl.value, apiSecret: Resource.NightscoutApiSecret.value }
+    : null;
+  let dexcomSession: string | null = null;
+
+  const results: GapRepair[] = [];
+  for (const gap of gaps) {
+    const source: RepairSource | null = isWithinDexcomReach(gap.start, now)
+      ? "dexcom"
+      : nightscout
+        ? "nightscout"
+        : null;
+
+    if (!source) {
+      console.log(`Backfill: gap ${formatGap(gap)} is out of reach, skipping`);
+      results.push({ ...gap, source, repaired: 0 });
+      continue;
+    }
+
+    try {
+      let fetched: DexcomReading[];
+      if (source === "dexcom") {
+        // A gap left for lack of budget fails, so it's retried through the job queue
+        if (!(await reserveDexcomCalls((dexcomSession ? 0 : DEXCOM_LOGIN_CALLS) + 1, now))) {
+          throw new Error("Dexcom request budget spent");
+        }
+        dexcomSession ??= await getSessionId({
+          username: Resource.DexcomUsername.value,
+          password: Resource.DexcomPassword.value,
+        });
+        fetched = await fetchDexcomWindow(dexcomSession, gap.start, gap.end, now);
+      } else {
+        fetched = await fetchNightscoutWindow(nightscout!, gap.start, gap.end);
+      }
+
+      const { accepted, rejected } = filterGlucoseReadings(fetched);
+      recordRejections(`backfill-${source}`, rejected);
+
+      const result = await storeRecords(
+        getDocClient(),
+        Resource.SignageTable.name,
+        profileUserId(DEFAULT_PROFILE_ID),
+        accepted.map((reading) => toCgmRecord(reading, source))
+      );
+      if (result.errors.length > 0) {
+        console.error(`Backfill write errors: ${result.errors.join(", ")}`);
+      }
+
+      console.log(`Backfill: gap ${formatGap(gap)} repaired ${result.written} readings from ${source}`);
+      results.push({ ...gap, source, repaired: result.written });
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      console.error(`Backfill: gap ${formatGap(gap)} failed (${source}): ${message}`);
+      results.push({ ...gap, source, repaired: 0, error: message });
+    }
+  }
+
+  return {
+    scannedReadings: timestamps.length,
+    gaps: results,
+    totalRepaired: results.reduce((sum, gap) => sum + gap.repaired, 0),
+  };
+}
+
+export const scheduled: ScheduledHandler = async () => {
+  const report = await repairGaps();
+  console.log(
+    `Backfill complete: ${report.scannedReadings} readings scanned, ${report.gaps.length} gaps, ${report.totalRepaired} readings repaired`
+  );
+
+  // Try failed gaps again through the job queue rather than waiting a day
+  if (report.gaps.some((gap) => gap.error)) {
+    await enqueueJob("glucoseBackfill", {});
+  }
+};
@@ -0,0 +1,97 @@
+/**
+ * Backfill sources
+ * Re-fetch glucose readings for a past window. Dexcom Share only reaches back
+ * 24 hours; Nightscout (when configured) keeps the full history.
+ */
+
+import { createHash } from "crypto";
+import {
+  fetchGlucoseReadings,
+  parseDexcomTimestamp,
+  type DexcomReading,
+} from "../dexcom/client.js";
+import type { NightscoutConfig } from "../treatments/nightscout.js";
+
+/** Dexcom Share's maximum lookback */
+export const DEXCOM_MAX_MINUTES = 1440;
+
+/** Nightscout SGV entry (only the fields we use) */
+interface NightscoutEntry {
+  date: number;
+  sgv?: number;
+  direction?: string;
+}
+
+/**
+ * Keep readings strictly between start and end.
+ */
+function withinWindow(readings: DexcomReading[], start: number, end: number): DexcomReading[] {
+  return readings.filter((reading) => {
+    const timestamp = parseDexcomTimestamp(reading.WT);
+    return timestamp > start && timestamp < end;
+  });
+}
+
+/**
+ * Check whether Dexcom Share can still serve a window.
+ */
+export function isWithinDexcomReach(start: number, now: number = Date.now()): boolean {
+  return now - start <= DEXCOM_MAX_MINUTES * 60 * 1000;
+}
+
+/**
+ * Fetch Dexcom readings between start and end (exclusive).
+ * Share only returns "the last N minutes", so this fetches back to start
+ * and trims. Callers take the call from the Dexcom request budget first.
+ */
+export async function fetchDexcomWindow(
+  sessionId: string,
+  start: number,
+  end: number,
+  now: number = Date.now()
+): Promise<DexcomReading[]> {
+  const minutes = Math.min(Math.ceil((now - start) / 60000), DEXCOM_MAX_MINUTES);
+  const maxCount = Math.ceil(minutes / 5) + 10;
+  const readings = (await fetchGlucoseReadings(sessionId, minutes, maxCount)) ?? [];
+  return withinWindow(readings, start, end);
+}
+
+/**
+ * Fetch Nightscout SGV entries between start and end (exclusive).
+ * Entries are returned in Dexcom's shape; Nightscout directions use the
+ * same names as Dexcom trends.
+ */
+export async function fetchNightscoutWindow(
+  config: NightscoutConfig,
+  start: number,
+  end: number
+): Promise<DexcomReading[]> {
+  const params = new URLSearchParams({
+    "find[date][$gt]": String(start),
+    "find[date][$lt]": String(end),
+    count: String(Math.ceil((end - start) / (5 * 60 * 1000)) + 10),
+  });
+  const url = `${config.url.replace(/\/+$/, "")}/api/v1/entries/sgv.json?${params}`;
+  const response = await fetch(url, {
+    headers: {
+      Accept: "application/json",
+      "api-secret": createHash("sha1").update(config.apiSecret).digest("hex"),
+    },
+  });
+
+  if (!response.ok) {
+    throw new Error(`Nightscout returned ${response.status}`);
+  }
+
+  const entries = (await response.json()) as NightscoutEntry[];
+  const readings = entries
+    .filter((entry) => typeof entry.sgv === "number" && typeof entry.date === "number")
+    .map((entry) => ({
+      WT: `Date(${entry.date})`,
+      ST: `Date(${entry.date})`,
+      DT: `Date(${entry.date})`,
+      Value: entry.sgv as number,
+      Trend: entry.direction ?? "NotComputable",
+    }));
+  return withinWindow(readings, start, end);
+}
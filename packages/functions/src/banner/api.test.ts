@@ -0,0 +1,93 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockGetBanner, mockSaveBanner, mockClearBanner } = vi.hoisted(() => ({
+  mockGetBanner: vi.fn(),
+  mockSaveBanner: vi.fn(),
+  mockClearBanner: vi.fn(),
+}));
+
+vi.mock("./store.js", () => ({
+  getTextBanner: mockGetBanner,
+  saveTextBanner: mockSaveBanner,
+  clearTextBanner: mockClearBanner,
+}));
+
+vi.mock("sst", () => ({
+  Resource: { SignageTable: { name: "test-table" } },
+}));
+
+import { handler } from "./api";
+
+function createEvent(method: string, body?: unknown): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("text banner API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGetBanner.mockResolvedValue({ template: "Door: {{.sensor.front_door}}", values: {}, updatedAt: 0 });
+    mockSaveBanner.mockResolvedValue(undefined);
+    mockClearBanner.mockResolvedValue(undefined);
+  });
+
+  it("reports the banner with its text on GET", async () => {
+    const { statusCode, body } = await invoke(createEvent("GET"));
+
+    expect(statusCode).toBe(200);
+    expect(body).toEqual({
+      template: "Door: {{.sensor.front_door}}",
+      fields: ["sensor.front_door"],
+      values: {},
+      text: "Door: ?",
+    });
+  });
+
+  it("merges pushed values into the stored banner", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { values: { sensor: { front_door: "OPEN" } } }));
+
+    expect(statusCode).toBe(200);
+    expect(body.text).toBe("Door: OPEN");
+    expect(mockSaveBanner).toHaveBeenCalledWith({
+      template: "Door: {{.sensor.front_door}}",
+      values: { sensor: { front_door: "OPEN" } },
+      updatedAt: expect.any(Number),
+    });
+  });
+
+  it("sets a template before any banner exists", async () => {
+    mockGetBanner.mockResolvedValue(null);
+
+    const { statusCode, body } = await invoke(createEvent("POST", { template: "Hello" }));
+
+    expect(statusCode).toBe(200);
+    expect(body.text).toBe("Hello");
+  });
+
+  it("rejects malformed templates and reserved values", async () => {
+    expect((await invoke(createEvent("POST", { template: "Door: {{door}}" }))).statusCode).toBe(400);
+    expect((await invoke(createEvent("POST", { values: { glucose: 50 } }))).statusCode).toBe(400);
+    expect(mockSaveBanner).not.toHaveBeenCalled();
+  });
+
+  it("clears the banner on DELETE", async () => {
+    const { statusCode, body } = await invoke(createEvent("DELETE"));
+
+    expect(statusCode).toBe(200);
+    expect(body.text).toBeNull();
+    expect(mockClearBanner).toHaveBeenCalled();
+  });
+
+  it("rejects invalid JSON", async () => {
+    const event = { requestContext: { http: { method: "POST" } }, body: "{" } as unknown as APIGatewayProxyEventV2;
+    expect((await invoke(event)).statusCode).toBe(400);
+  });
+});
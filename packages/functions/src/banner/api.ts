@@ -0,0 +1,80 @@
+/**
+ * Text banner API
+ *
+ * GET    /banner - the template, pushed values, and the text as it shows now
+ * POST   /banner - set the template and/or push values
+ * DELETE /banner - remove the banner and its values
+ *
+ * Body: { "template": "Door: {{.sensor.front_door}}", "values": { "sensor": { "front_door": "OPEN" } } }
+ * Values merge into the stored ones (a null value removes one), so each
+ * tool can push just its own. "template": null hides the banner but keeps
+ * the values. The display's own data is there too: {{.glucose.value}},
+ * {{.glucose.trend}}, {{.glucose.delta}}, {{.glucose.minutes}} (since the
+ * reading), {{.treatments.insulin}} and {{.treatments.carbs}} (last 4 hours).
+ * The banner shows over the insight rows in every layout, unless a
+ * notification or a pomodoro has them, and changes with the minute's update.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { clearTextBanner, getTextBanner, saveTextBanner } from "./store.js";
+import { applyBannerRequest, bannerText, parseBannerRequest, templateFields } from "./template.js";
+import type { TextBanner } from "./types.js";
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * The banner as reported: its text is filled in from pushed values only,
+ * with "?" for the display's own data
+ */
+function describeBanner(banner: TextBanner | null) {
+  return {
+    template: banner?.template ?? null,
+    fields: banner?.template ? templateFields(banner.template) : [],
+    values: banner?.values ?? {},
+    text: bannerText(banner, {}),
+  };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  if (method === "GET") {
+    return json(200, describeBanner(await getTextBanner()));
+  }
+
+  if (method === "DELETE") {
+    await clearTextBanner();
+    console.log("Text banner cleared");
+    return json(200, describeBanner(null));
+  }
+
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  let body: Parameters<typeof parseBannerRequest>[0];
+  try {
+    body = JSON.parse(event.body || "{}");
+  } catch {
+    return json(400, { error: "Invalid JSON" });
+  }
+
+  const request = parseBannerRequest(body);
+  if (typeof request === "string") {
+    return json(400, { error: request });
+  }
+
+  const banner = applyBannerRequest(await getTextBanner(), request);
+  await saveTextBanner(banner);
+  console.log(`Text banner updated: ${banner.template ? `"${banner.template}"` : "no template"}`);
+  return json(200, describeBanner(banner));
+};
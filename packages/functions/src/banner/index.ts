@@ -0,0 +1,8 @@
+/**
+ * Text banner templates, for the local server
+ *
+ * Only the pure template code; the API and its store need the deployed resources.
+ */
+
+export * from "./template.js";
+export * from "./types.js";
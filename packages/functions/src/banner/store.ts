@@ -0,0 +1,58 @@
+/**
+ * Text banner store
+ * The banner's template and pushed values, in one item.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DeleteCommand, DynamoDBDocumentClient, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { TemplateValues, TextBanner } from "./types.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const BANNER_KEY = { pk: "TEXT_BANNER", sk: "CURRENT" };
+
+/**
+ * Get the banner, or null if nothing has been set
+ */
+export async function getTextBanner(): Promise<TextBanner | null> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: BANNER_KEY,
+    })
+  );
+
+  const item = result.Item;
+  if (!item) return null;
+  return {
+    ...(item.template && { template: item.template as string }),
+    values: (item.values as TemplateValues | undefined) ?? {},
+    updatedAt: item.updatedAt as number,
+  };
+}
+
+/**
+ * Save the banner
+ */
+export async function saveTextBanner(banner: TextBanner): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: { ...BANNER_KEY, ...banner },
+    })
+  );
+}
+
+/**
+ * Remove the banner and its values
+ */
+export async function clearTextBanner(): Promise<void> {
+  await ddb.send(
+    new DeleteCommand({
+      TableName: Resource.SignageTable.name,
+      Key: BANNER_KEY,
+    })
+  );
+}
@@ -0,0 +1,118 @@
+import { describe, it, expect } from "vitest";
+import {
+  applyBannerRequest,
+  bannerText,
+  mergeTemplateValues,
+  parseBannerRequest,
+  renderTemplate,
+  templateFields,
+  validateTemplate,
+  validateTemplateValues,
+  MAX_TEMPLATE_LENGTH,
+} from "./template";
+
+const NOW = 1_700_000_000_000;
+
+describe("validateTemplate", () => {
+  it("accepts text with or without placeholders", () => {
+    expect(validateTemplate("Door: {{.sensor.front_door}}")).toBeNull();
+    expect(validateTemplate("{{ .glucose.value }} mg/dL")).toBeNull();
+    expect(validateTemplate("Hello")).toBeNull();
+  });
+
+  it("rejects empty, overlong, and malformed templates", () => {
+    expect(validateTemplate("  ")).toMatch(/non-empty/);
+    expect(validateTemplate("x".repeat(MAX_TEMPLATE_LENGTH + 1))).toMatch(/at most/);
+    expect(validateTemplate("Door: {{sensor.front_door}}")).toMatch(/placeholders/);
+    expect(validateTemplate("Door: {{.sensor.front door}}")).toMatch(/placeholders/);
+    expect(validateTemplate("Door: {{.sensor")).toMatch(/placeholders/);
+  });
+});
+
+describe("validateTemplateValues", () => {
+  it("accepts nested strings, numbers and booleans, and nulls", () => {
+    expect(validateTemplateValues({ sensor: { front_door: "OPEN", temp: 21.5, garage: { open: false } } })).toBeNull();
+    expect(validateTemplateValues({ sensor: null })).toBeNull();
+  });
+
+  it("rejects reserved names, bad names, arrays, long strings and deep nesting", () => {
+    expect(validateTemplateValues({ glucose: 100 })).toMatch(/reserved/);
+    expect(validateTemplateValues({ "front door": "OPEN" })).toMatch(/invalid value name/);
+    expect(validateTemplateValues({ sensor: ["OPEN"] })).toMatch(/must be an object/);
+    expect(validateTemplateValues({ note: "x".repeat(41) })).toMatch(/at most/);
+    expect(validateTemplateValues({ a: { b: { c: { d: 1 } } } })).toMatch(/nested too deeply/);
+  });
+});
+
+describe("parseBannerRequest", () => {
+  it("needs a template or values", () => {
+    expect(parseBannerRequest({})).toMatch(/Provide/);
+    expect(parseBannerRequest({ template: " Door: {{.door}} " })).toEqual({ template: "Door: {{.door}}" });
+    expect(parseBannerRequest({ template: null, values: { door: "OPEN" } })).toEqual({
+      template: null,
+      values: { door: "OPEN" },
+    });
+  });
+});
+
+describe("mergeTemplateValues", () => {
+  it("merges groups and removes nulls", () => {
+    const current = { sensor: { front_door: "OPEN", back_door: "SHUT" }, mode: "home" };
+
+    expect(mergeTemplateValues(current, { sensor: { front_door: "SHUT" } })).toEqual({
+      sensor: { front_door: "SHUT", back_door: "SHUT" },
+      mode: "home",
+    });
+    expect(mergeTemplateValues(current, { sensor: { front_door: null, back_door: null }, mode: null })).toEqual({});
+  });
+});
+
+describe("applyBannerRequest", () => {
+  it("keeps what the request doesn't change", () => {
+    const banner = { template: "Door: {{.door}}", values: { door: "OPEN" }, updatedAt: 0 };
+
+    expect(applyBannerRequest(banner, { values: { door: "SHUT" } }, NOW)).toEqual({
+      template: "Door: {{.door}}",
+      values: { door: "SHUT" },
+      updatedAt: NOW,
+    });
+    expect(applyBannerRequest(banner, { template: null }, NOW)).toEqual({ values: { door: "OPEN" }, updatedAt: NOW });
+  });
+});
+
+describe("renderTemplate", () => {
+  it("fills in paths, rounding fractions and marking gaps", () => {
+    const context = { sensor: { front_door: "OPEN", temp: 21.46, armed: true } };
+
+    expect(renderTemplate("Door: {{.sensor.front_door}}", context)).toBe("Door: OPEN");
+    expect(renderTemplate("{{.sensor.temp}}C {{ .sensor.armed }}", context)).toBe("21.5C true");
+    expect(renderTemplate("{{.sensor.window}} {{.sensor}}", context)).toBe("? ?");
+  });
+
+  it("lists the paths it looks up", () => {
+    expect(templateFields("{{.a.b}} and {{ .c }}")).toEqual(["a.b", "c"]);
+  });
+});
+
+describe("bannerText", () => {
+  it("fills in the display's own data", () => {
+    const banner = { template: "BG {{.glucose.value}} {{.glucose.minutes}}m IOB {{.treatments.insulin}}", values: {}, updatedAt: 0 };
+    const bloodSugar = {
+      glucose: 112,
+      trend: "Flat",
+      delta: 2,
+      timestamp: NOW - 4 * 60_000,
+      rangeStatus: "normal" as const,
+      isStale: false,
+    };
+    const treatments = { recentInsulinUnits: 3.5, recentCarbsGrams: 40, treatments: [], lastFetchedAt: NOW, isStale: false };
+
+    expect(bannerText(banner, { bloodSugar, treatments }, NOW)).toBe("BG 112 4m IOB 3.5");
+    expect(bannerText(banner, {}, NOW)).toBe("BG ? ?m IOB ?");
+  });
+
+  it("is null without a template", () => {
+    expect(bannerText({ values: { door: "OPEN" }, updatedAt: 0 }, {}, NOW)).toBeNull();
+    expect(bannerText(null, {}, NOW)).toBeNull();
+  });
+});
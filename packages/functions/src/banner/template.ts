@@ -0,0 +1,207 @@
+/**
+ * Text banner templates
+ *
+ * A banner is a one-off status line without a widget: a template such as
+ * "Door: {{.sensor.front_door}}" filled in from values other tools push
+ * ({ "sensor": { "front_door": "OPEN" } }) and from the display's own data,
+ * under `glucose` (value, trend, delta, minutes) and `treatments` (insulin,
+ * carbs). A path with nothing at it shows "?". Like notifications, the
+ * banner is just data for whoever renders next.
+ */
+
+import type { BloodSugarDisplayData } from "../rendering/blood-sugar-renderer.js";
+import type { TreatmentDisplayData } from "../glooko/types.js";
+import type { TemplateValue, TemplateValues, TextBanner } from "./types.js";
+
+export const MAX_TEMPLATE_LENGTH = 100;
+export const MAX_VALUE_LENGTH = 40;
+/** Deepest a pushed path can go, e.g. sensor.garage.door */
+export const MAX_VALUE_DEPTH = 3;
+/** Names the display's own data takes; pushed values can't use them */
+export const RESERVED_VALUE_NAMES = ["glucose", "treatments"];
+
+/** Shown for a path with no value */
+const MISSING_VALUE = "?";
+
+const NAME_PATTERN = /^[A-Za-z0-9_-]+$/;
+const PLACEHOLDER_PATTERN = /\{\{\s*\.([A-Za-z0-9_-]+(?:\.[A-Za-z0-9_-]+)*)\s*\}\}/g;
+
+/**
+ * A validated POST /banner body
+ */
+export interface BannerRequest {
+  /** New template; null removes it */
+  template?: string | null;
+  /** Values to merge in; a null value removes one */
+  values?: Record<string, unknown>;
+}
+
+/**
+ * Dotted paths a template looks up, in order
+ */
+export function templateFields(template: string): string[] {
+  return Array.from(template.matchAll(PLACEHOLDER_PATTERN), (match) => match[1]);
+}
+
+/**
+ * Validate a template. Returns an error message, or null if valid.
+ */
+export function validateTemplate(template: unknown): string | null {
+  if (typeof template !== "string" || template.trim() === "") {
+    return "template must be a non-empty string";
+  }
+  if (template.length > MAX_TEMPLATE_LENGTH) {
+    return `template must be at most ${MAX_TEMPLATE_LENGTH} characters`;
+  }
+  // Anything left with braces after the placeholders is a typo
+  const rest = template.replace(PLACEHOLDER_PATTERN, "");
+  if (rest.includes("{{") || rest.includes("}}")) {
+    return 'template placeholders look like {{.sensor.front_door}}';
+  }
+  return null;
+}
+
+/**
+ * Validate values to merge in. Returns an error message, or null if valid.
+ */
+export function validateTemplateValues(values: unknown, depth = 1, path = ""): string | null {
+  if (typeof values !== "object" || values === null || Array.isArray(values)) {
+    return `${path || "values"} must be an object`;
+  }
+  for (const [name, value] of Object.entries(values)) {
+    const at = path ? `${path}.${name}` : name;
+    if (!NAME_PATTERN.test(name)) {
+      return `invalid value name: ${JSON.stringify(at)} (letters, digits, _ and -)`;
+    }
+    if (depth === 1 && RESERVED_VALUE_NAMES.includes(name)) {
+      return `${name} is the display's own data (reserved: ${RESERVED_VALUE_NAMES.join(", ")})`;
+    }
+    if (value === null || typeof value === "boolean") continue;
+    if (typeof value === "number") {
+      if (!Number.isFinite(value)) return `${at} must be a finite number`;
+      continue;
+    }
+    if (typeof value === "string") {
+      if (value.length > MAX_VALUE_LENGTH) return `${at} must be at most ${MAX_VALUE_LENGTH} characters`;
+      continue;
+    }
+    if (depth >= MAX_VALUE_DEPTH) {
+      return `${at} is nested too deeply (at most ${MAX_VALUE_DEPTH} levels)`;
+    }
+    const error = validateTemplateValues(value, depth + 1, at);
+    if (error) return error;
+  }
+  return null;
+}
+
+/**
+ * Validate a POST /banner body.
+ * Returns the request, or an error message.
+ */
+export function parseBannerRequest(body: { template?: unknown; values?: unknown }): BannerRequest | string {
+  if (body.template === undefined && body.values === undefined) {
+    return "Provide template and/or values";
+  }
+  if (body.template !== undefined && body.template !== null) {
+    const error = validateTemplate(body.template);
+    if (error) return error;
+  }
+  if (body.values !== undefined) {
+    const error = validateTemplateValues(body.values);
+    if (error) return error;
+  }
+  return {
+    ...(body.template !== undefined && { template: body.template === null ? null : (body.template as string).trim() }),
+    ...(body.values !== undefined && { values: body.values as Record<string, unknown> }),
+  };
+}
+
+/**
+ * Merge pushed values into the stored ones: groups merge, null removes
+ */
+export function mergeTemplateValues(current: TemplateValues, changes: Record<string, unknown>): TemplateValues {
+  const merged: TemplateValues = { ...current };
+  for (const [name, value] of Object.entries(changes)) {
+    if (value === null) {
+      delete merged[name];
+    } else if (typeof value === "object") {
+      const existing = merged[name];
+      const group = mergeTemplateValues(typeof existing === "object" ? existing : {}, value as Record<string, unknown>);
+      if (Object.keys(group).length === 0) {
+        delete merged[name];
+      } else {
+        merged[name] = group;
+      }
+    } else {
+      merged[name] = value as TemplateValue;
+    }
+  }
+  return merged;
+}
+
+/**
+ * Apply a request to the stored banner (or to none)
+ */
+export function applyBannerRequest(banner: TextBanner | null, request: BannerRequest, now: number = Date.now()): TextBanner {
+  const template = request.template === undefined ? banner?.template : (request.template ?? undefined);
+  const values = request.values ? mergeTemplateValues(banner?.values ?? {}, request.values) : (banner?.values ?? {});
+  return { ...(template && { template }), values, updatedAt: now };
+}
+
+function formatValue(value: TemplateValue | undefined): string {
+  if (value === undefined || typeof value === "object") return MISSING_VALUE;
+  if (typeof value === "number") return Number.isInteger(value) ? String(value) : value.toFixed(1);
+  return String(value);
+}
+
+/**
+ * Fill in a template's placeholders
+ */
+export function renderTemplate(template: string, context: TemplateValues): string {
+  return template.replace(PLACEHOLDER_PATTERN, (_, path: string) => {
+    let value: TemplateValue | undefined = context;
+    for (const name of path.split(".")) {
+      value = typeof value === "object" ? value[name] : undefined;
+    }
+    return formatValue(value);
+  });
+}
+
+/**
+ * Pushed values plus the display's own data, for rendering
+ */
+export function buildTemplateContext(
+  values: TemplateValues,
+  data: { bloodSugar?: BloodSugarDisplayData | null; treatments?: TreatmentDisplayData | null },
+  now: number = Date.now()
+): TemplateValues {
+  const context: TemplateValues = { ...values };
+  if (data.bloodSugar) {
+    context.glucose = {
+      value: data.bloodSugar.glucose,
+      trend: data.bloodSugar.trend,
+      delta: data.bloodSugar.delta,
+      minutes: Math.max(0, Math.floor((now - data.bloodSugar.timestamp) / 60_000)),
+    };
+  }
+  if (data.treatments && !data.treatments.isStale) {
+    context.treatments = {
+      insulin: data.treatments.recentInsulinUnits,
+      carbs: data.treatments.recentCarbsGrams,
+    };
+  }
+  return context;
+}
+
+/**
+ * The banner's text right now, or null if it has no template
+ */
+export function bannerText(
+  banner: TextBanner | null,
+  data: Parameters<typeof buildTemplateContext>[1],
+  now: number = Date.now()
+): string | null {
+  if (!banner?.template) return null;
+  const text = renderTemplate(banner.template, buildTemplateContext(banner.values, data, now)).trim().replace(/\s+/g, " ");
+  return text || null;
+}
@@ -0,0 +1,21 @@
+/**
+ * Text banner types
+ */
+
+/** A value a template can show: a leaf, or a group of named values */
+export type TemplateValue = string | number | boolean | TemplateValues;
+
+export interface TemplateValues {
+  [name: string]: TemplateValue;
+}
+
+/**
+ * The stored banner: a template, and the values pushed for it
+ */
+export interface TextBanner {
+  /** e.g. "Door: {{.sensor.front_door}}"; no banner shows without one */
+  template?: string;
+  /** Values pushed by other tools, looked up by dotted path */
+  values: TemplateValues;
+  updatedAt: number;
+}
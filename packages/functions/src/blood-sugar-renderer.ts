@@ -109,17 +109,18 @@ function classifyRange(mgdl: number): RangeStatus {
 }
 
 /**
- * Get trend arrow character
+ * Get trend arrow characters (the 5x7 font falls back to ASCII if needed)
  */
 function getTrendArrow(trend: string): string {
   const arrows: Record<string, string> = {
-    doubleup: "^^",
-    singleup: "^",
-    fortyfiveup: "/",
-    flat: "-",
-    fortyfivedown: "\\",
-    singledown: "v",
-    doubledown: "vv",
+    doubleup: "↑↑",
+    singleup: "↑",
+    fortyfiveup: "↗",
+    flat: "→",
+    fortyfivedown: "↘",
+    singledown: "↓",
+    doubledown: "↓↓",
+    rateoutofrange: "⇅",
   };
   return arrows[trend.toLowerCase()] ?? "?";
 }
@@ -7,31 +7,44 @@
  * - Bottom half (rows 32-63): Blood Sugar
  */
 
-import {
-  ApiGatewayManagementApiClient,
-  PostToConnectionCommand,
-  GoneException,
-} from "@aws-sdk/client-apigatewaymanagementapi";
+import { ApiGatewayManagementApiClient } from "@aws-sdk/client-apigatewaymanagementapi";
 import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
 import { DynamoDBDocumentClient, GetCommand, QueryCommand, PutCommand, DeleteCommand } from "@aws-sdk/lib-dynamodb";
 import { Resource } from "sst";
 import type { ScheduledHandler } from "aws-lambda";
-import { encodeFrameToBase64 } from "@signage/core";
-import type { Frame } from "@signage/core";
+import {
+  encodeFrameToBase64,
+  decodeBase64ToPixels,
+  createTransitionFrames,
+  createSceneFrames,
+  subFrame,
+  rotateFrame,
+  fitFrame,
+  DISPLAY_MODELS,
+} from "@signage/core";
+import type { DisplayModel, Frame, Rotation } from "@signage/core";
 import {
   generateCompositeFrame,
+  renderCompactFrame,
   classifyRange,
+  calculateTIR,
+  limitPower,
+  renderClockTicks,
+  wallTime,
+  DEFAULT_TIMEZONE,
   DISPLAY_WIDTH,
   DISPLAY_HEIGHT,
+  WIDGET_REGIONS,
+  SPLIT_CHART_HOURS,
   type BloodSugarDisplayData,
+  type LayoutWidget,
   type ClockWeatherData,
+  type FramePatch,
 } from "./rendering/index.js";
-import {
-  getSessionId,
-  fetchGlucoseReadings,
-  parseDexcomTimestamp,
-  type DexcomReading,
-} from "./dexcom/client.js";
+import { isTrendComputable, parseDexcomTimestamp, type DexcomReading } from "./dexcom/client.js";
+import { createDexcomFetcher, type GlucoseFetcher } from "./dexcom/fetcher.js";
+import { DEFAULT_DEXCOM_RATE_LIMIT, DEXCOM_CALLS_PER_RUN, type DexcomRateLimit } from "./dexcom/rate-limit.js";
+import { takeDexcomTokens } from "./dexcom/rate-limit-store.js";
 import { storeRecords, createDocClient } from "@diabetes/core";
 import type { CgmReading } from "@diabetes/core";
 
@@ -45,9 +58,6 @@ function getCgmDocClient(): DynamoDBDocumentClient {
   return cgmDocClient;
 }
 
-/** Default user ID for CGM storage */
-const CGM_USER_ID = "john";
-
 /**
  * Store Dexcom readings as CGM records for agent analysis (dual-write).
  */
@@ -67,7 +77,7 @@ async function storeCgmReadingsForAgent(readings: DexcomReading[]): Promise<void
     const result = await storeRecords(
       getCgmDocClient(),
       tableName,
-      CGM_USER_ID,
+      profileUserId(DEFAULT_PROFILE_ID),
       cgmRecords
     );
 
@@ -86,10 +96,150 @@ import type { TreatmentDisplayData, GlookoTreatmentsItem } from "./glooko/types.
 import { calculateTreatmentTotals } from "./rendering/treatment-renderer.js";
 import { queryDailyInsulinByDateRange, getCurrentInsight } from "@diabetes/core";
 import { createInsightDisplayData, type InsightDisplayData } from "./rendering/insight-renderer.js";
+import { resolveLayout, getLayout, isPageTurn, type LayoutDefinition } from "./rendering/layouts.js";
+import { getDisplayConfig, saveDisplayConfig, type DisplayConfig } from "./display/config-store.js";
+import { getDisplayLock, type DisplayLock } from "./display/lock-store.js";
+import { clearPomodoroTimer, getPomodoroTimer, savePomodoroTimer } from "./pomodoro/store.js";
+import {
+  getPomodoroStatus,
+  isPomodoroBuzzDue,
+  isPomodoroFinished,
+  pomodoroIntervalsDone,
+  POMODORO_BUZZ,
+} from "./pomodoro/timer.js";
+import type { PomodoroStatus, PomodoroTimer } from "./pomodoro/types.js";
+import { fetchTickerQuotes, parseTickerSymbol } from "./ticker/quotes.js";
+import {
+  isTickerRefreshDue,
+  quoteToTimeSeriesPoint,
+  tickerHistoryId,
+  toDisplayQuote,
+  TICKER_HISTORY_CONFIG,
+  TICKER_HISTORY_HOURS,
+  type TickerHistoryValue,
+} from "./ticker/history.js";
+import type { TickerDisplayData, TickerQuote, TickerSettings } from "./ticker/types.js";
+import { takeNetworkSample } from "./network/probe.js";
+import {
+  NETWORK_HISTORY_CONFIG,
+  NETWORK_HISTORY_HOURS,
+  NETWORK_WIDGET_ID,
+  sampleToTimeSeriesPoint,
+  timeSeriesPointToSample,
+  type NetworkHistoryValue,
+} from "./network/history.js";
+import type { NetworkDisplayData, NetworkMonitorSettings } from "./network/types.js";
+import { fetchPrecipitationNowcast, isNowcastRefreshDue } from "./precipitation/nowcast.js";
+import { cacheNowcast, getCachedNowcast } from "./precipitation/store.js";
+import type { PrecipitationNowcast, PrecipitationSettings } from "./precipitation/types.js";
+import {
+  DEFAULT_HIGH_MGDL,
+  DEFAULT_LOW_MGDL,
+  parseFollowerAccounts,
+  PERSON_COLORS,
+  PRIMARY_PERSON_ID,
+  resolvePeople,
+} from "./followers/people.js";
+import type { FollowerAccount, GlucosePerson, PersonGlucoseData } from "./followers/types.js";
+import { createSpotifyClient, type SpotifyClient } from "./spotify/client.js";
+import type { NowPlaying } from "./spotify/types.js";
+import { deleteNotification, getNotifications, saveNotification } from "./notifications/store.js";
+import { advanceNotifications } from "./notifications/queue.js";
+import type { DisplayNotification } from "./notifications/types.js";
+import { isScreenOn } from "./display/sleep.js";
+import { getScreenOn, saveScreenOn } from "./display/screen-store.js";
+import { getManualTreatments, mergeTreatments } from "./treatments/manual-store.js";
+import { evaluateAlerts } from "./alerts/engine.js";
+import { applySuppression, DEFAULT_ALERT_RULES, rateThresholds, signalLossMinutes, URGENT_ALERT_TYPES } from "./alerts/rules.js";
+import { getAlertRules } from "./alerts/rules-store.js";
+import { applyAcknowledgments } from "./alerts/acknowledgment.js";
+import { getAlertAcknowledgments, saveAlertAcknowledgments } from "./alerts/acknowledgment-store.js";
+import {
+  filterGlucoseReadings,
+  sanitizeTemperaturesF,
+  recordRejections,
+  getRejectionCounts,
+  resetRejectionCounts,
+} from "./ingest/sanity-filters.js";
+import type { AlertRules, GlucoseAlert, GlucoseSample } from "./alerts/types.js";
+import { queryHistory, storeDataPoint, storeDataPoints } from "./widgets/history-store.js";
+import { dexcomFetchWindow, mergeHistoryPoints, type BloodSugarHistoryValue } from "./widgets/history-api.js";
+import { HISTORY_CONFIG as BG_HISTORY_CONFIG, readingToTimeSeriesPoint } from "./widgets/updaters/blood-sugar.js";
+import { isBackingOff, type DexcomBackoffState } from "./dexcom/backoff.js";
+import { getDexcomBackoff, recordDexcomFailure, clearDexcomBackoff } from "./dexcom/backoff-store.js";
+import { getAnnotations } from "./annotations/store.js";
+import type { Annotation } from "./annotations/types.js";
+import { getCompositorStatus, saveCompositorStatus } from "./status/store.js";
+import { findStuckSurfaces, hashFrame, updateFrameStreaks, STUCK_AFTER_MS } from "./status/frame-streaks.js";
+import type { FrameStreak } from "./status/types.js";
+import type { CompositorStatus } from "./status/types.js";
+import { recordSendResults, getDeviceStats } from "./devices/stats-store.js";
+import { deviceIdFor } from "./devices/stats.js";
+import { getDeviceSettings, acquireSendSlot, type DeviceSettings } from "./devices/limits-store.js";
+import { allowsAnimation } from "./devices/send-limits.js";
+import { isInMaintenanceWindow } from "./devices/maintenance.js";
+import type { DeviceSendStats, SendResult } from "./devices/types.js";
+import { createApiGatewaySender, type FrameSender } from "./devices/frame-sender.js";
+import { getPushHolds } from "./devices/push-store.js";
+import { saveDeviceFrames, type CachedFrame } from "./devices/frame-cache.js";
+import { getActiveAlertTypes, recordEvent, recordEvents, saveActiveAlertTypes } from "./events/store.js";
+import { alertEvents } from "./events/transitions.js";
+import { getTextBanner } from "./banner/store.js";
+import { bannerText } from "./banner/template.js";
+import type { TextBanner } from "./banner/types.js";
+import {
+  isBackInRange,
+  coversTirDay,
+  matchSceneRule,
+  SCENE_EVENT_COLORS,
+  type SceneEvent,
+  type SceneRule,
+} from "./scenes/events.js";
+import { claimScene, recordTirBest } from "./scenes/store.js";
+import { DEFAULT_PROFILE_ID, profileUserId } from "./profiles/profile.js";
+import { runBeforeCompose, runAfterCompose } from "./hooks/compose.js";
+import { composeHooks } from "./hooks/registry.js";
+import { publishMqtt, takeRetainedMqtt } from "./mqtt/client.js";
+import { buildMqttMessages, mqttPrefixFromUrl } from "./mqtt/messages.js";
+import {
+  applyHomeAssistantCommands,
+  buildHomeAssistantDiscovery,
+  buildHomeAssistantState,
+  homeAssistantCommandFilter,
+  isNightMode,
+  parseHomeAssistantCommands,
+} from "./mqtt/home-assistant.js";
 
 const ddbClient = new DynamoDBClient({});
 const ddb = DynamoDBDocumentClient.from(ddbClient);
 
+/**
+ * Frames per transition, including the final frame.
+ * Each frame is ~16KB base64; 4 keeps the message well under the 128KB WebSocket limit.
+ */
+const TRANSITION_STEPS = 4;
+
+/**
+ * Frames per scene, including the final frame. Two more than a transition;
+ * still under the WebSocket message limit.
+ */
+const SCENE_STEPS = 6;
+
+/** Delay between transition frames on the client */
+const TRANSITION_FRAME_DELAY_MS = 120;
+
+/** API Gateway's WebSocket message limit */
+const MAX_MESSAGE_BYTES = 128 * 1024;
+
+/**
+ * Seconds clock ticks run this far into the next minute, so the clock keeps
+ * going until the next run's frame arrives (which cancels the rest)
+ */
+const CLOCK_TICK_OVERLAP_MS = 10_000;
+
+/** Hours of glucose history shown on the chart */
+const BG_HISTORY_HOURS = 24;
+
 // Stale threshold: 10 minutes
 const STALE_THRESHOLD_MS = 10 * 60 * 1000;
 
@@ -100,6 +250,13 @@ function isStale(timestamp: number): boolean {
   return Date.now() - timestamp >= STALE_THRESHOLD_MS;
 }
 
+/**
+ * Message of a caught error, for logs and stored state
+ */
+function errorMessage(error: unknown): string {
+  return error instanceof Error ? error.message : String(error);
+}
+
 /**
  * Chart point for history
  */
@@ -167,36 +324,109 @@ async function getCachedBgData(): Promise<{
   return { current: null, history: [] };
 }
 
+/**
+ * Fetch the Dexcom backoff state; a read failure means no backoff
+ */
+async function fetchDexcomBackoff(): Promise<DexcomBackoffState | null> {
+  try {
+    return await getDexcomBackoff();
+  } catch (error) {
+    console.error("Failed to fetch Dexcom backoff:", error);
+    return null;
+  }
+}
+
+/**
+ * Extend the Dexcom backoff after a failed run, or clear it after a clean one
+ */
+async function updateDexcomBackoff(state: DexcomBackoffState | null, error: string | null): Promise<void> {
+  try {
+    if (error) {
+      const next = await recordDexcomFailure(state, error);
+      console.log(`Dexcom backoff: ${next.failures} failed run(s), next attempt at ${new Date(next.retryAt).toISOString()}`);
+    } else if (state) {
+      await clearDexcomBackoff();
+      console.log(`Dexcom recovered after ${state.failures} failed run(s)`);
+    }
+  } catch (err) {
+    console.error("Failed to update Dexcom backoff:", err);
+  }
+}
+
+/**
+ * Check the shared Dexcom request budget for this run's calls.
+ * A budget that can't be checked doesn't block the run.
+ */
+async function mayCallDexcom(rateLimit: DexcomRateLimit, calls: number = DEXCOM_CALLS_PER_RUN): Promise<boolean> {
+  try {
+    return await takeDexcomTokens(calls, rateLimit);
+  } catch (error) {
+    console.error("Failed to check Dexcom request budget:", error);
+    return true;
+  }
+}
+
 /**
  * Fetch blood sugar data and history from Dexcom.
- * Falls back to cached data when Dexcom API fails.
+ * Falls back to cached data when Dexcom API fails, and skips Dexcom
+ * entirely while backing off after repeated errors or when the request
+ * budget is spent.
  * Handles partial failures: preserves fresh current reading even if history fetch fails.
+ * `dexcomUnreachable` is set when Dexcom wasn't reached this run, so the
+ * display can tell an outage from having no data yet.
+ * Exported for tests, which pass a fake fetcher.
  */
-async function fetchBloodSugarData(): Promise<{
+export async function fetchBloodSugarData(
+  rateLimit: DexcomRateLimit = DEFAULT_DEXCOM_RATE_LIMIT,
+  fetcher: GlucoseFetcher = createDexcomFetcher({
+    username: Resource.DexcomUsername.value,
+    password: Resource.DexcomPassword.value,
+  })
+): Promise<{
   current: BloodSugarDisplayData | null;
   history: ChartPoint[];
+  dexcomUnreachable: boolean;
 }> {
-  let sessionId: string;
+  const backoff = await fetchDexcomBackoff();
+  if (backoff && isBackingOff(backoff, Date.now())) {
+    console.log(`Dexcom backing off until ${new Date(backoff.retryAt).toISOString()}, using cached BG data`);
+    return { ...(await getCachedBgData()), dexcomUnreachable: true };
+  }
+
+  if (!(await mayCallDexcom(rateLimit))) {
+    console.warn("Dexcom request budget spent, using cached BG data");
+    const cached = await getCachedBgData();
+    return {
+      ...cached,
+      current: cached.current && { ...cached.current, rateLimited: true },
+      dexcomUnreachable: false,
+    };
+  }
+
+  // Reuse the stored session, or log in now so an auth failure falls back
+  // to cached data before any fetch
   try {
-    sessionId = await getSessionId({
-      username: Resource.DexcomUsername.value,
-      password: Resource.DexcomPassword.value,
-    });
+    await fetcher.authenticate();
   } catch (error) {
     console.error("Dexcom auth failed:", error);
     console.log("Falling back to cached BG data");
-    return getCachedBgData();
+    await updateDexcomBackoff(backoff, errorMessage(error));
+    return { ...(await getCachedBgData()), dexcomUnreachable: true };
   }
 
+  // Any Dexcom error this run extends the backoff
+  let dexcomError: string | null = null;
+
   // Fetch current and history independently so a history failure
   // doesn't discard a successful current reading
   let current: BloodSugarDisplayData | null = null;
   let history: ChartPoint[] = [];
 
   try {
-    const readings = await fetchGlucoseReadings(sessionId, 30, 2);
+    const { accepted: readings, rejected } = filterGlucoseReadings(await fetcher.fetchReadings(30, 2));
+    recordRejections("dexcom", rejected);
 
-    if (readings && readings.length > 0) {
+    if (readings.length > 0) {
       const latest = readings[0];
       const previous = readings[1];
 
@@ -215,34 +445,158 @@ async function fetchBloodSugarData(): Promise<{
     }
   } catch (error) {
     console.error("Failed to fetch current BG reading:", error);
+    dexcomError = errorMessage(error);
+  }
+
+  // History comes from the stored time series; Dexcom is only asked for
+  // readings since the newest stored one, so an outage leaves a small gap
+  // instead of an empty chart
+  const since = Date.now() - BG_HISTORY_HOURS * 60 * 60 * 1000;
+  let stored: ChartPoint[] = [];
+  try {
+    stored = (await queryHistory<BloodSugarHistoryValue>("bloodsugar", since, Date.now(), DEFAULT_PROFILE_ID)).map((p) => ({
+      timestamp: p.timestamp,
+      glucose: p.value.glucose,
+    }));
+  } catch (error) {
+    console.error("Failed to read stored BG history:", error);
   }
 
+  let fetched: ChartPoint[] = [];
   try {
-    const historyReadings = await fetchGlucoseReadings(sessionId, 1440, 300);
+    const lastStoredAt = stored.length > 0 ? stored[stored.length - 1].timestamp : null;
+    const { minutes, maxCount } = dexcomFetchWindow(lastStoredAt);
+    const { accepted: historyReadings, rejected } = filterGlucoseReadings(
+      await fetcher.fetchReadings(minutes, maxCount)
+    );
+    recordRejections("dexcom-history", rejected);
 
     // Dual-write: store readings for agent analysis (fire-and-forget)
-    void storeCgmReadingsForAgent(historyReadings || []);
+    void storeCgmReadingsForAgent(historyReadings);
+
+    // Readings come newest first
+    const chronological = historyReadings
+      .filter((r) => parseDexcomTimestamp(r.WT) > 0)
+      .reverse();
+    const newPoints = chronological
+      .map((reading, idx) => readingToTimeSeriesPoint(reading, chronological[idx - 1]))
+      .filter((p) => lastStoredAt === null || p.timestamp > lastStoredAt);
+    if (newPoints.length > 0) {
+      try {
+        await storeDataPoints("bloodsugar", newPoints, BG_HISTORY_CONFIG, DEFAULT_PROFILE_ID);
+      } catch (error) {
+        console.error("Failed to store BG history:", error);
+      }
+    }
 
-    history = historyReadings
-      .map((r) => ({
-        timestamp: parseDexcomTimestamp(r.WT),
-        glucose: r.Value,
-      }))
-      .filter((p) => p.timestamp > 0)
-      .reverse(); // Oldest first
+    fetched = chronological.map((r) => ({ timestamp: parseDexcomTimestamp(r.WT), glucose: r.Value }));
   } catch (error) {
     console.error("Failed to fetch BG history:", error);
+    dexcomError ??= errorMessage(error);
   }
 
+  await updateDexcomBackoff(backoff, dexcomError);
+
+  history = mergeHistoryPoints(stored, fetched).filter((p) => p.timestamp >= since);
+
   // Cache successful data for future fallback
   if (current) {
     void cacheBgData(current, history);
-    return { current, history };
+    return { current, history, dexcomUnreachable: false };
   }
 
   // No current reading from API — fall back to cache
   console.log("No current BG reading, falling back to cached data");
-  return getCachedBgData();
+  return { ...(await getCachedBgData()), dexcomUnreachable: dexcomError !== null };
+}
+
+/** Readings asked of each follower account: the split layout's chart span */
+const FOLLOWER_READING_MINUTES = SPLIT_CHART_HOURS * 60;
+const FOLLOWER_MAX_READINGS = FOLLOWER_READING_MINUTES / 5;
+
+/** Last readings per follower account, for runs that can't fetch them (warm instances only) */
+const followerCache = new Map<string, { reading: BloodSugarDisplayData | null; history: ChartPoint[] }>();
+
+/**
+ * Fetch a follower account's latest reading and the split chart's history
+ * in one call, with the account's own stored session
+ */
+async function fetchFollowerGlucose(
+  account: FollowerAccount
+): Promise<{ reading: BloodSugarDisplayData | null; history: ChartPoint[] }> {
+  const fetcher = createDexcomFetcher(
+    { username: account.username, password: account.password },
+    `FOLLOWER#${account.id}`
+  );
+  const { accepted, rejected } = filterGlucoseReadings(
+    await fetcher.fetchReadings(FOLLOWER_READING_MINUTES, FOLLOWER_MAX_READINGS)
+  );
+  recordRejections(`dexcom-${account.id}`, rejected);
+
+  // Readings come newest first
+  const chronological = accepted.filter((r) => parseDexcomTimestamp(r.WT) > 0).reverse();
+  const history = chronological.map((r) => ({ timestamp: parseDexcomTimestamp(r.WT), glucose: r.Value }));
+  const latest = chronological[chronological.length - 1];
+  if (!latest) return { reading: null, history };
+
+  const previous = chronological[chronological.length - 2];
+  const timestamp = parseDexcomTimestamp(latest.WT);
+  return {
+    reading: {
+      glucose: latest.Value,
+      trend: latest.Trend,
+      delta: previous ? latest.Value - previous.Value : 0,
+      timestamp,
+      rangeStatus: classifyRange(latest.Value),
+      isStale: isStale(timestamp),
+    },
+    history,
+  };
+}
+
+/**
+ * Each person on the split layout: the main account from this run's fetch,
+ * followers fetched here with one call each from the same request budget.
+ * A follower that can't be fetched keeps its last reading, graying out as
+ * it ages.
+ */
+async function fetchPeopleGlucose(
+  settings: GlucosePerson[] | undefined,
+  primary: { current: BloodSugarDisplayData | null; history: ChartPoint[] },
+  rateLimit: DexcomRateLimit = DEFAULT_DEXCOM_RATE_LIMIT
+): Promise<PersonGlucoseData[]> {
+  const accounts = parseFollowerAccounts(Resource.DexcomFollowers.value);
+  const people = resolvePeople(settings, accounts);
+  const followers = accounts.filter((account) => people.some((person) => person.id === account.id));
+
+  if (followers.length > 0 && (await mayCallDexcom(rateLimit, followers.length))) {
+    await Promise.all(
+      followers.map(async (account) => {
+        try {
+          followerCache.set(account.id, await fetchFollowerGlucose(account));
+        } catch (error) {
+          console.error(`Failed to fetch Dexcom follower ${account.id}:`, error);
+        }
+      })
+    );
+  } else if (followers.length > 0) {
+    console.warn("Dexcom request budget spent, using last follower readings");
+  }
+
+  return people.map((person, i) => {
+    const glucose =
+      person.id === PRIMARY_PERSON_ID
+        ? { reading: primary.current, history: primary.history }
+        : followerCache.get(person.id) ?? { reading: null, history: [] };
+    return {
+      name: person.name,
+      low: person.low ?? DEFAULT_LOW_MGDL,
+      high: person.high ?? DEFAULT_HIGH_MGDL,
+      color: person.color ?? PERSON_COLORS[i % PERSON_COLORS.length],
+      reading: glucose.reading && { ...glucose.reading, isStale: isStale(glucose.reading.timestamp) },
+      history: glucose.history,
+    };
+  });
 }
 
 // Seattle coordinates (Fremont area)
@@ -340,7 +694,8 @@ export async function fetchWeatherData(): Promise<ClockWeatherData | null> {
       return null;
     }
 
-    const temps = data.hourly.temperature_2m;
+    const { values: temps, rejected } = sanitizeTemperaturesF(data.hourly.temperature_2m);
+    recordRejections("weather", rejected);
     const clouds = data.hourly.cloudcover || [];
     const precip = data.hourly.precipitation || [];
     const snow = data.hourly.snowfall || [];
@@ -411,9 +766,6 @@ function formatDatePacific(date: Date): string {
   }).format(date);
 }
 
-/** Default user ID for daily insulin queries */
-const DEFAULT_USER_ID = "john";
-
 /**
  * Fetch daily insulin totals from DAILY_INSULIN records
  * Uses the shared query function from @diabetes/core.
@@ -433,7 +785,7 @@ async function fetchDailyInsulinTotals(): Promise<Record<string, number>> {
     const totals = await queryDailyInsulinByDateRange(
       ddb,
       Resource.SignageTable.name,
-      DEFAULT_USER_ID,
+      profileUserId(DEFAULT_PROFILE_ID),
       startDate,
       endDate
     );
@@ -447,12 +799,25 @@ async function fetchDailyInsulinTotals(): Promise<Record<string, number>> {
 }
 
 /**
- * Fetch treatment data from DynamoDB (populated by Glooko scraper)
+ * Fetch manual treatment entries from the last 24 hours
+ */
+async function fetchManualTreatments() {
+  try {
+    return await getManualTreatments(Date.now() - 24 * 60 * 60 * 1000);
+  } catch (error) {
+    console.error("Failed to fetch manual treatments:", error);
+    return [];
+  }
+}
+
+/**
+ * Fetch treatment data from DynamoDB (populated by Glooko scraper),
+ * merged with manual entries from the treatments API
  */
 async function fetchTreatmentData(): Promise<TreatmentDisplayData | null> {
   try {
-    // Fetch treatments and daily totals in parallel
-    const [treatmentsResult, dailyInsulinTotals] = await Promise.all([
+    // Fetch treatments, daily totals, and manual entries in parallel
+    const [treatmentsResult, dailyInsulinTotals, manualTreatments] = await Promise.all([
       ddb.send(
         new GetCommand({
           TableName: Resource.SignageTable.name,
@@ -463,15 +828,16 @@ async function fetchTreatmentData(): Promise<TreatmentDisplayData | null> {
         })
       ),
       fetchDailyInsulinTotals(),
+      fetchManualTreatments(),
     ]);
 
-    if (!treatmentsResult.Item) {
+    if (!treatmentsResult.Item && manualTreatments.length === 0) {
       return null;
     }
 
-    const item = treatmentsResult.Item as GlookoTreatmentsItem;
-    const treatments = item.treatments || [];
-    const lastFetchedAt = item.lastFetchedAt || 0;
+    const item = treatmentsResult.Item as GlookoTreatmentsItem | undefined;
+    const treatments = mergeTreatments(item?.treatments || [], manualTreatments);
+    const lastFetchedAt = item?.lastFetchedAt || 0;
     const isStale = Date.now() - lastFetchedAt > TREATMENT_STALE_THRESHOLD_MS;
 
     // Calculate totals for last 4 hours
@@ -496,7 +862,7 @@ async function fetchTreatmentData(): Promise<TreatmentDisplayData | null> {
  */
 async function fetchCurrentInsight(): Promise<InsightDisplayData | null> {
   try {
-    const insight = await getCurrentInsight(ddb, Resource.SignageTable.name, DEFAULT_USER_ID);
+    const insight = await getCurrentInsight(ddb, Resource.SignageTable.name, profileUserId(DEFAULT_PROFILE_ID));
     if (!insight) {
       return null;
     }
@@ -507,6 +873,514 @@ async function fetchCurrentInsight(): Promise<InsightDisplayData | null> {
   }
 }
 
+/**
+ * Resolve the layout and transition from the stored display config
+ */
+async function fetchDisplaySettings(): Promise<{
+  layout: LayoutDefinition;
+  transition: DisplayConfig["transition"];
+  powerLimit: DisplayConfig["powerLimit"];
+  hiddenLayers: DisplayConfig["hiddenLayers"];
+  sceneRules: DisplayConfig["sceneRules"];
+  locale: DisplayConfig["locale"];
+  dexcomRateLimit: DisplayConfig["dexcomRateLimit"];
+  sleepSchedule: DisplayConfig["sleepSchedule"];
+  away: DisplayConfig["away"];
+  clockSeconds: DisplayConfig["clockSeconds"];
+  moonPhase: DisplayConfig["moonPhase"];
+  chartAntialias: DisplayConfig["chartAntialias"];
+  chartFill: DisplayConfig["chartFill"];
+  ticker: DisplayConfig["ticker"];
+  networkMonitor: DisplayConfig["networkMonitor"];
+  precipitation: DisplayConfig["precipitation"];
+  people: DisplayConfig["people"];
+  timezone: string;
+}> {
+  try {
+    const config = await getDisplayConfig();
+    const timezone = config.timezone ?? DEFAULT_TIMEZONE;
+    const now = Date.now();
+    return {
+      layout: resolveLayout(config, now, timezone),
+      // A rotation's page turns get its own transition, if it has one
+      transition: isPageTurn(config, now) ? (config.pageRotation?.transition ?? config.transition) : config.transition,
+      powerLimit: config.powerLimit,
+      hiddenLayers: config.hiddenLayers,
+      sceneRules: config.sceneRules,
+      locale: config.locale,
+      dexcomRateLimit: config.dexcomRateLimit,
+      sleepSchedule: config.sleepSchedule,
+      away: config.away,
+      clockSeconds: config.clockSeconds,
+      moonPhase: config.moonPhase,
+      chartAntialias: config.chartAntialias,
+      chartFill: config.chartFill,
+      ticker: config.ticker,
+      networkMonitor: config.networkMonitor,
+      precipitation: config.precipitation,
+      people: config.people,
+      timezone,
+    };
+  } catch (error) {
+    console.error("Failed to fetch display config:", error);
+    return {
+      layout: getLayout(undefined),
+      transition: undefined,
+      powerLimit: undefined,
+      hiddenLayers: undefined,
+      sceneRules: undefined,
+      locale: undefined,
+      dexcomRateLimit: undefined,
+      sleepSchedule: undefined,
+      away: undefined,
+      clockSeconds: undefined,
+      moonPhase: undefined,
+      chartAntialias: undefined,
+      chartFill: undefined,
+      ticker: undefined,
+      networkMonitor: undefined,
+      precipitation: undefined,
+      people: undefined,
+      timezone: DEFAULT_TIMEZONE,
+    };
+  }
+}
+
+/**
+ * Fetch annotations covering the glucose chart (last 24 hours)
+ */
+async function fetchAnnotations(): Promise<Annotation[]> {
+  try {
+    return await getAnnotations(Date.now() - 24 * 60 * 60 * 1000);
+  } catch (error) {
+    console.error("Failed to fetch annotations:", error);
+    return [];
+  }
+}
+
+/**
+ * Fetch the display lock, if one is active
+ */
+async function fetchDisplayLock(): Promise<DisplayLock | null> {
+  try {
+    return await getDisplayLock();
+  } catch (error) {
+    console.error("Failed to fetch display lock:", error);
+    return null;
+  }
+}
+
+/**
+ * Fetch the pomodoro timer, if one is running
+ */
+async function fetchPomodoroTimer(): Promise<PomodoroTimer | null> {
+  try {
+    return await getPomodoroTimer();
+  } catch (error) {
+    console.error("Failed to fetch pomodoro timer:", error);
+    return null;
+  }
+}
+
+/**
+ * Fetch quotes for the ticker page. Each symbol's day of stored quotes is
+ * read for its sparkline; the quote APIs are only called once the newest
+ * stored quote is older than the refresh interval, and what they return is
+ * stored in turn.
+ */
+async function fetchTickerData(settings: TickerSettings | undefined): Promise<TickerDisplayData> {
+  const symbols = settings?.symbols ?? [];
+  const sparkline = settings?.sparkline ?? false;
+  if (symbols.length === 0) {
+    return { quotes: [], sparkline };
+  }
+
+  const now = Date.now();
+  const since = now - TICKER_HISTORY_HOURS * 60 * 60 * 1000;
+  const histories = await Promise.all(
+    symbols.map(async (symbol) => {
+      try {
+        return await queryHistory<TickerHistoryValue>(tickerHistoryId(symbol), since, now);
+      } catch (error) {
+        console.error(`Failed to read ${symbol} history:`, error);
+        return [];
+      }
+    })
+  );
+
+  let fresh: TickerQuote[] = [];
+  if (isTickerRefreshDue(histories.map((history) => history[history.length - 1]?.timestamp ?? null), now)) {
+    fresh = await fetchTickerQuotes(symbols, now);
+    console.log(`Ticker: fetched ${fresh.length}/${symbols.length} quotes`);
+    await Promise.all(
+      fresh.map(async (quote) => {
+        try {
+          await storeDataPoint(tickerHistoryId(quote.symbol), quoteToTimeSeriesPoint(quote), TICKER_HISTORY_CONFIG);
+        } catch (error) {
+          console.error(`Failed to store ${quote.symbol} quote:`, error);
+        }
+      })
+    );
+  }
+
+  const quotes = symbols.flatMap((symbol, i) => {
+    const label = parseTickerSymbol(symbol)?.label ?? symbol;
+    const quote = toDisplayQuote(symbol, label, histories[i], fresh.find((q) => q.symbol === symbol));
+    return quote ? [quote] : [];
+  });
+  return { quotes, sparkline };
+}
+
+/**
+ * Measure the network for its page: a ping (and the download when it's due)
+ * each run, stored with the past few hours of samples. Nothing is measured
+ * until a host is configured.
+ */
+async function fetchNetworkData(settings: NetworkMonitorSettings | undefined): Promise<NetworkDisplayData | undefined> {
+  if (!settings) {
+    return undefined;
+  }
+
+  const now = Date.now();
+  let samples: NetworkDisplayData["samples"] = [];
+  try {
+    const history = await queryHistory<NetworkHistoryValue>(
+      NETWORK_WIDGET_ID,
+      now - NETWORK_HISTORY_HOURS * 60 * 60 * 1000,
+      now
+    );
+    samples = history.map(timeSeriesPointToSample);
+  } catch (error) {
+    console.error("Failed to read network history:", error);
+  }
+
+  const sample = await takeNetworkSample(settings, samples, now);
+  console.log(
+    `Network: ${settings.host} ${sample.pingMs === null ? "timed out" : `${sample.pingMs}ms`}` +
+      (sample.downloadMbps !== undefined ? `, download ${sample.downloadMbps ?? "failed"} Mbps` : "")
+  );
+  try {
+    await storeDataPoint(NETWORK_WIDGET_ID, sampleToTimeSeriesPoint(sample), NETWORK_HISTORY_CONFIG);
+  } catch (error) {
+    console.error("Failed to store network sample:", error);
+  }
+
+  return { host: settings.host, samples: [...samples, sample] };
+}
+
+/**
+ * Fetch the rain nowcast for the configured location. The cached one is
+ * used until Open-Meteo has had time to update it, and kept if a refresh
+ * fails.
+ */
+async function fetchPrecipitationData(
+  settings: PrecipitationSettings | undefined
+): Promise<PrecipitationNowcast | null> {
+  if (!settings) {
+    return null;
+  }
+
+  let cached: PrecipitationNowcast | null = null;
+  try {
+    cached = await getCachedNowcast();
+  } catch (error) {
+    console.error("Failed to read cached nowcast:", error);
+  }
+  if (!isNowcastRefreshDue(cached, settings)) {
+    return cached;
+  }
+
+  const nowcast = await fetchPrecipitationNowcast(settings);
+  if (!nowcast) {
+    // Older steps for the same place still cover the minutes they haven't run out of
+    const samePlace = cached?.latitude === settings.latitude && cached?.longitude === settings.longitude;
+    return samePlace ? cached : null;
+  }
+  try {
+    await cacheNowcast(nowcast);
+  } catch (error) {
+    console.error("Failed to cache nowcast:", error);
+  }
+  return nowcast;
+}
+
+// Kept across warm invocations so its access token is reused
+let spotifyClient: SpotifyClient | null = null;
+
+/**
+ * Fetch what Spotify is playing, when a refresh token is set
+ */
+async function fetchNowPlaying(): Promise<NowPlaying | null> {
+  const refreshToken = Resource.SpotifyRefreshToken.value;
+  if (!refreshToken) {
+    return null;
+  }
+  spotifyClient ??= createSpotifyClient({
+    clientId: Resource.SpotifyClientId.value,
+    clientSecret: Resource.SpotifyClientSecret.value,
+    refreshToken,
+  });
+  try {
+    return await spotifyClient.getNowPlaying();
+  } catch (error) {
+    console.error("Failed to fetch now playing:", error);
+    return null;
+  }
+}
+
+/**
+ * Move the notification queue on and return the notification to show.
+ * Shown and expired ones are dropped, and the start time of the one going
+ * up is saved, so it comes down after its time.
+ */
+async function fetchNotification(now: number): Promise<DisplayNotification | null> {
+  try {
+    const { current, removed, updated } = advanceNotifications(await getNotifications(), now);
+    await Promise.all([
+      ...removed.map((n) => deleteNotification(n.id)),
+      ...updated.map((n) => saveNotification(n)),
+    ]);
+    if (current) {
+      console.log(`Notification (${current.priority}): "${current.text}"`);
+    }
+    return current;
+  } catch (error) {
+    console.error("Failed to fetch notifications:", error);
+    return null;
+  }
+}
+
+/**
+ * Fetch whether the screens were last turned on (assumes on if unreadable)
+ */
+async function fetchScreenOn(): Promise<boolean> {
+  try {
+    return await getScreenOn();
+  } catch (error) {
+    console.error("Failed to fetch screen state:", error);
+    return true;
+  }
+}
+
+/**
+ * Fetch the identical-frame streaks recorded by the last run
+ */
+async function fetchFrameStreaks(): Promise<Record<string, FrameStreak> | undefined> {
+  try {
+    return (await getCompositorStatus())?.frameStreaks;
+  } catch (error) {
+    console.error("Failed to fetch frame streaks:", error);
+    return undefined;
+  }
+}
+
+/**
+ * Hash the frame and each data widget showing in it, so unchanged
+ * surfaces can be tracked across runs
+ */
+function hashSurfaces(frame: Frame, widgets: LayoutWidget[]): Record<string, string> {
+  const hashes: Record<string, string> = { frame: hashFrame(frame) };
+  for (const widget of widgets) {
+    if (!Object.prototype.hasOwnProperty.call(STUCK_AFTER_MS, widget)) continue;
+    const region = WIDGET_REGIONS[widget];
+    hashes[widget] = hashFrame(subFrame(frame, region.x, region.y, region.width, region.height));
+  }
+  return hashes;
+}
+
+/**
+ * Fetch the previously broadcast frame from the frame cache
+ */
+async function fetchPreviousFrame(): Promise<Frame | null> {
+  try {
+    const result = await ddb.send(
+      new GetCommand({
+        TableName: Resource.SignageTable.name,
+        Key: { pk: "FRAME_CACHE", sk: "LATEST" },
+      })
+    );
+    const item = result.Item;
+    if (!item?.frameData) return null;
+    return decodeBase64ToPixels(
+      item.frameData as string,
+      (item.width as number) ?? DISPLAY_WIDTH,
+      (item.height as number) ?? DISPLAY_HEIGHT
+    );
+  } catch (error) {
+    console.error("Failed to fetch previous frame:", error);
+    return null;
+  }
+}
+
+/**
+ * Build the in-between frames leading from the previous frame to the new one.
+ * Returns an empty list when transitions are off or nothing changed.
+ */
+function buildTransition(
+  previous: Frame | null,
+  frame: Frame,
+  transition: DisplayConfig["transition"]
+): Frame[] {
+  if (!transition || transition === "none" || !previous) return [];
+  if (previous.pixels.length === frame.pixels.length && previous.pixels.every((v, i) => v === frame.pixels[i])) {
+    return [];
+  }
+  return createTransitionFrames(previous, frame, { type: transition, steps: TRANSITION_STEPS }).slice(0, -1);
+}
+
+/**
+ * Build a scene for the first scene rule whose event just happened.
+ * Returns an empty list when no rule fires or its scene played recently;
+ * errors only cost the scene, never the frame.
+ */
+async function buildScene(
+  rules: SceneRule[] | undefined,
+  samples: GlucoseSample[],
+  frame: Frame
+): Promise<Frame[]> {
+  if (!rules || rules.length === 0) return [];
+  try {
+    const events: SceneEvent[] = [];
+    if (isBackInRange(samples)) {
+      events.push("backInRange");
+    }
+    if (rules.some((rule) => rule.event === "tirRecord") && coversTirDay(samples)) {
+      const tir = calculateTIR(samples);
+      if (tir !== null && (await recordTirBest(tir))) {
+        console.log(`New time in range record: ${tir}%`);
+        events.push("tirRecord");
+      }
+    }
+
+    const rule = matchSceneRule(rules, events);
+    if (!rule || !(await claimScene(rule.event))) return [];
+
+    console.log(`Playing ${rule.scene} scene for ${rule.event}`);
+    return createSceneFrames(frame, {
+      type: rule.scene,
+      color: SCENE_EVENT_COLORS[rule.event],
+      steps: SCENE_STEPS,
+      seed: Date.now(),
+    }).slice(0, -1);
+  } catch (error) {
+    console.error("Failed to build scene:", error);
+    return [];
+  }
+}
+
+/**
+ * Fetch alert rules, falling back to defaults
+ */
+async function fetchAlertRules(): Promise<AlertRules> {
+  try {
+    return await getAlertRules();
+  } catch (error) {
+    console.error("Failed to fetch alert rules:", error);
+    return DEFAULT_ALERT_RULES;
+  }
+}
+
+/**
+ * Hide snoozed alerts, saving any snooze that ended or got its baseline.
+ * Shows everything if the snoozes can't be read.
+ */
+async function applyAlertAcknowledgments(
+  alerts: GlucoseAlert[],
+  glucose: number | null,
+  now: number
+): Promise<GlucoseAlert[]> {
+  try {
+    const acknowledgments = await getAlertAcknowledgments();
+    if (acknowledgments.length === 0) return alerts;
+    const state = applyAcknowledgments(alerts, acknowledgments, glucose, now);
+    if (state.changed) {
+      await saveAlertAcknowledgments(state.acknowledgments);
+    }
+    return state.alerts;
+  } catch (error) {
+    console.error("Failed to apply alert acknowledgments:", error);
+    return alerts;
+  }
+}
+
+/**
+ * Log alerts that fired or cleared since the last run. Skipped if the
+ * last run's alerts can't be read, rather than logging them all again.
+ */
+async function logAlertTransitions(alerts: GlucoseAlert[], now: number): Promise<void> {
+  try {
+    const previous = await getActiveAlertTypes();
+    const events = alertEvents(previous, alerts, now);
+    if (events.length === 0) return;
+    await recordEvents(events);
+    await saveActiveAlertTypes(alerts.map((a) => a.type));
+  } catch (error) {
+    console.error("Failed to log alert transitions:", error);
+  }
+}
+
+/**
+ * Fetch per-device send statistics for the diagnostics page
+ */
+async function fetchDeviceStats(): Promise<DeviceSendStats[]> {
+  try {
+    return await getDeviceStats();
+  } catch (error) {
+    console.error("Failed to fetch device stats:", error);
+    return [];
+  }
+}
+
+/**
+ * Fetch per-device send limits and maintenance windows (none on failure)
+ */
+async function fetchDeviceSettings(): Promise<DeviceSettings> {
+  try {
+    return await getDeviceSettings();
+  } catch (error) {
+    console.error("Failed to fetch device settings:", error);
+    return { minIntervalMs: {}, maintenanceWindows: {}, rotations: {}, models: {}, layouts: {} };
+  }
+}
+
+/**
+ * Fetch the text banner (none on failure)
+ */
+async function fetchTextBanner(): Promise<TextBanner | null> {
+  try {
+    return await getTextBanner();
+  } catch (error) {
+    console.error("Failed to fetch text banner:", error);
+    return null;
+  }
+}
+
+/**
+ * Fetch the devices holding a pushed frame (none on failure, so the
+ * display isn't left stale)
+ */
+async function fetchPushHolds(): Promise<Record<string, number>> {
+  try {
+    return await getPushHolds();
+  } catch (error) {
+    console.error("Failed to fetch pushed frames:", error);
+    return {};
+  }
+}
+
+/**
+ * Wait for a rate-limited device's send slot. Sends anyway if the slot
+ * can't be checked, so a store outage doesn't blank the display.
+ */
+async function mayStartSend(deviceId: string, minIntervalMs: number): Promise<boolean> {
+  try {
+    return await acquireSendSlot(deviceId, minIntervalMs);
+  } catch (error) {
+    console.error(`Failed to check send slot for ${deviceId}:`, error);
+    return true;
+  }
+}
+
 /**
  * Get active WebSocket connections
  * Uses Query on pk="CONNECTIONS" for efficient retrieval
@@ -540,13 +1414,23 @@ async function getActiveConnections() {
  */
 async function removeStaleConnection(connectionId: string): Promise<void> {
   try {
-    await ddb.send(
+    const removed = await ddb.send(
       new DeleteCommand({
         TableName: Resource.SignageTable.name,
         Key: { pk: "CONNECTIONS", sk: connectionId },
+        ReturnValues: "ALL_OLD",
       })
     );
     console.log(`Removed stale connection: ${connectionId}`);
+    if (removed?.Attributes) {
+      await recordEvent({
+        timestamp: Date.now(),
+        category: "device",
+        type: "offline",
+        subject: deviceIdFor(removed.Attributes),
+        detail: `${connectionId} (stale)`,
+      });
+    }
   } catch (error) {
     console.error(`Failed to remove stale connection ${connectionId}:`, error);
   }
@@ -554,44 +1438,139 @@ async function removeStaleConnection(connectionId: string): Promise<void> {
 
 /**
  * Broadcast a frame to all connections.
+ * Transition frames, if any, are sent alongside for the client to play first.
  * Automatically cleans up stale connections that return 410 Gone.
+ * Each send's outcome and latency is added to the per-device statistics.
+ * Models with a frame in `renders` (their own layout) get that frame,
+ * without animation, as do devices assigned a layout in `layoutFrames`;
+ * the others get the frame resized to fit. Seconds
+ * clock ticks, if any, go to unrotated panels of the frame's own size.
+ * Exported for tests, which pass a fake sender.
  */
-async function broadcastFrame(
-  apiClient: ApiGatewayManagementApiClient,
-  connections: Array<{ connectionId: string }>,
-  frame: Frame
-): Promise<{ success: number; failed: number; cleaned: number }> {
-  const frameData = encodeFrameToBase64(frame);
-  const message = JSON.stringify({
-    type: "frame",
-    payload: {
-      frame: {
-        width: DISPLAY_WIDTH,
-        height: DISPLAY_HEIGHT,
-        data: frameData,
+export async function broadcastFrame(
+  sender: FrameSender,
+  connections: Array<{ connectionId: string; terminalId?: string | null; terminalType?: string }>,
+  frame: Frame,
+  transitionFrames: Frame[] = [],
+  deviceSettings: DeviceSettings = { minIntervalMs: {}, maintenanceWindows: {}, rotations: {}, models: {}, layouts: {} },
+  renders: Partial<Record<DisplayModel, Frame>> = {},
+  ticks: { stepMs: number; patches: FramePatch[] } = { stepMs: 0, patches: [] },
+  layoutFrames: Record<string, Frame> = {}
+): Promise<{ success: number; failed: number; cleaned: number; throttled: number; paused: number }> {
+  const buildMessage = (
+    model: DisplayModel,
+    rotation: Rotation,
+    layoutName: string | undefined,
+    withAnimation: boolean,
+    withTicks: boolean
+  ) => {
+    const { width, height } = DISPLAY_MODELS[model];
+    // Transitions are between full-size frames, so a model's or a device's
+    // own frame is sent alone
+    const rendered = renders[model] ?? (layoutName !== undefined ? layoutFrames[layoutName] : undefined);
+    const encode = (f: Frame) => {
+      const fitted = fitFrame(f, { width, height });
+      return encodeFrameToBase64(rotation === 0 ? fitted : rotateFrame(fitted, rotation));
+    };
+    const turned = rotation === 90 || rotation === 270;
+    const sent: CachedFrame = {
+      width: turned ? height : width,
+      height: turned ? width : height,
+      data: encode(rendered ?? frame),
+    };
+    const message = {
+      type: "frame",
+      payload: {
+        frame: sent,
+        ...(withAnimation && !rendered && transitionFrames.length > 0 && {
+          animation: {
+            frames: transitionFrames.map(encode),
+            frameDelayMs: TRANSITION_FRAME_DELAY_MS,
+          },
+        }),
       },
-    },
-    timestamp: Date.now(),
-  });
+      timestamp: Date.now(),
+    };
+    // Clock ticks patch the frame as sent, so only panels showing it as it
+    // is get them, and only while the message stays under the limit
+    const native = !rendered && rotation === 0 && width === frame.width && height === frame.height;
+    if (withTicks && native && ticks.patches.length > 0) {
+      const ticked = JSON.stringify({ ...message, payload: { ...message.payload, ticks: ticks.patches } });
+      if (ticked.length <= MAX_MESSAGE_BYTES) {
+        return { message: ticked, sent };
+      }
+      console.warn(`Clock ticks dropped: message would be ${Math.round(ticked.length / 1024)}KB`);
+    }
+    return { message: JSON.stringify(message), sent };
+  };
+  // Most devices share a model and rotation, so each message is built once
+  const messages = new Map<string, { message: string; sent: CachedFrame }>();
+  const messageFor = (
+    model: DisplayModel,
+    rotation: Rotation,
+    layoutName: string | undefined,
+    withAnimation: boolean,
+    withTicks: boolean
+  ) => {
+    const key = `${model}:${rotation}:${layoutName ?? ""}:${withAnimation}:${withTicks}`;
+    let message = messages.get(key);
+    if (message === undefined) {
+      message = buildMessage(model, rotation, layoutName, withAnimation, withTicks);
+      messages.set(key, message);
+    }
+    return message;
+  };
 
   let success = 0;
   let failed = 0;
   let cleaned = 0;
+  let throttled = 0;
+  let paused = 0;
+  const results: SendResult[] = [];
+  const sentFrames: Record<string, CachedFrame> = {};
 
   await Promise.all(
     connections.map(async (conn) => {
+      const deviceId = deviceIdFor(conn);
+      const minIntervalMs = deviceSettings.minIntervalMs[deviceId] ?? 0;
+      const window = deviceSettings.maintenanceWindows[deviceId];
+      const now = Date.now();
+
+      // Hold off while the device runs its nightly firmware check; it would
+      // only reject the frame and count against its reliability
+      if (window && isInMaintenanceWindow(window, now, "America/Los_Angeles")) {
+        paused++;
+        return;
+      }
+      // The first frame after the window replaces whatever the device reset
+      // to, so there's nothing to animate from
+      const resuming = window !== undefined && isInMaintenanceWindow(window, now - 60_000, "America/Los_Angeles");
+
+      // Rate-limited devices get at most one frame per interval, and no
+      // animation that would send faster than that
+      if (minIntervalMs > 0 && !(await mayStartSend(deviceId, minIntervalMs))) {
+        throttled++;
+        return;
+      }
+
+      const startedAt = Date.now();
+      const { message, sent } = messageFor(
+        deviceSettings.models[deviceId] ?? "pixoo64",
+        deviceSettings.rotations[deviceId] ?? 0,
+        deviceSettings.layouts[deviceId],
+        !resuming && allowsAnimation(minIntervalMs, TRANSITION_FRAME_DELAY_MS),
+        allowsAnimation(minIntervalMs, ticks.stepMs)
+      );
       try {
-        await apiClient.send(
-          new PostToConnectionCommand({
-            ConnectionId: conn.connectionId,
-            Data: message,
-          })
-        );
+        await sender.send(conn.connectionId, message);
+        sentFrames[deviceId] = sent;
         success++;
+        results.push({ deviceId, ok: true, latencyMs: Date.now() - startedAt });
       } catch (error) {
         failed++;
-        // Clean up stale connections (410 Gone = connection no longer exists)
-        if (error instanceof GoneException) {
+        results.push({ deviceId, ok: false, latencyMs: Date.now() - startedAt });
+        // Clean up stale connections
+        if (sender.isGone(error)) {
           await removeStaleConnection(conn.connectionId);
           cleaned++;
         }
@@ -599,7 +1578,149 @@ async function broadcastFrame(
     })
   );
 
-  return { success, failed, cleaned };
+  try {
+    await recordSendResults(results);
+  } catch (error) {
+    console.error("Failed to record device stats:", error);
+  }
+
+  // Each device's own frame, for when it reconnects
+  try {
+    await saveDeviceFrames(sentFrames);
+  } catch (error) {
+    console.error("Failed to cache device frames:", error);
+  }
+
+  return { success, failed, cleaned, throttled, paused };
+}
+
+/**
+ * Tell every connection to turn its screen on or off.
+ * Stale connections are left for the next frame broadcast to clean up.
+ */
+async function broadcastScreen(
+  sender: FrameSender,
+  connections: Array<{ connectionId: string }>,
+  on: boolean
+): Promise<{ success: number; failed: number }> {
+  const message = JSON.stringify({ type: "screen", payload: { on }, timestamp: Date.now() });
+  const results = await Promise.allSettled(
+    connections.map((conn) => sender.send(conn.connectionId, message))
+  );
+  const success = results.filter((r) => r.status === "fulfilled").length;
+  return { success, failed: results.length - success };
+}
+
+/**
+ * Tell every connection to sound its buzzer.
+ * Stale connections are left for the next frame broadcast to clean up.
+ */
+async function broadcastBuzzer(
+  sender: FrameSender,
+  connections: Array<{ connectionId: string }>
+): Promise<{ success: number; failed: number }> {
+  const message = JSON.stringify({ type: "buzzer", payload: POMODORO_BUZZ, timestamp: Date.now() });
+  const results = await Promise.allSettled(
+    connections.map((conn) => sender.send(conn.connectionId, message))
+  );
+  const success = results.filter((r) => r.status === "fulfilled").length;
+  return { success, failed: results.length - success };
+}
+
+/**
+ * Sound the buzzer once for pomodoro periods that ended since the last
+ * run (only while the screens are on), and stop a finished timer.
+ * Returns where the timer is now, or null when none is running.
+ */
+async function advancePomodoro(
+  sender: FrameSender,
+  connections: Array<{ connectionId: string }>,
+  timer: PomodoroTimer | null,
+  screenOn: boolean,
+  now: number
+): Promise<PomodoroStatus | null> {
+  if (!timer) return null;
+
+  const buzzDue = isPomodoroBuzzDue(timer, now);
+  if (buzzDue && screenOn) {
+    const sent = await broadcastBuzzer(sender, connections);
+    console.log(`Pomodoro buzzer: ${sent.success} sent, ${sent.failed} failed`);
+    await recordEvent({
+      timestamp: now,
+      category: "buzzer",
+      type: "sounded",
+      subject: "pomodoro",
+      detail: `${sent.success} sent, ${sent.failed} failed`,
+    });
+  }
+  try {
+    if (isPomodoroFinished(timer, now)) {
+      await clearPomodoroTimer();
+      console.log("Pomodoro finished");
+    } else if (buzzDue) {
+      await savePomodoroTimer({ ...timer, buzzedIntervals: pomodoroIntervalsDone(timer, now) });
+    }
+  } catch (error) {
+    console.error("Failed to save pomodoro timer:", error);
+  }
+  return getPomodoroStatus(timer, now);
+}
+
+/**
+ * Record this run for the status summary. Never fails the run.
+ */
+async function recordStatus(status: Omit<CompositorStatus, "updatedAt">): Promise<void> {
+  try {
+    await saveCompositorStatus({ updatedAt: Date.now(), ...status });
+  } catch (error) {
+    console.error("Failed to save compositor status:", error);
+  }
+}
+
+/**
+ * Apply Home Assistant switch commands left on the broker since the last
+ * run, before the display config is read
+ */
+async function applyMqttCommands(mqttUrl: string): Promise<void> {
+  try {
+    const prefix = mqttPrefixFromUrl(mqttUrl);
+    const commands = parseHomeAssistantCommands(
+      prefix,
+      await takeRetainedMqtt(mqttUrl, homeAssistantCommandFilter(prefix))
+    );
+    if (Object.keys(commands).length === 0) return;
+    const config = await getDisplayConfig();
+    const next = applyHomeAssistantCommands(config, commands, Date.now(), config.timezone ?? DEFAULT_TIMEZONE);
+    if (next) {
+      await saveDisplayConfig(next);
+      console.log(`Applied Home Assistant commands: ${JSON.stringify(commands)}`);
+    }
+  } catch (error) {
+    console.error("Failed to apply MQTT commands:", error instanceof Error ? error.message : error);
+  }
+}
+
+/**
+ * Publish Home Assistant discovery and the state this run ended up with,
+ * whether or not it composed a frame
+ */
+async function publishMqttState(
+  mqttUrl: string,
+  result: { connections?: number; broadcast?: { success: number } }
+): Promise<void> {
+  try {
+    const prefix = mqttPrefixFromUrl(mqttUrl);
+    const config = await getDisplayConfig();
+    const state = {
+      screenOn: !config.away,
+      nightMode: isNightMode(config, Date.now(), config.timezone ?? DEFAULT_TIMEZONE),
+      // A run with the screen off sends nothing, so connections have to do
+      reachable: (result.connections ?? 0) > 0 && (result.broadcast ? result.broadcast.success > 0 : true),
+    };
+    await publishMqtt(mqttUrl, [...buildHomeAssistantDiscovery(prefix), ...buildHomeAssistantState(prefix, state)]);
+  } catch (error) {
+    console.error("Failed to publish MQTT state:", error instanceof Error ? error.message : error);
+  }
 }
 
 /**
@@ -608,17 +1729,26 @@ async function broadcastFrame(
 async function updateDisplay(): Promise<{
   success: boolean;
   skipped?: boolean;
+  screenOff?: boolean;
+  vetoedBy?: string;
   time?: string;
+  layout?: string;
+  locked?: boolean;
   glucose?: number;
+  alerts?: string[];
+  rejectedPoints?: Record<string, number>;
   connections?: number;
-  broadcast?: { success: number; failed: number; cleaned: number };
+  broadcast?: { success: number; failed: number; cleaned: number; throttled: number; paused: number };
   error?: string;
 }> {
+  resetRejectionCounts();
+
   // Check for active connections first
   const connections = await getActiveConnections();
 
   if (connections.length === 0) {
     console.log("No active connections, skipping frame broadcast");
+    await recordStatus({ skipped: true });
     return { success: true, skipped: true };
   }
 
@@ -632,25 +1762,92 @@ async function updateDisplay(): Promise<{
   const url = new URL(wsApiUrl);
   const endpoint = `https://${url.host}/${url.pathname.split("/")[1] || ""}`;
 
-  const apiClient = new ApiGatewayManagementApiClient({ endpoint });
+  const sender = createApiGatewaySender(new ApiGatewayManagementApiClient({ endpoint }));
+
+  const displaySettingsRequest = fetchDisplaySettings();
 
   // Fetch blood sugar, treatment, and insight data in parallel
   // Note: Weather fetching disabled - insight display uses the same Y position (row 12)
   // Weather code is preserved for future displays. Re-enable by uncommenting below.
-  const [bloodSugarResult, treatmentData, insightData] = await Promise.all([
-    fetchBloodSugarData(),
+  const [
+    bloodSugarResult,
+    treatmentData,
+    insightData,
+    displaySettings,
+    alertRules,
+    previousFrame,
+    lock,
+    annotations,
+    deviceSettings,
+    previousStreaks,
+    previousScreenOn,
+    pomodoroTimer,
+    pushHolds,
+    textBanner,
+  ] = await Promise.all([
+    // Needs the request budget from the display settings
+    displaySettingsRequest.then((settings) => fetchBloodSugarData(settings.dexcomRateLimit)),
     // fetchWeatherData(), // Disabled: overlaps with insight region
     fetchTreatmentData(),
     fetchCurrentInsight(),
+    displaySettingsRequest,
+    fetchAlertRules(),
+    fetchPreviousFrame(),
+    fetchDisplayLock(),
+    fetchAnnotations(),
+    fetchDeviceSettings(),
+    fetchFrameStreaks(),
+    fetchScreenOn(),
+    fetchPomodoroTimer(),
+    fetchPushHolds(),
+    fetchTextBanner(),
   ]);
-
-  const { current: bloodSugarData, history } = bloodSugarResult;
+  const {
+    layout,
+    transition,
+    powerLimit,
+    hiddenLayers,
+    sceneRules,
+    locale,
+    clockSeconds,
+    moonPhase,
+    chartAntialias,
+    chartFill,
+    timezone,
+  } = displaySettings;
+
+  // Devices assigned a layout of their own show it instead of the
+  // display-wide one, schedule and all
+  const deviceLayouts = [...new Set(Object.values(deviceSettings.layouts))]
+    .filter((name) => name !== layout.name)
+    .map((name) => getLayout(name));
+  const showing = (widget: LayoutWidget) =>
+    !lock && [layout, ...deviceLayouts].some((shown) => shown.widgets.includes(widget));
+
+  // Send statistics are only needed when the diagnostics page is showing
+  const deviceStats = showing("diagnostics") ? await fetchDeviceStats() : undefined;
+  // Likewise quotes for the markets page
+  const tickers = showing("ticker") ? await fetchTickerData(displaySettings.ticker) : undefined;
+  // and network measurements for the network page
+  const network = showing("network") ? await fetchNetworkData(displaySettings.networkMonitor) : undefined;
+  // and the rain nowcast for layouts with its bar
+  const precipitation = showing("precipitation")
+    ? await fetchPrecipitationData(displaySettings.precipitation)
+    : undefined;
+  // and everyone's readings for the split page
+  const people = showing("split")
+    ? await fetchPeopleGlucose(displaySettings.people, bloodSugarResult, displaySettings.dexcomRateLimit)
+    : undefined;
+  // and what's playing, for layouts with the insight rows it takes over
+  const nowPlaying = showing("insight") ? await fetchNowPlaying() : undefined;
+
+  const { current: bloodSugarData, history, dexcomUnreachable } = bloodSugarResult;
 
   if (bloodSugarData) {
     const staleSuffix = bloodSugarData.isStale ? " (cached/stale)" : "";
     console.log(`Blood sugar: ${bloodSugarData.glucose} mg/dL, Trend: ${bloodSugarData.trend}, History: ${history.length} points${staleSuffix}`);
   } else {
-    console.log("Blood sugar data unavailable (no cache)");
+    console.log(`Blood sugar data unavailable (no cache${dexcomUnreachable ? ", Dexcom unreachable" : ""})`);
   }
 
   // Weather logging disabled (see comment above)
@@ -674,33 +1871,193 @@ async function updateDisplay(): Promise<{
     console.log("No insight available");
   }
 
-  // Generate composite frame using shared rendering module
-  const frame = generateCompositeFrame({
-    bloodSugar: bloodSugarData,
-    bloodSugarHistory: history.length > 0 ? { points: history } : undefined,
-    timezone: "America/Los_Angeles",
-    // weather: weatherData ?? undefined, // Disabled: overlaps with insight region
-    treatments: treatmentData,
-    insight: insightData,
-  });
+  const raisedAlerts = evaluateAlerts(
+    bloodSugarData && {
+      ...bloodSugarData,
+      trendComputable: isTrendComputable(bloodSugarData.trend),
+      sourceUnreachable: dexcomUnreachable,
+    },
+    history,
+    Date.now(),
+    rateThresholds(alertRules),
+    signalLossMinutes(alertRules)
+  );
+  const unsuppressed = applySuppression(raisedAlerts, alertRules, Date.now(), timezone);
+  if (raisedAlerts.length > unsuppressed.length) {
+    console.log(`Suppressed alerts: ${raisedAlerts.filter((a) => !unsuppressed.includes(a)).map((a) => a.type).join(", ")}`);
+  }
+  // Snoozed alerts stay off the display, the lock and screen checks, and MQTT
+  const alerts = await applyAlertAcknowledgments(unsuppressed, bloodSugarData?.glucose ?? null, Date.now());
+  if (unsuppressed.length > alerts.length) {
+    console.log(`Snoozed alerts: ${unsuppressed.filter((a) => !alerts.includes(a)).map((a) => a.type).join(", ")}`);
+  }
+  if (alerts.length > 0) {
+    console.log(`Active alerts: ${alerts.map((a) => `${a.type} (${a.detail})`).join(", ")}`);
+  }
+  await logAlertTransitions(alerts, Date.now());
+
+  // A display lock holds the locked frame; only urgent alerts break through
+  const urgentAlert = alerts.some((a) => URGENT_ALERT_TYPES.has(a.type));
+  const holdLock = lock !== null && !urgentAlert;
+  if (lock) {
+    console.log(`Display locked until ${new Date(lock.expiresAt).toISOString()}${urgentAlert ? " (urgent alert breaking through)" : ""}`);
+  }
+
+  // Screens sleep on schedule or while nobody is home; urgent alerts wake them.
+  // The on/off command only goes out when the state changes.
+  const screenOn = isScreenOn(displaySettings, Date.now(), timezone, urgentAlert);
+  if (screenOn !== previousScreenOn) {
+    const sent = await broadcastScreen(sender, connections as Array<{ connectionId: string }>, screenOn);
+    console.log(`Screen turned ${screenOn ? "on" : "off"}: ${sent.success} sent, ${sent.failed} failed`);
+    try {
+      await saveScreenOn(screenOn);
+    } catch (error) {
+      console.error("Failed to save screen state:", error);
+    }
+  }
+  // Period ends buzz even while locked, but not with the screens off
+  const pomodoro = await advancePomodoro(sender, connections, pomodoroTimer, screenOn, Date.now());
+  if (!screenOn) {
+    await recordStatus({ layout: layout.name, screenOff: true, connections: connections.length });
+    return { success: true, screenOff: true, layout: layout.name, connections: connections.length };
+  }
+
+  // Generate composite frame using shared rendering module. Compose hooks
+  // run around live frames only; a locked frame is sent as it was locked.
+  let frame: Frame;
+  // 32x8 clocks get their own layout from the same data (a locked frame is
+  // just resized for them)
+  const renders: Partial<Record<DisplayModel, Frame>> = {};
+  // and devices assigned a layout get it composed from the same data too
+  const layoutFrames: Record<string, Frame> = {};
+  // A seconds clock gets the rest of the minute ahead, as patches
+  let ticks: FramePatch[] = [];
+  if (holdLock) {
+    frame = decodeBase64ToPixels(lock.frameData, lock.width, lock.height);
+  } else {
+    // Notifications wait out locks and screens off, so they aren't missed
+    const notification = await fetchNotification(Date.now());
+    const composeData = await runBeforeCompose(composeHooks, {
+      bloodSugar: bloodSugarData,
+      bloodSugarHistory: history.length > 0 ? { points: history } : undefined,
+      glucoseStatus: { dexcomUnreachable },
+      timezone,
+      // weather: weatherData ?? undefined, // Disabled: overlaps with insight region
+      treatments: treatmentData,
+      insight: insightData,
+      layout,
+      alerts,
+      annotations,
+      deviceStats,
+      tickers,
+      network,
+      precipitation,
+      people,
+      nowPlaying,
+      notification,
+      textBanner: bannerText(textBanner, { bloodSugar: bloodSugarData, treatments: treatmentData }),
+      hiddenLayers,
+      locale,
+      now: Date.now(),
+      clockSeconds,
+      moonPhase,
+      chartAntialias,
+      chartFill,
+      pomodoro,
+    });
+    const hooked = await runAfterCompose(composeHooks, generateCompositeFrame(composeData), composeData);
+    if (hooked.vetoedBy) {
+      console.log(`Send vetoed by compose hook ${hooked.vetoedBy}`);
+      await recordStatus({ layout: layout.name, vetoedBy: hooked.vetoedBy, connections: connections.length });
+      return { success: true, vetoedBy: hooked.vetoedBy, layout: layout.name, connections: connections.length };
+    }
+    frame = hooked.frame;
+    if (Object.values(deviceSettings.models).includes("ulanzi")) {
+      renders.ulanzi = renderCompactFrame(composeData);
+    }
+    for (const deviceLayout of deviceLayouts) {
+      layoutFrames[deviceLayout.name] = generateCompositeFrame({ ...composeData, layout: deviceLayout });
+    }
+    if (composeData.clockSeconds && layout.widgets.some((w) => w === "clock" || w === "largeClock")) {
+      const until = (Math.floor((composeData.now ?? Date.now()) / 60_000) + 1) * 60_000 + CLOCK_TICK_OVERLAP_MS;
+      ticks = renderClockTicks(composeData, until, (f) => {
+        if (powerLimit) limitPower(f, powerLimit);
+      });
+    }
+  }
 
-  // Get current time in Pacific for logging
-  const now = new Date();
-  const pacificTime = new Date(now.toLocaleString("en-US", { timeZone: "America/Los_Angeles" }));
-  let hours = pacificTime.getHours();
-  const ampm = hours >= 12 ? "PM" : "AM";
-  hours = hours % 12 || 12;
-  const minutes = String(pacificTime.getMinutes()).padStart(2, "0");
-  const timeStr = `${hours}:${minutes} ${ampm}`;
+  // Power limit is the very last pass, so it also covers locked frames
+  if (powerLimit) {
+    const scale = limitPower(frame, powerLimit);
+    if (scale < 1) {
+      console.log(`Power limit: frame scaled to ${Math.round(scale * 100)}%`);
+    }
+    for (const rendered of [...Object.values(renders), ...Object.values(layoutFrames)]) {
+      limitPower(rendered, powerLimit);
+    }
+  }
 
-  // Broadcast frame
+  // A locked frame is meant to stay the same, so streaks only count live frames
+  const frameStreaks = holdLock
+    ? undefined
+    : updateFrameStreaks(previousStreaks, hashSurfaces(frame, layout.widgets), Date.now());
+  const stuck = frameStreaks ? findStuckSurfaces(frameStreaks, Date.now()) : [];
+  if (frameStreaks && stuck.length > 0) {
+    const details = stuck.map(
+      (name) => `${name} unchanged since ${new Date(frameStreaks[name].since).toISOString()} (${frameStreaks[name].count} runs)`
+    );
+    console.warn(`Stuck source suspected: ${details.join(", ")}`);
+  }
+
+  // Get the display's local time for logging
+  const localTime = wallTime(Date.now(), timezone);
+  const ampm = localTime.hour >= 12 ? "PM" : "AM";
+  const minutes = String(localTime.minute).padStart(2, "0");
+  const timeStr = `${localTime.hour % 12 || 12}:${minutes} ${ampm}`;
+
+  // A scene for a data event replaces the usual transition. Not over a
+  // locked frame or stale data, and not on dimmed layouts - a flash of
+  // confetti doesn't belong in a dark bedroom.
+  const sceneFrames =
+    !holdLock && bloodSugarData && !bloodSugarData.isStale && (layout.brightness ?? 1) >= 1
+      ? await buildScene(
+          sceneRules,
+          [
+            ...history.filter((p) => p.timestamp < bloodSugarData.timestamp),
+            { timestamp: bloodSugarData.timestamp, glucose: bloodSugarData.glucose },
+          ],
+          frame
+        )
+      : [];
+  if (powerLimit) {
+    for (const sceneFrame of sceneFrames) {
+      limitPower(sceneFrame, powerLimit);
+    }
+  }
+
+  // Devices showing a pushed frame keep it until the push expires (the
+  // first frame afterwards restores them); urgent alerts break through
+  const receiving = (
+    connections as Array<{ connectionId: string; terminalId?: string | null; terminalType?: string }>
+  ).filter((conn) => urgentAlert || pushHolds[deviceIdFor(conn)] === undefined);
+  if (receiving.length < connections.length) {
+    console.log(`Pushed frames held on: ${Object.keys(pushHolds).join(", ")}`);
+  }
+
+  // Broadcast frame, with a scene or a transition from the previous one if configured
+  const transitionFrames = sceneFrames.length > 0 ? sceneFrames : buildTransition(previousFrame, frame, transition);
   const broadcast = await broadcastFrame(
-    apiClient,
-    connections as Array<{ connectionId: string }>,
-    frame
+    sender,
+    receiving,
+    frame,
+    transitionFrames,
+    deviceSettings,
+    renders,
+    { stepMs: (clockSeconds ?? 0) * 1000, patches: ticks },
+    layoutFrames
   );
 
-  console.log(`Broadcast complete: ${broadcast.success} sent, ${broadcast.failed} failed${broadcast.cleaned > 0 ? `, ${broadcast.cleaned} stale removed` : ""}`);
+  console.log(`Broadcast complete: ${broadcast.success} sent, ${broadcast.failed} failed${broadcast.cleaned > 0 ? `, ${broadcast.cleaned} stale removed` : ""}${broadcast.throttled > 0 ? `, ${broadcast.throttled} rate-limited` : ""}${broadcast.paused > 0 ? `, ${broadcast.paused} in maintenance` : ""}`);
 
   // Cache frame for new connections
   const frameData = encodeFrameToBase64(frame);
@@ -718,10 +2075,37 @@ async function updateDisplay(): Promise<{
     })
   );
 
+  // Optional MQTT publishing, for Home Assistant and other subscribers. The
+  // URL holds the credentials, so it's never logged.
+  const mqttUrl = Resource.MqttUrl.value;
+  if (mqttUrl) {
+    const prefix = mqttPrefixFromUrl(mqttUrl);
+    try {
+      await publishMqtt(mqttUrl, buildMqttMessages(prefix, { bloodSugar: bloodSugarData, alerts, frame }));
+    } catch (error) {
+      console.error("Failed to publish to MQTT:", error instanceof Error ? error.message : error);
+    }
+  }
+
+  await recordStatus({
+    layout: layout.name,
+    locked: lock !== null,
+    alerts: alerts.map(({ type, title, detail }) => ({ type, title, detail })),
+    rejectedPoints: getRejectionCounts(),
+    connections: connections.length,
+    broadcast,
+    frameStreaks,
+    stuck: stuck.length > 0 ? stuck : undefined,
+  });
+
   return {
     success: true,
     time: timeStr,
+    layout: layout.name,
+    locked: lock !== null,
     glucose: bloodSugarData?.glucose,
+    alerts: alerts.map((a) => a.type),
+    rejectedPoints: getRejectionCounts(),
     connections: connections.length,
     broadcast,
   };
@@ -732,6 +2116,9 @@ async function updateDisplay(): Promise<{
  */
 export const scheduled: ScheduledHandler = async () => {
   console.log("Compositor triggered");
+  const mqttUrl = Resource.MqttUrl.value;
+  if (mqttUrl) await applyMqttCommands(mqttUrl);
   const result = await updateDisplay();
+  if (mqttUrl) await publishMqttState(mqttUrl, result);
   console.log("Compositor result:", JSON.stringify(result));
 };
@@ -7,6 +7,8 @@ import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
 import { DynamoDBDocumentClient, PutCommand, UpdateCommand } from "@aws-sdk/lib-dynamodb";
 import { Resource } from "sst";
 import type { APIGatewayProxyWebsocketHandlerV2 } from "aws-lambda";
+import { deviceIdFor } from "./devices/stats.js";
+import { recordEvent } from "./events/store.js";
 
 const client = new DynamoDBClient({});
 const ddb = DynamoDBDocumentClient.from(client);
@@ -47,6 +49,14 @@ export const handler: APIGatewayProxyWebsocketHandlerV2 = async (event) => {
     })
   );
 
+  await recordEvent({
+    timestamp: Date.now(),
+    category: "device",
+    type: "online",
+    subject: deviceIdFor({ terminalId, terminalType }),
+    detail: connectionId,
+  });
+
   return {
     statusCode: 200,
     body: "Connected",
@@ -0,0 +1,198 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockGetStats, mockGetSettings, mockUpdateSettings, mockRecordEvent } = vi.hoisted(() => ({
+  mockGetStats: vi.fn(),
+  mockGetSettings: vi.fn(),
+  mockUpdateSettings: vi.fn(),
+  mockRecordEvent: vi.fn(),
+}));
+
+vi.mock("./stats-store.js", () => ({
+  getDeviceStats: mockGetStats,
+}));
+
+vi.mock("./limits-store.js", () => ({
+  getDeviceSettings: mockGetSettings,
+  updateDeviceSettings: mockUpdateSettings,
+}));
+
+vi.mock("../events/store.js", () => ({
+  recordEvent: mockRecordEvent,
+}));
+
+import { handler } from "./api";
+
+function createEvent(method: string, query?: Record<string, string>, body?: unknown): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    queryStringParameters: query,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  return (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+}
+
+const STATS = [
+  { deviceId: "pixoo", sent: 99, failed: 1, successRate: 0.99, avgLatencyMs: 85, hourly: [] },
+];
+
+describe("device stats API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGetStats.mockResolvedValue(STATS);
+    mockGetSettings.mockResolvedValue({
+      minIntervalMs: { pixoo: 2000 },
+      maintenanceWindows: {},
+      rotations: {},
+      models: {},
+      layouts: {},
+    });
+    mockUpdateSettings.mockImplementation(async (deviceId: string, changes: { minIntervalMs?: number | null }) => ({
+      minIntervalMs: changes.minIntervalMs ? { [deviceId]: changes.minIntervalMs } : {},
+      maintenanceWindows: {},
+      rotations: {},
+      models: {},
+      layouts: {},
+    }));
+  });
+
+  it("returns the last 24 hours by default", async () => {
+    const result = await invoke(createEvent("GET"));
+
+    expect(result.statusCode).toBe(200);
+    expect(mockGetStats).toHaveBeenCalledWith(24);
+    expect(JSON.parse(result.body as string)).toEqual({
+      hours: 24,
+      devices: STATS,
+      limits: { pixoo: 2000 },
+      maintenanceWindows: {},
+      rotations: {},
+      models: {},
+      layouts: {},
+    });
+  });
+
+  it("accepts an hours parameter", async () => {
+    await invoke(createEvent("GET", { hours: "6" }));
+    expect(mockGetStats).toHaveBeenCalledWith(6);
+  });
+
+  it("rejects an out-of-range hours parameter", async () => {
+    const result = await invoke(createEvent("GET", { hours: "72" }));
+
+    expect(result.statusCode).toBe(400);
+    expect(mockGetStats).not.toHaveBeenCalled();
+  });
+
+  it("sets a device's minimum send interval", async () => {
+    const result = await invoke(createEvent("POST", undefined, { deviceId: "pixoo", minIntervalMs: 3000 }));
+
+    expect(result.statusCode).toBe(200);
+    expect(mockUpdateSettings).toHaveBeenCalledWith("pixoo", {
+      minIntervalMs: 3000,
+      maintenanceWindow: undefined,
+      rotation: undefined,
+    });
+    expect(mockRecordEvent).toHaveBeenCalledWith(
+      expect.objectContaining({ category: "config", type: "changed", subject: "devices" })
+    );
+    expect(JSON.parse(result.body as string)).toEqual({
+      limits: { pixoo: 3000 },
+      maintenanceWindows: {},
+      rotations: {},
+      models: {},
+      layouts: {},
+    });
+  });
+
+  it("clears a limit with null", async () => {
+    await invoke(createEvent("POST", undefined, { deviceId: "pixoo", minIntervalMs: null }));
+    expect(mockUpdateSettings).toHaveBeenCalledWith("pixoo", {
+      minIntervalMs: null,
+      maintenanceWindow: undefined,
+      rotation: undefined,
+    });
+  });
+
+  it("sets a maintenance window", async () => {
+    const window = { start: "03:00", end: "03:15" };
+    const result = await invoke(createEvent("POST", undefined, { deviceId: "pixoo", maintenanceWindow: window }));
+
+    expect(result.statusCode).toBe(200);
+    expect(mockUpdateSettings).toHaveBeenCalledWith("pixoo", {
+      minIntervalMs: undefined,
+      maintenanceWindow: window,
+      rotation: undefined,
+    });
+  });
+
+  it("rejects a malformed or overlong maintenance window", async () => {
+    const post = (maintenanceWindow: unknown) =>
+      invoke(createEvent("POST", undefined, { deviceId: "pixoo", maintenanceWindow }));
+
+    expect((await post({ start: "3am", end: "03:15" })).statusCode).toBe(400);
+    expect((await post({ start: "01:00", end: "05:00" })).statusCode).toBe(400);
+    expect((await post("03:00-03:15")).statusCode).toBe(400);
+    expect(mockUpdateSettings).not.toHaveBeenCalled();
+  });
+
+  it("sets a rotation", async () => {
+    await invoke(createEvent("POST", undefined, { deviceId: "pixoo", rotation: 90 }));
+    expect(mockUpdateSettings).toHaveBeenCalledWith("pixoo", {
+      minIntervalMs: undefined,
+      maintenanceWindow: undefined,
+      rotation: 90,
+    });
+  });
+
+  it("rejects a rotation that isn't a quarter turn", async () => {
+    const result = await invoke(createEvent("POST", undefined, { deviceId: "pixoo", rotation: 45 }));
+
+    expect(result.statusCode).toBe(400);
+    expect(mockUpdateSettings).not.toHaveBeenCalled();
+  });
+
+  it("sets a panel model", async () => {
+    await invoke(createEvent("POST", undefined, { deviceId: "kitchen", model: "pixoo16" }));
+    expect(mockUpdateSettings).toHaveBeenCalledWith("kitchen", expect.objectContaining({ model: "pixoo16" }));
+  });
+
+  it("rejects an unknown model", async () => {
+    const result = await invoke(createEvent("POST", undefined, { deviceId: "kitchen", model: "toString" }));
+
+    expect(result.statusCode).toBe(400);
+    expect(mockUpdateSettings).not.toHaveBeenCalled();
+  });
+
+  it("assigns a device its own layout", async () => {
+    await invoke(createEvent("POST", undefined, { deviceId: "bedroom", layout: "night" }));
+    expect(mockUpdateSettings).toHaveBeenCalledWith("bedroom", expect.objectContaining({ layout: "night" }));
+  });
+
+  it("rejects an unknown layout", async () => {
+    const result = await invoke(createEvent("POST", undefined, { deviceId: "bedroom", layout: "dark" }));
+
+    expect(result.statusCode).toBe(400);
+    expect(mockUpdateSettings).not.toHaveBeenCalled();
+  });
+
+  it("rejects a change with nothing to change", async () => {
+    const result = await invoke(createEvent("POST", undefined, { deviceId: "pixoo" }));
+    expect(result.statusCode).toBe(400);
+  });
+
+  it("rejects a limit without a device or with a bad interval", async () => {
+    expect((await invoke(createEvent("POST", undefined, { minIntervalMs: 1000 }))).statusCode).toBe(400);
+    expect((await invoke(createEvent("POST", undefined, { deviceId: "pixoo", minIntervalMs: -5 }))).statusCode).toBe(400);
+    expect((await invoke(createEvent("POST", undefined, { deviceId: "pixoo", minIntervalMs: "2s" }))).statusCode).toBe(400);
+    expect(mockUpdateSettings).not.toHaveBeenCalled();
+  });
+
+  it("rejects other methods", async () => {
+    const result = await invoke(createEvent("DELETE"));
+    expect(result.statusCode).toBe(405);
+  });
+});
@@ -0,0 +1,129 @@
+/**
+ * Device statistics API
+ *
+ * GET /devices            - per-device send success rate and latency, last 24h,
+ *                           plus configured send limits, maintenance windows, rotations, models
+ *                           and layouts
+ * GET /devices?hours=N    - over the last N hours (1-48)
+ * POST /devices           - change a device's settings
+ *                           { "deviceId": "pixoo", "minIntervalMs": 2000 }
+ *                           { "deviceId": "pixoo", "maintenanceWindow": { "start": "03:00", "end": "03:15" } }
+ *                           { "deviceId": "pixoo", "rotation": 90 }
+ *                           { "deviceId": "pixoo", "model": "pixoo16" }
+ *                           { "deviceId": "bedroom", "layout": "night" }
+ *                           (null removes a setting; a device without a layout shows the
+ *                           display-wide one, schedule and all)
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { DISPLAY_MODELS, isDisplayModel } from "@signage/core";
+import { getDeviceStats } from "./stats-store.js";
+import { getDeviceSettings, updateDeviceSettings } from "./limits-store.js";
+import { isValidMinInterval, MAX_MIN_INTERVAL_MS } from "./send-limits.js";
+import { isValidMaintenanceWindow, MAX_WINDOW_MINUTES } from "./maintenance.js";
+import { isValidRotation, ROTATIONS } from "./rotation.js";
+import { isLayoutName, LAYOUTS } from "../rendering/layouts.js";
+import { recordEvent } from "../events/store.js";
+import { configChangedEvent } from "../events/transitions.js";
+
+/** Statistics are kept for two days */
+const MAX_HOURS = 48;
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  if (method === "GET") {
+    const rawHours = event.queryStringParameters?.hours;
+    const hours = rawHours === undefined ? 24 : Number(rawHours);
+    if (!Number.isInteger(hours) || hours < 1 || hours > MAX_HOURS) {
+      return json(400, { error: `hours must be an integer from 1 to ${MAX_HOURS}` });
+    }
+
+    const [devices, settings] = await Promise.all([getDeviceStats(hours), getDeviceSettings()]);
+    return json(200, {
+      hours,
+      devices,
+      limits: settings.minIntervalMs,
+      maintenanceWindows: settings.maintenanceWindows,
+      rotations: settings.rotations,
+      models: settings.models,
+      layouts: settings.layouts,
+    });
+  }
+
+  if (method === "POST") {
+    let body: {
+      deviceId?: unknown;
+      minIntervalMs?: unknown;
+      maintenanceWindow?: unknown;
+      rotation?: unknown;
+      model?: unknown;
+      layout?: unknown;
+    };
+    try {
+      body = JSON.parse(event.body || "{}");
+    } catch {
+      return json(400, { error: "Invalid JSON" });
+    }
+
+    if (typeof body.deviceId !== "string" || body.deviceId.trim() === "") {
+      return json(400, { error: "deviceId is required" });
+    }
+    if (
+      body.minIntervalMs === undefined &&
+      body.maintenanceWindow === undefined &&
+      body.rotation === undefined &&
+      body.model === undefined &&
+      body.layout === undefined
+    ) {
+      return json(400, { error: "Nothing to change: pass minIntervalMs, maintenanceWindow, rotation, model or layout" });
+    }
+    if (body.minIntervalMs !== undefined && !isValidMinInterval(body.minIntervalMs)) {
+      return json(400, { error: `minIntervalMs must be null or an integer from 0 to ${MAX_MIN_INTERVAL_MS}` });
+    }
+    if (body.maintenanceWindow !== undefined && !isValidMaintenanceWindow(body.maintenanceWindow)) {
+      return json(400, {
+        error: `maintenanceWindow must be null or { start, end } in HH:MM, at most ${MAX_WINDOW_MINUTES} minutes long`,
+      });
+    }
+
+    if (body.rotation !== undefined && !isValidRotation(body.rotation)) {
+      return json(400, { error: `rotation must be null or one of ${ROTATIONS.join(", ")}` });
+    }
+    if (body.model !== undefined && body.model !== null && !isDisplayModel(body.model)) {
+      return json(400, { error: `model must be null or one of ${Object.keys(DISPLAY_MODELS).join(", ")}` });
+    }
+    if (body.layout !== undefined && body.layout !== null && (typeof body.layout !== "string" || !isLayoutName(body.layout))) {
+      return json(400, { error: `layout must be null or one of ${Object.keys(LAYOUTS).join(", ")}` });
+    }
+
+    const settings = await updateDeviceSettings(body.deviceId.trim(), {
+      minIntervalMs: body.minIntervalMs,
+      maintenanceWindow: body.maintenanceWindow,
+      rotation: body.rotation,
+      model: body.model,
+      layout: body.layout,
+    });
+    await recordEvent(configChangedEvent("devices", body));
+    return json(200, {
+      limits: settings.minIntervalMs,
+      maintenanceWindows: settings.maintenanceWindows,
+      rotations: settings.rotations,
+      models: settings.models,
+      layouts: settings.layouts,
+    });
+  }
+
+  return json(405, { error: `Method ${method} not allowed` });
+};
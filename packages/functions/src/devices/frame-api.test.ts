@@ -0,0 +1,116 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockGetSettings, mockGetConnections, mockSaveHold, mockSaveFrames, mockSenderSend } = vi.hoisted(() => ({
+  mockGetSettings: vi.fn(),
+  mockGetConnections: vi.fn(),
+  mockSaveHold: vi.fn(),
+  mockSaveFrames: vi.fn(),
+  mockSenderSend: vi.fn(),
+}));
+
+vi.mock("sst", () => ({
+  Resource: { SignageApi: { url: "wss://ws.example.com/prod" } },
+}));
+
+vi.mock("@aws-sdk/client-apigatewaymanagementapi", () => ({
+  ApiGatewayManagementApiClient: vi.fn(() => ({})),
+}));
+
+vi.mock("./limits-store.js", () => ({
+  getDeviceSettings: mockGetSettings,
+}));
+
+vi.mock("./push-store.js", () => ({
+  getDeviceConnections: mockGetConnections,
+  savePushHold: mockSaveHold,
+}));
+
+vi.mock("./frame-cache.js", () => ({
+  saveDeviceFrames: mockSaveFrames,
+}));
+
+vi.mock("./frame-sender.js", () => ({
+  createApiGatewaySender: () => ({ send: mockSenderSend, isGone: () => false }),
+}));
+
+import { handler } from "./frame-api";
+
+const RAW_FRAME = Buffer.alloc(64 * 64 * 3, 40);
+
+function createEvent(deviceId: string | undefined, body: Buffer, query?: Record<string, string>): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method: "POST" } },
+    pathParameters: deviceId ? { id: deviceId } : undefined,
+    queryStringParameters: query,
+    body: body.toString("base64"),
+    isBase64Encoded: true,
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("frame push API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGetSettings.mockResolvedValue({
+      minIntervalMs: {},
+      maintenanceWindows: {},
+      rotations: {},
+      models: { desk: "pixoo16" },
+      layouts: {},
+    });
+    mockGetConnections.mockResolvedValue(["a"]);
+    mockSaveHold.mockResolvedValue(undefined);
+    mockSaveFrames.mockResolvedValue(undefined);
+    mockSenderSend.mockResolvedValue(undefined);
+  });
+
+  it("sends the frame to the device and holds it", async () => {
+    const { statusCode, body } = await invoke(createEvent("kitchen", RAW_FRAME, { seconds: "120" }));
+
+    expect(statusCode).toBe(200);
+    expect(body).toMatchObject({ deviceId: "kitchen", sent: 1 });
+    expect(mockSenderSend).toHaveBeenCalledWith("a", expect.stringContaining('"type":"frame"'));
+    const [deviceId, frameData, expiresAt] = mockSaveHold.mock.calls[0];
+    expect(deviceId).toBe("kitchen");
+    expect(Buffer.from(frameData, "base64")).toEqual(RAW_FRAME);
+    expect(expiresAt - Date.now()).toBeGreaterThan(110_000);
+  });
+
+  it("fits the frame to the device's model, and caches it for a reconnect", async () => {
+    await invoke(createEvent("desk", RAW_FRAME));
+
+    const message = JSON.parse(mockSenderSend.mock.calls[0][1]);
+    expect(message.payload.frame).toMatchObject({ width: 16, height: 16 });
+    expect(mockSaveFrames).toHaveBeenCalledWith({ desk: message.payload.frame });
+  });
+
+  it("rejects bodies that aren't a frame", async () => {
+    const { statusCode } = await invoke(createEvent("kitchen", Buffer.alloc(10)));
+
+    expect(statusCode).toBe(400);
+    expect(mockSenderSend).not.toHaveBeenCalled();
+  });
+
+  it("reports a device that isn't connected, without holding", async () => {
+    mockGetConnections.mockResolvedValue([]);
+
+    const { statusCode } = await invoke(createEvent("garage", RAW_FRAME));
+
+    expect(statusCode).toBe(404);
+    expect(mockSaveHold).not.toHaveBeenCalled();
+  });
+
+  it("doesn't hold a frame that couldn't be sent", async () => {
+    mockSenderSend.mockRejectedValue(new Error("Gone"));
+
+    const { statusCode } = await invoke(createEvent("kitchen", RAW_FRAME));
+
+    expect(statusCode).toBe(502);
+    expect(mockSaveHold).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,84 @@
+/**
+ * Frame push API
+ *
+ * POST /devices/{id}/frame            - show a picture on one device for 60 seconds
+ * POST /devices/{id}/frame?seconds=N  - for N seconds (1-900)
+ *
+ * Body: a 64x64 PNG, or 12288 bytes of raw RGB (rows top to bottom, three
+ * bytes a pixel). The frame goes out at once, fitted to the device's model
+ * and rotation; the compositor then leaves the device alone until the hold
+ * ends, and its next update restores the display.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { ApiGatewayManagementApiClient } from "@aws-sdk/client-apigatewaymanagementapi";
+import { Resource } from "sst";
+import { encodeFrameToBase64 } from "@signage/core";
+import { getDeviceSettings } from "./limits-store.js";
+import { getDeviceConnections, savePushHold } from "./push-store.js";
+import { buildPushMessage, fitPushedFrame, parsePushedFrame, parsePushSeconds } from "./push.js";
+import { createApiGatewaySender } from "./frame-sender.js";
+import { saveDeviceFrames } from "./frame-cache.js";
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  const deviceId = event.pathParameters?.id;
+  if (!deviceId) {
+    return json(400, { error: "Device ID is required" });
+  }
+
+  const seconds = parsePushSeconds(event.queryStringParameters?.seconds);
+  if (typeof seconds === "string") {
+    return json(400, { error: seconds });
+  }
+
+  // Binary bodies arrive base64-encoded
+  const data = Buffer.from(event.body ?? "", event.isBase64Encoded ? "base64" : "latin1");
+  const frame = parsePushedFrame(new Uint8Array(data));
+  if (typeof frame === "string") {
+    return json(400, { error: frame });
+  }
+
+  const connectionIds = await getDeviceConnections(deviceId);
+  if (connectionIds.length === 0) {
+    return json(404, { error: `Device not connected: ${deviceId}` });
+  }
+
+  const settings = await getDeviceSettings();
+  const fitted = fitPushedFrame(frame, settings.models[deviceId] ?? "pixoo64", settings.rotations[deviceId] ?? 0);
+  const message = buildPushMessage(fitted);
+
+  const url = new URL(Resource.SignageApi.url);
+  const endpoint = `https://${url.host}/${url.pathname.split("/")[1] || ""}`;
+  const sender = createApiGatewaySender(new ApiGatewayManagementApiClient({ endpoint }));
+
+  // A stale connection is left for the compositor to clean up
+  const results = await Promise.allSettled(connectionIds.map((id) => sender.send(id, message)));
+  const sent = results.filter((r) => r.status === "fulfilled").length;
+  if (sent === 0) {
+    return json(502, { error: `Send to ${deviceId} failed` });
+  }
+
+  const expiresAt = Date.now() + seconds * 1000;
+  await savePushHold(deviceId, encodeFrameToBase64(frame), expiresAt);
+  // So the device gets the picture back if it reconnects during the hold
+  await saveDeviceFrames({ [deviceId]: fitted });
+
+  console.log(`Pushed frame to ${deviceId} for ${seconds}s (${sent} connection${sent === 1 ? "" : "s"})`);
+  return json(200, { deviceId, sent, until: new Date(expiresAt).toISOString() });
+};
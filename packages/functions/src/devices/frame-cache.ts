@@ -0,0 +1,78 @@
+/**
+ * Frame cache
+ *
+ * The last frame the display rendered (LATEST) is kept for transitions and
+ * the status page, and each device's last frame as sent to it, since a
+ * device with its own layout, model or rotation, or a pushed picture, isn't
+ * showing the display-wide one. A device that reconnects gets its own frame
+ * back straight away.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const FRAME_CACHE_PK = "FRAME_CACHE";
+
+/** Devices not seen for this long drop out of the cache */
+const DEVICE_FRAME_TTL_SECONDS = 7 * 24 * 60 * 60;
+
+/** A frame as sent: its size and base64 RGB data */
+export interface CachedFrame {
+  width: number;
+  height: number;
+  data: string;
+}
+
+function deviceSk(deviceId: string): string {
+  return `DEVICE#${deviceId}`;
+}
+
+/**
+ * Save the frame each device was just sent
+ */
+export async function saveDeviceFrames(frames: Record<string, CachedFrame>, now: number = Date.now()): Promise<void> {
+  await Promise.all(
+    Object.entries(frames).map(([deviceId, frame]) =>
+      ddb.send(
+        new PutCommand({
+          TableName: Resource.SignageTable.name,
+          Item: {
+            pk: FRAME_CACHE_PK,
+            sk: deviceSk(deviceId),
+            deviceId,
+            frameData: frame.data,
+            width: frame.width,
+            height: frame.height,
+            timestamp: now,
+            ttl: Math.floor(now / 1000) + DEVICE_FRAME_TTL_SECONDS,
+          },
+        })
+      )
+    )
+  );
+}
+
+async function getFrameItem(sk: string): Promise<CachedFrame | null> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: { pk: FRAME_CACHE_PK, sk },
+    })
+  );
+  const item = result.Item;
+  if (!item?.frameData) return null;
+  return { width: item.width as number, height: item.height as number, data: item.frameData as string };
+}
+
+/**
+ * Get the frame to show a device that (re)connects: its own last frame,
+ * or the display's latest when it has none yet
+ */
+export async function getCachedFrame(deviceId: string | null): Promise<CachedFrame | null> {
+  const own = deviceId ? await getFrameItem(deviceSk(deviceId)) : null;
+  return own ?? (await getFrameItem("LATEST"));
+}
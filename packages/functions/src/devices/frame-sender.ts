@@ -0,0 +1,33 @@
+/**
+ * Frame sender
+ * Delivers messages to WebSocket connections, behind an interface so the
+ * broadcast logic can be tested without API Gateway.
+ */
+
+import {
+  GoneException,
+  PostToConnectionCommand,
+  type ApiGatewayManagementApiClient,
+} from "@aws-sdk/client-apigatewaymanagementapi";
+
+export interface FrameSender {
+  /** Send a message; throws on failure */
+  send(connectionId: string, data: string): Promise<void>;
+  /** Whether a send error means the connection no longer exists */
+  isGone(error: unknown): boolean;
+}
+
+/**
+ * Sender backed by the API Gateway management API
+ */
+export function createApiGatewaySender(apiClient: ApiGatewayManagementApiClient): FrameSender {
+  return {
+    async send(connectionId, data) {
+      await apiClient.send(new PostToConnectionCommand({ ConnectionId: connectionId, Data: data }));
+    },
+    isGone(error) {
+      // 410 Gone = connection no longer exists
+      return error instanceof GoneException;
+    },
+  };
+}
@@ -0,0 +1,180 @@
+/**
+ * Device settings store
+ * Send limits, maintenance windows, rotations, models and layouts live in one item; each limited device's last
+ * send time lives in its own item so concurrent senders can claim slots
+ * with a conditional write.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand, UpdateCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import { decideSend } from "./send-limits.js";
+import type { DisplayModel, Rotation } from "@signage/core";
+import type { MaintenanceWindow } from "./types.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** DynamoDB key for the per-device settings */
+const LIMITS_KEY = { pk: "DEVICE_LIMITS", sk: "SETTINGS" };
+
+/** Partition for per-device last send times */
+const LAST_SEND_PK = "DEVICE_LAST_SEND";
+
+/**
+ * Per-device settings, each keyed by device ID
+ */
+export interface DeviceSettings {
+  /** Minimum interval between frames (ms) */
+  minIntervalMs: Record<string, number>;
+  /** Daily windows with sends paused */
+  maintenanceWindows: Record<string, MaintenanceWindow>;
+  /** Clockwise rotation applied to frames */
+  rotations: Record<string, Rotation>;
+  /** Panel model, for devices that aren't 64x64 */
+  models: Record<string, DisplayModel>;
+  /** Layout name, for devices that don't show the display-wide layout */
+  layouts: Record<string, string>;
+}
+
+/**
+ * Get all per-device settings
+ */
+export async function getDeviceSettings(): Promise<DeviceSettings> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: LIMITS_KEY,
+    })
+  );
+  return {
+    minIntervalMs: (result.Item?.minIntervalMs as Record<string, number> | undefined) ?? {},
+    maintenanceWindows: (result.Item?.maintenanceWindows as Record<string, MaintenanceWindow> | undefined) ?? {},
+    rotations: (result.Item?.rotations as Record<string, Rotation> | undefined) ?? {},
+    models: (result.Item?.models as Record<string, DisplayModel> | undefined) ?? {},
+    layouts: (result.Item?.layouts as Record<string, string> | undefined) ?? {},
+  };
+}
+
+/**
+ * Change a device's settings. Omitted fields are left alone; null (or a
+ * zero interval or rotation, or the default model) clears a setting.
+ */
+export async function updateDeviceSettings(
+  deviceId: string,
+  changes: {
+    minIntervalMs?: number | null;
+    maintenanceWindow?: MaintenanceWindow | null;
+    rotation?: Rotation | null;
+    model?: DisplayModel | null;
+    layout?: string | null;
+  }
+): Promise<DeviceSettings> {
+  const settings = await getDeviceSettings();
+
+  if (changes.minIntervalMs !== undefined) {
+    if (changes.minIntervalMs === null || changes.minIntervalMs === 0) {
+      delete settings.minIntervalMs[deviceId];
+    } else {
+      settings.minIntervalMs[deviceId] = changes.minIntervalMs;
+    }
+  }
+
+  if (changes.maintenanceWindow !== undefined) {
+    if (changes.maintenanceWindow === null) {
+      delete settings.maintenanceWindows[deviceId];
+    } else {
+      settings.maintenanceWindows[deviceId] = changes.maintenanceWindow;
+    }
+  }
+
+  if (changes.rotation !== undefined) {
+    if (changes.rotation === null || changes.rotation === 0) {
+      delete settings.rotations[deviceId];
+    } else {
+      settings.rotations[deviceId] = changes.rotation;
+    }
+  }
+
+  if (changes.model !== undefined) {
+    if (changes.model === null || changes.model === "pixoo64") {
+      delete settings.models[deviceId];
+    } else {
+      settings.models[deviceId] = changes.model;
+    }
+  }
+
+  if (changes.layout !== undefined) {
+    if (changes.layout === null) {
+      delete settings.layouts[deviceId];
+    } else {
+      settings.layouts[deviceId] = changes.layout;
+    }
+  }
+
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        ...LIMITS_KEY,
+        ...settings,
+        updatedAt: new Date().toISOString(),
+      },
+    })
+  );
+  return settings;
+}
+
+/**
+ * Record a send at `now` unless the device was sent to within the interval.
+ * Returns the previous send time when the slot is taken.
+ */
+async function claimSlot(
+  deviceId: string,
+  minIntervalMs: number,
+  now: number
+): Promise<{ claimed: true } | { claimed: false; lastSentAt: number | null }> {
+  try {
+    await ddb.send(
+      new UpdateCommand({
+        TableName: Resource.SignageTable.name,
+        Key: { pk: LAST_SEND_PK, sk: deviceId },
+        UpdateExpression: "SET lastSentAt = :now",
+        ConditionExpression: "attribute_not_exists(lastSentAt) OR lastSentAt <= :cutoff",
+        ExpressionAttributeValues: { ":now": now, ":cutoff": now - minIntervalMs },
+      })
+    );
+    return { claimed: true };
+  } catch (error: unknown) {
+    if ((error as { name?: string }).name !== "ConditionalCheckFailedException") {
+      throw error;
+    }
+  }
+
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: { pk: LAST_SEND_PK, sk: deviceId },
+    })
+  );
+  return { claimed: false, lastSentAt: (result.Item?.lastSentAt as number | undefined) ?? null };
+}
+
+/**
+ * Wait for a device's send slot. Resolves true when the caller may send,
+ * false when the frame should be dropped in favour of the next one.
+ */
+export async function acquireSendSlot(deviceId: string, minIntervalMs: number): Promise<boolean> {
+  const first = await claimSlot(deviceId, minIntervalMs, Date.now());
+  if (first.claimed) return true;
+
+  const decision = decideSend(first.lastSentAt, minIntervalMs, Date.now());
+  if (decision.action === "skip") return false;
+  if (decision.action === "defer") {
+    await new Promise((resolve) => setTimeout(resolve, decision.waitMs));
+  }
+
+  // Another sender may have taken the slot while we waited
+  const second = await claimSlot(deviceId, minIntervalMs, Date.now());
+  return second.claimed;
+}
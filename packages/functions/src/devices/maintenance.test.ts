@@ -0,0 +1,43 @@
+import { describe, it, expect } from "vitest";
+import { isInMaintenanceWindow, isValidMaintenanceWindow } from "./maintenance";
+
+const TZ = "UTC";
+const at = (hours: number, minutes: number) => Date.UTC(2026, 0, 15, hours, minutes);
+
+describe("isInMaintenanceWindow", () => {
+  const window = { start: "03:00", end: "03:15" };
+
+  it("covers the start but not the end", () => {
+    expect(isInMaintenanceWindow(window, at(3, 0), TZ)).toBe(true);
+    expect(isInMaintenanceWindow(window, at(3, 14), TZ)).toBe(true);
+    expect(isInMaintenanceWindow(window, at(3, 15), TZ)).toBe(false);
+    expect(isInMaintenanceWindow(window, at(2, 59), TZ)).toBe(false);
+  });
+
+  it("wraps around midnight", () => {
+    const late = { start: "23:50", end: "00:10" };
+    expect(isInMaintenanceWindow(late, at(23, 55), TZ)).toBe(true);
+    expect(isInMaintenanceWindow(late, at(0, 5), TZ)).toBe(true);
+    expect(isInMaintenanceWindow(late, at(0, 10), TZ)).toBe(false);
+  });
+
+  it("uses the given timezone", () => {
+    // 03:05 Pacific (PST) is 11:05 UTC
+    expect(isInMaintenanceWindow(window, at(11, 5), "America/Los_Angeles")).toBe(true);
+  });
+});
+
+describe("isValidMaintenanceWindow", () => {
+  it("accepts null and short windows", () => {
+    expect(isValidMaintenanceWindow(null)).toBe(true);
+    expect(isValidMaintenanceWindow({ start: "03:00", end: "03:15" })).toBe(true);
+    expect(isValidMaintenanceWindow({ start: "23:30", end: "00:30" })).toBe(true);
+  });
+
+  it("rejects malformed, empty, and overlong windows", () => {
+    expect(isValidMaintenanceWindow({ start: "3:00am", end: "03:15" })).toBe(false);
+    expect(isValidMaintenanceWindow({ start: "03:00", end: "03:00" })).toBe(false);
+    expect(isValidMaintenanceWindow({ start: "01:00", end: "04:00" })).toBe(false);
+    expect(isValidMaintenanceWindow("03:00")).toBe(false);
+  });
+});
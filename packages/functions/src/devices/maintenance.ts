@@ -0,0 +1,49 @@
+/**
+ * Device maintenance windows
+ *
+ * Divoom panels run a nightly firmware check and clock sync during which
+ * they reject requests or reset their channel. Sends to a device are
+ * paused inside its window and not counted against its reliability; the
+ * first frame after the window puts the panel back on the current state.
+ */
+
+import { parseTimeOfDay, minutesOfDay } from "../rendering/layouts.js";
+import type { MaintenanceWindow } from "./types.js";
+
+/** Longest allowed window */
+export const MAX_WINDOW_MINUTES = 120;
+
+/**
+ * Window length in minutes (wrapping midnight), or null if malformed
+ */
+function windowMinutes(window: MaintenanceWindow): number | null {
+  const start = parseTimeOfDay(window.start);
+  const end = parseTimeOfDay(window.end);
+  if (start === null || end === null) return null;
+  return (end - start + 24 * 60) % (24 * 60);
+}
+
+/**
+ * Check a requested window: null clears it
+ */
+export function isValidMaintenanceWindow(value: unknown): value is MaintenanceWindow | null {
+  if (value === null) return true;
+  if (typeof value !== "object") return false;
+  const { start, end } = value as Record<string, unknown>;
+  if (typeof start !== "string" || typeof end !== "string") return false;
+
+  const length = windowMinutes({ start, end });
+  return length !== null && length > 0 && length <= MAX_WINDOW_MINUTES;
+}
+
+/**
+ * Whether a timestamp falls inside a daily window (start inclusive, end exclusive)
+ */
+export function isInMaintenanceWindow(window: MaintenanceWindow, now: number, timezone: string): boolean {
+  const start = parseTimeOfDay(window.start);
+  const length = windowMinutes(window);
+  if (start === null || !length) return false;
+
+  const sinceStart = (minutesOfDay(now, timezone) - start + 24 * 60) % (24 * 60);
+  return sinceStart < length;
+}
@@ -0,0 +1,86 @@
+/**
+ * Pushed frame store
+ * One item per device holding a pushed frame, so the compositor knows to
+ * leave the device alone until it expires, and the device's connections
+ * to push to.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, PutCommand, QueryCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import { deviceIdFor } from "./stats.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const PUSH_PK = "PUSHED_FRAME";
+
+/**
+ * Record a pushed frame's hold on a device
+ */
+export async function savePushHold(deviceId: string, frameData: string, expiresAt: number): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        pk: PUSH_PK,
+        sk: deviceId,
+        frameData,
+        pushedAt: Date.now(),
+        expiresAt,
+        ttl: Math.ceil(expiresAt / 1000),
+      },
+    })
+  );
+}
+
+/**
+ * Devices holding a pushed frame, with when each hold ends.
+ * DynamoDB TTL deletion lags, so expiry is checked here too.
+ */
+export async function getPushHolds(now: number = Date.now()): Promise<Record<string, number>> {
+  const result = await ddb.send(
+    new QueryCommand({
+      TableName: Resource.SignageTable.name,
+      KeyConditionExpression: "pk = :pk",
+      ExpressionAttributeValues: { ":pk": PUSH_PK },
+    })
+  );
+
+  const holds: Record<string, number> = {};
+  for (const item of result.Items ?? []) {
+    if ((item.expiresAt as number) > now) {
+      holds[item.sk as string] = item.expiresAt as number;
+    }
+  }
+  return holds;
+}
+
+/**
+ * Connection IDs of a device's open WebSockets
+ */
+export async function getDeviceConnections(deviceId: string): Promise<string[]> {
+  const connectionIds: string[] = [];
+  let lastEvaluatedKey: Record<string, unknown> | undefined;
+
+  do {
+    const result = await ddb.send(
+      new QueryCommand({
+        TableName: Resource.SignageTable.name,
+        KeyConditionExpression: "pk = :pk",
+        ExpressionAttributeValues: { ":pk": "CONNECTIONS" },
+        ExclusiveStartKey: lastEvaluatedKey,
+      })
+    );
+
+    for (const item of result.Items ?? []) {
+      const connection = item as { connectionId: string; terminalId?: string | null; terminalType?: string };
+      if (deviceIdFor(connection) === deviceId) {
+        connectionIds.push(connection.connectionId);
+      }
+    }
+    lastEvaluatedKey = result.LastEvaluatedKey;
+  } while (lastEvaluatedKey);
+
+  return connectionIds;
+}
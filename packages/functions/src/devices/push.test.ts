@@ -0,0 +1,90 @@
+import { describe, it, expect } from "vitest";
+import { deflateSync } from "zlib";
+import { createSolidFrame } from "@signage/core";
+import {
+  buildPushMessage,
+  fitPushedFrame,
+  parsePushedFrame,
+  parsePushSeconds,
+  DEFAULT_PUSH_SECONDS,
+  MAX_PUSH_SECONDS,
+  RAW_FRAME_BYTES,
+} from "./push";
+
+/** Build a minimal RGBA PNG of one color (CRCs are not checked by the decoder) */
+function createPng(width: number, height: number, rgba: number[]): Buffer {
+  const chunk = (type: string, data: Buffer) => {
+    const length = Buffer.alloc(4);
+    length.writeUInt32BE(data.length);
+    return Buffer.concat([length, Buffer.from(type, "ascii"), data, Buffer.alloc(4)]);
+  };
+  const header = Buffer.alloc(13);
+  header.writeUInt32BE(width, 0);
+  header.writeUInt32BE(height, 4);
+  header[8] = 8;
+  header[9] = 6;
+  const row = Buffer.from([0, ...Array.from({ length: width }, () => rgba).flat()]);
+  return Buffer.concat([
+    Buffer.from([0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a]),
+    chunk("IHDR", header),
+    chunk("IDAT", deflateSync(Buffer.concat(Array.from({ length: height }, () => row)))),
+    chunk("IEND", Buffer.alloc(0)),
+  ]);
+}
+
+describe("parsePushedFrame", () => {
+  it("decodes a 64x64 PNG, blending transparency over black", () => {
+    const frame = parsePushedFrame(createPng(64, 64, [200, 100, 50, 128]));
+
+    expect(frame).toMatchObject({ width: 64, height: 64 });
+    expect(Array.from((frame as { pixels: Uint8Array }).pixels.slice(0, 3))).toEqual([100, 50, 25]);
+  });
+
+  it("takes raw RGB of the right length as it is", () => {
+    const raw = new Uint8Array(RAW_FRAME_BYTES).fill(7);
+    const frame = parsePushedFrame(raw);
+
+    expect(frame).toMatchObject({ width: 64, height: 64 });
+    expect((frame as { pixels: Uint8Array }).pixels).toEqual(raw);
+  });
+
+  it("rejects other sizes and unreadable PNGs", () => {
+    expect(parsePushedFrame(createPng(32, 32, [0, 0, 0, 255]))).toMatch(/64x64, got 32x32/);
+    expect(parsePushedFrame(new Uint8Array(100))).toMatch(/got 100 bytes/);
+    expect(parsePushedFrame(new Uint8Array([0x89, 0x50, 0x4e, 0x47, 0, 0]))).toMatch(/Unreadable PNG/);
+  });
+});
+
+describe("parsePushSeconds", () => {
+  it("defaults, and accepts whole seconds up to the maximum", () => {
+    expect(parsePushSeconds(undefined)).toBe(DEFAULT_PUSH_SECONDS);
+    expect(parsePushSeconds("300")).toBe(300);
+    expect(parsePushSeconds(String(MAX_PUSH_SECONDS))).toBe(MAX_PUSH_SECONDS);
+  });
+
+  it("rejects zero, fractions, text, and too long a hold", () => {
+    for (const value of ["0", "1.5", "soon", String(MAX_PUSH_SECONDS + 1)]) {
+      expect(parsePushSeconds(value)).toMatch(/seconds must be/);
+    }
+  });
+});
+
+describe("fitPushedFrame", () => {
+  it("fits the frame to the device's model and rotation", () => {
+    const frame = createSolidFrame(64, 64, { r: 255, g: 0, b: 0 });
+
+    const small = fitPushedFrame(frame, "ulanzi", 0);
+    expect(small).toMatchObject({ width: 32, height: 8 });
+    expect(Buffer.from(small.data, "base64")).toHaveLength(32 * 8 * 3);
+
+    expect(fitPushedFrame(frame, "ulanzi", 90)).toMatchObject({ width: 8, height: 32 });
+  });
+});
+
+describe("buildPushMessage", () => {
+  it("wraps the fitted frame in a frame message", () => {
+    const fitted = { width: 32, height: 8, data: "AAAA" };
+
+    expect(JSON.parse(buildPushMessage(fitted, 1000))).toEqual({ type: "frame", payload: { frame: fitted }, timestamp: 1000 });
+  });
+});
@@ -0,0 +1,87 @@
+/**
+ * Pushed frames
+ *
+ * External tools can put a picture of their own on one device for a while:
+ * a 64x64 PNG, or the raw RGB bytes the protocol carries. The compositor
+ * leaves the device alone until the push expires.
+ */
+
+import type { DisplayModel, Frame, Rotation } from "@signage/core";
+import { DISPLAY_MODELS, encodeFrameToBase64, fitFrame, rotateFrame } from "@signage/core";
+import { decodePng } from "../rendering/image.js";
+import type { CachedFrame } from "./frame-cache.js";
+
+/** Pushed frames are full-size frames */
+export const PUSH_FRAME_SIZE = 64;
+
+/** Length of a raw RGB body */
+export const RAW_FRAME_BYTES = PUSH_FRAME_SIZE * PUSH_FRAME_SIZE * 3;
+
+/** How long a pushed frame holds without `seconds` */
+export const DEFAULT_PUSH_SECONDS = 60;
+
+export const MAX_PUSH_SECONDS = 15 * 60;
+
+const PNG_SIGNATURE = [0x89, 0x50, 0x4e, 0x47];
+
+/**
+ * Turn a request body into a frame: a 64x64 PNG (transparency over black)
+ * or raw RGB. Returns the frame, or an error message.
+ */
+export function parsePushedFrame(data: Uint8Array): Frame | string {
+  if (PNG_SIGNATURE.every((byte, i) => data[i] === byte)) {
+    let image;
+    try {
+      image = decodePng(data);
+    } catch (error) {
+      return `Unreadable PNG: ${error instanceof Error ? error.message : error}`;
+    }
+    if (image.width !== PUSH_FRAME_SIZE || image.height !== PUSH_FRAME_SIZE) {
+      return `PNG must be ${PUSH_FRAME_SIZE}x${PUSH_FRAME_SIZE}, got ${image.width}x${image.height}`;
+    }
+    const pixels = new Uint8Array(RAW_FRAME_BYTES);
+    for (let i = 0, j = 0; i < pixels.length; i += 3, j += 4) {
+      const alpha = image.pixels[j + 3] / 255;
+      pixels[i] = Math.round(image.pixels[j] * alpha);
+      pixels[i + 1] = Math.round(image.pixels[j + 1] * alpha);
+      pixels[i + 2] = Math.round(image.pixels[j + 2] * alpha);
+    }
+    return { width: PUSH_FRAME_SIZE, height: PUSH_FRAME_SIZE, pixels };
+  }
+
+  if (data.length === RAW_FRAME_BYTES) {
+    return { width: PUSH_FRAME_SIZE, height: PUSH_FRAME_SIZE, pixels: data.slice() };
+  }
+
+  return `Body must be a ${PUSH_FRAME_SIZE}x${PUSH_FRAME_SIZE} PNG or ${RAW_FRAME_BYTES} bytes of raw RGB, got ${data.length} bytes`;
+}
+
+/**
+ * Parse the `seconds` query parameter. Returns the hold in seconds, or an
+ * error message.
+ */
+export function parsePushSeconds(value: string | undefined): number | string {
+  if (value === undefined) return DEFAULT_PUSH_SECONDS;
+  const seconds = Number(value);
+  if (!Number.isInteger(seconds) || seconds < 1 || seconds > MAX_PUSH_SECONDS) {
+    return `seconds must be an integer from 1 to ${MAX_PUSH_SECONDS}`;
+  }
+  return seconds;
+}
+
+/**
+ * A pushed frame as one device is sent it, fitted to its model and rotation
+ * the same way broadcasts are
+ */
+export function fitPushedFrame(frame: Frame, model: DisplayModel, rotation: Rotation): CachedFrame {
+  const fitted = fitFrame(frame, DISPLAY_MODELS[model]);
+  const turned = rotation === 0 ? fitted : rotateFrame(fitted, rotation);
+  return { width: turned.width, height: turned.height, data: encodeFrameToBase64(turned) };
+}
+
+/**
+ * Frame message for a fitted frame
+ */
+export function buildPushMessage(fitted: CachedFrame, now: number = Date.now()): string {
+  return JSON.stringify({ type: "frame", payload: { frame: fitted }, timestamp: now });
+}
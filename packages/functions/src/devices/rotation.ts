@@ -0,0 +1,18 @@
+/**
+ * Device rotation
+ *
+ * Panels mounted sideways or upside down get their frames rotated before
+ * sending, so nothing has to be changed on the device or in the Divoom app.
+ */
+
+import type { Rotation } from "@signage/core";
+
+/** Supported rotations, clockwise degrees */
+export const ROTATIONS: readonly Rotation[] = [0, 90, 180, 270];
+
+/**
+ * Check a requested rotation: null clears it
+ */
+export function isValidRotation(value: unknown): value is Rotation | null {
+  return value === null || ROTATIONS.includes(value as Rotation);
+}
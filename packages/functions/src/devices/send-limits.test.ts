@@ -0,0 +1,47 @@
+import { describe, it, expect } from "vitest";
+import { decideSend, allowsAnimation, isValidMinInterval, MAX_DEFER_MS, MAX_MIN_INTERVAL_MS } from "./send-limits";
+
+const NOW = 1_000_000;
+
+describe("decideSend", () => {
+  it("sends to devices without a limit or a previous send", () => {
+    expect(decideSend(NOW - 10, 0, NOW)).toEqual({ action: "send" });
+    expect(decideSend(null, 5000, NOW)).toEqual({ action: "send" });
+  });
+
+  it("sends once the interval has passed", () => {
+    expect(decideSend(NOW - 5000, 5000, NOW)).toEqual({ action: "send" });
+  });
+
+  it("holds a frame whose slot is close", () => {
+    expect(decideSend(NOW - 1000, 3000, NOW)).toEqual({ action: "defer", waitMs: 2000 });
+  });
+
+  it("drops a frame whose slot is too far off", () => {
+    expect(decideSend(NOW - 1000, 2000 + MAX_DEFER_MS, NOW)).toEqual({ action: "skip" });
+  });
+});
+
+describe("allowsAnimation", () => {
+  it("allows animation only when frames are no faster than the limit", () => {
+    expect(allowsAnimation(0, 120)).toBe(true);
+    expect(allowsAnimation(100, 120)).toBe(true);
+    expect(allowsAnimation(2000, 120)).toBe(false);
+  });
+});
+
+describe("isValidMinInterval", () => {
+  it("accepts null and whole milliseconds in range", () => {
+    expect(isValidMinInterval(null)).toBe(true);
+    expect(isValidMinInterval(0)).toBe(true);
+    expect(isValidMinInterval(MAX_MIN_INTERVAL_MS)).toBe(true);
+  });
+
+  it("rejects anything else", () => {
+    expect(isValidMinInterval(-1)).toBe(false);
+    expect(isValidMinInterval(1.5)).toBe(false);
+    expect(isValidMinInterval(MAX_MIN_INTERVAL_MS + 1)).toBe(false);
+    expect(isValidMinInterval("1000")).toBe(false);
+    expect(isValidMinInterval(undefined)).toBe(false);
+  });
+});
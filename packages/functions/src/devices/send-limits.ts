@@ -0,0 +1,50 @@
+/**
+ * Per-device send rate limits
+ *
+ * Some older Pixoo firmware becomes unstable when SendHttpGif calls arrive
+ * in quick succession, so a device can be given a minimum interval between
+ * frames. A frame that arrives too soon is held briefly if its slot is
+ * close, otherwise dropped - every compositor run renders the full current
+ * state, so the next frame supersedes it.
+ */
+
+/** Longest a send is held waiting for its slot */
+export const MAX_DEFER_MS = 5000;
+
+/** Upper bound for a configured interval */
+export const MAX_MIN_INTERVAL_MS = 10 * 60 * 1000;
+
+export type SendDecision =
+  | { action: "send" }
+  | { action: "defer"; waitMs: number }
+  | { action: "skip" };
+
+/**
+ * Decide what to do with a frame for a device last sent to at `lastSentAt`
+ */
+export function decideSend(lastSentAt: number | null, minIntervalMs: number, now: number): SendDecision {
+  if (lastSentAt === null || minIntervalMs <= 0) return { action: "send" };
+
+  const waitMs = lastSentAt + minIntervalMs - now;
+  if (waitMs <= 0) return { action: "send" };
+  if (waitMs <= MAX_DEFER_MS) return { action: "defer", waitMs };
+  return { action: "skip" };
+}
+
+/**
+ * Whether a device can play a transition animation without breaking its
+ * limit (each animation frame is a separate send on the relay)
+ */
+export function allowsAnimation(minIntervalMs: number, frameDelayMs: number): boolean {
+  return minIntervalMs <= frameDelayMs;
+}
+
+/**
+ * Check a requested interval: null clears the limit
+ */
+export function isValidMinInterval(value: unknown): value is number | null {
+  return (
+    value === null ||
+    (typeof value === "number" && Number.isInteger(value) && value >= 0 && value <= MAX_MIN_INTERVAL_MS)
+  );
+}
@@ -0,0 +1,87 @@
+/**
+ * Device send statistics store
+ * One item per device per hour under pk=DEVICE_STATS, kept for two days.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, QueryCommand, UpdateCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import { hourStart, summarizeDeviceStats, tallySendResults, HOUR_MS } from "./stats.js";
+import type { DeviceSendStats, SendResult } from "./types.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const STATS_PK = "DEVICE_STATS";
+
+/** Keep two days so a full 24-hour window is always available */
+const RETENTION_SECONDS = 48 * 60 * 60;
+
+function hourSk(hour: number, deviceId: string): string {
+  return `HOUR#${new Date(hour).toISOString()}#${deviceId}`;
+}
+
+/**
+ * Add a broadcast's send results to the current hour's counters
+ */
+export async function recordSendResults(results: SendResult[], now: number = Date.now()): Promise<void> {
+  const hour = hourStart(now);
+  const ttl = Math.floor(now / 1000) + RETENTION_SECONDS;
+
+  await Promise.all(
+    [...tallySendResults(results)].map(([deviceId, tally]) =>
+      ddb.send(
+        new UpdateCommand({
+          TableName: Resource.SignageTable.name,
+          Key: { pk: STATS_PK, sk: hourSk(hour, deviceId) },
+          UpdateExpression:
+            "ADD sent :sent, failed :failed, latencyTotalMs :latency SET deviceId = :device, #hour = :hour, #ttl = :ttl",
+          ExpressionAttributeNames: { "#hour": "hour", "#ttl": "ttl" },
+          ExpressionAttributeValues: {
+            ":sent": tally.sent,
+            ":failed": tally.failed,
+            ":latency": tally.latencyTotalMs,
+            ":device": deviceId,
+            ":hour": hour,
+            ":ttl": ttl,
+          },
+        })
+      )
+    )
+  );
+}
+
+/**
+ * Per-device statistics for the last `hours` hours
+ */
+export async function getDeviceStats(hours: number = 24, now: number = Date.now()): Promise<DeviceSendStats[]> {
+  const since = hourStart(now) - (hours - 1) * HOUR_MS;
+  const rows: Array<{ deviceId: string; hour: number; sent: number; failed: number; latencyTotalMs: number }> = [];
+  let lastEvaluatedKey: Record<string, unknown> | undefined;
+
+  do {
+    const result = await ddb.send(
+      new QueryCommand({
+        TableName: Resource.SignageTable.name,
+        KeyConditionExpression: "pk = :pk AND sk >= :since",
+        ExpressionAttributeValues: {
+          ":pk": STATS_PK,
+          ":since": `HOUR#${new Date(since).toISOString()}`,
+        },
+        ExclusiveStartKey: lastEvaluatedKey,
+      })
+    );
+    for (const item of result.Items ?? []) {
+      rows.push({
+        deviceId: item.deviceId as string,
+        hour: item.hour as number,
+        sent: (item.sent as number) ?? 0,
+        failed: (item.failed as number) ?? 0,
+        latencyTotalMs: (item.latencyTotalMs as number) ?? 0,
+      });
+    }
+    lastEvaluatedKey = result.LastEvaluatedKey;
+  } while (lastEvaluatedKey);
+
+  return summarizeDeviceStats(rows, now, hours);
+}
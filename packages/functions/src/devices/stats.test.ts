@@ -0,0 +1,79 @@
+import { describe, it, expect } from "vitest";
+import { deviceIdFor, summarizeDeviceStats, tallySendResults, hourStart, HOUR_MS } from "./stats";
+
+const NOW = Date.UTC(2026, 0, 15, 12, 30);
+const THIS_HOUR = hourStart(NOW);
+
+describe("deviceIdFor", () => {
+  it("prefers the terminal ID", () => {
+    expect(deviceIdFor({ terminalId: "kitchen", terminalType: "pixoo" })).toBe("kitchen");
+  });
+
+  it("falls back to the terminal type", () => {
+    expect(deviceIdFor({ terminalId: null, terminalType: "web" })).toBe("web");
+    expect(deviceIdFor({})).toBe("unknown");
+  });
+});
+
+describe("tallySendResults", () => {
+  it("counts sends and failures per device, summing successful latency", () => {
+    const tallies = tallySendResults([
+      { deviceId: "a", ok: true, latencyMs: 80 },
+      { deviceId: "a", ok: true, latencyMs: 120 },
+      { deviceId: "a", ok: false, latencyMs: 3000 },
+      { deviceId: "b", ok: false, latencyMs: 50 },
+    ]);
+
+    expect(tallies.get("a")).toEqual({ sent: 2, failed: 1, latencyTotalMs: 200 });
+    expect(tallies.get("b")).toEqual({ sent: 0, failed: 1, latencyTotalMs: 0 });
+  });
+});
+
+describe("summarizeDeviceStats", () => {
+  it("rolls hourly rows up per device", () => {
+    const [stats] = summarizeDeviceStats(
+      [
+        { deviceId: "a", hour: THIS_HOUR, sent: 9, failed: 1, latencyTotalMs: 900 },
+        { deviceId: "a", hour: THIS_HOUR - HOUR_MS, sent: 10, failed: 0, latencyTotalMs: 1000 },
+      ],
+      NOW
+    );
+
+    expect(stats.sent).toBe(19);
+    expect(stats.failed).toBe(1);
+    expect(stats.successRate).toBeCloseTo(0.95);
+    expect(stats.avgLatencyMs).toBe(100);
+    expect(stats.hourly).toHaveLength(24);
+    expect(stats.hourly[23]).toEqual({ sent: 9, failed: 1 });
+    expect(stats.hourly[22]).toEqual({ sent: 10, failed: 0 });
+    expect(stats.hourly[0]).toBeNull();
+  });
+
+  it("ignores rows outside the window", () => {
+    const stats = summarizeDeviceStats(
+      [{ deviceId: "a", hour: THIS_HOUR - 24 * HOUR_MS, sent: 5, failed: 0, latencyTotalMs: 500 }],
+      NOW
+    );
+    expect(stats).toEqual([]);
+  });
+
+  it("has no rate or latency when every send failed", () => {
+    const [stats] = summarizeDeviceStats(
+      [{ deviceId: "a", hour: THIS_HOUR, sent: 0, failed: 3, latencyTotalMs: 0 }],
+      NOW
+    );
+    expect(stats.successRate).toBe(0);
+    expect(stats.avgLatencyMs).toBeNull();
+  });
+
+  it("sorts devices by ID", () => {
+    const stats = summarizeDeviceStats(
+      [
+        { deviceId: "web", hour: THIS_HOUR, sent: 1, failed: 0, latencyTotalMs: 10 },
+        { deviceId: "kitchen", hour: THIS_HOUR, sent: 1, failed: 0, latencyTotalMs: 10 },
+      ],
+      NOW
+    );
+    expect(stats.map((s) => s.deviceId)).toEqual(["kitchen", "web"]);
+  });
+});
@@ -0,0 +1,89 @@
+/**
+ * Send statistics rollups
+ */
+
+import type { DeviceSendStats, HourlySendStats, SendResult } from "./types.js";
+
+export const HOUR_MS = 60 * 60 * 1000;
+
+/**
+ * Start of the hour containing a timestamp
+ */
+export function hourStart(timestamp: number): number {
+  return Math.floor(timestamp / HOUR_MS) * HOUR_MS;
+}
+
+/**
+ * Name a device by its terminal ID, falling back to its terminal type
+ * (emulators connect without an ID)
+ */
+export function deviceIdFor(connection: { terminalId?: string | null; terminalType?: string }): string {
+  return connection.terminalId || connection.terminalType || "unknown";
+}
+
+/**
+ * Group send results into per-device counts for one hour
+ */
+export function tallySendResults(results: SendResult[]): Map<string, Omit<HourlySendStats, "hour">> {
+  const tallies = new Map<string, Omit<HourlySendStats, "hour">>();
+  for (const result of results) {
+    const tally = tallies.get(result.deviceId) ?? { sent: 0, failed: 0, latencyTotalMs: 0 };
+    if (result.ok) {
+      tally.sent++;
+      tally.latencyTotalMs += result.latencyMs;
+    } else {
+      tally.failed++;
+    }
+    tallies.set(result.deviceId, tally);
+  }
+  return tallies;
+}
+
+/**
+ * Roll hourly counts up into per-device statistics over the last `hours`
+ * hours (including the current one). Devices are sorted by ID.
+ */
+export function summarizeDeviceStats(
+  rows: Array<HourlySendStats & { deviceId: string }>,
+  now: number,
+  hours: number = 24
+): DeviceSendStats[] {
+  const firstHour = hourStart(now) - (hours - 1) * HOUR_MS;
+  const byDevice = new Map<string, DeviceSendStats & { latencyTotalMs: number }>();
+
+  for (const row of rows) {
+    const index = Math.round((row.hour - firstHour) / HOUR_MS);
+    if (index < 0 || index >= hours) continue;
+
+    let stats = byDevice.get(row.deviceId);
+    if (!stats) {
+      stats = {
+        deviceId: row.deviceId,
+        sent: 0,
+        failed: 0,
+        successRate: null,
+        avgLatencyMs: null,
+        hourly: Array.from({ length: hours }, () => null),
+        latencyTotalMs: 0,
+      };
+      byDevice.set(row.deviceId, stats);
+    }
+
+    stats.sent += row.sent;
+    stats.failed += row.failed;
+    stats.latencyTotalMs += row.latencyTotalMs;
+    const slot = stats.hourly[index] ?? { sent: 0, failed: 0 };
+    stats.hourly[index] = { sent: slot.sent + row.sent, failed: slot.failed + row.failed };
+  }
+
+  return [...byDevice.values()]
+    .sort((a, b) => a.deviceId.localeCompare(b.deviceId))
+    .map(({ latencyTotalMs, ...stats }) => {
+      const attempts = stats.sent + stats.failed;
+      return {
+        ...stats,
+        successRate: attempts > 0 ? stats.sent / attempts : null,
+        avgLatencyMs: stats.sent > 0 ? Math.round(latencyTotalMs / stats.sent) : null,
+      };
+    });
+}
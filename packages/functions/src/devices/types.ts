@@ -0,0 +1,51 @@
+/**
+ * Per-device send statistics types
+ */
+
+/**
+ * Outcome of one frame send to one device
+ */
+export interface SendResult {
+  deviceId: string;
+  ok: boolean;
+  /** Time for the PostToConnection call */
+  latencyMs: number;
+}
+
+/**
+ * Send counts for one device in one hour
+ */
+export interface HourlySendStats {
+  /** Start of the hour (ms) */
+  hour: number;
+  sent: number;
+  failed: number;
+  /** Sum of latencies of successful sends */
+  latencyTotalMs: number;
+}
+
+/**
+ * Rolling statistics for one device
+ */
+export interface DeviceSendStats {
+  deviceId: string;
+  sent: number;
+  failed: number;
+  /** Successful sends / attempts, 0-1 (null with no attempts) */
+  successRate: number | null;
+  /** Mean latency of successful sends (null with none) */
+  avgLatencyMs: number | null;
+  /** One entry per hour, oldest first; null where nothing was attempted */
+  hourly: Array<{ sent: number; failed: number } | null>;
+}
+
+/**
+ * A daily window (device local time) when the device is expected to be
+ * unreachable, e.g. the Divoom nightly firmware check and clock sync
+ */
+export interface MaintenanceWindow {
+  /** "HH:MM" 24h start */
+  start: string;
+  /** "HH:MM" 24h end (may be past midnight) */
+  end: string;
+}
@@ -0,0 +1,41 @@
+import { describe, it, expect } from "vitest";
+import { backoffDelayMs, nextBackoff, isBackingOff, BACKOFF_BASE_MS, BACKOFF_MAX_MS } from "../backoff";
+
+const now = Date.UTC(2026, 9, 16, 12);
+
+describe("backoffDelayMs", () => {
+  it("doubles with each failure", () => {
+    expect(backoffDelayMs(1, () => 1)).toBe(BACKOFF_BASE_MS);
+    expect(backoffDelayMs(2, () => 1)).toBe(2 * BACKOFF_BASE_MS);
+    expect(backoffDelayMs(4, () => 1)).toBe(8 * BACKOFF_BASE_MS);
+  });
+
+  it("jitters between half and the full delay", () => {
+    expect(backoffDelayMs(3, () => 0)).toBe(2 * BACKOFF_BASE_MS);
+    expect(backoffDelayMs(3, () => 0.5)).toBe(3 * BACKOFF_BASE_MS);
+  });
+
+  it("caps the delay", () => {
+    expect(backoffDelayMs(20, () => 1)).toBe(BACKOFF_MAX_MS);
+  });
+});
+
+describe("nextBackoff", () => {
+  it("counts failures and schedules the retry", () => {
+    const first = nextBackoff(null, "Dexcom fetch failed: 500", now, () => 1);
+    expect(first).toEqual({ failures: 1, retryAt: now + BACKOFF_BASE_MS, lastError: "Dexcom fetch failed: 500" });
+
+    const second = nextBackoff(first, "Dexcom fetch failed: 503", now, () => 1);
+    expect(second.failures).toBe(2);
+    expect(second.retryAt).toBe(now + 2 * BACKOFF_BASE_MS);
+  });
+});
+
+describe("isBackingOff", () => {
+  it("holds off until the retry time", () => {
+    const state = { failures: 2, retryAt: now + 1000 };
+    expect(isBackingOff(state, now)).toBe(true);
+    expect(isBackingOff(state, now + 1000)).toBe(false);
+    expect(isBackingOff(null, now)).toBe(false);
+  });
+});
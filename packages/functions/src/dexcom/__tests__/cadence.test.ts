@@ -0,0 +1,24 @@
+import { describe, it, expect } from "vitest";
+import {
+  nextDexcomFetchDelayMs,
+  DEXCOM_LATE_POLL_MS,
+  DEXCOM_READING_INTERVAL_MS,
+  DEXCOM_UPLOAD_LAG_MS,
+} from "../cadence";
+
+const now = Date.UTC(2026, 9, 16, 12);
+
+describe("nextDexcomFetchDelayMs", () => {
+  it("waits until the next reading should be uploaded", () => {
+    expect(nextDexcomFetchDelayMs(now - 60_000, now)).toBe(DEXCOM_READING_INTERVAL_MS - 60_000 + DEXCOM_UPLOAD_LAG_MS);
+  });
+
+  it("never waits longer than one reading interval", () => {
+    expect(nextDexcomFetchDelayMs(now + 60_000, now)).toBe(DEXCOM_READING_INTERVAL_MS);
+  });
+
+  it("polls while a reading is late, or before the first", () => {
+    expect(nextDexcomFetchDelayMs(now - 12 * 60_000, now)).toBe(DEXCOM_LATE_POLL_MS);
+    expect(nextDexcomFetchDelayMs(null, now)).toBe(DEXCOM_LATE_POLL_MS);
+  });
+});
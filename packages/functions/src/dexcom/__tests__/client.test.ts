@@ -3,6 +3,7 @@ import {
   parseDexcomTimestamp,
   getSessionId,
   fetchGlucoseReadings,
+  isTrendComputable,
   DEXCOM_BASE_URL,
   DEXCOM_APP_ID,
   type DexcomCredentials,
@@ -28,6 +29,20 @@ describe("parseDexcomTimestamp", () => {
   });
 });
 
+describe("isTrendComputable", () => {
+  it("accepts directional trends", () => {
+    expect(isTrendComputable("Flat")).toBe(true);
+    expect(isTrendComputable("SingleUp")).toBe(true);
+    expect(isTrendComputable("DoubleDown")).toBe(true);
+  });
+
+  it("rejects NotComputable and RateOutOfRange in any case", () => {
+    expect(isTrendComputable("NotComputable")).toBe(false);
+    expect(isTrendComputable("RateOutOfRange")).toBe(false);
+    expect(isTrendComputable("notcomputable")).toBe(false);
+  });
+});
+
 describe("getSessionId", () => {
   let fetchMock: ReturnType<typeof vi.fn>;
   const originalFetch = global.fetch;
@@ -222,6 +237,18 @@ describe("fetchGlucoseReadings", () => {
     );
   });
 
+  it("includes Dexcom's error code when the response has one", async () => {
+    fetchMock.mockResolvedValueOnce({
+      ok: false,
+      status: 500,
+      json: () => Promise.resolve({ Code: "SessionNotValid", Message: "Session not active or timed out" }),
+    });
+
+    await expect(fetchGlucoseReadings("expired-session")).rejects.toThrow(
+      "Dexcom fetch failed: 500 (SessionNotValid)"
+    );
+  });
+
   it("returns multiple readings in order", async () => {
     const now = Date.now();
     const mockReadings: DexcomReading[] = [
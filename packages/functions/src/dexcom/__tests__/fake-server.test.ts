@@ -0,0 +1,180 @@
+/**
+ * Dexcom client against a fake Share server
+ *
+ * Unlike client.test.ts (which stubs fetch), these go over real HTTP, so
+ * request bodies, status codes and response parsing are exercised end to end.
+ */
+
+import { describe, it, expect, beforeAll, afterAll, beforeEach } from "vitest";
+import { createServer, type IncomingMessage, type Server, type ServerResponse } from "node:http";
+import type { AddressInfo } from "node:net";
+import { getSessionId, fetchGlucoseReadings, DEXCOM_APP_ID } from "../client.js";
+import { isSessionError } from "../session.js";
+import { filterGlucoseReadings } from "../../ingest/sanity-filters.js";
+
+interface FakeDexcom {
+  accountName: string;
+  password: string;
+  /** Session IDs the server accepts */
+  sessions: Set<string>;
+  /** Body returned for readings, as raw text */
+  readingsBody: string;
+  /** Requests received, for assertions */
+  requests: Array<{ path: string; body: unknown }>;
+}
+
+const fake: FakeDexcom = {
+  accountName: "",
+  password: "",
+  sessions: new Set(),
+  readingsBody: "[]",
+  requests: [],
+};
+
+function send(res: ServerResponse, status: number, body: unknown): void {
+  res.writeHead(status, { "Content-Type": "application/json" });
+  res.end(typeof body === "string" ? body : JSON.stringify(body));
+}
+
+async function readBody(req: IncomingMessage): Promise<unknown> {
+  let raw = "";
+  for await (const chunk of req) raw += chunk;
+  return raw ? JSON.parse(raw) : null;
+}
+
+async function handle(req: IncomingMessage, res: ServerResponse): Promise<void> {
+  const url = new URL(req.url ?? "/", "http://localhost");
+  const body = (await readBody(req)) as Record<string, string> | null;
+  fake.requests.push({ path: url.pathname, body });
+
+  switch (url.pathname) {
+    case "/ShareWebServices/Services/General/AuthenticatePublisherAccount":
+      if (body?.accountName !== fake.accountName || body?.password !== fake.password) {
+        return send(res, 500, { Code: "AccountPasswordInvalid", Message: "Account password invalid" });
+      }
+      return send(res, 200, "account-1");
+    case "/ShareWebServices/Services/General/LoginPublisherAccountById": {
+      if (body?.accountId !== "account-1") {
+        return send(res, 500, { Code: "AccountNotFound" });
+      }
+      const sessionId = `session-${fake.sessions.size + 1}`;
+      fake.sessions.add(sessionId);
+      return send(res, 200, sessionId);
+    }
+    case "/ShareWebServices/Services/Publisher/ReadPublisherLatestGlucoseValues":
+      if (!fake.sessions.has(url.searchParams.get("sessionId") ?? "")) {
+        return send(res, 500, { Code: "SessionIdNotFound", Message: "Session ID not found" });
+      }
+      return send(res, 200, fake.readingsBody);
+    default:
+      return send(res, 404, "");
+  }
+}
+
+let server: Server;
+let baseUrl: string;
+
+beforeAll(async () => {
+  server = createServer((req, res) => void handle(req, res));
+  await new Promise<void>((resolve) => server.listen(0, "127.0.0.1", resolve));
+  const { port } = server.address() as AddressInfo;
+  baseUrl = `http://127.0.0.1:${port}/ShareWebServices/Services`;
+});
+
+afterAll(async () => {
+  await new Promise((resolve) => server.close(resolve));
+});
+
+beforeEach(() => {
+  fake.accountName = "follower";
+  fake.password = "secret";
+  fake.sessions = new Set();
+  fake.readingsBody = "[]";
+  fake.requests = [];
+});
+
+const credentials = { username: "follower", password: "secret" };
+
+describe("authentication", () => {
+  it("logs in with the account ID from the first step", async () => {
+    const sessionId = await getSessionId(credentials, baseUrl);
+
+    expect(sessionId).toBe("session-1");
+    expect(fake.requests.map((r) => r.body)).toEqual([
+      { accountName: "follower", password: "secret", applicationId: DEXCOM_APP_ID },
+      { accountId: "account-1", password: "secret", applicationId: DEXCOM_APP_ID },
+    ]);
+  });
+
+  it("fails without requesting a session when the password is wrong", async () => {
+    await expect(getSessionId({ ...credentials, password: "wrong" }, baseUrl)).rejects.toThrow(
+      "Dexcom auth failed: 500"
+    );
+    expect(fake.requests).toHaveLength(1);
+  });
+});
+
+describe("session expiry", () => {
+  it("reports an expired session as a session error", async () => {
+    const sessionId = await getSessionId(credentials, baseUrl);
+    fake.sessions.clear();
+
+    const error = await fetchGlucoseReadings(sessionId, 30, 2, baseUrl).catch((e: unknown) => e);
+
+    expect(error).toBeInstanceOf(Error);
+    expect((error as Error).message).toBe("Dexcom fetch failed: 500 (SessionIdNotFound)");
+    expect(isSessionError(error)).toBe(true);
+  });
+
+  it("works again with a new session", async () => {
+    await getSessionId(credentials, baseUrl);
+    fake.sessions.clear();
+    const renewed = await getSessionId(credentials, baseUrl);
+
+    await expect(fetchGlucoseReadings(renewed, 30, 2, baseUrl)).resolves.toEqual([]);
+  });
+});
+
+describe("malformed readings", () => {
+  it("rejects a response that isn't a list of readings", async () => {
+    const sessionId = await getSessionId(credentials, baseUrl);
+    fake.readingsBody = JSON.stringify({ Value: 120 });
+
+    await expect(fetchGlucoseReadings(sessionId, 30, 2, baseUrl)).rejects.toThrow("malformed readings");
+  });
+
+  it("fails on a body that isn't JSON", async () => {
+    const sessionId = await getSessionId(credentials, baseUrl);
+    fake.readingsBody = "<html>maintenance</html>";
+
+    await expect(fetchGlucoseReadings(sessionId, 30, 2, baseUrl)).rejects.toThrow();
+  });
+
+  it("treats a null body as no readings", async () => {
+    const sessionId = await getSessionId(credentials, baseUrl);
+    fake.readingsBody = "null";
+
+    await expect(fetchGlucoseReadings(sessionId, 30, 2, baseUrl)).resolves.toEqual([]);
+  });
+
+  it("lets the sanity filter drop readings with missing or broken fields", async () => {
+    const sessionId = await getSessionId(credentials, baseUrl);
+    const now = Date.now();
+    fake.readingsBody = JSON.stringify([
+      { WT: `Date(${now})`, ST: "", DT: "", Value: 120, Trend: "Flat" },
+      { ST: "", DT: "", Value: 118, Trend: "Flat" },
+      { WT: "yesterday", ST: "", DT: "", Value: 115, Trend: "Flat" },
+      { WT: `Date(${now - 600_000})`, ST: "", DT: "", Value: "high", Trend: "Flat" },
+    ]);
+
+    const readings = await fetchGlucoseReadings(sessionId, 30, 4, baseUrl);
+    const { accepted, rejected } = filterGlucoseReadings(readings);
+
+    expect(accepted.map((r) => r.Value)).toEqual([120]);
+    expect(rejected.map((r) => r.reason)).toEqual([
+      "invalid timestamp",
+      "invalid timestamp",
+      "glucose out of range",
+    ]);
+  });
+});
@@ -0,0 +1,46 @@
+import { describe, it, expect } from "vitest";
+import { refillBucket, takeTokens, type DexcomRateLimit } from "../rate-limit.js";
+
+const limit: DexcomRateLimit = { capacity: 10, refillPerMinute: 2 };
+const now = new Date("2026-03-02T12:00:00Z").getTime();
+const MIN = 60 * 1000;
+
+describe("refillBucket", () => {
+  it("starts full", () => {
+    expect(refillBucket(null, limit, now)).toEqual({ tokens: 10, updatedAt: now });
+  });
+
+  it("refills continuously up to capacity", () => {
+    expect(refillBucket({ tokens: 1, updatedAt: now - 90 * 1000 }, limit, now).tokens).toBe(4);
+    expect(refillBucket({ tokens: 9, updatedAt: now - 10 * MIN }, limit, now).tokens).toBe(10);
+  });
+
+  it("doesn't refill for a clock that went backwards", () => {
+    expect(refillBucket({ tokens: 3, updatedAt: now + MIN }, limit, now).tokens).toBe(3);
+  });
+});
+
+describe("takeTokens", () => {
+  it("takes tokens while the budget lasts", () => {
+    const result = takeTokens({ tokens: 2, updatedAt: now }, 2, limit, now);
+    expect(result).toEqual({ allowed: true, state: { tokens: 0, updatedAt: now } });
+  });
+
+  it("refuses without spending when the budget is short", () => {
+    const result = takeTokens({ tokens: 1, updatedAt: now }, 2, limit, now);
+    expect(result).toEqual({ allowed: false, state: { tokens: 1, updatedAt: now } });
+  });
+
+  it("limits a tight loop to the refill rate", () => {
+    let state = null;
+    let allowed = 0;
+    // One run every 5 seconds for 10 minutes, 2 calls each
+    for (let t = now; t < now + 10 * MIN; t += 5000) {
+      const result = takeTokens(state, 2, limit, t);
+      state = result.state;
+      if (result.allowed) allowed++;
+    }
+    // 10 from the full bucket plus just under 20 refilled, at 2 calls a run
+    expect(allowed).toBe(14);
+  });
+});
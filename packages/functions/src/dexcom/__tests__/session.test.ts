@@ -0,0 +1,151 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+
+const { mockSend, mockGetSessionId } = vi.hoisted(() => ({
+  mockSend: vi.fn(),
+  mockGetSessionId: vi.fn(),
+}));
+
+vi.mock("sst", () => ({
+  Resource: { SignageTable: { name: "test-table" } },
+}));
+
+vi.mock("@aws-sdk/client-dynamodb", () => ({
+  DynamoDBClient: vi.fn().mockImplementation(() => ({})),
+}));
+
+vi.mock("@aws-sdk/lib-dynamodb", () => ({
+  DynamoDBDocumentClient: { from: () => ({ send: mockSend }) },
+  GetCommand: vi.fn().mockImplementation((params) => ({ ...params, _type: "Get" })),
+  PutCommand: vi.fn().mockImplementation((params) => ({ ...params, _type: "Put" })),
+}));
+
+vi.mock("../client.js", () => ({
+  getSessionId: mockGetSessionId,
+}));
+
+import { isSessionFresh, isSessionError, SESSION_MAX_AGE_MS } from "../session.js";
+import { clearSessionCache, getDexcomSessionId, withDexcomSession } from "../session-store.js";
+
+const credentials = { username: "user", password: "pass" };
+const now = new Date("2026-03-02T12:00:00Z").getTime();
+
+describe("isSessionFresh", () => {
+  it("accepts a session younger than the max age", () => {
+    expect(isSessionFresh({ sessionId: "s", createdAt: now - 60_000 }, now)).toBe(true);
+  });
+
+  it("renews a session at the max age, before Dexcom expires it", () => {
+    expect(isSessionFresh({ sessionId: "s", createdAt: now - SESSION_MAX_AGE_MS }, now)).toBe(false);
+  });
+
+  it("rejects a missing session or one created in the future", () => {
+    expect(isSessionFresh(null, now)).toBe(false);
+    expect(isSessionFresh({ sessionId: "s", createdAt: now + 60_000 }, now)).toBe(false);
+  });
+});
+
+describe("isSessionError", () => {
+  it("recognises Dexcom's session error codes", () => {
+    expect(isSessionError(new Error("Dexcom fetch failed: 500 (SessionNotValid)"))).toBe(true);
+    expect(isSessionError(new Error("Dexcom fetch failed: 500 (SessionIdNotFound)"))).toBe(true);
+  });
+
+  it("ignores other failures", () => {
+    expect(isSessionError(new Error("Dexcom fetch failed: 503"))).toBe(false);
+  });
+});
+
+describe("getDexcomSessionId", () => {
+  beforeEach(() => {
+    mockSend.mockReset();
+    mockGetSessionId.mockReset();
+    clearSessionCache();
+  });
+
+  it("reuses a fresh stored session without logging in", async () => {
+    mockSend.mockResolvedValueOnce({ Item: { sessionId: "stored", createdAt: now - 60_000 } });
+
+    expect(await getDexcomSessionId(credentials, now)).toBe("stored");
+    expect(mockGetSessionId).not.toHaveBeenCalled();
+  });
+
+  it("logs in and stores the session when the stored one is due for renewal", async () => {
+    mockSend.mockResolvedValueOnce({ Item: { sessionId: "old", createdAt: now - SESSION_MAX_AGE_MS } });
+    mockSend.mockResolvedValueOnce({});
+    mockGetSessionId.mockResolvedValueOnce("new");
+
+    expect(await getDexcomSessionId(credentials, now)).toBe("new");
+    expect(mockSend.mock.calls[1][0]).toMatchObject({
+      _type: "Put",
+      Item: { pk: "DEXCOM_SESSION", sk: "CURRENT", sessionId: "new", createdAt: now },
+    });
+  });
+
+  it("keeps the session in memory between calls", async () => {
+    mockSend.mockResolvedValueOnce({ Item: { sessionId: "stored", createdAt: now } });
+
+    await getDexcomSessionId(credentials, now);
+    await getDexcomSessionId(credentials, now + 60_000);
+
+    expect(mockSend).toHaveBeenCalledTimes(1);
+  });
+
+  it("keeps each account's session in its own slot", async () => {
+    mockSend.mockResolvedValueOnce({});
+    mockSend.mockResolvedValueOnce({});
+    mockGetSessionId.mockResolvedValueOnce("follower");
+
+    expect(await getDexcomSessionId(credentials, now, "FOLLOWER#max")).toBe("follower");
+    expect(mockSend.mock.calls[0][0]).toMatchObject({ _type: "Get", Key: { pk: "DEXCOM_SESSION", sk: "FOLLOWER#max" } });
+    expect(mockSend.mock.calls[1][0]).toMatchObject({ _type: "Put", Item: { sk: "FOLLOWER#max", sessionId: "follower" } });
+  });
+
+  it("logs in when the store can't be read", async () => {
+    vi.spyOn(console, "error").mockImplementation(() => {});
+    mockSend.mockRejectedValueOnce(new Error("throttled"));
+    mockSend.mockResolvedValueOnce({});
+    mockGetSessionId.mockResolvedValueOnce("new");
+
+    expect(await getDexcomSessionId(credentials, now)).toBe("new");
+  });
+});
+
+describe("withDexcomSession", () => {
+  beforeEach(() => {
+    mockSend.mockReset();
+    mockGetSessionId.mockReset();
+    clearSessionCache();
+    mockSend.mockResolvedValue({});
+    mockSend.mockResolvedValueOnce({ Item: { sessionId: "stored", createdAt: now } });
+  });
+
+  it("logs in again and retries once when Dexcom rejects the session", async () => {
+    vi.spyOn(console, "log").mockImplementation(() => {});
+    mockGetSessionId.mockResolvedValueOnce("new");
+    const call = vi
+      .fn()
+      .mockRejectedValueOnce(new Error("Dexcom fetch failed: 500 (SessionNotValid)"))
+      .mockResolvedValueOnce(["reading"]);
+
+    expect(await withDexcomSession(credentials, call, now)).toEqual(["reading"]);
+    expect(call.mock.calls.map(([id]) => id)).toEqual(["stored", "new"]);
+  });
+
+  it("gives up after one retry", async () => {
+    vi.spyOn(console, "log").mockImplementation(() => {});
+    mockGetSessionId.mockResolvedValueOnce("new");
+    const call = vi.fn().mockRejectedValue(new Error("Dexcom fetch failed: 500 (SessionNotValid)"));
+
+    await expect(withDexcomSession(credentials, call, now)).rejects.toThrow("SessionNotValid");
+    expect(call).toHaveBeenCalledTimes(2);
+    expect(mockGetSessionId).toHaveBeenCalledTimes(1);
+  });
+
+  it("does not log in again for other errors", async () => {
+    const call = vi.fn().mockRejectedValue(new Error("Dexcom fetch failed: 503"));
+
+    await expect(withDexcomSession(credentials, call, now)).rejects.toThrow("503");
+    expect(call).toHaveBeenCalledTimes(1);
+    expect(mockGetSessionId).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,60 @@
+/**
+ * Dexcom backoff store
+ * Keeps the backoff state between compositor runs in DynamoDB.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, DeleteCommand, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import { nextBackoff, type DexcomBackoffState } from "./backoff.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** DynamoDB key for the backoff state */
+const BACKOFF_KEY = { pk: "DEXCOM_BACKOFF", sk: "STATE" };
+
+/**
+ * Get the current backoff state, or null when Dexcom is healthy
+ */
+export async function getDexcomBackoff(): Promise<DexcomBackoffState | null> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: BACKOFF_KEY,
+    })
+  );
+  if (!result.Item) return null;
+  const { failures, retryAt, lastError } = result.Item;
+  return { failures: failures as number, retryAt: retryAt as number, lastError: lastError as string | undefined };
+}
+
+/**
+ * Record a failed run and return the new state
+ */
+export async function recordDexcomFailure(
+  state: DexcomBackoffState | null,
+  error: string,
+  now: number = Date.now()
+): Promise<DexcomBackoffState> {
+  const next = nextBackoff(state, error, now);
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: { ...BACKOFF_KEY, ...next },
+    })
+  );
+  return next;
+}
+
+/**
+ * Clear the backoff after a successful run
+ */
+export async function clearDexcomBackoff(): Promise<void> {
+  await ddb.send(
+    new DeleteCommand({
+      TableName: Resource.SignageTable.name,
+      Key: BACKOFF_KEY,
+    })
+  );
+}
@@ -0,0 +1,55 @@
+/**
+ * Dexcom error backoff
+ *
+ * The compositor runs every minute. When Dexcom is failing, it waits an
+ * exponentially growing, jittered delay between attempts instead of calling
+ * on every run, and renders from cached data meanwhile.
+ */
+
+/** Delay after the first failure */
+export const BACKOFF_BASE_MS = 60 * 1000;
+
+/** Longest delay between attempts */
+export const BACKOFF_MAX_MS = 15 * 60 * 1000;
+
+/**
+ * Backoff state carried between compositor runs
+ */
+export interface DexcomBackoffState {
+  /** Consecutive failed runs */
+  failures: number;
+  /** No Dexcom calls before this time (ms) */
+  retryAt: number;
+  /** Message of the latest error */
+  lastError?: string;
+}
+
+/**
+ * Delay before the next attempt after `failures` consecutive failures.
+ * "Equal jitter": half the exponential delay, plus a random part of the
+ * other half, so retries don't line up with Dexcom's recovery.
+ */
+export function backoffDelayMs(failures: number, random: () => number = Math.random): number {
+  const exponential = Math.min(BACKOFF_MAX_MS, BACKOFF_BASE_MS * 2 ** Math.max(0, failures - 1));
+  return Math.round(exponential / 2 + (exponential / 2) * random());
+}
+
+/**
+ * State after another failed run
+ */
+export function nextBackoff(
+  state: DexcomBackoffState | null,
+  error: string,
+  now: number,
+  random: () => number = Math.random
+): DexcomBackoffState {
+  const failures = (state?.failures ?? 0) + 1;
+  return { failures, retryAt: now + backoffDelayMs(failures, random), lastError: error };
+}
+
+/**
+ * Check if Dexcom calls should be skipped at `now`
+ */
+export function isBackingOff(state: DexcomBackoffState | null, now: number): boolean {
+  return state !== null && now < state.retryAt;
+}
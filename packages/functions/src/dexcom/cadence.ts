@@ -0,0 +1,29 @@
+/**
+ * Dexcom fetch cadence
+ *
+ * The sensor takes a reading every 5 minutes and Share has it a little
+ * later. Polling on a fixed minute mostly finds nothing new, and finds
+ * the new reading up to a minute late; a poller that keeps running (the
+ * local server) instead waits until the next reading should be there.
+ */
+
+/** Time between sensor readings */
+export const DEXCOM_READING_INTERVAL_MS = 5 * 60 * 1000;
+
+/** How long after the reading's timestamp Share usually has it */
+export const DEXCOM_UPLOAD_LAG_MS = 20 * 1000;
+
+/** Check again this often while an expected reading is late */
+export const DEXCOM_LATE_POLL_MS = 60 * 1000;
+
+/**
+ * Delay before the next fetch, given the timestamp of the latest reading
+ * (null before the first one)
+ */
+export function nextDexcomFetchDelayMs(latestReadingAt: number | null, now: number): number {
+  if (latestReadingAt === null) return DEXCOM_LATE_POLL_MS;
+  const due = latestReadingAt + DEXCOM_READING_INTERVAL_MS + DEXCOM_UPLOAD_LAG_MS;
+  // A late reading may be missed entirely (signal loss), so keep checking
+  // rather than skipping to the next slot
+  return due > now ? Math.min(due - now, DEXCOM_READING_INTERVAL_MS) : DEXCOM_LATE_POLL_MS;
+}
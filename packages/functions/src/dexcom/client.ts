@@ -30,23 +30,51 @@ export interface DexcomReading {
   Trend: string;
 }
 
+/** Trends where Dexcom can't tell direction, so the delta is meaningless */
+const NON_COMPUTABLE_TRENDS = new Set(["notcomputable", "rateoutofrange"]);
+
+/**
+ * Check whether a trend carries a usable direction (and delta).
+ * NotComputable and RateOutOfRange are reported during sensor glitches.
+ */
+export function isTrendComputable(trend: string): boolean {
+  return !NON_COMPUTABLE_TRENDS.has(trend.toLowerCase());
+}
+
 /**
  * Parse Dexcom timestamp format "Date(1234567890000)" to milliseconds.
+ * Returns 0 for anything else, including a missing field.
  */
 export function parseDexcomTimestamp(wt: string): number {
-  const match = wt.match(/Date\((\d+)\)/);
+  const match = typeof wt === "string" ? wt.match(/Date\((\d+)\)/) : null;
   return match ? parseInt(match[1], 10) : 0;
 }
 
+/**
+ * Read the error code from a failed Share API response
+ * (e.g. "SessionNotValid"), or null if there isn't one.
+ */
+async function readErrorCode(response: Response): Promise<string | null> {
+  try {
+    const body = (await response.json()) as { Code?: string };
+    return body?.Code ?? null;
+  } catch {
+    return null;
+  }
+}
+
 /**
  * Authenticate with Dexcom Share and get a session ID.
  *
  * Two-step process:
  * 1. Authenticate with username/password to get account ID
  * 2. Login with account ID to get session ID
+ *
+ * @param baseUrl - Share API base URL (default: US region; tests point this at a fake server)
  */
 export async function getSessionId(
-  credentials: DexcomCredentials
+  credentials: DexcomCredentials,
+  baseUrl: string = DEXCOM_BASE_URL
 ): Promise<string> {
   const { username, password } = credentials;
 
@@ -56,7 +84,7 @@ export async function getSessionId(
 
   // Step 1: Get account ID
   const authResponse = await fetch(
-    `${DEXCOM_BASE_URL}/General/AuthenticatePublisherAccount`,
+    `${baseUrl}/General/AuthenticatePublisherAccount`,
     {
       method: "POST",
       headers: {
@@ -83,7 +111,7 @@ export async function getSessionId(
 
   // Step 2: Get session ID
   const sessionResponse = await fetch(
-    `${DEXCOM_BASE_URL}/General/LoginPublisherAccountById`,
+    `${baseUrl}/General/LoginPublisherAccountById`,
     {
       method: "POST",
       headers: {
@@ -111,15 +139,17 @@ export async function getSessionId(
  * @param sessionId - Session ID from getSessionId()
  * @param minutes - Time window in minutes (max 1440 = 24 hours)
  * @param maxCount - Maximum number of readings to return
+ * @param baseUrl - Share API base URL (default: US region)
  * @returns Array of readings, newest first
  */
 export async function fetchGlucoseReadings(
   sessionId: string,
   minutes: number = 30,
-  maxCount: number = 2
+  maxCount: number = 2,
+  baseUrl: string = DEXCOM_BASE_URL
 ): Promise<DexcomReading[]> {
   const response = await fetch(
-    `${DEXCOM_BASE_URL}/Publisher/ReadPublisherLatestGlucoseValues?sessionId=${sessionId}&minutes=${minutes}&maxCount=${maxCount}`,
+    `${baseUrl}/Publisher/ReadPublisherLatestGlucoseValues?sessionId=${sessionId}&minutes=${minutes}&maxCount=${maxCount}`,
     {
       method: "POST",
       headers: {
@@ -130,8 +160,17 @@ export async function fetchGlucoseReadings(
   );
 
   if (!response.ok) {
-    throw new Error(`Dexcom fetch failed: ${response.status}`);
+    const code = await readErrorCode(response);
+    throw new Error(`Dexcom fetch failed: ${response.status}${code ? ` (${code})` : ""}`);
   }
 
-  return response.json() as Promise<DexcomReading[]>;
+  const readings = (await response.json()) as unknown;
+  // An empty body means no readings in the window
+  if (readings === null) {
+    return [];
+  }
+  if (!Array.isArray(readings)) {
+    throw new Error("Dexcom fetch returned malformed readings");
+  }
+  return readings as DexcomReading[];
 }
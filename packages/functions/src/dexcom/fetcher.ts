@@ -0,0 +1,40 @@
+/**
+ * Glucose fetcher
+ * What the compositor needs from Dexcom, behind an interface so the fetch
+ * and fallback logic can be tested without the Share API.
+ */
+
+import { fetchGlucoseReadings, type DexcomCredentials, type DexcomReading } from "./client.js";
+import { getDexcomSessionId, PRIMARY_SESSION_SLOT, withDexcomSession } from "./session-store.js";
+
+export interface GlucoseFetcher {
+  /** Make sure there is a usable session; throws if logging in fails */
+  authenticate(): Promise<void>;
+  /** Readings from the last `minutes`, newest first */
+  fetchReadings(minutes: number, maxCount: number): Promise<DexcomReading[]>;
+}
+
+/**
+ * Fetcher backed by the Share API, reusing the stored session (in `slot`,
+ * for accounts other than the main one)
+ */
+export function createDexcomFetcher(
+  credentials: DexcomCredentials,
+  slot: string = PRIMARY_SESSION_SLOT
+): GlucoseFetcher {
+  return {
+    async authenticate() {
+      await getDexcomSessionId(credentials, Date.now(), slot);
+    },
+    async fetchReadings(minutes, maxCount) {
+      return (
+        (await withDexcomSession(
+          credentials,
+          (sessionId) => fetchGlucoseReadings(sessionId, minutes, maxCount),
+          Date.now(),
+          slot
+        )) ?? []
+      );
+    },
+  };
+}
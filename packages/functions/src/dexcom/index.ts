@@ -0,0 +1,9 @@
+/**
+ * Dexcom timing, for the local server
+ *
+ * Only the pure helpers; the client and stores need the deployed
+ * resources.
+ */
+
+export * from "./cadence.js";
+export * from "./backoff.js";
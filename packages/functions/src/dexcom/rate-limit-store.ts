@@ -0,0 +1,94 @@
+/**
+ * Dexcom request budget store
+ * Keeps the shared token bucket in DynamoDB, so every Lambda using the
+ * account draws from the same budget.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import { DEFAULT_DEXCOM_RATE_LIMIT, takeTokens, type DexcomRateLimit, type TokenBucketState } from "./rate-limit.js";
+import { getDisplayConfig } from "../display/config-store.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** DynamoDB key for the token bucket */
+const BUCKET_KEY = { pk: "DEXCOM_RATE_LIMIT", sk: "BUCKET" };
+
+/** Attempts when another run updates the bucket at the same time */
+const MAX_ATTEMPTS = 3;
+
+/**
+ * Take `count` tokens from the shared bucket. Returns false when the
+ * budget is spent.
+ */
+export async function takeDexcomTokens(
+  count: number,
+  limit: DexcomRateLimit,
+  now: number = Date.now()
+): Promise<boolean> {
+  for (let attempt = 0; attempt < MAX_ATTEMPTS; attempt++) {
+    const result = await ddb.send(
+      new GetCommand({
+        TableName: Resource.SignageTable.name,
+        Key: BUCKET_KEY,
+      })
+    );
+    const previous = result.Item
+      ? { tokens: result.Item.tokens as number, updatedAt: result.Item.updatedAt as number }
+      : null;
+    const { allowed, state } = takeTokens(previous, count, limit, now);
+    if (!allowed) return false;
+
+    try {
+      await saveBucket(state, previous);
+      return true;
+    } catch (error) {
+      if ((error as { name?: string }).name !== "ConditionalCheckFailedException") {
+        throw error;
+      }
+      // Another run took tokens in between; read the bucket again
+    }
+  }
+  return false;
+}
+
+/**
+ * Take `count` tokens for Dexcom calls made outside the compositor, under
+ * the configured limit. Like the compositor's runs, a budget that can't
+ * be checked doesn't block the calls.
+ */
+export async function reserveDexcomCalls(count: number, now: number = Date.now()): Promise<boolean> {
+  let limit = DEFAULT_DEXCOM_RATE_LIMIT;
+  try {
+    limit = (await getDisplayConfig()).dexcomRateLimit ?? DEFAULT_DEXCOM_RATE_LIMIT;
+  } catch (error) {
+    console.error("Failed to read the Dexcom rate limit, using the default:", error);
+  }
+
+  try {
+    return await takeDexcomTokens(count, limit, now);
+  } catch (error) {
+    console.error("Failed to check Dexcom request budget:", error);
+    return true;
+  }
+}
+
+/**
+ * Save the bucket, only if nobody else changed it since it was read
+ */
+async function saveBucket(state: TokenBucketState, previous: TokenBucketState | null): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: { ...BUCKET_KEY, ...state },
+      ...(previous
+        ? {
+            ConditionExpression: "updatedAt = :updatedAt AND tokens = :tokens",
+            ExpressionAttributeValues: { ":updatedAt": previous.updatedAt, ":tokens": previous.tokens },
+          }
+        : { ConditionExpression: "attribute_not_exists(pk)" }),
+    })
+  );
+}
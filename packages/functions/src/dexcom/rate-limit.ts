@@ -0,0 +1,80 @@
+/**
+ * Dexcom request budget
+ *
+ * Dexcom locks Share accounts that call too often. Every Dexcom call
+ * (compositor runs, the widget updater, backfill repairs, and any extra
+ * deployment or tight debugging loop using the same account) draws from
+ * one token bucket, so a misconfiguration renders cached data instead of
+ * getting the account locked out.
+ */
+
+/**
+ * Token bucket settings
+ */
+export interface DexcomRateLimit {
+  /** Most calls that can be made in a burst */
+  capacity: number;
+  /** Calls added back to the budget per minute */
+  refillPerMinute: number;
+}
+
+/** A compositor run makes two calls (current reading and history) */
+export const DEXCOM_CALLS_PER_RUN = 2;
+
+/** Logging in without a stored session takes two calls (authenticate, then login) */
+export const DEXCOM_LOGIN_CALLS = 2;
+
+/**
+ * Room for one run a minute plus an occasional login and a few manual
+ * refreshes, but not for a second deployment on the same account
+ */
+export const DEFAULT_DEXCOM_RATE_LIMIT: DexcomRateLimit = {
+  capacity: 12,
+  refillPerMinute: 3,
+};
+
+/** Upper bounds for configured limits */
+export const MAX_DEXCOM_CAPACITY = 100;
+export const MAX_DEXCOM_REFILL_PER_MINUTE = 30;
+
+/**
+ * Bucket state carried between runs
+ */
+export interface TokenBucketState {
+  tokens: number;
+  /** When `tokens` was last brought up to date (ms) */
+  updatedAt: number;
+}
+
+/**
+ * Bring the bucket up to date: tokens refill continuously, up to capacity
+ */
+export function refillBucket(
+  state: TokenBucketState | null,
+  limit: DexcomRateLimit,
+  now: number
+): TokenBucketState {
+  if (!state) return { tokens: limit.capacity, updatedAt: now };
+  const elapsedMinutes = Math.max(0, now - state.updatedAt) / 60000;
+  return {
+    tokens: Math.min(limit.capacity, state.tokens + elapsedMinutes * limit.refillPerMinute),
+    updatedAt: now,
+  };
+}
+
+/**
+ * Take `count` tokens if the bucket has them. The returned state is the
+ * refilled bucket, minus the tokens when allowed.
+ */
+export function takeTokens(
+  state: TokenBucketState | null,
+  count: number,
+  limit: DexcomRateLimit,
+  now: number
+): { allowed: boolean; state: TokenBucketState } {
+  const refilled = refillBucket(state, limit, now);
+  if (refilled.tokens < count) {
+    return { allowed: false, state: refilled };
+  }
+  return { allowed: true, state: { ...refilled, tokens: refilled.tokens - count } };
+}
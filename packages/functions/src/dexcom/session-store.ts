@@ -0,0 +1,112 @@
+/**
+ * Dexcom session store
+ * Keeps the current Share session in DynamoDB so it survives cold starts.
+ * Each account has its own slot: the main account's, and one per follower.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import { getSessionId, type DexcomCredentials } from "./client.js";
+import { isSessionError, isSessionFresh, type DexcomSession } from "./session.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** Session slot of the main Dexcom account (the original single session) */
+export const PRIMARY_SESSION_SLOT = "CURRENT";
+
+/** DynamoDB key for a slot's current session */
+function sessionKey(slot: string) {
+  return { pk: "DEXCOM_SESSION", sk: slot };
+}
+
+/** Sessions held by this (warm) Lambda instance, by slot */
+const current = new Map<string, DexcomSession>();
+
+/**
+ * Forget the in-memory sessions (for tests)
+ */
+export function clearSessionCache(): void {
+  current.clear();
+}
+
+async function getStoredSession(slot: string): Promise<DexcomSession | null> {
+  try {
+    const result = await ddb.send(
+      new GetCommand({
+        TableName: Resource.SignageTable.name,
+        Key: sessionKey(slot),
+      })
+    );
+    if (!result.Item) return null;
+    return { sessionId: result.Item.sessionId as string, createdAt: result.Item.createdAt as number };
+  } catch (error) {
+    console.error("Failed to read stored Dexcom session:", error);
+    return null;
+  }
+}
+
+/**
+ * Log in to Dexcom and store the new session
+ */
+export async function renewDexcomSession(
+  credentials: DexcomCredentials,
+  now: number = Date.now(),
+  slot: string = PRIMARY_SESSION_SLOT
+): Promise<DexcomSession> {
+  const session = { sessionId: await getSessionId(credentials), createdAt: now };
+  current.set(slot, session);
+  try {
+    await ddb.send(
+      new PutCommand({
+        TableName: Resource.SignageTable.name,
+        Item: { ...sessionKey(slot), ...session },
+      })
+    );
+  } catch (error) {
+    // The session still works for this run; the next cold start logs in again
+    console.error("Failed to store Dexcom session:", error);
+  }
+  return session;
+}
+
+/**
+ * Get a usable session ID, reusing the stored session until it is due
+ * for renewal
+ */
+export async function getDexcomSessionId(
+  credentials: DexcomCredentials,
+  now: number = Date.now(),
+  slot: string = PRIMARY_SESSION_SLOT
+): Promise<string> {
+  const held = current.get(slot) ?? null;
+  let session = isSessionFresh(held, now) ? held : await getStoredSession(slot);
+  if (!session || !isSessionFresh(session, now)) {
+    session = await renewDexcomSession(credentials, now, slot);
+  }
+  current.set(slot, session);
+  return session.sessionId;
+}
+
+/**
+ * Make a Dexcom call with the current session. If Dexcom rejects the
+ * session, log in again and retry once; a fresh session failing too means
+ * something else is wrong, so that error is thrown.
+ */
+export async function withDexcomSession<T>(
+  credentials: DexcomCredentials,
+  call: (sessionId: string) => Promise<T>,
+  now: number = Date.now(),
+  slot: string = PRIMARY_SESSION_SLOT
+): Promise<T> {
+  const sessionId = await getDexcomSessionId(credentials, now, slot);
+  try {
+    return await call(sessionId);
+  } catch (error) {
+    if (!isSessionError(error)) throw error;
+    console.log("Dexcom session rejected, logging in again");
+    const renewed = await renewDexcomSession(credentials, now, slot);
+    return call(renewed.sessionId);
+  }
+}
@@ -0,0 +1,40 @@
+/**
+ * Dexcom session reuse
+ *
+ * Logging in takes two Share API calls, and the compositor runs every
+ * minute. A session is reused until it is SESSION_MAX_AGE_MS old, then
+ * renewed before Dexcom gets a chance to expire it.
+ */
+
+/**
+ * Renew sessions after this long. Dexcom doesn't document how long a
+ * session lasts; renewing well inside a day keeps clear of it.
+ */
+export const SESSION_MAX_AGE_MS = 6 * 60 * 60 * 1000;
+
+/** A Share session and when it was created */
+export interface DexcomSession {
+  sessionId: string;
+  createdAt: number;
+}
+
+/** Error codes Dexcom returns for a session it no longer accepts */
+const SESSION_ERROR_CODES = ["SessionIdNotFound", "SessionNotValid"];
+
+/**
+ * Check whether a session can still be used without renewing it
+ */
+export function isSessionFresh(session: DexcomSession | null, now: number = Date.now()): boolean {
+  if (!session) return false;
+  const age = now - session.createdAt;
+  return age >= 0 && age < SESSION_MAX_AGE_MS;
+}
+
+/**
+ * Check whether an error means Dexcom rejected the session (as opposed to
+ * being down), so logging in again would help
+ */
+export function isSessionError(error: unknown): boolean {
+  const message = error instanceof Error ? error.message : String(error);
+  return SESSION_ERROR_CODES.some((code) => message.includes(code));
+}
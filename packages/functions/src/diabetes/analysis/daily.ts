@@ -15,11 +15,12 @@ import {
 } from "@diabetes/core";
 import type { ScheduledHandler } from "aws-lambda";
 import { invokeModel } from "./invoke-model.js";
+import { getAnnotations } from "../../annotations/store.js";
+import { formatAnnotationNotes } from "../../annotations/format.js";
+import { DEFAULT_PROFILE_ID, profileUserId } from "../../profiles/profile.js";
 
 const docClient = createDocClient();
 
-const DEFAULT_USER_ID = "john";
-
 const SYSTEM_PROMPT = `You are a friendly diabetes analyst for a Type 1 diabetic using an insulin pump.
 Target range: 70-180 mg/dL. Time in range goal: >70%.
 
@@ -50,7 +51,7 @@ export const handler: ScheduledHandler = async () => {
     const agg = await getDailyAggregation(
       docClient,
       Resource.SignageTable.name,
-      DEFAULT_USER_ID,
+      profileUserId(DEFAULT_PROFILE_ID),
       dateStr
     );
 
@@ -59,11 +60,20 @@ export const handler: ScheduledHandler = async () => {
       return;
     }
 
+    // Annotations (sensor change, travel day, ...) give context for odd days
+    let notes: string | null = null;
+    try {
+      const annotations = await getAnnotations(Date.now() - 48 * 60 * 60_000);
+      notes = formatAnnotationNotes(annotations.filter((a) => formatDateInTimezone(a.timestamp) === dateStr));
+    } catch (error) {
+      console.error("Failed to fetch annotations:", error);
+    }
+
     const userMessage = `## Yesterday's Summary (${dateStr})
 
 Glucose: TIR ${agg.glucose.tir}% | Mean ${Math.round(agg.glucose.mean)} | Range ${agg.glucose.min}-${agg.glucose.max} | CV ${Math.round(agg.glucose.cv)}% | Readings: ${agg.glucose.readings}
 Insulin: ${agg.insulin.totalBolus}u bolus (${agg.insulin.bolusCount} doses) | ${agg.insulin.totalBasal}u basal
-
+${notes ? `Notes: ${notes}\n` : ""}
 Generate a SHORT daily summary (max 30 characters) for my LED display.
 Highlight the key win or area to watch. Call the respond tool.`;
 
@@ -82,7 +92,7 @@ Highlight the key win or area to watch. Call the respond tool.`;
     await storeInsight(
       docClient,
       Resource.SignageTable.name,
-      DEFAULT_USER_ID,
+      profileUserId(DEFAULT_PROFILE_ID),
       "daily",
       content,
       undefined, // metrics
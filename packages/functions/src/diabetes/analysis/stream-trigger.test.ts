@@ -1,5 +1,34 @@
-import { describe, it, expect } from "vitest";
-import type { DynamoDBRecord } from "aws-lambda";
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { DynamoDBRecord, DynamoDBStreamEvent } from "aws-lambda";
+import { DEFAULT_PROFILE_ID, profileUserId } from "../../profiles/profile";
+
+const { mockGetCurrentInsight, mockQueryByTypeAndTimeRange, mockStoreInsight, mockInvokeModel } = vi.hoisted(() => ({
+  mockGetCurrentInsight: vi.fn(),
+  mockQueryByTypeAndTimeRange: vi.fn(),
+  mockStoreInsight: vi.fn(),
+  mockInvokeModel: vi.fn(),
+}));
+
+vi.mock("sst", () => ({
+  Resource: { SignageTable: { name: "test-table" } },
+}));
+
+vi.mock("@diabetes/core", () => ({
+  createDocClient: () => ({}),
+  storeInsight: mockStoreInsight,
+  getCurrentInsight: mockGetCurrentInsight,
+  getCurrentLocalTime: () => "12:00 PM",
+  queryByTypeAndTimeRange: mockQueryByTypeAndTimeRange,
+  calculateGlucoseStats: () => ({ tir: 100, mean: 120, min: 110, max: 130, readingCount: 12 }),
+  getInsightHistory: async () => [],
+  getRecentInsightContents: async () => [],
+}));
+
+vi.mock("./invoke-model.js", () => ({
+  invokeModel: mockInvokeModel,
+}));
+
+import { handler } from "./stream-trigger";
 
 // Constants matching stream-trigger.ts
 const TRIGGER_TYPES = new Set(["CGM"]);
@@ -175,6 +204,84 @@ describe("stream-trigger", () => {
     });
   });
 
+  describe("profile filtering", () => {
+    const defaultUserId = profileUserId(DEFAULT_PROFILE_ID);
+
+    // A fresh CGM insert as the stream delivers it
+    function cgmRecord(userId: string, glucose: number, timestamp: number): DynamoDBRecord {
+      return {
+        eventName: "INSERT",
+        dynamodb: {
+          NewImage: {
+            pk: { S: `USR#${userId}#CGM#2026-02-01` },
+            sk: { S: `${timestamp}` },
+            timestamp: { N: timestamp.toString() },
+            data: { M: { glucoseMgDl: { N: glucose.toString() } } },
+          },
+        },
+      };
+    }
+
+    async function run(records: DynamoDBRecord[]) {
+      await handler({ Records: records } as DynamoDBStreamEvent, {} as never, () => {});
+    }
+
+    beforeEach(() => {
+      vi.clearAllMocks();
+      mockGetCurrentInsight.mockResolvedValue(null);
+      mockQueryByTypeAndTimeRange.mockResolvedValue([]);
+      mockStoreInsight.mockResolvedValue(undefined);
+      mockInvokeModel.mockResolvedValue({ content: "[green]Steady afternoon![/]", reasoning: "Flat for hours" });
+    });
+
+    it("analyses the default profile's readings", async () => {
+      await run([cgmRecord(defaultUserId, 120, Date.now() - 5 * 60 * 1000)]);
+
+      expect(mockGetCurrentInsight).toHaveBeenCalledWith(expect.anything(), "test-table", defaultUserId);
+      expect(mockStoreInsight).toHaveBeenCalledWith(
+        expect.anything(),
+        "test-table",
+        defaultUserId,
+        "hourly",
+        "[green]Steady afternoon![/]",
+        undefined,
+        "Flat for hours",
+        120,
+        "in-range"
+      );
+    });
+
+    it("does NOT analyse another profile's readings", async () => {
+      await run([cgmRecord(profileUserId("grandma"), 250, Date.now() - 60 * 1000)]);
+
+      expect(mockGetCurrentInsight).not.toHaveBeenCalled();
+      expect(mockInvokeModel).not.toHaveBeenCalled();
+      expect(mockStoreInsight).not.toHaveBeenCalled();
+    });
+
+    it("analyses only the default profile's reading in a mixed batch", async () => {
+      const now = Date.now();
+      // The other profile's reading is newer, so it would win if it got through
+      await run([
+        cgmRecord(defaultUserId, 120, now - 5 * 60 * 1000),
+        cgmRecord(profileUserId("grandma"), 250, now - 60 * 1000),
+      ]);
+
+      expect(mockInvokeModel).toHaveBeenCalledWith(expect.any(String), expect.stringContaining("Glucose: 120 mg/dL"));
+      expect(mockStoreInsight).toHaveBeenCalledWith(
+        expect.anything(),
+        "test-table",
+        defaultUserId,
+        "hourly",
+        expect.any(String),
+        undefined,
+        expect.any(String),
+        120,
+        "in-range"
+      );
+    });
+  });
+
   describe("event type filtering", () => {
     it("processes INSERT events", () => {
       const record = createMockRecord("INSERT", "USR#john#CGM#2026-02-01");
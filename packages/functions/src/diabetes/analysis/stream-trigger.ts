@@ -24,10 +24,12 @@ import type { DynamoDBStreamHandler } from "aws-lambda";
 import type { CgmReading, InsightZone, BolusRecord } from "@diabetes/core";
 import { invokeModel } from "./invoke-model.js";
 import { stripMarkup } from "./insight-utils.js";
+import { DEFAULT_PROFILE_ID, profileUserId } from "../../profiles/profile.js";
 
 const docClient = createDocClient();
 
-const DEFAULT_USER_ID = "john";
+// Store user ID of the default profile's records
+const DEFAULT_USER_ID = profileUserId(DEFAULT_PROFILE_ID);
 
 // LED display fits 30 characters (2 lines x 15 chars)
 const MAX_INSIGHT_LENGTH = 30;
@@ -177,9 +179,13 @@ export const handler: DynamoDBStreamHandler = async (event) => {
     if (!pk) return false;
 
     // PK format: USR#{userId}#{TYPE}#{date}
-    const recordType = pk.split("#")[2];
+    const [, userId, recordType] = pk.split("#");
     if (!TRIGGER_TYPES.has(recordType)) return false;
 
+    // Insights are generated for the default profile only; another
+    // household's readings must not feed into its analysis
+    if (userId !== DEFAULT_USER_ID) return false;
+
     // Skip historical backfills - only analyze fresh data
     const timestamp = record.dynamodb?.NewImage?.timestamp?.N;
     if (timestamp && now - Number(timestamp) > FRESHNESS_THRESHOLD_MS) {
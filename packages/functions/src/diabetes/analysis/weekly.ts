@@ -15,11 +15,12 @@ import {
 } from "@diabetes/core";
 import type { ScheduledHandler } from "aws-lambda";
 import { invokeModel } from "./invoke-model.js";
+import { getAnnotations } from "../../annotations/store.js";
+import { formatAnnotationNotes } from "../../annotations/format.js";
+import { DEFAULT_PROFILE_ID, profileUserId } from "../../profiles/profile.js";
 
 const docClient = createDocClient();
 
-const DEFAULT_USER_ID = "john";
-
 const SYSTEM_PROMPT = `You are a friendly diabetes analyst for a Type 1 diabetic using an insulin pump.
 Target range: 70-180 mg/dL. Time in range goal: >70%.
 
@@ -51,7 +52,7 @@ export const handler: ScheduledHandler = async () => {
     const dailyAggs = await getDailyAggregations(
       docClient,
       Resource.SignageTable.name,
-      DEFAULT_USER_ID,
+      profileUserId(DEFAULT_PROFILE_ID),
       startDate,
       endDate
     );
@@ -77,6 +78,14 @@ export const handler: ScheduledHandler = async () => {
     const weekMin = Math.min(...dailyAggs.map((d) => d.glucose.min));
     const weekMax = Math.max(...dailyAggs.map((d) => d.glucose.max));
 
+    // Annotations (sensor change, travel day, ...) give context for odd days
+    let notes: string | null = null;
+    try {
+      notes = formatAnnotationNotes(await getAnnotations(weekAgoMs, now));
+    } catch (error) {
+      console.error("Failed to fetch annotations:", error);
+    }
+
     const userMessage = `## Weekly Summary (${startDate} to ${endDate})
 
 ### Week Totals
@@ -84,7 +93,7 @@ TIR: ${Math.round(weightedTir)}% | Mean: ${Math.round(weekMean)} | Range: ${week
 
 ### Daily Breakdown
 ${dailyLines.join("\n")}
-
+${notes ? `\n### Notes\n${notes}\n` : ""}
 Generate a SHORT weekly summary (max 30 characters) for my LED display.
 Highlight the week's trend or achievement. Call the respond tool.`;
 
@@ -103,7 +112,7 @@ Highlight the week's trend or achievement. Call the respond tool.`;
     await storeInsight(
       docClient,
       Resource.SignageTable.name,
-      DEFAULT_USER_ID,
+      profileUserId(DEFAULT_PROFILE_ID),
       "weekly",
       content,
       undefined, // metrics
@@ -7,6 +7,8 @@ import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
 import { DynamoDBDocumentClient, DeleteCommand, UpdateCommand } from "@aws-sdk/lib-dynamodb";
 import { Resource } from "sst";
 import type { APIGatewayProxyWebsocketHandlerV2 } from "aws-lambda";
+import { deviceIdFor } from "./devices/stats.js";
+import { recordEvent } from "./events/store.js";
 
 const client = new DynamoDBClient({});
 const ddb = DynamoDBDocumentClient.from(client);
@@ -15,14 +17,15 @@ export const handler: APIGatewayProxyWebsocketHandlerV2 = async (event) => {
   const connectionId = event.requestContext.connectionId;
   console.log(`Client disconnected: ${connectionId}`);
 
-  // Remove connection from DynamoDB
-  await ddb.send(
+  // Remove connection from DynamoDB, keeping it to name the device
+  const removed = await ddb.send(
     new DeleteCommand({
       TableName: Resource.SignageTable.name,
       Key: {
         pk: "CONNECTIONS",
         sk: connectionId,
       },
+      ReturnValues: "ALL_OLD",
     })
   );
 
@@ -45,6 +48,17 @@ export const handler: APIGatewayProxyWebsocketHandlerV2 = async (event) => {
     }
   }
 
+  // A connection the compositor already removed as stale was logged offline then
+  if (removed?.Attributes) {
+    await recordEvent({
+      timestamp: Date.now(),
+      category: "device",
+      type: "offline",
+      subject: deviceIdFor(removed.Attributes),
+      detail: connectionId,
+    });
+  }
+
   return {
     statusCode: 200,
     body: "Disconnected",
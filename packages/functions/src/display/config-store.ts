@@ -0,0 +1,111 @@
+/**
+ * Display configuration store
+ * Persists display-wide settings (active layout, layout schedule, transition,
+ * power limit, hidden layers, scene rules, locale, Dexcom request budget,
+ * screen sleep, seconds clock, timezone, ticker symbols, network monitor,
+ * rain forecast location, split layout people) in DynamoDB.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { TransitionType } from "@signage/core";
+import { DEFAULT_LAYOUT_NAME, type LayerName, type LayoutSelection } from "../rendering/layouts.js";
+import type { PowerLimit } from "../rendering/adjustments.js";
+import type { SceneRule } from "../scenes/events.js";
+import type { LocaleName } from "../rendering/locales.js";
+import type { DexcomRateLimit } from "../dexcom/rate-limit.js";
+import type { SleepSchedule } from "./sleep.js";
+import type { TickerSettings } from "../ticker/types.js";
+import type { NetworkMonitorSettings } from "../network/types.js";
+import type { PrecipitationSettings } from "../precipitation/types.js";
+import type { GlucosePerson } from "../followers/types.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** DynamoDB key for the display configuration item */
+const CONFIG_KEY = { pk: "DISPLAY_CONFIG", sk: "SETTINGS" };
+
+/**
+ * Display-wide configuration
+ */
+export interface DisplayConfig extends LayoutSelection {
+  /** Transition animation between minute updates (default: none) */
+  transition?: TransitionType | "none";
+  /** Output power limit applied to every broadcast frame (default: none) */
+  powerLimit?: PowerLimit;
+  /** Compositing layers left out of every frame, for debugging (default: none) */
+  hiddenLayers?: LayerName[];
+  /** Scenes to play on data events; the first matching rule wins (default: none) */
+  sceneRules?: SceneRule[];
+  /** Language of the clock's day and month names (default: en) */
+  locale?: LocaleName;
+  /** Budget for Dexcom calls shared by every run (default: DEFAULT_DEXCOM_RATE_LIMIT) */
+  dexcomRateLimit?: DexcomRateLimit;
+  /** Daily period with the screen off (default: none) */
+  sleepSchedule?: SleepSchedule;
+  /** Nobody home: screen off until cleared (default: false) */
+  away?: boolean;
+  /** Show seconds on the clock, ticking every this many seconds, 1-5 (default: H:MM only) */
+  clockSeconds?: number;
+  /** Show the moon's phase by the clock at night (default: false) */
+  moonPhase?: boolean;
+  /** Smooth the glucose chart's line with anti-aliasing (default: false) */
+  chartAntialias?: boolean;
+  /** Shade the area under the glucose chart's line (default: false) */
+  chartFill?: boolean;
+  /**
+   * IANA timezone for the clock, chart markers, layout and sleep schedules
+   * and alert quiet hours (default: DEFAULT_TIMEZONE)
+   */
+  timezone?: string;
+  /** Symbols for the markets layout's ticker, and whether to draw sparklines (default: none) */
+  ticker?: TickerSettings;
+  /** Host (and optional download) for the network layout (default: none) */
+  networkMonitor?: NetworkMonitorSettings;
+  /** Where to forecast rain for the day layout's nowcast bar (default: none) */
+  precipitation?: PrecipitationSettings;
+  /** Who the split layout shows, with their thresholds and colors (default: everyone, see resolvePeople) */
+  people?: GlucosePerson[];
+}
+
+/** Configuration used before anything has been saved */
+export const DEFAULT_DISPLAY_CONFIG: DisplayConfig = {
+  activeLayout: DEFAULT_LAYOUT_NAME,
+};
+
+/**
+ * Get the display configuration, or defaults if none has been saved.
+ */
+export async function getDisplayConfig(): Promise<DisplayConfig> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: CONFIG_KEY,
+    })
+  );
+
+  if (!result.Item) {
+    return { ...DEFAULT_DISPLAY_CONFIG };
+  }
+
+  const { pk: _pk, sk: _sk, updatedAt: _updatedAt, ...config } = result.Item;
+  return { ...DEFAULT_DISPLAY_CONFIG, ...(config as Partial<DisplayConfig>) };
+}
+
+/**
+ * Save the display configuration.
+ */
+export async function saveDisplayConfig(config: DisplayConfig): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        ...CONFIG_KEY,
+        ...config,
+        updatedAt: new Date().toISOString(),
+      },
+    })
+  );
+}
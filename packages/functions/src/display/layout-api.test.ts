@@ -0,0 +1,433 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockGetConfig, mockSaveConfig } = vi.hoisted(() => ({
+  mockGetConfig: vi.fn(),
+  mockSaveConfig: vi.fn(),
+}));
+
+vi.mock("./config-store.js", () => ({
+  getDisplayConfig: mockGetConfig,
+  saveDisplayConfig: mockSaveConfig,
+}));
+
+vi.mock("../events/store.js", () => ({
+  recordEvent: vi.fn(),
+}));
+
+vi.mock("./lock-store.js", () => ({
+  getDisplayLock: vi.fn().mockResolvedValue(null),
+  getLockStatus: () => ({ locked: false }),
+}));
+
+import {
+  handler,
+  validateSchedule,
+  validatePowerLimit,
+  validateHiddenLayers,
+  validateSceneRules,
+  validateDexcomRateLimit,
+  validatePageRotation,
+} from "./layout-api";
+
+function createEvent(method: string, body?: unknown): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("validateSchedule", () => {
+  it("accepts valid entries", () => {
+    expect(validateSchedule([{ start: "22:00", layout: "night" }])).toBeNull();
+    expect(validateSchedule([])).toBeNull();
+  });
+
+  it("rejects bad times and unknown layouts", () => {
+    expect(validateSchedule([{ start: "25:00", layout: "night" }])).toMatch(/start/);
+    expect(validateSchedule([{ start: "22:00", layout: "party" }])).toMatch(/layout/);
+    expect(validateSchedule("nope")).toMatch(/array/);
+  });
+});
+
+describe("validatePowerLimit", () => {
+  it("accepts either cap, both, or null", () => {
+    expect(validatePowerLimit({ maxChannel: 180 })).toBeNull();
+    expect(validatePowerLimit({ maxTotal: 1_000_000 })).toBeNull();
+    expect(validatePowerLimit({ maxChannel: 180, maxTotal: 1_000_000 })).toBeNull();
+    expect(validatePowerLimit(null)).toBeNull();
+  });
+
+  it("rejects empty, out-of-range, and unknown fields", () => {
+    expect(validatePowerLimit({})).toMatch(/maxChannel and\/or maxTotal/);
+    expect(validatePowerLimit({ maxChannel: 0 })).toMatch(/maxChannel/);
+    expect(validatePowerLimit({ maxTotal: 4_000_000 })).toMatch(/maxTotal/);
+    expect(validatePowerLimit({ maxWatts: 5 })).toMatch(/unknown/);
+    expect(validatePowerLimit(5)).toMatch(/object/);
+  });
+});
+
+describe("validateDexcomRateLimit", () => {
+  it("accepts a budget or null", () => {
+    expect(validateDexcomRateLimit({ capacity: 20, refillPerMinute: 2.5 })).toBeNull();
+    expect(validateDexcomRateLimit(null)).toBeNull();
+  });
+
+  it("rejects budgets too small for a run, zero refill, and unknown fields", () => {
+    expect(validateDexcomRateLimit({ capacity: 1, refillPerMinute: 3 })).toMatch(/capacity/);
+    expect(validateDexcomRateLimit({ capacity: 12, refillPerMinute: 0 })).toMatch(/refillPerMinute/);
+    expect(validateDexcomRateLimit({ capacity: 12, refillPerMinute: 3, burst: 5 })).toMatch(/unknown/);
+    expect(validateDexcomRateLimit([])).toMatch(/object/);
+  });
+});
+
+describe("validateHiddenLayers", () => {
+  it("accepts known layers and an empty list", () => {
+    expect(validateHiddenLayers(["background", "overlays"])).toBeNull();
+    expect(validateHiddenLayers([])).toBeNull();
+  });
+
+  it("rejects unknown layers and non-arrays", () => {
+    expect(validateHiddenLayers(["sparkles"])).toMatch(/unknown layer/);
+    expect(validateHiddenLayers("alerts")).toMatch(/array/);
+  });
+});
+
+describe("validateSceneRules", () => {
+  it("accepts known events and scenes", () => {
+    expect(validateSceneRules([{ event: "backInRange", scene: "sweep" }, { event: "tirRecord", scene: "confetti" }])).toBeNull();
+    expect(validateSceneRules([])).toBeNull();
+  });
+
+  it("rejects unknown events and scenes", () => {
+    expect(validateSceneRules([{ event: "lunch", scene: "sweep" }])).toMatch(/scene event/);
+    expect(validateSceneRules([{ event: "backInRange", scene: "fireworks" }])).toMatch(/unknown scene/);
+    expect(validateSceneRules({ event: "backInRange" })).toMatch(/array/);
+  });
+});
+
+describe("validatePageRotation", () => {
+  it("accepts a rotation, with or without a transition, or null", () => {
+    expect(validatePageRotation({ pages: ["day", "markets"], intervalMinutes: 2 })).toBeNull();
+    expect(validatePageRotation({ pages: ["day", "markets"], intervalMinutes: 60, transition: "fade" })).toBeNull();
+    expect(validatePageRotation(null)).toBeNull();
+  });
+
+  it("rejects single pages, unknown layouts, bad intervals, and unknown fields", () => {
+    expect(validatePageRotation({ pages: ["day"], intervalMinutes: 2 })).toMatch(/at least two/);
+    expect(validatePageRotation({ pages: ["day", "calendar"], intervalMinutes: 2 })).toMatch(/unknown layout/);
+    expect(validatePageRotation({ pages: ["day", "night"], intervalMinutes: 0 })).toMatch(/intervalMinutes/);
+    expect(validatePageRotation({ pages: ["day", "night"], intervalMinutes: 1.5 })).toMatch(/intervalMinutes/);
+    expect(validatePageRotation({ pages: ["day", "night"], intervalMinutes: 2, transition: "spin" })).toMatch(/transition/);
+    expect(validatePageRotation({ pages: ["day", "night"], intervalMinutes: 2, shuffle: true })).toMatch(/unknown/);
+  });
+});
+
+describe("layout API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGetConfig.mockResolvedValue({ activeLayout: "day" });
+    mockSaveConfig.mockResolvedValue(undefined);
+  });
+
+  it("returns current config and available layouts on GET", async () => {
+    const { statusCode, body } = await invoke(createEvent("GET"));
+
+    expect(statusCode).toBe(200);
+    expect(body.activeLayout).toBe("day");
+    expect(body.resolvedLayout).toBe("day");
+    expect(body.availableLayouts).toContain("glucose-focus");
+    expect(body.lock).toEqual({ locked: false });
+    expect(body.availableLayers).toContain("alerts");
+  });
+
+  it("switches the active layout on POST", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { layout: "night" }));
+
+    expect(statusCode).toBe(200);
+    expect(body.resolvedLayout).toBe("night");
+    expect(mockSaveConfig).toHaveBeenCalledWith(
+      expect.objectContaining({ activeLayout: "night", activeLayoutSetAt: expect.any(Number) })
+    );
+  });
+
+  it("stores a schedule on POST", async () => {
+    const schedule = [{ start: "07:00", layout: "day" }, { start: "22:00", layout: "night" }];
+    const { statusCode } = await invoke(createEvent("POST", { schedule }));
+
+    expect(statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenCalledWith(
+      expect.objectContaining({ layoutSchedule: schedule })
+    );
+  });
+
+  it("rejects unknown layouts", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { layout: "party" }));
+
+    expect(statusCode).toBe(400);
+    expect(body.error).toMatch(/Unknown layout/);
+    expect(mockSaveConfig).not.toHaveBeenCalled();
+  });
+
+  it("sets the transition on POST", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { transition: "fade" }));
+
+    expect(statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenCalledWith(expect.objectContaining({ transition: "fade" }));
+  });
+
+  it("rejects unknown transitions", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { transition: "spin" }));
+
+    expect(statusCode).toBe(400);
+    expect(mockSaveConfig).not.toHaveBeenCalled();
+  });
+
+  it("sets and clears the power limit on POST", async () => {
+    const limit = { maxChannel: 200, maxTotal: 1_000_000 };
+    expect((await invoke(createEvent("POST", { powerLimit: limit }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenCalledWith(expect.objectContaining({ powerLimit: limit }));
+
+    mockGetConfig.mockResolvedValue({ activeLayout: "day", powerLimit: limit });
+    await invoke(createEvent("POST", { powerLimit: null }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+  });
+
+  it("rejects invalid power limits", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { powerLimit: { maxChannel: 300 } }));
+
+    expect(statusCode).toBe(400);
+    expect(mockSaveConfig).not.toHaveBeenCalled();
+  });
+
+  it("hides and restores layers on POST", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { hiddenLayers: ["overlays", "overlays"] }));
+    expect(statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenCalledWith(expect.objectContaining({ hiddenLayers: ["overlays"] }));
+
+    mockGetConfig.mockResolvedValue({ activeLayout: "day", hiddenLayers: ["overlays"] });
+    await invoke(createEvent("POST", { hiddenLayers: [] }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+  });
+
+  it("rejects unknown layers", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { hiddenLayers: ["sparkles"] }));
+
+    expect(statusCode).toBe(400);
+    expect(body.availableLayers).toEqual(["background", "widgets", "overlays", "alerts"]);
+    expect(mockSaveConfig).not.toHaveBeenCalled();
+  });
+
+  it("stores scene rules on POST", async () => {
+    const sceneRules = [{ event: "backInRange", scene: "sweep" }];
+    const { statusCode } = await invoke(createEvent("POST", { sceneRules }));
+
+    expect(statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenCalledWith(expect.objectContaining({ sceneRules }));
+  });
+
+  it("sets the clock locale on POST", async () => {
+    expect((await invoke(createEvent("POST", { locale: "de" }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenCalledWith(expect.objectContaining({ locale: "de" }));
+
+    const { statusCode, body } = await invoke(createEvent("POST", { locale: "tlh" }));
+    expect(statusCode).toBe(400);
+    expect(body.availableLocales).toContain("nl");
+  });
+
+  it("sets and clears the seconds clock on POST", async () => {
+    expect((await invoke(createEvent("POST", { clockSeconds: 1 }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenCalledWith(expect.objectContaining({ clockSeconds: 1 }));
+
+    mockGetConfig.mockResolvedValue({ activeLayout: "day", clockSeconds: 1 });
+    await invoke(createEvent("POST", { clockSeconds: null }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+
+    expect((await invoke(createEvent("POST", { clockSeconds: 10 }))).statusCode).toBe(400);
+  });
+
+  it("turns the moon phase on and off on POST", async () => {
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day" });
+    expect((await invoke(createEvent("POST", { moonPhase: true }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day", moonPhase: true });
+
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day", moonPhase: true });
+    await invoke(createEvent("POST", { moonPhase: false }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+
+    expect((await invoke(createEvent("POST", { moonPhase: "yes" }))).statusCode).toBe(400);
+  });
+
+  it("turns the smooth chart line on and off on POST", async () => {
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day" });
+    expect((await invoke(createEvent("POST", { chartAntialias: true }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day", chartAntialias: true });
+
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day", chartAntialias: true });
+    await invoke(createEvent("POST", { chartAntialias: false }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+
+    expect((await invoke(createEvent("POST", { chartAntialias: 1 }))).statusCode).toBe(400);
+  });
+
+  it("turns the chart fill on and off on POST", async () => {
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day" });
+    expect((await invoke(createEvent("POST", { chartFill: true }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day", chartFill: true });
+
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day", chartFill: true });
+    await invoke(createEvent("POST", { chartFill: false }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+
+    expect((await invoke(createEvent("POST", { chartFill: "on" }))).statusCode).toBe(400);
+  });
+
+  it("sets and clears the ticker symbols on POST", async () => {
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day" });
+    const ticker = { symbols: ["AAPL", "coingecko:bitcoin", "AAPL"], sparkline: true };
+    expect((await invoke(createEvent("POST", { ticker }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({
+      activeLayout: "day",
+      ticker: { symbols: ["AAPL", "coingecko:bitcoin"], sparkline: true },
+    });
+
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day", ticker: { symbols: ["AAPL"] } });
+    await invoke(createEvent("POST", { ticker: null }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+
+    expect((await invoke(createEvent("POST", { ticker: { symbols: ["not a symbol"] } }))).statusCode).toBe(400);
+  });
+
+  it("sets and clears the network monitor on POST", async () => {
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day" });
+    const networkMonitor = { host: "example.com", downloadUrl: "https://example.com/1mb.bin" };
+    expect((await invoke(createEvent("POST", { networkMonitor }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day", networkMonitor });
+
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day", networkMonitor: { host: "example.com" } });
+    await invoke(createEvent("POST", { networkMonitor: null }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+
+    expect((await invoke(createEvent("POST", { networkMonitor: { host: "https://example.com" } }))).statusCode).toBe(
+      400
+    );
+  });
+
+  it("sets and clears the rain forecast location on POST", async () => {
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day" });
+    const precipitation = { latitude: 47.61, longitude: -122.33 };
+    expect((await invoke(createEvent("POST", { precipitation }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day", precipitation });
+
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day", precipitation });
+    await invoke(createEvent("POST", { precipitation: null }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+
+    expect((await invoke(createEvent("POST", { precipitation: { latitude: 100, longitude: 0 } }))).statusCode).toBe(
+      400
+    );
+  });
+
+  it("sets and clears the split layout's people on POST", async () => {
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day" });
+    const people = [
+      { id: "primary", name: "ANA" },
+      { id: "max", name: "MAX", low: 80, high: 200, color: { r: 255, g: 0, b: 128 } },
+    ];
+    expect((await invoke(createEvent("POST", { people }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day", people });
+
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day", people });
+    await invoke(createEvent("POST", { people: null }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+
+    expect((await invoke(createEvent("POST", { people: [{ id: "max", name: "MAXIMILIAN" }] }))).statusCode).toBe(400);
+  });
+
+  it("sets and clears the timezone on POST", async () => {
+    expect((await invoke(createEvent("POST", { timezone: "Europe/Berlin" }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenCalledWith(expect.objectContaining({ timezone: "Europe/Berlin" }));
+
+    mockGetConfig.mockResolvedValue({ activeLayout: "day", timezone: "Europe/Berlin" });
+    await invoke(createEvent("POST", { timezone: null }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+
+    expect((await invoke(createEvent("POST", { timezone: "Pacific Time" }))).statusCode).toBe(400);
+  });
+
+  it("merges per-layout clock formats on POST", async () => {
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day", clockFormats: { day: "24h" } });
+    expect((await invoke(createEvent("POST", { clockFormats: { night: "24h" } }))).statusCode).toBe(200);
+    expect(mockSaveConfig).toHaveBeenLastCalledWith(
+      expect.objectContaining({ clockFormats: { day: "24h", night: "24h" } })
+    );
+
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "day", clockFormats: { day: "24h" } });
+    await invoke(createEvent("POST", { clockFormats: { day: null } }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+  });
+
+  it("rejects unknown clock formats and layouts", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { clockFormats: { day: "metric" } }));
+    expect(statusCode).toBe(400);
+    expect(body.availableClockFormats).toEqual(["12h", "12h-ampm", "24h"]);
+
+    expect((await invoke(createEvent("POST", { clockFormats: { kitchen: "24h" } }))).statusCode).toBe(400);
+    expect(mockSaveConfig).not.toHaveBeenCalled();
+  });
+
+  it("starts and stops a page rotation on POST", async () => {
+    const { statusCode, body } = await invoke(
+      createEvent("POST", { pageRotation: { pages: ["markets", "day"], intervalMinutes: 2, transition: "slide" } })
+    );
+    expect(statusCode).toBe(200);
+    expect(body.resolvedLayout).toBe("markets");
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({
+      activeLayout: "day",
+      pageRotation: { pages: ["markets", "day"], intervalMinutes: 2, startedAt: expect.any(Number), transition: "slide" },
+    });
+
+    mockGetConfig.mockResolvedValueOnce({
+      activeLayout: "day",
+      pageRotation: { pages: ["markets", "day"], intervalMinutes: 2, startedAt: 0 },
+      pinnedPage: "day",
+    });
+    await invoke(createEvent("POST", { pageRotation: null }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "day" });
+  });
+
+  it("pins and unpins a rotation page on POST", async () => {
+    const pageRotation = { pages: ["markets", "day"], intervalMinutes: 2, startedAt: Date.now() };
+    mockGetConfig.mockResolvedValue({ activeLayout: "night", pageRotation });
+
+    const { statusCode, body } = await invoke(createEvent("POST", { pinnedPage: "day" }));
+    expect(statusCode).toBe(200);
+    expect(body.resolvedLayout).toBe("day");
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "night", pageRotation, pinnedPage: "day" });
+
+    mockGetConfig.mockResolvedValueOnce({ activeLayout: "night", pageRotation, pinnedPage: "day" });
+    await invoke(createEvent("POST", { pinnedPage: null }));
+    expect(mockSaveConfig).toHaveBeenLastCalledWith({ activeLayout: "night", pageRotation });
+
+    expect((await invoke(createEvent("POST", { pinnedPage: "night" }))).statusCode).toBe(400);
+  });
+
+  it("rejects a pin without a rotation", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { pinnedPage: "day" }));
+
+    expect(statusCode).toBe(400);
+    expect(body.error).toMatch(/No page rotation/);
+    expect(mockSaveConfig).not.toHaveBeenCalled();
+  });
+
+  it("rejects invalid JSON", async () => {
+    const event = { requestContext: { http: { method: "POST" } }, body: "{" } as unknown as APIGatewayProxyEventV2;
+    const { statusCode } = await invoke(event);
+    expect(statusCode).toBe(400);
+  });
+});
@@ -0,0 +1,609 @@
+/**
+ * Layout API
+ *
+ * GET  /layout - current selection, resolved layout, lock state, and available layouts
+ * POST /layout - switch the active layout, replace the schedule, or change display settings
+ *
+ * Body: { "layout": "night", "schedule": [{ "start": "22:00", "layout": "night" }] }
+ * Every field is optional; see the README's Layouts section for the rest.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { SCENE_TYPES, TRANSITION_TYPES, type SceneType, type TransitionType } from "@signage/core";
+import {
+  LAYERS,
+  LAYOUTS,
+  isLayerName,
+  isLayoutName,
+  parseTimeOfDay,
+  resolveLayout,
+  MAX_PAGE_MINUTES,
+  type LayerName,
+  type LayoutScheduleEntry,
+  type PageRotation,
+} from "../rendering/layouts.js";
+import { fullWhiteTotal, type PowerLimit } from "../rendering/adjustments.js";
+import { DISPLAY_WIDTH, DISPLAY_HEIGHT } from "../rendering/text.js";
+import { SCENE_EVENTS, type SceneEvent, type SceneRule } from "../scenes/events.js";
+import { LOCALES, isLocaleName } from "../rendering/locales.js";
+import { MAX_CLOCK_SECONDS, MIN_CLOCK_SECONDS, isClockSeconds } from "../rendering/clock-ticks.js";
+import { CLOCK_FORMATS, isClockFormat, type ClockFormat } from "../rendering/clock-renderer.js";
+import {
+  DEFAULT_DEXCOM_RATE_LIMIT,
+  DEXCOM_CALLS_PER_RUN,
+  MAX_DEXCOM_CAPACITY,
+  MAX_DEXCOM_REFILL_PER_MINUTE,
+  type DexcomRateLimit,
+} from "../dexcom/rate-limit.js";
+import { DEFAULT_TIMEZONE, isTimezone } from "../rendering/zoned-time.js";
+import { getDisplayConfig, saveDisplayConfig } from "./config-store.js";
+import { recordEvent } from "../events/store.js";
+import { configChangedEvent } from "../events/transitions.js";
+import { isScreenOn, validateSleepSchedule, type SleepSchedule } from "./sleep.js";
+import { getDisplayLock, getLockStatus } from "./lock-store.js";
+import { normalizeTickerSettings, validateTickerSettings } from "../ticker/quotes.js";
+import type { TickerSettings } from "../ticker/types.js";
+import { validateNetworkMonitorSettings } from "../network/probe.js";
+import type { NetworkMonitorSettings } from "../network/types.js";
+import { validatePrecipitationSettings } from "../precipitation/nowcast.js";
+import type { PrecipitationSettings } from "../precipitation/types.js";
+import { validatePeople } from "../followers/people.js";
+import type { GlucosePerson } from "../followers/types.js";
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * Validate a schedule from a request body ([] clears it).
+ * Returns an error message, or null if valid.
+ */
+export function validateSchedule(schedule: unknown): string | null {
+  if (!Array.isArray(schedule)) {
+    return "schedule must be an array";
+  }
+  for (const entry of schedule as Partial<LayoutScheduleEntry>[]) {
+    if (typeof entry?.start !== "string" || parseTimeOfDay(entry.start) === null) {
+      return `invalid schedule start: ${JSON.stringify(entry?.start)}`;
+    }
+    if (typeof entry.layout !== "string" || !isLayoutName(entry.layout)) {
+      return `unknown layout in schedule: ${JSON.stringify(entry.layout)}`;
+    }
+  }
+  return null;
+}
+
+/**
+ * Validate a page rotation from a request body (null stops it). It cycles
+ * two or more layouts every intervalMinutes and overrides the schedule.
+ * Returns an error message, or null if valid.
+ */
+export function validatePageRotation(rotation: unknown): string | null {
+  if (rotation === null) return null;
+  if (typeof rotation !== "object" || Array.isArray(rotation)) {
+    return "pageRotation must be an object or null";
+  }
+
+  const { pages, intervalMinutes, transition, ...rest } = rotation as Record<string, unknown>;
+  if (Object.keys(rest).length > 0) {
+    return `unknown pageRotation fields: ${Object.keys(rest).join(", ")}`;
+  }
+  if (!Array.isArray(pages) || pages.length < 2) {
+    return "pages must be an array of at least two layouts";
+  }
+  for (const page of pages) {
+    if (typeof page !== "string" || !isLayoutName(page)) {
+      return `unknown layout in pages: ${JSON.stringify(page)}`;
+    }
+  }
+  if (!Number.isInteger(intervalMinutes) || (intervalMinutes as number) < 1 || (intervalMinutes as number) > MAX_PAGE_MINUTES) {
+    return `intervalMinutes must be an integer from 1 to ${MAX_PAGE_MINUTES}`;
+  }
+  if (transition !== undefined && transition !== "none" && !TRANSITION_TYPES.includes(transition as TransitionType)) {
+    return `Unknown transition: ${JSON.stringify(transition)}`;
+  }
+  return null;
+}
+
+/**
+ * Validate a power limit from a request body (null clears it).
+ * Returns an error message, or null if valid.
+ */
+export function validatePowerLimit(limit: unknown): string | null {
+  if (limit === null) return null;
+  if (typeof limit !== "object" || Array.isArray(limit)) {
+    return "powerLimit must be an object or null";
+  }
+
+  const { maxChannel, maxTotal, ...rest } = limit as Record<string, unknown>;
+  if (Object.keys(rest).length > 0) {
+    return `unknown powerLimit fields: ${Object.keys(rest).join(", ")}`;
+  }
+  if (maxChannel === undefined && maxTotal === undefined) {
+    return "powerLimit needs maxChannel and/or maxTotal";
+  }
+  if (maxChannel !== undefined && (!Number.isInteger(maxChannel) || (maxChannel as number) < 1 || (maxChannel as number) > 255)) {
+    return "maxChannel must be an integer from 1 to 255";
+  }
+  const fullWhite = fullWhiteTotal({ width: DISPLAY_WIDTH, height: DISPLAY_HEIGHT });
+  if (maxTotal !== undefined && (!Number.isInteger(maxTotal) || (maxTotal as number) < 1 || (maxTotal as number) > fullWhite)) {
+    return `maxTotal must be an integer from 1 to ${fullWhite}`;
+  }
+  return null;
+}
+
+/**
+ * Validate a hidden layer list from a request body ([] shows every layer).
+ * Returns an error message, or null if valid.
+ */
+export function validateHiddenLayers(layers: unknown): string | null {
+  if (!Array.isArray(layers)) {
+    return "hiddenLayers must be an array";
+  }
+  for (const layer of layers) {
+    if (typeof layer !== "string" || !isLayerName(layer)) {
+      return `unknown layer: ${JSON.stringify(layer)}`;
+    }
+  }
+  return null;
+}
+
+/**
+ * Validate per-layout clock formats from a request body. They merge into the
+ * stored ones; a null format goes back to the layout's own.
+ * Returns an error message, or null if valid.
+ */
+export function validateClockFormats(formats: unknown): string | null {
+  if (typeof formats !== "object" || formats === null || Array.isArray(formats)) {
+    return "clockFormats must be an object of layout name to format";
+  }
+  for (const [layout, format] of Object.entries(formats)) {
+    if (!isLayoutName(layout)) {
+      return `unknown layout: ${JSON.stringify(layout)}`;
+    }
+    if (format !== null && !isClockFormat(format)) {
+      return `unknown clock format: ${JSON.stringify(format)} (use ${CLOCK_FORMATS.join(", ")})`;
+    }
+  }
+  return null;
+}
+
+/**
+ * Validate scene rules from a request body ([] turns scenes off).
+ * Returns an error message, or null if valid.
+ */
+export function validateSceneRules(rules: unknown): string | null {
+  if (!Array.isArray(rules)) {
+    return "sceneRules must be an array";
+  }
+  for (const rule of rules as Partial<SceneRule>[]) {
+    if (!SCENE_EVENTS.includes(rule?.event as SceneEvent)) {
+      return `unknown scene event: ${JSON.stringify(rule?.event)}`;
+    }
+    if (!SCENE_TYPES.includes(rule.scene as SceneType)) {
+      return `unknown scene: ${JSON.stringify(rule.scene)}`;
+    }
+  }
+  return null;
+}
+
+/**
+ * Validate a Dexcom rate limit from a request body (null restores the default).
+ * Returns an error message, or null if valid.
+ */
+export function validateDexcomRateLimit(limit: unknown): string | null {
+  if (limit === null) return null;
+  if (typeof limit !== "object" || Array.isArray(limit)) {
+    return "dexcomRateLimit must be an object or null";
+  }
+
+  const { capacity, refillPerMinute, ...rest } = limit as Record<string, unknown>;
+  if (Object.keys(rest).length > 0) {
+    return `unknown dexcomRateLimit fields: ${Object.keys(rest).join(", ")}`;
+  }
+  if (
+    !Number.isInteger(capacity) ||
+    (capacity as number) < DEXCOM_CALLS_PER_RUN ||
+    (capacity as number) > MAX_DEXCOM_CAPACITY
+  ) {
+    return `capacity must be an integer from ${DEXCOM_CALLS_PER_RUN} to ${MAX_DEXCOM_CAPACITY}`;
+  }
+  if (
+    typeof refillPerMinute !== "number" ||
+    !Number.isFinite(refillPerMinute) ||
+    refillPerMinute <= 0 ||
+    refillPerMinute > MAX_DEXCOM_REFILL_PER_MINUTE
+  ) {
+    return `refillPerMinute must be above 0 and at most ${MAX_DEXCOM_REFILL_PER_MINUTE}`;
+  }
+  return null;
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  if (method === "GET") {
+    const [config, lock] = await Promise.all([getDisplayConfig(), getDisplayLock()]);
+    return json(200, {
+      ...config,
+      resolvedLayout: resolveLayout(config, Date.now(), config.timezone ?? DEFAULT_TIMEZONE).name,
+      screenOn: isScreenOn(config, Date.now(), config.timezone ?? DEFAULT_TIMEZONE),
+      lock: getLockStatus(lock),
+      availableLayouts: Object.keys(LAYOUTS),
+      availableTransitions: ["none", ...TRANSITION_TYPES],
+      availableLayers: LAYERS,
+      availableScenes: SCENE_TYPES,
+      availableSceneEvents: SCENE_EVENTS,
+      availableLocales: Object.keys(LOCALES),
+      availableClockFormats: CLOCK_FORMATS,
+      defaultDexcomRateLimit: DEFAULT_DEXCOM_RATE_LIMIT,
+    });
+  }
+
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  let body: {
+    layout?: unknown;
+    schedule?: unknown;
+    transition?: unknown;
+    powerLimit?: unknown;
+    hiddenLayers?: unknown;
+    sceneRules?: unknown;
+    locale?: unknown;
+    dexcomRateLimit?: unknown;
+    sleepSchedule?: unknown;
+    away?: unknown;
+    clockSeconds?: unknown;
+    timezone?: unknown;
+    clockFormats?: unknown;
+    moonPhase?: unknown;
+    chartAntialias?: unknown;
+    chartFill?: unknown;
+    ticker?: unknown;
+    networkMonitor?: unknown;
+    precipitation?: unknown;
+    people?: unknown;
+    pageRotation?: unknown;
+    pinnedPage?: unknown;
+  };
+  try {
+    body = JSON.parse(event.body || "{}");
+  } catch {
+    return json(400, { error: "Invalid JSON" });
+  }
+
+  if (
+    body.layout === undefined &&
+    body.schedule === undefined &&
+    body.transition === undefined &&
+    body.powerLimit === undefined &&
+    body.hiddenLayers === undefined &&
+    body.sceneRules === undefined &&
+    body.locale === undefined &&
+    body.dexcomRateLimit === undefined &&
+    body.sleepSchedule === undefined &&
+    body.away === undefined &&
+    body.clockSeconds === undefined &&
+    body.timezone === undefined &&
+    body.clockFormats === undefined &&
+    body.moonPhase === undefined &&
+    body.chartAntialias === undefined &&
+    body.chartFill === undefined &&
+    body.ticker === undefined &&
+    body.networkMonitor === undefined &&
+    body.precipitation === undefined &&
+    body.people === undefined &&
+    body.pageRotation === undefined &&
+    body.pinnedPage === undefined
+  ) {
+    return json(400, {
+      error:
+        "Provide layout, schedule, transition, powerLimit, hiddenLayers, sceneRules, locale, dexcomRateLimit, sleepSchedule, away, " +
+        "clockSeconds, timezone, clockFormats, moonPhase, chartAntialias, chartFill, ticker, networkMonitor, precipitation, " +
+        "people, pageRotation, and/or pinnedPage",
+    });
+  }
+
+  const config = await getDisplayConfig();
+
+  if (body.layout !== undefined) {
+    if (typeof body.layout !== "string" || !isLayoutName(body.layout)) {
+      return json(400, {
+        error: `Unknown layout: ${JSON.stringify(body.layout)}`,
+        availableLayouts: Object.keys(LAYOUTS),
+      });
+    }
+    config.activeLayout = body.layout;
+    config.activeLayoutSetAt = Date.now();
+  }
+
+  if (body.schedule !== undefined) {
+    const error = validateSchedule(body.schedule);
+    if (error) {
+      return json(400, { error });
+    }
+    config.layoutSchedule = body.schedule as LayoutScheduleEntry[];
+  }
+
+  if (body.transition !== undefined) {
+    if (body.transition !== "none" && !TRANSITION_TYPES.includes(body.transition as TransitionType)) {
+      return json(400, { error: `Unknown transition: ${JSON.stringify(body.transition)}` });
+    }
+    config.transition = body.transition as TransitionType | "none";
+  }
+
+  if (body.powerLimit !== undefined) {
+    const error = validatePowerLimit(body.powerLimit);
+    if (error) {
+      return json(400, { error });
+    }
+    if (body.powerLimit === null) {
+      delete config.powerLimit;
+    } else {
+      config.powerLimit = body.powerLimit as PowerLimit;
+    }
+  }
+
+  if (body.hiddenLayers !== undefined) {
+    const error = validateHiddenLayers(body.hiddenLayers);
+    if (error) {
+      return json(400, { error, availableLayers: LAYERS });
+    }
+    const hiddenLayers = [...new Set(body.hiddenLayers as LayerName[])];
+    if (hiddenLayers.length === 0) {
+      delete config.hiddenLayers;
+    } else {
+      config.hiddenLayers = hiddenLayers;
+    }
+  }
+
+  if (body.sceneRules !== undefined) {
+    const error = validateSceneRules(body.sceneRules);
+    if (error) {
+      return json(400, { error });
+    }
+    const rules = (body.sceneRules as SceneRule[]).map(({ event, scene }) => ({ event, scene }));
+    if (rules.length === 0) {
+      delete config.sceneRules;
+    } else {
+      config.sceneRules = rules;
+    }
+  }
+
+  if (body.locale !== undefined) {
+    if (typeof body.locale !== "string" || !isLocaleName(body.locale)) {
+      return json(400, {
+        error: `Unknown locale: ${JSON.stringify(body.locale)}`,
+        availableLocales: Object.keys(LOCALES),
+      });
+    }
+    config.locale = body.locale;
+  }
+
+  if (body.dexcomRateLimit !== undefined) {
+    const error = validateDexcomRateLimit(body.dexcomRateLimit);
+    if (error) {
+      return json(400, { error });
+    }
+    if (body.dexcomRateLimit === null) {
+      delete config.dexcomRateLimit;
+    } else {
+      const { capacity, refillPerMinute } = body.dexcomRateLimit as DexcomRateLimit;
+      config.dexcomRateLimit = { capacity, refillPerMinute };
+    }
+  }
+
+  if (body.sleepSchedule !== undefined) {
+    const error = validateSleepSchedule(body.sleepSchedule);
+    if (error) {
+      return json(400, { error });
+    }
+    if (body.sleepSchedule === null) {
+      delete config.sleepSchedule;
+    } else {
+      const { start, end } = body.sleepSchedule as SleepSchedule;
+      config.sleepSchedule = { start, end };
+    }
+  }
+
+  if (body.away !== undefined) {
+    if (typeof body.away !== "boolean") {
+      return json(400, { error: "away must be true or false" });
+    }
+    if (body.away) {
+      config.away = true;
+    } else {
+      delete config.away;
+    }
+  }
+
+  if (body.moonPhase !== undefined) {
+    if (typeof body.moonPhase !== "boolean") {
+      return json(400, { error: "moonPhase must be true or false" });
+    }
+    if (body.moonPhase) {
+      config.moonPhase = true;
+    } else {
+      delete config.moonPhase;
+    }
+  }
+
+  if (body.chartAntialias !== undefined) {
+    if (typeof body.chartAntialias !== "boolean") {
+      return json(400, { error: "chartAntialias must be true or false" });
+    }
+    if (body.chartAntialias) {
+      config.chartAntialias = true;
+    } else {
+      delete config.chartAntialias;
+    }
+  }
+
+  if (body.chartFill !== undefined) {
+    if (typeof body.chartFill !== "boolean") {
+      return json(400, { error: "chartFill must be true or false" });
+    }
+    if (body.chartFill) {
+      config.chartFill = true;
+    } else {
+      delete config.chartFill;
+    }
+  }
+
+  if (body.clockSeconds !== undefined) {
+    if (body.clockSeconds !== null && !isClockSeconds(body.clockSeconds)) {
+      return json(400, {
+        error: `clockSeconds must be whole seconds from ${MIN_CLOCK_SECONDS} to ${MAX_CLOCK_SECONDS}, or null`,
+      });
+    }
+    if (body.clockSeconds === null) {
+      delete config.clockSeconds;
+    } else {
+      config.clockSeconds = body.clockSeconds;
+    }
+  }
+
+  if (body.timezone !== undefined) {
+    if (body.timezone !== null && (typeof body.timezone !== "string" || !isTimezone(body.timezone))) {
+      return json(400, {
+        error: `Unknown timezone: ${JSON.stringify(body.timezone)} (use an IANA name like "Europe/Berlin")`,
+      });
+    }
+    if (body.timezone === null) {
+      delete config.timezone;
+    } else {
+      config.timezone = body.timezone;
+    }
+  }
+
+  if (body.clockFormats !== undefined) {
+    const error = validateClockFormats(body.clockFormats);
+    if (error) {
+      return json(400, { error, availableClockFormats: CLOCK_FORMATS });
+    }
+    const formats = { ...config.clockFormats };
+    for (const [layout, format] of Object.entries(body.clockFormats as Record<string, ClockFormat | null>)) {
+      if (format === null) {
+        delete formats[layout];
+      } else {
+        formats[layout] = format;
+      }
+    }
+    if (Object.keys(formats).length === 0) {
+      delete config.clockFormats;
+    } else {
+      config.clockFormats = formats;
+    }
+  }
+
+  if (body.ticker !== undefined) {
+    const error = validateTickerSettings(body.ticker);
+    if (error) {
+      return json(400, { error });
+    }
+    if (body.ticker === null) {
+      delete config.ticker;
+    } else {
+      config.ticker = normalizeTickerSettings(body.ticker as TickerSettings);
+    }
+  }
+
+  if (body.networkMonitor !== undefined) {
+    const error = validateNetworkMonitorSettings(body.networkMonitor);
+    if (error) {
+      return json(400, { error });
+    }
+    if (body.networkMonitor === null) {
+      delete config.networkMonitor;
+    } else {
+      const { host, downloadUrl } = body.networkMonitor as NetworkMonitorSettings;
+      config.networkMonitor = downloadUrl ? { host, downloadUrl } : { host };
+    }
+  }
+
+  if (body.precipitation !== undefined) {
+    const error = validatePrecipitationSettings(body.precipitation);
+    if (error) {
+      return json(400, { error });
+    }
+    if (body.precipitation === null) {
+      delete config.precipitation;
+    } else {
+      const { latitude, longitude } = body.precipitation as PrecipitationSettings;
+      config.precipitation = { latitude, longitude };
+    }
+  }
+
+  if (body.people !== undefined) {
+    const error = validatePeople(body.people);
+    if (error) {
+      return json(400, { error });
+    }
+    if (body.people === null) {
+      delete config.people;
+    } else {
+      config.people = (body.people as GlucosePerson[]).map(({ id, name, low, high, color }) => ({
+        id,
+        name: name.trim(),
+        ...(low !== undefined && { low }),
+        ...(high !== undefined && { high }),
+        ...(color !== undefined && { color: { r: color.r, g: color.g, b: color.b } }),
+      }));
+    }
+  }
+
+  if (body.pageRotation !== undefined) {
+    const error = validatePageRotation(body.pageRotation);
+    if (error) {
+      return json(400, { error });
+    }
+    if (body.pageRotation === null) {
+      delete config.pageRotation;
+      delete config.pinnedPage;
+    } else {
+      const { pages, intervalMinutes, transition } = body.pageRotation as PageRotation;
+      config.pageRotation = {
+        pages,
+        intervalMinutes,
+        startedAt: Date.now(),
+        ...(transition !== undefined && { transition }),
+      };
+      // A pin only holds on a page the new rotation still has
+      if (config.pinnedPage !== undefined && !pages.includes(config.pinnedPage)) {
+        delete config.pinnedPage;
+      }
+    }
+  }
+
+  if (body.pinnedPage !== undefined) {
+    if (body.pinnedPage === null) {
+      delete config.pinnedPage;
+    } else if (!config.pageRotation) {
+      return json(400, { error: "No page rotation to pin: set pageRotation first" });
+    } else if (typeof body.pinnedPage !== "string" || !config.pageRotation.pages.includes(body.pinnedPage)) {
+      return json(400, {
+        error: `pinnedPage must be one of the rotation's pages: ${config.pageRotation.pages.join(", ")}`,
+      });
+    } else {
+      config.pinnedPage = body.pinnedPage;
+    }
+  }
+
+  await saveDisplayConfig(config);
+  await recordEvent(configChangedEvent("display", body));
+  console.log(`Layout config updated: active=${config.activeLayout}, schedule=${config.layoutSchedule?.length ?? 0} entries`);
+
+  return json(200, {
+    ...config,
+    resolvedLayout: resolveLayout(config, Date.now(), config.timezone ?? DEFAULT_TIMEZONE).name,
+  });
+};
@@ -0,0 +1,122 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockGetLock, mockLockFrame, mockClearLock } = vi.hoisted(() => ({
+  mockGetLock: vi.fn(),
+  mockLockFrame: vi.fn(),
+  mockClearLock: vi.fn(),
+}));
+
+vi.mock("./lock-store.js", async (importOriginal) => {
+  const actual = await importOriginal<typeof import("./lock-store.js")>();
+  return {
+    getLockStatus: actual.getLockStatus,
+    getDisplayLock: mockGetLock,
+    lockCurrentFrame: mockLockFrame,
+    clearDisplayLock: mockClearLock,
+  };
+});
+
+vi.mock("sst", () => ({
+  Resource: { SignageTable: { name: "test-table" } },
+}));
+
+import { handler, parseLockRequest } from "./lock-api";
+
+function createEvent(method: string, body?: unknown): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("parseLockRequest", () => {
+  it("defaults to 15 minutes", () => {
+    expect(parseLockRequest({})).toEqual({ minutes: 15 });
+  });
+
+  it("keeps a trimmed reason", () => {
+    expect(parseLockRequest({ minutes: 5, reason: " photo " })).toEqual({ minutes: 5, reason: "photo" });
+  });
+
+  it("rejects bad durations and reasons", () => {
+    expect(parseLockRequest({ minutes: 0 })).toMatch(/positive/);
+    expect(parseLockRequest({ minutes: 500 })).toMatch(/at most/);
+    expect(parseLockRequest({ reason: 42 })).toMatch(/reason/);
+  });
+});
+
+describe("lock API handler", () => {
+  const now = Date.now();
+  const lock = {
+    frameData: "AAAA",
+    width: 64,
+    height: 64,
+    lockedAt: now,
+    expiresAt: now + 10 * 60 * 1000,
+    reason: "demo",
+  };
+
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGetLock.mockResolvedValue(null);
+    mockLockFrame.mockResolvedValue(lock);
+    mockClearLock.mockResolvedValue(undefined);
+  });
+
+  it("reports unlocked on GET when there is no lock", async () => {
+    const { statusCode, body } = await invoke(createEvent("GET"));
+
+    expect(statusCode).toBe(200);
+    expect(body).toEqual({ locked: false });
+  });
+
+  it("reports the lock on GET", async () => {
+    mockGetLock.mockResolvedValue(lock);
+    const { body } = await invoke(createEvent("GET"));
+
+    expect(body.locked).toBe(true);
+    expect(body.reason).toBe("demo");
+    expect(body.remainingMinutes).toBe(10);
+  });
+
+  it("locks the current frame on POST", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { minutes: 10, reason: "demo" }));
+
+    expect(statusCode).toBe(200);
+    expect(mockLockFrame).toHaveBeenCalledWith(10 * 60 * 1000, "demo");
+    expect(body.locked).toBe(true);
+  });
+
+  it("returns 409 when there is no frame to lock", async () => {
+    mockLockFrame.mockResolvedValue(null);
+    const { statusCode } = await invoke(createEvent("POST", {}));
+
+    expect(statusCode).toBe(409);
+  });
+
+  it("rejects invalid requests", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { minutes: -1 }));
+
+    expect(statusCode).toBe(400);
+    expect(mockLockFrame).not.toHaveBeenCalled();
+  });
+
+  it("releases the lock on DELETE", async () => {
+    const { statusCode, body } = await invoke(createEvent("DELETE"));
+
+    expect(statusCode).toBe(200);
+    expect(mockClearLock).toHaveBeenCalled();
+    expect(body.locked).toBe(false);
+  });
+
+  it("rejects other methods", async () => {
+    const { statusCode } = await invoke(createEvent("PUT"));
+    expect(statusCode).toBe(405);
+  });
+});
@@ -0,0 +1,91 @@
+/**
+ * Display Lock API
+ *
+ * GET    /lock - current lock state
+ * POST   /lock - freeze the display on the current frame
+ * DELETE /lock - release the lock
+ *
+ * Body: { "minutes": 15, "reason": "photo" }
+ * `minutes` is optional (default 15, max 240). While locked, layout schedules
+ * and non-urgent alerts don't change the display; urgent alerts still show.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { clearDisplayLock, getDisplayLock, getLockStatus, lockCurrentFrame } from "./lock-store.js";
+
+const DEFAULT_LOCK_MINUTES = 15;
+const MAX_LOCK_MINUTES = 240;
+const MAX_REASON_LENGTH = 100;
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * Validate a lock request body.
+ * Returns the lock duration and reason, or an error message.
+ */
+export function parseLockRequest(body: {
+  minutes?: unknown;
+  reason?: unknown;
+}): { minutes: number; reason?: string } | string {
+  const minutes = body.minutes ?? DEFAULT_LOCK_MINUTES;
+  if (typeof minutes !== "number" || !Number.isFinite(minutes) || minutes <= 0) {
+    return "minutes must be a positive number";
+  }
+  if (minutes > MAX_LOCK_MINUTES) {
+    return `minutes must be at most ${MAX_LOCK_MINUTES}`;
+  }
+
+  if (body.reason !== undefined && typeof body.reason !== "string") {
+    return "reason must be a string";
+  }
+  const reason = body.reason?.trim().slice(0, MAX_REASON_LENGTH);
+
+  return { minutes, ...(reason && { reason }) };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  if (method === "GET") {
+    return json(200, getLockStatus(await getDisplayLock()));
+  }
+
+  if (method === "DELETE") {
+    await clearDisplayLock();
+    console.log("Display lock released");
+    return json(200, { locked: false });
+  }
+
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  let body: { minutes?: unknown; reason?: unknown };
+  try {
+    body = JSON.parse(event.body || "{}");
+  } catch {
+    return json(400, { error: "Invalid JSON" });
+  }
+
+  const request = parseLockRequest(body);
+  if (typeof request === "string") {
+    return json(400, { error: request });
+  }
+
+  const lock = await lockCurrentFrame(request.minutes * 60 * 1000, request.reason);
+  if (!lock) {
+    return json(409, { error: "No frame has been displayed yet" });
+  }
+
+  console.log(`Display locked for ${request.minutes}m${request.reason ? ` (${request.reason})` : ""}`);
+  return json(200, getLockStatus(lock));
+};
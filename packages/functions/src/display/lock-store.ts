@@ -0,0 +1,140 @@
+/**
+ * Display lock store
+ * A lock freezes the display on the frame that was showing when it was taken,
+ * e.g. for a photo or a demo. Only urgent alerts break through.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, DeleteCommand, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const LOCK_KEY = { pk: "DISPLAY_LOCK", sk: "CURRENT" };
+const FRAME_CACHE_KEY = { pk: "FRAME_CACHE", sk: "LATEST" };
+
+/**
+ * An active display lock
+ */
+export interface DisplayLock {
+  /** Base64 frame held on the display */
+  frameData: string;
+  width: number;
+  height: number;
+  lockedAt: number;
+  expiresAt: number;
+  reason?: string;
+}
+
+/**
+ * Lock state as reported by status endpoints
+ */
+export interface DisplayLockStatus {
+  locked: boolean;
+  lockedAt?: string;
+  expiresAt?: string;
+  remainingMinutes?: number;
+  reason?: string;
+}
+
+/**
+ * Whether a lock is still in effect.
+ */
+export function isLockActive(lock: DisplayLock | null, now: number = Date.now()): lock is DisplayLock {
+  return lock !== null && lock.expiresAt > now;
+}
+
+/**
+ * Describe a lock for API responses.
+ */
+export function getLockStatus(lock: DisplayLock | null, now: number = Date.now()): DisplayLockStatus {
+  if (!isLockActive(lock, now)) {
+    return { locked: false };
+  }
+  return {
+    locked: true,
+    lockedAt: new Date(lock.lockedAt).toISOString(),
+    expiresAt: new Date(lock.expiresAt).toISOString(),
+    remainingMinutes: Math.ceil((lock.expiresAt - now) / 60000),
+    ...(lock.reason && { reason: lock.reason }),
+  };
+}
+
+/**
+ * Get the current lock, or null if there is none or it has expired.
+ * DynamoDB TTL deletion lags, so expiry is checked here too.
+ */
+export async function getDisplayLock(now: number = Date.now()): Promise<DisplayLock | null> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: LOCK_KEY,
+    })
+  );
+
+  const item = result.Item;
+  if (!item) return null;
+
+  const lock: DisplayLock = {
+    frameData: item.frameData as string,
+    width: item.width as number,
+    height: item.height as number,
+    lockedAt: item.lockedAt as number,
+    expiresAt: item.expiresAt as number,
+    ...(item.reason && { reason: item.reason as string }),
+  };
+  return isLockActive(lock, now) ? lock : null;
+}
+
+/**
+ * Lock the display on the most recently broadcast frame.
+ * Returns null if no frame has been broadcast yet.
+ */
+export async function lockCurrentFrame(
+  durationMs: number,
+  reason?: string,
+  now: number = Date.now()
+): Promise<DisplayLock | null> {
+  const cached = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: FRAME_CACHE_KEY,
+    })
+  );
+  if (!cached.Item?.frameData) return null;
+
+  const lock: DisplayLock = {
+    frameData: cached.Item.frameData as string,
+    width: cached.Item.width as number,
+    height: cached.Item.height as number,
+    lockedAt: now,
+    expiresAt: now + durationMs,
+    ...(reason && { reason }),
+  };
+
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        ...LOCK_KEY,
+        ...lock,
+        ttl: Math.ceil(lock.expiresAt / 1000),
+      },
+    })
+  );
+
+  return lock;
+}
+
+/**
+ * Release the display lock.
+ */
+export async function clearDisplayLock(): Promise<void> {
+  await ddb.send(
+    new DeleteCommand({
+      TableName: Resource.SignageTable.name,
+      Key: LOCK_KEY,
+    })
+  );
+}
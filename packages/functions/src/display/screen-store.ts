@@ -0,0 +1,40 @@
+/**
+ * Screen state store
+ * Remembers whether the screens were last told to be on or off, so the
+ * on/off command is only sent when that changes.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** DynamoDB key for the screen state */
+const SCREEN_KEY = { pk: "DISPLAY_SCREEN", sk: "STATE" };
+
+/**
+ * Get the last screen state sent (screens start out on)
+ */
+export async function getScreenOn(): Promise<boolean> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: SCREEN_KEY,
+    })
+  );
+  return result.Item?.on !== false;
+}
+
+/**
+ * Save the screen state just sent
+ */
+export async function saveScreenOn(on: boolean): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: { ...SCREEN_KEY, on, updatedAt: new Date().toISOString() },
+    })
+  );
+}
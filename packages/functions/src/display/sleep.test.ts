@@ -0,0 +1,48 @@
+import { describe, it, expect } from "vitest";
+import { isInSleepSchedule, isScreenOn, validateSleepSchedule } from "./sleep";
+
+const TZ = "UTC";
+const at = (hours: number, minutes: number) => Date.UTC(2026, 0, 15, hours, minutes);
+const overnight = { start: "23:00", end: "06:30" };
+
+describe("isInSleepSchedule", () => {
+  it("covers the start but not the end, across midnight", () => {
+    expect(isInSleepSchedule(overnight, at(23, 0), TZ)).toBe(true);
+    expect(isInSleepSchedule(overnight, at(3, 0), TZ)).toBe(true);
+    expect(isInSleepSchedule(overnight, at(6, 30), TZ)).toBe(false);
+    expect(isInSleepSchedule(overnight, at(22, 59), TZ)).toBe(false);
+  });
+
+  it("handles a daytime schedule", () => {
+    const workday = { start: "09:00", end: "17:00" };
+    expect(isInSleepSchedule(workday, at(12, 0), TZ)).toBe(true);
+    expect(isInSleepSchedule(workday, at(18, 0), TZ)).toBe(false);
+  });
+});
+
+describe("isScreenOn", () => {
+  it("turns the screen off during the schedule or while away", () => {
+    expect(isScreenOn({ sleepSchedule: overnight }, at(3, 0), TZ)).toBe(false);
+    expect(isScreenOn({ sleepSchedule: overnight }, at(12, 0), TZ)).toBe(true);
+    expect(isScreenOn({ away: true }, at(12, 0), TZ)).toBe(false);
+    expect(isScreenOn({}, at(3, 0), TZ)).toBe(true);
+  });
+
+  it("wakes the screen for an urgent alert", () => {
+    expect(isScreenOn({ sleepSchedule: overnight, away: true }, at(3, 0), TZ, true)).toBe(true);
+  });
+});
+
+describe("validateSleepSchedule", () => {
+  it("accepts a schedule or null", () => {
+    expect(validateSleepSchedule(overnight)).toBeNull();
+    expect(validateSleepSchedule(null)).toBeNull();
+  });
+
+  it("rejects malformed schedules", () => {
+    expect(validateSleepSchedule("23:00-06:00")).toMatch(/object/);
+    expect(validateSleepSchedule({ start: "25:00", end: "06:00" })).toMatch(/HH:MM/);
+    expect(validateSleepSchedule({ start: "06:00", end: "06:00" })).toMatch(/differ/);
+    expect(validateSleepSchedule({ ...overnight, days: [] })).toMatch(/unknown/);
+  });
+});
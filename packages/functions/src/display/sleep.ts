@@ -0,0 +1,68 @@
+/**
+ * Screen sleep
+ *
+ * The display turns its screen off overnight (a daily sleep schedule) or
+ * while nobody is home (the "away" flag, set by hand or by a home
+ * automation hook). Urgent alerts still wake it.
+ */
+
+import { parseTimeOfDay, minutesOfDay } from "../rendering/layouts.js";
+
+/** Daily period with the screen off, in display local time */
+export interface SleepSchedule {
+  /** "HH:MM" 24h, screen turns off */
+  start: string;
+  /** "HH:MM" 24h, screen turns back on (may be past midnight) */
+  end: string;
+}
+
+/**
+ * Validate a sleep schedule from a request body (null clears it).
+ * Returns an error message, or null if valid.
+ */
+export function validateSleepSchedule(value: unknown): string | null {
+  if (value === null) return null;
+  if (typeof value !== "object" || Array.isArray(value)) {
+    return "sleepSchedule must be an object or null";
+  }
+  const { start, end, ...rest } = value as Record<string, unknown>;
+  if (Object.keys(rest).length > 0) {
+    return `unknown sleepSchedule fields: ${Object.keys(rest).join(", ")}`;
+  }
+  const startMinutes = typeof start === "string" ? parseTimeOfDay(start) : null;
+  const endMinutes = typeof end === "string" ? parseTimeOfDay(end) : null;
+  if (startMinutes === null || endMinutes === null) {
+    return 'sleepSchedule start and end must be "HH:MM" times';
+  }
+  if (startMinutes === endMinutes) {
+    return "sleepSchedule start and end must differ";
+  }
+  return null;
+}
+
+/**
+ * Whether a timestamp falls inside the sleep schedule (start inclusive, end exclusive)
+ */
+export function isInSleepSchedule(schedule: SleepSchedule, now: number, timezone: string): boolean {
+  const start = parseTimeOfDay(schedule.start);
+  const end = parseTimeOfDay(schedule.end);
+  if (start === null || end === null) return false;
+
+  const length = (end - start + 24 * 60) % (24 * 60);
+  const sinceStart = (minutesOfDay(now, timezone) - start + 24 * 60) % (24 * 60);
+  return sinceStart < length;
+}
+
+/**
+ * Whether the screen should be on
+ */
+export function isScreenOn(
+  config: { sleepSchedule?: SleepSchedule; away?: boolean },
+  now: number,
+  timezone: string,
+  urgentAlert: boolean = false
+): boolean {
+  if (urgentAlert) return true;
+  if (config.away) return false;
+  return !(config.sleepSchedule && isInSleepSchedule(config.sleepSchedule, now, timezone));
+}
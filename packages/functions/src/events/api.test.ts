@@ -0,0 +1,67 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockQueryEvents } = vi.hoisted(() => ({
+  mockQueryEvents: vi.fn(),
+}));
+
+vi.mock("./store.js", () => ({
+  queryEvents: mockQueryEvents,
+}));
+
+import { handler, parseEventsQuery } from "./api";
+
+const NOW = Date.UTC(2026, 9, 16, 12);
+
+function createEvent(query?: Record<string, string>): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method: "GET" } },
+    queryStringParameters: query,
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("parseEventsQuery", () => {
+  it("defaults to the last day, every category, 200 events", () => {
+    expect(parseEventsQuery(undefined, NOW)).toEqual({ since: NOW - 24 * 3600000, limit: 200 });
+  });
+
+  it("rejects an unknown category and out-of-range limits", () => {
+    expect(parseEventsQuery({ category: "weather" }, NOW)).toMatch(/alert, device, config, buzzer/);
+    expect(parseEventsQuery({ limit: "0" }, NOW)).toMatch(/1 to 1000/);
+    expect(parseEventsQuery({ since: "soon" }, NOW)).toMatch(/duration/);
+  });
+});
+
+describe("handler", () => {
+  beforeEach(() => {
+    mockQueryEvents.mockReset();
+  });
+
+  it("returns events with readable times", async () => {
+    const timestamp = Date.UTC(2026, 9, 16, 3, 2);
+    mockQueryEvents.mockResolvedValue([{ timestamp, category: "buzzer", type: "sounded", subject: "pomodoro" }]);
+
+    const { statusCode, body } = await invoke(createEvent({ category: "buzzer", since: "12h" }));
+
+    expect(statusCode).toBe(200);
+    expect(mockQueryEvents).toHaveBeenCalledWith(expect.any(Number), expect.any(Number), {
+      category: "buzzer",
+      limit: 200,
+    });
+    expect(body.events).toEqual([
+      { timestamp, time: "2026-10-16T03:02:00.000Z", category: "buzzer", type: "sounded", subject: "pomodoro" },
+    ]);
+  });
+
+  it("returns 400 for a bad query", async () => {
+    const { statusCode } = await invoke(createEvent({ limit: "5000" }));
+
+    expect(statusCode).toBe(400);
+    expect(mockQueryEvents).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,70 @@
+/**
+ * Event Log API
+ *
+ * GET /events?since=24h&category=alert&limit=100
+ *
+ * Alerts fired and cleared, devices online and offline, settings changes and
+ * buzzer sounds, newest first. `since` is a duration ("24h", "7d") or an ISO
+ * date (default 24h; events are kept 30 days). `category` is one of alert,
+ * device, config, buzzer (default: all). `limit` is 1-1000 (default 200).
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { parseSince } from "../export/format.js";
+import { queryEvents } from "./store.js";
+import { EVENT_CATEGORIES, type EventCategory } from "./types.js";
+
+const DEFAULT_SINCE = "24h";
+const DEFAULT_LIMIT = 200;
+const MAX_LIMIT = 1000;
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * Validate the query string.
+ * Returns the start time, category and limit, or an error message.
+ */
+export function parseEventsQuery(
+  query: Record<string, string | undefined> | undefined,
+  now: number = Date.now()
+): { since: number; category?: EventCategory; limit: number } | string {
+  const since = parseSince(query?.since ?? DEFAULT_SINCE, now);
+  if (typeof since === "string") {
+    return since;
+  }
+
+  const category = query?.category;
+  if (category !== undefined && !(EVENT_CATEGORIES as readonly string[]).includes(category)) {
+    return `category must be one of: ${EVENT_CATEGORIES.join(", ")}`;
+  }
+
+  const limit = Number(query?.limit ?? DEFAULT_LIMIT);
+  if (!Number.isInteger(limit) || limit < 1 || limit > MAX_LIMIT) {
+    return `limit must be a whole number from 1 to ${MAX_LIMIT}`;
+  }
+
+  return { since, ...(category ? { category: category as EventCategory } : {}), limit };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const now = Date.now();
+  const parsed = parseEventsQuery(event.queryStringParameters, now);
+  if (typeof parsed === "string") {
+    return json(400, { error: parsed });
+  }
+
+  const events = await queryEvents(parsed.since, now, { category: parsed.category, limit: parsed.limit });
+  return json(200, {
+    since: new Date(parsed.since).toISOString(),
+    events: events.map((e) => ({ ...e, time: new Date(e.timestamp).toISOString() })),
+  });
+};
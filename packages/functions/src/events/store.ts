@@ -0,0 +1,118 @@
+/**
+ * Event log store
+ * Alerts fired and cleared, devices coming online and going offline,
+ * settings changes and buzzer sounds, newest first, kept for 30 days.
+ *
+ * Recording is best-effort: a failed write is logged and never fails the
+ * caller, since the log is for troubleshooting and not worth a dropped frame.
+ */
+
+import { randomUUID } from "crypto";
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand, QueryCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { EventCategory, LoggedEvent } from "./types.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const EVENTS_PK = "EVENTS";
+
+/** Alert types active on the compositor's last run */
+const ALERT_STATE_KEY = { pk: "EVENT_STATE", sk: "ALERTS" };
+
+/** How long events are kept */
+export const EVENT_RETENTION_DAYS = 30;
+
+function sortKey(timestamp: number): string {
+  return `TS#${new Date(timestamp).toISOString()}#${randomUUID().slice(0, 8)}`;
+}
+
+/**
+ * Record events. Errors are logged, not thrown.
+ */
+export async function recordEvents(events: LoggedEvent[]): Promise<void> {
+  try {
+    await Promise.all(
+      events.map((event) =>
+        ddb.send(
+          new PutCommand({
+            TableName: Resource.SignageTable.name,
+            Item: {
+              pk: EVENTS_PK,
+              sk: sortKey(event.timestamp),
+              ...event,
+              ttl: Math.floor(event.timestamp / 1000) + EVENT_RETENTION_DAYS * 24 * 60 * 60,
+            },
+          })
+        )
+      )
+    );
+  } catch (error) {
+    console.error("Failed to record events:", error);
+  }
+}
+
+/**
+ * Record one event. Errors are logged, not thrown.
+ */
+export async function recordEvent(event: LoggedEvent): Promise<void> {
+  await recordEvents([event]);
+}
+
+/**
+ * Get events in a time range, newest first
+ * @param options.category Only events of this category
+ * @param options.limit Most events to return
+ */
+export async function queryEvents(
+  since: number,
+  until: number = Date.now(),
+  options: { category?: EventCategory; limit?: number } = {}
+): Promise<LoggedEvent[]> {
+  const result = await ddb.send(
+    new QueryCommand({
+      TableName: Resource.SignageTable.name,
+      KeyConditionExpression: "pk = :pk AND sk BETWEEN :since AND :until",
+      ExpressionAttributeValues: {
+        ":pk": EVENTS_PK,
+        ":since": `TS#${new Date(since).toISOString()}`,
+        // "~" sorts after any suffix at the same instant
+        ":until": `TS#${new Date(until).toISOString()}~`,
+      },
+      ScanIndexForward: false,
+    })
+  );
+
+  // Filtered here rather than with a FilterExpression, which would apply after a Limit
+  return (result.Items || [])
+    .filter((item) => !options.category || item.category === options.category)
+    .slice(0, options.limit)
+    .map((item) => ({
+      timestamp: item.timestamp as number,
+      category: item.category as EventCategory,
+      type: item.type as string,
+      ...(item.subject !== undefined ? { subject: item.subject as string } : {}),
+      ...(item.detail !== undefined ? { detail: item.detail as string } : {}),
+    }));
+}
+
+/**
+ * Alert types that were active on the compositor's last run
+ */
+export async function getActiveAlertTypes(): Promise<string[]> {
+  const result = await ddb.send(new GetCommand({ TableName: Resource.SignageTable.name, Key: ALERT_STATE_KEY }));
+  return (result.Item?.types as string[] | undefined) ?? [];
+}
+
+/**
+ * Save the alert types active on this run
+ */
+export async function saveActiveAlertTypes(types: string[]): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: { ...ALERT_STATE_KEY, types },
+    })
+  );
+}
@@ -0,0 +1,40 @@
+import { describe, it, expect } from "vitest";
+import { alertEvents, configChangedEvent } from "./transitions";
+import type { GlucoseAlert } from "../alerts/types";
+
+const NOW = Date.UTC(2026, 9, 16, 10);
+
+function alert(type: GlucoseAlert["type"], detail: string): GlucoseAlert {
+  return { type, severity: "warning", title: "HIGH", detail, raisedAt: NOW };
+}
+
+describe("alertEvents", () => {
+  it("logs alerts that weren't active last run as fired", () => {
+    expect(alertEvents([], [alert("high", "262")], NOW)).toEqual([
+      { timestamp: NOW, category: "alert", type: "fired", subject: "high", detail: "HIGH 262" },
+    ]);
+  });
+
+  it("logs alerts that went away as cleared", () => {
+    expect(alertEvents(["high", "risingFast"], [alert("high", "270")], NOW)).toEqual([
+      { timestamp: NOW, category: "alert", type: "cleared", subject: "risingFast" },
+    ]);
+  });
+
+  it("logs nothing while the same alerts stay active", () => {
+    expect(alertEvents(["high"], [alert("high", "280")], NOW)).toEqual([]);
+  });
+});
+
+describe("configChangedEvent", () => {
+  it("keeps the change as detail, shortened", () => {
+    expect(configChangedEvent("devices", { deviceId: "kitchen", rotation: 90 }, NOW)).toEqual({
+      timestamp: NOW,
+      category: "config",
+      type: "changed",
+      subject: "devices",
+      detail: '{"deviceId":"kitchen","rotation":90}',
+    });
+    expect(configChangedEvent("display", { text: "x".repeat(500) }, NOW).detail).toHaveLength(200);
+  });
+});
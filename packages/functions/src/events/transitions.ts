@@ -0,0 +1,45 @@
+/**
+ * Turn the compositor's per-minute alert list into fired/cleared events
+ */
+
+import type { GlucoseAlert } from "../alerts/types.js";
+import type { LoggedEvent } from "./types.js";
+
+/**
+ * Events for alerts that became active or went away since the last run
+ * @param previous Alert types active on the last run
+ * @param alerts Alerts active now (after suppression and snoozes)
+ */
+export function alertEvents(previous: string[], alerts: GlucoseAlert[], now: number = Date.now()): LoggedEvent[] {
+  const active = new Set(alerts.map((alert) => alert.type as string));
+  const fired: LoggedEvent[] = alerts
+    .filter((alert) => !previous.includes(alert.type))
+    .map((alert) => ({
+      timestamp: now,
+      category: "alert",
+      type: "fired",
+      subject: alert.type,
+      detail: `${alert.title} ${alert.detail}`.trim(),
+    }));
+  const cleared: LoggedEvent[] = previous
+    .filter((type) => !active.has(type))
+    .map((type) => ({ timestamp: now, category: "alert", type: "cleared", subject: type }));
+  return [...fired, ...cleared];
+}
+
+/** Longest detail kept for a config change */
+const MAX_DETAIL_LENGTH = 200;
+
+/**
+ * Event for a settings change, with the request that made it as detail
+ */
+export function configChangedEvent(area: string, change: unknown, now: number = Date.now()): LoggedEvent {
+  const detail = JSON.stringify(change) ?? "";
+  return {
+    timestamp: now,
+    category: "config",
+    type: "changed",
+    subject: area,
+    detail: detail.length > MAX_DETAIL_LENGTH ? `${detail.slice(0, MAX_DETAIL_LENGTH - 3)}...` : detail,
+  };
+}
@@ -0,0 +1,26 @@
+/**
+ * Event log types
+ */
+
+/** What an event is about */
+export const EVENT_CATEGORIES = ["alert", "device", "config", "buzzer"] as const;
+
+export type EventCategory = (typeof EVENT_CATEGORIES)[number];
+
+/**
+ * Something that happened, kept for troubleshooting
+ * ("why did it buzz at 3am?")
+ *
+ * - alert: `fired` / `cleared`, subject is the alert type
+ * - device: `online` / `offline`, subject is the device ID
+ * - config: `changed`, subject is the settings area (alerts, devices, display, widgets)
+ * - buzzer: `sounded`, subject is what sounded it (pomodoro)
+ */
+export interface LoggedEvent {
+  timestamp: number;
+  category: EventCategory;
+  type: string;
+  subject?: string;
+  /** Short human-readable detail */
+  detail?: string;
+}
@@ -0,0 +1,84 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockLoadCgm, mockQueryHistory } = vi.hoisted(() => ({
+  mockLoadCgm: vi.fn(),
+  mockQueryHistory: vi.fn(),
+}));
+
+vi.mock("./store.js", () => ({
+  loadCgmReadings: mockLoadCgm,
+}));
+
+vi.mock("../widgets/history-store.js", () => ({
+  queryHistory: mockQueryHistory,
+}));
+
+import { handler, parseExportQuery } from "./api";
+import { DEFAULT_PROFILE_ID } from "../profiles/profile.js";
+
+const NOW = Date.UTC(2026, 9, 16, 12);
+
+function createEvent(query?: Record<string, string>): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method: "GET" } },
+    queryStringParameters: query,
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  return (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+}
+
+describe("parseExportQuery", () => {
+  it("defaults to 30 days of CSV", () => {
+    expect(parseExportQuery({ widget: "blood-sugar" }, NOW)).toEqual({
+      widget: "blood-sugar",
+      since: NOW - 30 * 24 * 60 * 60 * 1000,
+      format: "csv",
+    });
+  });
+
+  it("rejects a missing widget and unknown formats", () => {
+    expect(parseExportQuery({}, NOW)).toMatch(/widget/);
+    expect(parseExportQuery({ widget: "blood-sugar", format: "xlsx" }, NOW)).toMatch(/csv, json/);
+    expect(parseExportQuery({ widget: "blood-sugar", since: "1y" }, NOW)).toMatch(/duration/);
+  });
+});
+
+describe("handler", () => {
+  beforeEach(() => {
+    mockLoadCgm.mockReset();
+    mockQueryHistory.mockReset();
+  });
+
+  it("exports CGM readings as a CSV download", async () => {
+    mockLoadCgm.mockResolvedValue([{ type: "cgm", timestamp: NOW, glucoseMgDl: 120, importedAt: NOW }]);
+
+    const result = await invoke(createEvent({ widget: "blood-sugar", since: "7d" }));
+
+    expect(result.statusCode).toBe(200);
+    expect(result.headers?.["Content-Type"]).toMatch(/text\/csv/);
+    expect(result.headers?.["Content-Disposition"]).toMatch(/attachment; filename="blood-sugar-\d{4}-\d{2}-\d{2}\.csv"/);
+    expect(result.body).toBe("time,glucose_mg_dl,glucose_mmol_l\n2026-10-16T12:00:00.000Z,120,6.7\n");
+    expect(mockLoadCgm).toHaveBeenCalledWith(expect.any(Number), expect.any(Number), DEFAULT_PROFILE_ID);
+    expect(mockQueryHistory).not.toHaveBeenCalled();
+  });
+
+  it("exports another widget's history as JSON", async () => {
+    mockQueryHistory.mockResolvedValue([{ timestamp: NOW, value: { latencyMs: 21 } }]);
+
+    const result = await invoke(createEvent({ widget: "network", since: "3h", format: "json" }));
+    const body = JSON.parse(result.body as string);
+
+    expect(mockQueryHistory).toHaveBeenCalledWith("network", expect.any(Number), expect.any(Number), DEFAULT_PROFILE_ID);
+    expect(body.widget).toBe("network");
+    expect(body.rows).toEqual([{ time: "2026-10-16T12:00:00.000Z", latencyMs: 21 }]);
+  });
+
+  it("returns 400 for a bad query or profile", async () => {
+    expect((await invoke(createEvent({ since: "7d" }))).statusCode).toBe(400);
+    expect((await invoke(createEvent({ widget: "blood-sugar", profile: "Not Valid" }))).statusCode).toBe(400);
+    expect(mockLoadCgm).not.toHaveBeenCalled();
+  });
+});
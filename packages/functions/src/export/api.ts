@@ -0,0 +1,106 @@
+/**
+ * Export API
+ *
+ * GET /export?widget=blood-sugar&since=30d&format=csv
+ *
+ * `widget=blood-sugar` exports the stored CGM readings (kept long-term, in
+ * mg/dL and mmol/L). Any other widget ID (`network`, `ticker-AAPL`) exports
+ * that widget's stored history, which only reaches back its retention.
+ * `since` is a duration ("30d", "12h") or an ISO date, at most 90 days back;
+ * default 30d. `format` is csv (default) or json.
+ * Add `?profile=<id>` to export another household's readings.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { parseProfileParam } from "../profiles/profile.js";
+import { queryHistory } from "../widgets/history-store.js";
+import { loadCgmReadings } from "./store.js";
+import {
+  cgmRows,
+  EXPORT_FORMATS,
+  GLUCOSE_EXPORT_NAMES,
+  historyRows,
+  isExportFormat,
+  parseSince,
+  toCsv,
+  type ExportFormat,
+} from "./format.js";
+
+const DEFAULT_SINCE = "30d";
+const MAX_WIDGET_LENGTH = 64;
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * Validate the query string.
+ * Returns the widget, start time and format, or an error message.
+ */
+export function parseExportQuery(
+  query: Record<string, string | undefined> | undefined,
+  now: number = Date.now()
+): { widget: string; since: number; format: ExportFormat } | string {
+  const widget = query?.widget?.trim();
+  if (!widget) {
+    return "widget is required, e.g. widget=blood-sugar";
+  }
+  if (widget.length > MAX_WIDGET_LENGTH) {
+    return `widget must be at most ${MAX_WIDGET_LENGTH} characters`;
+  }
+
+  const since = parseSince(query?.since ?? DEFAULT_SINCE, now);
+  if (typeof since === "string") {
+    return since;
+  }
+
+  const format = query?.format ?? "csv";
+  if (!isExportFormat(format)) {
+    return `format must be one of: ${EXPORT_FORMATS.join(", ")}`;
+  }
+
+  return { widget, since, format };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const profile = parseProfileParam(event.queryStringParameters);
+  if (typeof profile !== "string") {
+    return json(400, profile);
+  }
+
+  const now = Date.now();
+  const parsed = parseExportQuery(event.queryStringParameters, now);
+  if (typeof parsed === "string") {
+    return json(400, { error: parsed });
+  }
+
+  const { widget, since, format } = parsed;
+  const rows = GLUCOSE_EXPORT_NAMES.includes(widget)
+    ? cgmRows(await loadCgmReadings(since, now, profile))
+    : historyRows(await queryHistory(widget, since, now, profile));
+
+  if (format === "json") {
+    return json(200, {
+      widget,
+      since: new Date(since).toISOString(),
+      until: new Date(now).toISOString(),
+      rows,
+    });
+  }
+
+  const filename = `${widget}-${new Date(now).toISOString().slice(0, 10)}.csv`.replace(/[^\w.-]/g, "_");
+  return {
+    statusCode: 200,
+    headers: {
+      "Content-Type": "text/csv; charset=utf-8",
+      "Content-Disposition": `attachment; filename="${filename}"`,
+    },
+    body: toCsv(rows),
+  };
+};
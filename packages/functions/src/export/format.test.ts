@@ -0,0 +1,62 @@
+import { describe, it, expect } from "vitest";
+import { cgmRows, historyRows, parseSince, toCsv } from "./format";
+
+const NOW = Date.UTC(2026, 9, 16, 12);
+const DAY = 24 * 60 * 60 * 1000;
+
+describe("parseSince", () => {
+  it("reads durations and ISO dates", () => {
+    expect(parseSince("30d", NOW)).toBe(NOW - 30 * DAY);
+    expect(parseSince("12h", NOW)).toBe(NOW - 12 * 60 * 60 * 1000);
+    expect(parseSince("2026-10-01", NOW)).toBe(Date.UTC(2026, 9, 1));
+  });
+
+  it("rejects nonsense, the future, and more than 90 days", () => {
+    expect(parseSince("a month", NOW)).toMatch(/duration/);
+    expect(parseSince("2026-11-01", NOW)).toMatch(/past/);
+    expect(parseSince("91d", NOW)).toMatch(/90 days/);
+  });
+});
+
+describe("cgmRows", () => {
+  it("orders readings oldest first, in both units", () => {
+    const readings = [
+      { type: "cgm" as const, timestamp: NOW, glucoseMgDl: 180, importedAt: NOW },
+      { type: "cgm" as const, timestamp: NOW - 300000, glucoseMgDl: 100, importedAt: NOW },
+    ];
+
+    expect(cgmRows(readings)).toEqual([
+      { time: "2026-10-16T11:55:00.000Z", glucose_mg_dl: 100, glucose_mmol_l: 5.5 },
+      { time: "2026-10-16T12:00:00.000Z", glucose_mg_dl: 180, glucose_mmol_l: 10 },
+    ]);
+  });
+});
+
+describe("historyRows", () => {
+  it("makes a column of each field and writes nested values as JSON", () => {
+    const rows = historyRows([
+      { timestamp: NOW, value: { latencyMs: 21, hosts: { a: 1 } } },
+      { timestamp: NOW - 60000, value: 3 },
+    ]);
+
+    expect(rows).toEqual([
+      { time: "2026-10-16T11:59:00.000Z", value: 3 },
+      { time: "2026-10-16T12:00:00.000Z", latencyMs: 21, hosts: '{"a":1}' },
+    ]);
+  });
+});
+
+describe("toCsv", () => {
+  it("writes a header of every column and quotes where needed", () => {
+    const csv = toCsv([
+      { time: "t1", glucose: 100 },
+      { time: "t2", note: 'said "hi", left' },
+    ]);
+
+    expect(csv).toBe('time,glucose,note\nt1,100,\nt2,,"said ""hi"", left"\n');
+  });
+
+  it("writes just the header for no rows", () => {
+    expect(toCsv([])).toBe("time\n");
+  });
+});
@@ -0,0 +1,98 @@
+/**
+ * Export formatting: stored readings as CSV or JSON rows
+ */
+
+import type { CgmReading } from "@diabetes/core";
+import type { TimeSeriesPoint } from "../widgets/types.js";
+
+export type ExportFormat = "csv" | "json";
+
+export const EXPORT_FORMATS: readonly ExportFormat[] = ["csv", "json"];
+
+/** Furthest back an export reaches */
+export const MAX_EXPORT_DAYS = 90;
+
+/** Widget names that export the long-term CGM records */
+export const GLUCOSE_EXPORT_NAMES = ["blood-sugar", "bloodsugar"];
+
+/** One exported row: the time first, then the reading's fields */
+export type ExportRow = { time: string } & Record<string, string | number | boolean | null>;
+
+const MGDL_PER_MMOL = 18.0182;
+
+export function isExportFormat(value: unknown): value is ExportFormat {
+  return EXPORT_FORMATS.includes(value as ExportFormat);
+}
+
+/**
+ * Parse `since`: a duration back from now ("30d", "12h") or an ISO date.
+ * Returns the timestamp, or an error message.
+ */
+export function parseSince(value: string, now: number = Date.now()): number | string {
+  const duration = /^(\d+)([dh])$/.exec(value.trim());
+  const since = duration
+    ? now - Number(duration[1]) * (duration[2] === "d" ? 24 : 1) * 60 * 60 * 1000
+    : Date.parse(value);
+  if (!Number.isFinite(since)) {
+    return 'since must be a duration like "30d" or "12h", or an ISO date';
+  }
+  if (since >= now) {
+    return "since must be in the past";
+  }
+  if (since < now - MAX_EXPORT_DAYS * 24 * 60 * 60 * 1000) {
+    return `since can be at most ${MAX_EXPORT_DAYS} days ago`;
+  }
+  return since;
+}
+
+/**
+ * CGM records as rows, oldest first, in both units
+ */
+export function cgmRows(readings: CgmReading[]): ExportRow[] {
+  return [...readings]
+    .sort((a, b) => a.timestamp - b.timestamp)
+    .map((r) => ({
+      time: new Date(r.timestamp).toISOString(),
+      glucose_mg_dl: r.glucoseMgDl,
+      glucose_mmol_l: Math.round((r.glucoseMgDl / MGDL_PER_MMOL) * 10) / 10,
+    }));
+}
+
+/**
+ * Widget history points as rows, oldest first. Each field of an object value
+ * becomes a column; nested values are written as JSON.
+ */
+export function historyRows(points: TimeSeriesPoint[]): ExportRow[] {
+  return [...points]
+    .sort((a, b) => a.timestamp - b.timestamp)
+    .map((p) => {
+      const row: ExportRow = { time: new Date(p.timestamp).toISOString() };
+      const fields = typeof p.value === "object" && p.value !== null ? p.value : { value: p.value };
+      for (const [key, value] of Object.entries(fields)) {
+        row[key] =
+          value === null || ["string", "number", "boolean"].includes(typeof value)
+            ? (value as string | number | boolean | null)
+            : JSON.stringify(value);
+      }
+      return row;
+    });
+}
+
+function csvField(value: string | number | boolean | null | undefined): string {
+  if (value === null || value === undefined) return "";
+  const text = String(value);
+  return /[",\r\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+}
+
+/**
+ * Rows as CSV with a header line. Columns are every field any row has, in
+ * the order they first appear.
+ */
+export function toCsv(rows: ExportRow[]): string {
+  const columns = [...new Set(["time", ...rows.flatMap((row) => Object.keys(row))])];
+  const lines = [columns.join(",")];
+  for (const row of rows) {
+    lines.push(columns.map((column) => csvField(row[column])).join(","));
+  }
+  return lines.join("\n") + "\n";
+}
@@ -0,0 +1,49 @@
+/**
+ * Export reads: long-term CGM records and widget history
+ */
+
+import { Resource } from "sst";
+import { createDocClient, queryByTypeAndDateRange, formatDateInTimezone } from "@diabetes/core";
+import type { CgmReading } from "@diabetes/core";
+import type { DynamoDBDocumentClient } from "@aws-sdk/lib-dynamodb";
+import { profileUserId } from "../profiles/profile.js";
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+let docClient: DynamoDBDocumentClient | null = null;
+
+function getDocClient(): DynamoDBDocumentClient {
+  if (!docClient) {
+    docClient = createDocClient();
+  }
+  return docClient;
+}
+
+/**
+ * Load a profile's CGM readings between two times, one day per query so
+ * each stays well under DynamoDB's 1MB page.
+ */
+export async function loadCgmReadings(since: number, until: number, profile: string): Promise<CgmReading[]> {
+  const dates = new Set<string>();
+  for (let ts = since; ts < until + DAY_MS; ts += DAY_MS) {
+    dates.add(formatDateInTimezone(Math.min(ts, until)));
+  }
+
+  const readings: CgmReading[] = [];
+  for (const date of dates) {
+    const records = await queryByTypeAndDateRange(
+      getDocClient(),
+      Resource.SignageTable.name,
+      profileUserId(profile),
+      "cgm",
+      date,
+      date
+    );
+    for (const record of records) {
+      if (record.type === "cgm" && record.timestamp >= since && record.timestamp <= until) {
+        readings.push(record);
+      }
+    }
+  }
+  return readings;
+}
@@ -0,0 +1,69 @@
+import { describe, it, expect, vi } from "vitest";
+import {
+  classifyPersonRange,
+  parseFollowerAccounts,
+  PRIMARY_PERSON_ID,
+  resolvePeople,
+  validatePeople,
+} from "./people";
+
+const max = { id: "max", username: "max@example.com", password: "secret" };
+
+describe("parseFollowerAccounts", () => {
+  it("reads a JSON list of accounts, empty for none", () => {
+    expect(parseFollowerAccounts(JSON.stringify([max]))).toEqual([max]);
+    expect(parseFollowerAccounts("")).toEqual([]);
+  });
+
+  it("drops malformed entries and ignores a malformed secret", () => {
+    vi.spyOn(console, "error").mockImplementation(() => {});
+
+    expect(parseFollowerAccounts(JSON.stringify([max, { id: "primary", username: "a", password: "b" }]))).toEqual([max]);
+    expect(parseFollowerAccounts("{not json")).toEqual([]);
+  });
+});
+
+describe("validatePeople", () => {
+  it("accepts names, thresholds and colors", () => {
+    expect(validatePeople([{ id: "primary", name: "ANA" }, { id: "max", name: "MAX", low: 80, high: 200, color: { r: 1, g: 2, b: 3 } }])).toBeNull();
+    expect(validatePeople(null)).toBeNull();
+  });
+
+  it("rejects too many people, long names, and crossed thresholds", () => {
+    const person = (id: string) => ({ id, name: id.toUpperCase() });
+
+    expect(validatePeople(["a", "b", "c", "d"].map(person))).toMatch(/1 to 3/);
+    expect(validatePeople([{ id: "max", name: "MAXIMILIAN" }])).toMatch(/name/);
+    expect(validatePeople([{ id: "max", name: "MAX", low: 200, high: 150 }])).toMatch(/low below high/);
+    expect(validatePeople([person("max"), person("max")])).toMatch(/twice/);
+    expect(validatePeople([{ id: "max", name: "MAX", color: { r: 300, g: 0, b: 0 } }])).toMatch(/color/);
+  });
+});
+
+describe("resolvePeople", () => {
+  it("defaults to the main account then each follower", () => {
+    expect(resolvePeople(undefined, [max])).toEqual([
+      { id: PRIMARY_PERSON_ID, name: "ME" },
+      { id: "max", name: "MAX" },
+    ]);
+  });
+
+  it("keeps the configured order and leaves out followers without a login", () => {
+    const people = [{ id: "max", name: "MAX" }, { id: "zoe", name: "ZOE" }, { id: "primary", name: "ANA" }];
+
+    expect(resolvePeople(people, [max]).map((p) => p.id)).toEqual(["max", "primary"]);
+  });
+});
+
+describe("classifyPersonRange", () => {
+  it("uses each person's own low and high", () => {
+    expect(classifyPersonRange(75, 80, 200)).toBe("low");
+    expect(classifyPersonRange(190, 80, 200)).toBe("normal");
+    expect(classifyPersonRange(190, 70, 180)).toBe("high");
+    expect(classifyPersonRange(260, 70, 180)).toBe("veryHigh");
+  });
+
+  it("keeps urgent low the same for everyone", () => {
+    expect(classifyPersonRange(50, 60, 180)).toBe("urgentLow");
+  });
+});
@@ -0,0 +1,132 @@
+/**
+ * People on the split layout
+ *
+ * The main Dexcom account plus any follower accounts in the DexcomFollowers
+ * secret, e.g. two kids' Dexcom follows. Logins stay in the secret; names,
+ * thresholds and colors are display config ("people"), since they're
+ * changed from the API and aren't secret.
+ */
+
+import type { RGB } from "@signage/core";
+import type { RangeStatus } from "../rendering/colors.js";
+import { URGENT_LOW_MGDL } from "../alerts/engine.js";
+import type { FollowerAccount, GlucosePerson } from "./types.js";
+
+/** Person ID for the main Dexcom account */
+export const PRIMARY_PERSON_ID = "primary";
+
+/** Columns that still fit a reading and a chart on 64 pixels */
+export const MAX_PEOPLE = 3;
+
+/** Longest name that fits over the narrowest column */
+export const MAX_PERSON_NAME_LENGTH = 5;
+
+export const DEFAULT_LOW_MGDL = 70;
+export const DEFAULT_HIGH_MGDL = 180;
+
+/** Label colors by position, for people without their own */
+export const PERSON_COLORS: RGB[] = [
+  { r: 120, g: 180, b: 255 },
+  { r: 255, g: 140, b: 200 },
+  { r: 200, g: 160, b: 255 },
+];
+
+const ID_PATTERN = /^[a-z0-9][a-z0-9-]{0,31}$/;
+
+function isChannel(value: unknown): boolean {
+  return typeof value === "number" && Number.isInteger(value) && value >= 0 && value <= 255;
+}
+
+/**
+ * Parse the DexcomFollowers secret: a JSON list of { id, username, password }.
+ * An empty secret means no followers; a malformed one is logged and ignored,
+ * so the main reading still shows.
+ */
+export function parseFollowerAccounts(raw: string): FollowerAccount[] {
+  if (raw.trim() === "") return [];
+  try {
+    const parsed = JSON.parse(raw) as unknown;
+    if (!Array.isArray(parsed)) throw new Error("not a list");
+    const accounts = parsed.filter(
+      (a): a is FollowerAccount =>
+        typeof a === "object" && a !== null &&
+        typeof a.id === "string" && ID_PATTERN.test(a.id) && a.id !== PRIMARY_PERSON_ID &&
+        typeof a.username === "string" && a.username !== "" &&
+        typeof a.password === "string" && a.password !== ""
+    );
+    if (accounts.length < parsed.length) {
+      console.error(`Ignoring ${parsed.length - accounts.length} malformed DexcomFollowers entries`);
+    }
+    return accounts.map(({ id, username, password }) => ({ id, username, password }));
+  } catch (error) {
+    console.error("DexcomFollowers is not a JSON list of accounts:", error);
+    return [];
+  }
+}
+
+/**
+ * Validate the people list from a request body (null clears it).
+ * Returns an error message, or null if valid.
+ */
+export function validatePeople(value: unknown): string | null {
+  if (value === null) return null;
+  if (!Array.isArray(value) || value.length === 0 || value.length > MAX_PEOPLE) {
+    return `people must be a list of 1 to ${MAX_PEOPLE} people, or null`;
+  }
+  const ids = new Set<string>();
+  for (const person of value) {
+    if (typeof person !== "object" || person === null) return "each person must be an object";
+    const { id, name, low, high, color, ...rest } = person as Record<string, unknown>;
+    if (Object.keys(rest).length > 0) return `unknown person fields: ${Object.keys(rest).join(", ")}`;
+    if (typeof id !== "string" || !ID_PATTERN.test(id)) {
+      return `person id must be "${PRIMARY_PERSON_ID}" or a DexcomFollowers id`;
+    }
+    if (ids.has(id)) return `${id} is listed twice`;
+    ids.add(id);
+    if (typeof name !== "string" || name.trim() === "" || name.length > MAX_PERSON_NAME_LENGTH) {
+      return `${id}.name must be 1 to ${MAX_PERSON_NAME_LENGTH} characters`;
+    }
+    const lowValue = low ?? DEFAULT_LOW_MGDL;
+    const highValue = high ?? DEFAULT_HIGH_MGDL;
+    if (typeof lowValue !== "number" || typeof highValue !== "number" ||
+        lowValue < URGENT_LOW_MGDL || highValue > 400 || lowValue >= highValue) {
+      return `${id} needs low below high, from ${URGENT_LOW_MGDL} to 400 mg/dL`;
+    }
+    if (color !== undefined) {
+      const { r, g, b } = (color ?? {}) as Record<string, unknown>;
+      if (!isChannel(r) || !isChannel(g) || !isChannel(b)) {
+        return `${id}.color must be { r, g, b } with 0-255 channels`;
+      }
+    }
+  }
+  return null;
+}
+
+/**
+ * The people to show: the configured list, leaving out followers with no
+ * login, or else the main account then every follower, named by ID
+ */
+export function resolvePeople(
+  people: GlucosePerson[] | undefined,
+  accounts: FollowerAccount[]
+): GlucosePerson[] {
+  const known = new Set([PRIMARY_PERSON_ID, ...accounts.map((a) => a.id)]);
+  if (people) return people.filter((person) => known.has(person.id));
+
+  return [
+    { id: PRIMARY_PERSON_ID, name: "ME" },
+    ...accounts.map((a) => ({ id: a.id, name: a.id.slice(0, MAX_PERSON_NAME_LENGTH).toUpperCase() })),
+  ].slice(0, MAX_PEOPLE);
+}
+
+/**
+ * Classify a reading against a person's own thresholds. Urgent low stays
+ * at the alert threshold for everyone; very high is 70 over their high.
+ */
+export function classifyPersonRange(glucose: number, low: number, high: number): RangeStatus {
+  if (glucose < URGENT_LOW_MGDL) return "urgentLow";
+  if (glucose < low) return "low";
+  if (glucose <= high) return "normal";
+  if (glucose <= high + 70) return "high";
+  return "veryHigh";
+}
@@ -0,0 +1,46 @@
+/**
+ * Dexcom follower types
+ */
+
+import type { RGB } from "@signage/core";
+import type { BloodSugarDisplayData } from "../rendering/blood-sugar-renderer.js";
+import type { ChartPoint } from "../rendering/chart-renderer.js";
+
+/**
+ * Login for another Dexcom Share account, from the DexcomFollowers secret
+ */
+export interface FollowerAccount {
+  /** Short ID the display config refers to it by */
+  id: string;
+  username: string;
+  password: string;
+}
+
+/**
+ * How one person is shown in the split layout, stored in the display config
+ */
+export interface GlucosePerson {
+  /** "primary" for the main Dexcom account, otherwise a follower account ID */
+  id: string;
+  /** Label over their column */
+  name: string;
+  /** Below this reads as low (mg/dL, default: 70) */
+  low?: number;
+  /** Above this reads as high (mg/dL, default: 180) */
+  high?: number;
+  /** Label color (default: one of PERSON_COLORS by position) */
+  color?: RGB;
+}
+
+/**
+ * One person's reading and recent history, as the split layout draws it
+ */
+export interface PersonGlucoseData {
+  name: string;
+  low: number;
+  high: number;
+  color: RGB;
+  reading: BloodSugarDisplayData | null;
+  /** Recent readings, oldest first */
+  history: ChartPoint[];
+}
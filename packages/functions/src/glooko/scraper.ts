@@ -19,6 +19,7 @@ import type {
   GlookoScraperResult,
   ExtractedCsv,
 } from "./types.js";
+import { DEFAULT_PROFILE_ID, profileUserId } from "../profiles/profile.js";
 
 // Debug flags - opt-in only to prevent PHI exposure
 const DEBUG_SCREENSHOTS = process.env.DEBUG_SCREENSHOTS === "true";
@@ -1409,10 +1410,6 @@ interface GlookoResource {
   SignageTable: { name: string };
 }
 
-// Default user ID for single-user system
-// Must match the user ID used in treatments.ts, compositor.ts, and other query code
-const DEFAULT_USER_ID = "john";
-
 /**
  * Lambda handler for scheduled scraping
  *
@@ -1451,7 +1448,7 @@ export async function handler(): Promise<{ statusCode: number; body: string }> {
     const { randomUUID } = await import("crypto");
 
     // Create storage instance
-    const storage = new GlookoStorage(tableName, DEFAULT_USER_ID);
+    const storage = new GlookoStorage(tableName, profileUserId(DEFAULT_PROFILE_ID));
 
     // Use the CSV files returned from scrapeGlooko (single browser session)
     const csvFiles = result.csvFiles;
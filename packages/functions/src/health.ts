@@ -1,18 +0,0 @@
-/**
- * Health check endpoint
- */
-
-import type { APIGatewayProxyHandlerV2 } from "aws-lambda";
-
-export const handler: APIGatewayProxyHandlerV2 = async () => {
-  return {
-    statusCode: 200,
-    headers: {
-      "Content-Type": "application/json",
-    },
-    body: JSON.stringify({
-      status: "healthy",
-      timestamp: new Date().toISOString(),
-    }),
-  };
-};
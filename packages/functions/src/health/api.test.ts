@@ -0,0 +1,73 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockProbe, mockLatest, mockBackoff, mockStatus } = vi.hoisted(() => ({
+  mockProbe: vi.fn(),
+  mockLatest: vi.fn(),
+  mockBackoff: vi.fn(),
+  mockStatus: vi.fn(),
+}));
+
+vi.mock("./store.js", () => ({
+  probeStorage: mockProbe,
+  getLatestReadingTime: mockLatest,
+}));
+
+vi.mock("../dexcom/backoff-store.js", () => ({
+  getDexcomBackoff: mockBackoff,
+}));
+
+vi.mock("../status/store.js", () => ({
+  getCompositorStatus: mockStatus,
+}));
+
+import { handler } from "./api";
+
+async function invoke() {
+  return (await handler({} as APIGatewayProxyEventV2, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+}
+
+describe("health API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockProbe.mockResolvedValue("write and read in 12ms");
+    mockLatest.mockResolvedValue(Date.now());
+    mockBackoff.mockResolvedValue(null);
+    mockStatus.mockResolvedValue({
+      updatedAt: Date.now(),
+      connections: 1,
+      broadcast: { success: 1, failed: 0, cleaned: 0 },
+    });
+  });
+
+  it("reports healthy with every check", async () => {
+    const result = await invoke();
+    const body = JSON.parse(result.body as string);
+
+    expect(result.statusCode).toBe(200);
+    expect(body.status).toBe("healthy");
+    expect(body.checks.map((c: { name: string }) => c.name)).toEqual(["storage", "glucose", "dexcom", "devices"]);
+    expect(typeof body.timestamp).toBe("string");
+  });
+
+  it("answers 503 when storage is not writable", async () => {
+    mockProbe.mockRejectedValue(new Error("AccessDenied"));
+
+    const result = await invoke();
+
+    expect(result.statusCode).toBe(503);
+    expect(JSON.parse(result.body as string).checks[0]).toEqual({
+      name: "storage",
+      status: "fail",
+      detail: "AccessDenied",
+    });
+  });
+
+  it("fails a check whose state can't be read", async () => {
+    mockStatus.mockRejectedValue(new Error("throttled"));
+
+    const body = JSON.parse((await invoke()).body as string);
+
+    expect(body.checks[3]).toEqual({ name: "devices", status: "fail", detail: "could not read state: throttled" });
+  });
+});
@@ -0,0 +1,103 @@
+import { describe, it, expect } from "vitest";
+import {
+  checkDevices,
+  checkDexcomAuth,
+  checkGlucoseFreshness,
+  checkProbe,
+  healthStatusCode,
+  summarizeHealth,
+} from "./checks";
+
+const now = 1_700_000_000_000;
+const MINUTE = 60 * 1000;
+
+describe("summarizeHealth", () => {
+  it("takes the worst check", () => {
+    const pass = { name: "a", status: "pass" as const, detail: "" };
+    const warn = { name: "b", status: "warn" as const, detail: "" };
+    const fail = { name: "c", status: "fail" as const, detail: "" };
+
+    expect(summarizeHealth([pass], now).status).toBe("healthy");
+    expect(summarizeHealth([pass, warn], now).status).toBe("degraded");
+    expect(summarizeHealth([warn, fail, pass], now).status).toBe("unhealthy");
+    expect(summarizeHealth([pass], now).checkedAt).toBe(now);
+  });
+
+  it("answers 503 only when unhealthy", () => {
+    expect(healthStatusCode({ status: "healthy", checkedAt: now, checks: [] })).toBe(200);
+    expect(healthStatusCode({ status: "degraded", checkedAt: now, checks: [] })).toBe(200);
+    expect(healthStatusCode({ status: "unhealthy", checkedAt: now, checks: [] })).toBe(503);
+  });
+});
+
+describe("checkGlucoseFreshness", () => {
+  it("grades by reading age", () => {
+    expect(checkGlucoseFreshness(now - 4 * MINUTE, now)).toEqual({
+      name: "glucose",
+      status: "pass",
+      detail: "latest reading 4m old",
+    });
+    expect(checkGlucoseFreshness(now - 20 * MINUTE, now).status).toBe("warn");
+    expect(checkGlucoseFreshness(now - 90 * MINUTE, now).status).toBe("fail");
+  });
+
+  it("fails without a reading", () => {
+    expect(checkGlucoseFreshness(null, now).status).toBe("fail");
+  });
+});
+
+describe("checkDexcomAuth", () => {
+  it("passes without a backoff", () => {
+    expect(checkDexcomAuth(null).status).toBe("pass");
+  });
+
+  it("fails on bad credentials and warns on other errors", () => {
+    expect(checkDexcomAuth({ failures: 3, retryAt: now, lastError: "Dexcom auth failed: 500" })).toEqual({
+      name: "dexcom",
+      status: "fail",
+      detail: "3 failed runs: Dexcom auth failed: 500",
+    });
+    expect(checkDexcomAuth({ failures: 1, retryAt: now, lastError: "fetch failed" }).status).toBe("warn");
+  });
+});
+
+describe("checkDevices", () => {
+  it("fails when the compositor stopped", () => {
+    expect(checkDevices(null, now).status).toBe("fail");
+    expect(checkDevices({ updatedAt: now - 10 * MINUTE, connections: 1 }, now)).toMatchObject({
+      status: "fail",
+      detail: "compositor last ran 10m ago",
+    });
+  });
+
+  it("warns when nothing is connected", () => {
+    expect(checkDevices({ updatedAt: now, skipped: true }, now).status).toBe("warn");
+  });
+
+  it("grades by broadcast results", () => {
+    const status = (success: number, failed: number) => ({
+      updatedAt: now,
+      connections: success + failed,
+      broadcast: { success, failed, cleaned: 0 },
+    });
+
+    expect(checkDevices(status(2, 0), now)).toEqual({ name: "devices", status: "pass", detail: "2 of 2 reached" });
+    expect(checkDevices(status(1, 1), now)).toMatchObject({ status: "warn", detail: "1 of 2 reached, 1 failed" });
+    expect(checkDevices(status(0, 2), now)).toMatchObject({ status: "fail", detail: "all 2 sends failed" });
+  });
+});
+
+describe("checkProbe", () => {
+  it("passes with the probe's detail and fails when it throws", async () => {
+    expect(await checkProbe("storage", async () => "writable")).toEqual({
+      name: "storage",
+      status: "pass",
+      detail: "writable",
+    });
+    expect(
+      await checkProbe("storage", async () => {
+        throw new Error("AccessDenied");
+      })
+    ).toEqual({ name: "storage", status: "fail", detail: "AccessDenied" });
+  });
+});
@@ -0,0 +1,137 @@
+/**
+ * Health checks
+ *
+ * Each check looks at one thing the display depends on and grades it
+ * pass / warn / fail; the report's status is the worst of them. Shared by
+ * the deployed /health endpoint and the local server's /healthz, so a
+ * monitor can treat both the same way:
+ *
+ *   { "status": "healthy" | "degraded" | "unhealthy", "checkedAt": ms,
+ *     "checks": [{ "name": "storage", "status": "pass", "detail": "..." }] }
+ *
+ * Degraded still answers 200: the display is up, just working from
+ * cached data or with a device missing. Unhealthy answers 503.
+ */
+
+import type { DexcomBackoffState } from "../dexcom/backoff.js";
+import type { CompositorStatus } from "../status/types.js";
+
+export type CheckStatus = "pass" | "warn" | "fail";
+
+export type HealthStatus = "healthy" | "degraded" | "unhealthy";
+
+export interface HealthCheck {
+  name: string;
+  status: CheckStatus;
+  detail: string;
+}
+
+export interface HealthReport {
+  status: HealthStatus;
+  checkedAt: number;
+  checks: HealthCheck[];
+}
+
+/** Glucose readings arrive every 5 minutes; a couple of missed ones is a warning */
+export const GLUCOSE_WARN_AGE_MS = 15 * 60 * 1000;
+/** An hour without a reading means the pipeline is broken */
+export const GLUCOSE_FAIL_AGE_MS = 60 * 60 * 1000;
+/** The compositor runs every minute; older status means it stopped */
+export const COMPOSITOR_STALE_MS = 5 * 60 * 1000;
+
+function minutes(ms: number): string {
+  return `${Math.round(ms / 60_000)}m`;
+}
+
+/**
+ * Combine checks into a report
+ */
+export function summarizeHealth(checks: HealthCheck[], now: number = Date.now()): HealthReport {
+  const status: HealthStatus = checks.some((c) => c.status === "fail")
+    ? "unhealthy"
+    : checks.some((c) => c.status === "warn")
+      ? "degraded"
+      : "healthy";
+  return { status, checkedAt: now, checks };
+}
+
+/**
+ * HTTP status for a report
+ */
+export function healthStatusCode(report: HealthReport): number {
+  return report.status === "unhealthy" ? 503 : 200;
+}
+
+/**
+ * Age of the latest glucose reading
+ */
+export function checkGlucoseFreshness(timestamp: number | null, now: number = Date.now()): HealthCheck {
+  if (timestamp === null) {
+    return { name: "glucose", status: "fail", detail: "no reading yet" };
+  }
+  const age = now - timestamp;
+  const status: CheckStatus = age > GLUCOSE_FAIL_AGE_MS ? "fail" : age > GLUCOSE_WARN_AGE_MS ? "warn" : "pass";
+  return { name: "glucose", status, detail: `latest reading ${minutes(age)} old` };
+}
+
+/**
+ * Dexcom login, judged from the backoff state rather than logging in (which
+ * would spend the request budget on every probe)
+ */
+export function checkDexcomAuth(backoff: DexcomBackoffState | null): HealthCheck {
+  if (!backoff) {
+    return { name: "dexcom", status: "pass", detail: "last run succeeded" };
+  }
+  const error = backoff.lastError ?? "unknown error";
+  // Bad credentials won't fix themselves; anything else is likely Dexcom
+  const authFailure = /auth failed|login failed|AccountPassword/i.test(error);
+  return {
+    name: "dexcom",
+    status: authFailure ? "fail" : "warn",
+    detail: `${backoff.failures} failed runs: ${error}`,
+  };
+}
+
+/**
+ * Whether the last compositor run reached a display
+ */
+export function checkDevices(status: CompositorStatus | null, now: number = Date.now()): HealthCheck {
+  if (!status) {
+    return { name: "devices", status: "fail", detail: "compositor has not run" };
+  }
+  const age = now - status.updatedAt;
+  if (age > COMPOSITOR_STALE_MS) {
+    return { name: "devices", status: "fail", detail: `compositor last ran ${minutes(age)} ago` };
+  }
+  if (status.skipped || !status.connections) {
+    return { name: "devices", status: "warn", detail: "no display connected" };
+  }
+  const broadcast = status.broadcast;
+  if (!broadcast) {
+    return {
+      name: "devices",
+      status: "pass",
+      detail: `${status.connections} connected${status.screenOff ? ", screen off" : ""}`,
+    };
+  }
+  if (broadcast.success === 0 && broadcast.failed > 0) {
+    return { name: "devices", status: "fail", detail: `all ${broadcast.failed} sends failed` };
+  }
+  const failed = broadcast.failed > 0 ? `, ${broadcast.failed} failed` : "";
+  return {
+    name: "devices",
+    status: broadcast.failed > 0 ? "warn" : "pass",
+    detail: `${broadcast.success} of ${status.connections} reached${failed}`,
+  };
+}
+
+/**
+ * Run a probe, failing the check when it throws
+ */
+export async function checkProbe(name: string, probe: () => Promise<string>): Promise<HealthCheck> {
+  try {
+    return { name, status: "pass", detail: await probe() };
+  } catch (error) {
+    return { name, status: "fail", detail: error instanceof Error ? error.message : String(error) };
+  }
+}
@@ -0,0 +1,8 @@
+/**
+ * Health checks, for the local server's /healthz
+ *
+ * Only the pure checks; the endpoint and its store need the deployed
+ * resources.
+ */
+
+export * from "./checks.js";
@@ -0,0 +1,47 @@
+/**
+ * Health check store
+ * Probes DynamoDB and reads the state the checks grade.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** Overwritten by every probe, and expires on its own */
+const PROBE_KEY = { pk: "HEALTH", sk: "PROBE" };
+const PROBE_TTL_SECONDS = 24 * 60 * 60;
+
+/**
+ * Write an item and read it back. Returns a short description with the
+ * round trip time; throws when either fails or the read is stale.
+ */
+export async function probeStorage(now: number = Date.now()): Promise<string> {
+  const startedAt = Date.now();
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: { ...PROBE_KEY, checkedAt: now, ttl: Math.floor(now / 1000) + PROBE_TTL_SECONDS },
+    })
+  );
+  const result = await ddb.send(
+    new GetCommand({ TableName: Resource.SignageTable.name, Key: PROBE_KEY, ConsistentRead: true })
+  );
+  if (result.Item?.checkedAt !== now) {
+    throw new Error("probe item did not read back");
+  }
+  return `write and read in ${Date.now() - startedAt}ms`;
+}
+
+/**
+ * Timestamp of the cached glucose reading, or null without one
+ */
+export async function getLatestReadingTime(): Promise<number | null> {
+  const result = await ddb.send(
+    new GetCommand({ TableName: Resource.SignageTable.name, Key: { pk: "BG_CACHE", sk: "LATEST" } })
+  );
+  const current = result.Item?.current as { timestamp?: number } | undefined;
+  return current?.timestamp ?? null;
+}
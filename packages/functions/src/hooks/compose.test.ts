@@ -0,0 +1,113 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import { createSolidFrame } from "@signage/core";
+import { runBeforeCompose, runAfterCompose, type ComposeHook } from "./compose";
+import { createScriptHook } from "./script";
+import type { CompositorData } from "../rendering/index";
+
+const data: CompositorData = { bloodSugar: null, timezone: "America/Los_Angeles" };
+const frame = createSolidFrame(4, 4);
+const red = createSolidFrame(4, 4, { r: 255, g: 0, b: 0 });
+
+beforeEach(() => {
+  vi.spyOn(console, "error").mockImplementation(() => {});
+});
+
+describe("runBeforeCompose", () => {
+  it("passes each hook the previous hook's data", async () => {
+    const hooks: ComposeHook[] = [
+      { name: "a", beforeCompose: (d) => ({ ...d, locale: "de" }) },
+      { name: "b", beforeCompose: (d) => ({ ...d, timezone: d.locale === "de" ? "Europe/Berlin" : "UTC" }) },
+    ];
+
+    expect(await runBeforeCompose(hooks, data)).toMatchObject({ locale: "de", timezone: "Europe/Berlin" });
+  });
+
+  it("keeps the data when a hook returns nothing or throws", async () => {
+    const hooks: ComposeHook[] = [
+      { name: "inspect", beforeCompose: () => undefined },
+      {
+        name: "broken",
+        beforeCompose: () => {
+          throw new Error("boom");
+        },
+      },
+    ];
+
+    expect(await runBeforeCompose(hooks, data)).toBe(data);
+  });
+});
+
+describe("runAfterCompose", () => {
+  it("uses a replacement frame", async () => {
+    const result = await runAfterCompose([{ name: "overlay", afterCompose: () => ({ frame: red }) }], frame, data);
+
+    expect(result).toEqual({ frame: red, vetoedBy: null });
+  });
+
+  it("stops at the first veto", async () => {
+    const later = vi.fn();
+    const result = await runAfterCompose(
+      [
+        { name: "quiet-hours", afterCompose: () => ({ veto: true }) },
+        { name: "later", afterCompose: later },
+      ],
+      frame,
+      data
+    );
+
+    expect(result.vetoedBy).toBe("quiet-hours");
+    expect(later).not.toHaveBeenCalled();
+  });
+
+  it("ignores a frame of the wrong size", async () => {
+    const result = await runAfterCompose(
+      [{ name: "resize", afterCompose: () => ({ frame: createSolidFrame(8, 8) }) }],
+      frame,
+      data
+    );
+
+    expect(result.frame).toBe(frame);
+  });
+});
+
+describe("createScriptHook", () => {
+  const node = JSON.stringify(process.execPath);
+
+  function script(source: string): ComposeHook {
+    return createScriptHook(`${node} -e ${JSON.stringify(source)}`);
+  }
+
+  it("replaces the data with the script's output", async () => {
+    const hook = script(
+      `let s="";process.stdin.on("data",c=>s+=c).on("end",()=>{const i=JSON.parse(s);console.log(JSON.stringify({data:{locale:i.stage==="before"?"fr":"en"}}))})`
+    );
+
+    expect(await runBeforeCompose([hook], data)).toMatchObject({ locale: "fr", timezone: "America/Los_Angeles" });
+  });
+
+  it("replaces the frame with the script's output", async () => {
+    const hook = script(`console.log(JSON.stringify({frame:Buffer.alloc(48,255).toString("base64")}))`);
+
+    const result = await runAfterCompose([hook], frame, data);
+
+    expect(Array.from(result.frame.pixels)).toEqual(new Array(48).fill(255));
+  });
+
+  it("vetoes the send", async () => {
+    const hook = script(`console.log(JSON.stringify({veto:true}))`);
+
+    expect((await runAfterCompose([hook], frame, data)).vetoedBy).toBe(hook.name);
+  });
+
+  it("leaves the frame alone when the script prints nothing or fails", async () => {
+    expect((await runAfterCompose([script("")], frame, data)).frame).toBe(frame);
+    expect((await runAfterCompose([script("process.exit(2)")], frame, data)).frame).toBe(frame);
+  });
+
+  it("gives up on a script that runs too long", async () => {
+    const hook = createScriptHook(`${node} -e ${JSON.stringify("setTimeout(()=>{},10000)")}`, 100);
+
+    expect((await runAfterCompose([hook], frame, data)).frame).toBe(frame);
+    expect(console.error).toHaveBeenCalledWith(expect.stringContaining("failed after compose"), expect.any(Error));
+  });
+});
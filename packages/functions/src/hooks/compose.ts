@@ -0,0 +1,75 @@
+/**
+ * Compose hooks
+ *
+ * Extension points around frame composition. A hook can change the data
+ * before the frame is rendered (e.g. add an annotation), and replace or
+ * veto the frame afterwards (e.g. draw an overlay, skip a send, or copy
+ * the frame somewhere else). A hook that throws is logged and skipped, so
+ * a broken hook never blanks the display.
+ */
+
+import type { Frame } from "@signage/core";
+import type { CompositorData } from "../rendering/index.js";
+
+/** What a post-compose hook can do with the frame */
+export interface AfterComposeResult {
+  /** Frame to send instead */
+  frame?: Frame;
+  /** Don't send anything this cycle */
+  veto?: boolean;
+}
+
+/**
+ * A compose hook. Both stages are optional; returning nothing leaves the
+ * data or frame as it was.
+ */
+export interface ComposeHook {
+  name: string;
+  beforeCompose?(data: CompositorData): CompositorData | void | Promise<CompositorData | void>;
+  afterCompose?(frame: Frame, data: CompositorData): AfterComposeResult | void | Promise<AfterComposeResult | void>;
+}
+
+/**
+ * Run the pre-compose hooks in order, each seeing the previous one's data
+ */
+export async function runBeforeCompose(hooks: ComposeHook[], data: CompositorData): Promise<CompositorData> {
+  let current = data;
+  for (const hook of hooks) {
+    if (!hook.beforeCompose) continue;
+    try {
+      current = (await hook.beforeCompose(current)) ?? current;
+    } catch (error) {
+      console.error(`Compose hook ${hook.name} failed before compose:`, error);
+    }
+  }
+  return current;
+}
+
+/**
+ * Run the post-compose hooks in order. Stops at the first veto and
+ * returns the hook's name with it.
+ */
+export async function runAfterCompose(
+  hooks: ComposeHook[],
+  frame: Frame,
+  data: CompositorData
+): Promise<{ frame: Frame; vetoedBy: string | null }> {
+  let current = frame;
+  for (const hook of hooks) {
+    if (!hook.afterCompose) continue;
+    try {
+      const result = await hook.afterCompose(current, data);
+      if (result?.veto) return { frame: current, vetoedBy: hook.name };
+      if (result?.frame) {
+        const { width, height, pixels } = result.frame;
+        if (width !== frame.width || height !== frame.height || pixels.length !== frame.pixels.length) {
+          throw new Error(`returned a ${width}x${height} frame with ${pixels.length} bytes`);
+        }
+        current = result.frame;
+      }
+    } catch (error) {
+      console.error(`Compose hook ${hook.name} failed after compose:`, error);
+    }
+  }
+  return { frame: current, vetoedBy: null };
+}
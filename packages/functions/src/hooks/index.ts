@@ -0,0 +1,7 @@
+/**
+ * Compose hooks - extension points around frame composition
+ */
+
+export * from "./compose.js";
+export * from "./script.js";
+export * from "./registry.js";
@@ -0,0 +1,12 @@
+/**
+ * Compose hook registry
+ * Hooks the compositor runs around each frame, in order.
+ */
+
+import type { ComposeHook } from "./compose.js";
+
+/**
+ * Hooks run by the compositor.
+ * Add hooks here; see compose.ts for what a hook can do.
+ */
+export const composeHooks: ComposeHook[] = [];
@@ -0,0 +1,78 @@
+/**
+ * Script compose hook
+ *
+ * Runs a command for each stage with JSON on stdin, so a hook can be
+ * written in any language:
+ *
+ *   before: { "stage": "before", "data": CompositorData }
+ *           -> { "data": CompositorData } to replace the data
+ *   after:  { "stage": "after", "data": ..., "frame": { width, height, data } }
+ *           -> { "frame": "<base64 RGB>" } to replace the frame,
+ *              or { "veto": true } to skip the send
+ *
+ * Empty output leaves things as they were. A non-zero exit, bad JSON or a
+ * timeout fails the hook (which the runner logs and skips).
+ */
+
+import { spawn } from "node:child_process";
+import { encodeFrameToBase64, decodeBase64ToPixels } from "@signage/core";
+import type { ComposeHook } from "./compose.js";
+
+/** Longest a script may take per stage; the compositor runs every minute */
+export const SCRIPT_HOOK_TIMEOUT_MS = 5000;
+
+/**
+ * Run a shell command with `input` on stdin and parse its stdout as JSON
+ * (null when it printed nothing)
+ */
+async function runScript(command: string, input: unknown, timeoutMs: number): Promise<Record<string, unknown> | null> {
+  const output = await new Promise<string>((resolve, reject) => {
+    const child = spawn(command, { shell: true, stdio: ["pipe", "pipe", "inherit"] });
+    let stdout = "";
+    const timer = setTimeout(() => {
+      child.kill("SIGKILL");
+      reject(new Error(`timed out after ${timeoutMs}ms`));
+    }, timeoutMs);
+    child.stdout.setEncoding("utf8");
+    child.stdout.on("data", (chunk: string) => (stdout += chunk));
+    child.on("error", (error) => {
+      clearTimeout(timer);
+      reject(error);
+    });
+    child.on("close", (code) => {
+      clearTimeout(timer);
+      if (code === 0) resolve(stdout);
+      else reject(new Error(`exited with ${code}`));
+    });
+    // A script that ignores stdin may close it before we finish writing
+    child.stdin.on("error", () => {});
+    child.stdin.end(JSON.stringify(input));
+  });
+  return output.trim() ? (JSON.parse(output) as Record<string, unknown>) : null;
+}
+
+/**
+ * Create a compose hook that runs `command` for both stages
+ */
+export function createScriptHook(command: string, timeoutMs: number = SCRIPT_HOOK_TIMEOUT_MS): ComposeHook {
+  return {
+    name: `script:${command}`,
+    async beforeCompose(data) {
+      const result = await runScript(command, { stage: "before", data }, timeoutMs);
+      return result?.data ? { ...data, ...(result.data as object) } : undefined;
+    },
+    async afterCompose(frame, data) {
+      const result = await runScript(
+        command,
+        { stage: "after", data, frame: { width: frame.width, height: frame.height, data: encodeFrameToBase64(frame) } },
+        timeoutMs
+      );
+      if (!result) return undefined;
+      if (result.veto === true) return { veto: true };
+      if (typeof result.frame === "string") {
+        return { frame: decodeBase64ToPixels(result.frame, frame.width, frame.height) };
+      }
+      return undefined;
+    },
+  };
+}
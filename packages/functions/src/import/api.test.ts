@@ -0,0 +1,114 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockStoreRecords } = vi.hoisted(() => ({
+  mockStoreRecords: vi.fn(),
+}));
+
+vi.mock("sst", () => ({
+  Resource: { SignageTable: { name: "test-table" } },
+}));
+
+vi.mock("@diabetes/core", () => ({
+  createDocClient: () => ({}),
+  storeRecords: mockStoreRecords,
+  isValidGlucose: (value: number) => value >= 20 && value <= 600,
+  parseCsvLine: vi.fn(),
+}));
+
+import { handler, parseImportBody } from "./api";
+
+const NOW = Date.UTC(2026, 2, 2, 17);
+
+function createEvent(method: string, body?: unknown, query?: Record<string, string>): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    queryStringParameters: query,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("parseImportBody", () => {
+  it("accepts readings from a known source", () => {
+    const result = parseImportBody(
+      { source: "clarity", readings: [{ timestamp: NOW - 60000, glucoseMgDl: 112 }] },
+      NOW
+    );
+    expect(result).toEqual({ source: "clarity", readings: [{ timestamp: NOW - 60000, glucoseMgDl: 112 }] });
+  });
+
+  it("names the first bad reading", () => {
+    const readings = [
+      { timestamp: NOW - 60000, glucoseMgDl: 112 },
+      { timestamp: NOW - 30000, glucoseMgDl: 900 },
+    ];
+    expect(parseImportBody({ source: "nightscout", readings }, NOW)).toMatch(/readings\[1\]\.glucoseMgDl/);
+    expect(parseImportBody({ source: "nightscout", readings: [{ timestamp: NOW + 3600000, glucoseMgDl: 100 }] }, NOW)).toMatch(
+      /future/
+    );
+  });
+
+  it("rejects unknown sources, empty and oversized batches", () => {
+    expect(parseImportBody({ source: "libre", readings: [] }, NOW)).toMatch(/clarity, nightscout/);
+    expect(parseImportBody({ source: "clarity", readings: [] }, NOW)).toMatch(/non-empty/);
+    const many = Array.from({ length: 2001 }, (_, i) => ({ timestamp: NOW - i * 300000, glucoseMgDl: 100 }));
+    expect(parseImportBody({ source: "clarity", readings: many }, NOW)).toMatch(/at most 2000/);
+  });
+});
+
+describe("handler", () => {
+  beforeEach(() => {
+    mockStoreRecords.mockReset();
+  });
+
+  it("stores readings as CGM records and reports duplicates", async () => {
+    mockStoreRecords.mockResolvedValue({ written: 1, duplicates: 1, errors: [] });
+
+    const { statusCode, body } = await invoke(
+      createEvent(
+        "POST",
+        {
+          source: "clarity",
+          readings: [
+            { timestamp: 1772468100000, glucoseMgDl: 112 },
+            { timestamp: 1772468400000, glucoseMgDl: 118 },
+          ],
+        },
+        { profile: "grandma" }
+      )
+    );
+
+    expect(statusCode).toBe(200);
+    expect(body).toEqual({ written: 1, duplicates: 1 });
+    const [, table, profile, records] = mockStoreRecords.mock.calls[0];
+    expect(table).toBe("test-table");
+    expect(profile).toBe("grandma");
+    expect(records[0]).toMatchObject({
+      type: "cgm",
+      timestamp: 1772468100000,
+      glucoseMgDl: 112,
+      sourceFile: "clarity-import",
+    });
+  });
+
+  it("returns 502 when some readings couldn't be stored", async () => {
+    mockStoreRecords.mockResolvedValue({ written: 0, duplicates: 0, errors: ["throttled"] });
+
+    const { statusCode } = await invoke(
+      createEvent("POST", { source: "nightscout", readings: [{ timestamp: 1772468100000, glucoseMgDl: 112 }] })
+    );
+
+    expect(statusCode).toBe(502);
+  });
+
+  it("rejects bad bodies and other methods", async () => {
+    expect((await invoke(createEvent("POST", { source: "clarity" }))).statusCode).toBe(400);
+    expect((await invoke(createEvent("GET"))).statusCode).toBe(405);
+    expect(mockStoreRecords).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,129 @@
+/**
+ * Import API
+ *
+ * POST /import - store glucose readings parsed from another tool's export
+ *
+ * Body: { "source": "clarity", "readings": [{ "timestamp": 1772468100000, "glucoseMgDl": 112 }] }
+ * `source` is clarity or nightscout. Up to 2000 readings per request; the
+ * `pnpm import-readings` command parses an export file and sends it in
+ * batches. Readings already stored are counted as duplicates, so an import
+ * can be run again safely.
+ * Add `?profile=<id>` to import into another household's readings.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { Resource } from "sst";
+import { createDocClient, storeRecords, isValidGlucose } from "@diabetes/core";
+import type { CgmReading } from "@diabetes/core";
+import type { DynamoDBDocumentClient } from "@aws-sdk/lib-dynamodb";
+import { parseProfileParam } from "../profiles/profile.js";
+import {
+  IMPORT_FORMATS,
+  isImportFormat,
+  MAX_IMPORT_READINGS,
+  type ImportedReading,
+  type ImportFormat,
+} from "./parse.js";
+
+/** Allow small clock skew for the newest readings */
+const MAX_FUTURE_MS = 5 * 60 * 1000;
+
+let docClient: DynamoDBDocumentClient | null = null;
+
+function getDocClient(): DynamoDBDocumentClient {
+  if (!docClient) {
+    docClient = createDocClient();
+  }
+  return docClient;
+}
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * Validate an import request body.
+ * Returns the source and readings, or an error message naming the first bad reading.
+ */
+export function parseImportBody(
+  body: { source?: unknown; readings?: unknown },
+  now: number = Date.now()
+): { source: ImportFormat; readings: ImportedReading[] } | string {
+  if (!isImportFormat(body.source)) {
+    return `source must be one of: ${IMPORT_FORMATS.join(", ")}`;
+  }
+  if (!Array.isArray(body.readings) || body.readings.length === 0) {
+    return "readings must be a non-empty array";
+  }
+  if (body.readings.length > MAX_IMPORT_READINGS) {
+    return `at most ${MAX_IMPORT_READINGS} readings per request`;
+  }
+
+  const readings: ImportedReading[] = [];
+  for (const [i, reading] of (body.readings as unknown[]).entries()) {
+    const { timestamp, glucoseMgDl } = (reading ?? {}) as Record<string, unknown>;
+    if (typeof timestamp !== "number" || !Number.isInteger(timestamp) || timestamp <= 0) {
+      return `readings[${i}].timestamp must be epoch milliseconds`;
+    }
+    if (timestamp > now + MAX_FUTURE_MS) {
+      return `readings[${i}] is in the future`;
+    }
+    if (typeof glucoseMgDl !== "number" || !isValidGlucose(glucoseMgDl)) {
+      return `readings[${i}].glucoseMgDl must be a glucose value in mg/dL`;
+    }
+    readings.push({ timestamp, glucoseMgDl: Math.round(glucoseMgDl) });
+  }
+  return { source: body.source, readings };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  const profile = parseProfileParam(event.queryStringParameters);
+  if (typeof profile !== "string") {
+    return json(400, profile);
+  }
+
+  let body: { source?: unknown; readings?: unknown };
+  try {
+    body = JSON.parse(event.body || "{}");
+  } catch {
+    return json(400, { error: "Invalid JSON" });
+  }
+
+  const result = parseImportBody(body);
+  if (typeof result === "string") {
+    return json(400, { error: result });
+  }
+
+  const importedAt = Date.now();
+  const records: CgmReading[] = result.readings.map((reading) => ({
+    type: "cgm",
+    timestamp: reading.timestamp,
+    glucoseMgDl: reading.glucoseMgDl,
+    importedAt,
+    sourceFile: `${result.source}-import`,
+  }));
+  const { written, duplicates, errors } = await storeRecords(
+    getDocClient(),
+    Resource.SignageTable.name,
+    profile,
+    records
+  );
+  if (errors.length > 0) {
+    console.error(`Import write errors: ${errors.join(", ")}`);
+    return json(502, { error: "Some readings could not be stored", written, duplicates });
+  }
+
+  console.log(`Imported ${written} ${result.source} readings (${duplicates} already stored)`);
+  return json(200, { written, duplicates });
+};
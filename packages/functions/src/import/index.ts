@@ -0,0 +1,7 @@
+/**
+ * Glucose export parsers, for the import command
+ *
+ * Only the parsing code; the API needs the deployed resources.
+ */
+
+export * from "./parse.js";
@@ -0,0 +1,79 @@
+import { describe, it, expect } from "vitest";
+import { detectImportFormat, parseClarityCsv, parseImport, parseNightscoutJson } from "./parse";
+
+const CLARITY = [
+  'Index,Timestamp (YYYY-MM-DDThh:mm:ss),Event Type,Event Subtype,Patient Info,Device Info,Source Device ID,Glucose Value (mg/dL),Insulin Value (u),Carb Value (grams)',
+  "1,,FirstName,,Jane,,,,,",
+  "2,,Device,,,G7,Android G7,,,",
+  "3,2026-03-02T08:15:00,EGV,,,,Android G7,112,,",
+  "4,2026-03-02T08:20:00,EGV,,,,Android G7,Low,,",
+  "5,2026-03-02T08:25:00,EGV,,,,Android G7,High,,",
+  "6,2026-03-02T08:30:00,Carbs,,,,Android G7,,,30",
+  "7,2026-03-02T08:35:00,EGV,,,,Android G7,,,",
+].join("\r\n");
+
+describe("parseClarityCsv", () => {
+  it("reads EGV rows in local time, with Low and High at the sensor's limits", () => {
+    const result = parseClarityCsv(CLARITY, "America/Los_Angeles");
+
+    expect(result.readings).toEqual([
+      { timestamp: Date.UTC(2026, 2, 2, 16, 15), glucoseMgDl: 112 },
+      { timestamp: Date.UTC(2026, 2, 2, 16, 20), glucoseMgDl: 40 },
+      { timestamp: Date.UTC(2026, 2, 2, 16, 25), glucoseMgDl: 400 },
+    ]);
+    // The EGV row without a value
+    expect(result.skipped).toBe(1);
+  });
+
+  it("converts mmol/L exports", () => {
+    const csv = "Index,Timestamp (YYYY-MM-DDThh:mm:ss),Event Type,Glucose Value (mmol/L)\n1,2026-03-02T08:15:00,EGV,6.2";
+
+    expect(parseClarityCsv(csv, "UTC").readings).toEqual([
+      { timestamp: Date.UTC(2026, 2, 2, 8, 15), glucoseMgDl: 112 },
+    ]);
+  });
+
+  it("finds nothing in a file without the Clarity header", () => {
+    expect(parseClarityCsv("a,b,c\n1,2,3")).toEqual({ readings: [], skipped: 0 });
+  });
+});
+
+describe("parseNightscoutJson", () => {
+  it("reads SGV entries from the API's array", () => {
+    const json = JSON.stringify([
+      { type: "sgv", sgv: 140, date: 1772468100000, direction: "Flat" },
+      { type: "mbg", mbg: 150, date: 1772468200000 },
+      { type: "sgv", sgv: 5, date: 1772468400000 },
+    ]);
+
+    expect(parseNightscoutJson(json)).toEqual({
+      readings: [{ timestamp: 1772468100000, glucoseMgDl: 140 }],
+      skipped: 1,
+    });
+  });
+
+  it("reads a mongoexport, one entry per line", () => {
+    const lines = [
+      '{"type":"sgv","sgv":{"$numberInt":"98"},"date":{"$numberLong":"1772468100000"}}',
+      '{"type":"sgv","sgv":101,"dateString":"2026-03-02T16:20:00.000Z"}',
+      "not json",
+    ].join("\n");
+
+    expect(parseNightscoutJson(lines)).toEqual({
+      readings: [
+        { timestamp: 1772468100000, glucoseMgDl: 98 },
+        { timestamp: Date.UTC(2026, 2, 2, 16, 20), glucoseMgDl: 101 },
+      ],
+      skipped: 1,
+    });
+  });
+});
+
+describe("parseImport", () => {
+  it("tells the formats apart by their contents", () => {
+    expect(detectImportFormat(CLARITY)).toBe("clarity");
+    expect(detectImportFormat('  [{"sgv": 100}]')).toBe("nightscout");
+    expect(detectImportFormat('{"sgv": 100}\n{"sgv": 101}')).toBe("nightscout");
+    expect(parseImport(CLARITY, undefined, "America/Los_Angeles").readings).toHaveLength(3);
+  });
+});
@@ -0,0 +1,168 @@
+/**
+ * Parse glucose exports from other tools
+ *
+ * - Dexcom Clarity: the CSV from "Export" on the Overview page. One row per
+ *   event; the EGV rows are the sensor readings. Times are local wall-clock
+ *   times without a zone, and values are mg/dL or mmol/L depending on the
+ *   account. Readings outside the sensor's range are written "Low"/"High".
+ * - Nightscout: the entries JSON from /api/v1/entries.json (an array), or a
+ *   mongoexport of the entries collection (one object per line).
+ */
+
+import { parseCsvLine, isValidGlucose } from "@diabetes/core";
+import { DEFAULT_TIMEZONE, zonedTimestamp } from "../rendering/zoned-time.js";
+
+export type ImportFormat = "clarity" | "nightscout";
+
+export const IMPORT_FORMATS: readonly ImportFormat[] = ["clarity", "nightscout"];
+
+/** What Clarity writes for readings below and above the sensor's range */
+export const SENSOR_LOW_MGDL = 40;
+export const SENSOR_HIGH_MGDL = 400;
+
+/** Most readings the import API takes per request */
+export const MAX_IMPORT_READINGS = 2000;
+
+const MGDL_PER_MMOL = 18.0182;
+
+/** One reading to import */
+export interface ImportedReading {
+  timestamp: number;
+  glucoseMgDl: number;
+}
+
+export interface ImportParseResult {
+  readings: ImportedReading[];
+  /** Rows that looked like readings but couldn't be used */
+  skipped: number;
+}
+
+export function isImportFormat(value: unknown): value is ImportFormat {
+  return IMPORT_FORMATS.includes(value as ImportFormat);
+}
+
+/**
+ * Guess the format of an export from its contents
+ */
+export function detectImportFormat(text: string): ImportFormat {
+  return /^\s*[[{]/.test(text) ? "nightscout" : "clarity";
+}
+
+/**
+ * Parse a Clarity wall-clock time ("2026-03-02T08:15:00") in a timezone
+ */
+function parseClarityTime(value: string, timezone: string): number | null {
+  const match = /^(\d{4})-(\d{2})-(\d{2})[T ](\d{2}):(\d{2})(?::(\d{2}))?/.exec(value);
+  if (!match) return null;
+  const [, year, month, day, hour, minute, second = "0"] = match;
+  return (
+    zonedTimestamp(Number(year), Number(month), Number(day), Number(hour), Number(minute), timezone) +
+    Number(second) * 1000
+  );
+}
+
+/**
+ * Parse a Dexcom Clarity CSV export
+ * @param timezone Zone the Clarity account shows times in
+ */
+export function parseClarityCsv(text: string, timezone: string = DEFAULT_TIMEZONE): ImportParseResult {
+  const lines = text.split(/\r?\n/).filter((line) => line.trim() !== "");
+  const headerIdx = lines.findIndex((line) => /event type/i.test(line) && /glucose value/i.test(line));
+  if (headerIdx === -1) {
+    return { readings: [], skipped: 0 };
+  }
+
+  const header = parseCsvLine(lines[headerIdx]).map((name) => name.toLowerCase());
+  const timeCol = header.findIndex((name) => name.startsWith("timestamp"));
+  const typeCol = header.indexOf("event type");
+  const glucoseCol = header.findIndex((name) => name.startsWith("glucose value"));
+  const isMmol = header[glucoseCol].includes("mmol");
+
+  const readings: ImportedReading[] = [];
+  let skipped = 0;
+  for (const line of lines.slice(headerIdx + 1)) {
+    const row = parseCsvLine(line);
+    if (row[typeCol]?.toUpperCase() !== "EGV") continue;
+
+    const timestamp = parseClarityTime(row[timeCol] ?? "", timezone);
+    const raw = (row[glucoseCol] ?? "").toLowerCase();
+    const value =
+      raw === "low"
+        ? SENSOR_LOW_MGDL
+        : raw === "high"
+          ? SENSOR_HIGH_MGDL
+          : isMmol
+            ? Math.round(parseFloat(raw) * MGDL_PER_MMOL)
+            : Math.round(parseFloat(raw));
+    if (timestamp === null || !Number.isFinite(value) || !isValidGlucose(value)) {
+      skipped++;
+      continue;
+    }
+    readings.push({ timestamp, glucoseMgDl: value });
+  }
+  return { readings, skipped };
+}
+
+/**
+ * Read a number or time from an export. mongoexport wraps them
+ * ({"$numberLong": "..."}, {"$date": "..."}), and times may be ISO strings.
+ */
+function exportedNumber(value: unknown): number {
+  if (typeof value === "object" && value !== null) {
+    const wrapped = value as Record<string, unknown>;
+    return exportedNumber(wrapped.$numberLong ?? wrapped.$numberInt ?? wrapped.$numberDouble ?? wrapped.$date);
+  }
+  if (typeof value === "string") {
+    return /^-?\d+(\.\d+)?$/.test(value.trim()) ? Number(value) : Date.parse(value);
+  }
+  return typeof value === "number" ? value : NaN;
+}
+
+/**
+ * Parse a Nightscout entries export. Only SGV entries are readings.
+ */
+export function parseNightscoutJson(text: string): ImportParseResult {
+  let entries: unknown[];
+  try {
+    const parsed: unknown = JSON.parse(text);
+    entries = Array.isArray(parsed) ? parsed : [parsed];
+  } catch {
+    // mongoexport: one entry per line
+    entries = [];
+    for (const line of text.split(/\r?\n/)) {
+      if (line.trim() === "") continue;
+      try {
+        entries.push(JSON.parse(line));
+      } catch {
+        entries.push(null);
+      }
+    }
+  }
+
+  const readings: ImportedReading[] = [];
+  let skipped = 0;
+  for (const entry of entries) {
+    const e = (entry ?? {}) as Record<string, unknown>;
+    if (e.type !== undefined && e.type !== "sgv") continue;
+
+    const timestamp = e.date !== undefined ? exportedNumber(e.date) : exportedNumber(e.dateString);
+    const value = exportedNumber(e.sgv);
+    if (!Number.isFinite(timestamp) || timestamp <= 0 || !Number.isFinite(value) || !isValidGlucose(value)) {
+      skipped++;
+      continue;
+    }
+    readings.push({ timestamp, glucoseMgDl: Math.round(value) });
+  }
+  return { readings, skipped };
+}
+
+/**
+ * Parse an export in either format
+ */
+export function parseImport(
+  text: string,
+  format: ImportFormat = detectImportFormat(text),
+  timezone?: string
+): ImportParseResult {
+  return format === "nightscout" ? parseNightscoutJson(text) : parseClarityCsv(text, timezone);
+}
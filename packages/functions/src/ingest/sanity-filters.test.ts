@@ -0,0 +1,79 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import {
+  filterGlucoseReadings,
+  sanitizeTemperaturesF,
+  recordRejections,
+  getRejectionCounts,
+  resetRejectionCounts,
+} from "./sanity-filters";
+
+const reading = (ms: number, value: number) => ({
+  WT: `Date(${ms})`,
+  ST: `Date(${ms})`,
+  DT: `Date(${ms})`,
+  Value: value,
+  Trend: "Flat",
+});
+
+describe("filterGlucoseReadings", () => {
+  it("keeps plausible readings in order", () => {
+    const readings = [reading(2000, 120), reading(1000, 115)];
+    const { accepted, rejected } = filterGlucoseReadings(readings);
+
+    expect(accepted).toEqual(readings);
+    expect(rejected).toEqual([]);
+  });
+
+  it("rejects out-of-range glucose", () => {
+    const { accepted, rejected } = filterGlucoseReadings([
+      reading(3000, 0),
+      reading(2000, 120),
+      reading(1000, 999),
+    ]);
+
+    expect(accepted.map((r) => r.Value)).toEqual([120]);
+    expect(rejected.map((r) => r.reason)).toEqual(["glucose out of range", "glucose out of range"]);
+  });
+
+  it("rejects duplicate and unparseable timestamps", () => {
+    const { accepted, rejected } = filterGlucoseReadings([
+      reading(2000, 120),
+      reading(2000, 121),
+      { ...reading(1000, 110), WT: "garbage" },
+    ]);
+
+    expect(accepted.map((r) => r.Value)).toEqual([120]);
+    expect(rejected.map((r) => r.reason)).toEqual(["duplicate timestamp", "invalid timestamp"]);
+  });
+
+  it("honors custom limits", () => {
+    const limits = { glucoseMgDl: { min: 40, max: 400 }, temperatureC: { min: -60, max: 60 } };
+    const { accepted } = filterGlucoseReadings([reading(1000, 30)], limits);
+
+    expect(accepted).toEqual([]);
+  });
+});
+
+describe("sanitizeTemperaturesF", () => {
+  it("blanks implausible temperatures without shifting the series", () => {
+    const { values, rejected } = sanitizeTemperaturesF([50, 999, null, -100]);
+
+    expect(values).toEqual([50, undefined, undefined, undefined]);
+    expect(rejected).toHaveLength(2);
+  });
+});
+
+describe("recordRejections", () => {
+  beforeEach(() => {
+    resetRejectionCounts();
+    vi.spyOn(console, "warn").mockImplementation(() => {});
+  });
+
+  it("counts rejections per source", () => {
+    recordRejections("dexcom", [{ reason: "glucose out of range", value: 0 }]);
+    recordRejections("dexcom", [{ reason: "duplicate timestamp", value: 120 }]);
+    recordRejections("weather", []);
+
+    expect(getRejectionCounts()).toEqual({ dexcom: 2 });
+  });
+});
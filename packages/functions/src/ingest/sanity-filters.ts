@@ -0,0 +1,124 @@
+/**
+ * Ingestion sanity filters
+ *
+ * Drops physically implausible points (sensor glitches, API garbage) before
+ * they reach charts, alerts, or storage. Rejected points are logged and
+ * counted per source.
+ */
+
+import type { DexcomReading } from "../dexcom/client.js";
+import { parseDexcomTimestamp } from "../dexcom/client.js";
+
+export interface ValueRange {
+  min: number;
+  max: number;
+}
+
+export interface SanityLimits {
+  /** Dexcom reports 40-400; anything outside 20-600 is garbage */
+  glucoseMgDl: ValueRange;
+  /** Outside recorded surface extremes */
+  temperatureC: ValueRange;
+}
+
+export const DEFAULT_SANITY_LIMITS: SanityLimits = {
+  glucoseMgDl: { min: 20, max: 600 },
+  temperatureC: { min: -60, max: 60 },
+};
+
+/**
+ * A point dropped by a sanity filter
+ */
+export interface Rejection {
+  reason: string;
+  value: unknown;
+  timestamp?: number;
+}
+
+/** Rejections since the last reset, keyed by source */
+const rejectionCounts: Record<string, number> = {};
+
+function inRange(value: number, range: ValueRange): boolean {
+  return Number.isFinite(value) && value >= range.min && value <= range.max;
+}
+
+/**
+ * Filter Dexcom readings: glucose must be in range, timestamps must parse,
+ * and only the first reading for each timestamp is kept. Order is preserved.
+ */
+export function filterGlucoseReadings(
+  readings: DexcomReading[],
+  limits: SanityLimits = DEFAULT_SANITY_LIMITS
+): { accepted: DexcomReading[]; rejected: Rejection[] } {
+  const accepted: DexcomReading[] = [];
+  const rejected: Rejection[] = [];
+  const seen = new Set<number>();
+
+  for (const reading of readings) {
+    const timestamp = parseDexcomTimestamp(reading.WT);
+    if (timestamp <= 0) {
+      rejected.push({ reason: "invalid timestamp", value: reading.WT });
+    } else if (!inRange(reading.Value, limits.glucoseMgDl)) {
+      rejected.push({ reason: "glucose out of range", value: reading.Value, timestamp });
+    } else if (seen.has(timestamp)) {
+      rejected.push({ reason: "duplicate timestamp", value: reading.Value, timestamp });
+    } else {
+      seen.add(timestamp);
+      accepted.push(reading);
+    }
+  }
+
+  return { accepted, rejected };
+}
+
+/**
+ * Sanitize an hourly temperature series in Fahrenheit.
+ * Implausible values become undefined rather than being removed, so the
+ * series stays aligned with its hours.
+ */
+export function sanitizeTemperaturesF(
+  temps: Array<number | null>,
+  limits: SanityLimits = DEFAULT_SANITY_LIMITS
+): { values: Array<number | undefined>; rejected: Rejection[] } {
+  const rejected: Rejection[] = [];
+  const values = temps.map((temp) => {
+    if (temp === null || temp === undefined) return undefined;
+    if (!inRange(((temp - 32) * 5) / 9, limits.temperatureC)) {
+      rejected.push({ reason: "temperature out of range", value: temp });
+      return undefined;
+    }
+    return temp;
+  });
+  return { values, rejected };
+}
+
+/**
+ * Log and count rejected points for a source.
+ */
+export function recordRejections(source: string, rejected: Rejection[]): void {
+  if (rejected.length === 0) return;
+
+  rejectionCounts[source] = (rejectionCounts[source] ?? 0) + rejected.length;
+  const reasons = [...new Set(rejected.map((r) => r.reason))].join(", ");
+  console.warn(`Sanity filter (${source}): rejected ${rejected.length} point(s): ${reasons}`);
+  for (const r of rejected.slice(0, 5)) {
+    const at = r.timestamp ? ` at ${new Date(r.timestamp).toISOString()}` : "";
+    console.warn(`  ${r.reason}: ${JSON.stringify(r.value)}${at}`);
+  }
+}
+
+/**
+ * Rejection counts by source since the last reset.
+ */
+export function getRejectionCounts(): Record<string, number> {
+  return { ...rejectionCounts };
+}
+
+/**
+ * Reset rejection counts (e.g. at the start of an update).
+ */
+export function resetRejectionCounts(): void {
+  for (const source of Object.keys(rejectionCounts)) {
+    delete rejectionCounts[source];
+  }
+}
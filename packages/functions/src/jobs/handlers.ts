@@ -0,0 +1,28 @@
+/**
+ * Job handlers
+ * One per job type. A handler throws to have the job retried.
+ */
+
+import { Resource } from "sst";
+import { repairGaps } from "../backfill/repair.js";
+import { forwardToNightscout } from "../treatments/nightscout.js";
+import type { JobPayloads, JobType } from "./queue.js";
+
+type JobHandlers = { [T in JobType]: (payload: JobPayloads[T]) => Promise<void> };
+
+export const jobHandlers: JobHandlers = {
+  async nightscoutForward({ treatment }) {
+    const url = Resource.NightscoutUrl.value;
+    // Nightscout was unconfigured after the job was queued; nothing to do
+    if (!url) return;
+    await forwardToNightscout(treatment, { url, apiSecret: Resource.NightscoutApiSecret.value });
+  },
+
+  async glucoseBackfill() {
+    const report = await repairGaps();
+    const failed = report.gaps.filter((gap) => gap.error);
+    if (failed.length > 0) {
+      throw new Error(`${failed.length} gaps failed: ${failed[0].error}`);
+    }
+  },
+};
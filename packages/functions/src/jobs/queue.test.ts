@@ -0,0 +1,42 @@
+import { describe, it, expect } from "vitest";
+import {
+  claimedJob,
+  isExhausted,
+  jobRetryDelayMs,
+  JOB_RETRY_BASE_MS,
+  JOB_RETRY_MAX_MS,
+  MAX_JOB_ATTEMPTS,
+  type Job,
+} from "./queue";
+
+const now = new Date("2026-03-02T12:00:00Z").getTime();
+
+function job(attempts: number): Job<"glucoseBackfill"> {
+  return { id: "abc", type: "glucoseBackfill", payload: {}, runAt: now, attempts, createdAt: now };
+}
+
+describe("jobRetryDelayMs", () => {
+  it("doubles from the base delay", () => {
+    expect(jobRetryDelayMs(1)).toBe(JOB_RETRY_BASE_MS);
+    expect(jobRetryDelayMs(2)).toBe(2 * JOB_RETRY_BASE_MS);
+    expect(jobRetryDelayMs(4)).toBe(8 * JOB_RETRY_BASE_MS);
+  });
+
+  it("caps the delay", () => {
+    expect(jobRetryDelayMs(30)).toBe(JOB_RETRY_MAX_MS);
+  });
+});
+
+describe("claimedJob", () => {
+  it("counts the attempt and pushes the job back by its retry delay", () => {
+    expect(claimedJob(job(0), now)).toMatchObject({ attempts: 1, runAt: now + JOB_RETRY_BASE_MS });
+    expect(claimedJob(job(2), now)).toMatchObject({ attempts: 3, runAt: now + 4 * JOB_RETRY_BASE_MS });
+  });
+});
+
+describe("isExhausted", () => {
+  it("gives up once the job has used every attempt", () => {
+    expect(isExhausted(job(MAX_JOB_ATTEMPTS - 1))).toBe(false);
+    expect(isExhausted(job(MAX_JOB_ATTEMPTS))).toBe(true);
+  });
+});
@@ -0,0 +1,70 @@
+/**
+ * Deferred job queue
+ *
+ * Work that failed or has to wait (a Nightscout write-back that got a 503,
+ * a backfill that couldn't reach its source) is stored as a job instead of
+ * being dropped, and a worker retries it on a schedule. Jobs live in
+ * DynamoDB, so nothing queued is lost when a Lambda dies mid-run.
+ */
+
+import type { GlookoTreatment } from "../glooko/types.js";
+
+/**
+ * Payload for each kind of job
+ */
+export interface JobPayloads {
+  /** Post a manual treatment to Nightscout */
+  nightscoutForward: { treatment: GlookoTreatment };
+  /** Run the glucose backfill repair again */
+  glucoseBackfill: Record<string, never>;
+}
+
+export type JobType = keyof JobPayloads;
+
+/**
+ * A queued job
+ */
+export interface Job<T extends JobType = JobType> {
+  id: string;
+  type: T;
+  payload: JobPayloads[T];
+  /** Not picked up before this time (ms) */
+  runAt: number;
+  /** Times the job has been picked up */
+  attempts: number;
+  createdAt: number;
+  lastError?: string;
+}
+
+/** Attempts before a job is moved to the dead-letter list */
+export const MAX_JOB_ATTEMPTS = 8;
+
+/** Delay before the first retry */
+export const JOB_RETRY_BASE_MS = 60 * 1000;
+
+/** Longest delay between attempts */
+export const JOB_RETRY_MAX_MS = 6 * 60 * 60 * 1000;
+
+/**
+ * Delay before attempt `attempts + 1`, doubling from a minute up to 6 hours
+ */
+export function jobRetryDelayMs(attempts: number): number {
+  return Math.min(JOB_RETRY_MAX_MS, JOB_RETRY_BASE_MS * 2 ** Math.max(0, attempts - 1));
+}
+
+/**
+ * The job as claimed by a worker: one more attempt, and not due again
+ * until its retry delay has passed. If the worker dies before finishing,
+ * the job simply comes due again.
+ */
+export function claimedJob<T extends JobType>(job: Job<T>, now: number): Job<T> {
+  const attempts = job.attempts + 1;
+  return { ...job, attempts, runAt: now + jobRetryDelayMs(attempts) };
+}
+
+/**
+ * Check whether a job has used up its attempts
+ */
+export function isExhausted(job: Job): boolean {
+  return job.attempts >= MAX_JOB_ATTEMPTS;
+}
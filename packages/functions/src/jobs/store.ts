@@ -0,0 +1,160 @@
+/**
+ * Job queue store
+ * Jobs are kept under JOB_QUEUE, sorted by when they are due; jobs that
+ * ran out of attempts move to JOB_DEAD for inspection.
+ */
+
+import { randomUUID } from "crypto";
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import {
+  DeleteCommand,
+  DynamoDBDocumentClient,
+  PutCommand,
+  QueryCommand,
+  TransactWriteCommand,
+} from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { Job, JobPayloads, JobType } from "./queue.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const QUEUE_PK = "JOB_QUEUE";
+const DEAD_PK = "JOB_DEAD";
+
+/** Dead jobs are kept for a month */
+const DEAD_TTL_SECONDS = 30 * 24 * 60 * 60;
+
+function sortKey(runAt: number, id: string): string {
+  return `RUN#${new Date(runAt).toISOString()}#${id}`;
+}
+
+/**
+ * Queue a job to run at `runAt` (default: as soon as the worker next runs)
+ */
+export async function enqueueJob<T extends JobType>(
+  type: T,
+  payload: JobPayloads[T],
+  runAt: number = Date.now()
+): Promise<Job<T>> {
+  const job: Job<T> = {
+    id: randomUUID().slice(0, 8),
+    type,
+    payload,
+    runAt,
+    attempts: 0,
+    createdAt: Date.now(),
+  };
+
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: { pk: QUEUE_PK, sk: sortKey(runAt, job.id), ...job },
+    })
+  );
+
+  return job;
+}
+
+/**
+ * Get jobs due at `now`, oldest first
+ */
+export async function getDueJobs(now: number, limit: number): Promise<Job[]> {
+  const result = await ddb.send(
+    new QueryCommand({
+      TableName: Resource.SignageTable.name,
+      KeyConditionExpression: "pk = :pk AND sk <= :until",
+      ExpressionAttributeValues: {
+        ":pk": QUEUE_PK,
+        // "~" sorts after every ID character, so jobs due exactly now are included
+        ":until": `RUN#${new Date(now).toISOString()}#~`,
+      },
+      Limit: limit,
+    })
+  );
+
+  return (result.Items ?? []).map((item) => {
+    const { pk: _pk, sk: _sk, ...job } = item;
+    return job as Job;
+  });
+}
+
+/**
+ * Move a job to its new due time. Fails with TransactionCanceledException
+ * if another worker moved it first, so only one worker runs a job.
+ */
+export async function rescheduleJob(previous: Job, next: Job): Promise<void> {
+  await ddb.send(
+    new TransactWriteCommand({
+      TransactItems: [
+        {
+          Delete: {
+            TableName: Resource.SignageTable.name,
+            Key: { pk: QUEUE_PK, sk: sortKey(previous.runAt, previous.id) },
+            ConditionExpression: "attempts = :attempts",
+            ExpressionAttributeValues: { ":attempts": previous.attempts },
+          },
+        },
+        {
+          Put: {
+            TableName: Resource.SignageTable.name,
+            Item: { pk: QUEUE_PK, sk: sortKey(next.runAt, next.id), ...next },
+          },
+        },
+      ],
+    })
+  );
+}
+
+/**
+ * Remove a finished job
+ */
+export async function completeJob(job: Job): Promise<void> {
+  await ddb.send(
+    new DeleteCommand({
+      TableName: Resource.SignageTable.name,
+      Key: { pk: QUEUE_PK, sk: sortKey(job.runAt, job.id) },
+    })
+  );
+}
+
+/**
+ * Save the error from a failed attempt; the job stays due at its retry time
+ */
+export async function recordJobError(job: Job, error: string): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: { pk: QUEUE_PK, sk: sortKey(job.runAt, job.id), ...job, lastError: error },
+    })
+  );
+}
+
+/**
+ * Move a job that ran out of attempts off the queue
+ */
+export async function deadLetterJob(job: Job): Promise<void> {
+  await ddb.send(
+    new TransactWriteCommand({
+      TransactItems: [
+        {
+          Delete: {
+            TableName: Resource.SignageTable.name,
+            Key: { pk: QUEUE_PK, sk: sortKey(job.runAt, job.id) },
+          },
+        },
+        {
+          Put: {
+            TableName: Resource.SignageTable.name,
+            Item: {
+              pk: DEAD_PK,
+              sk: `${new Date(job.createdAt).toISOString()}#${job.id}`,
+              ...job,
+              ttl: Math.floor(Date.now() / 1000) + DEAD_TTL_SECONDS,
+            },
+          },
+        },
+      ],
+    })
+  );
+}
@@ -0,0 +1,100 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+
+const { mockStore, mockHandlers } = vi.hoisted(() => ({
+  mockStore: {
+    getDueJobs: vi.fn(),
+    rescheduleJob: vi.fn(),
+    completeJob: vi.fn(),
+    recordJobError: vi.fn(),
+    deadLetterJob: vi.fn(),
+  },
+  mockHandlers: {
+    nightscoutForward: vi.fn(),
+    glucoseBackfill: vi.fn(),
+  },
+}));
+
+vi.mock("./store.js", () => mockStore);
+vi.mock("./handlers.js", () => ({ jobHandlers: mockHandlers }));
+
+import { runDueJobs } from "./worker";
+import { JOB_RETRY_BASE_MS, MAX_JOB_ATTEMPTS, type Job } from "./queue";
+
+const now = new Date("2026-03-02T12:00:00Z").getTime();
+const treatment = { timestamp: now, type: "carbs" as const, value: 30, source: "manual" };
+
+function forwardJob(attempts = 0): Job<"nightscoutForward"> {
+  return { id: "j1", type: "nightscoutForward", payload: { treatment }, runAt: now, attempts, createdAt: now };
+}
+
+describe("runDueJobs", () => {
+  beforeEach(() => {
+    for (const mock of [...Object.values(mockStore), ...Object.values(mockHandlers)]) {
+      mock.mockReset();
+    }
+    vi.spyOn(console, "error").mockImplementation(() => {});
+  });
+
+  it("claims, runs and removes a due job", async () => {
+    mockStore.getDueJobs.mockResolvedValue([forwardJob()]);
+
+    const summary = await runDueJobs(now);
+
+    expect(mockStore.rescheduleJob).toHaveBeenCalledWith(
+      forwardJob(),
+      expect.objectContaining({ attempts: 1, runAt: now + JOB_RETRY_BASE_MS })
+    );
+    expect(mockHandlers.nightscoutForward).toHaveBeenCalledWith({ treatment });
+    expect(mockStore.completeJob).toHaveBeenCalledWith(expect.objectContaining({ attempts: 1 }));
+    expect(summary.completed).toBe(1);
+  });
+
+  it("leaves a failed job queued for its retry with the error", async () => {
+    mockStore.getDueJobs.mockResolvedValue([forwardJob()]);
+    mockHandlers.nightscoutForward.mockRejectedValue(new Error("Nightscout returned 503"));
+
+    const summary = await runDueJobs(now);
+
+    expect(mockStore.completeJob).not.toHaveBeenCalled();
+    expect(mockStore.recordJobError).toHaveBeenCalledWith(
+      expect.objectContaining({ attempts: 1 }),
+      "Nightscout returned 503"
+    );
+    expect(summary.failed).toBe(1);
+  });
+
+  it("skips a job another worker already claimed", async () => {
+    mockStore.getDueJobs.mockResolvedValue([forwardJob()]);
+    mockStore.rescheduleJob.mockRejectedValue(
+      Object.assign(new Error("cancelled"), { name: "TransactionCanceledException" })
+    );
+
+    const summary = await runDueJobs(now);
+
+    expect(mockHandlers.nightscoutForward).not.toHaveBeenCalled();
+    expect(summary.skipped).toBe(1);
+  });
+
+  it("dead-letters a job that has used every attempt", async () => {
+    mockStore.getDueJobs.mockResolvedValue([forwardJob(MAX_JOB_ATTEMPTS)]);
+
+    const summary = await runDueJobs(now);
+
+    expect(mockStore.deadLetterJob).toHaveBeenCalledWith(forwardJob(MAX_JOB_ATTEMPTS));
+    expect(mockHandlers.nightscoutForward).not.toHaveBeenCalled();
+    expect(summary.deadLettered).toBe(1);
+  });
+
+  it("keeps going after a failed job", async () => {
+    mockStore.getDueJobs.mockResolvedValue([
+      forwardJob(),
+      { id: "j2", type: "glucoseBackfill", payload: {}, runAt: now, attempts: 0, createdAt: now },
+    ]);
+    mockHandlers.nightscoutForward.mockRejectedValue(new Error("down"));
+
+    const summary = await runDueJobs(now);
+
+    expect(mockHandlers.glucoseBackfill).toHaveBeenCalledOnce();
+    expect(summary).toEqual({ completed: 1, failed: 1, deadLettered: 0, skipped: 0 });
+  });
+});
@@ -0,0 +1,72 @@
+/**
+ * Job queue worker
+ * Runs due jobs every few minutes. Each job is claimed before it runs, so
+ * a crash mid-job just leaves it to be picked up again after its retry delay.
+ */
+
+import type { ScheduledHandler } from "aws-lambda";
+import { jobHandlers } from "./handlers.js";
+import { claimedJob, isExhausted, type Job, type JobType } from "./queue.js";
+import { completeJob, deadLetterJob, getDueJobs, recordJobError, rescheduleJob } from "./store.js";
+
+/** Jobs run per invocation; the rest wait for the next run */
+export const JOBS_PER_RUN = 20;
+
+export interface JobRunSummary {
+  completed: number;
+  failed: number;
+  deadLettered: number;
+  skipped: number;
+}
+
+async function runJob<T extends JobType>(job: Job<T>): Promise<void> {
+  const handler = jobHandlers[job.type] as (payload: Job<T>["payload"]) => Promise<void>;
+  await handler(job.payload);
+}
+
+/**
+ * Run every job due at `now`
+ */
+export async function runDueJobs(now: number = Date.now()): Promise<JobRunSummary> {
+  const summary: JobRunSummary = { completed: 0, failed: 0, deadLettered: 0, skipped: 0 };
+  const jobs = await getDueJobs(now, JOBS_PER_RUN);
+
+  for (const job of jobs) {
+    if (isExhausted(job)) {
+      console.error(`Job ${job.type} ${job.id} gave up after ${job.attempts} attempts: ${job.lastError}`);
+      await deadLetterJob(job);
+      summary.deadLettered++;
+      continue;
+    }
+
+    const claimed = claimedJob(job, now);
+    try {
+      await rescheduleJob(job, claimed);
+    } catch (error) {
+      // Another worker claimed it first
+      if ((error as { name?: string }).name !== "TransactionCanceledException") throw error;
+      summary.skipped++;
+      continue;
+    }
+
+    try {
+      await runJob(claimed);
+      await completeJob(claimed);
+      summary.completed++;
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      console.error(`Job ${job.type} ${job.id} attempt ${claimed.attempts} failed: ${message}`);
+      await recordJobError(claimed, message);
+      summary.failed++;
+    }
+  }
+
+  return summary;
+}
+
+export const scheduled: ScheduledHandler = async () => {
+  const summary = await runDueJobs();
+  console.log(
+    `Jobs: ${summary.completed} completed, ${summary.failed} failed, ${summary.deadLettered} dead-lettered, ${summary.skipped} skipped`
+  );
+};
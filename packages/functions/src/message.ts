@@ -11,6 +11,8 @@ import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
 import { DynamoDBDocumentClient, QueryCommand, GetCommand } from "@aws-sdk/lib-dynamodb";
 import { Resource } from "sst";
 import type { APIGatewayProxyWebsocketHandlerV2 } from "aws-lambda";
+import { getCachedFrame } from "./devices/frame-cache.js";
+import { deviceIdFor } from "./devices/stats.js";
 
 const ddbClient = new DynamoDBClient({});
 const ddb = DynamoDBDocumentClient.from(ddbClient);
@@ -43,34 +45,30 @@ export const handler: APIGatewayProxyWebsocketHandlerV2 = async (event) => {
     endpoint: `https://${domainName}`,
   });
 
-  // Handle client registration - send cached frame immediately
+  // Handle client registration - send the device's cached frame immediately
   if (message.type === "connect") {
     try {
-      const cachedFrame = await ddb.send(
+      const connection = await ddb.send(
         new GetCommand({
           TableName: Resource.SignageTable.name,
-          Key: { pk: "FRAME_CACHE", sk: "LATEST" },
+          Key: { pk: "CONNECTIONS", sk: connectionId },
         })
       );
+      const deviceId = connection.Item ? deviceIdFor(connection.Item) : null;
+      const cachedFrame = await getCachedFrame(deviceId);
 
-      if (cachedFrame.Item) {
+      if (cachedFrame) {
         await apiClient.send(
           new PostToConnectionCommand({
             ConnectionId: connectionId,
             Data: JSON.stringify({
               type: "frame",
-              payload: {
-                frame: {
-                  width: cachedFrame.Item.width,
-                  height: cachedFrame.Item.height,
-                  data: cachedFrame.Item.frameData,
-                },
-              },
+              payload: { frame: cachedFrame },
               timestamp: Date.now(),
             }),
           })
         );
-        console.log(`Sent cached frame to ${connectionId}`);
+        console.log(`Sent cached frame to ${connectionId}${deviceId ? ` (${deviceId})` : ""}`);
       }
     } catch (error) {
       console.log(`Could not send cached frame: ${error}`);
@@ -0,0 +1,144 @@
+import { describe, it, expect, afterEach } from "vitest";
+import { createServer, type AddressInfo, type Server } from "node:net";
+import {
+  encodeMqttConnect,
+  encodeMqttLength,
+  encodeMqttPublish,
+  parseMqttPackets,
+  publishMqtt,
+  takeRetainedMqtt,
+  type MqttMessage,
+} from "./client";
+
+/**
+ * Fake broker: answers CONNECT with CONNACK `code`, SUBSCRIBE with SUBACK
+ * and the `retained` messages, and records everything it receives
+ */
+function startBroker(
+  options: { code?: number; retained?: MqttMessage[] } = {}
+): Promise<{ server: Server; port: number; received: () => Buffer }> {
+  const { code = 0, retained = [] } = options;
+  let data = Buffer.alloc(0);
+  const server = createServer((socket) => {
+    let pending = Buffer.alloc(0);
+    socket.on("data", (chunk) => {
+      data = Buffer.concat([data, chunk]);
+      const { packets, rest } = parseMqttPackets(Buffer.concat([pending, chunk]));
+      pending = rest;
+      for (const { type } of packets) {
+        if (type === 1) socket.write(Buffer.from([0x20, 0x02, 0x00, code]));
+        if (type === 8) {
+          socket.write(Buffer.from([0x90, 0x03, 0x00, 0x01, 0x00]));
+          for (const message of retained) socket.write(encodeMqttPublish({ ...message, retain: true }));
+        }
+      }
+    });
+    socket.on("end", () => socket.end());
+  });
+  return new Promise((resolve) =>
+    server.listen(0, "127.0.0.1", () =>
+      resolve({ server, port: (server.address() as AddressInfo).port, received: () => data })
+    )
+  );
+}
+
+let server: Server | null = null;
+
+afterEach(() => {
+  server?.close();
+  server = null;
+});
+
+describe("encodeMqttLength", () => {
+  it("uses 7 bits per byte with a continuation bit", () => {
+    expect([...encodeMqttLength(0)]).toEqual([0]);
+    expect([...encodeMqttLength(127)]).toEqual([127]);
+    expect([...encodeMqttLength(128)]).toEqual([0x80, 0x01]);
+    expect([...encodeMqttLength(16_384)]).toEqual([0x80, 0x80, 0x01]);
+  });
+});
+
+describe("encodeMqttConnect", () => {
+  it("encodes a 3.1.1 clean-session CONNECT", () => {
+    const packet = encodeMqttConnect({ clientId: "c" });
+
+    expect([...packet]).toEqual([0x10, 13, 0, 4, 77, 81, 84, 84, 4, 0x02, 0, 30, 0, 1, 99]);
+  });
+
+  it("flags and appends credentials", () => {
+    const packet = encodeMqttConnect({ clientId: "c", username: "u", password: "p" });
+
+    expect(packet[9]).toBe(0xc2);
+    expect(packet.subarray(-6).toString("latin1")).toBe("\0\u0001u\0\u0001p");
+  });
+});
+
+describe("encodeMqttPublish", () => {
+  it("encodes a QoS 0 PUBLISH with the retain flag", () => {
+    const packet = encodeMqttPublish({ topic: "a/b", payload: "hi", retain: true });
+
+    expect([...packet]).toEqual([0x31, 7, 0, 3, 97, 47, 98, 104, 105]);
+  });
+});
+
+describe("parseMqttPackets", () => {
+  it("splits whole packets and keeps a partial one", () => {
+    const publish = encodeMqttPublish({ topic: "t", payload: "x" });
+    const buffer = Buffer.concat([Buffer.from([0x20, 0x02, 0x00, 0x00]), publish, publish.subarray(0, 3)]);
+
+    const { packets, rest } = parseMqttPackets(buffer);
+
+    expect(packets.map((p) => p.type)).toEqual([2, 3]);
+    expect(rest).toHaveLength(3);
+  });
+});
+
+describe("publishMqtt", () => {
+  it("connects, publishes and disconnects", async () => {
+    const broker = await startBroker();
+    server = broker.server;
+
+    await publishMqtt(`mqtt://u:p@127.0.0.1:${broker.port}`, [{ topic: "signage/glucose", payload: "{}" }], {
+      clientId: "test",
+    });
+
+    const expected = Buffer.concat([
+      encodeMqttConnect({ clientId: "test", username: "u", password: "p" }),
+      encodeMqttPublish({ topic: "signage/glucose", payload: "{}" }),
+      Buffer.from([0xe0, 0x00]),
+    ]);
+    await expect.poll(() => broker.received().equals(expected)).toBe(true);
+  });
+
+  it("fails when the broker refuses the connection", async () => {
+    const broker = await startBroker({ code: 4 });
+    server = broker.server;
+
+    await expect(publishMqtt(`mqtt://127.0.0.1:${broker.port}`, [])).rejects.toThrow("bad user name or password");
+  });
+
+  it("rejects other URL schemes", async () => {
+    await expect(publishMqtt("http://broker.example.com", [])).rejects.toThrow("Unsupported MQTT URL scheme");
+  });
+});
+
+describe("takeRetainedMqtt", () => {
+  it("returns the retained messages and clears them", async () => {
+    const command = { topic: "signage/screen/set", payload: "OFF" };
+    const broker = await startBroker({ retained: [command] });
+    server = broker.server;
+
+    const messages = await takeRetainedMqtt(`mqtt://127.0.0.1:${broker.port}`, "signage/+/set", { waitMs: 20 });
+
+    expect(messages).toEqual([command]);
+    const clear = encodeMqttPublish({ topic: command.topic, payload: "", retain: true });
+    await expect.poll(() => broker.received().includes(clear)).toBe(true);
+  });
+
+  it("returns nothing when no commands are waiting", async () => {
+    const broker = await startBroker();
+    server = broker.server;
+
+    await expect(takeRetainedMqtt(`mqtt://127.0.0.1:${broker.port}`, "signage/+/set", { waitMs: 20 })).resolves.toEqual([]);
+  });
+});
@@ -0,0 +1,107 @@
+import { describe, it, expect } from "vitest";
+import {
+  applyHomeAssistantCommands,
+  buildHomeAssistantDiscovery,
+  buildHomeAssistantState,
+  homeAssistantCommandFilter,
+  parseHomeAssistantCommands,
+} from "./home-assistant";
+import type { DisplayConfig } from "../display/config-store";
+
+const TZ = "America/Los_Angeles";
+// 14:00 Pacific
+const now = Date.UTC(2026, 5, 1, 21, 0, 0);
+
+describe("buildHomeAssistantDiscovery", () => {
+  it("describes one device with sensors and switches", () => {
+    const messages = buildHomeAssistantDiscovery("home/signage");
+
+    expect(messages.map((m) => m.topic)).toEqual([
+      "homeassistant/sensor/home_signage/glucose/config",
+      "homeassistant/sensor/home_signage/trend/config",
+      "homeassistant/sensor/home_signage/alerts/config",
+      "homeassistant/binary_sensor/home_signage/reachable/config",
+      "homeassistant/switch/home_signage/screen/config",
+      "homeassistant/switch/home_signage/night_mode/config",
+    ]);
+    expect(messages.every((m) => m.retain)).toBe(true);
+    expect(JSON.parse(messages[4].payload)).toMatchObject({
+      state_topic: "home/signage/screen",
+      command_topic: "home/signage/screen/set",
+      retain: true,
+      device: { identifiers: ["home_signage"] },
+    });
+  });
+});
+
+describe("buildHomeAssistantState", () => {
+  it("publishes ON/OFF states", () => {
+    const messages = buildHomeAssistantState("signage", { screenOn: true, nightMode: false, reachable: true });
+
+    expect(messages.map((m) => [m.topic, m.payload])).toEqual([
+      ["signage/reachable", "ON"],
+      ["signage/screen", "ON"],
+      ["signage/night_mode", "OFF"],
+    ]);
+  });
+});
+
+describe("parseHomeAssistantCommands", () => {
+  it("reads the last ON/OFF per switch and ignores the rest", () => {
+    const commands = parseHomeAssistantCommands("signage", [
+      { topic: "signage/screen/set", payload: "OFF" },
+      { topic: "signage/night_mode/set", payload: "ON" },
+      { topic: "signage/screen/set", payload: "ON" },
+      { topic: "signage/night_mode/set", payload: "maybe" },
+      { topic: "signage/unknown/set", payload: "ON" },
+      { topic: "other/screen/set", payload: "OFF" },
+    ]);
+
+    expect(commands).toEqual({ screenOn: true, nightMode: true });
+  });
+
+  it("matches the command filter's topics", () => {
+    expect(homeAssistantCommandFilter("signage")).toBe("signage/+/set");
+  });
+});
+
+describe("applyHomeAssistantCommands", () => {
+  const config: DisplayConfig = { activeLayout: "day" };
+
+  it("sets the away flag from the screen switch", () => {
+    expect(applyHomeAssistantCommands(config, { screenOn: false }, now, TZ)).toMatchObject({ away: true });
+    expect(applyHomeAssistantCommands({ ...config, away: true }, { screenOn: true }, now, TZ)).toMatchObject({
+      away: false,
+    });
+  });
+
+  it("switches to the night layout and back", () => {
+    const night = applyHomeAssistantCommands(config, { nightMode: true }, now, TZ);
+    expect(night).toMatchObject({ activeLayout: "night", activeLayoutSetAt: now });
+
+    expect(applyHomeAssistantCommands(night!, { nightMode: false }, now + 1, TZ)).toMatchObject({
+      activeLayout: "day",
+      activeLayoutSetAt: now + 1,
+    });
+  });
+
+  it("overrides a scheduled night with the manual layout", () => {
+    const scheduled: DisplayConfig = {
+      activeLayout: "glucose-focus",
+      activeLayoutSetAt: 0,
+      layoutSchedule: [
+        { start: "08:00", layout: "glucose-focus" },
+        { start: "12:00", layout: "night" },
+      ],
+    };
+
+    expect(applyHomeAssistantCommands(scheduled, { nightMode: false }, now, TZ)).toMatchObject({
+      activeLayout: "glucose-focus",
+      activeLayoutSetAt: now,
+    });
+  });
+
+  it("returns null when nothing changes", () => {
+    expect(applyHomeAssistantCommands(config, { screenOn: true, nightMode: false }, now, TZ)).toBeNull();
+  });
+});
@@ -0,0 +1,165 @@
+/**
+ * Home Assistant integration over MQTT
+ *
+ * Discovery configs make the display show up as one device with:
+ *
+ *   sensor         Glucose, Glucose trend, Glucose alerts   (from <prefix>/glucose, /alerts)
+ *   binary_sensor  Display reachable                        <prefix>/reachable
+ *   switch         Screen                                   <prefix>/screen, /screen/set
+ *   switch         Night mode                               <prefix>/night_mode, /night_mode/set
+ *
+ * The compositor runs once a minute and can't hold a subscription open,
+ * so the switches send retained commands. Each run takes them (clearing
+ * them), applies them to the display config, and publishes the state it
+ * ended up with, which is what Home Assistant shows.
+ */
+
+import { DEFAULT_LAYOUT_NAME, resolveLayout } from "../rendering/layouts.js";
+import type { DisplayConfig } from "../display/config-store.js";
+import type { MqttMessage } from "./client.js";
+
+/** Display state published for the binary sensor and switches */
+export interface HomeAssistantState {
+  /** Screen switch: on unless the "away" flag is set */
+  screenOn: boolean;
+  /** Night layout in effect (manually or by schedule) */
+  nightMode: boolean;
+  /** The last run reached at least one display */
+  reachable: boolean;
+}
+
+/** Switch commands taken from the broker; absent when not sent */
+export interface HomeAssistantCommands {
+  screenOn?: boolean;
+  nightMode?: boolean;
+}
+
+/** Switch name in topics -> command field */
+const SWITCHES = {
+  screen: "screenOn",
+  night_mode: "nightMode",
+} as const satisfies Record<string, keyof HomeAssistantCommands>;
+
+const NIGHT_LAYOUT = "night";
+
+/**
+ * Topic filter matching every switch's command topic
+ */
+export function homeAssistantCommandFilter(prefix: string): string {
+  return `${prefix}/+/set`;
+}
+
+/**
+ * Discovery messages for every entity
+ */
+export function buildHomeAssistantDiscovery(prefix: string): MqttMessage[] {
+  const id = prefix.replace(/[^a-zA-Z0-9_-]/g, "_");
+  const device = { identifiers: [id], name: "Signage", manufacturer: "signage" };
+  const entity = (component: string, key: string, config: Record<string, unknown>): MqttMessage => ({
+    topic: `homeassistant/${component}/${id}/${key}/config`,
+    payload: JSON.stringify({ unique_id: `${id}_${key}`, device, ...config }),
+    retain: true,
+  });
+  const toggle = (key: keyof typeof SWITCHES, name: string, icon: string) =>
+    entity("switch", key, {
+      name,
+      state_topic: `${prefix}/${key}`,
+      command_topic: `${prefix}/${key}/set`,
+      // Retained, so the next run picks the command up
+      retain: true,
+      icon,
+    });
+
+  return [
+    entity("sensor", "glucose", {
+      name: "Glucose",
+      state_topic: `${prefix}/glucose`,
+      value_template: "{{ value_json.glucose if value_json else None }}",
+      json_attributes_topic: `${prefix}/glucose`,
+      unit_of_measurement: "mg/dL",
+      state_class: "measurement",
+      icon: "mdi:water",
+    }),
+    entity("sensor", "trend", {
+      name: "Glucose trend",
+      state_topic: `${prefix}/glucose`,
+      value_template: "{{ value_json.trend if value_json else None }}",
+      icon: "mdi:trending-up",
+    }),
+    entity("sensor", "alerts", {
+      name: "Glucose alerts",
+      state_topic: `${prefix}/alerts`,
+      value_template: "{{ value_json | count }}",
+      icon: "mdi:alert",
+    }),
+    entity("binary_sensor", "reachable", {
+      name: "Display reachable",
+      state_topic: `${prefix}/reachable`,
+      device_class: "connectivity",
+    }),
+    toggle("screen", "Screen", "mdi:monitor"),
+    toggle("night_mode", "Night mode", "mdi:weather-night"),
+  ];
+}
+
+/**
+ * State messages for the binary sensor and switches
+ */
+export function buildHomeAssistantState(prefix: string, state: HomeAssistantState): MqttMessage[] {
+  const onOff = (value: boolean) => (value ? "ON" : "OFF");
+  return [
+    { topic: `${prefix}/reachable`, payload: onOff(state.reachable), retain: true },
+    { topic: `${prefix}/screen`, payload: onOff(state.screenOn), retain: true },
+    { topic: `${prefix}/night_mode`, payload: onOff(state.nightMode), retain: true },
+  ];
+}
+
+/**
+ * Read switch commands from taken messages; the last one per switch wins,
+ * and anything that isn't ON or OFF is ignored
+ */
+export function parseHomeAssistantCommands(prefix: string, messages: MqttMessage[]): HomeAssistantCommands {
+  const commands: HomeAssistantCommands = {};
+  for (const { topic, payload } of messages) {
+    const key = topic.startsWith(`${prefix}/`) && topic.endsWith("/set") ? topic.slice(prefix.length + 1, -4) : "";
+    if (!Object.hasOwn(SWITCHES, key) || (payload !== "ON" && payload !== "OFF")) continue;
+    commands[SWITCHES[key as keyof typeof SWITCHES]] = payload === "ON";
+  }
+  return commands;
+}
+
+/**
+ * Apply switch commands to the display config. Returns the new config, or
+ * null when nothing changes.
+ *
+ * Night mode switches the manual layout, which holds until the next
+ * scheduled change like any manual switch. Turning it off goes back to
+ * the default layout only if night was the manual choice.
+ */
+export function applyHomeAssistantCommands(
+  config: DisplayConfig,
+  commands: HomeAssistantCommands,
+  now: number,
+  timezone: string
+): DisplayConfig | null {
+  let next = config;
+  if (commands.screenOn !== undefined && commands.screenOn === Boolean(config.away)) {
+    next = { ...next, away: !commands.screenOn };
+  }
+  if (commands.nightMode !== undefined && commands.nightMode !== isNightMode(config, now, timezone)) {
+    const activeLayout = commands.nightMode
+      ? NIGHT_LAYOUT
+      : config.activeLayout === NIGHT_LAYOUT
+        ? DEFAULT_LAYOUT_NAME
+        : config.activeLayout;
+    next = { ...next, activeLayout, activeLayoutSetAt: now };
+  }
+  return next === config ? null : next;
+}
+
+/**
+ * Whether the night layout is in effect
+ */
+export function isNightMode(config: DisplayConfig, now: number, timezone: string): boolean {
+  return resolveLayout(config, now, timezone).name === NIGHT_LAYOUT;
+}
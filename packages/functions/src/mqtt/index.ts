@@ -0,0 +1,7 @@
+/**
+ * MQTT publishing of readings, alerts and frames
+ */
+
+export * from "./client.js";
+export * from "./messages.js";
+export * from "./home-assistant.js";
@@ -0,0 +1,41 @@
+import { describe, it, expect } from "vitest";
+import { createSolidFrame } from "@signage/core";
+import { buildMqttMessages, mqttPrefixFromUrl } from "./messages";
+
+const reading = {
+  glucose: 123,
+  trend: "Flat",
+  delta: 4,
+  timestamp: 1_700_000_000_000,
+  rangeStatus: "normal" as const,
+  isStale: false,
+};
+
+describe("buildMqttMessages", () => {
+  it("publishes the reading, alerts and frame as retained state", () => {
+    const messages = buildMqttMessages("signage", {
+      bloodSugar: reading,
+      alerts: [],
+      frame: createSolidFrame(2, 2),
+    });
+
+    expect(messages.map((m) => m.topic)).toEqual(["signage/glucose", "signage/alerts", "signage/frame"]);
+    expect(messages.every((m) => m.retain)).toBe(true);
+    expect(JSON.parse(messages[0].payload)).toEqual(reading);
+    expect(JSON.parse(messages[1].payload)).toEqual([]);
+    expect(JSON.parse(messages[2].payload)).toEqual({ width: 2, height: 2, data: "AAAAAAAAAAAAAAAA" });
+  });
+
+  it("publishes null without a reading", () => {
+    const [glucose] = buildMqttMessages("signage", { bloodSugar: null, alerts: [], frame: createSolidFrame(1, 1) });
+
+    expect(glucose.payload).toBe("null");
+  });
+});
+
+describe("mqttPrefixFromUrl", () => {
+  it("uses the URL path, or the default", () => {
+    expect(mqttPrefixFromUrl("mqtts://u:p@broker.example.com:8883/home/signage/")).toBe("home/signage");
+    expect(mqttPrefixFromUrl("mqtt://broker.example.com")).toBe("signage");
+  });
+});
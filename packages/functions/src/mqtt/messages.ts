@@ -0,0 +1,69 @@
+/**
+ * MQTT messages for a composed frame
+ *
+ * Topics under a configurable prefix (default "signage"), all retained so
+ * a subscriber that connects between runs gets the current state:
+ *
+ *   <prefix>/glucose   { glucose, trend, delta, timestamp, rangeStatus, isStale }
+ *                      or null without a reading
+ *   <prefix>/alerts    [{ type, severity, title, detail, raisedAt }]
+ *   <prefix>/frame     { width, height, data } with base64 RGB data
+ *
+ * Home Assistant reads these through the discovery configs in
+ * home-assistant.ts.
+ */
+
+import { encodeFrameToBase64, type Frame } from "@signage/core";
+import type { BloodSugarDisplayData } from "../rendering/index.js";
+import type { GlucoseAlert } from "../alerts/types.js";
+import type { MqttMessage } from "./client.js";
+
+export const DEFAULT_MQTT_PREFIX = "signage";
+
+export interface MqttSnapshot {
+  bloodSugar: BloodSugarDisplayData | null;
+  alerts: GlucoseAlert[];
+  frame: Frame;
+}
+
+/**
+ * State messages for one compositor run
+ */
+export function buildMqttMessages(prefix: string, snapshot: MqttSnapshot): MqttMessage[] {
+  const { bloodSugar, alerts, frame } = snapshot;
+  return [
+    {
+      topic: `${prefix}/glucose`,
+      payload: JSON.stringify(
+        bloodSugar && {
+          glucose: bloodSugar.glucose,
+          trend: bloodSugar.trend,
+          delta: bloodSugar.delta,
+          timestamp: bloodSugar.timestamp,
+          rangeStatus: bloodSugar.rangeStatus,
+          isStale: bloodSugar.isStale,
+        }
+      ),
+      retain: true,
+    },
+    {
+      topic: `${prefix}/alerts`,
+      payload: JSON.stringify(alerts),
+      retain: true,
+    },
+    {
+      topic: `${prefix}/frame`,
+      payload: JSON.stringify({ width: frame.width, height: frame.height, data: encodeFrameToBase64(frame) }),
+      retain: true,
+    },
+  ];
+}
+
+/**
+ * Topic prefix from the broker URL's path ("mqtt://host/home/signage" ->
+ * "home/signage"), or the default
+ */
+export function mqttPrefixFromUrl(url: string): string {
+  const path = decodeURIComponent(new URL(url).pathname).replace(/^\/+|\/+$/g, "");
+  return path || DEFAULT_MQTT_PREFIX;
+}
@@ -0,0 +1,42 @@
+/**
+ * Network measurement history
+ *
+ * Samples are kept as a widget time series (see widgets/history-store.ts)
+ * for the few hours the network page's sparkline covers.
+ */
+
+import type { TimeSeriesPoint, WidgetHistoryConfig } from "../widgets/types.js";
+import type { NetworkSample } from "./types.js";
+
+/** Widget id samples are stored under */
+export const NETWORK_WIDGET_ID = "network";
+
+/** Hours of samples kept and drawn */
+export const NETWORK_HISTORY_HOURS = 3;
+
+export const NETWORK_HISTORY_CONFIG: WidgetHistoryConfig = {
+  enabled: true,
+  retentionHours: NETWORK_HISTORY_HOURS,
+  backfillDepthHours: 0,
+  backfillThresholdMinutes: 0,
+  dedupeWindowMinutes: 1,
+  storageType: "time-series",
+};
+
+/** Stored value of one sample */
+export type NetworkHistoryValue = Omit<NetworkSample, "timestamp">;
+
+/**
+ * A sample as a time-series point
+ */
+export function sampleToTimeSeriesPoint(sample: NetworkSample): TimeSeriesPoint<NetworkHistoryValue> {
+  const { timestamp, ...value } = sample;
+  return { timestamp, value };
+}
+
+/**
+ * A stored point back as a sample
+ */
+export function timeSeriesPointToSample(point: TimeSeriesPoint<NetworkHistoryValue>): NetworkSample {
+  return { timestamp: point.timestamp, ...point.value };
+}
@@ -0,0 +1,10 @@
+/**
+ * Network monitor, for the local server
+ *
+ * The probe and sample helpers; samples are kept in memory there rather
+ * than in the history table.
+ */
+
+export * from "./probe.js";
+export * from "./history.js";
+export * from "./types.js";
@@ -0,0 +1,139 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import {
+  isDownloadDue,
+  isHost,
+  lastDownloadAt,
+  measureDownload,
+  measurePing,
+  takeNetworkSample,
+  validateNetworkMonitorSettings,
+  DOWNLOAD_INTERVAL_MS,
+} from "./probe";
+
+const NOW = Date.UTC(2026, 9, 16, 15, 0);
+
+describe("isHost", () => {
+  it("accepts host names and IPv4 addresses with an optional port", () => {
+    expect(isHost("example.com")).toBe(true);
+    expect(isHost("10.0.0.1")).toBe(true);
+    expect(isHost("router.local:8443")).toBe(true);
+  });
+
+  it("rejects URLs, bad ports and anything else", () => {
+    expect(isHost("https://example.com")).toBe(false);
+    expect(isHost("example.com:0")).toBe(false);
+    expect(isHost("example.com:99999")).toBe(false);
+    expect(isHost("-bad.example.com")).toBe(false);
+    expect(isHost(42)).toBe(false);
+  });
+});
+
+describe("validateNetworkMonitorSettings", () => {
+  it("accepts a host with an optional download URL, and null", () => {
+    expect(validateNetworkMonitorSettings({ host: "example.com" })).toBeNull();
+    expect(
+      validateNetworkMonitorSettings({ host: "example.com", downloadUrl: "https://example.com/1mb.bin" })
+    ).toBeNull();
+    expect(validateNetworkMonitorSettings(null)).toBeNull();
+  });
+
+  it("rejects a bad host, a bad URL and other fields", () => {
+    expect(validateNetworkMonitorSettings({ host: "not a host" })).toMatch(/host/);
+    expect(validateNetworkMonitorSettings({ host: "example.com", downloadUrl: "ftp://example.com/x" })).toMatch(
+      /downloadUrl/
+    );
+    expect(validateNetworkMonitorSettings({ host: "example.com", interval: 5 })).toMatch(/unknown/);
+    expect(validateNetworkMonitorSettings("example.com")).toMatch(/object/);
+  });
+});
+
+describe("isDownloadDue", () => {
+  it("is due when it never ran or the interval has passed", () => {
+    expect(isDownloadDue(null, NOW)).toBe(true);
+    expect(isDownloadDue(NOW - DOWNLOAD_INTERVAL_MS, NOW)).toBe(true);
+    expect(isDownloadDue(NOW - 60_000, NOW)).toBe(false);
+  });
+});
+
+describe("lastDownloadAt", () => {
+  it("finds the newest sample that downloaded, failed or not", () => {
+    expect(
+      lastDownloadAt([
+        { timestamp: 1, pingMs: 20, downloadMbps: 50 },
+        { timestamp: 2, pingMs: 20, downloadMbps: null },
+        { timestamp: 3, pingMs: 20 },
+      ])
+    ).toBe(2);
+    expect(lastDownloadAt([{ timestamp: 1, pingMs: 20 }])).toBeNull();
+  });
+});
+
+describe("probes", () => {
+  const originalFetch = global.fetch;
+  const mockFetch = vi.fn();
+
+  beforeEach(() => {
+    mockFetch.mockReset();
+    global.fetch = mockFetch as unknown as typeof fetch;
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  function bodyOf(chunks: number[]) {
+    let i = 0;
+    return {
+      getReader: () => ({
+        read: async () =>
+          i < chunks.length ? { done: false, value: new Uint8Array(chunks[i++]) } : { done: true, value: undefined },
+        cancel: async () => {},
+      }),
+    };
+  }
+
+  it("pings with HEAD requests to the host", async () => {
+    mockFetch.mockResolvedValue({ ok: true, status: 200, body: null });
+
+    const ping = await measurePing("example.com");
+
+    expect(ping).toEqual(expect.any(Number));
+    expect(mockFetch).toHaveBeenCalledTimes(3);
+    expect(mockFetch).toHaveBeenCalledWith("https://example.com/", expect.objectContaining({ method: "HEAD" }));
+  });
+
+  it("counts any response as an answer, and no response as lost", async () => {
+    mockFetch.mockResolvedValue({ ok: false, status: 404, body: null });
+    expect(await measurePing("example.com")).toEqual(expect.any(Number));
+
+    mockFetch.mockReset();
+    mockFetch.mockRejectedValue(new Error("timeout"));
+    expect(await measurePing("example.com")).toBeNull();
+    expect(mockFetch).toHaveBeenCalledTimes(1);
+  });
+
+  it("measures a download's throughput", async () => {
+    mockFetch.mockResolvedValue({ ok: true, status: 200, body: bodyOf([500_000, 500_000]) });
+
+    expect(await measureDownload("https://example.com/1mb.bin")).toBeGreaterThan(0);
+  });
+
+  it("returns null for a failed download", async () => {
+    mockFetch.mockResolvedValue({ ok: false, status: 500, body: null });
+
+    expect(await measureDownload("https://example.com/1mb.bin")).toBeNull();
+  });
+
+  it("only downloads when it's due", async () => {
+    mockFetch.mockResolvedValue({ ok: true, status: 200, body: bodyOf([1000]) });
+    const settings = { host: "example.com", downloadUrl: "https://example.com/1mb.bin" };
+
+    const recent = await takeNetworkSample(settings, [{ timestamp: NOW - 60_000, pingMs: 20, downloadMbps: 50 }], NOW);
+    expect(recent.downloadMbps).toBeUndefined();
+    expect(mockFetch).toHaveBeenCalledTimes(3);
+
+    const due = await takeNetworkSample(settings, [], NOW);
+    expect(due.timestamp).toBe(NOW);
+    expect(due.downloadMbps).not.toBeUndefined();
+  });
+});
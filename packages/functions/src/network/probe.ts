@@ -0,0 +1,153 @@
+/**
+ * Network probe
+ *
+ * Times HTTPS round trips to a host and, now and then, a small download.
+ * There's no ICMP from a Lambda (or without privileges locally), so a
+ * "ping" is a HEAD request: the quickest of a few on one kept-alive
+ * connection, so DNS and the TLS handshake don't count. Any response, even
+ * an error status, means the host answered.
+ */
+
+import type { NetworkMonitorSettings, NetworkSample } from "./types.js";
+
+/** Requests per ping; the first also sets up the connection */
+const PING_ATTEMPTS = 3;
+
+/** A ping request that takes longer than this counts as lost */
+export const PING_TIMEOUT_MS = 3000;
+
+/** How often the download runs */
+export const DOWNLOAD_INTERVAL_MS = 15 * 60 * 1000;
+
+/** Downloads stop after this much, so a big file can't eat the run */
+export const MAX_DOWNLOAD_BYTES = 5 * 1024 * 1024;
+
+const DOWNLOAD_TIMEOUT_MS = 10_000;
+
+const HOST_NAME = /^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$/i;
+
+/**
+ * Check that a value is a host name or IPv4 address, with an optional port
+ */
+export function isHost(value: unknown): value is string {
+  if (typeof value !== "string") return false;
+  const [name, port, ...rest] = value.split(":");
+  if (rest.length > 0 || name.length > 253 || !HOST_NAME.test(name)) return false;
+  return port === undefined || (/^\d{1,5}$/.test(port) && Number(port) >= 1 && Number(port) <= 65535);
+}
+
+/**
+ * Validate network monitor settings from a request body (null clears them).
+ * Returns an error message, or null if valid.
+ */
+export function validateNetworkMonitorSettings(value: unknown): string | null {
+  if (value === null) return null;
+  if (typeof value !== "object" || Array.isArray(value)) {
+    return "networkMonitor must be an object or null";
+  }
+  const { host, downloadUrl, ...rest } = value as Record<string, unknown>;
+  if (Object.keys(rest).length > 0) {
+    return `unknown networkMonitor fields: ${Object.keys(rest).join(", ")}`;
+  }
+  if (!isHost(host)) {
+    return 'networkMonitor host must be a host name like "example.com", with an optional port';
+  }
+  if (downloadUrl !== undefined && !isHttpUrl(downloadUrl)) {
+    return "networkMonitor downloadUrl must be an http(s) URL";
+  }
+  return null;
+}
+
+function isHttpUrl(value: unknown): boolean {
+  if (typeof value !== "string") return false;
+  try {
+    const url = new URL(value);
+    return url.protocol === "https:" || url.protocol === "http:";
+  } catch {
+    return false;
+  }
+}
+
+/**
+ * Time a round trip to a host: the quickest of a few HEAD requests, or null
+ * if it doesn't answer
+ */
+export async function measurePing(host: string): Promise<number | null> {
+  let best: number | null = null;
+  for (let i = 0; i < PING_ATTEMPTS; i++) {
+    const start = performance.now();
+    try {
+      const response = await fetch(`https://${host}/`, {
+        method: "HEAD",
+        signal: AbortSignal.timeout(PING_TIMEOUT_MS),
+      });
+      await response.body?.cancel();
+    } catch {
+      // A lost request ends the ping; one that never connects won't on a retry either
+      break;
+    }
+    const elapsed = Math.round(performance.now() - start);
+    best = best === null ? elapsed : Math.min(best, elapsed);
+  }
+  return best;
+}
+
+/**
+ * Download (up to MAX_DOWNLOAD_BYTES of) a URL and work out the throughput
+ * in megabits per second. Returns null if the download fails.
+ */
+export async function measureDownload(url: string): Promise<number | null> {
+  const start = performance.now();
+  let bytes = 0;
+  try {
+    const response = await fetch(url, { signal: AbortSignal.timeout(DOWNLOAD_TIMEOUT_MS) });
+    if (!response.ok || !response.body) return null;
+
+    const reader = response.body.getReader();
+    while (bytes < MAX_DOWNLOAD_BYTES) {
+      const { done, value } = await reader.read();
+      if (done) break;
+      bytes += value.byteLength;
+    }
+    await reader.cancel();
+  } catch {
+    return null;
+  }
+
+  const seconds = (performance.now() - start) / 1000;
+  if (bytes === 0 || seconds <= 0) return null;
+  return Math.round(((bytes * 8) / seconds / 1_000_000) * 10) / 10;
+}
+
+/**
+ * Whether the download is due, given when it last ran (null if never)
+ */
+export function isDownloadDue(lastDownloadAt: number | null, now: number = Date.now()): boolean {
+  return lastDownloadAt === null || now - lastDownloadAt >= DOWNLOAD_INTERVAL_MS;
+}
+
+/**
+ * When the download last ran (whether or not it worked), or null
+ */
+export function lastDownloadAt(samples: NetworkSample[]): number | null {
+  for (let i = samples.length - 1; i >= 0; i--) {
+    if (samples[i].downloadMbps !== undefined) return samples[i].timestamp;
+  }
+  return null;
+}
+
+/**
+ * Take a measurement: a ping every time, plus the download when it's due
+ */
+export async function takeNetworkSample(
+  settings: NetworkMonitorSettings,
+  previous: NetworkSample[],
+  now: number = Date.now()
+): Promise<NetworkSample> {
+  const pingMs = await measurePing(settings.host);
+  if (!settings.downloadUrl || !isDownloadDue(lastDownloadAt(previous), now)) {
+    return { timestamp: now, pingMs };
+  }
+  // A failed download is kept as null, so it waits for the interval like a good one
+  return { timestamp: now, pingMs, downloadMbps: await measureDownload(settings.downloadUrl) };
+}
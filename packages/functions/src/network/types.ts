@@ -0,0 +1,34 @@
+/**
+ * Network monitor types
+ */
+
+/**
+ * What the network monitor measures, stored in the display config
+ */
+export interface NetworkMonitorSettings {
+  /** Host to time round trips to, optionally with a port ("example.com", "10.0.0.1:8080") */
+  host: string;
+  /** Small file to download now and then for a throughput figure (default: none) */
+  downloadUrl?: string;
+}
+
+/**
+ * One measurement
+ */
+export interface NetworkSample {
+  /** When it was taken (ms) */
+  timestamp: number;
+  /** Round trip in ms, or null when the host didn't answer */
+  pingMs: number | null;
+  /** Download throughput in megabits per second on runs that downloaded (null if it failed) */
+  downloadMbps?: number | null;
+}
+
+/**
+ * Everything the network page draws
+ */
+export interface NetworkDisplayData {
+  host: string;
+  /** Measurements over the past few hours, oldest first */
+  samples: NetworkSample[];
+}
@@ -0,0 +1,100 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockGetNotifications, mockSaveNotification, mockDeleteNotification } = vi.hoisted(() => ({
+  mockGetNotifications: vi.fn(),
+  mockSaveNotification: vi.fn(),
+  mockDeleteNotification: vi.fn(),
+}));
+
+vi.mock("./store.js", () => ({
+  getNotifications: mockGetNotifications,
+  saveNotification: mockSaveNotification,
+  deleteNotification: mockDeleteNotification,
+}));
+
+vi.mock("sst", () => ({
+  Resource: { SignageTable: { name: "test-table" } },
+}));
+
+import { handler } from "./api";
+import { MAX_QUEUED_NOTIFICATIONS } from "./queue";
+import type { DisplayNotification } from "./types";
+
+function createEvent(method: string, body?: unknown, query?: Record<string, string>): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    body: body === undefined ? undefined : JSON.stringify(body),
+    queryStringParameters: query,
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+function queued(id: string): DisplayNotification {
+  return { id, text: id, priority: "normal", seconds: 10, createdAt: Date.now() };
+}
+
+describe("notification API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGetNotifications.mockResolvedValue([]);
+    mockSaveNotification.mockResolvedValue(undefined);
+    mockDeleteNotification.mockResolvedValue(undefined);
+  });
+
+  it("queues a notification on POST", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { text: "Laundry done", priority: "high" }));
+
+    expect(statusCode).toBe(200);
+    expect(mockSaveNotification).toHaveBeenCalledWith(
+      expect.objectContaining({ text: "Laundry done", priority: "high", seconds: 10 })
+    );
+    expect(body).toMatchObject({ notification: { text: "Laundry done" }, queued: 1 });
+  });
+
+  it("rejects invalid requests", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { text: "" }));
+
+    expect(statusCode).toBe(400);
+    expect(mockSaveNotification).not.toHaveBeenCalled();
+  });
+
+  it("returns 429 when the queue is full", async () => {
+    mockGetNotifications.mockResolvedValue(
+      Array.from({ length: MAX_QUEUED_NOTIFICATIONS }, (_, i) => queued(`n${i}`))
+    );
+
+    const { statusCode } = await invoke(createEvent("POST", { text: "One more" }));
+
+    expect(statusCode).toBe(429);
+    expect(mockSaveNotification).not.toHaveBeenCalled();
+  });
+
+  it("lists the queue on GET, showing one first", async () => {
+    mockGetNotifications.mockResolvedValue([queued("a"), { ...queued("b"), priority: "high" }]);
+
+    const { statusCode, body } = await invoke(createEvent("GET"));
+
+    expect(statusCode).toBe(200);
+    expect(body.notifications.map((n: DisplayNotification) => n.id)).toEqual(["b", "a"]);
+  });
+
+  it("drops one notification, or all of them, on DELETE", async () => {
+    const one = await invoke(createEvent("DELETE", undefined, { id: "a" }));
+    expect(one.body).toEqual({ deleted: 1 });
+    expect(mockDeleteNotification).toHaveBeenCalledWith("a");
+
+    mockGetNotifications.mockResolvedValue([queued("a"), queued("b")]);
+    const all = await invoke(createEvent("DELETE"));
+    expect(all.body).toEqual({ deleted: 2 });
+  });
+
+  it("rejects other methods", async () => {
+    const { statusCode } = await invoke(createEvent("PUT"));
+    expect(statusCode).toBe(405);
+  });
+});
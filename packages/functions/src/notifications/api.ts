@@ -0,0 +1,73 @@
+/**
+ * Notification API
+ *
+ * GET    /notify          - the queue, showing notification first
+ * POST   /notify          - queue a notification
+ * DELETE /notify?id=...   - drop one notification (all of them without an id)
+ *
+ * Body: { "text": "Laundry done", "priority": "normal", "seconds": 10 }
+ * `priority` is low, normal (default) or high; a higher one cuts in on the
+ * notification showing. `seconds` is how long it shows (default 10, max
+ * 3600). The display changes once a minute, so a short notification stays
+ * up until the next update after its time is over.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { deleteNotification, getNotifications, saveNotification } from "./store.js";
+import { advanceNotifications, createNotification, parseNotifyRequest, MAX_QUEUED_NOTIFICATIONS } from "./queue.js";
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  if (method === "GET") {
+    // Shown from the queue as it will be, without moving it on
+    const { queue } = advanceNotifications(await getNotifications(), Date.now());
+    return json(200, { notifications: queue });
+  }
+
+  if (method === "DELETE") {
+    const id = event.queryStringParameters?.id;
+    const ids = id ? [id] : (await getNotifications()).map((n) => n.id);
+    await Promise.all(ids.map((n) => deleteNotification(n)));
+    console.log(id ? `Notification ${id} dropped` : `Notifications cleared (${ids.length})`);
+    return json(200, { deleted: ids.length });
+  }
+
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  let body: Parameters<typeof parseNotifyRequest>[0];
+  try {
+    body = JSON.parse(event.body || "{}");
+  } catch {
+    return json(400, { error: "Invalid JSON" });
+  }
+
+  const request = parseNotifyRequest(body);
+  if (typeof request === "string") {
+    return json(400, { error: request });
+  }
+
+  const now = Date.now();
+  const { queue } = advanceNotifications(await getNotifications(), now);
+  if (queue.length >= MAX_QUEUED_NOTIFICATIONS) {
+    return json(429, { error: `Queue is full (${MAX_QUEUED_NOTIFICATIONS} notifications)` });
+  }
+
+  const notification = createNotification(request, now);
+  await saveNotification(notification);
+  console.log(`Notification queued (${notification.priority}, ${notification.seconds}s): "${notification.text}"`);
+  return json(200, { notification, queued: queue.length + 1 });
+};
@@ -0,0 +1,8 @@
+/**
+ * Notification queue, for the local server
+ *
+ * Only the pure queue; the API and its store need the deployed resources.
+ */
+
+export * from "./queue.js";
+export * from "./types.js";
@@ -0,0 +1,96 @@
+import { describe, it, expect } from "vitest";
+import {
+  advanceNotifications,
+  createNotification,
+  parseNotifyRequest,
+  MAX_NOTIFICATION_LENGTH,
+  NOTIFICATION_MAX_AGE_MS,
+} from "./queue";
+import type { DisplayNotification } from "./types";
+
+const NOW = Date.UTC(2026, 9, 16, 15, 0);
+
+function notification(
+  id: string,
+  priority: DisplayNotification["priority"],
+  createdAt: number,
+  shownAt?: number
+): DisplayNotification {
+  return { id, text: id, priority, seconds: 10, createdAt, ...(shownAt !== undefined && { shownAt }) };
+}
+
+describe("parseNotifyRequest", () => {
+  it("fills in defaults and tidies the text", () => {
+    expect(parseNotifyRequest({ text: "  Laundry\n done " })).toEqual({
+      text: "Laundry done",
+      priority: "normal",
+      seconds: 10,
+    });
+    expect(parseNotifyRequest({ text: "Door", priority: "high", seconds: 30 })).toEqual({
+      text: "Door",
+      priority: "high",
+      seconds: 30,
+    });
+  });
+
+  it("rejects missing or long text, bad priorities and bad durations", () => {
+    expect(parseNotifyRequest({})).toMatch(/text/);
+    expect(parseNotifyRequest({ text: " " })).toMatch(/text/);
+    expect(parseNotifyRequest({ text: "x".repeat(MAX_NOTIFICATION_LENGTH + 1) })).toMatch(/at most/);
+    expect(parseNotifyRequest({ text: "Door", priority: "urgent" })).toMatch(/priority/);
+    expect(parseNotifyRequest({ text: "Door", seconds: 0 })).toMatch(/seconds/);
+    expect(parseNotifyRequest({ text: "Door", seconds: 1.5 })).toMatch(/seconds/);
+  });
+});
+
+describe("createNotification", () => {
+  it("gives an ID that sorts by arrival", () => {
+    const created = createNotification({ text: "Door", priority: "normal", seconds: 10 }, NOW);
+
+    expect(created.id).toMatch(new RegExp(`^${NOW}-[0-9a-f]{8}$`));
+    expect(created.createdAt).toBe(NOW);
+    expect(created.shownAt).toBeUndefined();
+  });
+});
+
+describe("advanceNotifications", () => {
+  it("starts the highest priority, oldest first", () => {
+    const state = advanceNotifications(
+      [notification("a", "normal", NOW - 2000), notification("b", "high", NOW - 1000), notification("c", "high", NOW)],
+      NOW
+    );
+
+    expect(state.current).toMatchObject({ id: "b", shownAt: NOW });
+    expect(state.queue.map((n) => n.id)).toEqual(["b", "c", "a"]);
+    expect(state.updated.map((n) => n.id)).toEqual(["b"]);
+  });
+
+  it("keeps showing one until its time is up, then moves on", () => {
+    const queue = [notification("a", "normal", NOW - 5000, NOW - 5000), notification("b", "normal", NOW - 1000)];
+
+    expect(advanceNotifications(queue, NOW).current?.id).toBe("a");
+    expect(advanceNotifications(queue, NOW).updated).toEqual([]);
+
+    const later = advanceNotifications(queue, NOW + 5000);
+    expect(later.removed.map((n) => n.id)).toEqual(["a"]);
+    expect(later.current).toMatchObject({ id: "b", shownAt: NOW + 5000 });
+  });
+
+  it("lets a higher priority cut in, and puts the other back", () => {
+    const state = advanceNotifications(
+      [notification("a", "low", NOW - 5000, NOW - 5000), notification("b", "high", NOW)],
+      NOW
+    );
+
+    expect(state.current?.id).toBe("b");
+    expect(state.queue[1]).toEqual(notification("a", "low", NOW - 5000));
+    expect(state.updated.map((n) => n.id).sort()).toEqual(["a", "b"]);
+  });
+
+  it("drops notifications that waited too long", () => {
+    const state = advanceNotifications([notification("a", "low", NOW - NOTIFICATION_MAX_AGE_MS - 1)], NOW);
+
+    expect(state.current).toBeNull();
+    expect(state.removed.map((n) => n.id)).toEqual(["a"]);
+  });
+});
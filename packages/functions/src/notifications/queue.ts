@@ -0,0 +1,125 @@
+/**
+ * Notification queue
+ *
+ * Notifications wait in a queue and show one at a time over the insight
+ * rows, highest priority first and oldest first within a priority. One
+ * that outranks the notification on the display cuts in; the one it
+ * replaced goes back to waiting and shows in full later. Like the pomodoro
+ * timer, everything is worked out from timestamps, so the queue is just
+ * data for whoever renders next.
+ */
+
+import { randomUUID } from "crypto";
+import { NOTIFICATION_PRIORITIES, type DisplayNotification, type NotificationPriority } from "./types.js";
+
+export const DEFAULT_NOTIFICATION_SECONDS = 10;
+export const MAX_NOTIFICATION_SECONDS = 60 * 60;
+export const MAX_NOTIFICATION_LENGTH = 100;
+/** Waiting notifications are dropped after this; they'd be old news */
+export const NOTIFICATION_MAX_AGE_MS = 60 * 60 * 1000;
+/** Most notifications waiting at once */
+export const MAX_QUEUED_NOTIFICATIONS = 20;
+
+/**
+ * A validated POST /notify body
+ */
+export interface NotifyRequest {
+  text: string;
+  priority: NotificationPriority;
+  seconds: number;
+}
+
+/**
+ * Validate a POST /notify body.
+ * Returns the request, or an error message.
+ */
+export function parseNotifyRequest(body: { text?: unknown; priority?: unknown; seconds?: unknown }): NotifyRequest | string {
+  if (typeof body.text !== "string" || body.text.trim() === "") {
+    return "text is required";
+  }
+  const text = body.text.trim().replace(/\s+/g, " ");
+  if (text.length > MAX_NOTIFICATION_LENGTH) {
+    return `text must be at most ${MAX_NOTIFICATION_LENGTH} characters`;
+  }
+
+  const priority = body.priority ?? "normal";
+  if (!NOTIFICATION_PRIORITIES.includes(priority as NotificationPriority)) {
+    return `priority must be one of: ${NOTIFICATION_PRIORITIES.join(", ")}`;
+  }
+
+  const seconds = body.seconds ?? DEFAULT_NOTIFICATION_SECONDS;
+  if (typeof seconds !== "number" || !Number.isInteger(seconds) || seconds < 1 || seconds > MAX_NOTIFICATION_SECONDS) {
+    return `seconds must be a whole number from 1 to ${MAX_NOTIFICATION_SECONDS}`;
+  }
+
+  return { text, priority: priority as NotificationPriority, seconds };
+}
+
+/**
+ * A new notification for a request, with an ID that sorts by arrival
+ */
+export function createNotification(request: NotifyRequest, now: number = Date.now()): DisplayNotification {
+  return { id: `${now}-${randomUUID().slice(0, 8)}`, ...request, createdAt: now };
+}
+
+/**
+ * Order to show notifications in: highest priority, then oldest
+ */
+export function compareNotifications(a: DisplayNotification, b: DisplayNotification): number {
+  const byPriority = NOTIFICATION_PRIORITIES.indexOf(b.priority) - NOTIFICATION_PRIORITIES.indexOf(a.priority);
+  return byPriority !== 0 ? byPriority : a.createdAt - b.createdAt || a.id.localeCompare(b.id);
+}
+
+/**
+ * Whether a notification has been on the display for its full time
+ */
+export function isNotificationDone(notification: DisplayNotification, now: number): boolean {
+  return notification.shownAt !== undefined && now >= notification.shownAt + notification.seconds * 1000;
+}
+
+/**
+ * Where the queue is at a moment
+ */
+export interface NotificationQueueState {
+  /** Notification to show now, if any */
+  current: DisplayNotification | null;
+  /** Everything still queued, `current` included, in showing order */
+  queue: DisplayNotification[];
+  /** Notifications to drop from the store: shown in full, or too old */
+  removed: DisplayNotification[];
+  /** Notifications whose `shownAt` changed and need saving */
+  updated: DisplayNotification[];
+}
+
+/**
+ * Move the queue on to `now`: drop what's done, pick what to show, and
+ * start (or put back) whatever that changes
+ */
+export function advanceNotifications(queue: DisplayNotification[], now: number): NotificationQueueState {
+  const removed: DisplayNotification[] = [];
+  const kept: DisplayNotification[] = [];
+  for (const notification of queue) {
+    const expired = notification.shownAt === undefined && now - notification.createdAt > NOTIFICATION_MAX_AGE_MS;
+    (isNotificationDone(notification, now) || expired ? removed : kept).push(notification);
+  }
+  kept.sort(compareNotifications);
+
+  const updated: DisplayNotification[] = [];
+  const next = kept[0];
+  const result = kept.map((notification) => {
+    if (notification === next && notification.shownAt === undefined) {
+      const started = { ...notification, shownAt: now };
+      updated.push(started);
+      return started;
+    }
+    if (notification !== next && notification.shownAt !== undefined) {
+      // Cut in on by a higher priority: show it in full later
+      const { shownAt: _shownAt, ...waiting } = notification;
+      updated.push(waiting);
+      return waiting;
+    }
+    return notification;
+  });
+
+  return { current: result[0] ?? null, queue: result, removed, updated };
+}
@@ -0,0 +1,65 @@
+/**
+ * Notification store
+ * The queue of notifications waiting for (or on) the display, kept until
+ * the compositor has shown them.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DeleteCommand, DynamoDBDocumentClient, PutCommand, QueryCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { DisplayNotification } from "./types.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const NOTIFICATIONS_PK = "NOTIFICATIONS";
+
+/**
+ * Get every queued notification, in arrival order
+ */
+export async function getNotifications(): Promise<DisplayNotification[]> {
+  const result = await ddb.send(
+    new QueryCommand({
+      TableName: Resource.SignageTable.name,
+      KeyConditionExpression: "pk = :pk",
+      ExpressionAttributeValues: { ":pk": NOTIFICATIONS_PK },
+    })
+  );
+
+  return (result.Items || []).map((item) => ({
+    id: item.id as string,
+    text: item.text as string,
+    priority: item.priority as DisplayNotification["priority"],
+    seconds: item.seconds as number,
+    createdAt: item.createdAt as number,
+    ...(item.shownAt !== undefined && { shownAt: item.shownAt as number }),
+  }));
+}
+
+/**
+ * Save a notification, replacing the stored one with the same ID
+ */
+export async function saveNotification(notification: DisplayNotification): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        pk: NOTIFICATIONS_PK,
+        sk: notification.id,
+        ...notification,
+      },
+    })
+  );
+}
+
+/**
+ * Remove a notification by ID
+ */
+export async function deleteNotification(id: string): Promise<void> {
+  await ddb.send(
+    new DeleteCommand({
+      TableName: Resource.SignageTable.name,
+      Key: { pk: NOTIFICATIONS_PK, sk: id },
+    })
+  );
+}
@@ -0,0 +1,24 @@
+/**
+ * Display notification types
+ */
+
+/** How urgent a notification is; higher ones go first and cut in */
+export type NotificationPriority = "low" | "normal" | "high";
+
+/** Lowest to highest */
+export const NOTIFICATION_PRIORITIES: NotificationPriority[] = ["low", "normal", "high"];
+
+/**
+ * A queued or showing notification, as stored
+ */
+export interface DisplayNotification {
+  /** `<createdAt>-<random>`, so IDs sort in arrival order */
+  id: string;
+  text: string;
+  priority: NotificationPriority;
+  /** How long it shows once it's on the display */
+  seconds: number;
+  createdAt: number;
+  /** When it went on the display; unset while it waits */
+  shownAt?: number;
+}
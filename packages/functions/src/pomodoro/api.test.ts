@@ -0,0 +1,99 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockGetTimer, mockSaveTimer, mockClearTimer } = vi.hoisted(() => ({
+  mockGetTimer: vi.fn(),
+  mockSaveTimer: vi.fn(),
+  mockClearTimer: vi.fn(),
+}));
+
+vi.mock("./store.js", () => ({
+  getPomodoroTimer: mockGetTimer,
+  savePomodoroTimer: mockSaveTimer,
+  clearPomodoroTimer: mockClearTimer,
+}));
+
+vi.mock("sst", () => ({
+  Resource: { SignageTable: { name: "test-table" } },
+}));
+
+import { handler } from "./api";
+import { startPomodoro } from "./timer";
+
+function createEvent(method: string, body?: unknown): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("pomodoro API handler", () => {
+  const running = startPomodoro({ focusMinutes: 25, breakMinutes: 5, cycles: 4, display: "full", buzzer: false });
+
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGetTimer.mockResolvedValue(null);
+    mockSaveTimer.mockResolvedValue(undefined);
+    mockClearTimer.mockResolvedValue(undefined);
+  });
+
+  it("reports no timer on GET", async () => {
+    const { statusCode, body } = await invoke(createEvent("GET"));
+
+    expect(statusCode).toBe(200);
+    expect(body).toEqual({ running: false });
+  });
+
+  it("starts a timer on POST", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { focusMinutes: 50, breakMinutes: 10 }));
+
+    expect(statusCode).toBe(200);
+    expect(mockSaveTimer).toHaveBeenCalledWith(
+      expect.objectContaining({ focusMinutes: 50, breakMinutes: 10, cycles: 4, buzzedIntervals: 0 })
+    );
+    expect(body).toMatchObject({ running: true, phase: "focus", cycle: 1, remainingSeconds: 50 * 60 });
+  });
+
+  it("pauses and resumes the running timer", async () => {
+    mockGetTimer.mockResolvedValue(running);
+    const paused = await invoke(createEvent("POST", { action: "pause" }));
+    expect(paused.body.paused).toBe(true);
+    expect(mockSaveTimer).toHaveBeenLastCalledWith(expect.objectContaining({ pausedAt: expect.any(Number) }));
+
+    mockGetTimer.mockResolvedValue(mockSaveTimer.mock.lastCall![0]);
+    const resumed = await invoke(createEvent("POST", { action: "resume" }));
+    expect(resumed.body.paused).toBe(false);
+  });
+
+  it("returns 409 when pausing with nothing running", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { action: "pause" }));
+
+    expect(statusCode).toBe(409);
+    expect(mockSaveTimer).not.toHaveBeenCalled();
+  });
+
+  it("rejects invalid requests", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { focusMinutes: 500 }));
+
+    expect(statusCode).toBe(400);
+    expect(mockSaveTimer).not.toHaveBeenCalled();
+  });
+
+  it("stops the timer on DELETE", async () => {
+    const { statusCode, body } = await invoke(createEvent("DELETE"));
+
+    expect(statusCode).toBe(200);
+    expect(mockClearTimer).toHaveBeenCalled();
+    expect(body).toEqual({ running: false });
+  });
+
+  it("rejects other methods", async () => {
+    const { statusCode } = await invoke(createEvent("PUT"));
+    expect(statusCode).toBe(405);
+  });
+});
@@ -0,0 +1,75 @@
+/**
+ * Pomodoro API
+ *
+ * GET    /pomodoro - where the timer is
+ * POST   /pomodoro - start, pause or resume the timer
+ * DELETE /pomodoro - stop it
+ *
+ * Body: { "action": "start", "focusMinutes": 25, "breakMinutes": 5, "cycles": 4,
+ *         "display": "region", "buzzer": true }
+ * Everything is optional: "action" defaults to "start", which replaces any
+ * running timer. "display": "full" takes over the whole display instead of
+ * the insight rows; urgent alerts still show on top. "buzzer" sounds the
+ * device buzzer as each period ends. { "action": "pause" } and
+ * { "action": "resume" } take nothing else.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { clearPomodoroTimer, getPomodoroTimer, savePomodoroTimer } from "./store.js";
+import { applyPomodoroRequest, describePomodoro, parsePomodoroRequest } from "./timer.js";
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  if (method === "GET") {
+    return json(200, describePomodoro(await getPomodoroTimer()));
+  }
+
+  if (method === "DELETE") {
+    await clearPomodoroTimer();
+    console.log("Pomodoro stopped");
+    return json(200, { running: false });
+  }
+
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  let body: Parameters<typeof parsePomodoroRequest>[0];
+  try {
+    body = JSON.parse(event.body || "{}");
+  } catch {
+    return json(400, { error: "Invalid JSON" });
+  }
+
+  const request = parsePomodoroRequest(body);
+  if (typeof request === "string") {
+    return json(400, { error: request });
+  }
+
+  const now = Date.now();
+  const current = request.action === "start" ? null : await getPomodoroTimer();
+  const timer = applyPomodoroRequest(current, request, now);
+  if (!timer) {
+    return json(409, { error: "No pomodoro is running" });
+  }
+
+  await savePomodoroTimer(timer);
+  console.log(
+    request.action === "start"
+      ? `Pomodoro started: ${timer.cycles}x ${timer.focusMinutes}/${timer.breakMinutes}m, ${timer.display}`
+      : `Pomodoro ${request.action === "pause" ? "paused" : "resumed"}`
+  );
+  return json(200, describePomodoro(timer, now));
+};
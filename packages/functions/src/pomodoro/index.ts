@@ -0,0 +1,8 @@
+/**
+ * Pomodoro timer, for the local server
+ *
+ * Only the pure timer; the API and its store need the deployed resources.
+ */
+
+export * from "./timer.js";
+export * from "./types.js";
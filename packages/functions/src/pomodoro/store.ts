@@ -0,0 +1,68 @@
+/**
+ * Pomodoro timer store
+ * One display-wide timer, kept until it's stopped or the compositor finds
+ * it finished.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, DeleteCommand, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { PomodoroTimer } from "./types.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const POMODORO_KEY = { pk: "POMODORO", sk: "CURRENT" };
+
+/**
+ * Get the timer, or null if none is running
+ */
+export async function getPomodoroTimer(): Promise<PomodoroTimer | null> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: POMODORO_KEY,
+    })
+  );
+
+  const item = result.Item;
+  if (!item) return null;
+
+  return {
+    focusMinutes: item.focusMinutes as number,
+    breakMinutes: item.breakMinutes as number,
+    cycles: item.cycles as number,
+    display: item.display as PomodoroTimer["display"],
+    buzzer: item.buzzer === true,
+    startedAt: item.startedAt as number,
+    ...(item.pausedAt !== undefined && { pausedAt: item.pausedAt as number }),
+    buzzedIntervals: (item.buzzedIntervals as number | undefined) ?? 0,
+  };
+}
+
+/**
+ * Save the timer, replacing any other
+ */
+export async function savePomodoroTimer(timer: PomodoroTimer): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        ...POMODORO_KEY,
+        ...timer,
+      },
+    })
+  );
+}
+
+/**
+ * Stop the timer
+ */
+export async function clearPomodoroTimer(): Promise<void> {
+  await ddb.send(
+    new DeleteCommand({
+      TableName: Resource.SignageTable.name,
+      Key: POMODORO_KEY,
+    })
+  );
+}
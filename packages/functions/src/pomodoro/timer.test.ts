@@ -0,0 +1,156 @@
+import { describe, it, expect } from "vitest";
+import {
+  applyPomodoroRequest,
+  describePomodoro,
+  getPomodoroStatus,
+  isPomodoroBuzzDue,
+  isPomodoroFinished,
+  parsePomodoroRequest,
+  pausePomodoro,
+  pomodoroIntervalsDone,
+  resumePomodoro,
+  startPomodoro,
+  type PomodoroOptions,
+} from "./timer";
+
+const MINUTE = 60 * 1000;
+const options: PomodoroOptions = { focusMinutes: 25, breakMinutes: 5, cycles: 2, display: "region", buzzer: true };
+const start = Date.UTC(2026, 5, 1, 9, 0, 0);
+
+describe("getPomodoroStatus", () => {
+  const timer = startPomodoro(options, start);
+
+  it("starts in the first focus period", () => {
+    expect(getPomodoroStatus(timer, start + 10 * MINUTE)).toMatchObject({
+      phase: "focus",
+      cycle: 1,
+      cycles: 2,
+      remainingMs: 15 * MINUTE,
+      progress: 0.4,
+      paused: false,
+    });
+  });
+
+  it("moves to the break, then the next cycle", () => {
+    expect(getPomodoroStatus(timer, start + 26 * MINUTE)).toMatchObject({
+      phase: "break",
+      cycle: 1,
+      remainingMs: 4 * MINUTE,
+    });
+    expect(getPomodoroStatus(timer, start + 30 * MINUTE)).toMatchObject({ phase: "focus", cycle: 2, progress: 0 });
+  });
+
+  it("finishes after the last break", () => {
+    expect(isPomodoroFinished(timer, start + 60 * MINUTE - 1)).toBe(false);
+    expect(isPomodoroFinished(timer, start + 60 * MINUTE)).toBe(true);
+    expect(getPomodoroStatus(timer, start + 60 * MINUTE)).toBeNull();
+  });
+});
+
+describe("pause and resume", () => {
+  it("holds the timer while paused and carries on from there", () => {
+    const paused = pausePomodoro(startPomodoro(options, start), start + 10 * MINUTE);
+    expect(getPomodoroStatus(paused, start + 40 * MINUTE)).toMatchObject({
+      phase: "focus",
+      remainingMs: 15 * MINUTE,
+      paused: true,
+    });
+
+    const resumed = resumePomodoro(paused, start + 40 * MINUTE);
+    expect(resumed.pausedAt).toBeUndefined();
+    expect(getPomodoroStatus(resumed, start + 45 * MINUTE)).toMatchObject({
+      phase: "focus",
+      remainingMs: 10 * MINUTE,
+      paused: false,
+    });
+  });
+
+  it("leaves a paused timer paused and a running one running", () => {
+    const paused = pausePomodoro(startPomodoro(options, start), start + MINUTE);
+    expect(pausePomodoro(paused, start + 2 * MINUTE)).toBe(paused);
+
+    const running = startPomodoro(options, start);
+    expect(resumePomodoro(running, start + MINUTE)).toBe(running);
+  });
+});
+
+describe("applyPomodoroRequest", () => {
+  it("starts a new timer over a running one", () => {
+    const running = startPomodoro(options, start);
+    expect(applyPomodoroRequest(running, { action: "start", options }, start + MINUTE)).toMatchObject({
+      startedAt: start + MINUTE,
+      buzzedIntervals: 0,
+    });
+  });
+
+  it("pauses and resumes only a running timer", () => {
+    const running = startPomodoro(options, start);
+    expect(applyPomodoroRequest(running, { action: "pause" }, start + MINUTE)?.pausedAt).toBe(start + MINUTE);
+    expect(applyPomodoroRequest(null, { action: "pause" }, start)).toBeNull();
+    expect(applyPomodoroRequest(running, { action: "resume" }, start + 60 * MINUTE)).toBeNull();
+  });
+});
+
+describe("buzzer", () => {
+  it("is due once per period end", () => {
+    const timer = startPomodoro(options, start);
+    expect(isPomodoroBuzzDue(timer, start + 24 * MINUTE)).toBe(false);
+    expect(isPomodoroBuzzDue(timer, start + 25 * MINUTE)).toBe(true);
+
+    const buzzed = { ...timer, buzzedIntervals: pomodoroIntervalsDone(timer, start + 25 * MINUTE) };
+    expect(isPomodoroBuzzDue(buzzed, start + 29 * MINUTE)).toBe(false);
+    expect(isPomodoroBuzzDue(buzzed, start + 30 * MINUTE)).toBe(true);
+  });
+
+  it("counts the final break's end but nothing after it", () => {
+    const timer = startPomodoro(options, start);
+    expect(pomodoroIntervalsDone(timer, start + 60 * MINUTE)).toBe(4);
+    expect(pomodoroIntervalsDone(timer, start + 600 * MINUTE)).toBe(4);
+  });
+
+  it("stays quiet when turned off", () => {
+    const timer = startPomodoro({ ...options, buzzer: false }, start);
+    expect(isPomodoroBuzzDue(timer, start + 25 * MINUTE)).toBe(false);
+  });
+});
+
+describe("describePomodoro", () => {
+  it("reports no timer as not running", () => {
+    expect(describePomodoro(null)).toEqual({ running: false });
+    expect(describePomodoro(startPomodoro(options, start), start + 60 * MINUTE)).toEqual({ running: false });
+  });
+
+  it("reports the period and seconds left", () => {
+    expect(describePomodoro(startPomodoro(options, start), start + 90_500)).toMatchObject({
+      running: true,
+      phase: "focus",
+      cycle: 1,
+      remainingSeconds: 25 * 60 - 90,
+      display: "region",
+      buzzer: true,
+    });
+  });
+});
+
+describe("parsePomodoroRequest", () => {
+  it("starts a classic 25/5 timer by default", () => {
+    expect(parsePomodoroRequest({})).toEqual({
+      action: "start",
+      options: { focusMinutes: 25, breakMinutes: 5, cycles: 4, display: "region", buzzer: false },
+    });
+  });
+
+  it("takes pause and resume on their own", () => {
+    expect(parsePomodoroRequest({ action: "pause" })).toEqual({ action: "pause" });
+    expect(parsePomodoroRequest({ action: "resume" })).toEqual({ action: "resume" });
+  });
+
+  it("rejects bad values", () => {
+    expect(parsePomodoroRequest({ action: "stop" })).toMatch(/Unknown action/);
+    expect(parsePomodoroRequest({ focusMinutes: 0 })).toMatch(/whole minutes/);
+    expect(parsePomodoroRequest({ breakMinutes: 2.5 })).toMatch(/whole minutes/);
+    expect(parsePomodoroRequest({ cycles: 20 })).toMatch(/cycles/);
+    expect(parsePomodoroRequest({ display: "corner" })).toMatch(/display/);
+    expect(parsePomodoroRequest({ buzzer: "yes" })).toMatch(/buzzer/);
+  });
+});
@@ -0,0 +1,228 @@
+/**
+ * Pomodoro timer
+ *
+ * Focus for 25 minutes, break for 5, repeated for a number of cycles. The
+ * timer is just a start time and settings, so where it is (and whether a
+ * period has ended since the last look) is worked out from the clock
+ * rather than counted down by the once-a-minute compositor.
+ */
+
+import type { BuzzerPayload } from "@signage/core";
+import {
+  POMODORO_DISPLAYS,
+  type PomodoroDisplay,
+  type PomodoroPhase,
+  type PomodoroStatus,
+  type PomodoroTimer,
+} from "./types.js";
+
+export const DEFAULT_FOCUS_MINUTES = 25;
+export const DEFAULT_BREAK_MINUTES = 5;
+export const DEFAULT_POMODORO_CYCLES = 4;
+/** Longest focus or break period */
+export const MAX_POMODORO_MINUTES = 120;
+export const MAX_POMODORO_CYCLES = 12;
+
+const MINUTE_MS = 60 * 1000;
+
+/** Three short beeps as a period ends */
+export const POMODORO_BUZZ: BuzzerPayload = { activeMs: 300, offMs: 200, totalMs: 1500 };
+
+/**
+ * Settings for a new timer
+ */
+export interface PomodoroOptions {
+  focusMinutes: number;
+  breakMinutes: number;
+  cycles: number;
+  display: PomodoroDisplay;
+  buzzer: boolean;
+}
+
+/**
+ * Timer state as reported by the API
+ */
+export interface PomodoroTimerStatus {
+  running: boolean;
+  phase?: PomodoroPhase;
+  cycle?: number;
+  cycles?: number;
+  remainingSeconds?: number;
+  paused?: boolean;
+  focusMinutes?: number;
+  breakMinutes?: number;
+  display?: PomodoroDisplay;
+  buzzer?: boolean;
+}
+
+/** A validated POST /pomodoro request */
+export type PomodoroRequest = { action: "start"; options: PomodoroOptions } | { action: "pause" | "resume" };
+
+/**
+ * Start a timer now, in its first focus period
+ */
+export function startPomodoro(options: PomodoroOptions, now: number = Date.now()): PomodoroTimer {
+  return { ...options, startedAt: now, buzzedIntervals: 0 };
+}
+
+/**
+ * Pause a timer; an already paused one is returned as it is
+ */
+export function pausePomodoro(timer: PomodoroTimer, now: number = Date.now()): PomodoroTimer {
+  return timer.pausedAt === undefined ? { ...timer, pausedAt: now } : timer;
+}
+
+/**
+ * Resume a paused timer where it left off
+ */
+export function resumePomodoro(timer: PomodoroTimer, now: number = Date.now()): PomodoroTimer {
+  if (timer.pausedAt === undefined) return timer;
+  const { pausedAt, ...running } = timer;
+  return { ...running, startedAt: timer.startedAt + (now - pausedAt) };
+}
+
+/**
+ * The timer after a request: a new one for "start", the current one paused
+ * or resumed otherwise. Returns null when there's nothing running to pause
+ * or resume.
+ */
+export function applyPomodoroRequest(
+  current: PomodoroTimer | null,
+  request: PomodoroRequest,
+  now: number = Date.now()
+): PomodoroTimer | null {
+  if (request.action === "start") {
+    return startPomodoro(request.options, now);
+  }
+  if (!current || isPomodoroFinished(current, now)) {
+    return null;
+  }
+  return request.action === "pause" ? pausePomodoro(current, now) : resumePomodoro(current, now);
+}
+
+function elapsedMs(timer: PomodoroTimer, now: number): number {
+  return Math.max(0, (timer.pausedAt ?? now) - timer.startedAt);
+}
+
+/**
+ * Focus and break periods ended so far, up to two per cycle
+ */
+export function pomodoroIntervalsDone(timer: PomodoroTimer, now: number = Date.now()): number {
+  const focusMs = timer.focusMinutes * MINUTE_MS;
+  const cycleMs = focusMs + timer.breakMinutes * MINUTE_MS;
+  const elapsed = elapsedMs(timer, now);
+  const done = Math.floor(elapsed / cycleMs) * 2 + (elapsed % cycleMs >= focusMs ? 1 : 0);
+  return Math.min(done, timer.cycles * 2);
+}
+
+/**
+ * Whether every cycle has run
+ */
+export function isPomodoroFinished(timer: PomodoroTimer, now: number = Date.now()): boolean {
+  return pomodoroIntervalsDone(timer, now) >= timer.cycles * 2;
+}
+
+/**
+ * Whether a period has ended since the buzzer last sounded
+ */
+export function isPomodoroBuzzDue(timer: PomodoroTimer, now: number = Date.now()): boolean {
+  return timer.buzzer && pomodoroIntervalsDone(timer, now) > timer.buzzedIntervals;
+}
+
+/**
+ * Where the timer is now, or null once it has finished
+ */
+export function getPomodoroStatus(timer: PomodoroTimer, now: number = Date.now()): PomodoroStatus | null {
+  if (isPomodoroFinished(timer, now)) return null;
+
+  const focusMs = timer.focusMinutes * MINUTE_MS;
+  const breakMs = timer.breakMinutes * MINUTE_MS;
+  const elapsed = elapsedMs(timer, now);
+  const intoCycle = elapsed % (focusMs + breakMs);
+  const focus = intoCycle < focusMs;
+  const periodMs = focus ? focusMs : breakMs;
+  const intoPeriod = focus ? intoCycle : intoCycle - focusMs;
+
+  return {
+    phase: focus ? "focus" : "break",
+    cycle: Math.floor(elapsed / (focusMs + breakMs)) + 1,
+    cycles: timer.cycles,
+    remainingMs: periodMs - intoPeriod,
+    progress: intoPeriod / periodMs,
+    paused: timer.pausedAt !== undefined,
+    display: timer.display,
+  };
+}
+
+/**
+ * Describe a timer for API responses
+ */
+export function describePomodoro(timer: PomodoroTimer | null, now: number = Date.now()): PomodoroTimerStatus {
+  const status = timer && getPomodoroStatus(timer, now);
+  if (!timer || !status) {
+    return { running: false };
+  }
+  return {
+    running: true,
+    phase: status.phase,
+    cycle: status.cycle,
+    cycles: status.cycles,
+    remainingSeconds: Math.ceil(status.remainingMs / 1000),
+    paused: status.paused,
+    focusMinutes: timer.focusMinutes,
+    breakMinutes: timer.breakMinutes,
+    display: timer.display,
+    buzzer: timer.buzzer,
+  };
+}
+
+function isMinutes(value: unknown): value is number {
+  return typeof value === "number" && Number.isInteger(value) && value >= 1 && value <= MAX_POMODORO_MINUTES;
+}
+
+/**
+ * Validate a POST /pomodoro body.
+ * Returns the request, or an error message.
+ */
+export function parsePomodoroRequest(body: {
+  action?: unknown;
+  focusMinutes?: unknown;
+  breakMinutes?: unknown;
+  cycles?: unknown;
+  display?: unknown;
+  buzzer?: unknown;
+}): PomodoroRequest | string {
+  const action = body.action ?? "start";
+  if (action === "pause" || action === "resume") {
+    return { action };
+  }
+  if (action !== "start") {
+    return `Unknown action: ${JSON.stringify(action)} (use start, pause or resume)`;
+  }
+
+  const focusMinutes = body.focusMinutes ?? DEFAULT_FOCUS_MINUTES;
+  const breakMinutes = body.breakMinutes ?? DEFAULT_BREAK_MINUTES;
+  if (!isMinutes(focusMinutes) || !isMinutes(breakMinutes)) {
+    return `focusMinutes and breakMinutes must be whole minutes from 1 to ${MAX_POMODORO_MINUTES}`;
+  }
+
+  const cycles = body.cycles ?? DEFAULT_POMODORO_CYCLES;
+  if (typeof cycles !== "number" || !Number.isInteger(cycles) || cycles < 1 || cycles > MAX_POMODORO_CYCLES) {
+    return `cycles must be a whole number from 1 to ${MAX_POMODORO_CYCLES}`;
+  }
+
+  const display = body.display ?? "region";
+  if (!POMODORO_DISPLAYS.includes(display as PomodoroDisplay)) {
+    return `display must be one of: ${POMODORO_DISPLAYS.join(", ")}`;
+  }
+
+  const buzzer = body.buzzer ?? false;
+  if (typeof buzzer !== "boolean") {
+    return "buzzer must be true or false";
+  }
+
+  return {
+    action: "start",
+    options: { focusMinutes, breakMinutes, cycles, display: display as PomodoroDisplay, buzzer },
+  };
+}
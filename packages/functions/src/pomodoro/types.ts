@@ -0,0 +1,46 @@
+/**
+ * Pomodoro timer types
+ */
+
+/** Where the timer shows: the insight rows, or the whole display */
+export type PomodoroDisplay = "region" | "full";
+
+export const POMODORO_DISPLAYS: PomodoroDisplay[] = ["region", "full"];
+
+/** Focus period, then a break */
+export type PomodoroPhase = "focus" | "break";
+
+/**
+ * A started timer, as stored. Time spent paused moves `startedAt` later on
+ * resume, so the elapsed time is always `(pausedAt ?? now) - startedAt`.
+ */
+export interface PomodoroTimer {
+  focusMinutes: number;
+  breakMinutes: number;
+  /** Focus/break cycles before the timer finishes */
+  cycles: number;
+  display: PomodoroDisplay;
+  /** Sound the device buzzer when a period ends */
+  buzzer: boolean;
+  startedAt: number;
+  /** Set while paused */
+  pausedAt?: number;
+  /** Period ends already buzzed for, so each one buzzes once */
+  buzzedIntervals: number;
+}
+
+/**
+ * Where a timer is at a moment, for rendering
+ */
+export interface PomodoroStatus {
+  phase: PomodoroPhase;
+  /** 1-based cycle number */
+  cycle: number;
+  cycles: number;
+  /** Time left in the current period (ms) */
+  remainingMs: number;
+  /** Fraction of the current period done, 0-1 */
+  progress: number;
+  paused: boolean;
+  display: PomodoroDisplay;
+}
@@ -0,0 +1,116 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import {
+  fetchPrecipitationNowcast,
+  isNowcastRefreshDue,
+  isPrecipitationExpected,
+  parseOpenMeteoNowcast,
+  precipitationAt,
+  validatePrecipitationSettings,
+  NOWCAST_REFRESH_MS,
+} from "./nowcast";
+import type { PrecipitationNowcast } from "./types";
+
+// 15:00 UTC, on a step boundary
+const NOW = Date.UTC(2026, 9, 16, 15, 0);
+const SETTINGS = { latitude: 47.6, longitude: -122.3 };
+const MINUTE = 60_000;
+
+function nowcast(rates: number[]): PrecipitationNowcast {
+  return {
+    ...SETTINGS,
+    fetchedAt: NOW,
+    steps: rates.map((mmPerHour, i) => ({ end: NOW + (i + 1) * 15 * MINUTE, mmPerHour })),
+  };
+}
+
+describe("validatePrecipitationSettings", () => {
+  it("accepts a location, and null", () => {
+    expect(validatePrecipitationSettings(SETTINGS)).toBeNull();
+    expect(validatePrecipitationSettings(null)).toBeNull();
+  });
+
+  it("rejects out-of-range coordinates and other fields", () => {
+    expect(validatePrecipitationSettings({ latitude: 91, longitude: 0 })).toMatch(/latitude/);
+    expect(validatePrecipitationSettings({ latitude: 0, longitude: "0" })).toMatch(/longitude/);
+    expect(validatePrecipitationSettings({ ...SETTINGS, units: "in" })).toMatch(/unknown/);
+    expect(validatePrecipitationSettings([])).toMatch(/object/);
+  });
+});
+
+describe("parseOpenMeteoNowcast", () => {
+  it("turns 15-minute amounts into hourly rates", () => {
+    const steps = parseOpenMeteoNowcast({
+      minutely_15: { time: [NOW / 1000, NOW / 1000 + 900], precipitation: [0.5, null] },
+    });
+
+    expect(steps).toEqual([{ end: NOW, mmPerHour: 2 }]);
+  });
+
+  it("returns null without a series", () => {
+    expect(parseOpenMeteoNowcast({})).toBeNull();
+  });
+});
+
+describe("precipitationAt", () => {
+  it("finds the step covering a time", () => {
+    const forecast = nowcast([0, 1.2, 4]);
+
+    expect(precipitationAt(forecast, NOW + MINUTE)).toBe(0);
+    expect(precipitationAt(forecast, NOW + 15 * MINUTE)).toBe(0);
+    expect(precipitationAt(forecast, NOW + 16 * MINUTE)).toBe(1.2);
+    expect(precipitationAt(forecast, NOW + 44 * MINUTE)).toBe(4);
+    expect(precipitationAt(forecast, NOW + 46 * MINUTE)).toBeNull();
+  });
+});
+
+describe("isPrecipitationExpected", () => {
+  it("looks at the steps overlapping the coming hour", () => {
+    expect(isPrecipitationExpected(nowcast([0, 0, 0, 0]), NOW)).toBe(false);
+    expect(isPrecipitationExpected(nowcast([0, 0, 0, 0.4]), NOW)).toBe(true);
+    // Rain after the hour doesn't count
+    expect(isPrecipitationExpected(nowcast([0, 0, 0, 0, 3]), NOW)).toBe(false);
+  });
+});
+
+describe("isNowcastRefreshDue", () => {
+  it("refreshes when missing, old, or for another place", () => {
+    const cached = nowcast([0]);
+    expect(isNowcastRefreshDue(null, SETTINGS, NOW)).toBe(true);
+    expect(isNowcastRefreshDue(cached, SETTINGS, NOW + MINUTE)).toBe(false);
+    expect(isNowcastRefreshDue(cached, SETTINGS, NOW + NOWCAST_REFRESH_MS)).toBe(true);
+    expect(isNowcastRefreshDue(cached, { latitude: 40, longitude: -74 }, NOW + MINUTE)).toBe(true);
+  });
+});
+
+describe("fetchPrecipitationNowcast", () => {
+  const originalFetch = global.fetch;
+  const mockFetch = vi.fn();
+
+  beforeEach(() => {
+    mockFetch.mockReset();
+    global.fetch = mockFetch as unknown as typeof fetch;
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  it("asks Open-Meteo for the 15-minutely series at the location", async () => {
+    mockFetch.mockResolvedValueOnce({
+      ok: true,
+      json: async () => ({ minutely_15: { time: [NOW / 1000 + 900], precipitation: [0.25] } }),
+    });
+
+    const result = await fetchPrecipitationNowcast(SETTINGS, NOW);
+
+    expect(mockFetch.mock.calls[0][0]).toContain("latitude=47.6&longitude=-122.3");
+    expect(mockFetch.mock.calls[0][0]).toContain("minutely_15=precipitation");
+    expect(result).toEqual({ ...SETTINGS, fetchedAt: NOW, steps: [{ end: NOW + 15 * MINUTE, mmPerHour: 1 }] });
+  });
+
+  it("returns null when the request fails", async () => {
+    mockFetch.mockResolvedValueOnce({ ok: false, status: 503 });
+
+    expect(await fetchPrecipitationNowcast(SETTINGS, NOW)).toBeNull();
+  });
+});
@@ -0,0 +1,135 @@
+/**
+ * Precipitation nowcast
+ *
+ * Open-Meteo's 15-minutely forecast (free, no API key needed) for the next
+ * hour or two. Each value is the precipitation over the 15 minutes before
+ * its time; they're kept as rates so the bar's colors don't depend on the
+ * step length.
+ */
+
+import type { PrecipitationNowcast, PrecipitationSettings, PrecipitationStep } from "./types.js";
+
+const OPEN_METEO_URL = "https://api.open-meteo.com/v1/forecast";
+
+/** Minutes covered by each forecast step */
+export const NOWCAST_STEP_MINUTES = 15;
+
+/** Steps asked for: the current one, the next hour, and slack for a cached forecast to age */
+const NOWCAST_STEPS = 8;
+
+/** Open-Meteo updates its 15-minutely forecast about this often */
+export const NOWCAST_REFRESH_MS = 15 * 60 * 1000;
+
+/** Rates below this (mm/h) are dry; Open-Meteo reports 0.1 mm as its smallest amount */
+export const MIN_PRECIPITATION_MM_PER_HOUR = 0.1;
+
+interface OpenMeteoMinutelyResponse {
+  minutely_15?: {
+    time?: number[];
+    precipitation?: Array<number | null>;
+  };
+}
+
+/**
+ * Validate precipitation settings from a request body (null clears them).
+ * Returns an error message, or null if valid.
+ */
+export function validatePrecipitationSettings(value: unknown): string | null {
+  if (value === null) return null;
+  if (typeof value !== "object" || Array.isArray(value)) {
+    return "precipitation must be an object or null";
+  }
+  const { latitude, longitude, ...rest } = value as Record<string, unknown>;
+  if (Object.keys(rest).length > 0) {
+    return `unknown precipitation fields: ${Object.keys(rest).join(", ")}`;
+  }
+  if (typeof latitude !== "number" || !(latitude >= -90 && latitude <= 90)) {
+    return "precipitation latitude must be a number from -90 to 90";
+  }
+  if (typeof longitude !== "number" || !(longitude >= -180 && longitude <= 180)) {
+    return "precipitation longitude must be a number from -180 to 180";
+  }
+  return null;
+}
+
+/**
+ * Parse Open-Meteo's 15-minutely response (unixtime format) into steps.
+ * Missing values are left out. Returns null if the response has no series.
+ */
+export function parseOpenMeteoNowcast(response: OpenMeteoMinutelyResponse): PrecipitationStep[] | null {
+  const times = response.minutely_15?.time;
+  const amounts = response.minutely_15?.precipitation;
+  if (!Array.isArray(times) || !Array.isArray(amounts)) return null;
+
+  return times.flatMap((time, i) => {
+    const mm = amounts[i];
+    if (typeof time !== "number" || typeof mm !== "number" || mm < 0) return [];
+    return [{ end: time * 1000, mmPerHour: (mm * 60) / NOWCAST_STEP_MINUTES }];
+  });
+}
+
+/**
+ * Fetch the nowcast for a location. Returns null if the request fails or
+ * the response can't be read.
+ */
+export async function fetchPrecipitationNowcast(
+  settings: PrecipitationSettings,
+  now: number = Date.now()
+): Promise<PrecipitationNowcast | null> {
+  const { latitude, longitude } = settings;
+  const url =
+    `${OPEN_METEO_URL}?latitude=${latitude}&longitude=${longitude}` +
+    `&minutely_15=precipitation&forecast_minutely_15=${NOWCAST_STEPS}&timeformat=unixtime`;
+  try {
+    const response = await fetch(url);
+    if (!response.ok) {
+      throw new Error(`Open-Meteo returned ${response.status}`);
+    }
+    const steps = parseOpenMeteoNowcast((await response.json()) as OpenMeteoMinutelyResponse);
+    if (!steps) {
+      console.warn("Invalid precipitation nowcast received");
+      return null;
+    }
+    return { latitude, longitude, fetchedAt: now, steps };
+  } catch (error) {
+    console.error("Failed to fetch precipitation nowcast:", error);
+    return null;
+  }
+}
+
+/**
+ * Whether a cached nowcast should be fetched again: it's old, or for a
+ * different place
+ */
+export function isNowcastRefreshDue(
+  cached: PrecipitationNowcast | null,
+  settings: PrecipitationSettings,
+  now: number = Date.now()
+): boolean {
+  return (
+    !cached ||
+    cached.latitude !== settings.latitude ||
+    cached.longitude !== settings.longitude ||
+    now - cached.fetchedAt >= NOWCAST_REFRESH_MS
+  );
+}
+
+/**
+ * Precipitation rate (mm/h) at a time, or null outside the forecast
+ */
+export function precipitationAt(nowcast: PrecipitationNowcast, time: number): number | null {
+  const step = nowcast.steps.find((s) => time > s.end - NOWCAST_STEP_MINUTES * 60_000 && time <= s.end);
+  return step ? step.mmPerHour : null;
+}
+
+/**
+ * Whether any precipitation is expected within `minutes` of now
+ */
+export function isPrecipitationExpected(nowcast: PrecipitationNowcast, now: number, minutes: number = 60): boolean {
+  return nowcast.steps.some(
+    (s) =>
+      s.end > now &&
+      s.end - NOWCAST_STEP_MINUTES * 60_000 < now + minutes * 60_000 &&
+      s.mmPerHour >= MIN_PRECIPITATION_MM_PER_HOUR
+  );
+}
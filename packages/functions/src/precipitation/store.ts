@@ -0,0 +1,52 @@
+/**
+ * Precipitation nowcast cache
+ * The latest nowcast, so the compositor's minutely runs only call
+ * Open-Meteo when it has updated.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { PrecipitationNowcast } from "./types.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const NOWCAST_KEY = { pk: "PRECIPITATION_NOWCAST", sk: "LATEST" };
+
+/**
+ * Get the cached nowcast, or null if there isn't one
+ */
+export async function getCachedNowcast(): Promise<PrecipitationNowcast | null> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: NOWCAST_KEY,
+    })
+  );
+
+  const item = result.Item;
+  if (!item) return null;
+
+  return {
+    latitude: item.latitude as number,
+    longitude: item.longitude as number,
+    fetchedAt: item.fetchedAt as number,
+    steps: item.steps as PrecipitationNowcast["steps"],
+  };
+}
+
+/**
+ * Cache a nowcast, replacing the previous one
+ */
+export async function cacheNowcast(nowcast: PrecipitationNowcast): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        ...NOWCAST_KEY,
+        ...nowcast,
+      },
+    })
+  );
+}
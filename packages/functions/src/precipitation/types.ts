@@ -0,0 +1,34 @@
+/**
+ * Precipitation nowcast types
+ */
+
+/**
+ * Where to forecast precipitation for, stored in the display config
+ */
+export interface PrecipitationSettings {
+  latitude: number;
+  longitude: number;
+}
+
+/**
+ * One forecast step
+ */
+export interface PrecipitationStep {
+  /** End of the step (ms); it covers the NOWCAST_STEP_MINUTES before */
+  end: number;
+  /** Precipitation rate over the step, mm per hour */
+  mmPerHour: number;
+}
+
+/**
+ * Precipitation over roughly the next hour
+ */
+export interface PrecipitationNowcast {
+  /** Where it was forecast for */
+  latitude: number;
+  longitude: number;
+  /** When it was fetched (ms) */
+  fetchedAt: number;
+  /** Forecast steps, oldest first */
+  steps: PrecipitationStep[];
+}
@@ -0,0 +1,49 @@
+import { describe, it, expect } from "vitest";
+import { DEFAULT_PROFILE_ID, isValidProfileId, parseProfileParam, profilePk, profileUserId } from "./profile";
+
+describe("isValidProfileId", () => {
+  it("accepts lowercase IDs with digits and dashes", () => {
+    expect(isValidProfileId("default")).toBe(true);
+    expect(isValidProfileId("household-2")).toBe(true);
+  });
+
+  it("rejects IDs that could break out of a key", () => {
+    expect(isValidProfileId("a#b")).toBe(false);
+    expect(isValidProfileId("-x")).toBe(false);
+    expect(isValidProfileId("John")).toBe(false);
+    expect(isValidProfileId("x".repeat(33))).toBe(false);
+  });
+});
+
+describe("profilePk", () => {
+  it("keeps the bare key for the default profile", () => {
+    expect(profilePk(DEFAULT_PROFILE_ID, "ANNOTATIONS")).toBe("ANNOTATIONS");
+  });
+
+  it("namespaces other profiles", () => {
+    expect(profilePk("household-2", "ANNOTATIONS")).toBe("PROFILE#household-2#ANNOTATIONS");
+  });
+});
+
+describe("profileUserId", () => {
+  it("keeps the default profile's original store user ID", () => {
+    expect(profileUserId(DEFAULT_PROFILE_ID)).not.toBe(DEFAULT_PROFILE_ID);
+    expect(profileUserId(DEFAULT_PROFILE_ID)).toMatch(/^[a-z0-9-]+$/);
+  });
+
+  it("uses other profiles' IDs as they are", () => {
+    expect(profileUserId("household-2")).toBe("household-2");
+  });
+});
+
+describe("parseProfileParam", () => {
+  it("defaults to the default profile", () => {
+    expect(parseProfileParam(undefined)).toBe(DEFAULT_PROFILE_ID);
+    expect(parseProfileParam({ profile: "" })).toBe(DEFAULT_PROFILE_ID);
+  });
+
+  it("returns a valid profile or an error", () => {
+    expect(parseProfileParam({ profile: "household-2" })).toBe("household-2");
+    expect(parseProfileParam({ profile: "a#b" })).toEqual({ error: expect.stringMatching(/profile/) });
+  });
+});
@@ -0,0 +1,54 @@
+/**
+ * Profiles
+ *
+ * A profile is one household: its glucose history, treatments and
+ * annotations. Everything stored for a profile is namespaced by its ID, so
+ * one deployment can serve two households without mixing their data.
+ *
+ * The default profile is the original single-user install. Its keys are
+ * left as they were, so existing data needs no migration.
+ */
+
+/** Profile used when none is given */
+export const DEFAULT_PROFILE_ID = "default";
+
+/** User ID the default profile's diabetes records were stored under before profiles */
+const LEGACY_DEFAULT_USER_ID = "john";
+
+const PROFILE_ID_PATTERN = /^[a-z0-9][a-z0-9-]{0,31}$/;
+
+/**
+ * Check a profile ID: lowercase letters, digits and dashes, up to 32 characters
+ */
+export function isValidProfileId(id: string): boolean {
+  return PROFILE_ID_PATTERN.test(id);
+}
+
+/**
+ * Partition key for a profile's copy of a fixed-key item or list.
+ * The default profile keeps the bare key.
+ */
+export function profilePk(profileId: string, pk: string): string {
+  return profileId === DEFAULT_PROFILE_ID ? pk : `PROFILE#${profileId}#${pk}`;
+}
+
+/**
+ * User ID a profile's records are stored under in the diabetes store
+ * (`USR#<userId>#...`). The default profile keeps its original ID.
+ */
+export function profileUserId(profileId: string): string {
+  return profileId === DEFAULT_PROFILE_ID ? LEGACY_DEFAULT_USER_ID : profileId;
+}
+
+/**
+ * Read the `profile` query parameter. Returns the profile ID, or an error
+ * message when the parameter is present but invalid.
+ */
+export function parseProfileParam(query: Record<string, string | undefined> | undefined): string | { error: string } {
+  const profile = query?.profile;
+  if (profile === undefined || profile === "") return DEFAULT_PROFILE_ID;
+  if (!isValidProfileId(profile)) {
+    return { error: "profile must be lowercase letters, digits and dashes (max 32)" };
+  }
+  return profile;
+}
@@ -0,0 +1,50 @@
+/**
+ * Tests for annotation chart markers
+ */
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { renderAnnotationMarkers } from "../annotation-renderer";
+import { COLORS } from "../colors";
+
+const NOW = new Date("2026-03-02T12:00:00Z").getTime();
+const HOUR = 60 * 60 * 1000;
+
+describe("renderAnnotationMarkers", () => {
+  beforeEach(() => {
+    vi.useFakeTimers();
+    vi.setSystemTime(NOW);
+  });
+
+  afterEach(() => {
+    vi.useRealTimers();
+  });
+
+  const bounds = { x: 0, y: 10, width: 31, height: 10, hours: 3 };
+
+  it("draws a dotted line at the annotation time", () => {
+    const frame = createSolidFrame(64, 64);
+    // Halfway through the 3h window lands on column 15
+    renderAnnotationMarkers(frame, [{ timestamp: NOW - 1.5 * HOUR }], bounds);
+
+    expect(getPixel(frame, 15, 10)).toEqual(COLORS.annotation);
+    expect(getPixel(frame, 15, 11)).toEqual({ r: 0, g: 0, b: 0 });
+    expect(getPixel(frame, 15, 18)).toEqual(COLORS.annotation);
+    expect(getPixel(frame, 15, 20)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+
+  it("skips annotations outside the window", () => {
+    const frame = createSolidFrame(64, 64);
+    renderAnnotationMarkers(frame, [{ timestamp: NOW - 4 * HOUR }, { timestamp: NOW + HOUR }], bounds);
+
+    expect(frame.pixels.every((v) => v === 0)).toBe(true);
+  });
+
+  it("honors the chart offset", () => {
+    const frame = createSolidFrame(64, 64);
+    renderAnnotationMarkers(frame, [{ timestamp: NOW - 4 * HOUR }], { ...bounds, offsetHours: 3 });
+
+    // -4h in a -6h..-3h window is two thirds across: column 20
+    expect(getPixel(frame, 20, 10)).toEqual(COLORS.annotation);
+  });
+});
@@ -3,8 +3,9 @@
  */
 
 import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
-import { createSolidFrame, getPixel } from "@signage/core";
-import { renderChart, type ChartPoint, type ChartConfig } from "../chart-renderer.js";
+import { createSolidFrame, getPixel, getPixelValue, setPixel } from "@signage/core";
+import { renderChart, projectTrend, type ChartPoint, type ChartConfig } from "../chart-renderer.js";
+import { COLORS } from "../colors.js";
 
 describe("renderChart", () => {
   const now = Date.now();
@@ -34,8 +35,7 @@ describe("renderChart", () => {
     let hasPixels = false;
     for (let x = 0; x < 64; x++) {
       for (let y = 40; y < 60; y++) {
-        const pixel = getPixel(frame, x, y);
-        if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+        if (getPixelValue(frame, x, y) > 0) {
           hasPixels = true;
           break;
         }
@@ -66,8 +66,7 @@ describe("renderChart", () => {
     let hasPixels = false;
     for (let x = 0; x < 64; x++) {
       for (let y = 40; y < 60; y++) {
-        const pixel = getPixel(frame, x, y);
-        if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+        if (getPixelValue(frame, x, y) > 0) {
           hasPixels = true;
           break;
         }
@@ -99,8 +98,7 @@ describe("renderChart", () => {
     let hasPixels = false;
     for (let x = 0; x < 64; x++) {
       for (let y = 40; y < 60; y++) {
-        const pixel = getPixel(frame, x, y);
-        if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+        if (getPixelValue(frame, x, y) > 0) {
           hasPixels = true;
           break;
         }
@@ -246,8 +244,7 @@ describe("renderChart", () => {
     let hasPixelsInBounds = false;
     for (let x = 10; x < 40; x++) {
       for (let y = 40; y < 55; y++) {
-        const pixel = getPixel(frame, x, y);
-        if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+        if (getPixelValue(frame, x, y) > 0) {
           hasPixelsInBounds = true;
           break;
         }
@@ -283,8 +280,7 @@ describe("renderChart", () => {
     let markerPixelCount = 0;
     const expectedX = Math.round((1.5 / 3) * 63); // ~32
     for (let y = 40; y < 60; y++) {
-      const pixel = getPixel(frame, expectedX, y);
-      if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+      if (getPixelValue(frame, expectedX, y) > 0) {
         markerPixelCount++;
       }
     }
@@ -318,8 +314,7 @@ describe("renderChart", () => {
     let hasPixels = false;
     for (let x = 0; x < 64; x++) {
       for (let y = 40; y < 60; y++) {
-        const pixel = getPixel(frame, x, y);
-        if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+        if (getPixelValue(frame, x, y) > 0) {
           hasPixels = true;
           break;
         }
@@ -376,4 +371,393 @@ describe("renderChart", () => {
     expect(narrowHasPixels).toBe(true);
     expect(wideHasPixels).toBe(true);
   });
+
+  describe("target band", () => {
+    // Visible range 45-265 mg/dL over rows 40-59: 180 lands on row 47, 70 on row 57.
+    // The line starts at x=21, so columns left of it only show the band.
+    const points: ChartPoint[] = [
+      { timestamp: now - 2 * 60 * 60 * 1000, glucose: 60 },
+      { timestamp: now - 1 * 60 * 60 * 1000, glucose: 250 },
+      { timestamp: now, glucose: 150 },
+    ];
+    const config: ChartConfig = { x: 0, y: 40, width: 64, height: 20, hours: 3 };
+    const black = { r: 0, g: 0, b: 0 };
+
+    it("is off by default", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, config);
+
+      expect(getPixel(frame, 5, 52)).toEqual(black);
+    });
+
+    it("shades the target range", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, targetBand: {} });
+
+      expect(getPixel(frame, 5, 47)).toEqual(COLORS.chartTarget);
+      expect(getPixel(frame, 5, 57)).toEqual(COLORS.chartTarget);
+      expect(getPixel(frame, 5, 46)).toEqual(black);
+      expect(getPixel(frame, 5, 58)).toEqual(black);
+    });
+
+    it("outlines the range edges with dotted lines", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, targetBand: { style: "outline" } });
+
+      expect(getPixel(frame, 4, 47)).toEqual(COLORS.chartTarget);
+      expect(getPixel(frame, 5, 47)).toEqual(black);
+      expect(getPixel(frame, 4, 52)).toEqual(black);
+    });
+
+    it("uses a custom range", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, targetBand: { low: 100, high: 140 } });
+
+      expect(getPixel(frame, 5, 47)).toEqual(black);
+      expect(getPixel(frame, 5, 53)).toEqual(COLORS.chartTarget);
+    });
+
+    it("leaves pixels that were already drawn", () => {
+      const frame = createSolidFrame(64, 64);
+      const legend = { r: 35, g: 35, b: 35 };
+      setPixel(frame, 5, 52, legend);
+
+      renderChart(frame, points, { ...config, targetBand: {} });
+
+      expect(getPixel(frame, 5, 52)).toEqual(legend);
+    });
+  });
+
+  describe("gridlines", () => {
+    // Same chart as the target band tests: 180 on row 47, 70 on row 57
+    const points: ChartPoint[] = [
+      { timestamp: now - 2 * 60 * 60 * 1000, glucose: 60 },
+      { timestamp: now - 1 * 60 * 60 * 1000, glucose: 250 },
+      { timestamp: now, glucose: 150 },
+    ];
+    const config: ChartConfig = { x: 0, y: 40, width: 64, height: 20, hours: 3 };
+    const black = { r: 0, g: 0, b: 0 };
+
+    it("draws dotted lines at visible values only", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, gridlines: [70, 180, 350] });
+
+      expect(getPixel(frame, 3, 47)).toEqual(COLORS.chartGrid);
+      expect(getPixel(frame, 4, 47)).toEqual(black);
+      expect(getPixel(frame, 3, 57)).toEqual(COLORS.chartGrid);
+      // 350 is above the visible range: nothing drawn on the top row
+      expect(getPixel(frame, 3, 40)).toEqual(black);
+    });
+
+    it("labels lines at the left edge when asked", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, gridlines: [180], gridLabels: true });
+
+      // "180" rows 45-49; the 1 is a vertical stroke in column 1
+      expect(getPixel(frame, 1, 45)).toEqual(COLORS.chartGridLabel);
+      expect(getPixel(frame, 1, 49)).toEqual(COLORS.chartGridLabel);
+    });
+
+    it("skips labels that would overlap", () => {
+      const frame = createSolidFrame(64, 64);
+      // 180 and 170 are a row apart; only 180's label fits
+      renderChart(frame, points, { ...config, gridlines: [170, 180], gridLabels: true });
+
+      let labelPixels = 0;
+      for (let py = 40; py < 60; py++) {
+        for (let px = 0; px < 12; px++) {
+          const p = getPixel(frame, px, py);
+          if (p && p.r === COLORS.chartGridLabel.r) labelPixels++;
+        }
+      }
+      const single = createSolidFrame(64, 64);
+      renderChart(single, points, { ...config, gridlines: [180], gridLabels: true });
+      let singlePixels = 0;
+      for (let py = 40; py < 60; py++) {
+        for (let px = 0; px < 12; px++) {
+          const p = getPixel(single, px, py);
+          if (p && p.r === COLORS.chartGridLabel.r) singlePixels++;
+        }
+      }
+      expect(labelPixels).toBe(singlePixels);
+    });
+  });
+
+  describe("trend projection", () => {
+    // Falling 2 mg/dL per minute; the 3h + 30m axis puts now at x=54
+    const points: ChartPoint[] = [
+      { timestamp: now - 10 * 60 * 1000, glucose: 150 },
+      { timestamp: now - 5 * 60 * 1000, glucose: 140 },
+      { timestamp: now, glucose: 130 },
+    ];
+    const config: ChartConfig = { x: 0, y: 40, width: 64, height: 20, hours: 3 };
+
+    function columnMax(frame: ReturnType<typeof createSolidFrame>, px: number): number {
+      let max = 0;
+      for (let py = 40; py < 60; py++) {
+        const p = getPixel(frame, px, py);
+        if (p) max = Math.max(max, p.r, p.g, p.b);
+      }
+      return max;
+    }
+
+    it("projects along the recent slope", () => {
+      expect(projectTrend(points, 30, now)).toEqual({ timestamp: now + 30 * 60 * 1000, glucose: 70 });
+    });
+
+    it("needs recent readings", () => {
+      expect(projectTrend(points, 30, now + 20 * 60 * 1000)).toBeNull();
+      expect(projectTrend(points.slice(2), 30, now)).toBeNull();
+    });
+
+    it("is off by default", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, config);
+
+      // Latest reading at the right edge, nothing after it
+      expect(columnMax(frame, 63)).toBeGreaterThan(0);
+    });
+
+    it("draws a dimmed dotted line after the latest reading", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, projectionMinutes: 30 });
+
+      expect(columnMax(frame, 54)).toBeGreaterThan(0);
+      expect(columnMax(frame, 55)).toBe(0);
+      expect(columnMax(frame, 62)).toBeGreaterThan(0);
+      expect(columnMax(frame, 62)).toBeLessThanOrEqual(Math.round(255 * 0.45));
+    });
+
+    it("takes a line style of its own", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, projectionMinutes: 30, projectionStyle: {} });
+
+      // Solid: no gap after the latest reading
+      expect(columnMax(frame, 55)).toBeGreaterThan(0);
+      expect(columnMax(frame, 55)).toBeLessThanOrEqual(Math.round(255 * 0.45));
+    });
+
+        it("skips charts that end in the past", () => {
+      const frame = createSolidFrame(64, 64);
+      const earlier = points.map((p) => ({ ...p, timestamp: p.timestamp - 60 * 60 * 1000 }));
+      renderChart(frame, earlier, { ...config, offsetHours: 1, projectionMinutes: 30 });
+
+      // Same axis as without a projection: the latest reading stays at the right edge
+      expect(columnMax(frame, 63)).toBeGreaterThan(0);
+    });
+  });
+
+  describe("anti-aliasing", () => {
+    // A shallow climb from x=21 to x=63, so each column crosses the line once
+    const points: ChartPoint[] = [
+      { timestamp: now - 2 * 60 * 60 * 1000, glucose: 100 },
+      { timestamp: now, glucose: 200 },
+    ];
+    const config: ChartConfig = { x: 0, y: 40, width: 64, height: 20, hours: 3 };
+
+    function litInColumn(frame: ReturnType<typeof createSolidFrame>, px: number): number {
+      let lit = 0;
+      for (let py = 40; py < 60; py++) {
+        if (getPixelValue(frame, px, py) > 0) lit++;
+      }
+      return lit;
+    }
+
+    it("is off by default", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, config);
+
+      for (let px = 22; px < 63; px++) {
+        expect(litInColumn(frame, px)).toBe(1);
+      }
+    });
+
+    it("spreads the line over neighbouring pixels, dimmed by coverage", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, antialias: true });
+
+      let shared = 0;
+      for (let px = 22; px < 63; px++) {
+        const lit = litInColumn(frame, px);
+        expect(lit).toBeGreaterThanOrEqual(1);
+        expect(lit).toBeLessThanOrEqual(2);
+        if (lit === 2) shared++;
+      }
+      expect(shared).toBeGreaterThan(0);
+
+      // Some pixels are only partly lit
+      let dimmed = false;
+      for (let px = 22; px < 63 && !dimmed; px++) {
+        for (let py = 40; py < 60; py++) {
+          const p = getPixel(frame, px, py);
+          if (p && getPixelValue(frame, px, py) > 0 && Math.max(p.r, p.g, p.b) < 128) dimmed = true;
+        }
+      }
+      expect(dimmed).toBe(true);
+    });
+
+    it("keeps the readings as whole pixels", () => {
+      const plain = createSolidFrame(64, 64);
+      const smooth = createSolidFrame(64, 64);
+      renderChart(plain, points, config);
+      renderChart(smooth, points, { ...config, antialias: true });
+
+      for (const px of [21, 63]) {
+        for (let py = 40; py < 60; py++) {
+          expect(getPixel(smooth, px, py)).toEqual(getPixel(plain, px, py));
+        }
+      }
+    });
+  });
+
+  describe("line styles", () => {
+    // The same shallow climb from x=21 to x=63
+    const points: ChartPoint[] = [
+      { timestamp: now - 2 * 60 * 60 * 1000, glucose: 100 },
+      { timestamp: now, glucose: 200 },
+    ];
+    const config: ChartConfig = { x: 0, y: 40, width: 64, height: 20, hours: 3 };
+
+    function litInColumn(frame: ReturnType<typeof createSolidFrame>, px: number): number {
+      let lit = 0;
+      for (let py = 40; py < 60; py++) {
+        if (getPixelValue(frame, px, py) > 0) lit++;
+      }
+      return lit;
+    }
+
+    it("draws a thick line two pixels tall", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, lineStyle: { thickness: 2 } });
+
+      for (let px = 22; px < 63; px++) {
+        expect(litInColumn(frame, px)).toBe(2);
+      }
+    });
+
+    it("caps the thickness", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, lineStyle: { thickness: 10 } });
+
+      expect(litInColumn(frame, 40)).toBe(3);
+    });
+
+    it("leaves gaps in a dashed line, counted from the chart's left edge", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, lineStyle: { dash: [2, 2] } });
+
+      expect(litInColumn(frame, 22)).toBe(0);
+      expect(litInColumn(frame, 23)).toBe(0);
+      expect(litInColumn(frame, 24)).toBe(1);
+      expect(litInColumn(frame, 25)).toBe(1);
+      // Readings are drawn whether or not they fall in a gap
+      expect(litInColumn(frame, 63)).toBe(1);
+    });
+
+    it("dashes and thickens anti-aliased lines too", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, antialias: true, lineStyle: { thickness: 2, dash: [2, 2] } });
+
+      expect(litInColumn(frame, 22)).toBe(0);
+      expect(litInColumn(frame, 24)).toBeGreaterThanOrEqual(2);
+    });
+  });
+
+  describe("fill", () => {
+    // The same shallow climb from x=21 (row 57) to x=63 (row 42)
+    const points: ChartPoint[] = [
+      { timestamp: now - 2 * 60 * 60 * 1000, glucose: 100 },
+      { timestamp: now, glucose: 200 },
+    ];
+    const config: ChartConfig = { x: 0, y: 40, width: 64, height: 20, hours: 3 };
+
+    it("is off by default", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, config);
+
+      expect(getPixelValue(frame, 63, 59)).toBe(0);
+    });
+
+    it("shades under the line only, dimmer toward the bottom", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, fill: true });
+
+      // Above the line and before the first reading stay black
+      expect(getPixelValue(frame, 63, 41)).toBe(0);
+      expect(getPixelValue(frame, 20, 59)).toBe(0);
+
+      const line = getPixel(frame, 63, 42)!;
+      const under = getPixel(frame, 63, 43)!;
+      const bottom = getPixel(frame, 63, 59)!;
+      expect(Math.max(under.r, under.g, under.b)).toBeGreaterThan(0);
+      expect(Math.max(under.r, under.g, under.b)).toBeLessThan(Math.max(line.r, line.g, line.b));
+      expect(Math.max(bottom.r, bottom.g, bottom.b)).toBeGreaterThan(0);
+      expect(Math.max(bottom.r, bottom.g, bottom.b)).toBeLessThan(Math.max(under.r, under.g, under.b));
+    });
+
+    it("covers the target band below the line", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, fill: true, targetBand: {} });
+
+      expect(getPixel(frame, 63, 50)).not.toEqual(COLORS.chartTarget);
+    });
+  });
+
+  describe("overlay", () => {
+    // Flat glucose at row 49; heart rate climbing 60 to 120 bpm over the same span
+    const points: ChartPoint[] = [
+      { timestamp: now - 2 * 60 * 60 * 1000, glucose: 100 },
+      { timestamp: now - 1 * 60 * 60 * 1000, glucose: 100 },
+      { timestamp: now, glucose: 100 },
+    ];
+    const blue = { r: 0, g: 0, b: 255 };
+    const overlay = {
+      points: [
+        { timestamp: now - 2 * 60 * 60 * 1000, value: 60 },
+        { timestamp: now, value: 120 },
+      ],
+      color: blue,
+    };
+    const config: ChartConfig = { x: 0, y: 40, width: 64, height: 20, hours: 3 };
+
+    function hasBlue(frame: ReturnType<typeof createSolidFrame>, x0: number, x1: number, y0: number, y1: number) {
+      for (let px = x0; px <= x1; px++) {
+        for (let py = y0; py <= y1; py++) {
+          if (getPixelValue(frame, px, py) === 0x0000ff) return true;
+        }
+      }
+      return false;
+    }
+
+    it("scales the second series to the full height on its own", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, overlay });
+
+      expect(getPixel(frame, 21, 59)).toEqual(blue);
+      expect(getPixel(frame, 63, 40)).toEqual(blue);
+      // The glucose line stays on top
+      expect(getPixel(frame, 42, 49)).not.toEqual(blue);
+    });
+
+    it("is dashed by default, and takes a fixed scale", () => {
+      const frame = createSolidFrame(64, 64);
+      renderChart(frame, points, { ...config, overlay });
+      expect(hasBlue(frame, 23, 23, 40, 59)).toBe(false);
+
+      const scaled = createSolidFrame(64, 64);
+      renderChart(scaled, points, { ...config, overlay: { ...overlay, min: 0, max: 240 } });
+      // 60 of 0-240 bpm is a quarter of the way up
+      expect(getPixel(scaled, 21, 54)).toEqual(blue);
+    });
+
+    it("labels its scale at the right edge when asked", () => {
+      const plain = createSolidFrame(64, 64);
+      renderChart(plain, points, { ...config, overlay });
+      expect(hasBlue(plain, 57, 63, 55, 59)).toBe(false);
+
+      const labeled = createSolidFrame(64, 64);
+      renderChart(labeled, points, { ...config, overlay: { ...overlay, labels: true } });
+      expect(hasBlue(labeled, 57, 63, 55, 59)).toBe(true);
+    });
+  });
 });
@@ -3,8 +3,9 @@
  */
 
 import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
-import { createSolidFrame, getPixel } from "@signage/core";
-import { renderClockRegion, type ClockWeatherData } from "../clock-renderer.js";
+import { createSolidFrame, diffFrames, getPixel, getPixelValue } from "@signage/core";
+import { renderClockRegion, renderLargeClockRegion, type ClockWeatherData } from "../clock-renderer.js";
+import { COLORS } from "../colors.js";
 
 describe("renderClockRegion", () => {
   beforeEach(() => {
@@ -25,8 +26,7 @@ describe("renderClockRegion", () => {
     // Check that some pixels are set in the time area (row 4)
     let hasPixels = false;
     for (let x = 0; x < 64; x++) {
-      const pixel = getPixel(frame, x, 4);
-      if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+      if (getPixelValue(frame, x, 4) > 0) {
         hasPixels = true;
         break;
       }
@@ -42,8 +42,8 @@ describe("renderClockRegion", () => {
     // Format: "SAT JAN 24 2:30" (15 chars ≈ 59 pixels)
     let pixelCount = 0;
     for (let x = 0; x < 64; x++) {
-      const pixel = getPixel(frame, x, 4); // Middle of text (row 1 + 3)
-      if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+      // Middle of text (row 1 + 3)
+      if (getPixelValue(frame, x, 4) > 0) {
         pixelCount++;
       }
     }
@@ -119,8 +119,7 @@ describe("renderClockRegion", () => {
     let hasRightPixels = false;
     for (let x = 32; x < 64; x++) {
       for (let y = 0; y < 32; y++) {
-        const pixel = getPixel(frame, x, y);
-        if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+        if (getPixelValue(frame, x, y) > 0) {
           hasRightPixels = true;
           break;
         }
@@ -155,4 +154,229 @@ describe("renderClockRegion", () => {
     expect(pacificHasPixels).toBe(true);
     expect(easternHasPixels).toBe(true);
   });
+
+  it("renders the time it is given", () => {
+    const current = createSolidFrame(64, 64);
+    const earlier = createSolidFrame(64, 64);
+    renderClockRegion(current, "America/Los_Angeles");
+    renderClockRegion(earlier, "America/Los_Angeles", undefined, undefined, "en", { now: Date.now() - 60_000 });
+
+    expect(diffFrames(earlier, current)).not.toBeNull();
+  });
+
+  it("keeps seconds inside the row, so a tick only changes the last digits", () => {
+    const at = (seconds: number) => {
+      const frame = createSolidFrame(64, 64);
+      renderClockRegion(frame, "America/Los_Angeles", undefined, undefined, "en", {
+        now: Date.now() + seconds * 1000,
+        seconds: true,
+      });
+      return frame;
+    };
+
+    const changed = diffFrames(at(5), at(6));
+    expect(changed).not.toBeNull();
+    expect(changed!.x + changed!.width).toBeLessThanOrEqual(64);
+    expect(changed!.width).toBeLessThanOrEqual(4);
+    expect(changed!.y + changed!.height).toBeLessThanOrEqual(7);
+  });
+});
+
+describe("renderLargeClockRegion", () => {
+  beforeEach(() => {
+    vi.useFakeTimers();
+    // 2:30 PM Pacific
+    vi.setSystemTime(new Date("2026-01-24T22:30:00Z"));
+  });
+
+  afterEach(() => {
+    vi.useRealTimers();
+  });
+
+  function litRows(frame: ReturnType<typeof createSolidFrame>, color: { r: number; g: number; b: number }) {
+    const rows = new Set<number>();
+    for (let y = 0; y < 64; y++) {
+      for (let x = 0; x < 64; x++) {
+        const pixel = getPixel(frame, x, y);
+        if (pixel && pixel.r === color.r && pixel.g === color.g && pixel.b === color.b) rows.add(y);
+      }
+    }
+    return [...rows];
+  }
+
+  it("draws 12px tall digits centered in the top 18 rows", () => {
+    const frame = createSolidFrame(64, 64);
+    renderLargeClockRegion(frame, "America/Los_Angeles");
+
+    const rows = litRows(frame, { r: 255, g: 255, b: 255 });
+    expect(Math.min(...rows)).toBe(3);
+    expect(Math.max(...rows)).toBe(14);
+  });
+
+  it("adds a dim AM/PM marker on the digit baseline", () => {
+    const frame = createSolidFrame(64, 64);
+    renderLargeClockRegion(frame, "America/Los_Angeles");
+
+    const rows = litRows(frame, { r: 100, g: 100, b: 100 });
+    expect(Math.max(...rows)).toBe(14);
+    expect(Math.min(...rows)).toBe(10);
+  });
+
+  it("stays inside its bounds", () => {
+    const frame = createSolidFrame(64, 64);
+    renderLargeClockRegion(frame, "America/Los_Angeles", { startX: 0, endX: 63, startY: 20, endY: 37 });
+
+    const rows = litRows(frame, { r: 255, g: 255, b: 255 });
+    expect(Math.min(...rows)).toBeGreaterThanOrEqual(20);
+    expect(Math.max(...rows)).toBeLessThanOrEqual(37);
+  });
+
+  it("keeps the marker next to seconds while it fits", () => {
+    const frame = createSolidFrame(64, 64);
+    renderLargeClockRegion(frame, "America/Los_Angeles", undefined, { seconds: true });
+
+    expect(litRows(frame, { r: 100, g: 100, b: 100 })).not.toHaveLength(0);
+  });
+
+  it("drops the marker when seconds leave no room", () => {
+    const frame = createSolidFrame(64, 64);
+    // 12:30:00 PM Pacific
+    renderLargeClockRegion(frame, "America/Los_Angeles", undefined, {
+      now: Date.parse("2026-01-24T20:30:00Z"),
+      seconds: true,
+    });
+
+    expect(litRows(frame, { r: 100, g: 100, b: 100 })).toHaveLength(0);
+    expect(litRows(frame, { r: 255, g: 255, b: 255 })).not.toHaveLength(0);
+  });
+});
+
+describe("clock formats", () => {
+  // 2:30:00 PM Pacific
+  const now = Date.parse("2026-01-24T22:30:00Z");
+
+  function render(format: "12h" | "12h-ampm" | "24h", large = false) {
+    const frame = createSolidFrame(64, 64);
+    if (large) {
+      renderLargeClockRegion(frame, "America/Los_Angeles", undefined, { now, format });
+    } else {
+      renderClockRegion(frame, "America/Los_Angeles", undefined, undefined, "en", { now, format });
+    }
+    return frame;
+  }
+
+  function brightColumns(frame: ReturnType<typeof createSolidFrame>) {
+    const columns: number[] = [];
+    for (let x = 0; x < 64; x++) {
+      for (let y = 0; y < 18; y++) {
+        const pixel = getPixel(frame, x, y);
+        if (pixel && pixel.r === 255 && pixel.g === 255 && pixel.b === 255) {
+          columns.push(x);
+          break;
+        }
+      }
+    }
+    return columns;
+  }
+
+  it("writes 24h time with two-digit hours", () => {
+    // "14:30" is one digit wider than "2:30"
+    expect(brightColumns(render("24h")).length).toBeGreaterThan(brightColumns(render("12h")).length);
+  });
+
+  it("adds AM/PM to the date-line clock only when asked", () => {
+    expect(diffFrames(render("12h"), render("12h-ampm"))).not.toBeNull();
+    expect(diffFrames(render("12h"), render("12h"))).toBeNull();
+  });
+
+  it("leaves the large clock's marker out unless the format has it", () => {
+    const dim = (frame: ReturnType<typeof createSolidFrame>) => {
+      let count = 0;
+      for (let i = 0; i < frame.pixels.length; i += 3) {
+        if (frame.pixels[i] === 100 && frame.pixels[i + 1] === 100 && frame.pixels[i + 2] === 100) count++;
+      }
+      return count;
+    };
+
+    expect(dim(render("12h-ampm", true))).toBeGreaterThan(0);
+    expect(dim(render("12h", true))).toBe(0);
+    expect(dim(render("24h", true))).toBe(0);
+  });
+});
+
+describe("clock across daylight-saving changes", () => {
+  function render(timezone: string, now: number) {
+    const frame = createSolidFrame(64, 64);
+    renderClockRegion(frame, timezone, undefined, undefined, "en", { now });
+    return frame;
+  }
+
+  // Etc/GMT+8 is a fixed UTC-8 (the sign is inverted), so it's PST all year
+  it("follows the zone's offset on either side of spring forward", () => {
+    // 1:30 PST, then 3:30 PDT on March 8, 2026
+    const before = Date.parse("2026-03-08T09:30:00Z");
+    const after = Date.parse("2026-03-08T10:30:00Z");
+
+    expect(diffFrames(render("America/Los_Angeles", before), render("Etc/GMT+8", before))).toBeNull();
+    expect(diffFrames(render("America/Los_Angeles", after), render("Etc/GMT+7", after))).toBeNull();
+  });
+
+  it("shows the repeated hour twice at fall back", () => {
+    // 1:30 PDT, then 1:30 PST on November 1, 2026
+    const first = render("America/Los_Angeles", Date.parse("2026-11-01T08:30:00Z"));
+    const second = render("America/Los_Angeles", Date.parse("2026-11-01T09:30:00Z"));
+
+    expect(diffFrames(first, second)).toBeNull();
+  });
+
+  it("renders any configured zone", () => {
+    const now = Date.parse("2026-07-01T12:00:00Z");
+
+    expect(diffFrames(render("Europe/Berlin", now), render("Etc/GMT-2", now))).toBeNull();
+    expect(diffFrames(render("Europe/Berlin", now), render("America/Los_Angeles", now))).not.toBeNull();
+  });
+});
+
+describe("moon phase", () => {
+  // 10:30 PM and 2:30 PM Pacific, five days before the January 2026 full moon
+  const night = Date.parse("2026-01-29T06:30:00Z");
+  const day = Date.parse("2026-01-28T22:30:00Z");
+
+  function moonPixels(frame: ReturnType<typeof createSolidFrame>): number {
+    let count = 0;
+    for (let y = 0; y < 18; y++) {
+      for (let x = 0; x < 64; x++) {
+        const pixel = getPixel(frame, x, y);
+        if (pixel?.r === COLORS.moonLit.r || pixel?.r === COLORS.moonDark.r) count++;
+      }
+    }
+    return count;
+  }
+
+  function render(now: number, moon: boolean, large = false) {
+    const frame = createSolidFrame(64, 64);
+    if (large) {
+      renderLargeClockRegion(frame, "America/Los_Angeles", undefined, { now, moon });
+    } else {
+      renderClockRegion(frame, "America/Los_Angeles", undefined, undefined, "en", { now, moon });
+    }
+    return frame;
+  }
+
+  it("draws the moon before the date at night", () => {
+    const frame = render(night, true);
+    // 21 pixels make a 5x5 disk
+    expect(moonPixels(frame)).toBe(21);
+    expect(diffFrames(frame, render(night, false))).not.toBeNull();
+  });
+
+  it("draws the moon above the large clock's marker at night", () => {
+    expect(moonPixels(render(night, true, true))).toBe(21);
+  });
+
+  it("leaves the clock unchanged during the day or when off", () => {
+    expect(diffFrames(render(day, true), render(day, false))).toBeNull();
+    expect(diffFrames(render(day, true, true), render(day, false, true))).toBeNull();
+    expect(moonPixels(render(night, false))).toBe(0);
+  });
 });
@@ -0,0 +1,84 @@
+/**
+ * Tests for PNG and GIF frame export
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, setPixel, type Frame } from "@signage/core";
+import { encodeGif, encodePng } from "../export";
+import { decodeImage, type RgbaImage } from "../image";
+
+/** Drop the alpha channel for comparison with a frame */
+function toRgb(image: RgbaImage): number[] {
+  return Array.from(image.pixels).filter((_, i) => i % 4 !== 3);
+}
+
+function gradientFrame(): Frame {
+  const frame = createSolidFrame(64, 64);
+  for (let y = 0; y < 64; y++) {
+    for (let x = 0; x < 64; x++) {
+      setPixel(frame, x, y, { r: x * 4, g: y * 4, b: 128 });
+    }
+  }
+  return frame;
+}
+
+describe("encodePng", () => {
+  it("round-trips a frame exactly", () => {
+    const frame = gradientFrame();
+    const image = decodeImage(encodePng(frame));
+
+    expect(image.width).toBe(64);
+    expect(toRgb(image)).toEqual(Array.from(frame.pixels));
+  });
+
+  it("upscales by an integer factor", () => {
+    const frame = createSolidFrame(2, 2);
+    setPixel(frame, 1, 0, { r: 255, g: 255, b: 255 });
+    const image = decodeImage(encodePng(frame, { scale: 3 }));
+
+    expect(image.width).toBe(6);
+    expect(image.height).toBe(6);
+    expect(Array.from(image.pixels.subarray((2 * 6 + 3) * 4, (2 * 6 + 3) * 4 + 3))).toEqual([255, 255, 255]);
+  });
+});
+
+describe("encodeGif", () => {
+  it("round-trips a frame with 256 colors or fewer exactly", () => {
+    const frame = createSolidFrame(64, 64);
+    for (let i = 0; i < 64 * 64; i += 7) {
+      setPixel(frame, i % 64, Math.floor(i / 64), { r: (i * 37) % 128, g: 40, b: 200 });
+    }
+    const image = decodeImage(encodeGif([frame]));
+
+    expect(toRgb(image)).toEqual(Array.from(frame.pixels));
+  });
+
+  it("quantizes frames with more than 256 colors", () => {
+    const image = decodeImage(encodeGif([gradientFrame()]));
+    const original = Array.from(gradientFrame().pixels);
+
+    toRgb(image).forEach((value, i) => {
+      expect(Math.abs(value - original[i])).toBeLessThanOrEqual(43);
+    });
+  });
+
+  it("writes every frame with a loop extension", () => {
+    const frames = [createSolidFrame(8, 8), createSolidFrame(8, 8, { r: 255, g: 0, b: 0 })];
+    const gif = encodeGif(frames, { frameDelayMs: 200 });
+
+    const controlBlock = Buffer.from([0x21, 0xf9, 0x04]);
+    let frameCount = 0;
+    for (let at = gif.indexOf(controlBlock); at !== -1; at = gif.indexOf(controlBlock, at + 1)) {
+      frameCount++;
+      expect(gif.readUInt16LE(at + 4)).toBe(20); // 200ms in hundredths
+    }
+
+    expect(gif.includes(Buffer.from("NETSCAPE2.0"))).toBe(true);
+    expect(frameCount).toBe(2);
+  });
+
+  it("rejects empty and mismatched frame lists", () => {
+    expect(() => encodeGif([])).toThrow();
+    expect(() => encodeGif([createSolidFrame(8, 8), createSolidFrame(4, 4)])).toThrow(/same size/);
+  });
+});
@@ -0,0 +1,58 @@
+/**
+ * Rendering benchmarks: `pnpm bench`
+ *
+ * Composition runs once a minute in the compositor, but every step of a
+ * seconds clock, marquee or transition on the local server. "uncached"
+ * redraws every widget, as after a new reading; "cached" reuses the widget
+ * surfaces, as between ticks of a seconds clock.
+ */
+
+import { bench, describe } from "vitest";
+import { createSolidFrame } from "@signage/core";
+import { clearSurfaceCache, generateCompositeFrame, type CompositorData } from "../frame-composer.js";
+import { renderChart } from "../chart-renderer.js";
+import { LAYOUTS } from "../layouts.js";
+
+const NOW = Date.now();
+
+// A day of readings every 5 minutes, drifting through the target range
+const points = Array.from({ length: 288 }, (_, i) => ({
+  timestamp: NOW - (287 - i) * 5 * 60_000,
+  glucose: Math.round(140 + 60 * Math.sin(i / 20)),
+}));
+
+const data: CompositorData = {
+  bloodSugar: { glucose: 142, trend: "Flat", delta: 2, timestamp: NOW, rangeStatus: "normal", isStale: false },
+  bloodSugarHistory: { points },
+  timezone: "America/Los_Angeles",
+  layout: LAYOUTS.day,
+  now: NOW,
+};
+
+describe("generateCompositeFrame", () => {
+  bench("uncached", () => {
+    clearSurfaceCache();
+    generateCompositeFrame(data);
+  });
+
+  bench("cached", () => {
+    generateCompositeFrame(data);
+  });
+});
+
+describe("renderChart", () => {
+  const frame = createSolidFrame(64, 64);
+
+  bench("3 hours with target band and gridlines", () => {
+    renderChart(frame, points, {
+      x: 0,
+      y: 34,
+      width: 64,
+      height: 30,
+      targetBand: { style: "shade" },
+      gridlines: [70, 180],
+      projectionMinutes: 15,
+      timezone: "America/Los_Angeles",
+    });
+  });
+});
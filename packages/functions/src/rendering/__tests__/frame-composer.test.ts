@@ -3,8 +3,10 @@
  */
 
 import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
-import { getPixel } from "@signage/core";
+import { getPixel, getPixelValue } from "@signage/core";
 import { generateCompositeFrame, type CompositorData } from "../frame-composer.js";
+import { LAYOUTS } from "../layouts.js";
+import { COLORS } from "../colors.js";
 
 describe("generateCompositeFrame", () => {
   beforeEach(() => {
@@ -39,8 +41,7 @@ describe("generateCompositeFrame", () => {
     // Check for pixels in clock region (top half)
     let hasClockPixels = false;
     for (let x = 0; x < 64; x++) {
-      const pixel = getPixel(frame, x, 4);
-      if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+      if (getPixelValue(frame, x, 4) > 0) {
         hasClockPixels = true;
         break;
       }
@@ -67,8 +68,8 @@ describe("generateCompositeFrame", () => {
     // Glucose reading is now at rows 28-32 (above chart)
     let hasBloodSugarPixels = false;
     for (let x = 0; x < 64; x++) {
-      const pixel = getPixel(frame, x, 30); // Middle of text row (28-32)
-      if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+      // Middle of text row (28-32)
+      if (getPixelValue(frame, x, 30) > 0) {
         hasBloodSugarPixels = true;
         break;
       }
@@ -88,8 +89,7 @@ describe("generateCompositeFrame", () => {
     let hasErrorPixels = false;
     for (let x = 0; x < 64; x++) {
       for (let y = 28; y < 33; y++) { // Check text row area (28-32)
-        const pixel = getPixel(frame, x, y);
-        if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+        if (getPixelValue(frame, x, y) > 0) {
           hasErrorPixels = true;
           break;
         }
@@ -142,8 +142,7 @@ describe("generateCompositeFrame", () => {
     let hasChartPixels = false;
     for (let x = 0; x < 64; x++) {
       for (let y = 36; y < 60; y++) {
-        const pixel = getPixel(frame, x, y);
-        if (pixel && (pixel.r > 0 || pixel.g > 0 || pixel.b > 0)) {
+        if (getPixelValue(frame, x, y) > 0) {
           hasChartPixels = true;
           break;
         }
@@ -235,4 +234,405 @@ describe("generateCompositeFrame", () => {
     }
     expect(hasTreatmentPixels).toBe(false);
   });
+
+  it("shows the last bolus in the insight region when there is no insight", () => {
+    const now = Date.now();
+    const frame = generateCompositeFrame({
+      bloodSugar: null,
+      treatments: {
+        treatments: [{ timestamp: now - 2 * 60 * 60 * 1000, type: "insulin", value: 4, source: "manual" }],
+        recentInsulinUnits: 4,
+        recentCarbsGrams: 0,
+        lastFetchedAt: now,
+        isStale: false,
+      },
+    });
+
+    let hasStatusPixels = false;
+    for (let x = 0; x < 64; x++) {
+      if (getPixelValue(frame, x, 12) > 0) {
+        hasStatusPixels = true;
+        break;
+      }
+    }
+    expect(hasStatusPixels).toBe(true);
+  });
+
+  it("renders the top alert banner over the insight region in any layout", () => {
+    const frame = generateCompositeFrame({
+      bloodSugar: null,
+      layout: LAYOUTS["glucose-focus"],
+      alerts: [
+        { type: "urgentLowSoon", severity: "urgent", title: "LOW SOON", detail: "BELOW 55 IN 9M", raisedAt: Date.now() },
+      ],
+    });
+
+    expect(getPixel(frame, 0, 7)).toEqual({ r: 255, g: 0, b: 160 });
+  });
+
+  describe("layouts", () => {
+    const bloodSugar: CompositorData["bloodSugar"] = {
+      glucose: 120,
+      trend: "Flat",
+      delta: 5,
+      timestamp: Date.now(),
+      rangeStatus: "normal",
+      isStale: false,
+    };
+
+    function rowHasPixels(frame: ReturnType<typeof generateCompositeFrame>, y: number): boolean {
+      for (let x = 0; x < 64; x++) {
+        if (getPixelValue(frame, x, y) > 0) return true;
+      }
+      return false;
+    }
+
+    it("draws the rain bar below the insight in the day layout, only when rain is due", () => {
+      const now = Date.now();
+      const nowcast = (mmPerHour: number): CompositorData["precipitation"] => ({
+        latitude: 47.6,
+        longitude: -122.3,
+        fetchedAt: now,
+        steps: [1, 2, 3, 4].map((i) => ({ end: now + i * 15 * 60_000, mmPerHour })),
+      });
+
+      const rainy = generateCompositeFrame({ bloodSugar, timezone: "America/Los_Angeles", precipitation: nowcast(2.5) });
+      expect(getPixel(rainy, 10, 18)).toEqual(COLORS.rainModerate);
+
+      const dry = generateCompositeFrame({ bloodSugar, timezone: "America/Los_Angeles", precipitation: nowcast(0) });
+      expect(rowHasPixels(dry, 18)).toBe(false);
+    });
+
+    it("omits the clock in the glucose-focus layout", () => {
+      const frame = generateCompositeFrame({
+        bloodSugar,
+        timezone: "America/Los_Angeles",
+        layout: LAYOUTS["glucose-focus"],
+      });
+
+      expect(rowHasPixels(frame, 3)).toBe(false);
+      expect(rowHasPixels(frame, 30)).toBe(true);
+    });
+
+    it("shows the large clock in the night layout", () => {
+      const night = generateCompositeFrame({ bloodSugar, timezone: "America/Los_Angeles", layout: LAYOUTS.night });
+      const day = generateCompositeFrame({ bloodSugar, timezone: "America/Los_Angeles", layout: LAYOUTS.day });
+
+      // Rows 3-14 hold the 12px digits; the day layout's date line ends at row 6
+      expect(rowHasPixels(night, 12)).toBe(true);
+      expect(rowHasPixels(day, 12)).toBe(false);
+    });
+
+    it("dims the frame in the night layout", () => {
+      const day = generateCompositeFrame({ bloodSugar, layout: LAYOUTS.day });
+      const night = generateCompositeFrame({ bloodSugar, layout: LAYOUTS.night });
+
+      const maxChannel = (frame: typeof day) => Math.max(...frame.pixels);
+      expect(maxChannel(night)).toBeLessThan(maxChannel(day));
+      expect(maxChannel(night)).toBeGreaterThan(0);
+    });
+
+    it("warms the frame in the night layout", () => {
+      const night = generateCompositeFrame({ bloodSugar, layout: LAYOUTS.night });
+
+      let red = 0;
+      let blue = 0;
+      for (let i = 0; i < night.pixels.length; i += 3) {
+        red += night.pixels[i];
+        blue += night.pixels[i + 2];
+      }
+      expect(blue).toBeLessThan(red);
+    });
+
+    it("shows device statistics instead of glucose in the diagnostics layout", () => {
+      const frame = generateCompositeFrame({
+        bloodSugar,
+        timezone: "America/Los_Angeles",
+        layout: LAYOUTS.diagnostics,
+        deviceStats: [
+          {
+            deviceId: "pixoo",
+            sent: 24,
+            failed: 0,
+            successRate: 1,
+            avgLatencyMs: 85,
+            hourly: Array.from({ length: 24 }, () => ({ sent: 1, failed: 0 })),
+          },
+        ],
+      });
+
+      expect(rowHasPixels(frame, 3)).toBe(true);
+      // Hourly bars of the first device
+      expect(getPixel(frame, 8, 14)).toEqual(COLORS.normal);
+      // No glucose chart
+      expect(rowHasPixels(frame, 50)).toBe(false);
+    });
+
+    it("shows quotes instead of glucose in the markets layout", () => {
+      const frame = generateCompositeFrame({
+        bloodSugar,
+        timezone: "America/Los_Angeles",
+        layout: LAYOUTS.markets,
+        tickers: {
+          quotes: [
+            {
+              symbol: "AAPL",
+              label: "AAPL",
+              price: 187.32,
+              changePercent: 1.2,
+              timestamp: Date.now(),
+              history: [185, 187.32],
+            },
+          ],
+          sparkline: false,
+        },
+      });
+
+      expect(rowHasPixels(frame, 3)).toBe(true);
+      // The change, right-aligned on the first line
+      let up = false;
+      for (let x = 40; x < 64; x++) {
+        if (JSON.stringify(getPixel(frame, x, 10)) === JSON.stringify(COLORS.tickerUp)) up = true;
+      }
+      expect(up).toBe(true);
+      expect(rowHasPixels(frame, 50)).toBe(false);
+    });
+
+    it("shows the round trip and its sparkline in the network layout", () => {
+      const now = Date.now();
+      const frame = generateCompositeFrame({
+        bloodSugar,
+        timezone: "America/Los_Angeles",
+        layout: LAYOUTS.network,
+        network: {
+          host: "example.com",
+          samples: [
+            { timestamp: now - 120_000, pingMs: 30 },
+            { timestamp: now - 60_000, pingMs: 45, downloadMbps: 48.2 },
+            { timestamp: now, pingMs: 23 },
+          ],
+        },
+      });
+
+      expect(rowHasPixels(frame, 3)).toBe(true);
+      // Large ping digits in the quick color
+      let quick = false;
+      for (let y = 15; y < 27; y++) {
+        for (let x = 0; x < 64; x++) {
+          if (JSON.stringify(getPixel(frame, x, y)) === JSON.stringify(COLORS.normal)) quick = true;
+        }
+      }
+      expect(quick).toBe(true);
+      // Sparkline along the bottom
+      let sparkline = false;
+      for (let y = 38; y < 64; y++) {
+        if (rowHasPixels(frame, y)) sparkline = true;
+      }
+      expect(sparkline).toBe(true);
+    });
+
+    it("shows host metric bars in the system layout", () => {
+      const frame = generateCompositeFrame({
+        bloodSugar,
+        timezone: "America/Los_Angeles",
+        layout: LAYOUTS.system,
+        system: { hostname: "signage-pi", cpuPercent: 20, memoryPercent: 40, temperatureC: 50 },
+      });
+
+      expect(rowHasPixels(frame, 3)).toBe(true);
+      // CPU bar filled from the left
+      expect(getPixel(frame, 2, 24)).toEqual(COLORS.normal);
+    });
+  });
+
+  describe("pomodoro", () => {
+    const pomodoro: CompositorData["pomodoro"] = {
+      phase: "focus",
+      cycle: 1,
+      cycles: 4,
+      remainingMs: 20 * 60 * 1000,
+      progress: 0.2,
+      paused: false,
+      display: "region",
+    };
+    const bloodSugar: CompositorData["bloodSugar"] = {
+      glucose: 120,
+      trend: "Flat",
+      delta: 5,
+      timestamp: Date.now(),
+      rangeStatus: "normal",
+      isStale: false,
+    };
+
+    it("replaces the insight rows and keeps glucose", () => {
+      const frame = generateCompositeFrame({ bloodSugar, pomodoro, layout: LAYOUTS.day });
+
+      // Progress bar: filled in the focus color, then the track
+      expect(getPixel(frame, 2, 14)).toEqual(COLORS.pomodoroFocus);
+      expect(getPixel(frame, 60, 14)).toEqual(COLORS.separator);
+    });
+
+    it("swaps the large clock for the date line to make room", () => {
+      const frame = generateCompositeFrame({ bloodSugar, pomodoro, layout: LAYOUTS.night });
+
+      // Between the period and the minutes, where the large digits would be
+      for (let x = 22; x <= 33; x++) {
+        expect(getPixel(frame, x, 10)).toEqual(COLORS.bg);
+      }
+    });
+
+    it("takes over the whole display in full mode", () => {
+      const frame = generateCompositeFrame({
+        bloodSugar,
+        pomodoro: { ...pomodoro, display: "full" },
+        layout: LAYOUTS.day,
+      });
+
+      expect(getPixel(frame, 4, 38)).toEqual(COLORS.pomodoroFocus);
+      // No glucose chart
+      let chartPixels = 0;
+      for (let x = 0; x < 64; x++) {
+        if (getPixelValue(frame, x, 58) > 0) chartPixels++;
+      }
+      expect(chartPixels).toBe(0);
+    });
+  });
+
+  describe("now playing", () => {
+    const nowPlaying: CompositorData["nowPlaying"] = {
+      title: "Song",
+      artist: "Band",
+      durationMs: 200_000,
+      progressMs: 100_000,
+      isPlaying: true,
+      fetchedAt: Date.now(),
+    };
+    const insight: CompositorData["insight"] = {
+      content: "ALL GOOD",
+      type: "hourly",
+      generatedAt: Date.now(),
+      isStale: false,
+      status: "fresh",
+    };
+
+    it("takes the insight rows while playing", () => {
+      const frame = generateCompositeFrame({ bloodSugar: null, insight, nowPlaying, layout: LAYOUTS.day });
+
+      expect(getPixel(frame, 2, 16)).toEqual(COLORS.nowPlaying);
+    });
+
+    it("leaves the insight while paused, and gives way to a pomodoro", () => {
+      const paused = generateCompositeFrame({
+        bloodSugar: null,
+        insight,
+        nowPlaying: { ...nowPlaying!, isPlaying: false },
+        layout: LAYOUTS.day,
+      });
+      expect(getPixel(paused, 2, 16)).not.toEqual(COLORS.nowPlaying);
+
+      const frame = generateCompositeFrame({
+        bloodSugar: null,
+        nowPlaying,
+        pomodoro: {
+          phase: "focus",
+          cycle: 1,
+          cycles: 4,
+          remainingMs: 20 * 60 * 1000,
+          progress: 0.2,
+          paused: false,
+          display: "region",
+        },
+        layout: LAYOUTS.day,
+      });
+      expect(getPixel(frame, 2, 14)).toEqual(COLORS.pomodoroFocus);
+      expect(getPixel(frame, 2, 16)).not.toEqual(COLORS.nowPlaying);
+    });
+  });
+
+  describe("notification", () => {
+    const notification: CompositorData["notification"] = {
+      id: "1-abc",
+      text: "LAUNDRY DONE",
+      priority: "high",
+      seconds: 10,
+      createdAt: Date.now(),
+      shownAt: Date.now(),
+    };
+
+    it("covers the insight rows, or a playing track", () => {
+      const frame = generateCompositeFrame({
+        bloodSugar: null,
+        notification,
+        nowPlaying: { title: "Song", artist: "Band", durationMs: 200_000, progressMs: 100_000, isPlaying: true, fetchedAt: Date.now() },
+        layout: LAYOUTS.day,
+      });
+
+      expect(getPixel(frame, 0, 7)).toEqual(COLORS.notificationHigh);
+      expect(getPixel(frame, 2, 16)).toEqual(COLORS.notificationBackdrop);
+    });
+
+    it("stays under an alert", () => {
+      const frame = generateCompositeFrame({
+        bloodSugar: null,
+        notification,
+        alerts: [
+          { type: "urgentLowSoon", severity: "urgent", title: "LOW SOON", detail: "BELOW 55 IN 9M", raisedAt: Date.now() },
+        ],
+      });
+
+      expect(getPixel(frame, 0, 7)).toEqual(COLORS.alertAccent);
+    });
+  });
+
+  describe("text banner", () => {
+    it("takes the insight rows from a playing track", () => {
+      const frame = generateCompositeFrame({
+        bloodSugar: null,
+        textBanner: "DOOR: OPEN",
+        nowPlaying: { title: "Song", artist: "Band", durationMs: 200_000, progressMs: 100_000, isPlaying: true, fetchedAt: Date.now() },
+        layout: LAYOUTS.day,
+      });
+
+      expect(getPixel(frame, 0, 7)).toEqual(COLORS.textBanner);
+      expect(getPixel(frame, 2, 16)).toEqual(COLORS.notificationBackdrop);
+    });
+
+    it("gives way to a notification", () => {
+      const frame = generateCompositeFrame({
+        bloodSugar: null,
+        textBanner: "DOOR: OPEN",
+        notification: { id: "1-abc", text: "LAUNDRY", priority: "high", seconds: 10, createdAt: Date.now() },
+        layout: LAYOUTS.day,
+      });
+
+      expect(getPixel(frame, 0, 7)).toEqual(COLORS.notificationHigh);
+    });
+  });
+
+  describe("layers", () => {
+    const alerts: CompositorData["alerts"] = [
+      { type: "urgentLowSoon", severity: "urgent", title: "LOW SOON", detail: "BELOW 55 IN 9M", raisedAt: Date.now() },
+    ];
+
+    it("leaves out a hidden alerts layer", () => {
+      const frame = generateCompositeFrame({ bloodSugar: null, alerts, hiddenLayers: ["alerts"] });
+
+      expect(getPixel(frame, 0, 7)).not.toEqual({ r: 255, g: 0, b: 160 });
+    });
+
+    it("keeps other layers when widgets are hidden", () => {
+      const frame = generateCompositeFrame({
+        bloodSugar: null,
+        timezone: "America/Los_Angeles",
+        alerts,
+        hiddenLayers: ["widgets"],
+      });
+
+      // No clock, but the alert banner still shows
+      for (let x = 0; x < 64; x++) {
+        expect(getPixel(frame, x, 3)).toEqual({ r: 0, g: 0, b: 0 });
+      }
+      expect(getPixel(frame, 0, 7)).toEqual({ r: 255, g: 0, b: 160 });
+    });
+  });
 });
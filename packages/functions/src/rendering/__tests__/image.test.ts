@@ -0,0 +1,131 @@
+/**
+ * Tests for image decoding, scaling, and drawing
+ */
+
+import { describe, it, expect } from "vitest";
+import { deflateSync } from "zlib";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { decodeImage, drawImage, fitImageSize, scaleImage, type RgbaImage } from "../image";
+
+/** Build a minimal PNG (CRCs are not checked by the decoder) */
+function createPng(width: number, height: number, colorType: number, rows: number[][], extra: Buffer[] = []): Buffer {
+  const chunk = (type: string, data: Buffer) => {
+    const length = Buffer.alloc(4);
+    length.writeUInt32BE(data.length);
+    return Buffer.concat([length, Buffer.from(type, "ascii"), data, Buffer.alloc(4)]);
+  };
+  const header = Buffer.alloc(13);
+  header.writeUInt32BE(width, 0);
+  header.writeUInt32BE(height, 4);
+  header[8] = 8;
+  header[9] = colorType;
+  const raw = Buffer.concat(rows.map((row) => Buffer.from([0, ...row])));
+  return Buffer.concat([
+    Buffer.from([0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a]),
+    chunk("IHDR", header),
+    ...extra,
+    chunk("IDAT", deflateSync(raw)),
+    chunk("IEND", Buffer.alloc(0)),
+  ]);
+}
+
+/** 3x2 GIF: red red green / transparent red red */
+const TEST_GIF = Buffer.from(
+  "R0lGODlhAwACAIEAAAAAAP8AAAD/AAAA/yH5BAEAAAMALAAAAAADAAIAAAIDTDRWADs=",
+  "base64"
+);
+
+function solidImage(width: number, height: number, rgba: number[]): RgbaImage {
+  const pixels = new Uint8Array(width * height * 4);
+  for (let i = 0; i < width * height; i++) pixels.set(rgba, i * 4);
+  return { width, height, pixels };
+}
+
+describe("decodeImage", () => {
+  it("decodes an RGBA PNG", () => {
+    const png = createPng(2, 1, 6, [[255, 0, 0, 255, 0, 0, 255, 128]]);
+    const image = decodeImage(png);
+
+    expect(image.width).toBe(2);
+    expect(image.height).toBe(1);
+    expect(Array.from(image.pixels)).toEqual([255, 0, 0, 255, 0, 0, 255, 128]);
+  });
+
+  it("decodes a palette PNG with transparency", () => {
+    const chunk = (type: string, data: number[]) => {
+      const length = Buffer.alloc(4);
+      length.writeUInt32BE(data.length);
+      return Buffer.concat([length, Buffer.from(type, "ascii"), Buffer.from(data), Buffer.alloc(4)]);
+    };
+    const png = createPng(2, 1, 3, [[0, 1]], [chunk("PLTE", [1, 2, 3, 40, 50, 60]), chunk("tRNS", [0])]);
+    const image = decodeImage(png);
+
+    expect(Array.from(image.pixels)).toEqual([1, 2, 3, 0, 40, 50, 60, 255]);
+  });
+
+  it("decodes the first frame of a GIF", () => {
+    const image = decodeImage(TEST_GIF);
+
+    expect(image.width).toBe(3);
+    expect(image.height).toBe(2);
+    expect(Array.from(image.pixels.subarray(0, 12))).toEqual([255, 0, 0, 255, 255, 0, 0, 255, 0, 255, 0, 255]);
+    expect(image.pixels[15]).toBe(0); // transparent index
+  });
+
+  it("rejects other formats", () => {
+    expect(() => decodeImage(Buffer.from("BM not an image"))).toThrow(/Unsupported/);
+  });
+});
+
+describe("scaleImage", () => {
+  it("averages pixels with box scaling, ignoring transparent color", () => {
+    const image = {
+      width: 2,
+      height: 1,
+      pixels: new Uint8Array([200, 0, 0, 255, 0, 0, 0, 0]),
+    };
+    const scaled = scaleImage(image, 1, 1, "box");
+
+    expect(Array.from(scaled.pixels)).toEqual([200, 0, 0, 128]);
+  });
+
+  it("picks a single source pixel with nearest scaling", () => {
+    const image = { width: 2, height: 1, pixels: new Uint8Array([200, 0, 0, 255, 0, 0, 200, 255]) };
+    const scaled = scaleImage(image, 1, 1, "nearest");
+
+    expect(Array.from(scaled.pixels)).toEqual([0, 0, 200, 255]);
+  });
+});
+
+describe("fitImageSize", () => {
+  it("keeps the aspect ratio and never upscales", () => {
+    expect(fitImageSize(128, 64, 64, 64)).toEqual({ width: 64, height: 32 });
+    expect(fitImageSize(16, 16, 64, 64)).toEqual({ width: 16, height: 16 });
+  });
+});
+
+describe("drawImage", () => {
+  it("downscales large images to fit the frame", () => {
+    const frame = createSolidFrame(64, 64);
+    drawImage(frame, solidImage(256, 128, [0, 0, 255, 255]), 0, 0);
+
+    expect(getPixel(frame, 63, 31)).toEqual({ r: 0, g: 0, b: 255 });
+    expect(getPixel(frame, 0, 32)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+
+  it("skips transparent pixels and clips at the frame edge", () => {
+    const frame = createSolidFrame(4, 4, { r: 10, g: 10, b: 10 });
+    drawImage(frame, decodeImage(TEST_GIF), 2, 3);
+
+    expect(getPixel(frame, 2, 3)).toEqual({ r: 255, g: 0, b: 0 });
+    expect(getPixel(frame, 3, 3)).toEqual({ r: 255, g: 0, b: 0 });
+  });
+
+  it("respects a maximum size", () => {
+    const frame = createSolidFrame(64, 64);
+    drawImage(frame, solidImage(32, 32, [255, 255, 255, 255]), 0, 0, { maxWidth: 16, maxHeight: 16 });
+
+    expect(getPixel(frame, 15, 15)).toEqual({ r: 255, g: 255, b: 255 });
+    expect(getPixel(frame, 16, 16)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+});
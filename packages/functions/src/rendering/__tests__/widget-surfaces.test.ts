@@ -0,0 +1,94 @@
+/**
+ * Tests for per-widget surface compositing
+ */
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { getPixel, setPixel } from "@signage/core";
+
+const { mockRenderClock } = vi.hoisted(() => ({
+  mockRenderClock: vi.fn(),
+}));
+
+vi.mock("../clock-renderer.js", () => ({
+  renderClockRegion: mockRenderClock,
+}));
+
+import { generateCompositeFrame, clearSurfaceCache, WIDGET_REGIONS } from "../frame-composer.js";
+import { LAYOUTS } from "../layouts.js";
+
+describe("widget surfaces", () => {
+  beforeEach(() => {
+    vi.useFakeTimers();
+    vi.setSystemTime(new Date("2026-01-24T14:30:00"));
+    clearSurfaceCache();
+    mockRenderClock.mockReset();
+    // A misbehaving clock that paints the whole display
+    mockRenderClock.mockImplementation((frame) => {
+      for (let y = 0; y < frame.height; y++) {
+        for (let x = 0; x < frame.width; x++) {
+          setPixel(frame, x, y, { r: 255, g: 0, b: 0 });
+        }
+      }
+    });
+  });
+
+  afterEach(() => {
+    vi.useRealTimers();
+    vi.restoreAllMocks();
+  });
+
+  const clockOnly = { ...LAYOUTS.day, widgets: ["clock" as const] };
+
+  it("clips a widget to its region", () => {
+    const frame = generateCompositeFrame({ bloodSugar: null, layout: clockOnly });
+    const lastClockRow = WIDGET_REGIONS.clock.y + WIDGET_REGIONS.clock.height - 1;
+
+    expect(getPixel(frame, 10, lastClockRow)).toEqual({ r: 255, g: 0, b: 0 });
+    expect(getPixel(frame, 10, lastClockRow + 1)).toEqual({ r: 0, g: 0, b: 0 });
+    expect(getPixel(frame, 10, 63)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+
+  it("reuses an unchanged surface within the same minute", () => {
+    generateCompositeFrame({ bloodSugar: null, layout: clockOnly });
+    vi.setSystemTime(new Date("2026-01-24T14:30:30"));
+    const frame = generateCompositeFrame({ bloodSugar: null, layout: clockOnly });
+
+    expect(mockRenderClock).toHaveBeenCalledTimes(1);
+    expect(getPixel(frame, 0, 0)).toEqual({ r: 255, g: 0, b: 0 });
+  });
+
+  it("re-renders when the minute changes", () => {
+    generateCompositeFrame({ bloodSugar: null, layout: clockOnly });
+    vi.setSystemTime(new Date("2026-01-24T14:31:00"));
+    generateCompositeFrame({ bloodSugar: null, layout: clockOnly });
+
+    expect(mockRenderClock).toHaveBeenCalledTimes(2);
+  });
+
+  it("re-renders a seconds clock every step, at the step's time", () => {
+    generateCompositeFrame({ bloodSugar: null, layout: clockOnly, clockSeconds: 5 });
+    vi.setSystemTime(new Date("2026-01-24T14:30:04"));
+    generateCompositeFrame({ bloodSugar: null, layout: clockOnly, clockSeconds: 5 });
+    generateCompositeFrame({ bloodSugar: null, layout: clockOnly, clockSeconds: 5, now: Date.now() + 3000 });
+
+    expect(mockRenderClock).toHaveBeenCalledTimes(2);
+    expect(mockRenderClock.mock.calls[1][5]).toEqual({
+      now: new Date("2026-01-24T14:30:05").getTime(),
+      seconds: true,
+    });
+  });
+
+  it("keeps other widgets when one fails and does not cache the failure", () => {
+    mockRenderClock.mockImplementationOnce(() => {
+      throw new Error("boom");
+    });
+    vi.spyOn(console, "error").mockImplementation(() => {});
+    vi.spyOn(console, "warn").mockImplementation(() => {});
+
+    const failed = generateCompositeFrame({ bloodSugar: null, layout: clockOnly });
+    expect(getPixel(failed, 0, 0)).toEqual({ r: 0, g: 0, b: 0 });
+
+    const retried = generateCompositeFrame({ bloodSugar: null, layout: clockOnly });
+    expect(getPixel(retried, 0, 0)).toEqual({ r: 255, g: 0, b: 0 });
+  });
+});
@@ -0,0 +1,106 @@
+/**
+ * Tests for whole-frame output adjustments
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import {
+  applyBrightness,
+  applyChannelCap,
+  applyColorTemperature,
+  applyPowerLimit,
+  fullWhiteTotal,
+  limitPower,
+  colorTemperatureMultipliers,
+  NEUTRAL_COLOR_TEMPERATURE,
+} from "./adjustments.js";
+
+const WHITE = { r: 255, g: 255, b: 255 };
+
+describe("applyBrightness", () => {
+  it("scales every channel", () => {
+    const frame = createSolidFrame(2, 2, WHITE);
+    applyBrightness(frame, 0.5);
+    expect(getPixel(frame, 1, 1)).toEqual({ r: 128, g: 128, b: 128 });
+  });
+});
+
+describe("colorTemperatureMultipliers", () => {
+  it("is neutral at 6500K", () => {
+    const [r, g, b] = colorTemperatureMultipliers(NEUTRAL_COLOR_TEMPERATURE);
+    expect(r).toBeCloseTo(1);
+    expect(g).toBeCloseTo(1);
+    expect(b).toBeCloseTo(1);
+  });
+
+  it("cuts blue more than green when warm", () => {
+    const [r, g, b] = colorTemperatureMultipliers(2700);
+    expect(r).toBeCloseTo(1);
+    expect(g).toBeLessThan(1);
+    expect(b).toBeLessThan(g);
+  });
+
+  it("cuts red when cool", () => {
+    const [r, , b] = colorTemperatureMultipliers(9000);
+    expect(r).toBeLessThan(1);
+    expect(b).toBe(1);
+  });
+});
+
+describe("applyColorTemperature", () => {
+  it("leaves the frame alone at 6500K", () => {
+    const frame = createSolidFrame(2, 2, WHITE);
+    applyColorTemperature(frame, NEUTRAL_COLOR_TEMPERATURE);
+    expect(getPixel(frame, 0, 0)).toEqual(WHITE);
+  });
+
+  it("turns white warm at night temperatures", () => {
+    const frame = createSolidFrame(2, 2, WHITE);
+    applyColorTemperature(frame, 2700);
+
+    const pixel = getPixel(frame, 0, 0)!;
+    expect(pixel.r).toBe(255);
+    expect(pixel.g).toBeLessThan(255);
+    expect(pixel.b).toBeLessThan(pixel.g);
+  });
+
+  it("keeps black black", () => {
+    const frame = createSolidFrame(2, 2);
+    applyColorTemperature(frame, 2000);
+    expect(getPixel(frame, 1, 0)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+});
+
+describe("power limiting", () => {
+  it("caps each channel", () => {
+    const frame = createSolidFrame(2, 2, { r: 255, g: 100, b: 230 });
+    applyChannelCap(frame, 200);
+    expect(getPixel(frame, 0, 0)).toEqual({ r: 200, g: 100, b: 200 });
+  });
+
+  it("scales a full-white frame under the total", () => {
+    const frame = createSolidFrame(64, 64, WHITE);
+    const limit = Math.floor(fullWhiteTotal(frame) / 4);
+
+    const scale = applyPowerLimit(frame, limit);
+
+    expect(scale).toBeCloseTo(0.25);
+    expect(frame.pixels.reduce((sum, v) => sum + v, 0)).toBeLessThanOrEqual(limit);
+    // Still white, just dimmer
+    const pixel = getPixel(frame, 10, 10)!;
+    expect(pixel.r).toBe(pixel.g);
+    expect(pixel.g).toBe(pixel.b);
+  });
+
+  it("leaves frames within the total alone", () => {
+    const frame = createSolidFrame(64, 64, { r: 10, g: 10, b: 10 });
+    expect(applyPowerLimit(frame, fullWhiteTotal(frame) / 2)).toBe(1);
+    expect(getPixel(frame, 0, 0)).toEqual({ r: 10, g: 10, b: 10 });
+  });
+
+  it("applies the channel cap before the total", () => {
+    const frame = createSolidFrame(1, 1, WHITE);
+    expect(limitPower(frame, { maxChannel: 100, maxTotal: 300 })).toBe(1);
+    expect(getPixel(frame, 0, 0)).toEqual({ r: 100, g: 100, b: 100 });
+  });
+});
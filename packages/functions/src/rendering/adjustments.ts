@@ -0,0 +1,127 @@
+/**
+ * Whole-frame output adjustments
+ *
+ * Applied as the last step of composition, after every widget has drawn.
+ */
+
+import type { Frame } from "@signage/core";
+
+/**
+ * Scale every pixel by a brightness factor (0-1) in place
+ */
+export function applyBrightness(frame: Frame, brightness: number): void {
+  const factor = Math.max(0, Math.min(1, brightness));
+  if (factor === 1) return;
+
+  const { pixels } = frame;
+  for (let i = 0; i < pixels.length; i++) {
+    pixels[i] = Math.round(pixels[i] * factor);
+  }
+}
+
+/** Color temperature that leaves the frame unchanged (daylight white) */
+export const NEUTRAL_COLOR_TEMPERATURE = 6500;
+
+/**
+ * RGB of a black body at a color temperature, 0-255 per channel
+ * (Tanner Helland's fit, good from 1000K to 40000K)
+ */
+function kelvinToRgb(kelvin: number): [number, number, number] {
+  const t = kelvin / 100;
+  const clamp = (v: number) => Math.max(0, Math.min(255, v));
+
+  const r = t <= 66 ? 255 : 329.698727446 * Math.pow(t - 60, -0.1332047592);
+  const g = t <= 66 ? 99.4708025861 * Math.log(t) - 161.1195681661 : 288.1221695283 * Math.pow(t - 60, -0.0755148492);
+  const b = t >= 66 ? 255 : t <= 19 ? 0 : 138.5177312231 * Math.log(t - 10) - 305.0447927307;
+
+  return [clamp(r), clamp(g), clamp(b)];
+}
+
+/**
+ * Per-channel multipliers that shift white to a color temperature,
+ * relative to neutral (6500K gives [1, 1, 1])
+ */
+export function colorTemperatureMultipliers(kelvin: number): [number, number, number] {
+  const target = kelvinToRgb(Math.max(1000, Math.min(10000, kelvin)));
+  const neutral = kelvinToRgb(NEUTRAL_COLOR_TEMPERATURE);
+  return [
+    Math.min(1, target[0] / neutral[0]),
+    Math.min(1, target[1] / neutral[1]),
+    Math.min(1, target[2] / neutral[2]),
+  ];
+}
+
+/**
+ * Shift the white point of every pixel in place. Below 6500K is warmer
+ * (less blue), above is cooler. Channels are only ever scaled down, so
+ * this never brightens the frame.
+ */
+export function applyColorTemperature(frame: Frame, kelvin: number): void {
+  if (kelvin === NEUTRAL_COLOR_TEMPERATURE) return;
+
+  const multipliers = colorTemperatureMultipliers(kelvin);
+  const { pixels } = frame;
+  for (let i = 0; i < pixels.length; i++) {
+    pixels[i] = Math.round(pixels[i] * multipliers[i % 3]);
+  }
+}
+
+/**
+ * Output power limits. LED current scales with the sum of channel values,
+ * so capping that sum keeps full-white pages within a USB supply's budget.
+ */
+export interface PowerLimit {
+  /** Highest value any single channel may have, 1-255 */
+  maxChannel?: number;
+  /** Highest sum of every channel of every pixel */
+  maxTotal?: number;
+}
+
+/** Sum of every channel of a frame lit at full white */
+export function fullWhiteTotal(frame: Pick<Frame, "width" | "height">): number {
+  return frame.width * frame.height * 3 * 255;
+}
+
+/**
+ * Clamp every channel to at most `maxChannel` in place
+ */
+export function applyChannelCap(frame: Frame, maxChannel: number): void {
+  const cap = Math.max(0, Math.min(255, Math.round(maxChannel)));
+  if (cap === 255) return;
+
+  const { pixels } = frame;
+  for (let i = 0; i < pixels.length; i++) {
+    if (pixels[i] > cap) pixels[i] = cap;
+  }
+}
+
+/**
+ * Scale the whole frame down so the sum of its channels is at most
+ * `maxTotal`, keeping colors and relative brightness. Returns the scale
+ * applied (1 when the frame was already within the limit).
+ */
+export function applyPowerLimit(frame: Frame, maxTotal: number): number {
+  const { pixels } = frame;
+  let total = 0;
+  for (let i = 0; i < pixels.length; i++) {
+    total += pixels[i];
+  }
+  if (total <= maxTotal) return 1;
+
+  const scale = Math.max(0, maxTotal) / total;
+  for (let i = 0; i < pixels.length; i++) {
+    // Round down so the result stays under the limit
+    pixels[i] = Math.floor(pixels[i] * scale);
+  }
+  return scale;
+}
+
+/**
+ * Apply a power limit: the per-channel cap first, then the total
+ */
+export function limitPower(frame: Frame, limit: PowerLimit): number {
+  if (limit.maxChannel !== undefined) {
+    applyChannelCap(frame, limit.maxChannel);
+  }
+  return limit.maxTotal !== undefined ? applyPowerLimit(frame, limit.maxTotal) : 1;
+}
@@ -0,0 +1,44 @@
+/**
+ * Tests for alert banner renderer
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { renderAlertBanner, ALERT_BANNER_HEIGHT } from "./alert-renderer.js";
+import { COLORS } from "./colors.js";
+import type { GlucoseAlert } from "../alerts/types.js";
+
+describe("renderAlertBanner", () => {
+  const alert: GlucoseAlert = {
+    type: "urgentLowSoon",
+    severity: "urgent",
+    title: "LOW SOON",
+    detail: "BELOW 55 IN 12M",
+    raisedAt: 0,
+  };
+
+  it("fills the banner rows and nothing else", () => {
+    const frame = createSolidFrame(64, 64);
+    renderAlertBanner(frame, alert, 7);
+
+    expect(getPixel(frame, 0, 7)).toEqual(COLORS.alertAccent);
+    expect(getPixel(frame, 63, 7 + ALERT_BANNER_HEIGHT - 1)).toEqual(COLORS.alertAccent);
+    expect(getPixel(frame, 2, 7 + ALERT_BANNER_HEIGHT - 1)).toEqual(COLORS.alertBackdrop);
+    expect(getPixel(frame, 2, 6)).toEqual({ r: 0, g: 0, b: 0 });
+    expect(getPixel(frame, 2, 7 + ALERT_BANNER_HEIGHT)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+
+  it("draws the title in the accent color", () => {
+    const frame = createSolidFrame(64, 64);
+    renderAlertBanner(frame, alert, 7);
+
+    let accentPixels = 0;
+    for (let x = 2; x < 62; x++) {
+      for (let y = 7; y < 12; y++) {
+        const p = getPixel(frame, x, y);
+        if (p && p.r === COLORS.alertAccent.r && p.b === COLORS.alertAccent.b) accentPixels++;
+      }
+    }
+    expect(accentPixels).toBeGreaterThan(0);
+  });
+});
@@ -0,0 +1,37 @@
+/**
+ * Alert banner renderer
+ *
+ * Draws the most important active alert as a two-line banner over the
+ * insight region:
+ * ┃ LOW SOON                             ┃  line 1 (accent color)
+ * ┃ BELOW 55 IN 12M                      ┃  line 2 (white)
+ */
+
+import type { Frame } from "@signage/core";
+import { fillRect } from "@signage/core";
+import { drawTinyText, measureTinyText, DISPLAY_WIDTH } from "./text.js";
+import { COLORS } from "./colors.js";
+import type { GlucoseAlert } from "../alerts/types.js";
+
+/** Banner height: two 5px lines with a 1px gap */
+export const ALERT_BANNER_HEIGHT = 11;
+
+/** Second line offset from the banner top */
+const LINE2_OFFSET = 6;
+
+function centeredX(text: string): number {
+  return Math.floor((DISPLAY_WIDTH - measureTinyText(text)) / 2);
+}
+
+/**
+ * Render an alert banner with its top edge at y
+ */
+export function renderAlertBanner(frame: Frame, alert: GlucoseAlert, y: number): void {
+  // Backdrop and side accent bars set the banner apart from regular text
+  fillRect(frame, 0, y, DISPLAY_WIDTH, ALERT_BANNER_HEIGHT, COLORS.alertBackdrop);
+  fillRect(frame, 0, y, 1, ALERT_BANNER_HEIGHT, COLORS.alertAccent);
+  fillRect(frame, DISPLAY_WIDTH - 1, y, 1, ALERT_BANNER_HEIGHT, COLORS.alertAccent);
+
+  drawTinyText(frame, alert.title, centeredX(alert.title), y, COLORS.alertAccent);
+  drawTinyText(frame, alert.detail, centeredX(alert.detail), y + LINE2_OFFSET, COLORS.alertText);
+}
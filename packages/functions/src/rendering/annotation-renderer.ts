@@ -0,0 +1,36 @@
+/**
+ * Annotation marker rendering
+ * Dotted vertical lines on the glucose chart at each annotation's time
+ * (sensor change, site change, ...).
+ */
+
+import type { Frame } from "@signage/core";
+import { setPixel } from "@signage/core";
+import { COLORS } from "./colors.js";
+import type { ChartBounds } from "./treatment-renderer.js";
+import type { Annotation } from "../annotations/types.js";
+
+/**
+ * Render annotation markers within chart bounds.
+ * Draw before the glucose line so the line stays on top.
+ */
+export function renderAnnotationMarkers(
+  frame: Frame,
+  annotations: Pick<Annotation, "timestamp">[],
+  bounds: ChartBounds
+): void {
+  const { x, y, width, height, hours, offsetHours = 0 } = bounds;
+
+  const timeRange = hours * 60 * 60 * 1000;
+  const endTime = Date.now() - offsetHours * 60 * 60 * 1000;
+  const startTime = endTime - timeRange;
+
+  for (const annotation of annotations) {
+    if (annotation.timestamp < startTime || annotation.timestamp > endTime) continue;
+
+    const markerX = x + Math.round(((annotation.timestamp - startTime) / timeRange) * (width - 1));
+    for (let row = y; row < y + height; row += 2) {
+      setPixel(frame, markerX, row, COLORS.annotation);
+    }
+  }
+}
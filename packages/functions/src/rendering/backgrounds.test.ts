@@ -0,0 +1,85 @@
+/**
+ * Tests for dithered background fills
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { renderBackground, ditherChannel, DITHER_STEP } from "./backgrounds.js";
+
+function rowAverage(frame: ReturnType<typeof createSolidFrame>, y: number, channel: 0 | 1 | 2): number {
+  let sum = 0;
+  for (let x = 0; x < frame.width; x++) {
+    sum += frame.pixels[(y * frame.width + x) * 3 + channel];
+  }
+  return sum / frame.width;
+}
+
+describe("ditherChannel", () => {
+  it("only produces dither levels", () => {
+    for (let x = 0; x < 4; x++) {
+      for (let y = 0; y < 4; y++) {
+        expect(ditherChannel(9.3, x, y) % DITHER_STEP).toBe(0);
+      }
+    }
+  });
+
+  it("averages to the requested value over a 4x4 tile", () => {
+    let sum = 0;
+    for (let x = 0; x < 4; x++) {
+      for (let y = 0; y < 4; y++) {
+        sum += ditherChannel(10, x, y);
+      }
+    }
+    expect(sum / 16).toBeCloseTo(10, 0);
+  });
+
+  it("keeps exact levels and clamps", () => {
+    expect(ditherChannel(0, 1, 2)).toBe(0);
+    expect(ditherChannel(8, 3, 3)).toBe(8);
+    expect(ditherChannel(300, 0, 0)).toBe(255);
+  });
+});
+
+describe("renderBackground", () => {
+  it("draws a vertical gradient whose rows track the ideal ramp", () => {
+    const frame = createSolidFrame(64, 64);
+    renderBackground(frame, { type: "gradient", top: { r: 0, g: 0, b: 0 }, bottom: { r: 0, g: 0, b: 40 } });
+
+    for (const y of [0, 16, 32, 48, 63]) {
+      const ideal = (40 * y) / 63;
+      expect(Math.abs(rowAverage(frame, y, 2) - ideal)).toBeLessThan(DITHER_STEP / 2);
+    }
+    expect(rowAverage(frame, 0, 0)).toBe(0);
+  });
+
+  it("draws the same grain every time", () => {
+    const a = createSolidFrame(64, 64);
+    const b = createSolidFrame(64, 64);
+    const style = { type: "noise" as const, color: { r: 12, g: 12, b: 20 } };
+    renderBackground(a, style);
+    renderBackground(b, style);
+
+    expect(a.pixels).toEqual(b.pixels);
+    expect(new Set(a.pixels).size).toBeGreaterThan(2);
+  });
+
+  it("draws a glow that fades from its center", () => {
+    const frame = createSolidFrame(64, 64);
+    renderBackground(frame, { type: "glow", color: { r: 0, g: 80, b: 0 }, x: 32, y: 30, radius: 12 });
+
+    expect(getPixel(frame, 32, 30)!.g).toBeGreaterThanOrEqual(76);
+    expect(getPixel(frame, 32, 30)!.g).toBeGreaterThan(getPixel(frame, 32, 36)!.g);
+    expect(getPixel(frame, 32, 45)).toEqual({ r: 0, g: 0, b: 0 });
+    expect(getPixel(frame, 0, 0)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+
+  it("tints a glow without a color with the accent, or skips it", () => {
+    const tinted = createSolidFrame(64, 64);
+    renderBackground(tinted, { type: "glow" }, { accent: { r: 255, g: 0, b: 0 } });
+    expect(getPixel(tinted, 32, 30)!.r).toBeGreaterThan(0);
+
+    const plain = createSolidFrame(64, 64);
+    renderBackground(plain, { type: "glow" });
+    expect(plain.pixels.every((v) => v === 0)).toBe(true);
+  });
+});
@@ -0,0 +1,132 @@
+/**
+ * Background fills drawn behind every widget
+ *
+ * Smooth fills at the low intensities a background needs band visibly on an
+ * LED panel, where neighbouring levels near black are far apart. Every
+ * style is ordered-dithered (4x4 Bayer) so fractional levels become a fine,
+ * stable pattern instead of steps. Nothing is random from frame to frame,
+ * so an unchanged background never makes the frame "change".
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { setPixel } from "@signage/core";
+
+/**
+ * A background fill style
+ */
+export type BackgroundStyle =
+  /** Vertical gradient from `top` to `bottom` */
+  | { type: "gradient"; top: RGB; bottom: RGB }
+  /** Flat color with a fixed grain, `amount` 0-1 (default: 0.5) */
+  | { type: "noise"; color: RGB; amount?: number }
+  /**
+   * Radial glow, brightest at the center (default: behind the glucose
+   * reading, tinted with the accent color)
+   */
+  | { type: "glow"; color?: RGB; x?: number; y?: number; radius?: number };
+
+/**
+ * Values from the frame that styles can follow
+ */
+export interface BackgroundContext {
+  /** Color of the current glucose range, used by glow without a color */
+  accent?: RGB;
+}
+
+/** Level spacing dithered across (visible steps on the panel near black) */
+export const DITHER_STEP = 4;
+
+/** Glow center: middle of the glucose reading row */
+const GLOW_X = 32;
+const GLOW_Y = 30;
+const GLOW_RADIUS = 12;
+
+/** Accent-tinted glow intensity at its center */
+const GLOW_ACCENT_STRENGTH = 0.25;
+
+const BAYER_4X4 = [
+  [0, 8, 2, 10],
+  [12, 4, 14, 6],
+  [3, 11, 1, 9],
+  [15, 7, 13, 5],
+];
+
+/**
+ * Quantize a channel value (0-255, fractional) to the dither step, choosing
+ * the level above or below by the pixel's Bayer threshold
+ */
+export function ditherChannel(value: number, x: number, y: number): number {
+  const threshold = (BAYER_4X4[y & 3][x & 3] + 0.5) / 16;
+  const level = Math.floor(value / DITHER_STEP + threshold) * DITHER_STEP;
+  return Math.max(0, Math.min(255, level));
+}
+
+function setDithered(frame: Frame, x: number, y: number, r: number, g: number, b: number): void {
+  setPixel(frame, x, y, {
+    r: ditherChannel(r, x, y),
+    g: ditherChannel(g, x, y),
+    b: ditherChannel(b, x, y),
+  });
+}
+
+/**
+ * Fixed per-pixel grain in [0, 1)
+ */
+function grain(x: number, y: number): number {
+  let h = (x * 374761393 + y * 668265263) | 0;
+  h = Math.imul(h ^ (h >>> 13), 1274126177);
+  return ((h ^ (h >>> 16)) >>> 0) / 4294967296;
+}
+
+/**
+ * Fill a frame with a background style
+ */
+export function renderBackground(frame: Frame, style: BackgroundStyle, context: BackgroundContext = {}): void {
+  const { width, height } = frame;
+
+  if (style.type === "gradient") {
+    const { top, bottom } = style;
+    for (let y = 0; y < height; y++) {
+      const t = height > 1 ? y / (height - 1) : 0;
+      const r = top.r + (bottom.r - top.r) * t;
+      const g = top.g + (bottom.g - top.g) * t;
+      const b = top.b + (bottom.b - top.b) * t;
+      for (let x = 0; x < width; x++) {
+        setDithered(frame, x, y, r, g, b);
+      }
+    }
+    return;
+  }
+
+  if (style.type === "noise") {
+    const { color } = style;
+    const amount = Math.max(0, Math.min(1, style.amount ?? 0.5));
+    for (let y = 0; y < height; y++) {
+      for (let x = 0; x < width; x++) {
+        const factor = 1 + amount * (grain(x, y) * 2 - 1);
+        setDithered(frame, x, y, color.r * factor, color.g * factor, color.b * factor);
+      }
+    }
+    return;
+  }
+
+  const color =
+    style.color ??
+    (context.accent && {
+      r: context.accent.r * GLOW_ACCENT_STRENGTH,
+      g: context.accent.g * GLOW_ACCENT_STRENGTH,
+      b: context.accent.b * GLOW_ACCENT_STRENGTH,
+    });
+  if (!color) return;
+
+  const { x: cx = GLOW_X, y: cy = GLOW_Y, radius = GLOW_RADIUS } = style;
+  for (let y = Math.max(0, Math.floor(cy - radius)); y <= Math.min(height - 1, Math.ceil(cy + radius)); y++) {
+    for (let x = Math.max(0, Math.floor(cx - radius)); x <= Math.min(width - 1, Math.ceil(cx + radius)); x++) {
+      const distance = Math.hypot(x - cx, y - cy);
+      if (distance >= radius) continue;
+      // Quadratic falloff reads as a soft glow rather than a disc
+      const intensity = (1 - distance / radius) ** 2;
+      setDithered(frame, x, y, color.r * intensity, color.g * intensity, color.b * intensity);
+    }
+  }
+}
@@ -0,0 +1,99 @@
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel, getPixelValue } from "@signage/core";
+import { histogram, renderBarChart } from "./bar-chart-renderer";
+import { COLORS } from "./colors";
+
+const RED = { r: 255, g: 0, b: 0 };
+
+/** Rows lit in a column of the chart */
+function litRows(frame: ReturnType<typeof createSolidFrame>, px: number, fromY: number, toY: number): number {
+  let lit = 0;
+  for (let py = fromY; py <= toY; py++) {
+    if (getPixelValue(frame, px, py) > 0) lit++;
+  }
+  return lit;
+}
+
+describe("renderBarChart", () => {
+  it("scales bars to the largest value, growing up from the bottom", () => {
+    const frame = createSolidFrame(64, 64);
+    // 4 bars of 4px with 1px gaps: 19px, centered in 20
+    renderBarChart(frame, [{ value: 10 }, { value: 5 }, { value: 0 }, { value: 1 }], {
+      x: 0,
+      y: 0,
+      width: 20,
+      height: 10,
+    });
+
+    expect(litRows(frame, 0, 0, 9)).toBe(10);
+    expect(litRows(frame, 5, 0, 9)).toBe(5);
+    expect(getPixel(frame, 5, 9)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 5, 4)).toEqual({ r: 0, g: 0, b: 0 });
+    expect(litRows(frame, 10, 0, 9)).toBe(0);
+    // Small but positive still shows
+    expect(litRows(frame, 15, 0, 9)).toBe(1);
+    // Gaps between bars
+    expect(litRows(frame, 4, 0, 9)).toBe(0);
+  });
+
+  it("uses each bar's own color and a fixed max", () => {
+    const frame = createSolidFrame(64, 64);
+    renderBarChart(frame, [{ value: 50, color: RED }, { value: 200 }], { x: 0, y: 0, width: 3, height: 10, max: 100 });
+
+    expect(getPixel(frame, 0, 9)).toEqual(RED);
+    expect(litRows(frame, 0, 0, 9)).toBe(5);
+    // Clamped at the max
+    expect(litRows(frame, 2, 0, 9)).toBe(10);
+  });
+
+  it("drops the gaps, then the oldest bars, when they don't fit", () => {
+    const frame = createSolidFrame(64, 64);
+    const bars = Array.from({ length: 12 }, (_, i) => ({ value: i + 1 }));
+    renderBarChart(frame, bars, { x: 0, y: 0, width: 10, height: 12 });
+
+    // The last 10 bars (3-12), 1px each with no gaps
+    for (let px = 0; px < 10; px++) {
+      expect(litRows(frame, px, 0, 11)).toBe(Math.round(((px + 3) / 12) * 12));
+    }
+  });
+
+  it("draws labels under the bars, skipping ones that would overlap", () => {
+    const frame = createSolidFrame(64, 64);
+    renderBarChart(
+      frame,
+      [
+        { value: 1, label: "6A" },
+        { value: 1, label: "7A" },
+        { value: 1, label: "8A" },
+      ],
+      { x: 0, y: 0, width: 15, height: 12 }
+    );
+
+    // Bars fill rows 0-5, labels rows 7-11
+    expect(litRows(frame, 0, 0, 5)).toBe(6);
+    expect(litRows(frame, 0, 6, 6)).toBe(0);
+    let labelPixels = 0;
+    for (let px = 0; px < 15; px++) labelPixels += litRows(frame, px, 7, 11);
+    expect(labelPixels).toBeGreaterThan(0);
+    // The middle label would run into the first, so only two are drawn
+    expect(litRows(frame, 7, 7, 11)).toBe(0);
+  });
+
+  it("draws nothing without bars", () => {
+    const frame = createSolidFrame(64, 64);
+    renderBarChart(frame, [], { x: 0, y: 0, width: 10, height: 10 });
+
+    expect(frame.pixels.every((v) => v === 0)).toBe(true);
+  });
+});
+
+describe("histogram", () => {
+  it("counts values into bins, the last one closed", () => {
+    expect(histogram([55, 70, 100, 180, 181, 250, 400, NaN], [40, 70, 180, 400])).toEqual([1, 2, 4]);
+  });
+
+  it("leaves out values outside the edges", () => {
+    expect(histogram([10, 500], [40, 70, 180])).toEqual([0, 0]);
+    expect(histogram([100], [40])).toEqual([]);
+  });
+});
@@ -0,0 +1,120 @@
+/**
+ * Bar chart renderer for discrete series
+ *
+ * One bar per value, scaled from zero to the largest, with optional tiny
+ * labels underneath:
+ * ┌───────────────────────┐
+ * │       ▮               │
+ * │    ▮  ▮     ▮         │  hourly rainfall, daily time in range, ...
+ * │ ▮  ▮  ▮  ▮  ▮  ▮      │
+ * │ 6A    12P   6P        │  labels, skipped where they'd overlap
+ * └───────────────────────┘
+ * The peer of renderChart for data that comes in buckets rather than as a
+ * continuous line.
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { fillRect } from "@signage/core";
+import { COLORS } from "./colors.js";
+import { drawTinyText, measureTinyText } from "./text.js";
+
+/**
+ * One bar
+ */
+export interface Bar {
+  value: number;
+  /** Bar color (default: the chart's color) */
+  color?: RGB;
+  /** Tiny label centered under the bar (default: none) */
+  label?: string;
+}
+
+/**
+ * Bar chart configuration
+ */
+export interface BarChartConfig {
+  /** Chart X position */
+  x: number;
+  /** Chart Y position (top) */
+  y: number;
+  /** Chart width in pixels */
+  width: number;
+  /** Chart height in pixels, labels included */
+  height: number;
+  /** Color of bars without their own (default: COLORS.normal) */
+  color?: RGB;
+  /** Pixels between bars; dropped when the bars wouldn't fit (default: 1) */
+  gap?: number;
+  /** Value a full-height bar stands for (default: the largest value) */
+  max?: number;
+  /** Color of the labels (default: COLORS.chartGridLabel) */
+  labelColor?: RGB;
+}
+
+/** Rows the labels take under the bars: the tiny font and a gap above it */
+export const BAR_LABEL_HEIGHT = 6;
+
+/**
+ * Render a bar chart. Bars grow up from the bottom, scaled so the largest
+ * (or `max`) fills the height; any positive value shows at least one row.
+ * Bars share the width evenly and are centered; when there are more bars
+ * than columns, the last ones are shown.
+ */
+export function renderBarChart(frame: Frame, bars: Bar[], config: BarChartConfig): void {
+  const { x, y, width, height, color = COLORS.normal, labelColor = COLORS.chartGridLabel } = config;
+  if (bars.length === 0 || width <= 0 || height <= 0) return;
+
+  const shown = bars.slice(-width);
+  const preferredGap = Math.max(0, config.gap ?? 1);
+  const gap = shown.length + preferredGap * (shown.length - 1) <= width ? preferredGap : 0;
+  const barWidth = Math.floor((width - gap * (shown.length - 1)) / shown.length);
+  const used = barWidth * shown.length + gap * (shown.length - 1);
+  const startX = x + Math.floor((width - used) / 2);
+
+  // Labels only when there's room for bars above them
+  const hasLabels = shown.some((bar) => bar.label) && height > BAR_LABEL_HEIGHT;
+  const barsHeight = hasLabels ? height - BAR_LABEL_HEIGHT : height;
+  const bottom = y + barsHeight;
+
+  const finite = shown.map((bar) => (Number.isFinite(bar.value) ? bar.value : 0));
+  const max = config.max ?? Math.max(...finite);
+
+  let lastLabelRight = -Infinity;
+  shown.forEach((bar, i) => {
+    const barX = startX + i * (barWidth + gap);
+    const value = Math.min(finite[i], max);
+    if (value > 0 && max > 0) {
+      const barHeight = Math.max(1, Math.round((value / max) * barsHeight));
+      fillRect(frame, barX, bottom - barHeight, barWidth, barHeight, bar.color ?? color);
+    }
+
+    if (!hasLabels || !bar.label) return;
+    const labelWidth = measureTinyText(bar.label);
+    const labelX = Math.max(x, Math.min(x + width - labelWidth, barX + Math.floor((barWidth - labelWidth) / 2)));
+    // Skip labels that would touch the previous one
+    if (labelX <= lastLabelRight || labelWidth > width) return;
+    drawTinyText(frame, bar.label, labelX, bottom + 1, labelColor);
+    lastLabelRight = labelX + labelWidth;
+  });
+}
+
+/**
+ * Count values into histogram bins. `edges` are ascending bin boundaries;
+ * bin i holds values from edges[i] up to (not including) edges[i + 1], and
+ * the last bin includes its upper edge. Values outside the edges are left
+ * out. Returns one count per bin.
+ */
+export function histogram(values: number[], edges: number[]): number[] {
+  const counts = new Array<number>(Math.max(0, edges.length - 1)).fill(0);
+  for (const value of values) {
+    if (!Number.isFinite(value)) continue;
+    for (let i = 0; i < counts.length; i++) {
+      const last = i === counts.length - 1;
+      if (value >= edges[i] && (value < edges[i + 1] || (last && value === edges[i + 1]))) {
+        counts[i]++;
+        break;
+      }
+    }
+  }
+  return counts;
+}
@@ -2,8 +2,18 @@
  * Tests for blood sugar renderer
  */
 
-import { describe, it, expect } from "vitest";
-import { calculateTIR, classifyRange, calculateInsulinTotal } from "./blood-sugar-renderer.js";
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { createSolidFrame, type Frame } from "@signage/core";
+import {
+  calculateTIR,
+  classifyRange,
+  calculateInsulinTotal,
+  calculateTimeMarkers,
+  renderBloodSugarRegion,
+  type BloodSugarDisplayData,
+} from "./blood-sugar-renderer.js";
+import { COLORS } from "./colors.js";
+import type { GlucoseSourceStatus } from "./glucose-state.js";
 
 describe("calculateTIR", () => {
   const now = Date.now();
@@ -157,3 +167,206 @@ describe("calculateInsulinTotal", () => {
     expect(calculateInsulinTotal(treatments, now - 24 * HOUR, now - 3 * HOUR)).toBe(0);
   });
 });
+
+describe("calculateTimeMarkers", () => {
+  const tz = "America/Los_Angeles";
+  const at = (iso: string) => new Date(iso).getTime();
+
+  it("returns the four 6-hour marks of an ordinary day in time order", () => {
+    const markers = calculateTimeMarkers(tz, at("2026-06-10T09:15:00-07:00"));
+    expect(markers).toEqual([
+      at("2026-06-09T12:00:00-07:00"),
+      at("2026-06-09T18:00:00-07:00"),
+      at("2026-06-10T00:00:00-07:00"),
+      at("2026-06-10T06:00:00-07:00"),
+    ]);
+  });
+
+  it("keeps markers on the local hour across spring forward", () => {
+    // The 23-hour day means yesterday's noon is still within the last 24h
+    const markers = calculateTimeMarkers(tz, at("2026-03-08T12:30:00-07:00"));
+    expect(markers).toEqual([
+      at("2026-03-07T12:00:00-08:00"),
+      at("2026-03-07T18:00:00-08:00"),
+      at("2026-03-08T00:00:00-08:00"),
+      at("2026-03-08T06:00:00-07:00"),
+      at("2026-03-08T12:00:00-07:00"),
+    ]);
+  });
+
+  it("keeps markers on the local hour across fall back", () => {
+    const markers = calculateTimeMarkers(tz, at("2026-11-01T12:30:00-08:00"));
+    expect(markers).toEqual([
+      at("2026-10-31T18:00:00-07:00"),
+      at("2026-11-01T00:00:00-07:00"),
+      at("2026-11-01T06:00:00-08:00"),
+      at("2026-11-01T12:00:00-08:00"),
+    ]);
+  });
+});
+
+describe("renderBloodSugarRegion with non-computable trends", () => {
+  const base: BloodSugarDisplayData = {
+    glucose: 120,
+    trend: "NotComputable",
+    delta: 5,
+    timestamp: Date.now(),
+    rangeStatus: "normal",
+    isStale: false,
+  };
+
+  function render(data: BloodSugarDisplayData) {
+    const frame = createSolidFrame(64, 64, { r: 0, g: 0, b: 0 });
+    renderBloodSugarRegion(frame, data);
+    return frame;
+  }
+
+  it("does not draw the delta", () => {
+    const withDelta = render(base);
+    const otherDelta = render({ ...base, delta: -40 });
+    expect(withDelta.pixels).toEqual(otherDelta.pixels);
+  });
+
+  it("draws a distinct glyph for each trend", () => {
+    const notComputable = render(base);
+    const outOfRange = render({ ...base, trend: "RateOutOfRange" });
+    expect(notComputable.pixels).not.toEqual(outOfRange.pixels);
+  });
+});
+
+describe("renderBloodSugarRegion when rate limited", () => {
+  const data: BloodSugarDisplayData = {
+    glucose: 120,
+    trend: "Flat",
+    delta: 2,
+    timestamp: Date.now() - 3 * 60 * 1000,
+    rangeStatus: "normal",
+    isStale: false,
+  };
+
+  function colorsDrawn(input: BloodSugarDisplayData): Set<string> {
+    const frame = createSolidFrame(64, 64, { r: 0, g: 0, b: 0 });
+    renderBloodSugarRegion(frame, input);
+    const colors = new Set<string>();
+    for (let i = 0; i < frame.pixels.length; i += 3) {
+      colors.add(`${frame.pixels[i]},${frame.pixels[i + 1]},${frame.pixels[i + 2]}`);
+    }
+    return colors;
+  }
+
+  it("draws the reading's age in its own color", () => {
+    const { r, g, b } = COLORS.rateLimited;
+    expect(colorsDrawn(data).has(`${r},${g},${b}`)).toBe(false);
+    expect(colorsDrawn({ ...data, rateLimited: true }).has(`${r},${g},${b}`)).toBe(true);
+  });
+});
+
+describe("renderBloodSugarRegion without a live reading", () => {
+  const now = Date.UTC(2026, 5, 1, 12, 0, 0);
+  const history = {
+    points: Array.from({ length: 12 }, (_, i) => ({ timestamp: now - i * 5 * 60 * 1000, glucose: 100 + i * 5 })),
+  };
+
+  afterEach(() => {
+    vi.useRealTimers();
+  });
+
+  function render(
+    data: BloodSugarDisplayData | null,
+    points?: typeof history,
+    status: GlucoseSourceStatus = {}
+  ): Frame {
+    const frame = createSolidFrame(64, 64, { r: 0, g: 0, b: 0 });
+    renderBloodSugarRegion(frame, data, points, undefined, undefined, undefined, {}, status);
+    return frame;
+  }
+
+  function colorsInRows(frame: Frame, fromY: number, toY: number): Set<string> {
+    const colors = new Set<string>();
+    for (let i = fromY * 64 * 3; i < (toY + 1) * 64 * 3; i += 3) {
+      colors.add(`${frame.pixels[i]},${frame.pixels[i + 1]},${frame.pixels[i + 2]}`);
+    }
+    return colors;
+  }
+
+  const has = (colors: Set<string>, { r, g, b }: { r: number; g: number; b: number }) => colors.has(`${r},${g},${b}`);
+
+  it("tells no data from an unreachable Dexcom", () => {
+    vi.useFakeTimers({ now });
+
+    const noData = colorsInRows(render(null), 28, 32);
+    const unreachable = colorsInRows(render(null, undefined, { dexcomUnreachable: true }), 28, 32);
+
+    expect(has(noData, COLORS.stale)).toBe(true);
+    expect(has(unreachable, COLORS.unreachable)).toBe(true);
+    expect(has(noData, COLORS.urgentLow)).toBe(false);
+  });
+
+  it("grays the chart unless the reading is live", () => {
+    vi.useFakeTimers({ now });
+    const reading: BloodSugarDisplayData = {
+      glucose: 100,
+      trend: "Flat",
+      delta: 0,
+      timestamp: now,
+      rangeStatus: "normal",
+      isStale: false,
+    };
+    const isGray = (color: string) => new Set(color.split(",")).size === 1;
+
+    const live = colorsInRows(render(reading, history), 34, 63);
+    const stale = colorsInRows(render({ ...reading, isStale: true }, history), 34, 63);
+
+    expect([...live].every(isGray)).toBe(false);
+    expect([...stale].every(isGray)).toBe(true);
+  });
+
+  it("blinks a stale reading's age", () => {
+    const stale: BloodSugarDisplayData = {
+      glucose: 100,
+      trend: "Flat",
+      delta: 0,
+      timestamp: now - 25 * 60 * 1000,
+      rangeStatus: "normal",
+      isStale: true,
+    };
+
+    vi.useFakeTimers({ now });
+    const on = render(stale);
+    vi.setSystemTime(now + 1000);
+    const off = render(stale);
+
+    expect(has(colorsInRows(on, 28, 32), COLORS.updateTime)).toBe(true);
+    expect(has(colorsInRows(off, 28, 32), COLORS.updateTime)).toBe(false);
+  });
+
+  it("shows how long readings have been missing", () => {
+    const gapped: BloodSugarDisplayData = {
+      glucose: 100,
+      trend: "Flat",
+      delta: 0,
+      timestamp: now - 42 * 60 * 1000,
+      rangeStatus: "normal",
+      isStale: true,
+    };
+
+    vi.useFakeTimers({ now });
+    const frame = render(gapped, history);
+
+    expect(has(colorsInRows(frame, 28, 32), COLORS.noSignal)).toBe(true);
+    expect(has(colorsInRows(frame, 28, 32), COLORS.stale)).toBe(false);
+    expect([...colorsInRows(frame, 34, 63)].every((color) => new Set(color.split(",")).size === 1)).toBe(true);
+  });
+
+  it("blinks the reconnecting message", () => {
+    const status = { deviceReconnecting: true };
+
+    vi.useFakeTimers({ now });
+    const on = colorsInRows(render(null, undefined, status), 28, 32);
+    vi.setSystemTime(now + 1000);
+    const off = colorsInRows(render(null, undefined, status), 28, 32);
+
+    expect(has(on, COLORS.reconnecting)).toBe(true);
+    expect(has(off, COLORS.reconnecting)).toBe(false);
+  });
+});
@@ -6,7 +6,13 @@ import type { Frame, RGB } from "@signage/core";
 import { setPixel } from "@signage/core";
 import { drawText, drawTinyText, measureText, measureTinyText, DISPLAY_WIDTH } from "./text.js";
 import { COLORS, type RangeStatus, getTrendTintedColor } from "./colors.js";
-import { renderChart, type ChartPoint } from "./chart-renderer.js";
+import { renderChart, type ChartConfig, type ChartPoint } from "./chart-renderer.js";
+import { renderTreatmentMarkers } from "./treatment-renderer.js";
+import { renderAnnotationMarkers } from "./annotation-renderer.js";
+import { DEFAULT_TIMEZONE, wallTime, zonedTimestamp } from "./zoned-time.js";
+import { formatGap, isBlinkOn, resolveGlucoseState, type GlucoseRenderState, type GlucoseSourceStatus } from "./glucose-state.js";
+import { isTrendComputable } from "../dexcom/client.js";
+import type { Annotation } from "../annotations/types.js";
 import type { TreatmentDisplayData } from "../glooko/types.js";
 
 // Blood sugar region boundaries (chart at bottom layout)
@@ -54,6 +60,8 @@ export interface BloodSugarDisplayData {
   timestamp: number;
   rangeStatus: RangeStatus;
   isStale: boolean;
+  /** Cached because the Dexcom request budget is spent (not because Dexcom failed) */
+  rateLimited?: boolean;
 }
 
 /**
@@ -129,21 +137,40 @@ const TREND_ARROWS: Record<string, number[]> = {
     0b01010,
     0b00100,
   ],
+  // Broken line - sensor can't compute a direction
+  notcomputable: [
+    0b00000,
+    0b00000,
+    0b11011,
+    0b00000,
+    0b00000,
+  ],
+  // ⇅ Up and down - changing faster than the sensor can report
+  rateoutofrange: [
+    0b00100,
+    0b01110,
+    0b00000,
+    0b01110,
+    0b00100,
+  ],
 };
 
 const ARROW_WIDTH = 5;
 const ARROW_HEIGHT = 5;
 
 /**
- * Draw a trend arrow at specified position
+ * Draw a trend arrow at specified position, clipped to rows minY-maxY
+ * (default: the blood sugar region)
  * Returns the width consumed (for text positioning)
  */
-function drawTrendArrow(
+export function drawTrendArrow(
   frame: Frame,
   trend: string,
   x: number,
   y: number,
-  color: RGB
+  color: RGB,
+  minY: number = BG_REGION_START,
+  maxY: number = BG_REGION_END
 ): number {
   const bitmap = TREND_ARROWS[trend.toLowerCase()];
   if (!bitmap) {
@@ -157,7 +184,7 @@ function drawTrendArrow(
       if (bit) {
         const px = x + col;
         const py = y + row;
-        if (px >= 0 && px < DISPLAY_WIDTH && py >= BG_REGION_START && py <= BG_REGION_END) {
+        if (px >= 0 && px < frame.width && py >= minY && py <= maxY) {
           setPixel(frame, px, py, color);
         }
       }
@@ -205,46 +232,34 @@ export function calculateTIR(points: ChartPoint[]): number | null {
 }
 
 /**
- * Calculate time markers for midnight, 6am, noon, 6pm in the last 24 hours
+ * Calculate time markers for midnight, 6am, noon, 6pm in the last 24 hours.
+ * Markers follow the timezone's wall clock, so on daylight-saving days they
+ * stay on the local hour even though the day is 23 or 25 hours long.
  */
-function calculateTimeMarkers(timezone?: string): number[] {
-  const tz = timezone || "America/Los_Angeles";
-  const now = Date.now();
+export function calculateTimeMarkers(timezone?: string, now: number = Date.now()): number[] {
+  const tz = timezone || DEFAULT_TIMEZONE;
   const markers: number[] = [];
+  const today = wallTime(now, tz);
 
-  // Get current hour in target timezone
-  const nowDate = new Date(now);
-  const tzHour = parseInt(
-    new Intl.DateTimeFormat("en-US", {
-      timeZone: tz,
-      hour: "2-digit",
-      hour12: false,
-    }).format(nowDate)
-  );
-
-  // Calculate how many ms ago was the start of the current hour in the target timezone
-  const msIntoCurrentHour =
-    nowDate.getMinutes() * 60 * 1000 +
-    nowDate.getSeconds() * 1000 +
-    nowDate.getMilliseconds();
-
-  // Calculate timestamps for midnight (0), 6am (6), noon (12), 6pm (18)
+  // Calculate timestamps for midnight (0), 6am (6), noon (12), 6pm (18),
+  // yesterday's first so markers come out in time order
   const markerHours = [0, 6, 12, 18];
 
-  for (const markerHour of markerHours) {
-    // How many hours ago was this marker?
-    let hoursAgo = tzHour - markerHour;
-    if (hoursAgo < 0) {
-      hoursAgo += 24; // It was yesterday
-    }
-
-    // Calculate the timestamp
-    const msAgo = hoursAgo * 60 * 60 * 1000 + msIntoCurrentHour;
-    const markerTimestamp = now - msAgo;
-
-    // Only include if within the last 24 hours
-    if (now - markerTimestamp <= 24 * 60 * 60 * 1000) {
-      markers.push(markerTimestamp);
+  for (const dayOffset of [-1, 0]) {
+    for (const markerHour of markerHours) {
+      const markerTimestamp = zonedTimestamp(
+        today.year,
+        today.month,
+        today.day + dayOffset,
+        markerHour,
+        0,
+        tz
+      );
+
+      // Only include if within the last 24 hours
+      if (markerTimestamp <= now && now - markerTimestamp <= 24 * 60 * 60 * 1000) {
+        markers.push(markerTimestamp);
+      }
     }
   }
 
@@ -278,32 +293,6 @@ export function calculateInsulinTotal(
     .reduce((sum, t) => sum + t.value, 0);
 }
 
-/**
- * Get midnight timestamp for a given date in a timezone.
- * Works correctly regardless of runtime timezone (e.g., UTC on Lambda).
- */
-function getMidnightTimestamp(date: Date, timezone: string): number {
-  // Get the current time-of-day in the target timezone
-  const formatter = new Intl.DateTimeFormat("en-US", {
-    timeZone: timezone,
-    hour: "2-digit",
-    minute: "2-digit",
-    second: "2-digit",
-    hour12: false,
-  });
-
-  const parts = formatter.formatToParts(date);
-  const hour = parseInt(parts.find(p => p.type === "hour")?.value || "0");
-  const minute = parseInt(parts.find(p => p.type === "minute")?.value || "0");
-  const second = parseInt(parts.find(p => p.type === "second")?.value || "0");
-
-  // Calculate seconds since midnight in the target timezone
-  const secondsSinceMidnight = hour * 3600 + minute * 60 + second;
-
-  // Subtract that from the timestamp to get midnight
-  return date.getTime() - secondsSinceMidnight * 1000;
-}
-
 /**
  * Get date string (YYYY-MM-DD) for a timestamp in a specific timezone
  */
@@ -330,24 +319,15 @@ function renderTreatmentChart(
 ): void {
   const { treatments: treatmentList, dailyInsulinTotals } = treatments;
   const now = Date.now();
-  const tz = timezone || "America/Los_Angeles";
+  const tz = timezone || DEFAULT_TIMEZONE;
   const numDays = 5;
 
-  // Calculate midnights for each of the last 5 days
-  // We calculate each separately to handle DST transitions correctly
+  // Calculate midnights for each of the last 5 days from the local date,
+  // so 23 and 25 hour daylight-saving days still start at local midnight
+  const today = wallTime(now, tz);
   const midnights: number[] = [];
-
-  // Get today's midnight
-  let currentMidnight = getMidnightTimestamp(new Date(now), tz);
-  midnights.push(currentMidnight);
-
-  // Go back 4 more days, subtracting from each midnight
-  for (let i = 0; i < numDays - 1; i++) {
-    // Subtract 1 hour from midnight to land at 23:00 the previous day
-    // getMidnightTimestamp will then find that day's midnight
-    const previousDayTime = currentMidnight - 1 * 60 * 60 * 1000;
-    currentMidnight = getMidnightTimestamp(new Date(previousDayTime), tz);
-    midnights.unshift(currentMidnight);
+  for (let i = numDays - 1; i >= 0; i--) {
+    midnights.push(zonedTimestamp(today.year, today.month, today.day - i, 0, 0, tz));
   }
 
   // midnights = [4 days ago, 3 days ago, 2 days ago, yesterday, today]
@@ -489,28 +469,44 @@ function renderTreatmentChart(
 }
 
 /**
- * Render blood sugar widget to bottom region of frame
- * Layout: text on top, treatment chart, then glucose sparkline
+ * Glucose chart options a layout can set
  */
-export function renderBloodSugarRegion(
-  frame: Frame,
-  data: BloodSugarDisplayData | null,
-  history?: BloodSugarHistory,
-  timezone?: string,
-  treatments?: TreatmentDisplayData | null
-): void {
-  if (!data) {
-    const errText = "BG ERR";
-    drawText(frame, errText, centerXWithMargin(errText), TEXT_ROW, COLORS.urgentLow, BG_REGION_START, BG_REGION_END);
-    return;
+export type BloodSugarChartOptions = Pick<ChartConfig, "targetBand" | "projectionMinutes" | "antialias" | "lineStyle" | "fill">;
+
+/** Text row message for each state without a reading to show */
+const STATE_MESSAGES: Record<Exclude<GlucoseRenderState, "live" | "stale">, { text: string; color: RGB }> = {
+  noSignal: { text: "NO SIGNAL", color: COLORS.noSignal },
+  noData: { text: "NO DATA", color: COLORS.stale },
+  unreachable: { text: "NO DEXCOM", color: COLORS.unreachable },
+  reconnecting: { text: "RECONNECT", color: COLORS.reconnecting },
+};
+
+/**
+ * Turn the glucose chart gray, for a reading that isn't live
+ */
+function grayOutChart(frame: Frame): void {
+  for (let y = GLUCOSE_CHART_Y; y < GLUCOSE_CHART_Y + GLUCOSE_CHART_HEIGHT; y++) {
+    for (let x = CHART_X; x < CHART_X + CHART_WIDTH; x++) {
+      const i = (y * frame.width + x) * 3;
+      const luma = Math.round(0.3 * frame.pixels[i] + 0.59 * frame.pixels[i + 1] + 0.11 * frame.pixels[i + 2]);
+      frame.pixels[i] = frame.pixels[i + 1] = frame.pixels[i + 2] = luma;
+    }
   }
+}
 
+/**
+ * Draw the reading row: arrow, reading, delta and age. A stale reading is
+ * gray and its age blinks.
+ */
+function drawReadingRow(frame: Frame, data: BloodSugarDisplayData, blinkOn: boolean): void {
   const { glucose, trend, delta, timestamp, rangeStatus, isStale } = data;
   const valueColor = isStale ? COLORS.stale : COLORS[rangeStatus];
 
   // Top: Arrow + reading + delta + time
-  // Use spaces when there's room, remove them when tight
-  const deltaStr = delta >= 0 ? `+${delta}` : String(delta);
+  // Use spaces when there's room, remove them when tight.
+  // Delta is omitted when the sensor reports no usable trend.
+  const showDelta = isTrendComputable(trend);
+  const deltaStr = showDelta ? (delta >= 0 ? `+${delta}` : String(delta)) : "";
   const mins = minutesAgo(timestamp);
 
   // Calculate widths for different spacing options
@@ -518,11 +514,11 @@ export function renderBloodSugarRegion(
   const timeStr = `${mins}m`;
 
   // Full spacing: "194 +8 5m"
-  const fullText = `${glucoseStr} ${deltaStr} ${timeStr}`;
+  const fullText = showDelta ? `${glucoseStr} ${deltaStr} ${timeStr}` : `${glucoseStr} ${timeStr}`;
   const fullWidth = ARROW_WIDTH + 2 + measureText(fullText);
 
   // Tight spacing: "194+8 5m" (remove space after glucose)
-  const tightText = `${glucoseStr}${deltaStr} ${timeStr}`;
+  const tightText = showDelta ? `${glucoseStr}${deltaStr} ${timeStr}` : fullText;
   const tightWidth = ARROW_WIDTH + 2 + measureText(tightText);
 
   // Available width (with margins)
@@ -546,18 +542,55 @@ export function renderBloodSugarRegion(
   textX += measureText(glucoseStr);
 
   // Add space if using full spacing (use measureText for font-consistent spacing)
-  if (useFullSpacing) {
+  if (useFullSpacing || !showDelta) {
     textX += measureText(" ");
   }
 
   // Draw delta in trend-tinted color (blends reading color with trend direction)
-  const deltaColor = getTrendTintedColor(valueColor, trend);
-  drawText(frame, deltaStr, textX, TEXT_ROW, deltaColor, BG_REGION_START, BG_REGION_END);
-  textX += measureText(deltaStr);
-  textX += measureText(" ");
+  if (showDelta) {
+    const deltaColor = getTrendTintedColor(valueColor, trend);
+    drawText(frame, deltaStr, textX, TEXT_ROW, deltaColor, BG_REGION_START, BG_REGION_END);
+    textX += measureText(deltaStr);
+    textX += measureText(" ");
+  }
 
-  // Draw update time in off-white (less eye-catching)
-  drawText(frame, timeStr, textX, TEXT_ROW, COLORS.updateTime, BG_REGION_START, BG_REGION_END);
+  // Draw update time in off-white (less eye-catching), or in its own color
+  // when the reading is held back by the Dexcom request budget. A stale
+  // reading's age blinks, so an old number can't pass for a current one.
+  const timeColor = data.rateLimited ? COLORS.rateLimited : COLORS.updateTime;
+  if (!isStale || blinkOn) {
+    drawText(frame, timeStr, textX, TEXT_ROW, timeColor, BG_REGION_START, BG_REGION_END);
+  }
+}
+
+/**
+ * Render blood sugar widget to bottom region of frame
+ * Layout: text on top, treatment chart, then glucose sparkline.
+ * Without a live reading the text row says why (see glucose-state.ts)
+ * and the chart is gray.
+ */
+export function renderBloodSugarRegion(
+  frame: Frame,
+  data: BloodSugarDisplayData | null,
+  history?: BloodSugarHistory,
+  timezone?: string,
+  treatments?: TreatmentDisplayData | null,
+  annotations?: Annotation[],
+  chartOptions: BloodSugarChartOptions = {},
+  sourceStatus: GlucoseSourceStatus = {}
+): void {
+  const now = Date.now();
+  const state = resolveGlucoseState(data, sourceStatus, now);
+  const blinkOn = isBlinkOn(now);
+
+  if (state === "live" || state === "stale") {
+    if (data) drawReadingRow(frame, data, blinkOn);
+  } else if (state !== "reconnecting" || blinkOn) {
+    const { text: message, color } = STATE_MESSAGES[state];
+    // A gap says how long it's been: "NO SIGNAL 42m"
+    const text = state === "noSignal" && data ? `${message} ${formatGap(now - data.timestamp)}` : message;
+    drawText(frame, text, centerXWithMargin(text), TEXT_ROW, color, BG_REGION_START, BG_REGION_END);
+  }
 
   // Treatment chart (4-day midnight-to-midnight insulin totals)
   if (treatments && !treatments.isStale) {
@@ -584,6 +617,25 @@ export function renderBloodSugarRegion(
       drawTinyText(frame, tirStr, tirX, legendY, COLORS.veryDim);
     }
 
+    // Annotation lines under the sparkline
+    if (annotations && annotations.length > 0) {
+      renderAnnotationMarkers(frame, annotations, {
+        x: CHART_X,
+        y: GLUCOSE_CHART_Y,
+        width: CHART_LEFT_WIDTH,
+        height: GLUCOSE_CHART_HEIGHT,
+        hours: CHART_LEFT_HOURS,
+        offsetHours: CHART_RIGHT_HOURS,
+      });
+      renderAnnotationMarkers(frame, annotations, {
+        x: rightX,
+        y: GLUCOSE_CHART_Y,
+        width: CHART_RIGHT_WIDTH,
+        height: GLUCOSE_CHART_HEIGHT,
+        hours: CHART_RIGHT_HOURS,
+      });
+    }
+
     // Left half: 21 hour compressed history (from -24h to -3h, offset by 3h)
     renderChart(frame, history.points, {
       x: CHART_X,
@@ -594,9 +646,13 @@ export function renderBloodSugarRegion(
       offsetHours: CHART_RIGHT_HOURS, // Offset by 3h so it shows -24h to -3h
       timeMarkers,
       timezone,
+      targetBand: chartOptions.targetBand,
+      antialias: chartOptions.antialias,
+      lineStyle: chartOptions.lineStyle,
+      fill: chartOptions.fill,
     });
 
-    // Right half: 3 hour detailed history
+    // Right half: 3 hour detailed history, plus the projection
     renderChart(frame, history.points, {
       x: rightX,
       y: GLUCOSE_CHART_Y,
@@ -605,7 +661,32 @@ export function renderBloodSugarRegion(
       hours: CHART_RIGHT_HOURS,
       timeMarkers,
       timezone,
+      ...chartOptions,
     });
+
+    // Treatment markers on top of the line; labels only fit in the detailed 3h half
+    if (treatments && treatments.treatments.length > 0) {
+      renderTreatmentMarkers(frame, treatments.treatments, {
+        x: CHART_X,
+        y: GLUCOSE_CHART_Y,
+        width: CHART_LEFT_WIDTH,
+        height: GLUCOSE_CHART_HEIGHT,
+        hours: CHART_LEFT_HOURS,
+        offsetHours: CHART_RIGHT_HOURS,
+      });
+      renderTreatmentMarkers(frame, treatments.treatments, {
+        x: rightX,
+        y: GLUCOSE_CHART_Y,
+        width: CHART_RIGHT_WIDTH,
+        height: GLUCOSE_CHART_HEIGHT,
+        hours: CHART_RIGHT_HOURS,
+        showLabels: true,
+      });
+    }
+
+    if (state !== "live") {
+      grayOutChart(frame);
+    }
   }
 }
 
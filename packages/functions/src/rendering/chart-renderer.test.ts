@@ -3,7 +3,7 @@
  */
 
 import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
-import { renderChart } from "./chart-renderer.js";
+import { renderChart, renderSparkline } from "./chart-renderer.js";
 import type { Frame, RGB } from "@signage/core";
 
 // Mock setPixel to track what pixels are drawn
@@ -133,3 +133,38 @@ describe("renderChart time markers", () => {
     expect(pixelsAtLeftEdge.length).toBeGreaterThanOrEqual(23);
   });
 });
+
+describe("renderSparkline", () => {
+  const color: RGB = { r: 0, g: 200, b: 0 };
+  let mockFrame: Frame;
+
+  beforeEach(() => {
+    drawnPixels.length = 0;
+    mockFrame = {
+      pixels: new Uint8Array(64 * 64 * 3),
+      width: 64,
+      height: 64,
+    };
+  });
+
+  it("scales the values to fill the box, lowest at the bottom", () => {
+    renderSparkline(mockFrame, [10, 20, 15], 0, 10, 11, 5, color);
+
+    expect(drawnPixels).toContainEqual({ x: 0, y: 14, color });
+    expect(drawnPixels).toContainEqual({ x: 5, y: 10, color });
+    expect(drawnPixels).toContainEqual({ x: 10, y: 12, color });
+    expect(drawnPixels.every((p) => p.x >= 0 && p.x <= 10 && p.y >= 10 && p.y <= 14)).toBe(true);
+  });
+
+  it("draws a flat series at mid-height", () => {
+    renderSparkline(mockFrame, [5, 5, 5], 0, 10, 8, 5, color);
+
+    expect(new Set(drawnPixels.map((p) => p.y))).toEqual(new Set([12]));
+  });
+
+  it("draws nothing without values", () => {
+    renderSparkline(mockFrame, [], 0, 10, 8, 5, color);
+
+    expect(drawnPixels).toHaveLength(0);
+  });
+});
@@ -2,9 +2,12 @@
  * Sparkline chart renderer for blood sugar history
  */
 
-import type { Frame } from "@signage/core";
-import { setPixel } from "@signage/core";
+import type { Frame, RGB } from "@signage/core";
+import { blendPixel, BYTES_PER_PIXEL, setPixel } from "@signage/core";
 import { COLORS } from "./colors.js";
+import { drawTinyText, measureTinyText } from "./text.js";
+import { calculateRateOfChange } from "../alerts/engine.js";
+import { DEFAULT_TIMEZONE } from "./zoned-time.js";
 
 /**
  * A single point for the chart
@@ -14,6 +17,57 @@ export interface ChartPoint {
   glucose: number;
 }
 
+/**
+ * Target range band drawn behind the chart line
+ */
+export interface TargetBand {
+  /** Lower edge in mg/dL (default: 70) */
+  low?: number;
+  /** Upper edge in mg/dL (default: 180) */
+  high?: number;
+  /** Fill the band, or draw dotted lines at its edges (default: shade) */
+  style?: "shade" | "outline";
+}
+
+/**
+ * A point of a series overlaid on the glucose chart
+ */
+export interface SeriesPoint {
+  timestamp: number;
+  value: number;
+}
+
+/**
+ * A second series drawn over the glucose chart on the same time axis, with
+ * its own vertical scale (heart rate, steps, ...)
+ */
+export interface ChartOverlay {
+  points: SeriesPoint[];
+  /** Line color; pick one unlike the glucose colors */
+  color: RGB;
+  /** Value at the bottom of the chart (default: the lowest visible value) */
+  min?: number;
+  /** Value at the top of the chart (default: the highest visible value) */
+  max?: number;
+  /** How the line is drawn (default: dashed, 1px) */
+  style?: LineStyle;
+  /** Label the top and bottom of its scale at the chart's right edge (default: false) */
+  labels?: boolean;
+}
+
+/**
+ * How a chart line is drawn
+ */
+export interface LineStyle {
+  /** Width in pixels, 1-3 (default: 1) */
+  thickness?: number;
+  /**
+   * Alternating on and off run lengths in columns, starting with on:
+   * [1, 1] is dotted, [3, 2] dashed (default: solid)
+   */
+  dash?: number[];
+}
+
 /**
  * Chart configuration
  */
@@ -34,8 +88,57 @@ export interface ChartConfig {
   padding?: number;
   /** Timestamps to draw as vertical marker lines */
   timeMarkers?: number[];
-  /** Timezone for time marker calculations (default: America/Los_Angeles) */
+  /** Timezone for time marker calculations (default: DEFAULT_TIMEZONE) */
   timezone?: string;
+  /** Mark the target range so it's obvious when the line leaves it (default: off) */
+  targetBand?: TargetBand;
+  /** Glucose values (mg/dL) to draw dotted horizontal gridlines at (default: none) */
+  gridlines?: number[];
+  /** Label each gridline with its value at the chart's left edge (default: false) */
+  gridLabels?: boolean;
+  /**
+   * Extend the line this many minutes past the latest reading along the
+   * recent trend, dimmed and dotted. Only for charts that end now
+   * (default: 0, off)
+   */
+  projectionMinutes?: number;
+  /**
+   * Blend the line's edges into the neighbouring pixels so diagonal trends
+   * look smooth instead of stair-stepped (default: false)
+   */
+  antialias?: boolean;
+  /** How the glucose line is drawn; the readings themselves stay single pixels (default: solid, 1px) */
+  lineStyle?: LineStyle;
+  /** How the projected trend is drawn (default: dotted, 1px) */
+  projectionStyle?: LineStyle;
+  /**
+   * Shade the area under the line in its colors, dimmed and fading toward
+   * the bottom (default: false)
+   */
+  fill?: boolean;
+  /** Second series on its own scale, drawn under the glucose line (default: none) */
+  overlay?: ChartOverlay;
+}
+
+/** Brightness of the projected line relative to the real one */
+const PROJECTION_DIM = 0.45;
+
+/** Brightness of the fill just under the line, and at the chart's bottom */
+const FILL_TOP_DIM = 0.3;
+const FILL_BOTTOM_DIM = 0.08;
+
+/** Every other column */
+const DOTTED: LineStyle = { dash: [1, 1] };
+
+/** Two on, one off: unlike both the solid line and the dotted projection */
+const DASHED: LineStyle = { dash: [2, 1] };
+
+/** Widest line drawn; any wider swamps a 20-row chart */
+const MAX_LINE_THICKNESS = 3;
+
+function lineThickness(style: LineStyle): number {
+  const thickness = Math.round(style.thickness ?? 1);
+  return Number.isFinite(thickness) ? Math.max(1, Math.min(MAX_LINE_THICKNESS, thickness)) : 1;
 }
 
 // Target range for coloring
@@ -53,7 +156,7 @@ function lerp(a: number, b: number, t: number): number {
 /**
  * Interpolate between two colors
  */
-function lerpColor(
+export function lerpColor(
   c1: { r: number; g: number; b: number },
   c2: { r: number; g: number; b: number },
   t: number
@@ -96,6 +199,29 @@ function getGlucoseColor(glucose: number): { r: number; g: number; b: number } {
   }
 }
 
+/**
+ * Project glucose along the recent trend (least-squares slope over the last
+ * 15 minutes, as the predictive low alert uses). Returns the projected point
+ * `minutes` after the latest reading, clamped to the chart's 40-400 scale,
+ * or null when the history is too short or too old for a slope.
+ */
+export function projectTrend(
+  points: ChartPoint[],
+  minutes: number,
+  now: number = Date.now()
+): ChartPoint | null {
+  if (points.length === 0 || minutes <= 0) return null;
+
+  const rate = calculateRateOfChange(points, now);
+  if (rate === null) return null;
+
+  const latest = points.reduce((a, b) => (b.timestamp > a.timestamp ? b : a));
+  return {
+    timestamp: latest.timestamp + minutes * 60 * 1000,
+    glucose: Math.max(40, Math.min(400, latest.glucose + rate * minutes)),
+  };
+}
+
 /**
  * Render a sparkline chart of blood sugar history
  */
@@ -113,7 +239,16 @@ export function renderChart(
     offsetHours = 0,
     padding = 15,
     timeMarkers = [],
-    timezone = "America/Los_Angeles",
+    timezone = DEFAULT_TIMEZONE,
+    targetBand,
+    gridlines = [],
+    gridLabels = false,
+    projectionMinutes = 0,
+    antialias = false,
+    lineStyle = {},
+    projectionStyle = DOTTED,
+    fill = false,
+    overlay,
   } = config;
 
   if (points.length === 0) return;
@@ -121,7 +256,10 @@ export function renderChart(
   const now = Date.now();
   const endTime = now - offsetHours * 60 * 60 * 1000;
   const startTime = endTime - hours * 60 * 60 * 1000;
-  const timeRange = hours * 60 * 60 * 1000;
+  // The projection only makes sense at the live end of the chart; it gets
+  // its own slice of the time axis after now
+  const projectionMs = offsetHours === 0 ? projectionMinutes * 60 * 1000 : 0;
+  const timeRange = hours * 60 * 60 * 1000 + projectionMs;
 
   // Filter points to the time range
   const visiblePoints = points.filter((p) => p.timestamp >= startTime && p.timestamp <= endTime);
@@ -131,8 +269,15 @@ export function renderChart(
   // Sort by timestamp
   visiblePoints.sort((a, b) => a.timestamp - b.timestamp);
 
-  // Calculate adaptive range from actual data
+  // Projected end point, from the latest reading to the end of the axis
+  const latest = visiblePoints[visiblePoints.length - 1];
+  const projected =
+    projectionMs > 0 ? projectTrend(visiblePoints, (endTime + projectionMs - latest.timestamp) / 60000, now) : null;
+
+  // Calculate adaptive range from actual data (and the projection, so a
+  // predicted drop stays on the chart)
   const glucoseValues = visiblePoints.map((p) => p.glucose);
+  if (projected) glucoseValues.push(projected.glucose);
   const dataMin = Math.min(...glucoseValues);
   const dataMax = Math.max(...glucoseValues);
 
@@ -145,8 +290,11 @@ export function renderChart(
   const maxGlucose = Math.min(400, dataMax + padding + extraPadding);
   const glucoseRange = maxGlucose - minGlucose;
 
-  // Target range background removed - the line color gradient provides
-  // sufficient visual indication of range status
+  const glucoseToY = (glucose: number): number =>
+    y + height - 1 - Math.round(((glucose - minGlucose) / glucoseRange) * (height - 1));
+
+  const timeToX = (timestamp: number): number =>
+    x + Math.round(((timestamp - startTime) / timeRange) * (width - 1));
 
   // Helper to convert Y pixel position to glucose value
   const yToGlucose = (py: number): number => {
@@ -154,6 +302,25 @@ export function renderChart(
     return minGlucose + normalizedY * glucoseRange;
   };
 
+  // Fill first, so the band and gridlines only show above the line
+  if (fill) {
+    const linePoints = visiblePoints.map((p) => ({
+      px: timeToX(p.timestamp),
+      py: glucoseToY(Math.max(minGlucose, Math.min(maxGlucose, p.glucose))),
+    }));
+    renderFill(frame, linePoints, yToGlucose, x, y, width, height);
+  }
+
+  // Target range band is opt-in - by default the line color gradient
+  // indicates range status
+  if (targetBand) {
+    renderTargetBand(frame, targetBand, minGlucose, maxGlucose, glucoseToY, x, width);
+  }
+
+  if (gridlines.length > 0) {
+    renderGridlines(frame, gridlines, gridLabels, minGlucose, maxGlucose, glucoseToY, x, y, width, height);
+  }
+
   // Draw time marker vertical lines FIRST (so chart line appears on top)
   // Each marker has brightness based on sunlight percentage for that hour
   // Use exclusive end when there's an offset to avoid double-draw at chart boundary
@@ -199,14 +366,30 @@ export function renderChart(
     }
   }
 
+  if (overlay) {
+    renderOverlay(frame, overlay, startTime, endTime, timeToX, antialias, x, y, width, height);
+  }
+
+  // Projection under the line, so the latest reading stays at full brightness
+  if (projected) {
+    const colorAt = (py: number): RGB => {
+      const color = getGlucoseColor(yToGlucose(py));
+      return {
+        r: Math.round(color.r * PROJECTION_DIM),
+        g: Math.round(color.g * PROJECTION_DIM),
+        b: Math.round(color.b * PROJECTION_DIM),
+      };
+    };
+    renderProjection(frame, latest, projected, projectionStyle, colorAt, timeToX, glucoseToY, x, y, width, height);
+  }
+
   // Draw the line chart ON TOP of markers
   let prevPixelX: number | null = null;
   let prevPixelY: number | null = null;
 
   for (const point of visiblePoints) {
     // Calculate pixel position
-    const timeOffset = point.timestamp - startTime;
-    const pixelX = x + Math.round((timeOffset / timeRange) * (width - 1));
+    const pixelX = timeToX(point.timestamp);
 
     const clampedGlucose = Math.max(minGlucose, Math.min(maxGlucose, point.glucose));
     const glucoseOffset = clampedGlucose - minGlucose;
@@ -219,18 +402,297 @@ export function renderChart(
 
       // Connect to previous point with a line (color determined per-pixel by Y position)
       if (prevPixelX !== null && prevPixelY !== null) {
-        drawLine(frame, prevPixelX, prevPixelY, pixelX, pixelY, yToGlucose, x, y, width, height);
+        const colorAt = (py: number) => getGlucoseColor(yToGlucose(py));
+        const draw = antialias ? drawSmoothLine : drawLine;
+        draw(frame, prevPixelX, prevPixelY, pixelX, pixelY, colorAt, x, y, width, height, lineStyle);
       }
     }
 
     prevPixelX = pixelX;
     prevPixelY = pixelY;
   }
+
+}
+
+/**
+ * Render a plain sparkline: values evenly spaced across the width, scaled
+ * to fill the height, in one color. For series other than glucose, which
+ * have no fixed scale or range colors.
+ */
+export function renderSparkline(
+  frame: Frame,
+  values: number[],
+  x: number,
+  y: number,
+  width: number,
+  height: number,
+  color: RGB,
+  style: LineStyle = {}
+): void {
+  const finite = values.filter((v) => Number.isFinite(v));
+  if (finite.length === 0 || width <= 0 || height <= 0) return;
+
+  const min = Math.min(...finite);
+  const range = Math.max(...finite) - min;
+  // A flat series sits mid-height rather than on the bottom edge
+  const valueToY = (value: number): number =>
+    range === 0
+      ? y + Math.floor((height - 1) / 2)
+      : y + height - 1 - Math.round(((value - min) / range) * (height - 1));
+  const indexToX = (i: number): number =>
+    finite.length === 1 ? x + width - 1 : x + Math.round((i / (finite.length - 1)) * (width - 1));
+
+  let prevX = indexToX(0);
+  let prevY = valueToY(finite[0]);
+  setPixel(frame, prevX, prevY, color);
+  for (let i = 1; i < finite.length; i++) {
+    const px = indexToX(i);
+    const py = valueToY(finite[i]);
+    drawLine(frame, prevX, prevY, px, py, () => color, x, y, width, height, style);
+    prevX = px;
+    prevY = py;
+  }
+}
+
+/**
+ * Draw an overlay series across the chart, scaled to fill its height
+ * independently of the glucose scale, with optional scale labels at the
+ * right edge
+ */
+function renderOverlay(
+  frame: Frame,
+  overlay: ChartOverlay,
+  startTime: number,
+  endTime: number,
+  timeToX: (timestamp: number) => number,
+  antialias: boolean,
+  x: number,
+  y: number,
+  width: number,
+  height: number
+): void {
+  const visible = overlay.points
+    .filter((p) => p.timestamp >= startTime && p.timestamp <= endTime && Number.isFinite(p.value))
+    .sort((a, b) => a.timestamp - b.timestamp);
+  if (visible.length === 0) return;
+
+  const values = visible.map((p) => p.value);
+  const min = overlay.min ?? Math.min(...values);
+  const max = overlay.max ?? Math.max(...values);
+  // A flat series sits mid-height
+  const valueToY = (value: number): number =>
+    max <= min
+      ? y + Math.floor((height - 1) / 2)
+      : y + height - 1 - Math.round(((Math.max(min, Math.min(max, value)) - min) / (max - min)) * (height - 1));
+
+  const { color, style = DASHED } = overlay;
+  const draw = antialias ? drawSmoothLine : drawLine;
+  let prevX = timeToX(visible[0].timestamp);
+  let prevY = valueToY(visible[0].value);
+  if (visible.length === 1) {
+    setPixel(frame, prevX, prevY, color);
+  }
+  for (const point of visible.slice(1)) {
+    const px = timeToX(point.timestamp);
+    const py = valueToY(point.value);
+    draw(frame, prevX, prevY, px, py, () => color, x, y, width, height, style);
+    prevX = px;
+    prevY = py;
+  }
+
+  if (overlay.labels && max > min && height >= 10) {
+    for (const [value, labelY] of [
+      [max, y],
+      [min, y + height - 5],
+    ]) {
+      const label = String(Math.round(value));
+      drawTinyText(frame, label, x + width - measureTinyText(label), labelY, color);
+    }
+  }
+}
+
+/**
+ * Draw the projected trend from the latest reading. Dashes count from the
+ * latest reading's column, so a dotted projection leaves a gap after it.
+ * Drawn before the line, which covers its first pixel.
+ */
+function renderProjection(
+  frame: Frame,
+  from: ChartPoint,
+  to: ChartPoint,
+  style: LineStyle,
+  colorAt: (y: number) => RGB,
+  timeToX: (timestamp: number) => number,
+  glucoseToY: (glucose: number) => number,
+  x: number,
+  y: number,
+  width: number,
+  height: number
+): void {
+  const fromX = timeToX(from.timestamp);
+  const toX = timeToX(to.timestamp);
+  if (toX <= fromX) return;
+
+  const fromY = glucoseToY(from.glucose);
+  const toY = glucoseToY(to.glucose);
+  drawLine(frame, fromX, fromY, toX, toY, colorAt, x, y, width, height, style, fromX);
+}
+
+/**
+ * Shade the area under the line: each column from just below the line's
+ * height there down to the chart's bottom, in the color of each row's
+ * glucose, fading with depth
+ */
+function renderFill(
+  frame: Frame,
+  linePoints: { px: number; py: number }[],
+  yToGlucose: (py: number) => number,
+  x: number,
+  y: number,
+  width: number,
+  height: number
+): void {
+  const bottom = y + height - 1;
+  for (let i = 1; i < linePoints.length; i++) {
+    const from = linePoints[i - 1];
+    const to = linePoints[i];
+    // Each segment fills up to the column before the next one starts
+    const lastX = i === linePoints.length - 1 ? to.px : to.px - 1;
+    for (let px = Math.max(from.px, x); px <= Math.min(lastX, x + width - 1); px++) {
+      const t = to.px === from.px ? 0 : (px - from.px) / (to.px - from.px);
+      const lineY = Math.round(from.py + (to.py - from.py) * t);
+      for (let py = Math.max(lineY + 1, y); py <= bottom; py++) {
+        const depth = bottom === lineY ? 1 : (py - lineY) / (bottom - lineY);
+        const dim = FILL_TOP_DIM + (FILL_BOTTOM_DIM - FILL_TOP_DIM) * depth;
+        const color = getGlucoseColor(yToGlucose(py));
+        setPixel(frame, px, py, {
+          r: Math.round(color.r * dim),
+          g: Math.round(color.g * dim),
+          b: Math.round(color.b * dim),
+        });
+      }
+    }
+  }
+}
+
+/**
+ * Paint a chart background pixel (band, gridline) unless something is
+ * already drawn there
+ */
+function setBackgroundPixel(frame: Frame, px: number, py: number, color: RGB = COLORS.chartTarget): void {
+  if (px < 0 || px >= frame.width || py < 0 || py >= frame.height) return;
+  // Read the bytes directly: this runs for every band and gridline pixel
+  const offset = (py * frame.width + px) * BYTES_PER_PIXEL;
+  if (frame.pixels[offset] === 0 && frame.pixels[offset + 1] === 0 && frame.pixels[offset + 2] === 0) {
+    setPixel(frame, px, py, color);
+  }
+}
+
+/**
+ * Draw the target range band, clipped to the chart's visible glucose range.
+ * Drawn before markers and the line so both stay on top, and only onto
+ * blank pixels so legends and annotations drawn earlier show through.
+ */
+function renderTargetBand(
+  frame: Frame,
+  band: TargetBand,
+  minGlucose: number,
+  maxGlucose: number,
+  glucoseToY: (glucose: number) => number,
+  x: number,
+  width: number
+): void {
+  const { low = TARGET_LOW, high = TARGET_HIGH, style = "shade" } = band;
+
+  if (style === "outline") {
+    // Edges only where they're on the chart; dotted so they read as guides
+    for (const edge of [low, high]) {
+      if (edge < minGlucose || edge > maxGlucose) continue;
+      const edgeY = glucoseToY(edge);
+      for (let px = x; px < x + width; px += 2) {
+        setBackgroundPixel(frame, px, edgeY);
+      }
+    }
+    return;
+  }
+
+  const bandLow = Math.max(low, minGlucose);
+  const bandHigh = Math.min(high, maxGlucose);
+  if (bandLow > bandHigh) return;
+
+  for (let py = glucoseToY(bandHigh); py <= glucoseToY(bandLow); py++) {
+    for (let px = x; px < x + width; px++) {
+      setBackgroundPixel(frame, px, py);
+    }
+  }
+}
+
+/**
+ * Draw dotted gridlines at glucose values within the visible range, with
+ * optional tiny labels at the left edge. Labels that would overlap one
+ * already drawn are skipped.
+ */
+function renderGridlines(
+  frame: Frame,
+  values: number[],
+  labels: boolean,
+  minGlucose: number,
+  maxGlucose: number,
+  glucoseToY: (glucose: number) => number,
+  x: number,
+  y: number,
+  width: number,
+  height: number
+): void {
+  const visible = values.filter((v) => v >= minGlucose && v <= maxGlucose).sort((a, b) => b - a);
+  let lastLabelBottom = -Infinity;
+
+  for (const value of visible) {
+    const lineY = glucoseToY(value);
+    for (let px = x; px < x + width; px += 3) {
+      setBackgroundPixel(frame, px, lineY, COLORS.chartGrid);
+    }
+
+    if (!labels) continue;
+
+    // Centered on the line, kept inside the chart
+    const label = String(value);
+    const labelY = Math.max(y, Math.min(y + height - 5, lineY - 2));
+    if (labelY <= lastLabelBottom || measureTinyText(label) > width) continue;
+    drawTinyText(frame, label, x, labelY, COLORS.chartGridLabel);
+    lastLabelBottom = labelY + 5;
+  }
+}
+
+/**
+ * Whether a column falls in an "on" run of a dash pattern, counting columns
+ * from the pattern's start
+ */
+function isDashOn(dash: number[] | undefined, column: number): boolean {
+  if (!dash || dash.length === 0) return true;
+  const period = dash.reduce((sum, run) => sum + run, 0);
+  if (period <= 0) return true;
+
+  let offset = ((column % period) + period) % period;
+  for (let i = 0; i < dash.length; i++) {
+    if (offset < dash[i]) return i % 2 === 0;
+    offset -= dash[i];
+  }
+  return true;
+}
+
+/**
+ * Offset of a thick line's first pixel from its center: a 2px line widens
+ * down (or right, for steep lines), a 3px line both ways
+ */
+function thicknessStart(thickness: number): number {
+  return -Math.floor((thickness - 1) / 2);
 }
 
 /**
  * Draw a line between two points using Bresenham's algorithm
- * Color is determined per-pixel based on Y position (glucose level)
+ * Color is determined per-pixel based on Y position (glucose level on the
+ * glucose chart). Dash runs count columns from dashFrom.
  */
 function drawLine(
   frame: Frame,
@@ -238,11 +700,13 @@ function drawLine(
   y0: number,
   x1: number,
   y1: number,
-  yToGlucose: (y: number) => number,
+  colorAt: (y: number) => RGB,
   clipX: number,
   clipY: number,
   clipWidth: number,
-  clipHeight: number
+  clipHeight: number,
+  style: LineStyle = {},
+  dashFrom: number = clipX
 ): void {
   const dx = Math.abs(x1 - x0);
   const dy = Math.abs(y1 - y0);
@@ -250,16 +714,24 @@ function drawLine(
   const sy = y0 < y1 ? 1 : -1;
   let err = dx - dy;
 
+  // Thick lines widen across their direction of travel
+  const thickness = lineThickness(style);
+  const start = thicknessStart(thickness);
+  const steep = dy > dx;
+
   let currentX = x0;
   let currentY = y0;
 
   while (true) {
-    // Only draw if within clip bounds
-    if (currentX >= clipX && currentX < clipX + clipWidth && currentY >= clipY && currentY < clipY + clipHeight) {
-      // Color based on Y position (glucose level at this pixel)
-      const glucose = yToGlucose(currentY);
-      const color = getGlucoseColor(glucose);
-      setPixel(frame, currentX, currentY, color);
+    if (isDashOn(style.dash, currentX - dashFrom)) {
+      for (let offset = start; offset < start + thickness; offset++) {
+        const px = steep ? currentX + offset : currentX;
+        const py = steep ? currentY : currentY + offset;
+        // Only draw if within clip bounds
+        if (px >= clipX && px < clipX + clipWidth && py >= clipY && py < clipY + clipHeight) {
+          setPixel(frame, px, py, colorAt(py));
+        }
+      }
     }
 
     if (currentX === x1 && currentY === y1) break;
@@ -275,3 +747,60 @@ function drawLine(
     }
   }
 }
+
+/**
+ * Draw an anti-aliased line between two points (Xiaolin Wu's algorithm)
+ * Each step along the longer axis covers the pixels the ideal line passes
+ * between: the edge pixels blended in by how close the line comes to them,
+ * any between them (thick lines) solid. Color and dashes work as for drawLine.
+ */
+function drawSmoothLine(
+  frame: Frame,
+  x0: number,
+  y0: number,
+  x1: number,
+  y1: number,
+  colorAt: (y: number) => RGB,
+  clipX: number,
+  clipY: number,
+  clipWidth: number,
+  clipHeight: number,
+  style: LineStyle = {},
+  dashFrom: number = clipX
+): void {
+  const plot = (px: number, py: number, coverage: number): void => {
+    if (coverage <= 0) return;
+    if (px < clipX || px >= clipX + clipWidth || py < clipY || py >= clipY + clipHeight) return;
+    blendPixel(frame, px, py, colorAt(py), coverage);
+  };
+
+  // Walk the longer axis so every step moves exactly one pixel along it
+  const steep = Math.abs(y1 - y0) > Math.abs(x1 - x0);
+  let [major0, minor0, major1, minor1] = steep ? [y0, x0, y1, x1] : [x0, y0, x1, y1];
+  if (major0 > major1) {
+    [major0, major1] = [major1, major0];
+    [minor0, minor1] = [minor1, minor0];
+  }
+
+  const thickness = lineThickness(style);
+  const gradient = major1 === major0 ? 0 : (minor1 - minor0) / (major1 - major0);
+  // Endpoints are whole pixels, so the first and last steps land fully on
+  // the line's pixels
+  for (let major = major0; major <= major1; major++) {
+    const minor = minor0 + gradient * (major - major0);
+    const column = steep ? Math.round(minor) : major;
+    if (!isDashOn(style.dash, column - dashFrom)) continue;
+
+    const edge = minor + thicknessStart(thickness);
+    const base = Math.floor(edge);
+    const frac = edge - base;
+    for (let i = 0; i <= thickness; i++) {
+      const coverage = i === 0 ? 1 - frac : i === thickness ? frac : 1;
+      if (steep) {
+        plot(base + i, major, coverage);
+      } else {
+        plot(major, base + i, coverage);
+      }
+    }
+  }
+}
@@ -4,7 +4,11 @@
 
 import type { Frame } from "@signage/core";
 import { drawText, measureText, DISPLAY_WIDTH } from "./text.js";
+import { LARGE_DIGIT_FONT } from "./fonts.js";
 import { COLORS } from "./colors.js";
+import { formatClockDate, DEFAULT_LOCALE, type LocaleName } from "./locales.js";
+import { DEFAULT_TIMEZONE, wallTime, type WallTime } from "./zoned-time.js";
+import { drawMoonPhase, isMoonHour, moonPhase, MOON_GLYPH_SIZE } from "./moon-phase.js";
 
 // Clock region boundaries (compact - just date/time at top)
 const CLOCK_REGION_START_Y = 0;
@@ -47,6 +51,68 @@ export interface ClockWeatherData {
   currentHourIndex?: number;
 }
 
+/**
+ * What time the clock shows
+ */
+export interface ClockTimeOptions {
+  /** Time to show, in ms (default: now). Lets ticks be rendered ahead. */
+  now?: number;
+  /** Show seconds as H:MM:SS */
+  seconds?: boolean;
+  /** How to write the time (default: each clock's own, see CLOCK_FORMATS) */
+  format?: ClockFormat;
+  /** Show the moon's phase at night (see moon-phase.ts) */
+  moon?: boolean;
+}
+
+/**
+ * Time formats: "12h" is "2:05", "12h-ampm" adds the AM/PM marker, "24h" is
+ * "14:05". The date-line clock defaults to 12h, the large clock to 12h-ampm.
+ */
+export const CLOCK_FORMATS = ["12h", "12h-ampm", "24h"] as const;
+
+export type ClockFormat = (typeof CLOCK_FORMATS)[number];
+
+/**
+ * Check if a value names a clock format
+ */
+export function isClockFormat(value: unknown): value is ClockFormat {
+  return (CLOCK_FORMATS as readonly unknown[]).includes(value);
+}
+
+/**
+ * Local wall-clock time in a timezone. Read through the zone's own rules,
+ * not the process's, so the clock is right on either side of a DST change
+ * wherever the server runs.
+ */
+function localTimeAt(timezone: string, now: number | undefined): WallTime {
+  return wallTime(now ?? Date.now(), timezone);
+}
+
+/**
+ * Calendar date of a wall time, for day and month names. Noon, so no DST
+ * gap in the process's own zone can move it to another day.
+ */
+function calendarDate(wall: WallTime): Date {
+  return new Date(wall.year, wall.month - 1, wall.day, 12);
+}
+
+/**
+ * Time digits ("2:05", "2:05:09", "14:05") and the AM/PM marker, empty
+ * unless the format has one
+ */
+function formatClockTime(wall: WallTime, seconds: boolean | undefined, format: ClockFormat): {
+  digits: string;
+  period: string;
+} {
+  const hours = format === "24h" ? String(wall.hour).padStart(2, "0") : String(wall.hour % 12 || 12);
+  const minutes = String(wall.minute).padStart(2, "0");
+  return {
+    digits: seconds ? `${hours}:${minutes}:${String(wall.second).padStart(2, "0")}` : `${hours}:${minutes}`,
+    period: format === "12h-ampm" ? (wall.hour < 12 ? "AM" : "PM") : "",
+  };
+}
+
 /**
  * Calculate center X position within a bounded region
  */
@@ -62,16 +128,15 @@ function centerXInBounds(text: string, startX: number, endX: number): number {
  */
 export function renderClockRegion(
   frame: Frame,
-  timezone = "America/Los_Angeles",
+  timezone = DEFAULT_TIMEZONE,
   _weather?: ClockWeatherData, // Weather param kept for API compatibility but not used
-  bounds?: ClockRegionBounds
+  bounds?: ClockRegionBounds,
+  locale: LocaleName = DEFAULT_LOCALE,
+  time: ClockTimeOptions = {}
 ): void {
-  const now = new Date();
-  const localTime = new Date(now.toLocaleString("en-US", { timeZone: timezone }));
-  let hours = localTime.getHours();
-  hours = hours % 12 || 12;
-  const minutes = String(localTime.getMinutes()).padStart(2, "0");
-  const timeStr = `${hours}:${minutes}`;
+  const localTime = localTimeAt(timezone, time.now);
+  const { digits, period } = formatClockTime(localTime, time.seconds, time.format ?? "12h");
+  const timeStr = `${digits}${period}`;
 
   // Default bounds (full width top region)
   const startX = bounds?.startX ?? 0;
@@ -93,18 +158,60 @@ export function renderClockRegion(
     return;
   }
 
-  // Full-width region - date and time on single row: "SAT JAN 24 11:09"
-  const days = ["SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"];
-  const months = ["JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"];
-  const dayName = days[localTime.getDay()];
-  const monthName = months[localTime.getMonth()];
-  const dayNum = localTime.getDate();
-  const dateStr = `${dayName} ${monthName} ${dayNum} `;
+  // Full-width region - date and time on single row: "SAT JAN 24 11:09",
+  // after the moon at night. Seconds make it longer, so the weekday and
+  // then the date give way.
+  const moonWidth = time.moon && isMoonHour(localTime.hour) ? MOON_GLYPH_SIZE + 1 : 0;
+  const day = calendarDate(localTime);
+  const dateStr =
+    [`${formatClockDate(day, locale)} `, `${formatClockDate(day, locale, false)} `].find(
+      (date) => measureText(`${date}${timeStr}`) <= regionWidth - moonWidth
+    ) ?? "";
   const dateTimeStr = `${dateStr}${timeStr}`;
 
   // Draw date (dimmer) and time (brighter) with different colors
-  const dateTimeX = centerXInBounds(dateTimeStr, startX, endX);
+  const moonX = centerXInBounds(dateTimeStr, startX, endX - moonWidth);
+  const dateTimeX = moonX + moonWidth;
   const dateWidth = measureText(dateStr);
+  if (moonWidth) {
+    drawMoonPhase(frame, moonX, startY + 1, moonPhase(time.now ?? Date.now()));
+  }
   drawText(frame, dateStr, dateTimeX, startY + 1, COLORS.clockSecondary, startY, endY);
   drawText(frame, timeStr, dateTimeX + dateWidth + 1, startY + 1, COLORS.clockTime, startY, endY);
 }
+
+/**
+ * Render the time in large 8x12 digits with a small AM/PM marker (unless
+ * the format leaves it out) and, at night if asked, the moon above it. Used
+ * by layouts meant to be read from across the room. With seconds the marker
+ * and moon are dropped when they don't fit.
+ */
+export function renderLargeClockRegion(
+  frame: Frame,
+  timezone = DEFAULT_TIMEZONE,
+  bounds: ClockRegionBounds = { startX: 0, endX: DISPLAY_WIDTH - 1, startY: 0, endY: 17 },
+  time: ClockTimeOptions = {}
+): void {
+  const localTime = localTimeAt(timezone, time.now);
+  const { digits: timeStr, period: fullPeriod } = formatClockTime(localTime, time.seconds, time.format ?? "12h-ampm");
+  const regionWidth = bounds.endX - bounds.startX + 1;
+
+  // Time, marker and moon are centered together; the marker sits on the
+  // digits' baseline and the moon level with their top
+  const timeWidth = measureText(timeStr, LARGE_DIGIT_FONT);
+  const period = fullPeriod && timeWidth + 2 + measureText(fullPeriod) <= regionWidth ? fullPeriod : "";
+  const moon = time.moon && isMoonHour(localTime.hour) && timeWidth + 2 + MOON_GLYPH_SIZE <= regionWidth;
+  const sideWidth = Math.max(period ? measureText(period) : 0, moon ? MOON_GLYPH_SIZE : 0);
+  const totalWidth = sideWidth ? timeWidth + 2 + sideWidth : timeWidth;
+  const regionHeight = bounds.endY - bounds.startY + 1;
+  const x = bounds.startX + Math.floor((regionWidth - totalWidth) / 2);
+  const y = bounds.startY + Math.floor((regionHeight - LARGE_DIGIT_FONT.height) / 2);
+
+  drawText(frame, timeStr, x, y, COLORS.clockTime, bounds.startY, bounds.endY, LARGE_DIGIT_FONT);
+  if (period) {
+    drawText(frame, period, x + timeWidth + 2, y + LARGE_DIGIT_FONT.height - 5, COLORS.clockSecondary, bounds.startY, bounds.endY);
+  }
+  if (moon) {
+    drawMoonPhase(frame, x + timeWidth + 2, y, moonPhase(time.now ?? Date.now()));
+  }
+}
@@ -0,0 +1,66 @@
+/**
+ * Tests for seconds clock ticks
+ */
+
+import { describe, it, expect, beforeEach } from "vitest";
+import { clearSurfaceCache, WIDGET_REGIONS } from "./frame-composer.js";
+import { LAYOUTS } from "./layouts.js";
+import { isClockSeconds, renderClockTicks } from "./clock-ticks.js";
+
+// 2:30:57.5 PM Pacific
+const start = Date.parse("2026-01-24T22:30:57.500Z");
+const clockOnly = { ...LAYOUTS.day, widgets: ["clock" as const] };
+
+describe("renderClockTicks", () => {
+  beforeEach(() => {
+    clearSurfaceCache();
+  });
+
+  it("patches each step up to the end, inside the clock row", () => {
+    const patches = renderClockTicks(
+      { bloodSugar: null, layout: clockOnly, now: start, clockSeconds: 1 },
+      Date.parse("2026-01-24T22:31:01Z")
+    );
+
+    expect(patches.map((p) => p.delayMs)).toEqual([500, 1500, 2500]);
+    for (const patch of patches) {
+      expect(patch.y + patch.height).toBeLessThanOrEqual(WIDGET_REGIONS.clock.height);
+      expect(Buffer.from(patch.data, "base64")).toHaveLength(patch.width * patch.height * 3);
+    }
+    // Only the seconds digit changes until the minute rolls over
+    expect(patches[0].width).toBeLessThan(patches[2].width);
+  });
+
+  it("steps by the configured seconds", () => {
+    const patches = renderClockTicks(
+      { bloodSugar: null, layout: clockOnly, now: start, clockSeconds: 5 },
+      start + 20_000
+    );
+
+    expect(patches.map((p) => p.delayMs)).toEqual([2500, 7500, 12500, 17500]);
+  });
+
+  it("passes each frame through the final step", () => {
+    const seen: number[] = [];
+    renderClockTicks({ bloodSugar: null, layout: clockOnly, now: start, clockSeconds: 1 }, start + 2000, (frame) => {
+      seen.push(frame.width);
+    });
+
+    expect(seen).toHaveLength(3);
+  });
+
+  it("renders nothing without seconds", () => {
+    expect(renderClockTicks({ bloodSugar: null, layout: clockOnly, now: start }, start + 60_000)).toEqual([]);
+  });
+});
+
+describe("isClockSeconds", () => {
+  it("accepts whole seconds from 1 to 5", () => {
+    expect(isClockSeconds(1)).toBe(true);
+    expect(isClockSeconds(5)).toBe(true);
+    expect(isClockSeconds(0)).toBe(false);
+    expect(isClockSeconds(6)).toBe(false);
+    expect(isClockSeconds(2.5)).toBe(false);
+    expect(isClockSeconds("2")).toBe(false);
+  });
+});
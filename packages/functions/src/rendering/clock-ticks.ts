@@ -0,0 +1,68 @@
+/**
+ * Seconds clock ticks
+ *
+ * The compositor runs once a minute, so a clock showing seconds has the
+ * rest of the minute rendered ahead: one frame per step, sent alongside the
+ * minute's frame as patches - just the rectangle that changed, usually the
+ * last digits - for the client to apply on a timer. A minute of 1s patches
+ * is a few KB, where full frames would pass API Gateway's 128KB message
+ * limit.
+ */
+
+import { diffFrames, encodeFrameToBase64, subFrame, type Frame, type FrameRect } from "@signage/core";
+import { generateCompositeFrame, type CompositorData } from "./frame-composer.js";
+
+/** Shortest step: one frame a second */
+export const MIN_CLOCK_SECONDS = 1;
+/** Longest step that still reads as a seconds clock */
+export const MAX_CLOCK_SECONDS = 5;
+
+/**
+ * Changed rectangle of a tick frame, applied `delayMs` after the minute's
+ * frame arrives. `data` is base64 RGB for just the rectangle.
+ */
+export interface FramePatch extends FrameRect {
+  delayMs: number;
+  data: string;
+}
+
+/**
+ * Check a clock step from a request: whole seconds, 1-5
+ */
+export function isClockSeconds(value: unknown): value is number {
+  return Number.isInteger(value) && (value as number) >= MIN_CLOCK_SECONDS && (value as number) <= MAX_CLOCK_SECONDS;
+}
+
+/**
+ * Render every clock step after `data.now` up to `until` as patches, each on
+ * the step before. `finish` gets each frame before it's compared, for the
+ * same final passes as the minute's frame (e.g. the power limit).
+ */
+export function renderClockTicks(
+  data: CompositorData,
+  until: number,
+  finish: (frame: Frame) => void = () => {}
+): FramePatch[] {
+  const stepMs = (data.clockSeconds ?? 0) * 1000;
+  if (stepMs <= 0) return [];
+
+  const start = data.now ?? Date.now();
+  let previous = generateCompositeFrame({ ...data, now: start });
+  finish(previous);
+
+  const patches: FramePatch[] = [];
+  for (let at = (Math.floor(start / stepMs) + 1) * stepMs; at < until; at += stepMs) {
+    const next = generateCompositeFrame({ ...data, now: at });
+    finish(next);
+    const rect = diffFrames(previous, next);
+    if (rect) {
+      patches.push({
+        delayMs: at - start,
+        ...rect,
+        data: encodeFrameToBase64(subFrame(next, rect.x, rect.y, rect.width, rect.height)),
+      });
+    }
+    previous = next;
+  }
+  return patches;
+}
@@ -9,6 +9,8 @@ export const COLORS = {
   clockHeader: { r: 0, g: 200, b: 255 } as RGB,
   clockTime: { r: 255, g: 255, b: 255 } as RGB,
   clockSecondary: { r: 100, g: 100, b: 100 } as RGB, // Date, AM/PM, and other secondary text
+  moonLit: { r: 220, g: 220, b: 170 } as RGB,
+  moonDark: { r: 35, g: 35, b: 45 } as RGB, // Unlit part of the moon glyph
 
   // Blood sugar colors by range
   urgentLow: { r: 255, g: 0, b: 0 } as RGB,
@@ -21,6 +23,14 @@ export const COLORS = {
 
   // Update timestamp color (off-white, less eye-catching)
   updateTime: { r: 140, g: 140, b: 140 } as RGB,
+  // Update timestamp while Dexcom calls are rate limited (distinct from stale gray)
+  rateLimited: { r: 90, g: 140, b: 255 } as RGB,
+  // Glucose source failing with nothing cached (distinct from the red of a low)
+  unreachable: { r: 170, g: 80, b: 255 } as RGB,
+  // Sensor sending nothing while Dexcom answers (gap in readings)
+  noSignal: { r: 230, g: 110, b: 60 } as RGB,
+  // Display link down and being retried
+  reconnecting: { r: 0, g: 160, b: 160 } as RGB,
 
   // Readiness score colors
   readinessOptimal: { r: 0, g: 255, b: 0 } as RGB,      // 85-100: Green
@@ -35,6 +45,50 @@ export const COLORS = {
   insulinBolus: { r: 200, g: 180, b: 220 } as RGB,  // Light purple/lavender
   insulinBasal: { r: 140, g: 90, b: 140 } as RGB,   // Darker purple/magenta
 
+  // Alert banner (predictive alerts - distinct from the red of an actual low)
+  alertAccent: { r: 255, g: 0, b: 160 } as RGB,     // Magenta
+  alertBackdrop: { r: 50, g: 0, b: 30 } as RGB,     // Dark magenta
+  alertText: { r: 255, g: 255, b: 255 } as RGB,
+
+  // Notification banner (pushed messages)
+  notificationBackdrop: { r: 0, g: 20, b: 40 } as RGB,   // Dark blue
+  notificationHigh: { r: 255, g: 140, b: 0 } as RGB,     // Orange
+  textBanner: { r: 0, g: 180, b: 140 } as RGB,           // Teal
+
+  // Annotation markers on the glucose chart (sensor change, site change, ...)
+  annotation: { r: 0, g: 90, b: 110 } as RGB,       // Dim teal
+
+  // Target range band behind the glucose chart line
+  chartTarget: { r: 0, g: 35, b: 15 } as RGB,       // Very dim green
+
+  // Pomodoro timer periods
+  pomodoroFocus: { r: 255, g: 90, b: 60 } as RGB,   // Tomato
+  pomodoroBreak: { r: 0, g: 200, b: 120 } as RGB,
+
+  // Ticker daily change
+  tickerUp: { r: 0, g: 220, b: 90 } as RGB,
+  tickerDown: { r: 255, g: 60, b: 60 } as RGB,
+
+  // Now playing progress bar
+  nowPlaying: { r: 30, g: 215, b: 96 } as RGB,     // Spotify green
+
+  // Precipitation nowcast, light to heavy
+  rainLight: { r: 120, g: 200, b: 255 } as RGB,    // Light blue
+  rainModerate: { r: 0, g: 80, b: 255 } as RGB,    // Blue
+  rainHeavy: { r: 170, g: 0, b: 255 } as RGB,      // Purple
+
+  // Weather icons
+  weatherSun: { r: 255, g: 200, b: 0 } as RGB,
+  weatherCloud: { r: 190, g: 190, b: 200 } as RGB,
+  weatherCloudDark: { r: 90, g: 90, b: 105 } as RGB,  // Overcast and storm clouds
+  weatherSnow: { r: 235, g: 245, b: 255 } as RGB,
+  weatherBolt: { r: 255, g: 240, b: 60 } as RGB,
+  weatherFog: { r: 120, g: 120, b: 130 } as RGB,
+
+  // Glucose chart gridlines and their value labels
+  chartGrid: { r: 30, g: 30, b: 30 } as RGB,
+  chartGridLabel: { r: 70, g: 70, b: 70 } as RGB,
+
   // Background
   bg: { r: 0, g: 0, b: 0 } as RGB,
   separator: { r: 40, g: 40, b: 40 } as RGB,
@@ -0,0 +1,123 @@
+/**
+ * Tests for the compact 32x8 renderer
+ */
+
+import { describe, it, expect } from "vitest";
+import { getPixel, getPixelValue, type Frame } from "@signage/core";
+import { renderCompactFrame, COMPACT_WIDTH, COMPACT_HEIGHT } from "./compact-renderer.js";
+import type { BloodSugarDisplayData } from "./blood-sugar-renderer.js";
+import { COLORS } from "./colors.js";
+import { LAYOUTS } from "./layouts.js";
+import type { GlucoseAlert } from "../alerts/types.js";
+
+const now = Date.UTC(2026, 0, 1, 12, 0, 0);
+
+const reading: BloodSugarDisplayData = {
+  glucose: 123,
+  trend: "Flat",
+  delta: 4,
+  timestamp: now - 60_000,
+  rangeStatus: "normal",
+  isStale: false,
+};
+
+function litInRow(frame: Frame, y: number): number {
+  let lit = 0;
+  for (let x = 0; x < frame.width; x++) {
+    if (getPixelValue(frame, x, y) > 0) lit++;
+  }
+  return lit;
+}
+
+describe("renderCompactFrame", () => {
+  it("renders a 32x8 frame with the reading in its range color", () => {
+    const frame = renderCompactFrame({ bloodSugar: reading }, now);
+
+    expect(frame).toMatchObject({ width: COMPACT_WIDTH, height: COMPACT_HEIGHT });
+    // Middle row of the flat arrow
+    expect(getPixel(frame, 1, 3)).toEqual(COLORS.normal);
+    expect(litInRow(frame, 0)).toBe(0);
+  });
+
+  it("tightens, then drops, the delta when the reading leaves no room", () => {
+    // Arrow and "388" take columns 1-17
+    const tight = renderCompactFrame({ bloodSugar: { ...reading, glucose: 388, delta: -18 } }, now);
+    const dropped = renderCompactFrame({ bloodSugar: { ...reading, glucose: 388, delta: -100 } }, now);
+
+    const litFrom = (frame: Frame, x0: number) => {
+      let lit = 0;
+      for (let y = 1; y <= 5; y++) {
+        for (let x = x0; x < COMPACT_WIDTH; x++) {
+          if (getPixelValue(frame, x, y) > 0) lit++;
+        }
+      }
+      return lit;
+    };
+    expect(litFrom(tight, 18)).toBeGreaterThan(0);
+    expect(litFrom(dropped, 18)).toBe(0);
+  });
+
+  it("shows one history column per reading, newest on the right", () => {
+    const frame = renderCompactFrame(
+      {
+        bloodSugar: reading,
+        bloodSugarHistory: {
+          points: [
+            { timestamp: now - 60_000, glucose: 120 },
+            { timestamp: now - 10 * 60_000, glucose: 50 },
+            { timestamp: now - 4 * 60 * 60_000, glucose: 120 },
+          ],
+        },
+      },
+      now
+    );
+
+    expect(litInRow(frame, 7)).toBe(2);
+    expect(getPixel(frame, 31, 7)?.g).toBeGreaterThan(0);
+    expect(getPixel(frame, 29, 7)?.r).toBeGreaterThan(0);
+  });
+
+  it("lights the top row while an alert is active", () => {
+    const alert: GlucoseAlert = {
+      type: "high",
+      severity: "warning",
+      title: "HIGH",
+      detail: "",
+      raisedAt: now,
+    };
+
+    const frame = renderCompactFrame({ bloodSugar: reading, alerts: [alert] }, now);
+
+    expect(litInRow(frame, 0)).toBe(COMPACT_WIDTH);
+  });
+
+  it("follows the layout's brightness", () => {
+    const frame = renderCompactFrame({ bloodSugar: reading, layout: LAYOUTS.night }, now);
+
+    expect(getPixel(frame, 1, 3)?.g).toBeLessThan(COLORS.normal.g);
+  });
+
+  it("says why there's no reading", () => {
+    const colorsInRow = (frame: Frame, y: number) =>
+      Array.from({ length: COMPACT_WIDTH }, (_, x) => JSON.stringify(getPixel(frame, x, y)));
+
+    const noData = renderCompactFrame({ bloodSugar: null }, now);
+    const unreachable = renderCompactFrame({ bloodSugar: null, glucoseStatus: { dexcomUnreachable: true } }, now);
+    const noSignal = renderCompactFrame({ bloodSugar: { ...reading, timestamp: now - 45 * 60_000, isStale: true } }, now);
+
+    expect(colorsInRow(noData, 1)).toContain(JSON.stringify(COLORS.stale));
+    expect(colorsInRow(unreachable, 1)).toContain(JSON.stringify(COLORS.unreachable));
+    expect(colorsInRow(noSignal, 1)).toContain(JSON.stringify(COLORS.noSignal));
+  });
+
+  it("grays the history strip for a stale reading", () => {
+    const history = { points: [{ timestamp: now, glucose: 120 }] };
+
+    const live = renderCompactFrame({ bloodSugar: reading, bloodSugarHistory: history }, now);
+    const stale = renderCompactFrame({ bloodSugar: { ...reading, isStale: true }, bloodSugarHistory: history }, now);
+
+    expect(getPixel(live, COMPACT_WIDTH - 1, 7)?.g).toBeGreaterThan(getPixel(live, COMPACT_WIDTH - 1, 7)!.r);
+    const { r, g, b } = getPixel(stale, COMPACT_WIDTH - 1, 7)!;
+    expect(r === g && g === b && r > 0).toBe(true);
+  });
+});
@@ -0,0 +1,112 @@
+/**
+ * Compact layout for 32x8 clocks (Ulanzi TC001 / AWTRIX)
+ *
+ * A 64x64 frame shrunk to 32x8 is unreadable, so these panels get their
+ * own frame from the same data:
+ * ┌────────────────────────────────┐
+ * │ ▔▔▔▔▔▔▔▔ alert ▔▔▔▔▔▔▔▔▔▔▔▔▔▔▔ │  row 0    (lit while an alert is active)
+ * │ → 123 +4                       │  rows 1-5 (arrow, reading, delta)
+ * │                                │  row 6    (spacer)
+ * │ ▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪▪ │  row 7    (last 32 readings by range)
+ * └────────────────────────────────┘
+ *
+ * The clock has its own time and date apps, so there is no clock here.
+ * Without a live reading the text row says why, as on the full frame
+ * (see glucose-state.ts).
+ */
+
+import { createSolidFrame, setPixel, type Frame, type RGB } from "@signage/core";
+import { COLORS, getTrendTintedColor } from "./colors.js";
+import { drawText, measureText } from "./text.js";
+import { classifyRange, drawTrendArrow } from "./blood-sugar-renderer.js";
+import { isTrendComputable } from "../dexcom/client.js";
+import { applyBrightness, applyColorTemperature } from "./adjustments.js";
+import { getLayout } from "./layouts.js";
+import { isBlinkOn, resolveGlucoseState, type GlucoseRenderState } from "./glucose-state.js";
+import type { CompositorData } from "./frame-composer.js";
+
+export const COMPACT_WIDTH = 32;
+export const COMPACT_HEIGHT = 8;
+
+const TEXT_ROW = 1;
+const HISTORY_ROW = 7;
+const ARROW_WIDTH = 5;
+/** One history column per CGM reading */
+const READING_INTERVAL_MS = 5 * 60 * 1000;
+/** History strip brightness, so it doesn't compete with the reading */
+const HISTORY_DIM = 0.4;
+
+/** Text row for each state without a reading, short enough for 32 columns */
+const STATE_MESSAGES: Record<Exclude<GlucoseRenderState, "live" | "stale">, { text: string; color: RGB }> = {
+  noSignal: { text: "NO SIG", color: COLORS.noSignal },
+  noData: { text: "NO BG", color: COLORS.stale },
+  unreachable: { text: "NO DEX", color: COLORS.unreachable },
+  reconnecting: { text: "RECON", color: COLORS.reconnecting },
+};
+
+function dim(color: RGB, factor: number): RGB {
+  return {
+    r: Math.round(color.r * factor),
+    g: Math.round(color.g * factor),
+    b: Math.round(color.b * factor),
+  };
+}
+
+/**
+ * Render the compact frame for a 32x8 panel
+ */
+export function renderCompactFrame(data: CompositorData, now: number = Date.now()): Frame {
+  const frame = createSolidFrame(COMPACT_WIDTH, COMPACT_HEIGHT, COLORS.bg);
+  const maxY = TEXT_ROW + 4;
+
+  if (data.alerts && data.alerts.length > 0) {
+    for (let x = 0; x < COMPACT_WIDTH; x++) {
+      setPixel(frame, x, 0, COLORS.alertAccent);
+    }
+  }
+
+  const reading = data.bloodSugar;
+  const state = resolveGlucoseState(reading, data.glucoseStatus, now);
+  if (state !== "live" && state !== "stale") {
+    const { text, color } = STATE_MESSAGES[state];
+    if (state !== "reconnecting" || isBlinkOn(now)) {
+      drawText(frame, text, 1, TEXT_ROW, color, TEXT_ROW, maxY);
+    }
+  } else if (reading) {
+    const valueColor = reading.isStale ? COLORS.stale : COLORS[reading.rangeStatus];
+    const glucose = String(reading.glucose);
+    const showDelta = isTrendComputable(reading.trend);
+    const delta = reading.delta >= 0 ? `+${reading.delta}` : String(reading.delta);
+
+    // Drop the space before the delta, then the delta, when it doesn't fit
+    const textX = 1 + ARROW_WIDTH + 1;
+    const spaced = textX + measureText(`${glucose} `) + 1;
+    const tight = textX + measureText(glucose) + 1;
+    const deltaX = spaced + measureText(delta) <= COMPACT_WIDTH ? spaced : tight;
+
+    drawTrendArrow(frame, reading.trend, 1, TEXT_ROW, valueColor, TEXT_ROW, maxY);
+    drawText(frame, glucose, textX, TEXT_ROW, valueColor, TEXT_ROW, maxY);
+    if (showDelta && deltaX + measureText(delta) <= COMPACT_WIDTH) {
+      const deltaColor = getTrendTintedColor(valueColor, reading.trend);
+      drawText(frame, delta, deltaX, TEXT_ROW, deltaColor, TEXT_ROW, maxY);
+    }
+  }
+
+  // Newest reading in the rightmost column; gray unless the reading is live
+  for (const point of data.bloodSugarHistory?.points ?? []) {
+    const column = COMPACT_WIDTH - 1 - Math.floor((now - point.timestamp) / READING_INTERVAL_MS);
+    if (column < 0 || column >= COMPACT_WIDTH) continue;
+    const color = state === "live" ? COLORS[classifyRange(point.glucose)] : COLORS.stale;
+    setPixel(frame, column, HISTORY_ROW, dim(color, HISTORY_DIM));
+  }
+
+  const layout = data.layout ?? getLayout(undefined);
+  if (layout.colorTemperature !== undefined) {
+    applyColorTemperature(frame, layout.colorTemperature);
+  }
+  if (layout.brightness !== undefined) {
+    applyBrightness(frame, layout.brightness);
+  }
+
+  return frame;
+}
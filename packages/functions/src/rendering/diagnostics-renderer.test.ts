@@ -0,0 +1,65 @@
+/**
+ * Tests for device diagnostics renderer
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel, getPixelValue } from "@signage/core";
+import { renderDiagnosticsRegion, getSuccessRateColor, DIAGNOSTICS_ROW_HEIGHT } from "./diagnostics-renderer.js";
+import { COLORS } from "./colors.js";
+import type { DeviceSendStats } from "../devices/types.js";
+
+function device(deviceId: string, hourly: DeviceSendStats["hourly"]): DeviceSendStats {
+  return { deviceId, sent: 0, failed: 0, successRate: 0.95, avgLatencyMs: 85, hourly };
+}
+
+function countLit(frame: ReturnType<typeof createSolidFrame>, minY: number, maxY: number): number {
+  let lit = 0;
+  for (let y = minY; y <= maxY; y++) {
+    for (let x = 0; x < 64; x++) {
+      if (getPixelValue(frame, x, y) > 0) lit++;
+    }
+  }
+  return lit;
+}
+
+describe("getSuccessRateColor", () => {
+  it("grades success rates", () => {
+    expect(getSuccessRateColor(1)).toEqual(COLORS.normal);
+    expect(getSuccessRateColor(0.95)).toEqual(COLORS.high);
+    expect(getSuccessRateColor(0.5)).toEqual(COLORS.urgentLow);
+    expect(getSuccessRateColor(null)).toEqual(COLORS.separator);
+  });
+});
+
+describe("renderDiagnosticsRegion", () => {
+  it("draws one hourly bar per hour, colored by that hour's success", () => {
+    const hourly: DeviceSendStats["hourly"] = Array.from({ length: 24 }, () => null);
+    hourly[0] = { sent: 60, failed: 0 };
+    hourly[23] = { sent: 1, failed: 1 };
+
+    const frame = createSolidFrame(64, 64);
+    renderDiagnosticsRegion(frame, [device("pixoo", hourly)], 7, 63);
+
+    const barY = 7 + 1 + 6;
+    expect(getPixel(frame, 8, barY)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 30, barY)).toEqual(COLORS.separator);
+    expect(getPixel(frame, 55, barY + 1)).toEqual(COLORS.urgentLow);
+  });
+
+  it("stops at the bottom of the region", () => {
+    const hourly: DeviceSendStats["hourly"] = Array.from({ length: 24 }, () => ({ sent: 1, failed: 0 }));
+    const devices = Array.from({ length: 10 }, (_, i) => device(`d${i}`, hourly));
+
+    const frame = createSolidFrame(64, 64);
+    renderDiagnosticsRegion(frame, devices, 7, 7 + 2 * DIAGNOSTICS_ROW_HEIGHT);
+
+    expect(countLit(frame, 7 + 2 * DIAGNOSTICS_ROW_HEIGHT + 1, 63)).toBe(0);
+  });
+
+  it("shows a placeholder with no statistics", () => {
+    const frame = createSolidFrame(64, 64);
+    renderDiagnosticsRegion(frame, [], 7, 63);
+
+    expect(countLit(frame, 7, 20)).toBeGreaterThan(0);
+  });
+});
@@ -0,0 +1,86 @@
+/**
+ * Device diagnostics page
+ *
+ * One block per connected panel showing how reliably frames reached it
+ * over the past day:
+ * ┌───────────────────────────────────────┐
+ * │ PIXOO 98%                       85MS  │  name, success rate, mean latency
+ * │  ▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮            │  hourly success, oldest first
+ * └───────────────────────────────────────┘
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { fillRect } from "@signage/core";
+import { drawTinyText, measureTinyText, DISPLAY_WIDTH } from "./text.js";
+import { COLORS } from "./colors.js";
+import type { DeviceSendStats } from "../devices/types.js";
+
+/** Rows per device: text line, gap, bar row, gap */
+export const DIAGNOSTICS_ROW_HEIGHT = 9;
+
+/** Width of one hourly bar */
+const BAR_WIDTH = 2;
+
+/** Longest device name that fits beside the rate and latency */
+const MAX_NAME_LENGTH = 5;
+
+/**
+ * Color for a success rate: green when (nearly) every frame arrives,
+ * yellow for occasional drops, red for an unreliable link
+ */
+export function getSuccessRateColor(rate: number | null): RGB {
+  if (rate === null) return COLORS.separator;
+  if (rate >= 0.99) return COLORS.normal;
+  if (rate >= 0.9) return COLORS.high;
+  return COLORS.urgentLow;
+}
+
+function formatRate(rate: number | null): string {
+  return rate === null ? "--" : `${Math.floor(rate * 100)}%`;
+}
+
+/**
+ * Render the diagnostics page within [minY, maxY].
+ * Devices that don't fit are left off.
+ */
+export function renderDiagnosticsRegion(
+  frame: Frame,
+  stats: DeviceSendStats[],
+  minY: number,
+  maxY: number
+): void {
+  if (stats.length === 0) {
+    const text = "NO SEND DATA";
+    drawTinyText(frame, text, Math.floor((DISPLAY_WIDTH - measureTinyText(text)) / 2), minY + 2, COLORS.stale);
+    return;
+  }
+
+  let y = minY + 1;
+  for (const device of stats) {
+    if (y + 7 > maxY) break;
+
+    const name = device.deviceId.toUpperCase().slice(0, MAX_NAME_LENGTH);
+    drawTinyText(frame, name, 1, y, COLORS.clockTime);
+    drawTinyText(
+      frame,
+      formatRate(device.successRate),
+      1 + measureTinyText(name) + 4,
+      y,
+      getSuccessRateColor(device.successRate)
+    );
+
+    if (device.avgLatencyMs !== null) {
+      const latency = `${Math.min(device.avgLatencyMs, 9999)}MS`;
+      drawTinyText(frame, latency, DISPLAY_WIDTH - 1 - measureTinyText(latency), y, COLORS.clockSecondary);
+    }
+
+    // Hourly bars, centered
+    const barsX = Math.floor((DISPLAY_WIDTH - device.hourly.length * BAR_WIDTH) / 2);
+    device.hourly.forEach((hour, i) => {
+      const rate = hour && hour.sent + hour.failed > 0 ? hour.sent / (hour.sent + hour.failed) : null;
+      fillRect(frame, barsX + i * BAR_WIDTH, y + 6, BAR_WIDTH, 2, getSuccessRateColor(rate));
+    });
+
+    y += DIAGNOSTICS_ROW_HEIGHT;
+  }
+}
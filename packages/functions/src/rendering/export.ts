@@ -0,0 +1,244 @@
+/**
+ * Frame export to PNG and animated GIF
+ *
+ * Saves composed frames as images for sharing, debugging, and golden tests.
+ * Frames can be upscaled (nearest neighbor) so the 64x64 output is readable.
+ */
+
+import { writeFile } from "fs/promises";
+import { deflateSync } from "zlib";
+import type { Frame } from "@signage/core";
+
+export interface ExportOptions {
+  /** Integer upscale factor (default: 1) */
+  scale?: number;
+}
+
+export interface GifExportOptions extends ExportOptions {
+  /** Delay between frames (default: 500ms, rounded to 10ms) */
+  frameDelayMs?: number;
+  /** Times to play; 0 loops forever (default: 0) */
+  loopCount?: number;
+}
+
+const CRC_TABLE = Array.from({ length: 256 }, (_, n) => {
+  let c = n;
+  for (let k = 0; k < 8; k++) {
+    c = c & 1 ? 0xedb88320 ^ (c >>> 1) : c >>> 1;
+  }
+  return c >>> 0;
+});
+
+function crc32(data: Uint8Array): number {
+  let crc = 0xffffffff;
+  for (const byte of data) {
+    crc = CRC_TABLE[(crc ^ byte) & 0xff] ^ (crc >>> 8);
+  }
+  return (crc ^ 0xffffffff) >>> 0;
+}
+
+/**
+ * Upscale a frame by an integer factor (nearest neighbor).
+ */
+function upscaleFrame(frame: Frame, scale: number): Frame {
+  const factor = Math.max(1, Math.floor(scale));
+  if (factor === 1) return frame;
+
+  const width = frame.width * factor;
+  const height = frame.height * factor;
+  const pixels = new Uint8Array(width * height * 3);
+  for (let y = 0; y < height; y++) {
+    for (let x = 0; x < width; x++) {
+      const src = (Math.floor(y / factor) * frame.width + Math.floor(x / factor)) * 3;
+      pixels.set(frame.pixels.subarray(src, src + 3), (y * width + x) * 3);
+    }
+  }
+  return { width, height, pixels };
+}
+
+function pngChunk(type: string, data: Uint8Array): Buffer {
+  const header = Buffer.alloc(8);
+  header.writeUInt32BE(data.length, 0);
+  header.write(type, 4, "ascii");
+  const crc = Buffer.alloc(4);
+  crc.writeUInt32BE(crc32(Buffer.concat([header.subarray(4), data])));
+  return Buffer.concat([header, data, crc]);
+}
+
+/**
+ * Encode a frame as an RGB PNG.
+ */
+export function encodePng(frame: Frame, options: ExportOptions = {}): Buffer {
+  const image = upscaleFrame(frame, options.scale ?? 1);
+  const stride = image.width * 3;
+
+  // Each scanline is prefixed with filter type 0 (none)
+  const raw = Buffer.alloc((stride + 1) * image.height);
+  for (let y = 0; y < image.height; y++) {
+    raw.set(image.pixels.subarray(y * stride, (y + 1) * stride), y * (stride + 1) + 1);
+  }
+
+  const header = Buffer.alloc(13);
+  header.writeUInt32BE(image.width, 0);
+  header.writeUInt32BE(image.height, 4);
+  header[8] = 8; // bit depth
+  header[9] = 2; // truecolor
+
+  return Buffer.concat([
+    Buffer.from([0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a]),
+    pngChunk("IHDR", header),
+    pngChunk("IDAT", deflateSync(raw)),
+    pngChunk("IEND", new Uint8Array(0)),
+  ]);
+}
+
+/**
+ * Build a palette shared by all frames. Frames with 256 colors or fewer
+ * (the usual case for the display) are exact; otherwise colors are
+ * quantized to 3-3-2 bit RGB.
+ */
+function buildPalette(frames: Frame[]): { palette: number[]; indexOf: (r: number, g: number, b: number) => number } {
+  const colors = new Map<number, number>();
+  for (const frame of frames) {
+    for (let i = 0; i < frame.pixels.length && colors.size <= 256; i += 3) {
+      const key = (frame.pixels[i] << 16) | (frame.pixels[i + 1] << 8) | frame.pixels[i + 2];
+      if (!colors.has(key)) colors.set(key, colors.size);
+    }
+  }
+
+  if (colors.size <= 256) {
+    const palette = [...colors.keys()].flatMap((key) => [key >> 16, (key >> 8) & 0xff, key & 0xff]);
+    return { palette, indexOf: (r, g, b) => colors.get((r << 16) | (g << 8) | b) ?? 0 };
+  }
+
+  const palette = Array.from({ length: 256 }, (_, i) => [
+    Math.round(((i >> 5) * 255) / 7),
+    Math.round((((i >> 2) & 7) * 255) / 7),
+    Math.round(((i & 3) * 255) / 3),
+  ]).flat();
+  return {
+    palette,
+    indexOf: (r, g, b) => (Math.round((r * 7) / 255) << 5) | (Math.round((g * 7) / 255) << 2) | Math.round((b * 3) / 255),
+  };
+}
+
+/**
+ * Compress color indices with GIF's variable-width LZW.
+ */
+function encodeLzw(indices: Uint8Array, minCodeSize: number): Uint8Array {
+  const clearCode = 1 << minCodeSize;
+  const endCode = clearCode + 1;
+  const dictionary = new Map<number, number>();
+  const out: number[] = [];
+  let codeSize = minCodeSize + 1;
+  let nextCode = endCode + 1;
+  let bits = 0;
+  let bitCount = 0;
+
+  const emit = (code: number) => {
+    bits |= code << bitCount;
+    bitCount += codeSize;
+    while (bitCount >= 8) {
+      out.push(bits & 0xff);
+      bits >>>= 8;
+      bitCount -= 8;
+    }
+  };
+
+  emit(clearCode);
+  let prefix = indices[0];
+  for (let i = 1; i < indices.length; i++) {
+    const key = (prefix << 8) | indices[i];
+    const code = dictionary.get(key);
+    if (code !== undefined) {
+      prefix = code;
+      continue;
+    }
+
+    emit(prefix);
+    if (nextCode < 4096) {
+      dictionary.set(key, nextCode++);
+      if (nextCode > 1 << codeSize && codeSize < 12) codeSize++;
+    } else {
+      // Table full: start over
+      emit(clearCode);
+      dictionary.clear();
+      codeSize = minCodeSize + 1;
+      nextCode = endCode + 1;
+    }
+    prefix = indices[i];
+  }
+  emit(prefix);
+  emit(endCode);
+  if (bitCount > 0) out.push(bits & 0xff);
+
+  return Uint8Array.from(out);
+}
+
+/**
+ * Encode frames as an animated GIF. A single frame gives a still GIF.
+ */
+export function encodeGif(frames: Frame[], options: GifExportOptions = {}): Buffer {
+  if (frames.length === 0) {
+    throw new Error("encodeGif needs at least one frame");
+  }
+  const { scale = 1, frameDelayMs = 500, loopCount = 0 } = options;
+  const images = frames.map((frame) => upscaleFrame(frame, scale));
+  const { width, height } = images[0];
+  if (images.some((image) => image.width !== width || image.height !== height)) {
+    throw new Error("All frames must be the same size");
+  }
+
+  const { palette, indexOf } = buildPalette(images);
+  const sizeBits = Math.max(1, Math.ceil(Math.log2(palette.length / 3)));
+  const table = new Uint8Array(3 * (1 << sizeBits));
+  table.set(palette);
+  const minCodeSize = Math.max(2, sizeBits);
+  const delay = Math.round(frameDelayMs / 10);
+
+  const u16 = (value: number) => [value & 0xff, (value >> 8) & 0xff];
+  const parts: Uint8Array[] = [
+    Buffer.from("GIF89a", "ascii"),
+    Uint8Array.from([...u16(width), ...u16(height), 0xf0 | (sizeBits - 1), 0, 0]),
+    table,
+  ];
+
+  if (images.length > 1) {
+    // NETSCAPE2.0 application extension: loop count
+    parts.push(Uint8Array.from([0x21, 0xff, 0x0b, ...Buffer.from("NETSCAPE2.0", "ascii"), 0x03, 0x01, ...u16(loopCount), 0x00]));
+  }
+
+  for (const image of images) {
+    const indices = new Uint8Array(width * height);
+    for (let i = 0; i < indices.length; i++) {
+      indices[i] = indexOf(image.pixels[i * 3], image.pixels[i * 3 + 1], image.pixels[i * 3 + 2]);
+    }
+    const data = encodeLzw(indices, minCodeSize);
+
+    // Graphic control extension (delay), then the image descriptor
+    parts.push(Uint8Array.from([0x21, 0xf9, 0x04, 0x04, ...u16(delay), 0x00, 0x00]));
+    parts.push(Uint8Array.from([0x2c, 0, 0, 0, 0, ...u16(width), ...u16(height), 0x00, minCodeSize]));
+    for (let pos = 0; pos < data.length; pos += 255) {
+      const block = data.subarray(pos, pos + 255);
+      parts.push(Uint8Array.from([block.length]), block);
+    }
+    parts.push(Uint8Array.from([0x00]));
+  }
+
+  parts.push(Uint8Array.from([0x3b]));
+  return Buffer.concat(parts);
+}
+
+/**
+ * Save a frame as a PNG file.
+ */
+export async function exportPng(frame: Frame, path: string, options: ExportOptions = {}): Promise<void> {
+  await writeFile(path, encodePng(frame, options));
+}
+
+/**
+ * Save frames as an animated GIF file.
+ */
+export async function exportGif(frames: Frame[], path: string, options: GifExportOptions = {}): Promise<void> {
+  await writeFile(path, encodeGif(frames, options));
+}
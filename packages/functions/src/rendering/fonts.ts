@@ -0,0 +1,398 @@
+/**
+ * Bitmap fonts for the 64x64 display
+ *
+ * Glyphs are stored one number per row, most significant bit on the left.
+ * A glyph is the font's width unless it has an entry in `widths` (used for
+ * narrow glyphs like the large font's colon).
+ */
+
+/**
+ * A fixed-height bitmap font
+ */
+export interface Font {
+  name: FontName;
+  /** Default glyph width in pixels */
+  width: number;
+  /** Glyph height in pixels */
+  height: number;
+  /** Gap between glyphs in pixels */
+  spacing: number;
+  glyphs: Record<string, number[]>;
+  /** Per-glyph widths for glyphs narrower than `width` */
+  widths?: Record<string, number>;
+}
+
+export type FontName = "tiny" | "medium" | "large";
+
+/**
+ * Compact 3x5 font - the default for all text
+ */
+export const TINY_FONT: Font = {
+  name: "tiny",
+  width: 3,
+  height: 5,
+  spacing: 1,
+  glyphs: {
+    // Numbers
+    "0": [0b111, 0b101, 0b101, 0b101, 0b111],
+    "1": [0b010, 0b110, 0b010, 0b010, 0b111],
+    "2": [0b111, 0b001, 0b111, 0b100, 0b111],
+    "3": [0b111, 0b001, 0b111, 0b001, 0b111],
+    "4": [0b101, 0b101, 0b111, 0b001, 0b001],
+    "5": [0b111, 0b100, 0b111, 0b001, 0b111],
+    "6": [0b111, 0b100, 0b111, 0b101, 0b111],
+    "7": [0b111, 0b001, 0b001, 0b001, 0b001],
+    "8": [0b111, 0b101, 0b111, 0b101, 0b111],
+    "9": [0b111, 0b101, 0b111, 0b001, 0b111],
+    // Letters (uppercase)
+    "A": [0b010, 0b101, 0b111, 0b101, 0b101],
+    "B": [0b110, 0b101, 0b110, 0b101, 0b110],
+    "C": [0b011, 0b100, 0b100, 0b100, 0b011],
+    "D": [0b110, 0b101, 0b101, 0b101, 0b110],
+    "E": [0b111, 0b100, 0b110, 0b100, 0b111],
+    "F": [0b111, 0b100, 0b110, 0b100, 0b100],
+    "G": [0b011, 0b100, 0b101, 0b101, 0b011],
+    "H": [0b101, 0b101, 0b111, 0b101, 0b101],
+    "I": [0b111, 0b010, 0b010, 0b010, 0b111],
+    "J": [0b011, 0b001, 0b001, 0b101, 0b010],
+    "K": [0b101, 0b110, 0b100, 0b110, 0b101],
+    "L": [0b100, 0b100, 0b100, 0b100, 0b111],
+    "M": [0b101, 0b111, 0b101, 0b101, 0b101],
+    "N": [0b101, 0b111, 0b111, 0b101, 0b101],
+    "O": [0b010, 0b101, 0b101, 0b101, 0b010],
+    "P": [0b110, 0b101, 0b110, 0b100, 0b100],
+    "Q": [0b010, 0b101, 0b101, 0b111, 0b011],
+    "R": [0b110, 0b101, 0b110, 0b101, 0b101],
+    "S": [0b011, 0b100, 0b010, 0b001, 0b110],
+    "T": [0b111, 0b010, 0b010, 0b010, 0b010],
+    "U": [0b101, 0b101, 0b101, 0b101, 0b111],
+    "V": [0b101, 0b101, 0b101, 0b101, 0b010],
+    "W": [0b101, 0b101, 0b101, 0b111, 0b101],
+    "X": [0b101, 0b101, 0b010, 0b101, 0b101],
+    "Y": [0b101, 0b101, 0b010, 0b010, 0b010],
+    "Z": [0b111, 0b001, 0b010, 0b100, 0b111],
+    // Lowercase (same as uppercase; see TINY_MIXED_CASE_FONT for true lowercase)
+    "a": [0b010, 0b101, 0b111, 0b101, 0b101],
+    "b": [0b110, 0b101, 0b110, 0b101, 0b110],
+    "c": [0b011, 0b100, 0b100, 0b100, 0b011],
+    "d": [0b110, 0b101, 0b101, 0b101, 0b110],
+    "e": [0b111, 0b100, 0b110, 0b100, 0b111],
+    "f": [0b111, 0b100, 0b110, 0b100, 0b100],
+    "g": [0b011, 0b100, 0b101, 0b101, 0b011],
+    "h": [0b101, 0b101, 0b111, 0b101, 0b101],
+    "i": [0b111, 0b010, 0b010, 0b010, 0b111],
+    "j": [0b011, 0b001, 0b001, 0b101, 0b010],
+    "k": [0b101, 0b110, 0b100, 0b110, 0b101],
+    "l": [0b100, 0b100, 0b100, 0b100, 0b111],
+    "m": [0b101, 0b111, 0b101, 0b101, 0b101],
+    "n": [0b101, 0b111, 0b111, 0b101, 0b101],
+    "o": [0b010, 0b101, 0b101, 0b101, 0b010],
+    "p": [0b110, 0b101, 0b110, 0b100, 0b100],
+    "q": [0b010, 0b101, 0b101, 0b111, 0b011],
+    "r": [0b110, 0b101, 0b110, 0b101, 0b101],
+    "s": [0b011, 0b100, 0b010, 0b001, 0b110],
+    "t": [0b111, 0b010, 0b010, 0b010, 0b010],
+    "u": [0b101, 0b101, 0b101, 0b101, 0b111],
+    "v": [0b101, 0b101, 0b101, 0b101, 0b010],
+    "w": [0b101, 0b101, 0b101, 0b111, 0b101],
+    "x": [0b101, 0b101, 0b010, 0b101, 0b101],
+    "y": [0b101, 0b101, 0b010, 0b010, 0b010],
+    "z": [0b111, 0b001, 0b010, 0b100, 0b111],
+    // Symbols
+    " ": [0b000, 0b000, 0b000, 0b000, 0b000],
+    "/": [0b001, 0b001, 0b010, 0b100, 0b100],
+    "-": [0b000, 0b000, 0b111, 0b000, 0b000],
+    "+": [0b000, 0b010, 0b111, 0b010, 0b000],
+    "%": [0b101, 0b001, 0b010, 0b100, 0b101],
+    ":": [0b000, 0b010, 0b000, 0b010, 0b000],
+    ".": [0b0, 0b0, 0b0, 0b0, 0b1], // 1px wide so decimals like "7.8" stay compact
+    ",": [0b000, 0b000, 0b000, 0b010, 0b100],
+    "!": [0b010, 0b010, 0b010, 0b000, 0b010],
+    "?": [0b110, 0b001, 0b010, 0b000, 0b010],
+    "'": [0b010, 0b010, 0b000, 0b000, 0b000],
+    "°": [0b010, 0b101, 0b010, 0b000, 0b000],
+    "=": [0b000, 0b111, 0b000, 0b111, 0b000],
+    "<": [0b001, 0b010, 0b100, 0b010, 0b001],
+    "(": [0b010, 0b100, 0b100, 0b100, 0b010],
+    ")": [0b010, 0b001, 0b001, 0b001, 0b010],
+    "*": [0b000, 0b101, 0b010, 0b101, 0b000],
+    // Arrows for trend display
+    "→": [0b010, 0b001, 0b111, 0b001, 0b010], // Right arrow (stable)
+    "↑": [0b010, 0b111, 0b010, 0b010, 0b010], // Up arrow (rising)
+    "↓": [0b010, 0b010, 0b010, 0b111, 0b010], // Down arrow (falling)
+    "↗": [0b011, 0b001, 0b101, 0b010, 0b000], // Up-right arrow (rising slowly)
+    "↘": [0b000, 0b010, 0b101, 0b001, 0b011], // Down-right arrow (falling slowly)
+    "⇅": [0b010, 0b111, 0b000, 0b111, 0b010], // Up-down arrow (rate out of range)
+    ">": [0b100, 0b010, 0b001, 0b010, 0b100], // Greater than as arrow alternative
+    ";": [0b000, 0b010, 0b000, 0b010, 0b100],
+    "\"": [0b101, 0b101, 0b000, 0b000, 0b000],
+    "[": [0b110, 0b100, 0b100, 0b100, 0b110],
+    "]": [0b011, 0b001, 0b001, 0b001, 0b011],
+    "_": [0b000, 0b000, 0b000, 0b000, 0b111],
+    "#": [0b101, 0b111, 0b101, 0b111, 0b101],
+    "&": [0b010, 0b101, 0b010, 0b101, 0b011],
+    "|": [0b010, 0b010, 0b010, 0b010, 0b010],
+    "^": [0b010, 0b101, 0b000, 0b000, 0b000],
+  },
+  widths: { ".": 1 },
+};
+
+/**
+ * True lowercase for the 3x5 font: a 3-row x-height with ascenders, and
+ * descenders squeezed into the bottom row.
+ */
+const TINY_LOWERCASE_GLYPHS: Record<string, number[]> = {
+  "a": [0b000, 0b110, 0b011, 0b101, 0b111],
+  "b": [0b100, 0b100, 0b110, 0b101, 0b110],
+  "c": [0b000, 0b000, 0b011, 0b100, 0b011],
+  "d": [0b001, 0b001, 0b011, 0b101, 0b011],
+  "e": [0b000, 0b010, 0b111, 0b100, 0b011],
+  "f": [0b001, 0b010, 0b111, 0b010, 0b010],
+  "g": [0b000, 0b011, 0b101, 0b011, 0b110],
+  "h": [0b100, 0b100, 0b110, 0b101, 0b101],
+  "i": [0b010, 0b000, 0b010, 0b010, 0b010],
+  "j": [0b001, 0b000, 0b001, 0b101, 0b010],
+  "k": [0b100, 0b101, 0b110, 0b110, 0b101],
+  "l": [0b110, 0b010, 0b010, 0b010, 0b111],
+  "m": [0b000, 0b000, 0b111, 0b111, 0b101],
+  "n": [0b000, 0b000, 0b110, 0b101, 0b101],
+  "o": [0b000, 0b000, 0b010, 0b101, 0b010],
+  "p": [0b000, 0b110, 0b101, 0b110, 0b100],
+  "q": [0b000, 0b011, 0b101, 0b011, 0b001],
+  "r": [0b000, 0b000, 0b011, 0b100, 0b100],
+  "s": [0b000, 0b011, 0b110, 0b011, 0b110],
+  "t": [0b010, 0b111, 0b010, 0b010, 0b011],
+  "u": [0b000, 0b000, 0b101, 0b101, 0b011],
+  "v": [0b000, 0b000, 0b101, 0b101, 0b010],
+  "w": [0b000, 0b000, 0b101, 0b111, 0b111],
+  "x": [0b000, 0b000, 0b101, 0b010, 0b101],
+  "y": [0b000, 0b101, 0b101, 0b011, 0b110],
+  "z": [0b000, 0b111, 0b011, 0b110, 0b111],
+};
+
+/**
+ * The 3x5 font with true lowercase. Opt-in: most text on the display is
+ * written to be read in capitals.
+ */
+export const TINY_MIXED_CASE_FONT: Font = {
+  ...TINY_FONT,
+  glyphs: { ...TINY_FONT.glyphs, ...TINY_LOWERCASE_GLYPHS },
+};
+
+/**
+ * 5x7 font - readable from a distance, about 10 characters per line
+ */
+export const MEDIUM_FONT: Font = {
+  name: "medium",
+  width: 5,
+  height: 7,
+  spacing: 1,
+  glyphs: {
+    "A": [0b01110, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001],
+    "B": [0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110],
+    "C": [0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110],
+    "D": [0b11110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b11110],
+    "E": [0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111],
+    "F": [0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000],
+    "G": [0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01110],
+    "H": [0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001],
+    "I": [0b01110, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110],
+    "J": [0b00111, 0b00010, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100],
+    "K": [0b10001, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010, 0b10001],
+    "L": [0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b11111],
+    "M": [0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001],
+    "N": [0b10001, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001, 0b10001],
+    "O": [0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110],
+    "P": [0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000],
+    "Q": [0b01110, 0b10001, 0b10001, 0b10001, 0b10101, 0b10010, 0b01101],
+    "R": [0b11110, 0b10001, 0b10001, 0b11110, 0b10100, 0b10010, 0b10001],
+    "S": [0b01110, 0b10001, 0b10000, 0b01110, 0b00001, 0b10001, 0b01110],
+    "T": [0b11111, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100],
+    "U": [0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110],
+    "V": [0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100],
+    "W": [0b10001, 0b10001, 0b10001, 0b10101, 0b10101, 0b10101, 0b01010],
+    "X": [0b10001, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b10001],
+    "Y": [0b10001, 0b10001, 0b01010, 0b00100, 0b00100, 0b00100, 0b00100],
+    "Z": [0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b11111],
+    "a": [0b00000, 0b00000, 0b01110, 0b00001, 0b01111, 0b10001, 0b01111],
+    "b": [0b10000, 0b10000, 0b10110, 0b11001, 0b10001, 0b10001, 0b11110],
+    "c": [0b00000, 0b00000, 0b01110, 0b10000, 0b10000, 0b10001, 0b01110],
+    "d": [0b00001, 0b00001, 0b01101, 0b10011, 0b10001, 0b10001, 0b01111],
+    "e": [0b00000, 0b00000, 0b01110, 0b10001, 0b11111, 0b10000, 0b01110],
+    "f": [0b00110, 0b01001, 0b01000, 0b11100, 0b01000, 0b01000, 0b01000],
+    "g": [0b00000, 0b01111, 0b10001, 0b01111, 0b00001, 0b10001, 0b01110],
+    "h": [0b10000, 0b10000, 0b10110, 0b11001, 0b10001, 0b10001, 0b10001],
+    "i": [0b00100, 0b00000, 0b01100, 0b00100, 0b00100, 0b00100, 0b01110],
+    "j": [0b00010, 0b00000, 0b00110, 0b00010, 0b00010, 0b10010, 0b01100],
+    "k": [0b10000, 0b10000, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010],
+    "l": [0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110],
+    "m": [0b00000, 0b00000, 0b11010, 0b10101, 0b10101, 0b10001, 0b10001],
+    "n": [0b00000, 0b00000, 0b10110, 0b11001, 0b10001, 0b10001, 0b10001],
+    "o": [0b00000, 0b00000, 0b01110, 0b10001, 0b10001, 0b10001, 0b01110],
+    "p": [0b00000, 0b00000, 0b11110, 0b10001, 0b11110, 0b10000, 0b10000],
+    "q": [0b00000, 0b00000, 0b01101, 0b10011, 0b01111, 0b00001, 0b00001],
+    "r": [0b00000, 0b00000, 0b10110, 0b11001, 0b10000, 0b10000, 0b10000],
+    "s": [0b00000, 0b00000, 0b01110, 0b10000, 0b01110, 0b00001, 0b11110],
+    "t": [0b01000, 0b01000, 0b11100, 0b01000, 0b01000, 0b01001, 0b00110],
+    "u": [0b00000, 0b00000, 0b10001, 0b10001, 0b10001, 0b10011, 0b01101],
+    "v": [0b00000, 0b00000, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100],
+    "w": [0b00000, 0b00000, 0b10001, 0b10001, 0b10101, 0b10101, 0b01010],
+    "x": [0b00000, 0b00000, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001],
+    "y": [0b00000, 0b00000, 0b10001, 0b10001, 0b01111, 0b00001, 0b01110],
+    "z": [0b00000, 0b00000, 0b11111, 0b00010, 0b00100, 0b01000, 0b11111],
+    "0": [0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110],
+    "1": [0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110],
+    "2": [0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111],
+    "3": [0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110],
+    "4": [0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010],
+    "5": [0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110],
+    "6": [0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110],
+    "7": [0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000],
+    "8": [0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110],
+    "9": [0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100],
+    " ": [0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b00000],
+    "!": [0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00000, 0b00100],
+    "?": [0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b00000, 0b00100],
+    ".": [0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b00100],
+    ",": [0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b00100, 0b01000],
+    ":": [0b00000, 0b00100, 0b00000, 0b00000, 0b00000, 0b00100, 0b00000],
+    "-": [0b00000, 0b00000, 0b00000, 0b11111, 0b00000, 0b00000, 0b00000],
+    "+": [0b00000, 0b00100, 0b00100, 0b11111, 0b00100, 0b00100, 0b00000],
+    "'": [0b00100, 0b00100, 0b01000, 0b00000, 0b00000, 0b00000, 0b00000],
+    "^": [0b00100, 0b01010, 0b10001, 0b00000, 0b00000, 0b00000, 0b00000],
+    "/": [0b00001, 0b00010, 0b00010, 0b00100, 0b01000, 0b01000, 0b10000],
+    "\\": [0b10000, 0b01000, 0b01000, 0b00100, 0b00010, 0b00010, 0b00001],
+    // Extended: symbols, degree sign and trend arrows
+    "%": [0b11001, 0b11010, 0b00010, 0b00100, 0b01000, 0b01011, 0b10011],
+    "=": [0b00000, 0b00000, 0b11111, 0b00000, 0b11111, 0b00000, 0b00000],
+    "<": [0b00010, 0b00100, 0b01000, 0b10000, 0b01000, 0b00100, 0b00010],
+    ">": [0b01000, 0b00100, 0b00010, 0b00001, 0b00010, 0b00100, 0b01000],
+    "(": [0b00010, 0b00100, 0b01000, 0b01000, 0b01000, 0b00100, 0b00010],
+    ")": [0b01000, 0b00100, 0b00010, 0b00010, 0b00010, 0b00100, 0b01000],
+    "→": [0b00000, 0b00100, 0b00010, 0b11111, 0b00010, 0b00100, 0b00000],
+    "↑": [0b00100, 0b01110, 0b10101, 0b00100, 0b00100, 0b00100, 0b00100],
+    "↓": [0b00100, 0b00100, 0b00100, 0b00100, 0b10101, 0b01110, 0b00100],
+    "↗": [0b00000, 0b01111, 0b00011, 0b00101, 0b01001, 0b10000, 0b00000],
+    "↘": [0b00000, 0b10000, 0b01001, 0b00101, 0b00011, 0b01111, 0b00000],
+    "⇅": [0b00100, 0b01110, 0b10101, 0b00000, 0b10101, 0b01110, 0b00100],
+    "°": [0b01100, 0b10010, 0b10010, 0b01100, 0b00000, 0b00000, 0b00000],
+    "*": [0b00000, 0b10101, 0b01110, 0b11111, 0b01110, 0b10101, 0b00000],
+  },
+};
+
+/**
+ * 8x12 digits for a clock readable across the room.
+ * Digits and a few separators only; "12:45" is 38px wide.
+ */
+export const LARGE_DIGIT_FONT: Font = {
+  name: "large",
+  width: 8,
+  height: 12,
+  spacing: 1,
+  glyphs: {
+    "0": [
+      0b00111100, 0b01111110, 0b11000011, 0b11000011, 0b11000011, 0b11000011,
+      0b11000011, 0b11000011, 0b11000011, 0b11000011, 0b01111110, 0b00111100,
+    ],
+    "1": [
+      0b00011000, 0b00111000, 0b01111000, 0b00011000, 0b00011000, 0b00011000,
+      0b00011000, 0b00011000, 0b00011000, 0b00011000, 0b01111110, 0b01111110,
+    ],
+    "2": [
+      0b00111100, 0b01111110, 0b11000011, 0b00000011, 0b00000110, 0b00001100,
+      0b00011000, 0b00110000, 0b01100000, 0b11000000, 0b11111111, 0b11111111,
+    ],
+    "3": [
+      0b00111100, 0b01111110, 0b11000011, 0b00000011, 0b00000110, 0b00011100,
+      0b00011110, 0b00000011, 0b00000011, 0b11000011, 0b01111110, 0b00111100,
+    ],
+    "4": [
+      0b00000110, 0b00001110, 0b00011110, 0b00110110, 0b01100110, 0b11000110,
+      0b11111111, 0b11111111, 0b00000110, 0b00000110, 0b00000110, 0b00000110,
+    ],
+    "5": [
+      0b11111111, 0b11111111, 0b11000000, 0b11000000, 0b11111100, 0b11111110,
+      0b00000011, 0b00000011, 0b00000011, 0b11000011, 0b01111110, 0b00111100,
+    ],
+    "6": [
+      0b00111100, 0b01111110, 0b11000011, 0b11000000, 0b11000000, 0b11111100,
+      0b11111110, 0b11000011, 0b11000011, 0b11000011, 0b01111110, 0b00111100,
+    ],
+    "7": [
+      0b11111111, 0b11111111, 0b00000011, 0b00000110, 0b00000110, 0b00001100,
+      0b00001100, 0b00011000, 0b00011000, 0b00110000, 0b00110000, 0b00110000,
+    ],
+    "8": [
+      0b00111100, 0b01111110, 0b11000011, 0b11000011, 0b11000011, 0b01111110,
+      0b01111110, 0b11000011, 0b11000011, 0b11000011, 0b01111110, 0b00111100,
+    ],
+    "9": [
+      0b00111100, 0b01111110, 0b11000011, 0b11000011, 0b11000011, 0b01111111,
+      0b00111111, 0b00000011, 0b00000011, 0b11000011, 0b01111110, 0b00111100,
+    ],
+    "-": [
+      0b00000000, 0b00000000, 0b00000000, 0b00000000, 0b00000000, 0b01111110,
+      0b01111110, 0b00000000, 0b00000000, 0b00000000, 0b00000000, 0b00000000,
+    ],
+    ":": [0b00, 0b00, 0b00, 0b11, 0b11, 0b00, 0b00, 0b11, 0b11, 0b00, 0b00, 0b00],
+    ".": [0b00, 0b00, 0b00, 0b00, 0b00, 0b00, 0b00, 0b00, 0b00, 0b00, 0b11, 0b11],
+    "°": [0b0110, 0b1001, 0b1001, 0b0110, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000],
+    " ": [0b0000, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000, 0b0000],
+  },
+  widths: { ":": 2, ".": 2, "°": 4, " ": 4 },
+};
+
+export const FONTS: Record<FontName, Font> = {
+  tiny: TINY_FONT,
+  medium: MEDIUM_FONT,
+  large: LARGE_DIGIT_FONT,
+};
+
+/**
+ * Width of a single glyph in a font
+ */
+export function glyphWidth(font: Font, char: string): number {
+  return font.widths?.[char] ?? font.width;
+}
+
+/**
+ * Stand-ins tried in order when a font lacks a character, so text degrades
+ * to something readable ("↑" becomes "^") instead of a blank.
+ */
+export const GLYPH_FALLBACKS: Record<string, string[]> = {
+  "↑": ["^"],
+  "↓": ["v"],
+  "→": [">", "-"],
+  "↗": ["/"],
+  "↘": ["\\"],
+  "⇅": ["?"],
+  "°": ["o"],
+  "·": ["."],
+  "×": ["x"],
+  "–": ["-"],
+  "—": ["-"],
+  "−": ["-"],
+  "‘": ["'"],
+  "’": ["'"],
+};
+
+/**
+ * Find the glyph to draw for a character: the font's own glyph, then each
+ * fallback, then the other letter case. Returns the character actually used
+ * (for its width), or undefined if nothing matches.
+ */
+export function resolveGlyph(font: Font, char: string): { char: string; bitmap: number[] } | undefined {
+  const candidates = [char, ...(GLYPH_FALLBACKS[char] ?? []), char.toUpperCase(), char.toLowerCase()];
+  for (const candidate of candidates) {
+    const bitmap = font.glyphs[candidate];
+    if (bitmap) return { char: candidate, bitmap };
+  }
+  return undefined;
+}
+
+/**
+ * Bitmap for a character (with fallbacks), or undefined if the font can't draw it
+ */
+export function getGlyph(font: Font, char: string): number[] | undefined {
+  return resolveGlyph(font, char)?.bitmap;
+}
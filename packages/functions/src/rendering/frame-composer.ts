@@ -1,29 +1,29 @@
 /**
  * Frame composer - combines all widgets into a single frame
  *
- * Layout (64x64) - chart at bottom for visual priority:
+ * Default layout (64x64), by surface (see WIDGET_REGIONS):
  * ┌───────────────────────────────────────┐
- * │        SUN FEB 1  2:53                │  rows  1-5  (date/time)
- * │                                       │  row   6    (spacer)
- * │     4-HOUR GLUCOSE                    │  rows  7-11 (insight line 1)
- * │     ANALYSIS                          │  rows 13-17 (insight line 2)
- * │                                       │  row  19    (spacer)
- * │        16  13  12   4   2   4H        │  rows 20-26 (insulin totals)
- * │                                       │  row  27    (spacer)
- * │   → 281  +9  0M                       │  rows 28-32 (glucose reading)
- * │                                       │  row  33    (spacer)
- * │     [glucose sparkline chart]         │  rows 34-63 (30px - expanded!)
+ * │        SUN FEB 1  2:53                │  rows  0-6   clock
+ * │     4-HOUR GLUCOSE                    │  rows  7-17  insight, or a banner,
+ * │     ANALYSIS                          │              pomodoro or now playing
+ * │▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒▒│  row  18     rain bar (overlay)
+ * │   → 281  +9  0M                       │  rows 18-63  blood sugar reading
+ * │     [glucose sparkline chart]         │              and chart
  * └───────────────────────────────────────┘
  *
- * Note: Spacer rows are intentionally left blank to provide visual separation
- * between the main sections (time, insights, insulin, glucose reading, chart).
+ * Full-screen pages (diagnostics, ticker, network, system, split) take
+ * rows 7-63 under the clock instead.
+ *
+ * Each widget renders into its own surface (see WIDGET_REGIONS), which is then
+ * blitted onto the display. A widget can't draw over its neighbours, and a
+ * surface whose inputs haven't changed is reused instead of re-rendered.
  */
 
 import type { Frame } from "@signage/core";
-import { createSolidFrame } from "@signage/core";
+import { createSolidFrame, fillFrame, subFrame, blitFrame } from "@signage/core";
 import { DISPLAY_WIDTH, DISPLAY_HEIGHT } from "./text.js";
 import { COLORS } from "./colors.js";
-import { renderClockRegion, type ClockWeatherData } from "./clock-renderer.js";
+import { renderClockRegion, renderLargeClockRegion, type ClockWeatherData } from "./clock-renderer.js";
 import {
   renderBloodSugarRegion,
   type BloodSugarDisplayData,
@@ -31,14 +31,176 @@ import {
 } from "./blood-sugar-renderer.js";
 import type { TreatmentDisplayData } from "../glooko/types.js";
 import { renderInsightRegion, type InsightDisplayData } from "./insight-renderer.js";
+import { renderLastBolusStatus } from "./treatment-renderer.js";
+import { renderAlertBanner } from "./alert-renderer.js";
+import type { GlucoseAlert } from "../alerts/types.js";
+import type { Annotation } from "../annotations/types.js";
+import { renderDiagnosticsRegion } from "./diagnostics-renderer.js";
+import type { DeviceSendStats } from "../devices/types.js";
+import { renderPomodoroRegion } from "./pomodoro-renderer.js";
+import type { PomodoroStatus } from "../pomodoro/types.js";
+import { renderTickerRegion } from "./ticker-renderer.js";
+import type { TickerDisplayData } from "../ticker/types.js";
+import { renderNetworkRegion } from "./network-renderer.js";
+import type { NetworkDisplayData } from "../network/types.js";
+import { renderSystemRegion } from "./system-renderer.js";
+import type { SystemMetrics } from "../system/types.js";
+import { renderPrecipitationBar } from "./precipitation-renderer.js";
+import type { PrecipitationNowcast } from "../precipitation/types.js";
+import { renderNowPlayingRegion } from "./now-playing-renderer.js";
+import { MARQUEE_STEP_MS } from "./marquee.js";
+import type { NowPlaying } from "../spotify/types.js";
+import { renderNotificationBanner, renderTextBanner } from "./notification-renderer.js";
+import type { DisplayNotification } from "../notifications/types.js";
+import { renderSplitGlucoseRegion } from "./split-renderer.js";
+import type { PersonGlucoseData } from "../followers/types.js";
+import { getLayout, LAYERS, type LayerName, type LayoutDefinition, type LayoutWidget } from "./layouts.js";
+import { applyBrightness, applyColorTemperature } from "./adjustments.js";
+import type { LocaleName } from "./locales.js";
+import { renderBackground } from "./backgrounds.js";
+import { glucoseStateBlinks, isBlinkOn, resolveGlucoseState, type GlucoseSourceStatus } from "./glucose-state.js";
 
 export interface CompositorData {
   bloodSugar: BloodSugarDisplayData | null;
   bloodSugarHistory?: BloodSugarHistory;
+  /** Why there is no live reading, when there isn't (see glucose-state.ts) */
+  glucoseStatus?: GlucoseSourceStatus;
   timezone?: string;
   weather?: ClockWeatherData;
   treatments?: TreatmentDisplayData | null;
   insight?: InsightDisplayData | null;
+  /** Layout to render (default: "day" - all widgets) */
+  layout?: LayoutDefinition;
+  /** Active alerts, most important first (shown in every layout) */
+  alerts?: GlucoseAlert[];
+  /** Annotations marked on the glucose chart */
+  annotations?: Annotation[];
+  /** Per-device send statistics (diagnostics layout) */
+  deviceStats?: DeviceSendStats[];
+  /** Stock and crypto quotes (markets layout) */
+  tickers?: TickerDisplayData;
+  /** Round trips and downloads to the monitored host (network layout) */
+  network?: NetworkDisplayData;
+  /** CPU, memory and temperature of the host (system layout, local server only) */
+  system?: SystemMetrics | null;
+  /** Each person's reading and recent history (split layout) */
+  people?: PersonGlucoseData[];
+  /** Precipitation forecast for the next hour (day layout) */
+  precipitation?: PrecipitationNowcast | null;
+  /** Layers to leave out of the frame (default: none) */
+  hiddenLayers?: LayerName[];
+  /** Language of the clock's day and month names (default: en) */
+  locale?: LocaleName;
+  /** Time to render, in ms (default: now); seconds ticks are rendered ahead */
+  now?: number;
+  /** Show seconds on the clock, stepping this many at a time (default: H:MM only) */
+  clockSeconds?: number;
+  /** Show the moon's phase by the clock at night (default: off) */
+  moonPhase?: boolean;
+  /** Smooth the glucose chart's line with anti-aliasing (default: off) */
+  chartAntialias?: boolean;
+  /** Shade the area under the glucose chart's line (default: off) */
+  chartFill?: boolean;
+  /** Running pomodoro timer, over the insight rows or the whole display */
+  pomodoro?: PomodoroStatus | null;
+  /** What Spotify is playing; while it plays it takes the insight rows */
+  nowPlaying?: NowPlaying | null;
+  /** Pushed notification, over the insight rows while it shows */
+  notification?: DisplayNotification | null;
+  /** Filled-in text banner, over the insight rows unless a notification or pomodoro has them */
+  textBanner?: string | null;
+}
+
+/**
+ * Rectangle on the display, in display pixel coordinates
+ */
+export interface FrameRegion {
+  x: number;
+  y: number;
+  width: number;
+  height: number;
+}
+
+/** Everything rendered as its own surface: layout widgets, the pomodoro timer, now playing and the banners */
+export type SurfaceName =
+  | LayoutWidget
+  | "pomodoro"
+  | "pomodoroFull"
+  | "nowPlaying"
+  | "textBanner"
+  | "notification"
+  | "alert";
+
+/**
+ * Where each widget's surface is placed on the display, and on which layer.
+ * Layers are composited bottom to top (see LAYERS), surfaces within a layer
+ * in ascending z order. Black pixels are transparent, so a higher surface
+ * only covers what it actually draws. The background layer is the
+ * layout's background fill, not a surface.
+ */
+export const WIDGET_REGIONS: Record<SurfaceName, FrameRegion & { layer: LayerName; z: number }> = {
+  clock: { x: 0, y: 0, width: DISPLAY_WIDTH, height: 7, layer: "widgets", z: 0 },
+  // Large clock takes the clock and insight rows
+  largeClock: { x: 0, y: 0, width: DISPLAY_WIDTH, height: 18, layer: "widgets", z: 0 },
+  bloodSugar: { x: 0, y: 18, width: DISPLAY_WIDTH, height: DISPLAY_HEIGHT - 18, layer: "widgets", z: 1 },
+  // Diagnostics page takes everything below the clock
+  diagnostics: { x: 0, y: 7, width: DISPLAY_WIDTH, height: DISPLAY_HEIGHT - 7, layer: "widgets", z: 1 },
+  // Ticker, network and system pages likewise
+  ticker: { x: 0, y: 7, width: DISPLAY_WIDTH, height: DISPLAY_HEIGHT - 7, layer: "widgets", z: 1 },
+  network: { x: 0, y: 7, width: DISPLAY_WIDTH, height: DISPLAY_HEIGHT - 7, layer: "widgets", z: 1 },
+  system: { x: 0, y: 7, width: DISPLAY_WIDTH, height: DISPLAY_HEIGHT - 7, layer: "widgets", z: 1 },
+  split: { x: 0, y: 7, width: DISPLAY_WIDTH, height: DISPLAY_HEIGHT - 7, layer: "widgets", z: 1 },
+  // Insight text overlays the rows between clock and reading
+  insight: { x: 0, y: 7, width: DISPLAY_WIDTH, height: 11, layer: "overlays", z: 0 },
+  // Rain bar on the free row below the insight
+  precipitation: { x: 0, y: 18, width: DISPLAY_WIDTH, height: 1, layer: "overlays", z: 2 },
+  // Pomodoro timer takes the insight region, or the whole display
+  pomodoro: { x: 0, y: 7, width: DISPLAY_WIDTH, height: 11, layer: "overlays", z: 1 },
+  pomodoroFull: { x: 0, y: 0, width: DISPLAY_WIDTH, height: DISPLAY_HEIGHT, layer: "widgets", z: 2 },
+  // Now playing takes the insight region too
+  nowPlaying: { x: 0, y: 7, width: DISPLAY_WIDTH, height: 11, layer: "overlays", z: 1 },
+  // Text banner takes the insight region too
+  textBanner: { x: 0, y: 7, width: DISPLAY_WIDTH, height: 11, layer: "overlays", z: 1 },
+  // Notification banner covers the insight region and anything in it
+  notification: { x: 0, y: 7, width: DISPLAY_WIDTH, height: 11, layer: "overlays", z: 3 },
+  // Alert banner covers the insight region
+  alert: { x: 0, y: 7, width: DISPLAY_WIDTH, height: 11, layer: "alerts", z: 0 },
+};
+
+/**
+ * Compositing order of a surface: its layer, then its z within the layer
+ */
+function stackOrder(widget: SurfaceName): number {
+  const region = WIDGET_REGIONS[widget];
+  return LAYERS.indexOf(region.layer) * 100 + region.z;
+}
+
+/**
+ * A widget rendered in isolation before compositing
+ */
+interface WidgetSurfaceSpec {
+  widget: SurfaceName;
+  /** Inputs that determine the surface; unchanged key reuses the cached surface */
+  cacheKey: string;
+  /** Draws the widget using display coordinates */
+  render: (frame: Frame) => void;
+}
+
+/** Last rendered surface per widget (one entry each, so memory stays bounded) */
+const surfaceCache = new Map<SurfaceName, { key: string; surface: Frame }>();
+
+/**
+ * Display-sized frame widgets are drawn onto before their region is copied
+ * out. Reused (cleared before each widget) rather than allocated per widget,
+ * since rendering is synchronous and only the copy is kept.
+ */
+const scratch = createSolidFrame(DISPLAY_WIDTH, DISPLAY_HEIGHT, COLORS.bg);
+
+/**
+ * Clear cached widget surfaces (for tests)
+ */
+export function clearSurfaceCache(): void {
+  surfaceCache.clear();
 }
 
 /**
@@ -55,6 +217,31 @@ function safeRender(widgetName: string, renderFn: () => void): boolean {
   }
 }
 
+/**
+ * Render a widget into its own surface, reusing the cached one when the
+ * inputs are unchanged. Anything drawn outside the widget's region is dropped.
+ * Returns null if rendering failed.
+ */
+function renderSurface(spec: WidgetSurfaceSpec): Frame | null {
+  const cached = surfaceCache.get(spec.widget);
+  if (cached && cached.key === spec.cacheKey) {
+    return cached.surface;
+  }
+
+  // Renderers draw in display coordinates, so render onto the scratch
+  // display-sized frame and keep only the widget's region
+  fillFrame(scratch, COLORS.bg);
+  if (!safeRender(spec.widget, () => spec.render(scratch))) {
+    surfaceCache.delete(spec.widget);
+    return null;
+  }
+
+  const region = WIDGET_REGIONS[spec.widget];
+  const surface = subFrame(scratch, region.x, region.y, region.width, region.height);
+  surfaceCache.set(spec.widget, { key: spec.cacheKey, surface });
+  return surface;
+}
+
 /**
  * Generate the composite frame with all widgets
  * Uses graceful degradation - if one widget fails, others continue rendering
@@ -62,22 +249,280 @@ function safeRender(widgetName: string, renderFn: () => void): boolean {
 export function generateCompositeFrame(data: CompositorData): Frame {
   const frame = createSolidFrame(DISPLAY_WIDTH, DISPLAY_HEIGHT, COLORS.bg);
   const errors: string[] = [];
+  const layout = data.layout ?? getLayout(undefined);
+  const widgets = new Set(layout.widgets);
+  // A notification covers the insight rows (shrinking a large clock to fit
+  // above it)
+  if (data.notification) {
+    widgets.delete("insight");
+    if (widgets.delete("largeClock")) widgets.add("clock");
+  }
+  // A running pomodoro takes the insight rows (shrinking a large clock to
+  // fit above it), or the whole display
+  if (data.pomodoro?.display === "full") {
+    widgets.clear();
+  } else if (data.pomodoro) {
+    widgets.delete("insight");
+    if (widgets.delete("largeClock")) widgets.add("clock");
+  }
+  // The text banner takes them when neither of those has them (shrinking a
+  // large clock likewise)
+  const textBanner = !data.pomodoro && !data.notification ? (data.textBanner ?? null) : null;
+  if (textBanner) {
+    widgets.delete("insight");
+    if (widgets.delete("largeClock")) widgets.add("clock");
+  }
+  // Music that's playing takes the insight rows, unless a pomodoro, a
+  // notification or the text banner has them
+  let nowPlaying: NowPlaying | null = null;
+  if (!data.pomodoro && !data.notification && !textBanner && data.nowPlaying?.isPlaying && widgets.delete("insight")) {
+    nowPlaying = data.nowPlaying;
+  }
+  // Widgets only change with the displayed minute, so it bounds cache reuse.
+  // A seconds clock changes with every step instead.
+  const now = data.now ?? Date.now();
+  const minute = Math.floor(now / 60_000);
+  const clockStepMs = data.clockSeconds ? data.clockSeconds * 1000 : 60_000;
+  const clockTime = Math.floor(now / clockStepMs) * clockStepMs;
+  const clockTimeOptions = {
+    now: clockTime,
+    seconds: Boolean(data.clockSeconds),
+    format: layout.clockFormat,
+    moon: data.moonPhase,
+  };
+  const specs: WidgetSurfaceSpec[] = [];
+
+  // Clock (full width) - time and date
+  if (widgets.has("clock")) {
+    specs.push({
+      widget: "clock",
+      cacheKey: JSON.stringify([clockTimeOptions, data.timezone, data.weather, data.locale]),
+      render: (f) => renderClockRegion(f, data.timezone, data.weather, undefined, data.locale, clockTimeOptions),
+    });
+  }
+
+  // Large clock - time only, in 8x12 digits
+  if (widgets.has("largeClock")) {
+    specs.push({
+      widget: "largeClock",
+      cacheKey: JSON.stringify([clockTimeOptions, data.timezone]),
+      render: (f) => renderLargeClockRegion(f, data.timezone, undefined, clockTimeOptions),
+    });
+  }
+
+  // Insight overlay, or the last bolus status line when no insight is available
+  if (widgets.has("insight") && data.insight) {
+    const insight = data.insight;
+    specs.push({
+      widget: "insight",
+      cacheKey: JSON.stringify(insight),
+      render: (f) => renderInsightRegion(f, insight),
+    });
+  } else if (widgets.has("insight") && data.treatments) {
+    const treatments = data.treatments.treatments;
+    specs.push({
+      widget: "insight",
+      cacheKey: JSON.stringify([minute, treatments]),
+      // Single line, vertically centered in the two-line insight region
+      render: (f) => renderLastBolusStatus(f, treatments, WIDGET_REGIONS.insight.y + 3),
+    });
+  }
+
+  // Pomodoro timer in place of the insight, or over everything
+  if (data.pomodoro) {
+    const status = data.pomodoro;
+    const widget = status.display === "full" ? "pomodoroFull" : "pomodoro";
+    const region = WIDGET_REGIONS[widget];
+    specs.push({
+      widget,
+      cacheKey: JSON.stringify(status),
+      render: (f) => renderPomodoroRegion(f, status, region.y, region.y + region.height - 1),
+    });
+  }
+
+  // Now playing in place of the insight
+  if (nowPlaying) {
+    const track = nowPlaying;
+    const region = WIDGET_REGIONS.nowPlaying;
+    specs.push({
+      widget: "nowPlaying",
+      // Scrolling title and a running progress bar: changes with each marquee step
+      cacheKey: JSON.stringify([Math.floor(now / MARQUEE_STEP_MS), track]),
+      render: (f) => renderNowPlayingRegion(f, track, region.y, region.y + region.height - 1, now),
+    });
+  }
+
+  // Blood sugar in bottom region (with treatment chart and glucose chart)
+  if (widgets.has("bloodSugar")) {
+    // Blinking states change within the minute
+    const blink = glucoseStateBlinks(resolveGlucoseState(data.bloodSugar, data.glucoseStatus, now)) && isBlinkOn(now);
+    specs.push({
+      widget: "bloodSugar",
+      cacheKey: JSON.stringify([
+        minute,
+        blink,
+        data.timezone,
+        data.bloodSugar,
+        data.glucoseStatus,
+        data.bloodSugarHistory,
+        data.treatments,
+        data.annotations,
+        layout.chartTargetBand,
+        layout.chartProjectionMinutes,
+        layout.chartLineStyle,
+        data.chartAntialias,
+        data.chartFill,
+      ]),
+      render: (f) =>
+        renderBloodSugarRegion(
+          f,
+          data.bloodSugar,
+          data.bloodSugarHistory,
+          data.timezone,
+          data.treatments,
+          data.annotations,
+          {
+            targetBand: layout.chartTargetBand,
+            projectionMinutes: layout.chartProjectionMinutes,
+            lineStyle: layout.chartLineStyle,
+            antialias: data.chartAntialias,
+            fill: data.chartFill,
+          },
+          data.glucoseStatus
+        ),
+    });
+  }
+
+  // Device send statistics
+  if (widgets.has("diagnostics")) {
+    const stats = data.deviceStats ?? [];
+    const region = WIDGET_REGIONS.diagnostics;
+    specs.push({
+      widget: "diagnostics",
+      cacheKey: JSON.stringify(stats),
+      render: (f) => renderDiagnosticsRegion(f, stats, region.y, region.y + region.height - 1),
+    });
+  }
+
+  // Stock and crypto quotes
+  if (widgets.has("ticker")) {
+    const tickers = data.tickers ?? { quotes: [], sparkline: false };
+    const region = WIDGET_REGIONS.ticker;
+    specs.push({
+      widget: "ticker",
+      // Quotes grey out as they age, so the minute bounds reuse too
+      cacheKey: JSON.stringify([minute, tickers]),
+      render: (f) => renderTickerRegion(f, tickers, region.y, region.y + region.height - 1, now),
+    });
+  }
 
-  // Render clock (full width) - includes time, date, and weather band
-  if (!safeRender("clock", () => renderClockRegion(frame, data.timezone, data.weather))) {
-    errors.push("clock");
+  // Network monitor
+  if (widgets.has("network")) {
+    const network = data.network ?? { host: "", samples: [] };
+    const region = WIDGET_REGIONS.network;
+    specs.push({
+      widget: "network",
+      cacheKey: JSON.stringify(network),
+      render: (f) => renderNetworkRegion(f, network, region.y, region.y + region.height - 1),
+    });
   }
 
-  // Render insight overlay (replaces weather band area when insight available)
-  if (data.insight) {
-    if (!safeRender("insight", () => renderInsightRegion(frame, data.insight ?? null))) {
-      errors.push("insight");
+  // Rain in the next hour (draws nothing when it's dry)
+  if (widgets.has("precipitation") && data.precipitation) {
+    const nowcast = data.precipitation;
+    const region = WIDGET_REGIONS.precipitation;
+    specs.push({
+      widget: "precipitation",
+      // The bar starts at the current minute
+      cacheKey: JSON.stringify([minute, nowcast]),
+      render: (f) => renderPrecipitationBar(f, nowcast, region.y, minute * 60_000),
+    });
+  }
+
+  // Host system metrics
+  if (widgets.has("system")) {
+    const system = data.system ?? null;
+    const region = WIDGET_REGIONS.system;
+    specs.push({
+      widget: "system",
+      cacheKey: JSON.stringify(system),
+      render: (f) => renderSystemRegion(f, system, region.y, region.y + region.height - 1),
+    });
+  }
+
+  // People side by side
+  if (widgets.has("split")) {
+    const people = data.people ?? [];
+    const region = WIDGET_REGIONS.split;
+    specs.push({
+      widget: "split",
+      // Charts end at the current minute
+      cacheKey: JSON.stringify([minute, people]),
+      render: (f) => renderSplitGlucoseRegion(f, people, region.y, region.y + region.height - 1, minute * 60_000),
+    });
+  }
+
+  // Text banner (shown in every layout)
+  if (textBanner) {
+    specs.push({
+      widget: "textBanner",
+      cacheKey: textBanner,
+      render: (f) => renderTextBanner(f, textBanner, WIDGET_REGIONS.textBanner.y),
+    });
+  }
+
+  // Pushed notification (shown in every layout, under the alert banner)
+  if (data.notification) {
+    const notification = data.notification;
+    specs.push({
+      widget: "notification",
+      cacheKey: JSON.stringify([notification.text, notification.priority]),
+      render: (f) => renderNotificationBanner(f, notification, WIDGET_REGIONS.notification.y),
+    });
+  }
+
+  // Alert banner (not layout-dependent - alerts show in every layout)
+  if (data.alerts && data.alerts.length > 0) {
+    const alert = data.alerts[0];
+    specs.push({
+      widget: "alert",
+      cacheKey: JSON.stringify([alert.type, alert.title, alert.detail]),
+      render: (f) => renderAlertBanner(f, alert, WIDGET_REGIONS.alert.y),
+    });
+  }
+
+  const hidden = new Set(data.hiddenLayers ?? []);
+  const visibleSpecs = specs
+    .filter((spec) => !hidden.has(WIDGET_REGIONS[spec.widget].layer))
+    .sort((a, b) => stackOrder(a.widget) - stackOrder(b.widget));
+
+  // Background layer under everything; widget surfaces are transparent where black
+  const background = layout.background;
+  if (background && !hidden.has("background")) {
+    const accent = data.bloodSugar
+      ? data.bloodSugar.isStale
+        ? COLORS.stale
+        : COLORS[data.bloodSugar.rangeStatus]
+      : undefined;
+    safeRender("background", () => renderBackground(frame, background, { accent }));
+  }
+
+  for (const spec of visibleSpecs) {
+    const surface = renderSurface(spec);
+    if (!surface) {
+      errors.push(spec.widget);
+      continue;
     }
+    const region = WIDGET_REGIONS[spec.widget];
+    blitFrame(frame, surface, region.x, region.y, { transparentColor: COLORS.bg });
+  }
+
+  if (layout.colorTemperature !== undefined) {
+    applyColorTemperature(frame, layout.colorTemperature);
   }
 
-  // Render blood sugar in bottom region (with treatment chart and glucose chart)
-  if (!safeRender("bloodSugar", () => renderBloodSugarRegion(frame, data.bloodSugar, data.bloodSugarHistory, data.timezone, data.treatments))) {
-    errors.push("bloodSugar");
+  if (layout.brightness !== undefined) {
+    applyBrightness(frame, layout.brightness);
   }
 
   if (errors.length > 0) {
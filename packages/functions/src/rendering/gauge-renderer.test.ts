@@ -0,0 +1,56 @@
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel, getPixelValue } from "@signage/core";
+import { renderGauge } from "./gauge-renderer";
+import { COLORS } from "./colors";
+
+// 21x21 box centered on (10, 10), 1px ring at radius 8
+const config = { cx: 10, cy: 10, radius: 8, thickness: 1 };
+
+describe("renderGauge", () => {
+  it("fills clockwise from the start of the dial over the track", () => {
+    const frame = createSolidFrame(64, 64);
+    renderGauge(frame, 0.5, config);
+
+    // 270-degree dial from 7:30 to 4:30; half full reaches 12 o'clock
+    expect(getPixel(frame, 2, 10)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 10, 2)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 18, 10)).toEqual(COLORS.veryDim);
+    // The gap at the bottom
+    expect(getPixelValue(frame, 10, 18)).toBe(0);
+  });
+
+  it("shows just the track when empty, and clamps the fraction", () => {
+    const empty = createSolidFrame(64, 64);
+    renderGauge(empty, -1, config);
+    expect(getPixel(empty, 2, 10)).toEqual(COLORS.veryDim);
+    expect(getPixel(empty, 18, 10)).toEqual(COLORS.veryDim);
+
+    const full = createSolidFrame(64, 64);
+    renderGauge(full, 2, config);
+    expect(getPixel(full, 18, 10)).toEqual(COLORS.normal);
+  });
+
+  it("makes a closed ring starting at 12 o'clock with a 360 sweep", () => {
+    const frame = createSolidFrame(64, 64);
+    renderGauge(frame, 0.25, { ...config, sweep: 360 });
+
+    expect(getPixel(frame, 10, 2)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 18, 10)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 10, 18)).toEqual(COLORS.veryDim);
+    expect(getPixel(frame, 2, 10)).toEqual(COLORS.veryDim);
+  });
+
+  it("centers the label", () => {
+    const frame = createSolidFrame(64, 64);
+    renderGauge(frame, 1, { ...config, label: "72%", labelColor: COLORS.clockTime });
+
+    let lit = 0;
+    for (let px = 4; px <= 16; px++) {
+      for (let py = 8; py <= 12; py++) {
+        if (getPixelValue(frame, px, py) > 0) lit++;
+      }
+    }
+    expect(lit).toBeGreaterThan(0);
+    expect(getPixelValue(frame, 10, 5)).toBe(0);
+  });
+});
@@ -0,0 +1,70 @@
+/**
+ * Gauge renderer for percentage-style values
+ *
+ * A ring filled clockwise by how far along something is (battery, pomodoro
+ * progress, a step goal), over a dim track, with an optional label such as
+ * "72%" in the middle. By default the ring leaves a gap at the bottom like
+ * a dial (270 degrees); a sweep of 360 makes a closed ring that starts at
+ * 12 o'clock.
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { drawArc } from "@signage/core";
+import { COLORS } from "./colors.js";
+import { drawTinyText, measureTinyText } from "./text.js";
+
+/**
+ * Gauge configuration
+ */
+export interface GaugeConfig {
+  /** Center X; may be fractional (x.5 centers on an even width) */
+  cx: number;
+  /** Center Y; may be fractional */
+  cy: number;
+  /** Outer radius in pixels */
+  radius: number;
+  /** Ring depth in pixels (default: 2) */
+  thickness?: number;
+  /** Degrees of the ring in use, centered on 12 o'clock (default: 270) */
+  sweep?: number;
+  /** Filled part (default: COLORS.normal) */
+  color?: RGB;
+  /** Unfilled part (default: COLORS.veryDim) */
+  trackColor?: RGB;
+  /** Tiny text centered in the ring, e.g. "72%" (default: none) */
+  label?: string;
+  /** Label color (default: the fill color) */
+  labelColor?: RGB;
+}
+
+/** Default degrees of the ring in use: a dial with a gap at the bottom */
+export const DEFAULT_GAUGE_SWEEP = 270;
+
+/**
+ * Render a gauge filled to `fraction` (0-1, clamped). The track is drawn
+ * in full first, so an empty gauge still shows where it would fill.
+ */
+export function renderGauge(frame: Frame, fraction: number, config: GaugeConfig): void {
+  const {
+    cx,
+    cy,
+    radius,
+    thickness = 2,
+    color = COLORS.normal,
+    trackColor = COLORS.veryDim,
+    label,
+    labelColor = color,
+  } = config;
+  const sweep = Math.max(0, Math.min(360, config.sweep ?? DEFAULT_GAUGE_SWEEP));
+  const filled = Number.isFinite(fraction) ? Math.max(0, Math.min(1, fraction)) : 0;
+
+  // A full ring starts at 12 o'clock; a dial's gap is centered at the bottom
+  const start = sweep >= 360 ? 0 : -sweep / 2;
+  drawArc(frame, cx, cy, radius, start, start + sweep, trackColor, thickness);
+  drawArc(frame, cx, cy, radius, start, start + sweep * filled, color, thickness);
+
+  if (label) {
+    const labelX = Math.round(cx - (measureTinyText(label) - 1) / 2);
+    drawTinyText(frame, label, labelX, Math.round(cy - 2), labelColor);
+  }
+}
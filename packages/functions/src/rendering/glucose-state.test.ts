@@ -0,0 +1,63 @@
+import { describe, it, expect } from "vitest";
+import { formatGap, glucoseStateBlinks, isBlinkOn, NO_SIGNAL_AFTER_MS, resolveGlucoseState } from "./glucose-state";
+import type { BloodSugarDisplayData } from "./blood-sugar-renderer";
+
+const reading: BloodSugarDisplayData = {
+  glucose: 120,
+  trend: "Flat",
+  delta: 2,
+  timestamp: 0,
+  rangeStatus: "normal",
+  isStale: false,
+};
+
+describe("resolveGlucoseState", () => {
+  it("shows a reading as live or stale", () => {
+    expect(resolveGlucoseState(reading)).toBe("live");
+    expect(resolveGlucoseState({ ...reading, isStale: true }, { dexcomUnreachable: true })).toBe("stale");
+  });
+
+  it("shows a long gap in readings as no signal", () => {
+    const stale = { ...reading, isStale: true };
+
+    expect(resolveGlucoseState(stale, {}, NO_SIGNAL_AFTER_MS - 1)).toBe("stale");
+    expect(resolveGlucoseState(stale, {}, NO_SIGNAL_AFTER_MS)).toBe("noSignal");
+  });
+
+  it("blames an outage rather than the sensor when Dexcom is unreachable", () => {
+    expect(resolveGlucoseState({ ...reading, isStale: true }, { dexcomUnreachable: true }, NO_SIGNAL_AFTER_MS)).toBe("stale");
+  });
+
+  it("tells an outage from having no data yet", () => {
+    expect(resolveGlucoseState(null)).toBe("noData");
+    expect(resolveGlucoseState(null, { dexcomUnreachable: true })).toBe("unreachable");
+  });
+
+  it("puts a reconnecting link first", () => {
+    expect(resolveGlucoseState(reading, { deviceReconnecting: true })).toBe("reconnecting");
+    expect(resolveGlucoseState(null, { deviceReconnecting: true, dexcomUnreachable: true })).toBe("reconnecting");
+  });
+});
+
+describe("formatGap", () => {
+  it("shows minutes, then hours and minutes, then hours", () => {
+    expect(formatGap(42 * 60_000)).toBe("42m");
+    expect(formatGap(135 * 60_000)).toBe("2h15m");
+    expect(formatGap(65 * 60_000)).toBe("1h05m");
+    expect(formatGap(13 * 60 * 60_000 + 59 * 60_000)).toBe("13h");
+  });
+});
+
+describe("isBlinkOn", () => {
+  it("alternates each second", () => {
+    expect(isBlinkOn(10_000)).toBe(true);
+    expect(isBlinkOn(11_500)).toBe(false);
+    expect(isBlinkOn(12_999)).toBe(true);
+  });
+
+  it("only applies to stale and reconnecting", () => {
+    const states = ["live", "stale", "noSignal", "noData", "unreachable", "reconnecting"] as const;
+
+    expect(states.filter(glucoseStateBlinks)).toEqual(["stale", "reconnecting"]);
+  });
+});
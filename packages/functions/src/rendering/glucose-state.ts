@@ -0,0 +1,83 @@
+/**
+ * Glucose render states
+ *
+ * What the glucose widgets show, so a problem looks different from a
+ * number rather than leaving an old one up:
+ *
+ *   live          reading in its range color
+ *   stale         reading and chart in gray, the age ("23m") blinking
+ *   noSignal      "NO SIGNAL 42m" - Dexcom answers but the sensor has sent
+ *                 nothing for a while (sensor change, warm-up, signal loss)
+ *   noData        "NO DATA" - nothing has been read yet
+ *   unreachable   "NO DEXCOM" - Dexcom is failing and nothing is cached
+ *   reconnecting  "RECONNECT" blinking, chart in gray - the display's link
+ *                 is down and being retried
+ *
+ * Blinking follows the second, so the local server (one frame a second)
+ * blinks and the deployed compositor (one a minute) alternates per run.
+ */
+
+import type { BloodSugarDisplayData } from "./blood-sugar-renderer.js";
+
+export type GlucoseRenderState = "live" | "stale" | "noSignal" | "noData" | "unreachable" | "reconnecting";
+
+/**
+ * How old a stale reading gets before the gap is shown instead of it.
+ * Dexcom Share doesn't say why readings stopped, so a sensor change,
+ * warm-up and lost signal all look the same.
+ */
+export const NO_SIGNAL_AFTER_MS = 30 * 60 * 1000;
+
+/**
+ * Where the reading came from, beyond the reading itself
+ */
+export interface GlucoseSourceStatus {
+  /** Dexcom couldn't be reached this run (failing or backing off) */
+  dexcomUnreachable?: boolean;
+  /** The display's link is down and being retried */
+  deviceReconnecting?: boolean;
+}
+
+/**
+ * Pick the render state; a reconnecting link wins, since whatever else
+ * is drawn may not be what the display shows. A long gap only counts as
+ * no signal while Dexcom itself is answering.
+ */
+export function resolveGlucoseState(
+  reading: BloodSugarDisplayData | null,
+  status: GlucoseSourceStatus = {},
+  now: number = Date.now()
+): GlucoseRenderState {
+  if (status.deviceReconnecting) return "reconnecting";
+  if (reading) {
+    if (!reading.isStale) return "live";
+    const gapped = !status.dexcomUnreachable && now - reading.timestamp >= NO_SIGNAL_AFTER_MS;
+    return gapped ? "noSignal" : "stale";
+  }
+  return status.dexcomUnreachable ? "unreachable" : "noData";
+}
+
+/**
+ * Format a gap in readings: "42m", "2h15m", or whole hours past ten
+ */
+export function formatGap(ms: number): string {
+  const minutes = Math.max(0, Math.floor(ms / 60_000));
+  if (minutes < 60) return `${minutes}m`;
+  const hours = Math.floor(minutes / 60);
+  if (hours >= 10) return `${hours}h`;
+  return `${hours}h${String(minutes % 60).padStart(2, "0")}m`;
+}
+
+/**
+ * Whether blinking elements are lit at this time
+ */
+export function isBlinkOn(now: number = Date.now()): boolean {
+  return Math.floor(now / 1000) % 2 === 0;
+}
+
+/**
+ * Whether a state has blinking elements (and so changes within a minute)
+ */
+export function glucoseStateBlinks(state: GlucoseRenderState): boolean {
+  return state === "stale" || state === "reconnecting";
+}
@@ -0,0 +1,471 @@
+/**
+ * Image loading and drawing
+ *
+ * Decodes PNG and GIF files into RGBA images and draws them into frames,
+ * downscaled to fit. Used for icons, logos, and photos on the display.
+ *
+ * Decoding is self-contained (Node zlib only) so the Lambda bundle stays small:
+ * - PNG: all color types and bit depths, no interlacing
+ * - GIF: first frame only, including transparency and interlacing
+ */
+
+import { readFile } from "fs/promises";
+import { inflateSync } from "zlib";
+import type { Frame } from "@signage/core";
+import { blendPixel } from "@signage/core";
+
+/**
+ * Decoded image with 8-bit RGBA pixels
+ */
+export interface RgbaImage {
+  width: number;
+  height: number;
+  pixels: Uint8Array;
+}
+
+/** Downscaling method: nearest for pixel art and icons, box for photos */
+export type ScalingMethod = "nearest" | "box";
+
+export interface DrawImageOptions {
+  /** Maximum drawn size (default: the frame size) */
+  maxWidth?: number;
+  maxHeight?: number;
+  /** Downscaling method (default: box) */
+  scaling?: ScalingMethod;
+}
+
+const PNG_SIGNATURE = [0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a];
+
+/** Samples per pixel for each PNG color type */
+const PNG_CHANNELS: Record<number, number> = { 0: 1, 2: 3, 3: 1, 4: 2, 6: 4 };
+
+function paeth(a: number, b: number, c: number): number {
+  const p = a + b - c;
+  const pa = Math.abs(p - a);
+  const pb = Math.abs(p - b);
+  const pc = Math.abs(p - c);
+  if (pa <= pb && pa <= pc) return a;
+  return pb <= pc ? b : c;
+}
+
+/**
+ * Decode a PNG file into an RGBA image.
+ */
+export function decodePng(data: Uint8Array): RgbaImage {
+  const view = new DataView(data.buffer, data.byteOffset, data.byteLength);
+  let pos = PNG_SIGNATURE.length;
+
+  let width = 0;
+  let height = 0;
+  let bitDepth = 0;
+  let colorType = 0;
+  let palette: Uint8Array | null = null;
+  let transparency: Uint8Array | null = null;
+  const idat: Uint8Array[] = [];
+
+  while (pos + 8 <= data.length) {
+    const length = view.getUint32(pos);
+    const type = String.fromCharCode(...data.subarray(pos + 4, pos + 8));
+    const start = pos + 8;
+    const chunk = data.subarray(start, start + length);
+    pos = start + length + 4; // skip CRC
+
+    if (type === "IHDR") {
+      width = view.getUint32(start);
+      height = view.getUint32(start + 4);
+      bitDepth = chunk[8];
+      colorType = chunk[9];
+      if (chunk[12] !== 0) {
+        throw new Error("Interlaced PNG images are not supported");
+      }
+    } else if (type === "PLTE") {
+      palette = chunk;
+    } else if (type === "tRNS") {
+      transparency = chunk;
+    } else if (type === "IDAT") {
+      idat.push(chunk);
+    } else if (type === "IEND") {
+      break;
+    }
+  }
+
+  const channels = PNG_CHANNELS[colorType];
+  if (!width || !height || channels === undefined) {
+    throw new Error("Invalid PNG header");
+  }
+  if (colorType === 3 && !palette) {
+    throw new Error("PNG palette missing");
+  }
+
+  const raw = inflateSync(Buffer.concat(idat));
+  const bitsPerPixel = channels * bitDepth;
+  const bytesPerPixel = Math.max(1, bitsPerPixel >> 3);
+  const stride = Math.ceil((width * bitsPerPixel) / 8);
+  const maxSample = (1 << Math.min(bitDepth, 8)) - 1;
+
+  // Read a raw sample; 16-bit samples keep their high byte
+  const readSample = (line: Uint8Array, index: number): number => {
+    if (bitDepth === 8) return line[index];
+    if (bitDepth === 16) return line[index * 2];
+    const bit = index * bitDepth;
+    return (line[bit >> 3] >> (8 - bitDepth - (bit & 7))) & maxSample;
+  };
+  const scale = (sample: number): number =>
+    bitDepth < 8 ? Math.round((sample * 255) / maxSample) : sample;
+
+  // tRNS for gray/truecolor holds 16-bit sample values
+  const transparentKey = transparency && colorType !== 3
+    ? Array.from({ length: transparency.length / 2 }, (_, i) =>
+        bitDepth === 16 ? transparency![i * 2] : transparency![i * 2 + 1]
+      )
+    : null;
+
+  const pixels = new Uint8Array(width * height * 4);
+  let previous = new Uint8Array(stride);
+
+  for (let y = 0; y < height; y++) {
+    const offset = y * (stride + 1);
+    const filter = raw[offset];
+    const line = new Uint8Array(raw.subarray(offset + 1, offset + 1 + stride));
+
+    for (let i = 0; i < stride; i++) {
+      const left = i >= bytesPerPixel ? line[i - bytesPerPixel] : 0;
+      const up = previous[i];
+      const upLeft = i >= bytesPerPixel ? previous[i - bytesPerPixel] : 0;
+      switch (filter) {
+        case 1: line[i] = (line[i] + left) & 0xff; break;
+        case 2: line[i] = (line[i] + up) & 0xff; break;
+        case 3: line[i] = (line[i] + ((left + up) >> 1)) & 0xff; break;
+        case 4: line[i] = (line[i] + paeth(left, up, upLeft)) & 0xff; break;
+      }
+    }
+
+    for (let x = 0; x < width; x++) {
+      const samples = Array.from({ length: channels }, (_, c) => readSample(line, x * channels + c));
+      const out = (y * width + x) * 4;
+      let r: number, g: number, b: number;
+      let a = 255;
+
+      if (colorType === 3) {
+        const index = samples[0];
+        r = palette![index * 3];
+        g = palette![index * 3 + 1];
+        b = palette![index * 3 + 2];
+        if (transparency && index < transparency.length) a = transparency[index];
+      } else if (colorType === 0 || colorType === 4) {
+        r = g = b = scale(samples[0]);
+        if (colorType === 4) a = samples[1];
+      } else {
+        [r, g, b] = samples;
+        if (colorType === 6) a = samples[3];
+      }
+
+      if (transparentKey && transparentKey.every((v, i) => v === samples[i])) {
+        a = 0;
+      }
+
+      pixels[out] = r;
+      pixels[out + 1] = g;
+      pixels[out + 2] = b;
+      pixels[out + 3] = a;
+    }
+
+    previous = line;
+  }
+
+  return { width, height, pixels };
+}
+
+/**
+ * Decode GIF LZW image data into color indices.
+ */
+function decodeLzw(minCodeSize: number, data: Uint8Array, pixelCount: number): Uint8Array {
+  const clearCode = 1 << minCodeSize;
+  const endCode = clearCode + 1;
+  const prefix = new Int32Array(4096);
+  const suffix = new Uint8Array(4096);
+  const firstChar = new Uint8Array(4096);
+  const stack = new Uint8Array(4097);
+  for (let i = 0; i < clearCode; i++) firstChar[i] = i;
+
+  const out = new Uint8Array(pixelCount);
+  let outPos = 0;
+  let codeSize = minCodeSize + 1;
+  let nextCode = endCode + 1;
+  let prev = -1;
+  let bits = 0;
+  let bitCount = 0;
+  let pos = 0;
+
+  while (outPos < pixelCount) {
+    while (bitCount < codeSize && pos < data.length) {
+      bits |= data[pos++] << bitCount;
+      bitCount += 8;
+    }
+    if (bitCount < codeSize) break;
+
+    const code = bits & ((1 << codeSize) - 1);
+    bits >>>= codeSize;
+    bitCount -= codeSize;
+
+    if (code === clearCode) {
+      codeSize = minCodeSize + 1;
+      nextCode = endCode + 1;
+      prev = -1;
+      continue;
+    }
+    if (code === endCode) break;
+
+    if (prev === -1) {
+      out[outPos++] = code;
+      prev = code;
+      continue;
+    }
+
+    // Walk the chain back to its first character; the KwKwK case repeats prev
+    let sp = 0;
+    let current = code;
+    if (code >= nextCode) {
+      stack[sp++] = firstChar[prev];
+      current = prev;
+    }
+    while (current > endCode) {
+      stack[sp++] = suffix[current];
+      current = prefix[current];
+    }
+    stack[sp++] = current;
+    const first = current;
+
+    while (sp > 0 && outPos < pixelCount) {
+      out[outPos++] = stack[--sp];
+    }
+
+    if (nextCode < 4096) {
+      prefix[nextCode] = prev;
+      suffix[nextCode] = first;
+      firstChar[nextCode] = firstChar[prev];
+      nextCode++;
+      if (nextCode === 1 << codeSize && codeSize < 12) codeSize++;
+    }
+    prev = code;
+  }
+
+  return out;
+}
+
+/** Row order of an interlaced GIF: every 8th from 0, every 8th from 4, every 4th from 2, every 2nd from 1 */
+function interlacedRows(height: number): number[] {
+  const rows: number[] = [];
+  for (const [start, step] of [[0, 8], [4, 8], [2, 4], [1, 2]]) {
+    for (let y = start; y < height; y += step) rows.push(y);
+  }
+  return rows;
+}
+
+/**
+ * Decode the first frame of a GIF file into an RGBA image.
+ */
+export function decodeGif(data: Uint8Array): RgbaImage {
+  const u16 = (at: number) => data[at] | (data[at + 1] << 8);
+  const width = u16(6);
+  const height = u16(8);
+  const screenFlags = data[10];
+  let pos = 13;
+
+  let globalPalette: Uint8Array | null = null;
+  if (screenFlags & 0x80) {
+    const size = 3 * (1 << ((screenFlags & 7) + 1));
+    globalPalette = data.subarray(pos, pos + size);
+    pos += size;
+  }
+
+  const readSubBlocks = (): Uint8Array => {
+    const parts: Uint8Array[] = [];
+    while (pos < data.length && data[pos] !== 0) {
+      const length = data[pos];
+      parts.push(data.subarray(pos + 1, pos + 1 + length));
+      pos += length + 1;
+    }
+    pos++;
+    return Buffer.concat(parts);
+  };
+
+  let transparentIndex = -1;
+  const pixels = new Uint8Array(width * height * 4);
+
+  while (pos < data.length) {
+    const block = data[pos++];
+
+    if (block === 0x21) {
+      const label = data[pos++];
+      const body = readSubBlocks();
+      // Graphic control extension: flags, delay (2), transparent index
+      if (label === 0xf9 && body.length >= 4 && body[0] & 1) {
+        transparentIndex = body[3];
+      }
+    } else if (block === 0x2c) {
+      const left = u16(pos);
+      const top = u16(pos + 2);
+      const frameWidth = u16(pos + 4);
+      const frameHeight = u16(pos + 6);
+      const flags = data[pos + 8];
+      pos += 9;
+
+      let palette = globalPalette;
+      if (flags & 0x80) {
+        const size = 3 * (1 << ((flags & 7) + 1));
+        palette = data.subarray(pos, pos + size);
+        pos += size;
+      }
+      if (!palette) {
+        throw new Error("GIF has no color table");
+      }
+
+      const minCodeSize = data[pos++];
+      const indices = decodeLzw(minCodeSize, readSubBlocks(), frameWidth * frameHeight);
+      const rows = flags & 0x40 ? interlacedRows(frameHeight) : null;
+
+      for (let row = 0; row < frameHeight; row++) {
+        const y = top + (rows ? rows[row] : row);
+        for (let col = 0; col < frameWidth; col++) {
+          const x = left + col;
+          const index = indices[row * frameWidth + col];
+          if (x >= width || y >= height || index === transparentIndex) continue;
+          const out = (y * width + x) * 4;
+          pixels[out] = palette[index * 3];
+          pixels[out + 1] = palette[index * 3 + 1];
+          pixels[out + 2] = palette[index * 3 + 2];
+          pixels[out + 3] = 255;
+        }
+      }
+      return { width, height, pixels };
+    } else {
+      break;
+    }
+  }
+
+  throw new Error("GIF contains no image");
+}
+
+/**
+ * Decode a PNG or GIF from its bytes.
+ */
+export function decodeImage(data: Uint8Array): RgbaImage {
+  if (PNG_SIGNATURE.every((byte, i) => data[i] === byte)) {
+    return decodePng(data);
+  }
+  if (String.fromCharCode(...data.subarray(0, 4)) === "GIF8") {
+    return decodeGif(data);
+  }
+  throw new Error("Unsupported image format (expected PNG or GIF)");
+}
+
+/**
+ * Load a PNG or GIF image from disk.
+ */
+export async function loadImage(path: string): Promise<RgbaImage> {
+  return decodeImage(await readFile(path));
+}
+
+/**
+ * Resize an image. Box scaling averages every source pixel under each
+ * destination pixel, weighting color by alpha so transparent edges don't
+ * darken.
+ */
+export function scaleImage(
+  image: RgbaImage,
+  width: number,
+  height: number,
+  method: ScalingMethod = "box"
+): RgbaImage {
+  const pixels = new Uint8Array(width * height * 4);
+
+  for (let y = 0; y < height; y++) {
+    const y0 = Math.floor((y * image.height) / height);
+    const y1 = Math.max(y0 + 1, Math.floor(((y + 1) * image.height) / height));
+
+    for (let x = 0; x < width; x++) {
+      const out = (y * width + x) * 4;
+
+      if (method === "nearest") {
+        const sx = Math.min(image.width - 1, Math.floor(((x + 0.5) * image.width) / width));
+        const sy = Math.min(image.height - 1, Math.floor(((y + 0.5) * image.height) / height));
+        pixels.set(image.pixels.subarray((sy * image.width + sx) * 4, (sy * image.width + sx) * 4 + 4), out);
+        continue;
+      }
+
+      const x0 = Math.floor((x * image.width) / width);
+      const x1 = Math.max(x0 + 1, Math.floor(((x + 1) * image.width) / width));
+      let r = 0, g = 0, b = 0, a = 0, count = 0;
+      for (let sy = y0; sy < y1; sy++) {
+        for (let sx = x0; sx < x1; sx++) {
+          const src = (sy * image.width + sx) * 4;
+          const alpha = image.pixels[src + 3];
+          r += image.pixels[src] * alpha;
+          g += image.pixels[src + 1] * alpha;
+          b += image.pixels[src + 2] * alpha;
+          a += alpha;
+          count++;
+        }
+      }
+      if (a > 0) {
+        pixels[out] = Math.round(r / a);
+        pixels[out + 1] = Math.round(g / a);
+        pixels[out + 2] = Math.round(b / a);
+      }
+      pixels[out + 3] = Math.round(a / count);
+    }
+  }
+
+  return { width, height, pixels };
+}
+
+/**
+ * Size that fits within the bounds, keeping the aspect ratio. Never upscales.
+ */
+export function fitImageSize(
+  width: number,
+  height: number,
+  maxWidth: number,
+  maxHeight: number
+): { width: number; height: number } {
+  const scale = Math.min(1, maxWidth / width, maxHeight / height);
+  return {
+    width: Math.max(1, Math.round(width * scale)),
+    height: Math.max(1, Math.round(height * scale)),
+  };
+}
+
+/**
+ * Draw an image into a frame at (x, y), downscaled to fit and alpha-blended.
+ * Pixels outside the frame are clipped.
+ */
+export function drawImage(
+  frame: Frame,
+  image: RgbaImage,
+  x: number,
+  y: number,
+  options: DrawImageOptions = {}
+): void {
+  const { maxWidth = frame.width, maxHeight = frame.height, scaling = "box" } = options;
+  const size = fitImageSize(image.width, image.height, maxWidth, maxHeight);
+  const scaled =
+    size.width === image.width && size.height === image.height
+      ? image
+      : scaleImage(image, size.width, size.height, scaling);
+
+  for (let iy = 0; iy < scaled.height; iy++) {
+    for (let ix = 0; ix < scaled.width; ix++) {
+      const src = (iy * scaled.width + ix) * 4;
+      const alpha = scaled.pixels[src + 3];
+      if (alpha === 0) continue;
+      blendPixel(
+        frame,
+        x + ix,
+        y + iy,
+        { r: scaled.pixels[src], g: scaled.pixels[src + 1], b: scaled.pixels[src + 2] },
+        alpha / 255
+      );
+    }
+  }
+}
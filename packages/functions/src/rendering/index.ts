@@ -5,15 +5,42 @@
 
 export * from "./frame-composer.js";
 export * from "./text.js";
+export * from "./fonts.js";
 export * from "./colors.js";
 export * from "./blood-sugar-renderer.js";
+export * from "./glucose-state.js";
 export * from "./clock-renderer.js";
+export * from "./clock-ticks.js";
 export * from "./chart-renderer.js";
+export * from "./bar-chart-renderer.js";
+export * from "./gauge-renderer.js";
 export * from "./ascii-renderer.js";
 export * from "./readiness-renderer.js";
 export * from "./treatment-renderer.js";
 export * from "./insight-renderer.js";
-export type { ClockWeatherData, ClockRegionBounds } from "./clock-renderer.js";
+export * from "./layouts.js";
+export * from "./locales.js";
+export * from "./zoned-time.js";
+export * from "./adjustments.js";
+export * from "./backgrounds.js";
+export * from "./alert-renderer.js";
+export * from "./annotation-renderer.js";
+export * from "./diagnostics-renderer.js";
+export * from "./pomodoro-renderer.js";
+export * from "./ticker-renderer.js";
+export * from "./network-renderer.js";
+export * from "./system-renderer.js";
+export * from "./split-renderer.js";
+export * from "./precipitation-renderer.js";
+export * from "./marquee.js";
+export * from "./now-playing-renderer.js";
+export * from "./notification-renderer.js";
+export * from "./compact-renderer.js";
+export * from "./image.js";
+export * from "./sprites.js";
+export * from "./weather-icons.js";
+export * from "./export.js";
+export type { ClockWeatherData, ClockRegionBounds, ClockTimeOptions } from "./clock-renderer.js";
 export type { ReadinessDisplayData } from "./readiness-renderer.js";
 export type { ChartBounds } from "./treatment-renderer.js";
 export type { InsightDisplayData } from "./insight-renderer.js";
@@ -0,0 +1,172 @@
+/**
+ * Tests for named layouts and schedule resolution
+ */
+
+import { describe, it, expect } from "vitest";
+import {
+  getLayout,
+  isLayoutName,
+  parseTimeOfDay,
+  findScheduledLayout,
+  resolveLayout,
+  rotationPage,
+  isPageTurn,
+  DEFAULT_LAYOUT_NAME,
+  type LayoutSelection,
+} from "./layouts.js";
+
+const TZ = "America/Los_Angeles";
+
+describe("getLayout", () => {
+  it("returns built-in layouts by name", () => {
+    expect(getLayout("night").name).toBe("night");
+    expect(getLayout("glucose-focus").widgets).toEqual(["bloodSugar"]);
+  });
+
+  it("falls back to the default layout for unknown names", () => {
+    expect(getLayout("nope").name).toBe(DEFAULT_LAYOUT_NAME);
+    expect(getLayout(undefined).name).toBe(DEFAULT_LAYOUT_NAME);
+  });
+});
+
+describe("isLayoutName", () => {
+  it("only accepts built-in layout names", () => {
+    expect(isLayoutName("day")).toBe(true);
+    expect(isLayoutName("toString")).toBe(false);
+  });
+});
+
+describe("parseTimeOfDay", () => {
+  it("parses HH:MM into minutes since midnight", () => {
+    expect(parseTimeOfDay("00:00")).toBe(0);
+    expect(parseTimeOfDay("7:30")).toBe(450);
+    expect(parseTimeOfDay("22:15")).toBe(1335);
+  });
+
+  it("rejects malformed values", () => {
+    expect(parseTimeOfDay("24:00")).toBeNull();
+    expect(parseTimeOfDay("12:60")).toBeNull();
+    expect(parseTimeOfDay("noon")).toBeNull();
+  });
+});
+
+describe("findScheduledLayout", () => {
+  const schedule = [
+    { start: "22:00", layout: "night" },
+    { start: "07:00", layout: "day" },
+  ];
+
+  it("picks the latest entry that started today", () => {
+    const now = new Date("2026-03-02T12:00:00-08:00").getTime();
+    const result = findScheduledLayout(schedule, now, TZ);
+    expect(result?.layout).toBe("day");
+    expect(result?.startedAt).toBe(new Date("2026-03-02T07:00:00-08:00").getTime());
+  });
+
+  it("wraps to yesterday's last entry before the first entry of the day", () => {
+    const now = new Date("2026-03-02T03:00:00-08:00").getTime();
+    const result = findScheduledLayout(schedule, now, TZ);
+    expect(result?.layout).toBe("night");
+    expect(result?.startedAt).toBe(new Date("2026-03-01T22:00:00-08:00").getTime());
+  });
+
+  it("reports the local start time across a daylight-saving change", () => {
+    // Night started at 22:00 PST; clocks sprang forward at 02:00
+    const now = new Date("2026-03-08T05:00:00-07:00").getTime();
+    const result = findScheduledLayout(schedule, now, TZ);
+    expect(result?.layout).toBe("night");
+    expect(result?.startedAt).toBe(new Date("2026-03-07T22:00:00-08:00").getTime());
+
+    const afterFallBack = findScheduledLayout(schedule, new Date("2026-11-01T12:00:00-08:00").getTime(), TZ);
+    expect(afterFallBack?.startedAt).toBe(new Date("2026-11-01T07:00:00-08:00").getTime());
+  });
+
+  it("never reports a start after now for an entry skipped by spring forward", () => {
+    const now = new Date("2026-03-08T03:10:00-07:00").getTime();
+    const result = findScheduledLayout([{ start: "02:30", layout: "night" }], now, TZ);
+    expect(result?.startedAt).toBe(now);
+  });
+
+  it("returns null when no entries are valid", () => {
+    expect(findScheduledLayout([{ start: "bad", layout: "day" }], Date.now(), TZ)).toBeNull();
+  });
+});
+
+describe("resolveLayout", () => {
+  const now = new Date("2026-03-02T23:00:00-08:00").getTime();
+
+  it("defaults to the day layout with no selection", () => {
+    expect(resolveLayout(null, now, TZ).name).toBe("day");
+  });
+
+  it("uses the manual selection without a schedule", () => {
+    const selection: LayoutSelection = { activeLayout: "glucose-focus" };
+    expect(resolveLayout(selection, now, TZ).name).toBe("glucose-focus");
+  });
+
+  it("applies the configured clock format to the layout it resolves", () => {
+    const selection: LayoutSelection = { activeLayout: "night", clockFormats: { night: "24h", day: "12h-ampm" } };
+
+    expect(resolveLayout(selection, now, TZ)).toMatchObject({ name: "night", clockFormat: "24h" });
+    expect(resolveLayout({ activeLayout: "night" }, now, TZ).clockFormat).toBe("12h-ampm");
+    const other = resolveLayout({ activeLayout: "diagnostics", clockFormats: { night: "24h" } }, now, TZ);
+    expect(other.clockFormat).toBeUndefined();
+  });
+
+  it("follows the schedule when the manual switch predates the current entry", () => {
+    const selection: LayoutSelection = {
+      activeLayout: "day",
+      activeLayoutSetAt: new Date("2026-03-02T09:00:00-08:00").getTime(),
+      layoutSchedule: [
+        { start: "07:00", layout: "day" },
+        { start: "22:00", layout: "night" },
+      ],
+    };
+    expect(resolveLayout(selection, now, TZ).name).toBe("night");
+  });
+
+  it("holds a manual switch made after the current entry started", () => {
+    const selection: LayoutSelection = {
+      activeLayout: "glucose-focus",
+      activeLayoutSetAt: new Date("2026-03-02T22:30:00-08:00").getTime(),
+      layoutSchedule: [
+        { start: "07:00", layout: "day" },
+        { start: "22:00", layout: "night" },
+      ],
+    };
+    expect(resolveLayout(selection, now, TZ).name).toBe("glucose-focus");
+  });
+});
+
+describe("page rotation", () => {
+  const startedAt = new Date("2026-03-02T12:00:00Z").getTime();
+  const minute = 60_000;
+  const rotation = { pages: ["glucose-focus", "day", "markets"], intervalMinutes: 2, startedAt };
+
+  it("shows each page for its interval, then wraps around", () => {
+    expect(rotationPage(rotation, startedAt)).toBe("glucose-focus");
+    expect(rotationPage(rotation, startedAt + 1 * minute)).toBe("glucose-focus");
+    expect(rotationPage(rotation, startedAt + 2 * minute)).toBe("day");
+    expect(rotationPage(rotation, startedAt + 5 * minute)).toBe("markets");
+    expect(rotationPage(rotation, startedAt + 6 * minute)).toBe("glucose-focus");
+  });
+
+  it("overrides the manual selection and the schedule, unless a page is pinned", () => {
+    const selection: LayoutSelection = {
+      activeLayout: "night",
+      activeLayoutSetAt: startedAt + 3 * minute,
+      layoutSchedule: [{ start: "07:00", layout: "night" }],
+      pageRotation: rotation,
+    };
+    expect(resolveLayout(selection, startedAt + 3 * minute).name).toBe("day");
+    expect(resolveLayout({ ...selection, pinnedPage: "markets" }, startedAt + 3 * minute).name).toBe("markets");
+  });
+
+  it("reports a turn only on the minute the page changes", () => {
+    const selection: LayoutSelection = { activeLayout: "day", pageRotation: rotation };
+    expect(isPageTurn(selection, startedAt + 2 * minute)).toBe(true);
+    expect(isPageTurn(selection, startedAt + 3 * minute)).toBe(false);
+    expect(isPageTurn({ ...selection, pinnedPage: "day" }, startedAt + 4 * minute)).toBe(false);
+    expect(isPageTurn({ activeLayout: "day" }, startedAt + 2 * minute)).toBe(false);
+  });
+});
@@ -0,0 +1,323 @@
+/**
+ * Named display layouts
+ *
+ * A layout decides which widget regions the frame composer renders and how
+ * bright the final frame is. Layouts are selected by name ("day", "night",
+ * "glucose-focus", "diagnostics", "markets", "network", "system", "split") either
+ * manually, from a time-of-day schedule, or as pages of a rotation.
+ */
+
+import type { TransitionType } from "@signage/core";
+import type { LineStyle, TargetBand } from "./chart-renderer.js";
+import type { BackgroundStyle } from "./backgrounds.js";
+import type { ClockFormat } from "./clock-renderer.js";
+import { DEFAULT_TIMEZONE, wallTime, zonedTimestamp } from "./zoned-time.js";
+
+/** Widget regions the frame composer knows how to render */
+export type LayoutWidget =
+  | "clock"
+  | "largeClock"
+  | "insight"
+  | "bloodSugar"
+  | "diagnostics"
+  | "ticker"
+  | "network"
+  | "system"
+  | "precipitation"
+  | "split";
+
+/**
+ * Compositing layers, bottom to top. Each surface belongs to one layer;
+ * any layer can be hidden (for debugging) without touching the others.
+ */
+export const LAYERS = ["background", "widgets", "overlays", "alerts"] as const;
+
+export type LayerName = (typeof LAYERS)[number];
+
+/**
+ * Check if a name refers to a compositing layer
+ */
+export function isLayerName(name: string): name is LayerName {
+  return (LAYERS as readonly string[]).includes(name);
+}
+
+/**
+ * A named layout definition
+ */
+export interface LayoutDefinition {
+  name: string;
+  /** Widget regions to render, in draw order */
+  widgets: LayoutWidget[];
+  /** Output brightness multiplier, 0-1 (default: 1) */
+  brightness?: number;
+  /** White point in Kelvin; below 6500 is warmer (default: 6500, neutral) */
+  colorTemperature?: number;
+  /** Target range band on the glucose chart (default: none) */
+  chartTargetBand?: TargetBand;
+  /** Minutes of projected trend after the latest reading on the glucose chart (default: none) */
+  chartProjectionMinutes?: number;
+  /** Thickness and dashes of the glucose chart's line (default: solid, 1px) */
+  chartLineStyle?: LineStyle;
+  /** Fill behind the widgets (default: black) */
+  background?: BackgroundStyle;
+  /** How the clock writes the time (default: each clock's own) */
+  clockFormat?: ClockFormat;
+}
+
+/**
+ * A scheduled layout change. The layout applies from `start` until the next
+ * entry's start (wrapping around midnight).
+ */
+export interface LayoutScheduleEntry {
+  /** Local start time in 24h "HH:MM" format */
+  start: string;
+  /** Layout name to activate */
+  layout: string;
+}
+
+/** Longest a rotation page can show, in minutes */
+export const MAX_PAGE_MINUTES = 60;
+
+/**
+ * Layouts shown in turn as full-screen pages
+ */
+export interface PageRotation {
+  /** Layout names, in the order they're shown */
+  pages: string[];
+  /** Minutes each page shows (the display updates once a minute) */
+  intervalMinutes: number;
+  /** When the first page went up (ms) */
+  startedAt: number;
+  /** Animation when the page turns (default: the display's transition) */
+  transition?: TransitionType | "none";
+}
+
+/**
+ * Persisted layout selection
+ */
+export interface LayoutSelection {
+  /** Manually selected layout name */
+  activeLayout: string;
+  /** When the manual selection was made (ms) - overrides the schedule until its next change */
+  activeLayoutSetAt?: number;
+  /** Optional time-of-day schedule */
+  layoutSchedule?: LayoutScheduleEntry[];
+  /** Clock format per layout name, over the layout's own */
+  clockFormats?: Record<string, ClockFormat>;
+  /** Pages to cycle through - overrides the manual selection and the schedule */
+  pageRotation?: PageRotation;
+  /** Rotation page to hold until unpinned */
+  pinnedPage?: string;
+}
+
+export const DEFAULT_LAYOUT_NAME = "day";
+
+/**
+ * Built-in layouts
+ */
+export const LAYOUTS: Record<string, LayoutDefinition> = {
+  // Everything: date/time, AI insight, rain in the next hour, insulin
+  // totals, glucose + chart
+  day: {
+    name: "day",
+    widgets: ["clock", "insight", "precipitation", "bloodSugar"],
+    chartProjectionMinutes: 30,
+  },
+  // Big time instead of the date line and wordy insight, dimmed and warmed
+  // for a dark bedroom
+  night: {
+    name: "night",
+    widgets: ["largeClock", "bloodSugar"],
+    brightness: 0.35,
+    colorTemperature: 2700,
+    chartProjectionMinutes: 30,
+    clockFormat: "12h-ampm",
+  },
+  // Glucose reading, insulin totals and chart only, with the target range
+  // marked, a 2px line to read from across the room, and a glow in the range
+  // color behind the reading
+  "glucose-focus": {
+    name: "glucose-focus",
+    widgets: ["bloodSugar"],
+    chartTargetBand: { style: "outline" },
+    chartProjectionMinutes: 30,
+    chartLineStyle: { thickness: 2 },
+    background: { type: "glow" },
+  },
+  // Per-panel frame delivery over the past day, for chasing WiFi dropouts
+  diagnostics: {
+    name: "diagnostics",
+    widgets: ["clock", "diagnostics"],
+  },
+  // Stock and crypto quotes below the clock (symbols set in the display config)
+  markets: {
+    name: "markets",
+    widgets: ["clock", "ticker"],
+  },
+  // Round trips to a host (and download speed) below the clock
+  network: {
+    name: "network",
+    widgets: ["clock", "network"],
+  },
+  // CPU, memory and temperature of the machine running the local server
+  system: {
+    name: "system",
+    widgets: ["clock", "system"],
+  },
+  // Two or three people's readings and charts side by side (the main
+  // Dexcom account and followers, see followers/people.ts)
+  split: {
+    name: "split",
+    widgets: ["clock", "split"],
+  },
+};
+
+/**
+ * Get a layout by name, falling back to the default layout for unknown names
+ */
+export function getLayout(name: string | undefined): LayoutDefinition {
+  return (name && LAYOUTS[name]) || LAYOUTS[DEFAULT_LAYOUT_NAME];
+}
+
+/**
+ * Check if a layout name refers to a built-in layout
+ */
+export function isLayoutName(name: string): boolean {
+  return Object.prototype.hasOwnProperty.call(LAYOUTS, name);
+}
+
+/**
+ * Parse a "HH:MM" string into minutes since midnight.
+ * Returns null for malformed values.
+ */
+export function parseTimeOfDay(value: string): number | null {
+  const match = /^(\d{1,2}):(\d{2})$/.exec(value);
+  if (!match) return null;
+  const hours = parseInt(match[1], 10);
+  const minutes = parseInt(match[2], 10);
+  if (hours > 23 || minutes > 59) return null;
+  return hours * 60 + minutes;
+}
+
+/**
+ * Get minutes since local midnight for a timestamp in a timezone
+ */
+export function minutesOfDay(timestamp: number, timezone: string): number {
+  const parts = new Intl.DateTimeFormat("en-US", {
+    timeZone: timezone,
+    hour: "2-digit",
+    minute: "2-digit",
+    hourCycle: "h23",
+  }).formatToParts(new Date(timestamp));
+  const hour = parseInt(parts.find((p) => p.type === "hour")?.value || "0", 10);
+  const minute = parseInt(parts.find((p) => p.type === "minute")?.value || "0", 10);
+  return hour * 60 + minute;
+}
+
+/**
+ * Find the schedule entry in effect at a time, and when it started.
+ * Returns null if the schedule has no valid entries.
+ */
+export function findScheduledLayout(
+  schedule: LayoutScheduleEntry[],
+  now: number,
+  timezone: string
+): { layout: string; startedAt: number } | null {
+  const entries = schedule
+    .map((entry) => ({ layout: entry.layout, start: parseTimeOfDay(entry.start) }))
+    .filter((entry): entry is { layout: string; start: number } => entry.start !== null)
+    .sort((a, b) => a.start - b.start);
+
+  if (entries.length === 0) return null;
+
+  const nowMinutes = minutesOfDay(now, timezone);
+
+  // Latest entry starting at or before now; before the first entry of the
+  // day, yesterday's last entry is still in effect
+  let current = entries[entries.length - 1];
+  for (const entry of entries) {
+    if (entry.start <= nowMinutes) {
+      current = entry;
+    }
+  }
+
+  // Resolve the start on the local calendar rather than counting minutes
+  // back from now, which is an hour off across a daylight-saving change
+  const today = wallTime(now, timezone);
+  const dayOffset = current.start > nowMinutes ? -1 : 0;
+  const startedAt = zonedTimestamp(
+    today.year,
+    today.month,
+    today.day + dayOffset,
+    Math.floor(current.start / 60),
+    current.start % 60,
+    timezone
+  );
+  // A start inside a spring-forward gap resolves past the gap; the entry
+  // is already in effect, so it can't have started later than now
+  return {
+    layout: current.layout,
+    startedAt: Math.min(startedAt, now),
+  };
+}
+
+/**
+ * Resolve which layout should be shown right now, with its configured
+ * clock format.
+ *
+ * Without a schedule the manual selection always wins. With a schedule,
+ * a manual switch holds until the next scheduled change, after which the
+ * schedule takes over again. A page rotation overrides both until removed.
+ */
+export function resolveLayout(
+  selection: LayoutSelection | null,
+  now: number = Date.now(),
+  timezone = DEFAULT_TIMEZONE
+): LayoutDefinition {
+  if (!selection) return getLayout(DEFAULT_LAYOUT_NAME);
+
+  const layout = getLayout(selectLayoutName(selection, now, timezone));
+  const clockFormat = selection.clockFormats?.[layout.name];
+  return clockFormat ? { ...layout, clockFormat } : layout;
+}
+
+/**
+ * Name of the layout the selection and schedule call for right now
+ */
+function selectLayoutName(selection: LayoutSelection, now: number, timezone: string): string {
+  if (selection.pageRotation?.pages.length) {
+    return selection.pinnedPage ?? rotationPage(selection.pageRotation, now);
+  }
+
+  const scheduled = selection.layoutSchedule?.length
+    ? findScheduledLayout(selection.layoutSchedule, now, timezone)
+    : null;
+
+  if (!scheduled) return selection.activeLayout;
+
+  const manualSetAt = selection.activeLayoutSetAt ?? 0;
+  if (manualSetAt >= scheduled.startedAt) {
+    return selection.activeLayout;
+  }
+
+  return scheduled.layout;
+}
+
+/**
+ * Page a rotation shows at a time, counting whole intervals since it started
+ */
+export function rotationPage(rotation: PageRotation, now: number): string {
+  const elapsed = Math.max(0, now - rotation.startedAt);
+  const turns = Math.floor(elapsed / (rotation.intervalMinutes * 60_000));
+  return rotation.pages[turns % rotation.pages.length];
+}
+
+/**
+ * Check if a rotation turned to another page since the previous minute's
+ * update (never while a page is pinned)
+ */
+export function isPageTurn(selection: LayoutSelection, now: number): boolean {
+  const rotation = selection.pageRotation;
+  if (!rotation?.pages.length || selection.pinnedPage) return false;
+  return rotationPage(rotation, now) !== rotationPage(rotation, now - 60_000);
+}
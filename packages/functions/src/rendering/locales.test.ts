@@ -0,0 +1,50 @@
+/**
+ * Tests for clock locale packs
+ */
+
+import { describe, it, expect } from "vitest";
+import { LOCALES, formatClockDate, isLocaleName } from "./locales.js";
+import { TINY_FONT } from "./fonts.js";
+import { measureText } from "./text.js";
+
+describe("locale packs", () => {
+  // Saturday, January 24
+  const saturday = new Date(2026, 0, 24, 14, 30);
+
+  it("formats month-first for English and day-first elsewhere", () => {
+    expect(formatClockDate(saturday)).toBe("SAT JAN 24");
+    expect(formatClockDate(saturday, "de")).toBe("SA 24 JAN");
+    expect(formatClockDate(saturday, "fr")).toBe("SAM 24 JAN");
+    expect(formatClockDate(saturday, "es")).toBe("SAB 24 ENE");
+    expect(formatClockDate(saturday, "nl")).toBe("ZA 24 JAN");
+    expect(formatClockDate(saturday, "en", false)).toBe("JAN 24");
+    expect(formatClockDate(saturday, "de", false)).toBe("24 JAN");
+  });
+
+  it("has every day and month, short enough for the date line", () => {
+    for (const [name, pack] of Object.entries(LOCALES)) {
+      expect(pack.days, name).toHaveLength(7);
+      expect(pack.months, name).toHaveLength(12);
+      for (const word of [...pack.days, ...pack.months]) {
+        expect(word.length, `${name} ${word}`).toBeLessThanOrEqual(3);
+        // Only glyphs the clock font has, so nothing falls back
+        for (const char of word) {
+          expect(TINY_FONT.glyphs[char], `${name} ${char}`).toBeDefined();
+        }
+      }
+    }
+  });
+
+  it("keeps the longest date line inside 64px", () => {
+    // Wednesday, December 30, 12:59
+    const longest = new Date(2026, 11, 30, 12, 59);
+    for (const name of Object.keys(LOCALES)) {
+      expect(measureText(`${formatClockDate(longest, name as keyof typeof LOCALES)} 12:59`), name).toBeLessThanOrEqual(64);
+    }
+  });
+
+  it("recognizes locale names", () => {
+    expect(isLocaleName("fr")).toBe(true);
+    expect(isLocaleName("toString")).toBe(false);
+  });
+});
@@ -0,0 +1,69 @@
+/**
+ * Locale packs for the clock's date line
+ *
+ * Abbreviations are uppercase ASCII (the pixel fonts have no accents) and at
+ * most three letters, so "SAT JAN 24 12:30" still fits 64px in any locale.
+ */
+
+/**
+ * Day and month names for one locale
+ */
+export interface LocalePack {
+  /** Sunday first, like Date.getDay() */
+  days: string[];
+  /** January first, like Date.getMonth() */
+  months: string[];
+  /** Write the day number before the month ("SA 24 JAN") */
+  dayFirst: boolean;
+}
+
+export type LocaleName = "en" | "de" | "fr" | "es" | "nl";
+
+export const LOCALES: Record<LocaleName, LocalePack> = {
+  en: {
+    days: ["SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"],
+    months: ["JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"],
+    dayFirst: false,
+  },
+  de: {
+    days: ["SO", "MO", "DI", "MI", "DO", "FR", "SA"],
+    months: ["JAN", "FEB", "MRZ", "APR", "MAI", "JUN", "JUL", "AUG", "SEP", "OKT", "NOV", "DEZ"],
+    dayFirst: true,
+  },
+  fr: {
+    days: ["DIM", "LUN", "MAR", "MER", "JEU", "VEN", "SAM"],
+    months: ["JAN", "FEV", "MAR", "AVR", "MAI", "JUN", "JUL", "AOU", "SEP", "OCT", "NOV", "DEC"],
+    dayFirst: true,
+  },
+  es: {
+    days: ["DOM", "LUN", "MAR", "MIE", "JUE", "VIE", "SAB"],
+    months: ["ENE", "FEB", "MAR", "ABR", "MAY", "JUN", "JUL", "AGO", "SEP", "OCT", "NOV", "DIC"],
+    dayFirst: true,
+  },
+  nl: {
+    days: ["ZO", "MA", "DI", "WO", "DO", "VR", "ZA"],
+    months: ["JAN", "FEB", "MRT", "APR", "MEI", "JUN", "JUL", "AUG", "SEP", "OKT", "NOV", "DEC"],
+    dayFirst: true,
+  },
+};
+
+export const DEFAULT_LOCALE: LocaleName = "en";
+
+/**
+ * Check if a name refers to a locale pack
+ */
+export function isLocaleName(name: string): name is LocaleName {
+  return Object.prototype.hasOwnProperty.call(LOCALES, name);
+}
+
+/**
+ * Format the clock's date line for a local date, e.g. "SAT JAN 24" or
+ * "SA 24 JAN" ("JAN 24" / "24 JAN" without the weekday)
+ */
+export function formatClockDate(localTime: Date, locale: LocaleName = DEFAULT_LOCALE, weekday = true): string {
+  const pack = LOCALES[locale] ?? LOCALES[DEFAULT_LOCALE];
+  const month = pack.months[localTime.getMonth()];
+  const date = localTime.getDate();
+  const monthDate = pack.dayFirst ? `${date} ${month}` : `${month} ${date}`;
+  return weekday ? `${pack.days[localTime.getDay()]} ${monthDate}` : monthDate;
+}
@@ -0,0 +1,69 @@
+/**
+ * Tests for marquee text
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixelValue, type Frame } from "@signage/core";
+import { drawMarqueeText, marqueeOffset, MARQUEE_PAUSE_MS, MARQUEE_STEP_MS } from "./marquee.js";
+import { COLORS } from "./colors.js";
+
+function columnsWithPixels(frame: Frame, y: number): number[] {
+  const columns: number[] = [];
+  for (let x = 0; x < 64; x++) {
+    for (let row = y; row < y + 5; row++) {
+      if (getPixelValue(frame, x, row) > 0) {
+        columns.push(x);
+        break;
+      }
+    }
+  }
+  return columns;
+}
+
+describe("marqueeOffset", () => {
+  it("doesn't scroll text that fits", () => {
+    expect(marqueeOffset(40, 62, 10_000)).toBe(0);
+  });
+
+  it("holds at the start, then scrolls a pixel per step", () => {
+    expect(marqueeOffset(100, 62, 0)).toBe(0);
+    expect(marqueeOffset(100, 62, MARQUEE_PAUSE_MS - 1)).toBe(0);
+    expect(marqueeOffset(100, 62, MARQUEE_PAUSE_MS + 3 * MARQUEE_STEP_MS)).toBe(3);
+  });
+
+  it("starts over after a full pass", () => {
+    const cycleMs = MARQUEE_PAUSE_MS + (100 + 12) * MARQUEE_STEP_MS;
+    expect(marqueeOffset(100, 62, cycleMs)).toBe(0);
+    expect(marqueeOffset(100, 62, cycleMs + MARQUEE_PAUSE_MS + MARQUEE_STEP_MS)).toBe(1);
+  });
+});
+
+describe("drawMarqueeText", () => {
+  const LONG = "A VERY LONG TRACK TITLE THAT CANNOT FIT";
+
+  it("draws text that fits where it's put", () => {
+    const frame = createSolidFrame(64, 64);
+    drawMarqueeText(frame, "HI", 10, 8, 20, COLORS.clockTime, 5000);
+
+    expect(columnsWithPixels(frame, 8)[0]).toBe(10);
+  });
+
+  it("keeps scrolled text inside its span", () => {
+    const frame = createSolidFrame(64, 64);
+    drawMarqueeText(frame, LONG, 4, 8, 40, COLORS.clockTime, MARQUEE_PAUSE_MS + 20 * MARQUEE_STEP_MS);
+
+    const columns = columnsWithPixels(frame, 8);
+    expect(columns.length).toBeGreaterThan(0);
+    expect(Math.min(...columns)).toBeGreaterThanOrEqual(4);
+    expect(Math.max(...columns)).toBeLessThan(44);
+  });
+
+  it("moves as time passes", () => {
+    const before = createSolidFrame(64, 64);
+    const after = createSolidFrame(64, 64);
+    drawMarqueeText(before, LONG, 1, 8, 62, COLORS.clockTime, 0);
+    drawMarqueeText(after, LONG, 1, 8, 62, COLORS.clockTime, MARQUEE_PAUSE_MS + 5 * MARQUEE_STEP_MS);
+
+    expect(after.pixels).not.toEqual(before.pixels);
+  });
+});
@@ -0,0 +1,80 @@
+/**
+ * Marquee text
+ *
+ * Tiny text that doesn't fit its width scrolls left, holding still at the
+ * start of each pass so the beginning can be read, then wraps around with
+ * a gap. Text that fits is drawn as-is. The position follows elapsed time,
+ * so frames rendered at any rate agree on it.
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { BYTES_PER_PIXEL, createSolidFrame, fillFrame } from "@signage/core";
+import { drawTinyText, measureTinyText, DISPLAY_WIDTH, DISPLAY_HEIGHT, type TinyTextOptions } from "./text.js";
+import { TINY_FONT } from "./fonts.js";
+import { COLORS } from "./colors.js";
+
+/** Scroll speed */
+export const MARQUEE_PX_PER_SECOND = 8;
+
+/** Time between scroll steps; frames within one step look the same */
+export const MARQUEE_STEP_MS = 1000 / MARQUEE_PX_PER_SECOND;
+
+/** Hold at the start of each pass */
+export const MARQUEE_PAUSE_MS = 2000;
+
+/** Blank pixels between the end of the text and its next pass */
+const MARQUEE_GAP = 12;
+
+/** Display-sized frame the text is drawn on before copying; cleared per call */
+const scratch = createSolidFrame(DISPLAY_WIDTH, DISPLAY_HEIGHT, COLORS.bg);
+
+/**
+ * How far (px) the text has scrolled after `elapsedMs`; 0 for text that fits
+ */
+export function marqueeOffset(textWidth: number, width: number, elapsedMs: number): number {
+  if (textWidth <= width) return 0;
+  const cycle = textWidth + MARQUEE_GAP;
+  const cycleMs = MARQUEE_PAUSE_MS + cycle * MARQUEE_STEP_MS;
+  const t = ((elapsedMs % cycleMs) + cycleMs) % cycleMs;
+  return t < MARQUEE_PAUSE_MS ? 0 : Math.floor((t - MARQUEE_PAUSE_MS) / MARQUEE_STEP_MS);
+}
+
+/**
+ * Draw tiny text in the `width` pixels from `x`, scrolling it if it doesn't
+ * fit. Nothing is drawn outside that span.
+ */
+export function drawMarqueeText(
+  frame: Frame,
+  text: string,
+  x: number,
+  y: number,
+  width: number,
+  color: RGB,
+  elapsedMs: number,
+  options: TinyTextOptions = {}
+): void {
+  const textWidth = measureTinyText(text, options);
+  const offset = marqueeOffset(textWidth, width, elapsedMs);
+
+  // Draw on a scratch frame and copy the visible span, since text is only
+  // clipped at the display edges
+  fillFrame(scratch, COLORS.bg);
+  drawTinyText(scratch, text, x - offset, y, color, options);
+  if (offset > 0) {
+    drawTinyText(scratch, text, x - offset + textWidth + MARQUEE_GAP, y, color, options);
+  }
+  const endCol = Math.min(DISPLAY_WIDTH, frame.width, x + width);
+  for (let row = Math.max(0, y); row < Math.min(DISPLAY_HEIGHT, frame.height, y + TINY_FONT.height); row++) {
+    for (let col = Math.max(0, x); col < endCol; col++) {
+      const src = (row * DISPLAY_WIDTH + col) * BYTES_PER_PIXEL;
+      const r = scratch.pixels[src];
+      const g = scratch.pixels[src + 1];
+      const b = scratch.pixels[src + 2];
+      if (!r && !g && !b) continue;
+      const dst = (row * frame.width + col) * BYTES_PER_PIXEL;
+      frame.pixels[dst] = r;
+      frame.pixels[dst + 1] = g;
+      frame.pixels[dst + 2] = b;
+    }
+  }
+}
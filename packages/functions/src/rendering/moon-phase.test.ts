@@ -0,0 +1,65 @@
+/**
+ * Tests for the moon phase glyph
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { COLORS } from "./colors.js";
+import { drawMoonPhase, isMoonHour, moonPhase } from "./moon-phase.js";
+
+function litColumns(phase: number): number[] {
+  const frame = createSolidFrame(5, 5);
+  drawMoonPhase(frame, 0, 0, phase);
+  const columns: number[] = [];
+  for (let x = 0; x < 5; x++) {
+    if (getPixel(frame, x, 2)?.r === COLORS.moonLit.r) columns.push(x);
+  }
+  return columns;
+}
+
+describe("moonPhase", () => {
+  it("matches published new and full moons within a day", () => {
+    const day = 1 / 29.53;
+    // New moon January 18, 2026 19:52 UTC; full moon January 3, 2026 10:03 UTC
+    const newMoon = moonPhase(Date.parse("2026-01-18T19:52:00Z"));
+    expect(Math.min(newMoon, 1 - newMoon)).toBeLessThan(day);
+    expect(Math.abs(moonPhase(Date.parse("2026-01-03T10:03:00Z")) - 0.5)).toBeLessThan(day);
+  });
+
+  it("stays between 0 and 1 before the reference new moon", () => {
+    const phase = moonPhase(Date.parse("1999-12-25T00:00:00Z"));
+    expect(phase).toBeGreaterThanOrEqual(0);
+    expect(phase).toBeLessThan(1);
+  });
+});
+
+describe("drawMoonPhase", () => {
+  it("lights nothing at new moon and everything at full", () => {
+    expect(litColumns(0)).toEqual([]);
+    expect(litColumns(0.5)).toEqual([0, 1, 2, 3, 4]);
+  });
+
+  it("lights the right side while waxing and the left while waning", () => {
+    expect(litColumns(0.2)).toEqual([3, 4]);
+    expect(litColumns(0.3)).toEqual([2, 3, 4]);
+    expect(litColumns(0.7)).toEqual([0, 1, 2]);
+    expect(litColumns(0.8)).toEqual([0, 1]);
+  });
+
+  it("draws a round disk with the dark side dim", () => {
+    const frame = createSolidFrame(5, 5);
+    drawMoonPhase(frame, 0, 0, 0);
+    expect(getPixel(frame, 0, 0)).toEqual({ r: 0, g: 0, b: 0 });
+    expect(getPixel(frame, 2, 2)).toEqual(COLORS.moonDark);
+  });
+});
+
+describe("isMoonHour", () => {
+  it("is night from 6pm to 6am", () => {
+    expect(isMoonHour(18)).toBe(true);
+    expect(isMoonHour(0)).toBe(true);
+    expect(isMoonHour(5)).toBe(true);
+    expect(isMoonHour(6)).toBe(false);
+    expect(isMoonHour(12)).toBe(false);
+  });
+});
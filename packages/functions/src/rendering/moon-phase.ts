@@ -0,0 +1,65 @@
+/**
+ * Moon phase glyph
+ *
+ * The phase comes from the mean length of a lunar month counted from a
+ * known new moon, so it needs no API. The mean drifts up to about half a
+ * day from the true phase, which a 5px moon can't show anyway.
+ */
+
+import { setPixel, type Frame } from "@signage/core";
+import { COLORS } from "./colors.js";
+
+/** New moon of January 6, 2000, 18:14 UTC */
+const REFERENCE_NEW_MOON = Date.UTC(2000, 0, 6, 18, 14);
+/** Mean synodic month, in days */
+const SYNODIC_MONTH_DAYS = 29.530588853;
+
+/** Glyph width and height */
+export const MOON_GLYPH_SIZE = 5;
+
+/** The moon shows on the clock from this local hour... */
+export const MOON_NIGHT_START_HOUR = 18;
+/** ...until this one */
+export const MOON_NIGHT_END_HOUR = 6;
+
+/**
+ * Fraction of the lunar month at a time: 0 new, 0.25 first quarter,
+ * 0.5 full, 0.75 last quarter
+ */
+export function moonPhase(timestamp: number): number {
+  const days = (timestamp - REFERENCE_NEW_MOON) / (24 * 60 * 60 * 1000);
+  const phase = (days / SYNODIC_MONTH_DAYS) % 1;
+  return phase < 0 ? phase + 1 : phase;
+}
+
+/**
+ * Whether a local hour (0-23) is night, when the clock shows the moon
+ */
+export function isMoonHour(hour: number): boolean {
+  return hour >= MOON_NIGHT_START_HOUR || hour < MOON_NIGHT_END_HOUR;
+}
+
+/**
+ * Draw the moon as seen from the northern hemisphere, lit from the right
+ * while waxing and from the left while waning. The dark part is drawn dim
+ * so a new moon still reads as a moon.
+ */
+export function drawMoonPhase(frame: Frame, x: number, y: number, phase: number): void {
+  const radius = MOON_GLYPH_SIZE / 2;
+  // Cosine of the phase angle: where the terminator crosses each row, as a
+  // fraction of the row's half width
+  const terminator = Math.cos(2 * Math.PI * phase);
+  const waxing = phase < 0.5;
+
+  for (let row = 0; row < MOON_GLYPH_SIZE; row++) {
+    for (let col = 0; col < MOON_GLYPH_SIZE; col++) {
+      const dx = col + 0.5 - radius;
+      const dy = row + 0.5 - radius;
+      if (dx * dx + dy * dy > radius * radius) continue;
+
+      const halfWidth = Math.sqrt(radius * radius - dy * dy);
+      const lit = waxing ? dx > terminator * halfWidth : dx < -terminator * halfWidth;
+      setPixel(frame, x + col, y + row, lit ? COLORS.moonLit : COLORS.moonDark);
+    }
+  }
+}
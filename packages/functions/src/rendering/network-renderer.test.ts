@@ -0,0 +1,74 @@
+/**
+ * Tests for the network monitor page
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel, type Frame, type RGB } from "@signage/core";
+import { getPingColor, renderNetworkRegion } from "./network-renderer.js";
+import { COLORS } from "./colors.js";
+import type { NetworkSample } from "../network/types.js";
+
+const NOW = Date.UTC(2026, 9, 16, 15, 0);
+
+function samples(pings: Array<number | null>): NetworkSample[] {
+  return pings.map((pingMs, i) => ({ timestamp: NOW - (pings.length - 1 - i) * 60_000, pingMs }));
+}
+
+function hasColor(frame: Frame, color: RGB, minY: number, maxY: number): boolean {
+  for (let y = minY; y <= maxY; y++) {
+    for (let x = 0; x < 64; x++) {
+      if (JSON.stringify(getPixel(frame, x, y)) === JSON.stringify(color)) return true;
+    }
+  }
+  return false;
+}
+
+describe("getPingColor", () => {
+  it("is green quick, yellow slow and red very slow or lost", () => {
+    expect(getPingColor(20)).toEqual(COLORS.normal);
+    expect(getPingColor(120)).toEqual(COLORS.high);
+    expect(getPingColor(400)).toEqual(COLORS.urgentLow);
+    expect(getPingColor(null)).toEqual(COLORS.urgentLow);
+  });
+});
+
+describe("renderNetworkRegion", () => {
+  it("draws the latest round trip large and the history as a sparkline", () => {
+    const frame = createSolidFrame(64, 64);
+    renderNetworkRegion(frame, { host: "example.com", samples: samples([30, 25, 40, 23]) }, 7, 63);
+
+    // Large digits
+    expect(hasColor(frame, COLORS.normal, 15, 26)).toBe(true);
+    // Sparkline
+    expect(hasColor(frame, COLORS.normal, 38, 62)).toBe(true);
+  });
+
+  it("shows a lost ping in red and counts the loss", () => {
+    const frame = createSolidFrame(64, 64);
+    renderNetworkRegion(frame, { host: "example.com", samples: samples([30, null, 40, null]) }, 7, 63);
+
+    expect(hasColor(frame, COLORS.urgentLow, 15, 26)).toBe(true);
+    // "LOSS 50%" in the warning color
+    expect(hasColor(frame, COLORS.high, 30, 34)).toBe(true);
+  });
+
+  it("shows the latest download when one has run", () => {
+    const withDownload = samples([30, 25]);
+    withDownload[0].downloadMbps = 48.2;
+    const frame = createSolidFrame(64, 64);
+    renderNetworkRegion(frame, { host: "example.com", samples: withDownload }, 7, 63);
+
+    expect(hasColor(frame, COLORS.clockTime, 30, 34)).toBe(true);
+
+    const without = createSolidFrame(64, 64);
+    renderNetworkRegion(without, { host: "example.com", samples: samples([30, 25]) }, 7, 63);
+    expect(hasColor(without, COLORS.clockTime, 30, 34)).toBe(false);
+  });
+
+  it("says so before the first sample", () => {
+    const frame = createSolidFrame(64, 64);
+    renderNetworkRegion(frame, { host: "example.com", samples: [] }, 7, 63);
+
+    expect(hasColor(frame, COLORS.stale, 19, 23)).toBe(true);
+  });
+});
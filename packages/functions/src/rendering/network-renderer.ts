@@ -0,0 +1,82 @@
+/**
+ * Network monitor page
+ *
+ * ┌───────────────────────────────────────┐
+ * │ EXAMPLE.COM                           │  host
+ * │             23 MS                     │  latest round trip, large
+ * │ 48.2MBPS                     LOSS 0%  │  latest download, lost pings
+ * │ ‾‾\_/‾‾‾‾‾‾‾‾\__/‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾  │  round trips over 3 hours
+ * └───────────────────────────────────────┘
+ * The round trip and sparkline are green when quick, yellow when slow and
+ * red when very slow or lost.
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { drawText, drawTinyText, measureText, measureTinyText, DISPLAY_WIDTH } from "./text.js";
+import { LARGE_DIGIT_FONT } from "./fonts.js";
+import { COLORS } from "./colors.js";
+import { renderSparkline } from "./chart-renderer.js";
+import type { NetworkDisplayData } from "../network/types.js";
+
+/** Round trips up to this are good, up to SLOW_PING_MS slow, beyond that bad */
+const GOOD_PING_MS = 50;
+const SLOW_PING_MS = 150;
+
+/** Longest host that fits on its line */
+const MAX_HOST_LENGTH = 15;
+
+/**
+ * Color for a round trip: green quick, yellow slow, red very slow or lost
+ */
+export function getPingColor(pingMs: number | null): RGB {
+  if (pingMs === null || pingMs > SLOW_PING_MS) return COLORS.urgentLow;
+  if (pingMs > GOOD_PING_MS) return COLORS.high;
+  return COLORS.normal;
+}
+
+/**
+ * Render the network page within [minY, maxY]
+ */
+export function renderNetworkRegion(frame: Frame, data: NetworkDisplayData, minY: number, maxY: number): void {
+  drawTinyText(frame, data.host.toUpperCase().slice(0, MAX_HOST_LENGTH), 1, minY + 1, COLORS.clockSecondary);
+
+  const latest = data.samples[data.samples.length - 1];
+  if (!latest) {
+    const text = "NO SAMPLES";
+    drawTinyText(frame, text, Math.floor((DISPLAY_WIDTH - measureTinyText(text)) / 2), minY + 12, COLORS.stale);
+    return;
+  }
+
+  // Latest round trip in large digits with a small unit on their baseline,
+  // like the large clock
+  const pingY = minY + 8;
+  const pingColor = getPingColor(latest.pingMs);
+  if (latest.pingMs === null) {
+    const text = "TIMEOUT";
+    drawTinyText(frame, text, Math.floor((DISPLAY_WIDTH - measureTinyText(text)) / 2), pingY + 4, pingColor);
+  } else {
+    const digits = String(Math.min(latest.pingMs, 9999));
+    const digitsWidth = measureText(digits, LARGE_DIGIT_FONT);
+    const x = Math.floor((DISPLAY_WIDTH - digitsWidth - 2 - measureTinyText("MS")) / 2);
+    drawText(frame, digits, x, pingY, pingColor, minY, maxY, LARGE_DIGIT_FONT);
+    drawTinyText(frame, "MS", x + digitsWidth + 2, pingY + LARGE_DIGIT_FONT.height - 5, COLORS.clockSecondary);
+  }
+
+  // Newest download (if one has run) and the share of lost pings
+  const statsY = pingY + LARGE_DIGIT_FONT.height + 3;
+  const download = [...data.samples].reverse().find((s) => s.downloadMbps !== undefined);
+  if (download) {
+    const mbps = download.downloadMbps;
+    const text = `${mbps === null ? "--" : mbps >= 100 ? Math.round(mbps) : mbps.toFixed(1)}MBPS`;
+    drawTinyText(frame, text, 1, statsY, COLORS.clockTime);
+  }
+  const lost = data.samples.filter((s) => s.pingMs === null).length;
+  const loss = `LOSS ${Math.round((lost / data.samples.length) * 100)}%`;
+  const lossColor = lost > 0 ? COLORS.high : COLORS.clockSecondary;
+  drawTinyText(frame, loss, DISPLAY_WIDTH - 1 - measureTinyText(loss), statsY, lossColor);
+
+  // Round trips over the kept history; lost pings leave no point
+  const pings = data.samples.map((s) => s.pingMs).filter((ms): ms is number => ms !== null);
+  const sparklineY = statsY + 8;
+  renderSparkline(frame, pings, 1, sparklineY, DISPLAY_WIDTH - 2, maxY - sparklineY, pingColor);
+}
@@ -0,0 +1,52 @@
+/**
+ * Tests for notification banner renderer
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { notificationLines, renderNotificationBanner } from "./notification-renderer.js";
+import { ALERT_BANNER_HEIGHT } from "./alert-renderer.js";
+import { COLORS } from "./colors.js";
+import type { DisplayNotification } from "../notifications/types.js";
+
+const notification: DisplayNotification = {
+  id: "1-abc",
+  text: "Laundry is done",
+  priority: "normal",
+  seconds: 10,
+  createdAt: 0,
+};
+
+describe("notificationLines", () => {
+  it("wraps to two lines and cuts the rest short", () => {
+    expect(notificationLines("Laundry is done")).toEqual(["Laundry is done"]);
+    expect(notificationLines("Front door left open for ten minutes")).toEqual(["Front door left", "open for ten..."]);
+  });
+});
+
+describe("renderNotificationBanner", () => {
+  it("fills the banner rows with priority-colored sides", () => {
+    const frame = createSolidFrame(64, 64);
+    renderNotificationBanner(frame, { ...notification, priority: "high" }, 7);
+
+    expect(getPixel(frame, 0, 7)).toEqual(COLORS.notificationHigh);
+    expect(getPixel(frame, 63, 7 + ALERT_BANNER_HEIGHT - 1)).toEqual(COLORS.notificationHigh);
+    expect(getPixel(frame, 2, 7 + ALERT_BANNER_HEIGHT - 1)).toEqual(COLORS.notificationBackdrop);
+    expect(getPixel(frame, 2, 6)).toEqual({ r: 0, g: 0, b: 0 });
+    expect(getPixel(frame, 2, 7 + ALERT_BANNER_HEIGHT)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+
+  it("centers a single line vertically", () => {
+    const frame = createSolidFrame(64, 64);
+    renderNotificationBanner(frame, notification, 7);
+
+    const textRows = new Set<number>();
+    for (let y = 7; y < 7 + ALERT_BANNER_HEIGHT; y++) {
+      for (let x = 2; x < 62; x++) {
+        if (getPixel(frame, x, y)?.r === COLORS.alertText.r) textRows.add(y);
+      }
+    }
+    expect(Math.min(...textRows)).toBe(10);
+    expect(Math.max(...textRows)).toBe(14);
+  });
+});
@@ -0,0 +1,66 @@
+/**
+ * Notification banner renderer
+ *
+ * Draws a pushed notification over the insight region, wrapped to two lines:
+ * ┃          LAUNDRY IS                  ┃  line 1
+ * ┃          DONE                        ┃  line 2 ("..." if it doesn't fit)
+ * The side bars show the priority: grey low, blue normal, orange high.
+ * The text banner (see banner/template.ts) uses the same layout in teal.
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { fillRect } from "@signage/core";
+import { drawTinyText, measureTinyText, DISPLAY_WIDTH } from "./text.js";
+import { COLORS } from "./colors.js";
+import { ALERT_BANNER_HEIGHT } from "./alert-renderer.js";
+import { truncateText, wrapText } from "../text-utils.js";
+import type { DisplayNotification, NotificationPriority } from "../notifications/types.js";
+
+/** 3x5 glyphs with 1px spacing between the side bars */
+const MAX_CHARS_PER_LINE = 15;
+
+/** Second line offset from the banner top */
+const LINE2_OFFSET = 6;
+
+const PRIORITY_COLORS: Record<NotificationPriority, RGB> = {
+  low: COLORS.stale,
+  normal: COLORS.clockHeader,
+  high: COLORS.notificationHigh,
+};
+
+/**
+ * The (at most two) lines a notification's text is shown as
+ */
+export function notificationLines(text: string): string[] {
+  const lines = wrapText(text, MAX_CHARS_PER_LINE);
+  if (lines.length <= 2) return lines;
+  return [lines[0], truncateText(lines.slice(1).join(" "), MAX_CHARS_PER_LINE)];
+}
+
+/**
+ * Render a notification banner with its top edge at y
+ */
+export function renderNotificationBanner(frame: Frame, notification: DisplayNotification, y: number): void {
+  drawBanner(frame, notification.text, PRIORITY_COLORS[notification.priority], y);
+}
+
+/**
+ * Render the text banner's line with its top edge at y
+ */
+export function renderTextBanner(frame: Frame, text: string, y: number): void {
+  drawBanner(frame, text, COLORS.textBanner, y);
+}
+
+function drawBanner(frame: Frame, text: string, accent: RGB, y: number): void {
+  fillRect(frame, 0, y, DISPLAY_WIDTH, ALERT_BANNER_HEIGHT, COLORS.notificationBackdrop);
+  fillRect(frame, 0, y, 1, ALERT_BANNER_HEIGHT, accent);
+  fillRect(frame, DISPLAY_WIDTH - 1, y, 1, ALERT_BANNER_HEIGHT, accent);
+
+  // A single line sits in the middle, like the bolus status line
+  const lines = notificationLines(text);
+  const top = lines.length === 1 ? y + 3 : y;
+  lines.forEach((line, i) => {
+    const x = Math.floor((DISPLAY_WIDTH - measureTinyText(line)) / 2);
+    drawTinyText(frame, line, x, top + i * LINE2_OFFSET, COLORS.alertText);
+  });
+}
@@ -0,0 +1,58 @@
+/**
+ * Tests for the now playing rows
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { formatNowPlaying, renderNowPlayingRegion } from "./now-playing-renderer.js";
+import { COLORS } from "./colors.js";
+import type { NowPlaying } from "../spotify/types.js";
+
+const NOW = Date.UTC(2026, 9, 16, 15, 0);
+
+const TRACK: NowPlaying = {
+  title: "Song",
+  artist: "Band",
+  durationMs: 200_000,
+  progressMs: 50_000,
+  isPlaying: true,
+  fetchedAt: NOW,
+};
+
+describe("formatNowPlaying", () => {
+  it("joins title and artist, or shows the title alone", () => {
+    expect(formatNowPlaying(TRACK)).toBe("Song - Band");
+    expect(formatNowPlaying({ ...TRACK, artist: "" })).toBe("Song");
+  });
+});
+
+describe("renderNowPlayingRegion", () => {
+  it("fills the progress bar to the position, which runs on while playing", () => {
+    const frame = createSolidFrame(64, 64);
+    // A minute after fetching: 110s of 200s
+    renderNowPlayingRegion(frame, TRACK, 7, 17, NOW + 60_000);
+
+    expect(getPixel(frame, 2, 16)).toEqual(COLORS.nowPlaying);
+    expect(getPixel(frame, 33, 16)).toEqual(COLORS.nowPlaying);
+    expect(getPixel(frame, 36, 16)).toEqual(COLORS.veryDim);
+  });
+
+  it("greys out and holds the position while paused", () => {
+    const frame = createSolidFrame(64, 64);
+    renderNowPlayingRegion(frame, { ...TRACK, isPlaying: false }, 7, 17, NOW + 60_000);
+
+    expect(getPixel(frame, 2, 16)).toEqual(COLORS.stale);
+    expect(getPixel(frame, 20, 16)).toEqual(COLORS.veryDim);
+  });
+
+  it("draws the title", () => {
+    const frame = createSolidFrame(64, 64);
+    renderNowPlayingRegion(frame, TRACK, 7, 17, NOW);
+
+    let drawn = false;
+    for (let x = 0; x < 64; x++) {
+      if (JSON.stringify(getPixel(frame, x, 9)) === JSON.stringify(COLORS.clockTime)) drawn = true;
+    }
+    expect(drawn).toBe(true);
+  });
+});
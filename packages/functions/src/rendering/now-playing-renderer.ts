@@ -0,0 +1,53 @@
+/**
+ * Now playing
+ *
+ * Takes the insight rows while music plays:
+ * ┌───────────────────────────────────────┐
+ * │ Song Title - Artist Na→               │  scrolls when too long
+ * │ ▬▬▬▬▬▬▬▬▬▬▬▬▬▬▬──────────────────────  │  position in the track
+ * └───────────────────────────────────────┘
+ * Grey while paused.
+ */
+
+import type { Frame } from "@signage/core";
+import { fillRect } from "@signage/core";
+import { DISPLAY_WIDTH } from "./text.js";
+import { COLORS } from "./colors.js";
+import { drawMarqueeText } from "./marquee.js";
+import { nowPlayingProgress } from "../spotify/client.js";
+import type { NowPlaying } from "../spotify/types.js";
+
+/**
+ * The line shown for a track: title and artist
+ */
+export function formatNowPlaying(nowPlaying: NowPlaying): string {
+  return nowPlaying.artist ? `${nowPlaying.title} - ${nowPlaying.artist}` : nowPlaying.title;
+}
+
+/**
+ * Render what's playing within [minY, maxY] at `now`
+ */
+export function renderNowPlayingRegion(
+  frame: Frame,
+  nowPlaying: NowPlaying,
+  minY: number,
+  maxY: number,
+  now: number
+): void {
+  const textColor = nowPlaying.isPlaying ? COLORS.clockTime : COLORS.stale;
+  const barColor = nowPlaying.isPlaying ? COLORS.nowPlaying : COLORS.stale;
+  const progress = nowPlayingProgress(nowPlaying, now);
+
+  // Scroll from the start of the track, so a new track starts with its title
+  const trackStartedAt = nowPlaying.fetchedAt - nowPlaying.progressMs;
+  drawMarqueeText(frame, formatNowPlaying(nowPlaying), 1, minY + 2, DISPLAY_WIDTH - 2, textColor, now - trackStartedAt, {
+    mixedCase: true,
+  });
+
+  const barY = Math.min(minY + 9, maxY);
+  const barWidth = DISPLAY_WIDTH - 2;
+  fillRect(frame, 1, barY, barWidth, 1, COLORS.veryDim);
+  if (nowPlaying.durationMs > 0) {
+    fillRect(frame, 1, barY, Math.round((progress / nowPlaying.durationMs) * barWidth), 1, barColor);
+  }
+}
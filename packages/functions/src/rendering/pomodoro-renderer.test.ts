@@ -0,0 +1,69 @@
+/**
+ * Tests for pomodoro timer renderer
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { drawProgressBar, renderPomodoroRegion } from "./pomodoro-renderer.js";
+import { COLORS } from "./colors.js";
+import type { PomodoroStatus } from "../pomodoro/types.js";
+
+const status: PomodoroStatus = {
+  phase: "break",
+  cycle: 2,
+  cycles: 4,
+  remainingMs: 3 * 60 * 1000,
+  progress: 0.5,
+  paused: false,
+  display: "full",
+};
+
+describe("drawProgressBar", () => {
+  it("fills the done part and draws the rest as track", () => {
+    const frame = createSolidFrame(64, 64);
+    drawProgressBar(frame, 0, 0, 10, 2, 0.3, COLORS.normal);
+
+    expect(getPixel(frame, 2, 1)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 3, 0)).toEqual(COLORS.separator);
+    expect(getPixel(frame, 10, 0)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+
+  it("clamps progress to the bar", () => {
+    const frame = createSolidFrame(64, 64);
+    drawProgressBar(frame, 0, 0, 10, 1, 1.5, COLORS.normal);
+
+    expect(getPixel(frame, 9, 0)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 10, 0)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+});
+
+describe("renderPomodoroRegion", () => {
+  it("draws one line and a bar in a short region", () => {
+    const frame = createSolidFrame(64, 64);
+    renderPomodoroRegion(frame, status, 7, 17);
+
+    expect(getPixel(frame, 2, 14)).toEqual(COLORS.pomodoroBreak);
+    expect(getPixel(frame, 61, 14)).toEqual(COLORS.separator);
+    for (let x = 0; x < 64; x++) {
+      expect(getPixel(frame, x, 18)).toEqual({ r: 0, g: 0, b: 0 });
+    }
+  });
+
+  it("lights a dot per cycle started on the full page", () => {
+    const frame = createSolidFrame(64, 64);
+    renderPomodoroRegion(frame, status, 0, 63);
+
+    // Four dots, 5px apart, centered: the first two lit
+    expect(getPixel(frame, 23, 48)).toEqual(COLORS.pomodoroBreak);
+    expect(getPixel(frame, 28, 48)).toEqual(COLORS.pomodoroBreak);
+    expect(getPixel(frame, 33, 48)).toEqual(COLORS.separator);
+    expect(getPixel(frame, 38, 48)).toEqual(COLORS.separator);
+  });
+
+  it("grays everything out while paused", () => {
+    const frame = createSolidFrame(64, 64);
+    renderPomodoroRegion(frame, { ...status, paused: true }, 0, 63);
+
+    expect(getPixel(frame, 4, 38)).toEqual(COLORS.stale);
+  });
+});
@@ -0,0 +1,97 @@
+/**
+ * Pomodoro timer renderer
+ *
+ * In the insight rows, one line and a bar:
+ * ┌───────────────────────────────────────┐
+ * │ FOCUS                         24M 1/4 │  period, minutes left, cycle
+ * │ ▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮ │  period progress
+ * └───────────────────────────────────────┘
+ *
+ * Over the whole display, the period, minutes left in large digits, the
+ * bar, and a dot per cycle. Minutes are rounded up, so the display only
+ * has to change once a minute.
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { fillRect } from "@signage/core";
+import { drawText, measureText, DISPLAY_WIDTH } from "./text.js";
+import { LARGE_DIGIT_FONT } from "./fonts.js";
+import { COLORS } from "./colors.js";
+import type { PomodoroStatus } from "../pomodoro/types.js";
+
+/** Below this many rows the timer is drawn as a single line */
+const FULL_MIN_HEIGHT = 40;
+
+/** Cycle dot size and the gap after each */
+const DOT_SIZE = 3;
+const DOT_GAP = 2;
+
+/**
+ * Draw a horizontal progress bar: `progress` (0-1) of it filled from the
+ * left in `color`, the rest in `track`
+ */
+export function drawProgressBar(
+  frame: Frame,
+  x: number,
+  y: number,
+  width: number,
+  height: number,
+  progress: number,
+  color: RGB,
+  track: RGB = COLORS.separator
+): void {
+  const filled = Math.round(width * Math.max(0, Math.min(1, progress)));
+  fillRect(frame, x, y, width, height, track);
+  fillRect(frame, x, y, filled, height, color);
+}
+
+function phaseColor(status: PomodoroStatus): RGB {
+  if (status.paused) return COLORS.stale;
+  return status.phase === "focus" ? COLORS.pomodoroFocus : COLORS.pomodoroBreak;
+}
+
+function minutesLeft(status: PomodoroStatus): number {
+  return Math.ceil(status.remainingMs / 60_000);
+}
+
+function phaseLabel(status: PomodoroStatus): string {
+  return status.paused ? "PAUSED" : status.phase === "focus" ? "FOCUS" : "BREAK";
+}
+
+/**
+ * Render the timer within [minY, maxY]: one line and a bar when that's
+ * all the room there is, the full page otherwise
+ */
+export function renderPomodoroRegion(frame: Frame, status: PomodoroStatus, minY: number, maxY: number): void {
+  const color = phaseColor(status);
+
+  if (maxY - minY + 1 < FULL_MIN_HEIGHT) {
+    const right = `${minutesLeft(status)}M ${status.cycle}/${status.cycles}`;
+    drawText(frame, phaseLabel(status), 2, minY + 1, color, minY, maxY);
+    drawText(frame, right, DISPLAY_WIDTH - 2 - measureText(right), minY + 1, COLORS.clockTime, minY, maxY);
+    drawProgressBar(frame, 2, minY + 7, DISPLAY_WIDTH - 4, 2, status.progress, color);
+    return;
+  }
+
+  const label = phaseLabel(status);
+  drawText(frame, label, Math.floor((DISPLAY_WIDTH - measureText(label)) / 2), minY + 6, color, minY, maxY);
+
+  // Minutes in large digits with a small marker on their baseline, like the large clock
+  const digits = String(minutesLeft(status));
+  const digitsWidth = measureText(digits, LARGE_DIGIT_FONT);
+  const totalWidth = digitsWidth + 2 + measureText("MIN");
+  const x = Math.floor((DISPLAY_WIDTH - totalWidth) / 2);
+  const y = minY + 18;
+  drawText(frame, digits, x, y, COLORS.clockTime, minY, maxY, LARGE_DIGIT_FONT);
+  drawText(frame, "MIN", x + digitsWidth + 2, y + LARGE_DIGIT_FONT.height - 5, COLORS.clockSecondary, minY, maxY);
+
+  drawProgressBar(frame, 4, minY + 38, DISPLAY_WIDTH - 8, 4, status.progress, color);
+
+  // A dot per cycle: finished and current ones lit
+  const dotsWidth = status.cycles * (DOT_SIZE + DOT_GAP) - DOT_GAP;
+  const dotsX = Math.floor((DISPLAY_WIDTH - dotsWidth) / 2);
+  for (let i = 0; i < status.cycles; i++) {
+    const dotColor = i < status.cycle ? color : COLORS.separator;
+    fillRect(frame, dotsX + i * (DOT_SIZE + DOT_GAP), minY + 48, DOT_SIZE, DOT_SIZE, dotColor);
+  }
+}
@@ -0,0 +1,55 @@
+/**
+ * Tests for the precipitation nowcast bar
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { getPrecipitationColor, renderPrecipitationBar } from "./precipitation-renderer.js";
+import { COLORS } from "./colors.js";
+import type { PrecipitationNowcast } from "../precipitation/types.js";
+
+const NOW = Date.UTC(2026, 9, 16, 15, 0);
+const MINUTE = 60_000;
+
+function nowcast(rates: number[]): PrecipitationNowcast {
+  return {
+    latitude: 47.6,
+    longitude: -122.3,
+    fetchedAt: NOW,
+    steps: rates.map((mmPerHour, i) => ({ end: NOW + (i + 1) * 15 * MINUTE, mmPerHour })),
+  };
+}
+
+describe("getPrecipitationColor", () => {
+  it("grades from light blue through blue to purple", () => {
+    expect(getPrecipitationColor(0.1)).toEqual(COLORS.rainLight);
+    expect(getPrecipitationColor(2.5)).toEqual(COLORS.rainModerate);
+    expect(getPrecipitationColor(10)).toEqual(COLORS.rainHeavy);
+    expect(getPrecipitationColor(50)).toEqual(COLORS.rainHeavy);
+  });
+
+  it("gets bluer as the rate rises", () => {
+    expect(getPrecipitationColor(1).r).toBeLessThan(getPrecipitationColor(0.3).r);
+  });
+});
+
+describe("renderPrecipitationBar", () => {
+  it("draws a minute per pixel, dry minutes as a dim track", () => {
+    const frame = createSolidFrame(64, 64);
+    renderPrecipitationBar(frame, nowcast([0, 2.5, 10, 0]), 18, NOW);
+
+    // Bar starts at x=2 with the current minute
+    expect(getPixel(frame, 2, 18)).toEqual(COLORS.veryDim);
+    expect(getPixel(frame, 2 + 20, 18)).toEqual(COLORS.rainModerate);
+    expect(getPixel(frame, 2 + 40, 18)).toEqual(COLORS.rainHeavy);
+    expect(getPixel(frame, 2 + 50, 18)).toEqual(COLORS.veryDim);
+    expect(getPixel(frame, 1, 18)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+
+  it("draws nothing for a dry hour", () => {
+    const frame = createSolidFrame(64, 64);
+    renderPrecipitationBar(frame, nowcast([0, 0, 0, 0]), 18, NOW);
+
+    expect(getPixel(frame, 2, 18)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+});
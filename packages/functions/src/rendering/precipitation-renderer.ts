@@ -0,0 +1,63 @@
+/**
+ * Precipitation nowcast bar
+ *
+ * One pixel per minute for the next hour, along the free row between the
+ * insight text and the insulin totals:
+ * ┌───────────────────────────────────────┐
+ * │ ░░░░░▒▒▒▓▓▓▓▒▒░░░░                    │  now → +60 min
+ * └───────────────────────────────────────┘
+ * Light blue for drizzle through blue to purple for a downpour; dry minutes
+ * are a dim track. Nothing is drawn when the hour is dry.
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { setPixel } from "@signage/core";
+import { DISPLAY_WIDTH } from "./text.js";
+import { COLORS } from "./colors.js";
+import { lerpColor } from "./chart-renderer.js";
+import {
+  isPrecipitationExpected,
+  precipitationAt,
+  MIN_PRECIPITATION_MM_PER_HOUR,
+} from "../precipitation/nowcast.js";
+import type { PrecipitationNowcast } from "../precipitation/types.js";
+
+/** Minutes the bar covers, one pixel each */
+export const PRECIPITATION_BAR_MINUTES = 60;
+
+/** Rates (mm/h) at which the bar is fully blue, and fully purple */
+const MODERATE_MM_PER_HOUR = 2.5;
+const HEAVY_MM_PER_HOUR = 10;
+
+/**
+ * Color for a precipitation rate, graded on a log scale from light blue
+ * (drizzle) through blue (2.5 mm/h) to purple (10 mm/h and up)
+ */
+export function getPrecipitationColor(mmPerHour: number): RGB {
+  const position = (value: number, from: number, to: number) =>
+    Math.min(1, Math.max(0, Math.log(value / from) / Math.log(to / from)));
+  if (mmPerHour <= MODERATE_MM_PER_HOUR) {
+    return lerpColor(
+      COLORS.rainLight,
+      COLORS.rainModerate,
+      position(Math.max(mmPerHour, MIN_PRECIPITATION_MM_PER_HOUR), MIN_PRECIPITATION_MM_PER_HOUR, MODERATE_MM_PER_HOUR)
+    );
+  }
+  return lerpColor(COLORS.rainModerate, COLORS.rainHeavy, position(mmPerHour, MODERATE_MM_PER_HOUR, HEAVY_MM_PER_HOUR));
+}
+
+/**
+ * Render the next hour's precipitation as a bar on row `y`, starting at
+ * `now`. Draws nothing when no precipitation is expected.
+ */
+export function renderPrecipitationBar(frame: Frame, nowcast: PrecipitationNowcast, y: number, now: number): void {
+  if (!isPrecipitationExpected(nowcast, now, PRECIPITATION_BAR_MINUTES)) return;
+
+  const x0 = Math.floor((DISPLAY_WIDTH - PRECIPITATION_BAR_MINUTES) / 2);
+  for (let minute = 0; minute < PRECIPITATION_BAR_MINUTES; minute++) {
+    const rate = precipitationAt(nowcast, now + minute * 60_000);
+    const color =
+      rate !== null && rate >= MIN_PRECIPITATION_MM_PER_HOUR ? getPrecipitationColor(rate) : COLORS.veryDim;
+    setPixel(frame, x0 + minute, y, color);
+  }
+}
@@ -0,0 +1,68 @@
+/**
+ * Tests for the split glucose page
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, type Frame, type RGB } from "@signage/core";
+import { renderSplitGlucoseRegion } from "./split-renderer.js";
+import { COLORS } from "./colors.js";
+import type { PersonGlucoseData } from "../followers/types.js";
+
+const now = Date.UTC(2026, 0, 1, 12, 0, 0);
+const ana = { r: 120, g: 180, b: 255 };
+const max = { r: 255, g: 140, b: 200 };
+
+function person(name: string, color: RGB, glucose: number, overrides: Partial<PersonGlucoseData> = {}): PersonGlucoseData {
+  return {
+    name,
+    color,
+    low: 70,
+    high: 180,
+    reading: { glucose, trend: "Flat", delta: 1, timestamp: now - 60_000, rangeStatus: "normal", isStale: false },
+    history: [{ timestamp: now - 30 * 60_000, glucose }],
+    ...overrides,
+  };
+}
+
+function colorsIn(frame: Frame, x0: number, x1: number, y0: number, y1: number): Set<string> {
+  const colors = new Set<string>();
+  for (let y = y0; y <= y1; y++) {
+    for (let x = x0; x <= x1; x++) {
+      const i = (y * frame.width + x) * 3;
+      colors.add(`${frame.pixels[i]},${frame.pixels[i + 1]},${frame.pixels[i + 2]}`);
+    }
+  }
+  return colors;
+}
+
+const has = (colors: Set<string>, { r, g, b }: RGB) => colors.has(`${r},${g},${b}`);
+
+describe("renderSplitGlucoseRegion", () => {
+  it("draws each person in their own column and color", () => {
+    const frame = createSolidFrame(64, 64);
+    renderSplitGlucoseRegion(frame, [person("ANA", ana, 120), person("MAX", max, 120)], 7, 63, now);
+
+    expect(has(colorsIn(frame, 0, 30, 8, 12), ana)).toBe(true);
+    expect(has(colorsIn(frame, 0, 30, 8, 12), max)).toBe(false);
+    expect(has(colorsIn(frame, 32, 63, 8, 12), max)).toBe(true);
+  });
+
+  it("colors readings by each person's thresholds", () => {
+    const frame = createSolidFrame(64, 64);
+    renderSplitGlucoseRegion(frame, [person("ANA", ana, 190), person("MAX", max, 190, { high: 200 })], 7, 63, now);
+
+    expect(has(colorsIn(frame, 0, 30, 15, 19), COLORS.high)).toBe(true);
+    expect(has(colorsIn(frame, 32, 63, 15, 19), COLORS.normal)).toBe(true);
+  });
+
+  it("grays a stale reading and shows --- with none", () => {
+    const frame = createSolidFrame(64, 64);
+    const stale = person("ANA", ana, 120);
+    stale.reading = { ...stale.reading!, isStale: true };
+    renderSplitGlucoseRegion(frame, [stale, person("MAX", max, 120, { reading: null })], 7, 63, now);
+
+    expect(has(colorsIn(frame, 0, 30, 15, 63), COLORS.normal)).toBe(false);
+    expect(has(colorsIn(frame, 0, 30, 15, 19), COLORS.stale)).toBe(true);
+    expect(has(colorsIn(frame, 32, 63, 15, 19), COLORS.stale)).toBe(true);
+  });
+});
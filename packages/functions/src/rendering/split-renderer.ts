@@ -0,0 +1,143 @@
+/**
+ * Split glucose page: several people side by side
+ *
+ * ┌─────────────────────────────────┐
+ * │   ME      │   MAX    │   ZOE    │  names in each person's color
+ * │   123     │   84     │   211    │  reading in their own range colors
+ * │   → +2    │   ↘ -6   │   ↑ +12  │  trend and delta
+ * │  ╱‾‾╲_    │  ‾‾╲__   │   __╱‾   │  last 3 hours, dotted low/high lines
+ * └─────────────────────────────────┘
+ * Columns share the width evenly (two or three of them). A stale reading
+ * and its chart are gray; a person with nothing read shows "---".
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { setPixel } from "@signage/core";
+import { drawText, measureText, DISPLAY_WIDTH } from "./text.js";
+import { COLORS, getTrendTintedColor } from "./colors.js";
+import { drawTrendArrow } from "./blood-sugar-renderer.js";
+import { isTrendComputable } from "../dexcom/client.js";
+import { classifyPersonRange } from "../followers/people.js";
+import type { PersonGlucoseData } from "../followers/types.js";
+
+/** Hours of history in each mini chart */
+export const SPLIT_CHART_HOURS = 3;
+
+/** Glucose scale of the mini charts (mg/dL), shared so columns compare */
+const CHART_MIN_MGDL = 40;
+const CHART_MAX_MGDL = 300;
+
+const ARROW_WIDTH = 5;
+
+/** Rows below the region top: name, reading, trend, then the chart */
+const NAME_ROW = 1;
+const READING_ROW = 8;
+const TREND_ROW = 15;
+const CHART_TOP = 22;
+
+function centeredX(text: string, x: number, width: number): number {
+  return x + Math.max(0, Math.floor((width - measureText(text)) / 2));
+}
+
+/**
+ * Draw one person's mini chart: a point per reading, in their range colors
+ * (gray when stale), with dotted lines at their low and high
+ */
+function drawMiniChart(
+  frame: Frame,
+  person: PersonGlucoseData,
+  x: number,
+  y: number,
+  width: number,
+  height: number,
+  now: number
+): void {
+  const toY = (glucose: number): number => {
+    const clamped = Math.min(CHART_MAX_MGDL, Math.max(CHART_MIN_MGDL, glucose));
+    return y + height - 1 - Math.round(((clamped - CHART_MIN_MGDL) / (CHART_MAX_MGDL - CHART_MIN_MGDL)) * (height - 1));
+  };
+
+  for (const limit of [person.low, person.high]) {
+    for (let px = x; px < x + width; px += 2) {
+      setPixel(frame, px, toY(limit), COLORS.chartGrid);
+    }
+  }
+
+  const start = now - SPLIT_CHART_HOURS * 60 * 60 * 1000;
+  const live = person.reading !== null && !person.reading.isStale;
+  for (const point of person.history) {
+    if (point.timestamp < start || point.timestamp > now) continue;
+    const px = x + Math.min(width - 1, Math.floor(((point.timestamp - start) / (now - start)) * width));
+    const color = live ? COLORS[classifyPersonRange(point.glucose, person.low, person.high)] : COLORS.stale;
+    setPixel(frame, px, toY(point.glucose), color);
+  }
+}
+
+/**
+ * Draw one person's column
+ */
+function drawPersonColumn(
+  frame: Frame,
+  person: PersonGlucoseData,
+  x: number,
+  width: number,
+  startY: number,
+  endY: number,
+  now: number
+): void {
+  const name = person.name.toUpperCase();
+  drawText(frame, name, centeredX(name, x, width), startY + NAME_ROW, person.color, startY, endY);
+
+  const reading = person.reading;
+  if (!reading) {
+    drawText(frame, "---", centeredX("---", x, width), startY + READING_ROW, COLORS.stale, startY, endY);
+  } else {
+    const valueColor: RGB = reading.isStale
+      ? COLORS.stale
+      : COLORS[classifyPersonRange(reading.glucose, person.low, person.high)];
+    const glucose = String(reading.glucose);
+    drawText(frame, glucose, centeredX(glucose, x, width), startY + READING_ROW, valueColor, startY, endY);
+
+    // Trend arrow, and the delta when the sensor reports a usable trend
+    const delta = isTrendComputable(reading.trend)
+      ? reading.delta >= 0
+        ? `+${reading.delta}`
+        : String(reading.delta)
+      : "";
+    const trendWidth = ARROW_WIDTH + (delta ? 1 + measureText(delta) : 0);
+    const trendX = x + Math.max(0, Math.floor((width - trendWidth) / 2));
+    const trendY = startY + TREND_ROW;
+    drawTrendArrow(frame, reading.trend, trendX, trendY, valueColor, startY, endY);
+    if (delta) {
+      drawText(frame, delta, trendX + ARROW_WIDTH + 1, trendY, getTrendTintedColor(valueColor, reading.trend), startY, endY);
+    }
+  }
+
+  const chartY = startY + CHART_TOP;
+  drawMiniChart(frame, person, x + 1, chartY, width - 2, endY - chartY + 1, now);
+}
+
+/**
+ * Render the split page between startY and endY
+ */
+export function renderSplitGlucoseRegion(
+  frame: Frame,
+  people: PersonGlucoseData[],
+  startY: number,
+  endY: number,
+  now: number = Date.now()
+): void {
+  if (people.length === 0) {
+    drawText(frame, "NO PEOPLE", centeredX("NO PEOPLE", 0, DISPLAY_WIDTH), startY + READING_ROW, COLORS.stale, startY, endY);
+    return;
+  }
+
+  const width = Math.floor(DISPLAY_WIDTH / people.length);
+  people.forEach((person, i) => {
+    const x = i * width;
+    if (i > 0) {
+      for (let y = startY; y <= endY; y++) setPixel(frame, x - 1, y, COLORS.separator);
+    }
+    drawPersonColumn(frame, person, x, i === people.length - 1 ? DISPLAY_WIDTH - x : width - 1, startY, endY, now);
+  });
+}
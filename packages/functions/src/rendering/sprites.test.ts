@@ -0,0 +1,65 @@
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { drawSprite, spriteHeight, spriteWidth, type Sprite } from "./sprites";
+
+const RED = { r: 255, g: 0, b: 0 };
+const BLUE = { r: 0, g: 0, b: 255 };
+const BLACK = { r: 0, g: 0, b: 0 };
+
+const ARROW: Sprite = {
+  rows: [".R.", "RBR", ".X."],
+  palette: { R: RED, B: BLUE },
+};
+
+describe("drawSprite", () => {
+  it("measures a sprite by its rows", () => {
+    expect(spriteWidth(ARROW)).toBe(3);
+    expect(spriteHeight(ARROW)).toBe(3);
+  });
+
+  it("draws palette colors and leaves transparent pixels alone", () => {
+    const frame = createSolidFrame(8, 8, BLUE);
+    drawSprite(frame, ARROW, 2, 3);
+
+    expect(getPixel(frame, 3, 3)).toEqual(RED);
+    expect(getPixel(frame, 2, 4)).toEqual(RED);
+    expect(getPixel(frame, 3, 4)).toEqual(BLUE);
+    expect(getPixel(frame, 2, 3)).toEqual(BLUE);
+    // Characters missing from the palette are skipped too
+    expect(getPixel(frame, 3, 5)).toEqual(BLUE);
+  });
+
+  it("swaps colors from the options palette", () => {
+    const frame = createSolidFrame(8, 8);
+    drawSprite(frame, ARROW, 0, 0, { palette: { R: BLUE } });
+
+    expect(getPixel(frame, 1, 0)).toEqual(BLUE);
+    expect(getPixel(frame, 1, 1)).toEqual(BLUE);
+  });
+
+  it("scales each pixel to a square", () => {
+    const frame = createSolidFrame(8, 8);
+    drawSprite(frame, ARROW, 0, 0, { scale: 2 });
+
+    expect(getPixel(frame, 2, 0)).toEqual(RED);
+    expect(getPixel(frame, 3, 1)).toEqual(RED);
+    expect(getPixel(frame, 1, 1)).toEqual(BLACK);
+    expect(getPixel(frame, 0, 2)).toEqual(RED);
+  });
+
+  it("blends with alpha", () => {
+    const frame = createSolidFrame(8, 8);
+    drawSprite(frame, ARROW, 0, 0, { alpha: 0.5 });
+
+    expect(getPixel(frame, 1, 0)?.r).toBeGreaterThan(100);
+    expect(getPixel(frame, 1, 0)?.r).toBeLessThan(200);
+  });
+
+  it("clips at the frame edges", () => {
+    const frame = createSolidFrame(4, 4);
+    drawSprite(frame, ARROW, -1, 2);
+
+    expect(getPixel(frame, 0, 2)).toEqual(RED);
+    expect(getPixel(frame, 1, 3)).toEqual(RED);
+  });
+});
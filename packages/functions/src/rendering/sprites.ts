@@ -0,0 +1,60 @@
+/**
+ * Pixel-art sprites
+ *
+ * Small images written in source as rows of characters, one per pixel,
+ * each looked up in the sprite's palette; "." is transparent. They're
+ * compiled in, so icons need no files or decoding (image.ts loads PNG and
+ * GIF files for anything bigger).
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { fillRect } from "@signage/core";
+
+/** Row character for a pixel left as it is */
+export const SPRITE_TRANSPARENT = ".";
+
+/**
+ * A pixel-art image
+ */
+export interface Sprite {
+  /** One string per row, all the same length */
+  rows: readonly string[];
+  /** Color for each character the rows use, other than "." */
+  palette: Readonly<Record<string, RGB>>;
+}
+
+export interface DrawSpriteOptions {
+  /** Colors to use instead of the sprite's own, by character (default: none) */
+  palette?: Readonly<Record<string, RGB>>;
+  /** Opacity, 0-1 (default: 1) */
+  alpha?: number;
+  /** Draw each pixel as a square this many pixels wide (default: 1) */
+  scale?: number;
+}
+
+export function spriteWidth(sprite: Sprite): number {
+  return sprite.rows[0]?.length ?? 0;
+}
+
+export function spriteHeight(sprite: Sprite): number {
+  return sprite.rows.length;
+}
+
+/**
+ * Draw a sprite with its top-left corner at (x, y), clipped to the frame.
+ * Transparent pixels and characters without a color are skipped.
+ */
+export function drawSprite(frame: Frame, sprite: Sprite, x: number, y: number, options: DrawSpriteOptions = {}): void {
+  const { alpha = 1 } = options;
+  const scale = Math.max(1, Math.floor(options.scale ?? 1));
+
+  sprite.rows.forEach((row, rowIndex) => {
+    for (let col = 0; col < row.length; col++) {
+      const char = row[col];
+      if (char === SPRITE_TRANSPARENT) continue;
+      const color = options.palette?.[char] ?? sprite.palette[char];
+      if (!color) continue;
+      fillRect(frame, x + col * scale, y + rowIndex * scale, scale, scale, color, alpha);
+    }
+  });
+}
@@ -0,0 +1,63 @@
+/**
+ * Tests for the host system page
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { getTemperatureColor, getUsageColor, renderSystemRegion } from "./system-renderer.js";
+import { COLORS } from "./colors.js";
+
+const METRICS = { hostname: "signage-pi", cpuPercent: 50, memoryPercent: 95, temperatureC: 52.6 };
+
+describe("getUsageColor", () => {
+  it("is green, then yellow from 70% and red from 90%", () => {
+    expect(getUsageColor(20)).toEqual(COLORS.normal);
+    expect(getUsageColor(75)).toEqual(COLORS.high);
+    expect(getUsageColor(90)).toEqual(COLORS.urgentLow);
+  });
+});
+
+describe("getTemperatureColor", () => {
+  it("is green, then yellow from 65°C and red from 75°C, grey when unknown", () => {
+    expect(getTemperatureColor(50)).toEqual(COLORS.normal);
+    expect(getTemperatureColor(70)).toEqual(COLORS.high);
+    expect(getTemperatureColor(80)).toEqual(COLORS.urgentLow);
+    expect(getTemperatureColor(null)).toEqual(COLORS.stale);
+  });
+});
+
+describe("renderSystemRegion", () => {
+  it("fills each bar to its share in the metric's color", () => {
+    const frame = createSolidFrame(64, 64);
+    renderSystemRegion(frame, METRICS, 7, 63);
+
+    // CPU bar (row at y=16, bar from y=23): half filled, then the track
+    expect(getPixel(frame, 2, 24)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 31, 24)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 40, 24)).toEqual(COLORS.separator);
+    // Memory bar nearly full, in red
+    expect(getPixel(frame, 55, 38)).toEqual(COLORS.urgentLow);
+    // Temperature bar: 52.6°C is 41% of 30-85°C
+    expect(getPixel(frame, 20, 52)).toEqual(COLORS.normal);
+    expect(getPixel(frame, 30, 52)).toEqual(COLORS.separator);
+  });
+
+  it("leaves the temperature bar empty without a thermal zone", () => {
+    const frame = createSolidFrame(64, 64);
+    renderSystemRegion(frame, { ...METRICS, temperatureC: null }, 7, 63);
+
+    expect(getPixel(frame, 2, 52)).toEqual(COLORS.separator);
+  });
+
+  it("says there's no data without metrics", () => {
+    const frame = createSolidFrame(64, 64);
+    renderSystemRegion(frame, null, 7, 63);
+
+    let drawn = false;
+    for (let x = 0; x < 64; x++) {
+      if (getPixel(frame, x, 10)?.r) drawn = true;
+    }
+    expect(drawn).toBe(true);
+    expect(getPixel(frame, 2, 24)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+});
@@ -0,0 +1,101 @@
+/**
+ * Host system page
+ *
+ * ┌───────────────────────────────────────┐
+ * │ SIGNAGE-PI                            │  host name
+ * │ CPU                              23%  │
+ * │ ▮▮▮▮▮▮▮▮▮▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯  │  share in use
+ * │ MEM                              41%  │
+ * │ ▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯  │
+ * │ TEMP                             52C  │
+ * │ ▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▮▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯▯  │  30-85°C
+ * └───────────────────────────────────────┘
+ * Values and bars are green when comfortable, yellow when busy or warm and
+ * red when close to the limit (a Pi throttles at 80-85°C).
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { fillRect } from "@signage/core";
+import { drawTinyText, measureTinyText, DISPLAY_WIDTH } from "./text.js";
+import { COLORS } from "./colors.js";
+import type { SystemMetrics } from "../system/types.js";
+
+/** Rows per metric: label line, gap, bar, gap */
+const ROW_HEIGHT = 14;
+
+const BAR_HEIGHT = 4;
+
+/** Temperatures the bar spans, °C */
+const TEMPERATURE_MIN_C = 30;
+const TEMPERATURE_MAX_C = 85;
+
+/** Longest host name that fits on its line */
+const MAX_HOSTNAME_LENGTH = 15;
+
+/**
+ * Color for a CPU or memory percentage: green, yellow from 70%, red from 90%
+ */
+export function getUsageColor(percent: number): RGB {
+  if (percent >= 90) return COLORS.urgentLow;
+  if (percent >= 70) return COLORS.high;
+  return COLORS.normal;
+}
+
+/**
+ * Color for a temperature: green, yellow from 65°C, red from 75°C
+ */
+export function getTemperatureColor(celsius: number | null): RGB {
+  if (celsius === null) return COLORS.stale;
+  if (celsius >= 75) return COLORS.urgentLow;
+  if (celsius >= 65) return COLORS.high;
+  return COLORS.normal;
+}
+
+/**
+ * Draw one metric: label and value, then a bar filled to `fraction`
+ * (null leaves the bar empty)
+ */
+function drawMetric(frame: Frame, y: number, label: string, value: string, fraction: number | null, color: RGB): void {
+  drawTinyText(frame, label, 1, y, COLORS.clockSecondary);
+  drawTinyText(frame, value, DISPLAY_WIDTH - 1 - measureTinyText(value), y, color);
+
+  const barWidth = DISPLAY_WIDTH - 2;
+  fillRect(frame, 1, y + 7, barWidth, BAR_HEIGHT, COLORS.separator);
+  if (fraction !== null) {
+    const filled = Math.round(Math.min(1, Math.max(0, fraction)) * barWidth);
+    fillRect(frame, 1, y + 7, filled, BAR_HEIGHT, color);
+  }
+}
+
+/**
+ * Render the host system page within [minY, maxY].
+ * Metrics that don't fit are left off.
+ */
+export function renderSystemRegion(frame: Frame, metrics: SystemMetrics | null, minY: number, maxY: number): void {
+  if (!metrics) {
+    const text = "NO HOST DATA";
+    drawTinyText(frame, text, Math.floor((DISPLAY_WIDTH - measureTinyText(text)) / 2), minY + 2, COLORS.stale);
+    return;
+  }
+
+  drawTinyText(frame, metrics.hostname.toUpperCase().slice(0, MAX_HOSTNAME_LENGTH), 1, minY + 1, COLORS.clockTime);
+
+  const { cpuPercent, memoryPercent, temperatureC } = metrics;
+  const rows: Array<[string, string, number | null, RGB]> = [
+    ["CPU", `${cpuPercent}%`, cpuPercent / 100, getUsageColor(cpuPercent)],
+    ["MEM", `${memoryPercent}%`, memoryPercent / 100, getUsageColor(memoryPercent)],
+    [
+      "TEMP",
+      temperatureC === null ? "--" : `${Math.round(temperatureC)}C`,
+      temperatureC === null ? null : (temperatureC - TEMPERATURE_MIN_C) / (TEMPERATURE_MAX_C - TEMPERATURE_MIN_C),
+      getTemperatureColor(temperatureC),
+    ],
+  ];
+
+  let y = minY + 9;
+  for (const [label, value, fraction, color] of rows) {
+    if (y + 7 + BAR_HEIGHT - 1 > maxY) break;
+    drawMetric(frame, y, label, value, fraction, color);
+    y += ROW_HEIGHT;
+  }
+}
@@ -3,7 +3,18 @@
  */
 
 import { describe, it, expect } from "vitest";
-import { measureText, measureTinyText, centerX } from "./text.js";
+import { createSolidFrame, getPixel } from "@signage/core";
+import { measureText, measureTinyText, centerX, drawText, drawTinyText } from "./text.js";
+import {
+  FONTS,
+  TINY_FONT,
+  TINY_MIXED_CASE_FONT,
+  MEDIUM_FONT,
+  LARGE_DIGIT_FONT,
+  glyphWidth,
+  resolveGlyph,
+  type Font,
+} from "./fonts.js";
 
 describe("measureTinyText", () => {
   it("returns 0 for empty string", () => {
@@ -48,3 +59,121 @@ describe("centerX", () => {
     expect(centerX("A")).toBe(30);
   });
 });
+
+describe("fonts", () => {
+  it.each(Object.values(FONTS))("$name glyphs match the font size", (font) => {
+    for (const [char, rows] of Object.entries(font.glyphs)) {
+      expect(rows, char).toHaveLength(font.height);
+      const width = glyphWidth(font, char);
+      for (const row of rows) {
+        expect(row >> width, char).toBe(0);
+      }
+    }
+  });
+
+  it("has every digit in every font", () => {
+    for (const font of Object.values(FONTS)) {
+      for (const digit of "0123456789:") {
+        expect(font.glyphs[digit], `${font.name} ${digit}`).toBeDefined();
+      }
+    }
+  });
+
+  it("measures with the selected font", () => {
+    // 5px + 1px + 5px
+    expect(measureText("AB", MEDIUM_FONT)).toBe(11);
+    // Four 8px digits, a 2px colon, four 1px gaps
+    expect(measureText("12:45", LARGE_DIGIT_FONT)).toBe(38);
+    expect(centerX("12:45", LARGE_DIGIT_FONT)).toBe(13);
+  });
+
+  it("draws with the selected font", () => {
+    const frame = createSolidFrame(64, 64);
+    const white = { r: 255, g: 255, b: 255 };
+    drawText(frame, "1", 0, 0, white, 0, 63, LARGE_DIGIT_FONT);
+
+    // Bottom bar of the large 1 spans columns 1-6 on rows 10-11
+    expect(getPixel(frame, 1, 11)).toEqual(white);
+    expect(getPixel(frame, 6, 11)).toEqual(white);
+    expect(getPixel(frame, 0, 11)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+});
+
+describe("symbols and fallbacks", () => {
+  it("has trend arrows and the degree sign in the text fonts", () => {
+    for (const font of [TINY_FONT, MEDIUM_FONT]) {
+      for (const char of "↑↗→↘↓⇅°") {
+        expect(resolveGlyph(font, char)?.char, `${font.name} ${char}`).toBe(char);
+      }
+    }
+  });
+
+  it("keeps decimal points narrow", () => {
+    // 3px + 1px + 1px + 1px + 3px
+    expect(measureText("7.8")).toBe(9);
+    expect(measureTinyText("7.8")).toBe(9);
+    expect(measureText("7.8", LARGE_DIGIT_FONT)).toBe(20);
+  });
+
+  it("falls back to a stand-in glyph, then the other case", () => {
+    const font: Font = {
+      name: "tiny",
+      width: 3,
+      height: 5,
+      spacing: 1,
+      glyphs: { "^": [0b010, 0b101, 0, 0, 0], A: [0b010, 0b101, 0b111, 0b101, 0b101] },
+    };
+
+    expect(resolveGlyph(font, "↑")?.char).toBe("^");
+    expect(resolveGlyph(font, "a")?.char).toBe("A");
+    expect(resolveGlyph(font, "↓")).toBeUndefined();
+  });
+
+  it("draws a fallback glyph instead of a blank", () => {
+    const frame = createSolidFrame(64, 64);
+    const yellow = { r: 255, g: 255, b: 0 };
+    // Large font has no letters or arrows, but "−" (minus sign) falls back to "-"
+    drawText(frame, "−", 0, 0, yellow, 0, 63, LARGE_DIGIT_FONT);
+
+    expect(getPixel(frame, 3, 5)).toEqual(yellow);
+  });
+});
+
+describe("tiny text case and punctuation", () => {
+  const white = { r: 255, g: 255, b: 255 };
+
+  it("has punctuation in the tiny font", () => {
+    for (const char of "%+,()[];:\"'_#&|^!?") {
+      expect(TINY_FONT.glyphs[char], char).toBeDefined();
+    }
+  });
+
+  it("has a lowercase glyph for every letter in the mixed-case font", () => {
+    for (const char of "abcdefghijklmnopqrstuvwxyz") {
+      const rows = TINY_MIXED_CASE_FONT.glyphs[char];
+      expect(rows, char).toHaveLength(5);
+      expect(rows, char).not.toEqual(TINY_FONT.glyphs[char.toUpperCase()]);
+      for (const row of rows) {
+        expect(row >> 3, char).toBe(0);
+      }
+    }
+  });
+
+  it("draws lowercase as capitals by default", () => {
+    const frame = createSolidFrame(64, 64);
+    drawTinyText(frame, "a", 0, 0, white);
+
+    // Top of the capital A
+    expect(getPixel(frame, 1, 0)).toEqual(white);
+  });
+
+  it("draws true lowercase with mixedCase", () => {
+    const frame = createSolidFrame(64, 64);
+    drawTinyText(frame, "a", 0, 0, white, { mixedCase: true });
+
+    // Lowercase a has nothing above the x-height
+    expect(getPixel(frame, 1, 0)).toEqual({ r: 0, g: 0, b: 0 });
+    expect(getPixel(frame, 0, 4)).toEqual(white);
+    expect(measureTinyText("ab", { mixedCase: true })).toBe(7);
+  });
+});
@@ -0,0 +1,124 @@
+/**
+ * Tests for the stock and crypto ticker page
+ */
+
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel, type Frame, type RGB } from "@signage/core";
+import {
+  formatTickerChange,
+  formatTickerPrice,
+  getTickerChangeColor,
+  renderTickerRegion,
+  TICKER_STALE_MS,
+} from "./ticker-renderer.js";
+import { COLORS } from "./colors.js";
+import type { TickerDisplayQuote } from "../ticker/types.js";
+
+const NOW = Date.UTC(2026, 9, 16, 15, 0);
+
+function quote(overrides: Partial<TickerDisplayQuote> = {}): TickerDisplayQuote {
+  return {
+    symbol: "AAPL",
+    label: "AAPL",
+    price: 187.32,
+    changePercent: 1.24,
+    timestamp: NOW,
+    history: [185, 186, 184, 187.32],
+    ...overrides,
+  };
+}
+
+function rowsWithColor(frame: Frame, color: RGB, minY: number, maxY: number): number[] {
+  const rows: number[] = [];
+  for (let y = minY; y <= maxY; y++) {
+    for (let x = 0; x < 64; x++) {
+      if (JSON.stringify(getPixel(frame, x, y)) === JSON.stringify(color)) {
+        rows.push(y);
+        break;
+      }
+    }
+  }
+  return rows;
+}
+
+describe("formatTickerPrice", () => {
+  it("keeps prices to about five characters", () => {
+    expect(formatTickerPrice(2_460_000)).toBe("2.5M");
+    expect(formatTickerPrice(67_450.12)).toBe("67450");
+    expect(formatTickerPrice(123_456)).toBe("123K");
+    expect(formatTickerPrice(187.32)).toBe("187.3");
+    expect(formatTickerPrice(1.234)).toBe("1.23");
+    expect(formatTickerPrice(0.01234)).toBe("0.012");
+  });
+});
+
+describe("formatTickerChange", () => {
+  it("signs the change with one decimal below 10%", () => {
+    expect(formatTickerChange(1.24)).toBe("+1.2%");
+    expect(formatTickerChange(-0.44)).toBe("-0.4%");
+    expect(formatTickerChange(12.6)).toBe("+13%");
+  });
+
+  it("drops the sign when the change rounds to nothing", () => {
+    expect(formatTickerChange(0.02)).toBe("0.0%");
+    expect(formatTickerChange(-0.04)).toBe("0.0%");
+  });
+});
+
+describe("getTickerChangeColor", () => {
+  it("is green up, red down and grey flat", () => {
+    expect(getTickerChangeColor(0.5)).toEqual(COLORS.tickerUp);
+    expect(getTickerChangeColor(-0.5)).toEqual(COLORS.tickerDown);
+    expect(getTickerChangeColor(0)).toEqual(COLORS.clockSecondary);
+  });
+});
+
+describe("renderTickerRegion", () => {
+  it("draws a line per quote, colored by its change", () => {
+    const frame = createSolidFrame(64, 64);
+    renderTickerRegion(
+      frame,
+      { quotes: [quote(), quote({ symbol: "coingecko:bitcoin", label: "BTC", changePercent: -2.5 })], sparkline: false },
+      7,
+      63,
+      NOW
+    );
+
+    expect(rowsWithColor(frame, COLORS.tickerUp, 7, 63)).toEqual([8, 9, 10, 11, 12]);
+    expect(rowsWithColor(frame, COLORS.tickerDown, 7, 63)).toEqual([15, 16, 17, 18, 19]);
+  });
+
+  it("draws the sparkline under each quote when enabled", () => {
+    const frame = createSolidFrame(64, 64);
+    renderTickerRegion(frame, { quotes: [quote()], sparkline: true }, 7, 63, NOW);
+
+    const rows = rowsWithColor(frame, COLORS.tickerUp, 7, 63);
+    expect(rows.filter((y) => y >= 14)).not.toHaveLength(0);
+    expect(Math.max(...rows)).toBe(19);
+  });
+
+  it("greys out quotes that haven't been refreshed in a while", () => {
+    const frame = createSolidFrame(64, 64);
+    renderTickerRegion(frame, { quotes: [quote({ timestamp: NOW - TICKER_STALE_MS })], sparkline: false }, 7, 63, NOW);
+
+    expect(rowsWithColor(frame, COLORS.tickerUp, 7, 63)).toHaveLength(0);
+    expect(rowsWithColor(frame, COLORS.stale, 7, 63)).not.toHaveLength(0);
+  });
+
+  it("leaves off quotes that don't fit", () => {
+    const frame = createSolidFrame(64, 64);
+    const quotes = Array.from({ length: 6 }, () => quote({ changePercent: -1 }));
+    renderTickerRegion(frame, { quotes, sparkline: true }, 7, 63, NOW);
+
+    // Four rows of 14 fit below the clock
+    expect(Math.max(...rowsWithColor(frame, COLORS.tickerDown, 7, 63))).toBeLessThanOrEqual(61);
+    expect(rowsWithColor(frame, COLORS.tickerDown, 50, 55)).not.toHaveLength(0);
+  });
+
+  it("says so when there are no quotes", () => {
+    const frame = createSolidFrame(64, 64);
+    renderTickerRegion(frame, { quotes: [], sparkline: false }, 7, 63, NOW);
+
+    expect(rowsWithColor(frame, COLORS.stale, 7, 63)).toEqual([9, 10, 11, 12, 13]);
+  });
+});
@@ -0,0 +1,102 @@
+/**
+ * Stock and crypto ticker page
+ *
+ * One line per symbol, with an optional sparkline of the past day beneath:
+ * ┌───────────────────────────────────────┐
+ * │ AAPL  187.3                    +1.2%  │  label, price, daily change
+ * │ ‾‾‾\__/‾‾‾‾‾\___/‾‾‾‾‾‾‾‾\_/‾‾‾‾‾‾‾  │  price history (sparkline on)
+ * └───────────────────────────────────────┘
+ * The change and sparkline are green when the price is up on the day, red
+ * when it's down.
+ */
+
+import type { Frame, RGB } from "@signage/core";
+import { drawTinyText, measureTinyText, DISPLAY_WIDTH } from "./text.js";
+import { COLORS } from "./colors.js";
+import { renderSparkline } from "./chart-renderer.js";
+import type { TickerDisplayData, TickerDisplayQuote } from "../ticker/types.js";
+
+/** Rows per symbol: text line and gap, plus the sparkline and gap when shown */
+export const TICKER_ROW_HEIGHT = 7;
+export const TICKER_SPARKLINE_ROW_HEIGHT = 14;
+
+/** Sparkline rows under the text line */
+const SPARKLINE_HEIGHT = 6;
+
+/** Prices start here, after the longest label */
+const PRICE_X = 19;
+
+/** Quotes older than this (fetches failing) are greyed out */
+export const TICKER_STALE_MS = 30 * 60 * 1000;
+
+/**
+ * Format a price in at most five characters where possible:
+ * 123K, 12345, 187.3, 1.23, 0.012
+ */
+export function formatTickerPrice(price: number): string {
+  if (price >= 1_000_000) return `${(price / 1_000_000).toFixed(1)}M`;
+  if (price >= 100_000) return `${Math.round(price / 1000)}K`;
+  if (price >= 1000) return String(Math.round(price));
+  if (price >= 100) return price.toFixed(1);
+  if (price >= 1) return price.toFixed(2);
+  return price.toPrecision(2);
+}
+
+/**
+ * Format a daily change: +1.2%, -0.4%, +12%
+ */
+export function formatTickerChange(changePercent: number): string {
+  const magnitude = Math.abs(changePercent);
+  const text = magnitude >= 10 ? String(Math.round(magnitude)) : magnitude.toFixed(1);
+  if (Number(text) === 0) return "0.0%";
+  return `${changePercent > 0 ? "+" : "-"}${text}%`;
+}
+
+/**
+ * Color for a daily change: green up, red down, grey flat
+ */
+export function getTickerChangeColor(changePercent: number): RGB {
+  if (changePercent > 0) return COLORS.tickerUp;
+  if (changePercent < 0) return COLORS.tickerDown;
+  return COLORS.clockSecondary;
+}
+
+function renderQuote(frame: Frame, quote: TickerDisplayQuote, y: number, sparkline: boolean, stale: boolean): void {
+  const changeColor = stale ? COLORS.stale : getTickerChangeColor(quote.changePercent);
+  const change = formatTickerChange(quote.changePercent);
+
+  drawTinyText(frame, quote.label, 1, y, COLORS.clockSecondary);
+  drawTinyText(frame, formatTickerPrice(quote.price), PRICE_X, y, stale ? COLORS.stale : COLORS.clockTime);
+  drawTinyText(frame, change, DISPLAY_WIDTH - 1 - measureTinyText(change), y, changeColor);
+
+  if (sparkline && quote.history.length > 1) {
+    renderSparkline(frame, quote.history, 1, y + 6, DISPLAY_WIDTH - 2, SPARKLINE_HEIGHT, changeColor);
+  }
+}
+
+/**
+ * Render the ticker page within [minY, maxY].
+ * Symbols that don't fit are left off.
+ */
+export function renderTickerRegion(
+  frame: Frame,
+  data: TickerDisplayData,
+  minY: number,
+  maxY: number,
+  now: number = Date.now()
+): void {
+  if (data.quotes.length === 0) {
+    const text = "NO QUOTES";
+    drawTinyText(frame, text, Math.floor((DISPLAY_WIDTH - measureTinyText(text)) / 2), minY + 2, COLORS.stale);
+    return;
+  }
+
+  const rowHeight = data.sparkline ? TICKER_SPARKLINE_ROW_HEIGHT : TICKER_ROW_HEIGHT;
+  let y = minY + 1;
+  for (const quote of data.quotes) {
+    // Each row ends with two blank rows
+    if (y + rowHeight - 3 > maxY) break;
+    renderQuote(frame, quote, y, data.sparkline, now - quote.timestamp >= TICKER_STALE_MS);
+    y += rowHeight;
+  }
+}
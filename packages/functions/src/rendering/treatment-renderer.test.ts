@@ -8,6 +8,8 @@ import {
   calculateTreatmentTotals,
   renderTreatmentSummary,
   renderTreatmentMarkers,
+  formatTreatmentLabel,
+  formatLastBolus,
 } from "./treatment-renderer.js";
 import type { TreatmentDisplayData, GlookoTreatment } from "../glooko/types.js";
 
@@ -273,4 +275,55 @@ describe("renderTreatmentMarkers", () => {
     const hasPixels = frame.pixels.some((v) => v !== 0);
     expect(hasPixels).toBe(true);
   });
+
+  it("draws a label below insulin markers when enabled", () => {
+    const now = Date.now();
+    const bounds = { x: 1, y: 34, width: 62, height: 30, hours: 3 };
+    const treatments: GlookoTreatment[] = [
+      { timestamp: now - 1.5 * 60 * 60 * 1000, type: "insulin", value: 4 },
+    ];
+
+    const plain = createSolidFrame(64, 64);
+    renderTreatmentMarkers(plain, treatments, bounds);
+    const labeled = createSolidFrame(64, 64);
+    renderTreatmentMarkers(labeled, treatments, { ...bounds, showLabels: true });
+
+    // Label rows sit just below the 4px marker
+    const rowHasPixels = (frame: typeof plain, y: number) =>
+      frame.pixels.subarray(y * 64 * 3, (y + 1) * 64 * 3).some((v) => v !== 0);
+    expect(rowHasPixels(plain, 38)).toBe(false);
+    expect(rowHasPixels(labeled, 38)).toBe(true);
+  });
+});
+
+describe("formatTreatmentLabel", () => {
+  it("formats insulin units and carb grams", () => {
+    expect(formatTreatmentLabel({ timestamp: 0, type: "insulin", value: 4 })).toBe("4U");
+    expect(formatTreatmentLabel({ timestamp: 0, type: "insulin", value: 2.5 })).toBe("2.5U");
+    expect(formatTreatmentLabel({ timestamp: 0, type: "carbs", value: 29.6 })).toBe("30G");
+  });
+});
+
+describe("formatLastBolus", () => {
+  const now = new Date("2026-03-02T12:00:00Z").getTime();
+  const minutesAgo = (n: number) => now - n * 60 * 1000;
+
+  it("returns null without a recent bolus", () => {
+    expect(formatLastBolus([], now)).toBeNull();
+    expect(formatLastBolus([{ timestamp: minutesAgo(30), type: "carbs", value: 20 }], now)).toBeNull();
+    expect(formatLastBolus([{ timestamp: minutesAgo(25 * 60), type: "insulin", value: 2 }], now)).toBeNull();
+  });
+
+  it("uses the most recent bolus", () => {
+    const treatments: GlookoTreatment[] = [
+      { timestamp: minutesAgo(300), type: "insulin", value: 5 },
+      { timestamp: minutesAgo(130), type: "insulin", value: 2 },
+    ];
+    expect(formatLastBolus(treatments, now)).toBe("BOLUS 2H AGO");
+  });
+
+  it("shows minutes under an hour", () => {
+    expect(formatLastBolus([{ timestamp: minutesAgo(25), type: "insulin", value: 1 }], now)).toBe("BOLUS 25M AGO");
+    expect(formatLastBolus([{ timestamp: now, type: "insulin", value: 1 }], now)).toBe("BOLUS NOW");
+  });
 });
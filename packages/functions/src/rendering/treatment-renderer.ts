@@ -4,7 +4,7 @@
 
 import type { Frame, RGB } from "@signage/core";
 import { setPixel } from "@signage/core";
-import { drawTinyText, DISPLAY_WIDTH } from "./text.js";
+import { drawTinyText, measureTinyText, DISPLAY_WIDTH } from "./text.js";
 import type { TreatmentDisplayData, GlookoTreatment } from "../glooko/types.js";
 
 // Treatment colors
@@ -46,6 +46,27 @@ export interface ChartBounds {
   hours: number;
   /** Hours offset from now (0 = ends at now) */
   offsetHours?: number;
+  /** Draw the amount next to each marker (e.g. "4U", "30G") */
+  showLabels?: boolean;
+}
+
+/** Tiny font glyph height, for label placement */
+const LABEL_HEIGHT = 5;
+
+/**
+ * Format insulin units: show decimal if not whole number, max 1 decimal place
+ */
+function formatUnits(insulinUnits: number): string {
+  return insulinUnits % 1 === 0 ? String(insulinUnits) : insulinUnits.toFixed(1);
+}
+
+/**
+ * Format a single treatment as a marker label (e.g., "4.5U", "30G")
+ */
+export function formatTreatmentLabel(treatment: GlookoTreatment): string {
+  return treatment.type === "insulin"
+    ? `${formatUnits(treatment.value)}U`
+    : `${Math.round(treatment.value)}G`;
 }
 
 /**
@@ -55,10 +76,7 @@ function formatSummary(insulinUnits: number, carbGrams: number): string {
   const parts: string[] = [];
 
   if (insulinUnits > 0) {
-    // Format insulin: show decimal if not whole number, max 1 decimal place
-    const insulinStr =
-      insulinUnits % 1 === 0 ? String(insulinUnits) : insulinUnits.toFixed(1);
-    parts.push(`${insulinStr}U`);
+    parts.push(`${formatUnits(insulinUnits)}U`);
   }
 
   if (carbGrams > 0) {
@@ -93,8 +111,8 @@ export function renderTreatmentSummary(
   const color = isStale ? COLORS.stale : COLORS.insulin;
   const text = formatSummary(recentInsulinUnits, recentCarbsGrams);
 
-  // Calculate width of tiny text (4px per char including spacing)
-  const textWidth = text.length * 4;
+  // Width of the tiny text plus its trailing 1px gap
+  const textWidth = measureTinyText(text) + 1;
 
   // Position at right edge with margin
   const x = rightEdge - textWidth - 1;
@@ -133,6 +151,8 @@ function drawMarker(
  * Render treatment markers on the chart
  * Insulin markers (▼) appear at the top of the chart
  * Carb markers (▲) appear at the bottom of the chart
+ * With showLabels, the amount is drawn just inside the chart next to each
+ * marker, skipping labels that would overlap the previous one of that type.
  *
  * @param frame The frame to render to
  * @param treatments Array of treatments to render
@@ -145,7 +165,7 @@ export function renderTreatmentMarkers(
 ): void {
   if (!treatments || treatments.length === 0) return;
 
-  const { x, y, width, height, hours, offsetHours = 0 } = bounds;
+  const { x, y, width, height, hours, offsetHours = 0, showLabels = false } = bounds;
 
   const now = Date.now();
   const endTime = now - offsetHours * 60 * 60 * 1000;
@@ -153,9 +173,12 @@ export function renderTreatmentMarkers(
   const timeRange = hours * 60 * 60 * 1000;
 
   // Filter treatments to visible time range
-  const visibleTreatments = treatments.filter(
-    (t) => t.timestamp >= startTime && t.timestamp <= endTime
-  );
+  const visibleTreatments = treatments
+    .filter((t) => t.timestamp >= startTime && t.timestamp <= endTime)
+    .sort((a, b) => a.timestamp - b.timestamp);
+
+  // Right edge of the last label drawn per type (labels must not overlap)
+  const labelEnd = { insulin: -Infinity, carbs: -Infinity };
 
   for (const treatment of visibleTreatments) {
     // Calculate X position based on timestamp
@@ -189,10 +212,62 @@ export function renderTreatmentMarkers(
           height
         );
       }
+
+      if (showLabels) {
+        const label = formatTreatmentLabel(treatment);
+        const labelWidth = measureTinyText(label);
+        // Center under/over the marker, kept inside the chart
+        const labelX = Math.max(x, Math.min(markerX - Math.floor(labelWidth / 2), x + width - labelWidth));
+        if (labelX > labelEnd[treatment.type]) {
+          const labelY = treatment.type === "insulin"
+            ? y + MARKER_HEIGHT
+            : y + height - MARKER_HEIGHT - LABEL_HEIGHT - 1;
+          const color = treatment.type === "insulin" ? COLORS.insulin : COLORS.carbs;
+          drawTinyText(frame, label, labelX, labelY, color);
+          labelEnd[treatment.type] = labelX + labelWidth;
+        }
+      }
     }
   }
 }
 
+/**
+ * Format the time since the most recent bolus (e.g., "BOLUS 2H AGO").
+ * Returns null if there was no bolus in the last 24 hours.
+ */
+export function formatLastBolus(
+  treatments: GlookoTreatment[],
+  now: number = Date.now()
+): string | null {
+  const cutoff = now - 24 * 60 * 60 * 1000;
+  let latest: number | null = null;
+  for (const treatment of treatments) {
+    if (treatment.type !== "insulin" || treatment.timestamp < cutoff || treatment.timestamp > now) continue;
+    if (latest === null || treatment.timestamp > latest) latest = treatment.timestamp;
+  }
+  if (latest === null) return null;
+
+  const minutes = Math.floor((now - latest) / 60000);
+  if (minutes < 1) return "BOLUS NOW";
+  if (minutes < 60) return `BOLUS ${minutes}M AGO`;
+  return `BOLUS ${Math.floor(minutes / 60)}H AGO`;
+}
+
+/**
+ * Render the "last bolus" status line centered at the given row.
+ * Draws nothing when there was no recent bolus.
+ */
+export function renderLastBolusStatus(
+  frame: Frame,
+  treatments: GlookoTreatment[],
+  y: number
+): void {
+  const text = formatLastBolus(treatments);
+  if (!text) return;
+  const x = Math.floor((DISPLAY_WIDTH - measureTinyText(text)) / 2);
+  drawTinyText(frame, text, x, y, COLORS.insulin);
+}
+
 /**
  * Calculate treatment totals for a time window
  *
@@ -0,0 +1,72 @@
+import { describe, it, expect } from "vitest";
+import { createSolidFrame, getPixel } from "@signage/core";
+import {
+  WEATHER_CONDITIONS,
+  WEATHER_ICON_SIZES,
+  drawWeatherIcon,
+  getWeatherIcon,
+  weatherConditionForCode,
+  weatherConditionFromHourly,
+} from "./weather-icons";
+import { spriteHeight, spriteWidth } from "./sprites";
+import { COLORS } from "./colors";
+
+describe("weather icons", () => {
+  it("has every condition at every size, square and fully colored", () => {
+    for (const size of WEATHER_ICON_SIZES) {
+      for (const condition of WEATHER_CONDITIONS) {
+        const sprite = getWeatherIcon(condition, size);
+        expect(spriteHeight(sprite)).toBe(size);
+        for (const row of sprite.rows) {
+          expect(row.length).toBe(size);
+          for (const char of row) {
+            if (char !== ".") expect(sprite.palette[char]).toBeDefined();
+          }
+        }
+      }
+      expect(spriteWidth(getWeatherIcon("clear", size))).toBe(size);
+    }
+  });
+
+  it("draws at the given position", () => {
+    const frame = createSolidFrame(64, 64);
+    drawWeatherIcon(frame, "clear", 10, 20, 16);
+
+    // The middle of the sun
+    expect(getPixel(frame, 17, 27)).toEqual(COLORS.weatherSun);
+    expect(getPixel(frame, 10, 20)).toEqual({ r: 0, g: 0, b: 0 });
+  });
+});
+
+describe("weatherConditionForCode", () => {
+  it("maps WMO codes to conditions", () => {
+    expect(weatherConditionForCode(0)).toBe("clear");
+    expect(weatherConditionForCode(2)).toBe("partlyCloudy");
+    expect(weatherConditionForCode(3)).toBe("cloudy");
+    expect(weatherConditionForCode(48)).toBe("fog");
+    expect(weatherConditionForCode(53)).toBe("rain");
+    expect(weatherConditionForCode(81)).toBe("rain");
+    expect(weatherConditionForCode(75)).toBe("snow");
+    expect(weatherConditionForCode(86)).toBe("snow");
+    expect(weatherConditionForCode(96)).toBe("thunderstorm");
+  });
+
+  it("returns null for unknown codes", () => {
+    expect(weatherConditionForCode(4)).toBeNull();
+    expect(weatherConditionForCode(100)).toBeNull();
+  });
+});
+
+describe("weatherConditionFromHourly", () => {
+  it("prefers precipitation over cloud cover", () => {
+    expect(weatherConditionFromHourly({ cloudCover: 100, precipitation: 1.2 })).toBe("rain");
+    expect(weatherConditionFromHourly({ cloudCover: 100, precipitation: 0.5, isSnow: true })).toBe("snow");
+  });
+
+  it("grades cloud cover", () => {
+    expect(weatherConditionFromHourly({ cloudCover: 90 })).toBe("cloudy");
+    expect(weatherConditionFromHourly({ cloudCover: 40, precipitation: 0.05 })).toBe("partlyCloudy");
+    expect(weatherConditionFromHourly({ cloudCover: 10 })).toBe("clear");
+    expect(weatherConditionFromHourly({})).toBe("clear");
+  });
+});
@@ -0,0 +1,319 @@
+/**
+ * Weather icons
+ *
+ * Sprites for each weather condition at 10x10 (beside text) and 16x16
+ * (standalone). Conditions come from WMO weather codes, as Open-Meteo
+ * returns them, or from the hourly cloud cover and precipitation the
+ * weather fetch keeps.
+ *
+ * Palette: S sun, C cloud, D dark cloud, R rain, W snow, B lightning, F fog
+ */
+
+import type { Frame } from "@signage/core";
+import { COLORS } from "./colors.js";
+import { drawSprite, type DrawSpriteOptions, type Sprite } from "./sprites.js";
+import type { HourlyCondition } from "./clock-renderer.js";
+
+export type WeatherCondition = "clear" | "partlyCloudy" | "cloudy" | "fog" | "rain" | "snow" | "thunderstorm";
+
+export const WEATHER_CONDITIONS: readonly WeatherCondition[] = [
+  "clear",
+  "partlyCloudy",
+  "cloudy",
+  "fog",
+  "rain",
+  "snow",
+  "thunderstorm",
+];
+
+export type WeatherIconSize = 10 | 16;
+
+export const WEATHER_ICON_SIZES: readonly WeatherIconSize[] = [10, 16];
+
+/** Cloud cover (%) from which an hour counts as partly cloudy, and as cloudy */
+const PARTLY_CLOUDY_COVER = 25;
+const CLOUDY_COVER = 85;
+
+/** Precipitation (mm) from which an hour counts as rain or snow */
+const MIN_PRECIPITATION_MM = 0.1;
+
+const PALETTE = {
+  S: COLORS.weatherSun,
+  C: COLORS.weatherCloud,
+  D: COLORS.weatherCloudDark,
+  R: COLORS.rainLight,
+  W: COLORS.weatherSnow,
+  B: COLORS.weatherBolt,
+  F: COLORS.weatherFog,
+};
+
+function icon(rows: string[]): Sprite {
+  return { rows, palette: PALETTE };
+}
+
+const ICONS: Record<WeatherIconSize, Record<WeatherCondition, Sprite>> = {
+  10: {
+    clear: icon([
+      "....SS....",
+      ".S......S.",
+      "...SSSS...",
+      "..SSSSSS..",
+      "S.SSSSSS.S",
+      "S.SSSSSS.S",
+      "..SSSSSS..",
+      "...SSSS...",
+      ".S......S.",
+      "....SS....",
+    ]),
+    partlyCloudy: icon([
+      ".S..S.....",
+      "..SSS.....",
+      "SSSSSS....",
+      ".SSSCCC...",
+      ".SSCCCCC..",
+      "..CCCCCCC.",
+      ".CCCCCCCCC",
+      ".CCCCCCCCC",
+      "..CCCCCCC.",
+      "..........",
+    ]),
+    cloudy: icon([
+      "..........",
+      "......DD..",
+      ".....DDDD.",
+      "...CCCDDDD",
+      "..CCCCCDDD",
+      ".CCCCCCCD.",
+      "CCCCCCCCC.",
+      "CCCCCCCCC.",
+      ".CCCCCCC..",
+      "..........",
+    ]),
+    fog: icon([
+      "..........",
+      ".FFFFFFF..",
+      "..........",
+      "..FFFFFFFF",
+      "..........",
+      "FFFFFFFF..",
+      "..........",
+      ".FFFFFFFF.",
+      "..........",
+      "...FFFFF..",
+    ]),
+    rain: icon([
+      "....DDD...",
+      "...DDDDD..",
+      ".DDDDDDDD.",
+      "DDDDDDDDDD",
+      "DDDDDDDDDD",
+      ".DDDDDDDD.",
+      "..R..R..R.",
+      ".R..R..R..",
+      "...R..R...",
+      "..R..R....",
+    ]),
+    snow: icon([
+      "....DDD...",
+      "...DDDDD..",
+      ".DDDDDDDD.",
+      "DDDDDDDDDD",
+      "DDDDDDDDDD",
+      ".DDDDDDDD.",
+      ".W...W....",
+      "...W...W..",
+      ".W...W....",
+      "...W...W..",
+    ]),
+    thunderstorm: icon([
+      "....DDD...",
+      "...DDDDD..",
+      ".DDDDDDDD.",
+      "DDDDDDDDDD",
+      "DDDDDDDDDD",
+      ".DDDBBDDD.",
+      "...BB.....",
+      "..BBBB....",
+      "....B.....",
+      "...B......",
+    ]),
+  },
+  16: {
+    clear: icon([
+      ".......SS.......",
+      ".......SS.......",
+      "..S..........S..",
+      "...S..SSSS..S...",
+      ".....SSSSSS.....",
+      "....SSSSSSSS....",
+      "....SSSSSSSS....",
+      "SS..SSSSSSSS..SS",
+      "SS..SSSSSSSS..SS",
+      "....SSSSSSSS....",
+      "....SSSSSSSS....",
+      ".....SSSSSS.....",
+      "...S..SSSS..S...",
+      "..S..........S..",
+      ".......SS.......",
+      ".......SS.......",
+    ]),
+    partlyCloudy: icon([
+      ".....S..........",
+      "..S.......S.....",
+      "....SSSS........",
+      "...SSSSSS.......",
+      "..SSSSSSSS......",
+      "S.SSSSSSSS.S....",
+      "..SSSSSCCCC.....",
+      "..SSSSCCCCCC....",
+      "...SSCCCCCCCCC..",
+      "..S.CCCCCCCCCCC.",
+      "...CCCCCCCCCCCCC",
+      "..CCCCCCCCCCCCCC",
+      "..CCCCCCCCCCCCCC",
+      "...CCCCCCCCCCCC.",
+      "................",
+      "................",
+    ]),
+    cloudy: icon([
+      "................",
+      "................",
+      ".........DDDD...",
+      "........DDDDDD..",
+      "......DDDDDDDDD.",
+      ".....CCCCDDDDDDD",
+      "....CCCCCCDDDDDD",
+      "..CCCCCCCCCCDDD.",
+      ".CCCCCCCCCCCCD..",
+      "CCCCCCCCCCCCCC..",
+      "CCCCCCCCCCCCCC..",
+      "CCCCCCCCCCCCCC..",
+      ".CCCCCCCCCCCC...",
+      "................",
+      "................",
+      "................",
+    ]),
+    fog: icon([
+      "................",
+      "................",
+      "..FFFFFFFFFF....",
+      "................",
+      "....FFFFFFFFFFF.",
+      "................",
+      ".FFFFFFFFFFFF...",
+      "................",
+      "...FFFFFFFFFFFF.",
+      "................",
+      ".FFFFFFFFFFF....",
+      "................",
+      "....FFFFFFFFF...",
+      "................",
+      "................",
+      "................",
+    ]),
+    rain: icon([
+      "................",
+      "......DDDD......",
+      ".....DDDDDD.....",
+      "...DDDDDDDDD....",
+      "..DDDDDDDDDDDD..",
+      ".DDDDDDDDDDDDDD.",
+      "DDDDDDDDDDDDDDDD",
+      "DDDDDDDDDDDDDDDD",
+      ".DDDDDDDDDDDDDD.",
+      "................",
+      "...R....R....R..",
+      "..R....R....R...",
+      "................",
+      ".R....R....R....",
+      "R....R....R.....",
+      "................",
+    ]),
+    snow: icon([
+      "................",
+      "......DDDD......",
+      ".....DDDDDD.....",
+      "...DDDDDDDDD....",
+      "..DDDDDDDDDDDD..",
+      ".DDDDDDDDDDDDDD.",
+      "DDDDDDDDDDDDDDDD",
+      "DDDDDDDDDDDDDDDD",
+      ".DDDDDDDDDDDDDD.",
+      "................",
+      "..W....W....W...",
+      ".WWW..WWW..WWW..",
+      "..W....W....W...",
+      "....W....W......",
+      "...WWW..WWW.....",
+      "....W....W......",
+    ]),
+    thunderstorm: icon([
+      "......DDDD......",
+      ".....DDDDDD.....",
+      "...DDDDDDDDD....",
+      "..DDDDDDDDDDDD..",
+      ".DDDDDDDDDDDDDD.",
+      "DDDDDDDDDDDDDDDD",
+      "DDDDDDDDDDDDDDDD",
+      ".DDDDDDBBBDDDDD.",
+      "......BBB.......",
+      ".....BBB........",
+      "....BBBBBB......",
+      ".......BB.......",
+      "......BB........",
+      ".....BB.........",
+      ".....B..........",
+      "................",
+    ]),
+  },
+};
+
+/**
+ * Condition for a WMO weather interpretation code (Open-Meteo's
+ * `weathercode`), or null for codes it doesn't use
+ */
+export function weatherConditionForCode(code: number): WeatherCondition | null {
+  if (code === 0 || code === 1) return "clear";
+  if (code === 2) return "partlyCloudy";
+  if (code === 3) return "cloudy";
+  if (code === 45 || code === 48) return "fog";
+  // Drizzle, freezing drizzle, rain, freezing rain, showers
+  if ((code >= 51 && code <= 67) || (code >= 80 && code <= 82)) return "rain";
+  // Snow, snow grains, snow showers
+  if ((code >= 71 && code <= 77) || code === 85 || code === 86) return "snow";
+  // Thunderstorm, with or without hail
+  if (code >= 95 && code <= 99) return "thunderstorm";
+  return null;
+}
+
+/**
+ * Condition for an hour of the stored forecast. Fog and thunderstorms
+ * can't be told from cloud cover and precipitation, so they don't come up.
+ */
+export function weatherConditionFromHourly(hour: HourlyCondition): WeatherCondition {
+  if ((hour.precipitation ?? 0) >= MIN_PRECIPITATION_MM) {
+    return hour.isSnow ? "snow" : "rain";
+  }
+  const cover = hour.cloudCover ?? 0;
+  if (cover >= CLOUDY_COVER) return "cloudy";
+  if (cover >= PARTLY_CLOUDY_COVER) return "partlyCloudy";
+  return "clear";
+}
+
+export function getWeatherIcon(condition: WeatherCondition, size: WeatherIconSize = 16): Sprite {
+  return ICONS[size][condition];
+}
+
+/**
+ * Draw a condition's icon with its top-left corner at (x, y)
+ */
+export function drawWeatherIcon(
+  frame: Frame,
+  condition: WeatherCondition,
+  x: number,
+  y: number,
+  size: WeatherIconSize = 16,
+  options?: DrawSpriteOptions
+): void {
+  drawSprite(frame, getWeatherIcon(condition, size), x, y, options);
+}
@@ -0,0 +1,87 @@
+/**
+ * Tests for wall-clock time across daylight-saving transitions
+ */
+
+import { describe, it, expect } from "vitest";
+import { isTimezone, wallTime, zoneOffsetMs, zonedTimestamp, startOfZonedDay } from "./zoned-time.js";
+
+const LA = "America/Los_Angeles";
+const HOUR = 60 * 60 * 1000;
+
+describe("wallTime", () => {
+  it("reads the local date and time in the zone", () => {
+    const ts = new Date("2026-03-08T01:59:00-08:00").getTime();
+    expect(wallTime(ts, LA)).toEqual({ year: 2026, month: 3, day: 8, hour: 1, minute: 59, second: 0 });
+  });
+
+  it("reports midnight as hour 0", () => {
+    const ts = new Date("2026-06-01T00:00:00-07:00").getTime();
+    expect(wallTime(ts, LA).hour).toBe(0);
+  });
+});
+
+describe("zoneOffsetMs", () => {
+  it("follows the zone's daylight-saving rules", () => {
+    expect(zoneOffsetMs(new Date("2026-01-15T12:00:00Z").getTime(), LA)).toBe(-8 * HOUR);
+    expect(zoneOffsetMs(new Date("2026-07-15T12:00:00Z").getTime(), LA)).toBe(-7 * HOUR);
+    expect(zoneOffsetMs(new Date("2026-07-15T12:00:00Z").getTime(), "Asia/Kolkata")).toBe(5.5 * HOUR);
+  });
+});
+
+describe("zonedTimestamp", () => {
+  it("resolves wall times on either side of spring forward", () => {
+    expect(zonedTimestamp(2026, 3, 8, 1, 30, LA)).toBe(new Date("2026-03-08T01:30:00-08:00").getTime());
+    expect(zonedTimestamp(2026, 3, 8, 6, 0, LA)).toBe(new Date("2026-03-08T06:00:00-07:00").getTime());
+  });
+
+  it("moves wall times skipped by spring forward past the gap", () => {
+    expect(zonedTimestamp(2026, 3, 8, 2, 30, LA)).toBe(new Date("2026-03-08T03:30:00-07:00").getTime());
+  });
+
+  it("picks the first occurrence of a wall time repeated by fall back", () => {
+    expect(zonedTimestamp(2026, 11, 1, 1, 30, LA)).toBe(new Date("2026-11-01T01:30:00-07:00").getTime());
+    expect(zonedTimestamp(2026, 11, 1, 6, 0, LA)).toBe(new Date("2026-11-01T06:00:00-08:00").getTime());
+  });
+
+  it("rolls over out-of-range days like Date.UTC", () => {
+    expect(zonedTimestamp(2026, 3, 0, 0, 0, LA)).toBe(new Date("2026-02-28T00:00:00-08:00").getTime());
+  });
+
+  it("handles zones with other transition dates and half-hour offsets", () => {
+    // Europe switches on the last Sunday of March, weeks after the US
+    expect(zonedTimestamp(2026, 3, 29, 6, 0, "Europe/Berlin")).toBe(
+      new Date("2026-03-29T06:00:00+02:00").getTime()
+    );
+    expect(zonedTimestamp(2026, 3, 29, 0, 0, "Europe/Berlin")).toBe(
+      new Date("2026-03-29T00:00:00+01:00").getTime()
+    );
+    expect(zonedTimestamp(2026, 3, 8, 18, 0, "Asia/Kolkata")).toBe(
+      new Date("2026-03-08T18:00:00+05:30").getTime()
+    );
+  });
+});
+
+describe("startOfZonedDay", () => {
+  it("spans 23 hours on the spring-forward day", () => {
+    const start = startOfZonedDay(new Date("2026-03-08T12:00:00-07:00").getTime(), LA);
+    const next = startOfZonedDay(new Date("2026-03-09T12:00:00-07:00").getTime(), LA);
+    expect(start).toBe(new Date("2026-03-08T00:00:00-08:00").getTime());
+    expect(next - start).toBe(23 * HOUR);
+  });
+
+  it("spans 25 hours on the fall-back day", () => {
+    const start = startOfZonedDay(new Date("2026-11-01T12:00:00-08:00").getTime(), LA);
+    const next = startOfZonedDay(new Date("2026-11-02T12:00:00-08:00").getTime(), LA);
+    expect(start).toBe(new Date("2026-11-01T00:00:00-07:00").getTime());
+    expect(next - start).toBe(25 * HOUR);
+  });
+});
+
+describe("isTimezone", () => {
+  it("accepts IANA zones and rejects anything else", () => {
+    expect(isTimezone("Europe/Berlin")).toBe(true);
+    expect(isTimezone("UTC")).toBe(true);
+    expect(isTimezone("Mars/Olympus_Mons")).toBe(false);
+    expect(isTimezone("")).toBe(false);
+  });
+});
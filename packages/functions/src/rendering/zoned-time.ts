@@ -0,0 +1,118 @@
+/**
+ * Wall-clock time in an IANA timezone
+ *
+ * Lambda runs in UTC, so "6am local" or "midnight local" can't be found by
+ * subtracting hours from now: on daylight-saving days the local day is 23 or
+ * 25 hours long and the offset changes part way through. These helpers go
+ * through the zone's own rules (via Intl) instead.
+ */
+
+/** Timezone used until one is configured */
+export const DEFAULT_TIMEZONE = "America/Los_Angeles";
+
+export interface WallTime {
+  year: number;
+  /** 1-12 */
+  month: number;
+  day: number;
+  /** 0-23 */
+  hour: number;
+  minute: number;
+  second: number;
+}
+
+const formatters = new Map<string, Intl.DateTimeFormat>();
+
+function getFormatter(timezone: string): Intl.DateTimeFormat {
+  let formatter = formatters.get(timezone);
+  if (!formatter) {
+    formatter = new Intl.DateTimeFormat("en-US", {
+      timeZone: timezone,
+      year: "numeric",
+      month: "2-digit",
+      day: "2-digit",
+      hour: "2-digit",
+      minute: "2-digit",
+      second: "2-digit",
+      hourCycle: "h23",
+    });
+    formatters.set(timezone, formatter);
+  }
+  return formatter;
+}
+
+/**
+ * Check if a name is an IANA timezone this runtime knows, e.g. "Europe/Berlin"
+ */
+export function isTimezone(name: string): boolean {
+  try {
+    getFormatter(name);
+    return true;
+  } catch {
+    return false;
+  }
+}
+
+/**
+ * Get the wall-clock date and time of a timestamp in a timezone
+ */
+export function wallTime(timestamp: number, timezone: string): WallTime {
+  const parts = getFormatter(timezone).formatToParts(new Date(timestamp));
+  const get = (type: string) => parseInt(parts.find((p) => p.type === type)?.value || "0", 10);
+  return {
+    year: get("year"),
+    month: get("month"),
+    day: get("day"),
+    hour: get("hour") % 24,
+    minute: get("minute"),
+    second: get("second"),
+  };
+}
+
+/**
+ * Get a timezone's offset from UTC at a timestamp, in ms (positive east of UTC)
+ */
+export function zoneOffsetMs(timestamp: number, timezone: string): number {
+  const wall = wallTime(timestamp, timezone);
+  const wallAsUtc = Date.UTC(wall.year, wall.month - 1, wall.day, wall.hour, wall.minute, wall.second);
+  return wallAsUtc - (timestamp - (((timestamp % 1000) + 1000) % 1000));
+}
+
+/**
+ * Get the timestamp of a wall-clock time in a timezone.
+ *
+ * Out-of-range fields roll over like Date.UTC (day 0 is the last day of the
+ * previous month). A time skipped by a spring-forward gap resolves to the
+ * same distance after the gap; a time repeated by a fall-back resolves to
+ * its first occurrence.
+ */
+export function zonedTimestamp(
+  year: number,
+  month: number,
+  day: number,
+  hour: number,
+  minute: number,
+  timezone: string
+): number {
+  const wallAsUtc = Date.UTC(year, month - 1, day, hour, minute);
+  // The offset before the wall time is the one in effect for it, unless a
+  // transition lands in between; checking either side of it settles which
+  const before = wallAsUtc - zoneOffsetMs(wallAsUtc - 24 * 60 * 60 * 1000, timezone);
+  const after = wallAsUtc - zoneOffsetMs(wallAsUtc + 24 * 60 * 60 * 1000, timezone);
+  const candidates = [before, after].filter(
+    (candidate) => candidate + zoneOffsetMs(candidate, timezone) === wallAsUtc
+  );
+  if (candidates.length > 0) {
+    return Math.min(...candidates);
+  }
+  // In a spring-forward gap: keep the distance from the wall time before it
+  return before;
+}
+
+/**
+ * Get the timestamp of local midnight starting the day that contains a timestamp
+ */
+export function startOfZonedDay(timestamp: number, timezone: string): number {
+  const wall = wallTime(timestamp, timezone);
+  return zonedTimestamp(wall.year, wall.month, wall.day, 0, 0, timezone);
+}
@@ -0,0 +1,39 @@
+import { describe, it, expect } from "vitest";
+import { isBackInRange, coversTirDay, matchSceneRule, type SceneRule } from "./events";
+
+const HOUR = 60 * 60 * 1000;
+const now = Date.UTC(2026, 9, 16, 12);
+
+describe("isBackInRange", () => {
+  it("fires when the latest reading returns to range", () => {
+    expect(isBackInRange([{ timestamp: now - 5 * 60000, glucose: 185 }, { timestamp: now, glucose: 175 }])).toBe(true);
+    expect(isBackInRange([{ timestamp: now, glucose: 72 }, { timestamp: now - 5 * 60000, glucose: 66 }])).toBe(true);
+  });
+
+  it("ignores readings that stay in or out of range", () => {
+    expect(isBackInRange([{ timestamp: now - 5 * 60000, glucose: 120 }, { timestamp: now, glucose: 125 }])).toBe(false);
+    expect(isBackInRange([{ timestamp: now - 5 * 60000, glucose: 175 }, { timestamp: now, glucose: 185 }])).toBe(false);
+    expect(isBackInRange([{ timestamp: now, glucose: 120 }])).toBe(false);
+  });
+});
+
+describe("coversTirDay", () => {
+  it("needs most of a day of history", () => {
+    expect(coversTirDay([{ timestamp: now - 21 * HOUR, glucose: 120 }, { timestamp: now, glucose: 120 }], now)).toBe(true);
+    expect(coversTirDay([{ timestamp: now - 6 * HOUR, glucose: 120 }, { timestamp: now, glucose: 120 }], now)).toBe(false);
+    expect(coversTirDay([], now)).toBe(false);
+  });
+});
+
+describe("matchSceneRule", () => {
+  const rules: SceneRule[] = [
+    { event: "tirRecord", scene: "confetti" },
+    { event: "backInRange", scene: "sweep" },
+  ];
+
+  it("returns the first rule for any of the events", () => {
+    expect(matchSceneRule(rules, ["backInRange"])).toEqual(rules[1]);
+    expect(matchSceneRule(rules, ["backInRange", "tirRecord"])).toEqual(rules[0]);
+    expect(matchSceneRule(rules, [])).toBeNull();
+  });
+});
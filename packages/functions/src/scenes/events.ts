@@ -0,0 +1,62 @@
+/**
+ * Scene events
+ *
+ * Data events that can trigger a short scene (see createSceneFrames in
+ * @signage/core), and the rules mapping events to scenes. Pure functions -
+ * the compositor checks for events each minute and plays the first matching
+ * rule's scene instead of the usual transition.
+ */
+
+import type { RGB, SceneType } from "@signage/core";
+import type { GlucoseSample } from "../alerts/types.js";
+import { classifyRange } from "../rendering/blood-sugar-renderer.js";
+import { COLORS } from "../rendering/colors.js";
+
+/** Events a scene rule can react to */
+export type SceneEvent = "backInRange" | "tirRecord";
+
+export const SCENE_EVENTS: SceneEvent[] = ["backInRange", "tirRecord"];
+
+/**
+ * Play `scene` when `event` happens
+ */
+export interface SceneRule {
+  event: SceneEvent;
+  scene: SceneType;
+}
+
+/** Sweep color for each event */
+export const SCENE_EVENT_COLORS: Record<SceneEvent, RGB> = {
+  backInRange: COLORS.normal,
+  tirRecord: { r: 255, g: 200, b: 0 },
+};
+
+/** History a 24h time in range needs to count toward a record */
+export const MIN_TIR_HISTORY_HOURS = 20;
+
+/**
+ * Check if the latest reading brought glucose back into range: in range now,
+ * out of range on the reading before it
+ */
+export function isBackInRange(samples: GlucoseSample[]): boolean {
+  if (samples.length < 2) return false;
+  const [previous, latest] = [...samples].sort((a, b) => a.timestamp - b.timestamp).slice(-2);
+  return classifyRange(latest.glucose) === "normal" && classifyRange(previous.glucose) !== "normal";
+}
+
+/**
+ * Check if history covers enough of the day for its time in range to be
+ * compared with past days
+ */
+export function coversTirDay(samples: GlucoseSample[], now: number = Date.now()): boolean {
+  if (samples.length === 0) return false;
+  const oldest = Math.min(...samples.map((s) => s.timestamp));
+  return now - oldest >= MIN_TIR_HISTORY_HOURS * 60 * 60 * 1000;
+}
+
+/**
+ * First rule matching one of the events, or null
+ */
+export function matchSceneRule(rules: SceneRule[], events: SceneEvent[]): SceneRule | null {
+  return rules.find((rule) => events.includes(rule.event)) ?? null;
+}
@@ -0,0 +1,72 @@
+/**
+ * Scene event store
+ * Tracks when each event's scene last played, so a crossing seen on several
+ * compositor runs plays once, and the best 24h time in range so far.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, UpdateCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { SceneEvent } from "./events.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** Partition for scene event state */
+const SCENE_PK = "SCENE_EVENTS";
+
+/** DynamoDB key for the best time in range */
+const TIR_BEST_KEY = { pk: SCENE_PK, sk: "TIR_BEST" };
+
+/** Minimum time between two plays of the same event's scene */
+export const SCENE_COOLDOWN_MS = 30 * 60 * 1000;
+
+/**
+ * Claim the right to play an event's scene. Returns false if it played
+ * within the cooldown.
+ */
+export async function claimScene(event: SceneEvent, now: number = Date.now()): Promise<boolean> {
+  try {
+    await ddb.send(
+      new UpdateCommand({
+        TableName: Resource.SignageTable.name,
+        Key: { pk: SCENE_PK, sk: `PLAYED#${event}` },
+        UpdateExpression: "SET playedAt = :now",
+        ConditionExpression: "attribute_not_exists(playedAt) OR playedAt <= :cutoff",
+        ExpressionAttributeValues: { ":now": now, ":cutoff": now - SCENE_COOLDOWN_MS },
+      })
+    );
+    return true;
+  } catch (error: unknown) {
+    if ((error as { name?: string }).name !== "ConditionalCheckFailedException") {
+      throw error;
+    }
+    return false;
+  }
+}
+
+/**
+ * Store a time in range if it beats the best so far.
+ * Returns true when it's a new record; the first value stored only sets the
+ * baseline.
+ */
+export async function recordTirBest(tir: number, now: number = Date.now()): Promise<boolean> {
+  try {
+    const result = await ddb.send(
+      new UpdateCommand({
+        TableName: Resource.SignageTable.name,
+        Key: TIR_BEST_KEY,
+        UpdateExpression: "SET best = :tir, achievedAt = :now",
+        ConditionExpression: "attribute_not_exists(best) OR best < :tir",
+        ExpressionAttributeValues: { ":tir": tir, ":now": now },
+        ReturnValues: "UPDATED_OLD",
+      })
+    );
+    return result.Attributes?.best !== undefined;
+  } catch (error: unknown) {
+    if ((error as { name?: string }).name !== "ConditionalCheckFailedException") {
+      throw error;
+    }
+    return false;
+  }
+}
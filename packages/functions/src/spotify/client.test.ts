@@ -0,0 +1,105 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { createSpotifyClient, nowPlayingProgress, parseNowPlaying } from "./client";
+
+const NOW = Date.UTC(2026, 9, 16, 15, 0);
+const CREDENTIALS = { clientId: "id", clientSecret: "secret", refreshToken: "refresh" };
+
+const TRACK_RESPONSE = {
+  is_playing: true,
+  progress_ms: 30_000,
+  currently_playing_type: "track",
+  item: { name: "Song", duration_ms: 180_000, artists: [{ name: "Band" }, { name: "Guest" }] },
+};
+
+describe("parseNowPlaying", () => {
+  it("reads a track with its artists", () => {
+    expect(parseNowPlaying(TRACK_RESPONSE, NOW)).toEqual({
+      title: "Song",
+      artist: "Band, Guest",
+      durationMs: 180_000,
+      progressMs: 30_000,
+      isPlaying: true,
+      fetchedAt: NOW,
+    });
+  });
+
+  it("uses the show name for a podcast episode", () => {
+    const nowPlaying = parseNowPlaying(
+      {
+        ...TRACK_RESPONSE,
+        currently_playing_type: "episode",
+        item: { name: "Ep 12", duration_ms: 1000, show: { name: "Pod" } },
+      },
+      NOW
+    );
+    expect(nowPlaying?.artist).toBe("Pod");
+  });
+
+  it("returns null for ads and empty items", () => {
+    expect(parseNowPlaying({ currently_playing_type: "ad", item: null }, NOW)).toBeNull();
+  });
+});
+
+describe("nowPlayingProgress", () => {
+  const nowPlaying = parseNowPlaying(TRACK_RESPONSE, NOW)!;
+
+  it("runs on from the fetched position while playing, up to the end", () => {
+    expect(nowPlayingProgress(nowPlaying, NOW + 10_000)).toBe(40_000);
+    expect(nowPlayingProgress(nowPlaying, NOW + 600_000)).toBe(180_000);
+  });
+
+  it("holds while paused", () => {
+    expect(nowPlayingProgress({ ...nowPlaying, isPlaying: false }, NOW + 10_000)).toBe(30_000);
+  });
+});
+
+describe("createSpotifyClient", () => {
+  const originalFetch = global.fetch;
+  const mockFetch = vi.fn();
+
+  beforeEach(() => {
+    mockFetch.mockReset();
+    global.fetch = mockFetch as unknown as typeof fetch;
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  function tokenResponse() {
+    return { ok: true, status: 200, json: async () => ({ access_token: "access", expires_in: 3600 }) };
+  }
+
+  it("refreshes a token, then reads the current playback with it", async () => {
+    mockFetch
+      .mockResolvedValueOnce(tokenResponse())
+      .mockResolvedValueOnce({ ok: true, status: 200, json: async () => TRACK_RESPONSE });
+
+    const nowPlaying = await createSpotifyClient(CREDENTIALS).getNowPlaying(NOW);
+
+    expect(nowPlaying?.title).toBe("Song");
+    const [tokenUrl, tokenInit] = mockFetch.mock.calls[0];
+    expect(tokenUrl).toBe("https://accounts.spotify.com/api/token");
+    expect(tokenInit.headers.Authorization).toBe(`Basic ${Buffer.from("id:secret").toString("base64")}`);
+    expect(tokenInit.body).toContain("refresh_token=refresh");
+    expect(mockFetch.mock.calls[1][1].headers.Authorization).toBe("Bearer access");
+  });
+
+  it("reuses the token until it's about to expire", async () => {
+    mockFetch
+      .mockResolvedValueOnce(tokenResponse())
+      .mockResolvedValue({ ok: true, status: 204, json: async () => ({}) });
+    const client = createSpotifyClient(CREDENTIALS);
+
+    expect(await client.getNowPlaying(NOW)).toBeNull();
+    await client.getNowPlaying(NOW + 30 * 60_000);
+
+    expect(mockFetch).toHaveBeenCalledTimes(3);
+  });
+
+  it("throws when Spotify fails", async () => {
+    mockFetch.mockResolvedValueOnce({ ok: false, status: 400, json: async () => ({}) });
+
+    await expect(createSpotifyClient(CREDENTIALS).getNowPlaying(NOW)).rejects.toThrow(/token refresh/);
+  });
+});
@@ -0,0 +1,107 @@
+/**
+ * Spotify now-playing client
+ *
+ * Reads the account's current playback with a refresh token, so there's no
+ * login flow at runtime: the token is got once (`pnpm spotify-auth`) and kept
+ * as a secret. Access tokens last an hour and are reused until shortly
+ * before they expire.
+ */
+
+import type { NowPlaying, SpotifyCredentials, SpotifyCurrentlyPlayingResponse } from "./types.js";
+
+const SPOTIFY_TOKEN_URL = "https://accounts.spotify.com/api/token";
+const SPOTIFY_CURRENTLY_PLAYING_URL = "https://api.spotify.com/v1/me/player/currently-playing?additional_types=episode";
+
+/** Scope the refresh token needs */
+export const SPOTIFY_SCOPE = "user-read-currently-playing";
+
+// Refresh the access token this long before it expires
+const TOKEN_EXPIRY_BUFFER_MS = 60 * 1000;
+
+/**
+ * Reads the current playback for one account
+ */
+export interface SpotifyClient {
+  /** What's playing (or paused), or null when nothing is. Throws if Spotify can't be reached. */
+  getNowPlaying(now?: number): Promise<NowPlaying | null>;
+}
+
+/**
+ * Parse the currently-playing response. Returns null for ads, unknown items
+ * and anything without a title.
+ */
+export function parseNowPlaying(response: SpotifyCurrentlyPlayingResponse, now: number): NowPlaying | null {
+  const item = response.item;
+  if (!item?.name || typeof item.duration_ms !== "number") return null;
+
+  const artist =
+    response.currently_playing_type === "episode"
+      ? (item.show?.name ?? "")
+      : (item.artists ?? [])
+          .map((a) => a.name)
+          .filter(Boolean)
+          .join(", ");
+  return {
+    title: item.name,
+    artist,
+    durationMs: item.duration_ms,
+    progressMs: response.progress_ms ?? 0,
+    isPlaying: response.is_playing === true,
+    fetchedAt: now,
+  };
+}
+
+/**
+ * Playback position at `now`: it runs on from the fetched position while
+ * playing, up to the end of the track
+ */
+export function nowPlayingProgress(nowPlaying: NowPlaying, now: number): number {
+  if (!nowPlaying.isPlaying) return nowPlaying.progressMs;
+  return Math.min(nowPlaying.durationMs, nowPlaying.progressMs + Math.max(0, now - nowPlaying.fetchedAt));
+}
+
+/**
+ * Create a client for an account. The access token is cached in the client,
+ * so keep one around (a warm Lambda reuses it).
+ */
+export function createSpotifyClient(credentials: SpotifyCredentials): SpotifyClient {
+  let token: { value: string; expiresAt: number } | null = null;
+
+  async function accessToken(now: number): Promise<string> {
+    if (token && now < token.expiresAt - TOKEN_EXPIRY_BUFFER_MS) return token.value;
+
+    const basic = Buffer.from(`${credentials.clientId}:${credentials.clientSecret}`).toString("base64");
+    const response = await fetch(SPOTIFY_TOKEN_URL, {
+      method: "POST",
+      headers: {
+        Authorization: `Basic ${basic}`,
+        "Content-Type": "application/x-www-form-urlencoded",
+      },
+      body: new URLSearchParams({ grant_type: "refresh_token", refresh_token: credentials.refreshToken }).toString(),
+    });
+    if (!response.ok) {
+      throw new Error(`Spotify token refresh failed: ${response.status}`);
+    }
+    const data = (await response.json()) as { access_token: string; expires_in: number };
+    token = { value: data.access_token, expiresAt: now + data.expires_in * 1000 };
+    return token.value;
+  }
+
+  return {
+    async getNowPlaying(now = Date.now()) {
+      const response = await fetch(SPOTIFY_CURRENTLY_PLAYING_URL, {
+        headers: { Authorization: `Bearer ${await accessToken(now)}` },
+      });
+      if (response.status === 401) {
+        // Revoked early; get a new one next time
+        token = null;
+      }
+      // No content: nothing is playing
+      if (response.status === 204) return null;
+      if (!response.ok) {
+        throw new Error(`Spotify currently-playing failed: ${response.status}`);
+      }
+      return parseNowPlaying((await response.json()) as SpotifyCurrentlyPlayingResponse, now);
+    },
+  };
+}
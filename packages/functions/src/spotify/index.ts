@@ -0,0 +1,6 @@
+/**
+ * Spotify now playing, for the local server
+ */
+
+export * from "./client.js";
+export * from "./types.js";
@@ -0,0 +1,44 @@
+/**
+ * Spotify now-playing types
+ */
+
+/**
+ * App credentials and the account's refresh token
+ */
+export interface SpotifyCredentials {
+  clientId: string;
+  clientSecret: string;
+  refreshToken: string;
+}
+
+/**
+ * What's playing, as of `fetchedAt`
+ */
+export interface NowPlaying {
+  /** Track or episode name */
+  title: string;
+  /** Artists, or the podcast's name */
+  artist: string;
+  durationMs: number;
+  /** Position when fetched */
+  progressMs: number;
+  /** False while paused */
+  isPlaying: boolean;
+  /** When it was fetched (ms) */
+  fetchedAt: number;
+}
+
+/**
+ * Response from the currently-playing endpoint (the fields used)
+ */
+export interface SpotifyCurrentlyPlayingResponse {
+  is_playing?: boolean;
+  progress_ms?: number | null;
+  currently_playing_type?: string;
+  item?: {
+    name?: string;
+    duration_ms?: number;
+    artists?: Array<{ name?: string }>;
+    show?: { name?: string };
+  } | null;
+}
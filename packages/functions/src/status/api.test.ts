@@ -0,0 +1,62 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockCollect } = vi.hoisted(() => ({
+  mockCollect: vi.fn(),
+}));
+
+vi.mock("./store.js", () => ({
+  collectStatus: mockCollect,
+}));
+
+import { handler } from "./api";
+
+function createEvent(method: string, query?: Record<string, string>): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    queryStringParameters: query,
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  return (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+}
+
+const SUMMARY = {
+  generatedAt: 0,
+  devices: [],
+  lastFrameAt: null,
+  compositor: null,
+  glucose: null,
+  lock: { locked: false },
+  sources: [],
+  store: null,
+};
+
+describe("status API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockCollect.mockResolvedValue(SUMMARY);
+  });
+
+  it("returns the summary as JSON", async () => {
+    const result = await invoke(createEvent("GET"));
+
+    expect(result.statusCode).toBe(200);
+    expect(JSON.parse(result.body as string)).toEqual(SUMMARY);
+  });
+
+  it("returns plain text with format=text", async () => {
+    const result = await invoke(createEvent("GET", { format: "text" }));
+
+    expect(result.statusCode).toBe(200);
+    expect(result.headers?.["Content-Type"]).toMatch(/text\/plain/);
+    expect(result.body).toContain("SIGNAGE STATUS");
+    expect(result.body).toContain("Devices    none connected");
+  });
+
+  it("rejects other methods", async () => {
+    const result = await invoke(createEvent("POST"));
+    expect(result.statusCode).toBe(405);
+  });
+});
@@ -0,0 +1,44 @@
+/**
+ * Status API
+ *
+ * GET /status             - JSON summary: devices, last frame, glucose,
+ *                           alerts, lock, source freshness, store size
+ * GET /status?format=text - the same as a one-screen plain-text report
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { collectStatus } from "./store.js";
+import { formatStatus } from "./format.js";
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+  if (method !== "GET") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  const summary = await collectStatus();
+
+  if (event.queryStringParameters?.format === "text") {
+    return {
+      statusCode: 200,
+      headers: {
+        "Content-Type": "text/plain; charset=utf-8",
+        "Access-Control-Allow-Origin": "*",
+      },
+      body: formatStatus(summary) + "\n",
+    };
+  }
+
+  return json(200, summary);
+};
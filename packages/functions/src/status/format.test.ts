@@ -0,0 +1,134 @@
+import { describe, it, expect } from "vitest";
+import { formatAge, formatBytes, formatStatus } from "./format";
+import type { StatusSummary } from "./types";
+
+const NOW = new Date("2026-03-02T12:00:00Z").getTime();
+const MIN = 60 * 1000;
+
+function summary(overrides: Partial<StatusSummary> = {}): StatusSummary {
+  return {
+    generatedAt: NOW,
+    devices: [
+      {
+        connectionId: "abc",
+        terminalId: "living-room",
+        terminalType: "pixoo64",
+        connectedAt: new Date(NOW - 90 * MIN).toISOString(),
+      },
+    ],
+    lastFrameAt: NOW - 40 * 1000,
+    compositor: {
+      updatedAt: NOW - 40 * 1000,
+      layout: "day",
+      locked: false,
+      alerts: [],
+      broadcast: { success: 1, failed: 0, cleaned: 0 },
+      connections: 1,
+    },
+    glucose: { value: 142, trend: "Flat", timestamp: NOW - 3 * MIN },
+    lock: { locked: false },
+    sources: [
+      { name: "dexcom", updatedAt: NOW - MIN },
+      { name: "glooko", updatedAt: NOW - 48 * MIN },
+    ],
+    store: { itemCount: 12345, sizeBytes: 4.2 * 1024 * 1024 },
+    ...overrides,
+  };
+}
+
+describe("formatAge", () => {
+  it("picks the coarsest useful unit", () => {
+    expect(formatAge(42 * 1000)).toBe("42s");
+    expect(formatAge(7 * MIN)).toBe("7m");
+    expect(formatAge(125 * MIN)).toBe("2h 5m");
+    expect(formatAge(76 * 60 * MIN)).toBe("3d 4h");
+    expect(formatAge(-5000)).toBe("0s");
+  });
+});
+
+describe("formatBytes", () => {
+  it("formats bytes, kilobytes and megabytes", () => {
+    expect(formatBytes(512)).toBe("512 B");
+    expect(formatBytes(2048)).toBe("2.0 KB");
+    expect(formatBytes(4.2 * 1024 * 1024)).toBe("4.2 MB");
+  });
+});
+
+describe("formatStatus", () => {
+  it("summarizes a healthy system", () => {
+    const text = formatStatus(summary());
+
+    expect(text).toContain("pixoo64  living-room");
+    expect(text).toContain("connected 1h 30m ago");
+    expect(text).toContain("Frame      40s ago (layout day, 1 sent, 0 failed)");
+    expect(text).toContain("Glucose    142 Flat, 3m ago");
+    expect(text).toContain("Alerts     none");
+    expect(text).toContain("Lock       off");
+    expect(text).toContain("Store      12,345 items, 4.2 MB");
+    expect(text).not.toContain("STALE");
+  });
+
+  it("flags stale inputs", () => {
+    const text = formatStatus(
+      summary({
+        lastFrameAt: NOW - 20 * MIN,
+        glucose: { value: 142, trend: "Flat", timestamp: NOW - 40 * MIN },
+        sources: [{ name: "dexcom", updatedAt: NOW - 40 * MIN }],
+      })
+    );
+
+    expect(text).toContain("Frame      20m ago [STALE]");
+    expect(text).toContain("142 Flat, 40m ago [STALE]");
+    expect(text).toContain("dexcom   40m ago [STALE]");
+  });
+
+  it("shows alerts, locks, widget errors and rejected points", () => {
+    const text = formatStatus(
+      summary({
+        compositor: {
+          updatedAt: NOW,
+          alerts: [{ type: "high", title: "HIGH", detail: "ABOVE 250" }],
+          rejectedPoints: { dexcom: 2 },
+        },
+        lock: { locked: true, remainingMinutes: 12, reason: "demo" },
+        sources: [{ name: "widget:clock", updatedAt: NOW - MIN, errorCount: 3, lastError: "timeout" }],
+      })
+    );
+
+    expect(text).toContain("Alerts     HIGH: ABOVE 250");
+    expect(text).toContain("Lock       held, 12m left (demo)");
+    expect(text).toContain("widget:clock 1m ago, 3 errors (timeout)");
+    expect(text).toContain("Rejected   dexcom: 2");
+  });
+
+  it("handles an idle system", () => {
+    const text = formatStatus(
+      summary({
+        devices: [],
+        lastFrameAt: null,
+        compositor: { updatedAt: NOW, skipped: true },
+        glucose: null,
+        store: null,
+      })
+    );
+
+    expect(text).toContain("Devices    none connected");
+    expect(text).toContain("Frame      never (last run skipped: no connections)");
+    expect(text).toContain("Glucose    no reading");
+    expect(text).toContain("Store      unavailable");
+  });
+
+  it("names surfaces that stopped changing", () => {
+    const text = formatStatus(
+      summary({
+        compositor: {
+          updatedAt: NOW,
+          stuck: ["bloodSugar"],
+          frameStreaks: { bloodSugar: { hash: "abc", count: 26, since: NOW - 25 * MIN } },
+        },
+      })
+    );
+
+    expect(text).toContain("Stuck      bloodSugar unchanged for 25m");
+  });
+});
@@ -0,0 +1,142 @@
+/**
+ * Plain-text status summary
+ * One screen, meant for a terminal: what's connected, what was last sent,
+ * and which inputs have gone stale.
+ */
+
+import type { SourceStatus, StatusSummary } from "./types.js";
+
+/** When each item counts as stale and gets flagged */
+const STALE_AFTER_MS: Record<string, number> = {
+  frame: 5 * 60 * 1000,
+  glucose: 15 * 60 * 1000,
+  dexcom: 15 * 60 * 1000,
+  glooko: 3 * 60 * 60 * 1000,
+  insight: 24 * 60 * 60 * 1000,
+};
+
+const LABEL_WIDTH = 10;
+
+/**
+ * Format an age as "42s", "7m", "2h 5m" or "3d 4h"
+ */
+export function formatAge(ms: number): string {
+  const seconds = Math.max(0, Math.floor(ms / 1000));
+  if (seconds < 60) return `${seconds}s`;
+  const minutes = Math.floor(seconds / 60);
+  if (minutes < 60) return `${minutes}m`;
+  const hours = Math.floor(minutes / 60);
+  if (hours < 24) return `${hours}h ${minutes % 60}m`;
+  return `${Math.floor(hours / 24)}d ${hours % 24}h`;
+}
+
+/**
+ * Format a byte count as B, KB or MB
+ */
+export function formatBytes(bytes: number): string {
+  if (bytes < 1024) return `${bytes} B`;
+  if (bytes < 1024 * 1024) return `${(bytes / 1024).toFixed(1)} KB`;
+  return `${(bytes / (1024 * 1024)).toFixed(1)} MB`;
+}
+
+function ago(timestamp: number | null, now: number, staleKey?: string): string {
+  if (timestamp === null) return "never";
+  const age = now - timestamp;
+  const stale = staleKey !== undefined && age > STALE_AFTER_MS[staleKey];
+  return `${formatAge(age)} ago${stale ? " [STALE]" : ""}`;
+}
+
+function line(label: string, value: string): string {
+  return `${label.padEnd(LABEL_WIDTH)} ${value}`;
+}
+
+function formatSource(source: SourceStatus, now: number): string {
+  const errors = source.errorCount
+    ? `, ${source.errorCount} error${source.errorCount === 1 ? "" : "s"}${source.lastError ? ` (${source.lastError})` : ""}`
+    : "";
+  const staleKey = source.name in STALE_AFTER_MS ? source.name : undefined;
+  return `  ${source.name.padEnd(LABEL_WIDTH - 2)} ${ago(source.updatedAt, now, staleKey)}${errors}`;
+}
+
+/**
+ * Render the summary as plain text
+ */
+export function formatStatus(summary: StatusSummary, now: number = summary.generatedAt): string {
+  const lines: string[] = [`SIGNAGE STATUS  ${new Date(summary.generatedAt).toISOString()}`, ""];
+
+  lines.push(line("Devices", summary.devices.length === 0 ? "none connected" : String(summary.devices.length)));
+  for (const device of summary.devices) {
+    lines.push(
+      `  ${device.terminalType.padEnd(LABEL_WIDTH - 2)} ${(device.terminalId ?? "-").padEnd(16)} connected ${ago(Date.parse(device.connectedAt), now)}`
+    );
+  }
+
+  const compositor = summary.compositor;
+  let frameDetail = "";
+  if (compositor?.skipped) {
+    frameDetail = " (last run skipped: no connections)";
+  } else if (compositor?.screenOff) {
+    frameDetail = " (screen off)";
+  } else if (compositor?.vetoedBy) {
+    frameDetail = ` (send vetoed by ${compositor.vetoedBy})`;
+  } else if (compositor?.broadcast) {
+    const { success, failed, throttled, paused } = compositor.broadcast;
+    const limited = throttled ? `, ${throttled} rate-limited` : "";
+    const maintenance = paused ? `, ${paused} in maintenance` : "";
+    frameDetail = ` (layout ${compositor.layout ?? "?"}, ${success} sent, ${failed} failed${limited}${maintenance})`;
+  }
+  lines.push(line("Frame", `${ago(summary.lastFrameAt, now, "frame")}${frameDetail}`));
+
+  lines.push(
+    line(
+      "Glucose",
+      summary.glucose
+        ? `${summary.glucose.value} ${summary.glucose.trend}, ${ago(summary.glucose.timestamp, now, "glucose")}`
+        : "no reading"
+    )
+  );
+
+  const alerts = compositor?.alerts ?? [];
+  lines.push(
+    line("Alerts", alerts.length === 0 ? "none" : alerts.map((a) => `${a.title}: ${a.detail}`).join("; "))
+  );
+
+  const lock = summary.lock;
+  lines.push(
+    line(
+      "Lock",
+      lock.locked
+        ? `held, ${lock.remainingMinutes}m left${lock.reason ? ` (${lock.reason})` : ""}`
+        : "off"
+    )
+  );
+
+  lines.push("Sources");
+  for (const source of summary.sources) {
+    lines.push(formatSource(source, now));
+  }
+
+  if (compositor?.stuck?.length) {
+    const streaks = compositor.frameStreaks ?? {};
+    const stuck = compositor.stuck.map((name) =>
+      streaks[name] ? `${name} unchanged for ${formatAge(now - streaks[name].since)}` : name
+    );
+    lines.push(line("Stuck", stuck.join(", ")));
+  }
+
+  const rejected = Object.entries(compositor?.rejectedPoints ?? {});
+  if (rejected.length > 0) {
+    lines.push(line("Rejected", rejected.map(([source, count]) => `${source}: ${count}`).join(", ")));
+  }
+
+  lines.push(
+    line(
+      "Store",
+      summary.store
+        ? `${summary.store.itemCount.toLocaleString("en-US")} items, ${formatBytes(summary.store.sizeBytes)}`
+        : "unavailable"
+    )
+  );
+
+  return lines.join("\n");
+}
@@ -0,0 +1,50 @@
+import { describe, it, expect } from "vitest";
+import { createSolidFrame } from "@signage/core";
+import { findStuckSurfaces, hashFrame, updateFrameStreaks, STUCK_AFTER_MS } from "./frame-streaks";
+
+const NOW = new Date("2026-03-02T12:00:00Z").getTime();
+const MIN = 60 * 1000;
+
+describe("hashFrame", () => {
+  it("depends only on pixel content", () => {
+    const a = createSolidFrame(4, 4, { r: 10, g: 20, b: 30 });
+    const b = createSolidFrame(4, 4, { r: 10, g: 20, b: 30 });
+    const c = createSolidFrame(4, 4, { r: 10, g: 20, b: 31 });
+
+    expect(hashFrame(a)).toBe(hashFrame(b));
+    expect(hashFrame(a)).not.toBe(hashFrame(c));
+  });
+});
+
+describe("updateFrameStreaks", () => {
+  it("extends a streak while the hash is unchanged", () => {
+    const first = updateFrameStreaks(undefined, { frame: "aaa" }, NOW);
+    const second = updateFrameStreaks(first, { frame: "aaa" }, NOW + MIN);
+
+    expect(second.frame).toEqual({ hash: "aaa", count: 2, since: NOW });
+  });
+
+  it("restarts a streak when the hash changes", () => {
+    const previous = { frame: { hash: "aaa", count: 9, since: NOW - 10 * MIN } };
+
+    expect(updateFrameStreaks(previous, { frame: "bbb" }, NOW).frame).toEqual({ hash: "bbb", count: 1, since: NOW });
+  });
+
+  it("drops surfaces that weren't rendered", () => {
+    const previous = { insight: { hash: "aaa", count: 3, since: NOW - 3 * MIN } };
+
+    expect(updateFrameStreaks(previous, { frame: "bbb" }, NOW)).not.toHaveProperty("insight");
+  });
+});
+
+describe("findStuckSurfaces", () => {
+  it("flags each surface by its own threshold", () => {
+    const streaks = {
+      frame: { hash: "a", count: 3, since: NOW - 2 * MIN },
+      bloodSugar: { hash: "b", count: 25, since: NOW - STUCK_AFTER_MS.bloodSugar },
+      insight: { hash: "c", count: 25, since: NOW - STUCK_AFTER_MS.bloodSugar },
+    };
+
+    expect(findStuckSurfaces(streaks, NOW)).toEqual(["bloodSugar"]);
+  });
+});
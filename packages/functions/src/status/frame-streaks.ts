@@ -0,0 +1,63 @@
+/**
+ * Identical-frame streaks
+ *
+ * The clock changes the frame every minute, and a glucose widget changes
+ * at least with its "minutes ago" counter, so pixels that stay the same
+ * for long mean something upstream has stopped moving (a frozen source,
+ * a stuck cache) even though frames keep reaching the devices. That is a
+ * different problem from a device that stopped receiving frames, which
+ * the send statistics already cover.
+ */
+
+import { createHash } from "crypto";
+import type { Frame } from "@signage/core";
+import type { FrameStreak } from "./types.js";
+
+/**
+ * How long each surface may stay unchanged before it counts as stuck.
+ * "frame" is the whole composed frame; the rest are data widgets.
+ */
+export const STUCK_AFTER_MS: Record<string, number> = {
+  frame: 5 * 60 * 1000,
+  bloodSugar: 20 * 60 * 1000,
+  insight: 6 * 60 * 60 * 1000,
+};
+
+/**
+ * Content hash of a frame's pixels
+ */
+export function hashFrame(frame: Frame): string {
+  return createHash("sha1").update(frame.pixels).digest("hex").slice(0, 16);
+}
+
+/**
+ * Extend or restart each surface's streak with this run's hashes.
+ * Surfaces not rendered this run are dropped.
+ */
+export function updateFrameStreaks(
+  previous: Record<string, FrameStreak> | undefined,
+  hashes: Record<string, string>,
+  now: number
+): Record<string, FrameStreak> {
+  const streaks: Record<string, FrameStreak> = {};
+  for (const [name, hash] of Object.entries(hashes)) {
+    const last = previous?.[name];
+    streaks[name] =
+      last && last.hash === hash
+        ? { hash, count: last.count + 1, since: last.since }
+        : { hash, count: 1, since: now };
+  }
+  return streaks;
+}
+
+/**
+ * Surfaces that have been unchanged for longer than their threshold
+ */
+export function findStuckSurfaces(streaks: Record<string, FrameStreak>, now: number): string[] {
+  return Object.entries(streaks)
+    .filter(([name, streak]) => {
+      const limit = STUCK_AFTER_MS[name];
+      return limit !== undefined && now - streak.since >= limit;
+    })
+    .map(([name]) => name);
+}
@@ -0,0 +1,154 @@
+/**
+ * Status store
+ * Saves the compositor's last run and gathers the status summary.
+ */
+
+import { DynamoDBClient, DescribeTableCommand } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand, QueryCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import { getCurrentInsight } from "@diabetes/core";
+import { getDisplayLock, getLockStatus } from "../display/lock-store.js";
+import { getWidgetIds } from "../widgets/registry.js";
+import type { CompositorStatus, DeviceStatus, SourceStatus, StatusSummary } from "./types.js";
+import { DEFAULT_PROFILE_ID, profileUserId } from "../profiles/profile.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const STATUS_KEY = { pk: "COMPOSITOR_STATUS", sk: "LATEST" };
+
+/**
+ * Save the outcome of a compositor run
+ */
+export async function saveCompositorStatus(status: CompositorStatus): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: { ...STATUS_KEY, ...status },
+    })
+  );
+}
+
+/**
+ * Get the outcome of the last compositor run, or null before the first
+ */
+export async function getCompositorStatus(): Promise<CompositorStatus | null> {
+  const item = await getItem(STATUS_KEY.pk, STATUS_KEY.sk);
+  if (!item) return null;
+  const { pk: _pk, sk: _sk, ...status } = item;
+  return status as unknown as CompositorStatus;
+}
+
+async function getItem(pk: string, sk: string): Promise<Record<string, unknown> | undefined> {
+  const result = await ddb.send(
+    new GetCommand({ TableName: Resource.SignageTable.name, Key: { pk, sk } })
+  );
+  return result.Item;
+}
+
+async function getDevices(): Promise<DeviceStatus[]> {
+  const devices: DeviceStatus[] = [];
+  let lastEvaluatedKey: Record<string, unknown> | undefined;
+
+  do {
+    const result = await ddb.send(
+      new QueryCommand({
+        TableName: Resource.SignageTable.name,
+        KeyConditionExpression: "pk = :pk",
+        ExpressionAttributeValues: { ":pk": "CONNECTIONS" },
+        ExclusiveStartKey: lastEvaluatedKey,
+      })
+    );
+    for (const item of result.Items ?? []) {
+      devices.push({
+        connectionId: item.connectionId as string,
+        terminalId: (item.terminalId as string | null) ?? null,
+        terminalType: (item.terminalType as string) ?? "unknown",
+        connectedAt: item.connectedAt as string,
+      });
+    }
+    lastEvaluatedKey = result.LastEvaluatedKey;
+  } while (lastEvaluatedKey);
+
+  return devices;
+}
+
+async function getSources(bgCache: Record<string, unknown> | undefined): Promise<SourceStatus[]> {
+  const [treatments, insight, widgetStates] = await Promise.all([
+    getItem("GLOOKO#TREATMENTS", "DATA"),
+    getCurrentInsight(ddb, Resource.SignageTable.name, profileUserId(DEFAULT_PROFILE_ID)),
+    Promise.all(getWidgetIds().map((id) => getItem(`WIDGET#${id}`, "STATE"))),
+  ]);
+
+  const sources: SourceStatus[] = [
+    { name: "dexcom", updatedAt: (bgCache?.cachedAt as number) ?? null },
+    { name: "glooko", updatedAt: (treatments?.lastFetchedAt as number) ?? null },
+    { name: "insight", updatedAt: insight?.generatedAt ?? null },
+  ];
+
+  // Widgets driven by the dispatcher keep their own state
+  for (const state of widgetStates) {
+    if (!state) continue;
+    sources.push({
+      name: `widget:${state.widgetId as string}`,
+      updatedAt: state.lastRun ? Date.parse(state.lastRun as string) : null,
+      errorCount: (state.errorCount as number) ?? 0,
+      ...(state.lastError ? { lastError: state.lastError as string } : {}),
+    });
+  }
+
+  return sources;
+}
+
+/**
+ * Table size from DynamoDB. AWS refreshes these numbers about every six hours.
+ */
+async function getStoreSize(): Promise<StatusSummary["store"]> {
+  try {
+    const result = await client.send(
+      new DescribeTableCommand({ TableName: Resource.SignageTable.name })
+    );
+    return {
+      itemCount: result.Table?.ItemCount ?? 0,
+      sizeBytes: result.Table?.TableSizeBytes ?? 0,
+    };
+  } catch (error) {
+    console.error("Failed to describe table:", error);
+    return null;
+  }
+}
+
+/**
+ * Gather the status summary
+ */
+export async function collectStatus(now: number = Date.now()): Promise<StatusSummary> {
+  const [devices, frame, compositor, bgCache, lock, store] = await Promise.all([
+    getDevices(),
+    getItem("FRAME_CACHE", "LATEST"),
+    getItem(STATUS_KEY.pk, STATUS_KEY.sk),
+    getItem("BG_CACHE", "LATEST"),
+    getDisplayLock(now),
+    getStoreSize(),
+  ]);
+  const sources = await getSources(bgCache);
+
+  const current = bgCache?.current as { glucose: number; trend: string; timestamp: number } | undefined;
+  let compositorStatus: CompositorStatus | null = null;
+  if (compositor) {
+    const { pk: _pk, sk: _sk, ...rest } = compositor;
+    compositorStatus = rest as unknown as CompositorStatus;
+  }
+
+  return {
+    generatedAt: now,
+    devices,
+    lastFrameAt: (frame?.timestamp as number) ?? null,
+    compositor: compositorStatus,
+    glucose: current
+      ? { value: current.glucose, trend: current.trend, timestamp: current.timestamp }
+      : null,
+    lock: getLockStatus(lock, now),
+    sources,
+    store,
+  };
+}
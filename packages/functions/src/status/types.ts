@@ -0,0 +1,81 @@
+/**
+ * Status summary types
+ */
+
+import type { DisplayLockStatus } from "../display/lock-store.js";
+
+/**
+ * What the compositor did on its last run, saved after every run
+ */
+export interface CompositorStatus {
+  updatedAt: number;
+  /** True when the run was skipped because nothing was connected */
+  skipped?: boolean;
+  /** True when the screens were off (sleep schedule or away) and no frame was sent */
+  screenOff?: boolean;
+  /** Compose hook that vetoed the send, when one did */
+  vetoedBy?: string;
+  layout?: string;
+  locked?: boolean;
+  alerts?: Array<{ type: string; title: string; detail: string }>;
+  /** Points dropped by ingestion sanity filters, by source */
+  rejectedPoints?: Record<string, number>;
+  connections?: number;
+  /**
+   * Send counts; throttled = skipped by a per-device rate limit,
+   * paused = skipped during a device maintenance window
+   */
+  broadcast?: { success: number; failed: number; cleaned: number; throttled?: number; paused?: number };
+  /** How long the frame and each data widget have looked the same, by surface */
+  frameStreaks?: Record<string, FrameStreak>;
+  /** Surfaces unchanged for longer than their source should stay still */
+  stuck?: string[];
+}
+
+/**
+ * A run of identical renders of one surface
+ */
+export interface FrameStreak {
+  /** Content hash of the surface's pixels */
+  hash: string;
+  /** Consecutive runs with this hash */
+  count: number;
+  /** When the surface first looked like this (ms) */
+  since: number;
+}
+
+/**
+ * A connected display or emulator
+ */
+export interface DeviceStatus {
+  connectionId: string;
+  terminalId: string | null;
+  terminalType: string;
+  connectedAt: string;
+}
+
+/**
+ * Freshness of one data source feeding the display
+ */
+export interface SourceStatus {
+  name: string;
+  /** When the source last produced data (ms), null if never */
+  updatedAt: number | null;
+  errorCount?: number;
+  lastError?: string;
+}
+
+/**
+ * Everything `GET /status` reports
+ */
+export interface StatusSummary {
+  generatedAt: number;
+  devices: DeviceStatus[];
+  /** When the last frame was cached for broadcast (ms) */
+  lastFrameAt: number | null;
+  compositor: CompositorStatus | null;
+  glucose: { value: number; trend: string; timestamp: number } | null;
+  lock: DisplayLockStatus;
+  sources: SourceStatus[];
+  store: { itemCount: number; sizeBytes: number } | null;
+}
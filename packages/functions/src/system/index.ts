@@ -0,0 +1,9 @@
+/**
+ * Host system metrics, for the local server
+ *
+ * Only meaningful where the server runs beside the display; a Lambda's
+ * own metrics say nothing about it.
+ */
+
+export * from "./metrics.js";
+export * from "./types.js";
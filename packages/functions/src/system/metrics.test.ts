@@ -0,0 +1,55 @@
+import { describe, it, expect } from "vitest";
+import { mkdtemp, writeFile } from "node:fs/promises";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import { cpuPercentBetween, parseThermalZone, readSystemMetrics, readTemperature } from "./metrics";
+
+describe("cpuPercentBetween", () => {
+  it("is the busy share of the time between snapshots", () => {
+    expect(cpuPercentBetween({ busy: 1000, total: 4000 }, { busy: 1250, total: 5000 })).toBe(25);
+    expect(cpuPercentBetween({ busy: 0, total: 0 }, { busy: 4000, total: 4000 })).toBe(100);
+  });
+
+  it("is 0 when no time has passed", () => {
+    expect(cpuPercentBetween({ busy: 10, total: 40 }, { busy: 10, total: 40 })).toBe(0);
+  });
+});
+
+describe("parseThermalZone", () => {
+  it("converts millidegrees to °C", () => {
+    expect(parseThermalZone("52612\n")).toBe(52.6);
+    expect(parseThermalZone("-5000")).toBe(-5);
+  });
+
+  it("returns null for anything else", () => {
+    expect(parseThermalZone("")).toBeNull();
+    expect(parseThermalZone("hot")).toBeNull();
+  });
+});
+
+describe("readTemperature", () => {
+  it("reads a thermal zone file", async () => {
+    const dir = await mkdtemp(join(tmpdir(), "signage-thermal-"));
+    const path = join(dir, "temp");
+    await writeFile(path, "61000\n");
+
+    expect(await readTemperature(path)).toBe(61);
+  });
+
+  it("returns null without a thermal zone", async () => {
+    expect(await readTemperature("/nonexistent/thermal_zone0/temp")).toBeNull();
+  });
+});
+
+describe("readSystemMetrics", () => {
+  it("reads percentages and returns a snapshot for next time", async () => {
+    const first = await readSystemMetrics(null);
+    expect(first.metrics.cpuPercent).toBe(0);
+    expect(first.metrics.memoryPercent).toBeGreaterThan(0);
+    expect(first.metrics.memoryPercent).toBeLessThanOrEqual(100);
+
+    const second = await readSystemMetrics(first.cpu);
+    expect(second.metrics.cpuPercent).toBeGreaterThanOrEqual(0);
+    expect(second.metrics.cpuPercent).toBeLessThanOrEqual(100);
+  });
+});
@@ -0,0 +1,82 @@
+/**
+ * Host system metrics
+ *
+ * Reads CPU, memory and temperature for the machine the local server runs
+ * on, typically a Raspberry Pi beside the display. CPU use comes from the
+ * change in cumulative core times between two readings, so the first
+ * reading has nothing to compare against and reports 0. Temperature comes
+ * from the first thermal zone in sysfs, which is the SoC on a Pi; other
+ * machines may not have one.
+ */
+
+import { cpus, freemem, hostname, totalmem } from "node:os";
+import { readFile } from "node:fs/promises";
+import type { CpuSnapshot, SystemMetrics } from "./types.js";
+
+/** Where Linux exposes the SoC temperature, in millidegrees */
+export const THERMAL_ZONE_PATH = "/sys/class/thermal/thermal_zone0/temp";
+
+/**
+ * Cumulative CPU time across all cores right now
+ */
+export function takeCpuSnapshot(): CpuSnapshot {
+  let busy = 0;
+  let total = 0;
+  for (const { times } of cpus()) {
+    const coreBusy = times.user + times.nice + times.sys + times.irq;
+    busy += coreBusy;
+    total += coreBusy + times.idle;
+  }
+  return { busy, total };
+}
+
+/**
+ * CPU busy between two snapshots, 0-100 (0 when no time has passed)
+ */
+export function cpuPercentBetween(previous: CpuSnapshot, current: CpuSnapshot): number {
+  const total = current.total - previous.total;
+  if (total <= 0) return 0;
+  const percent = ((current.busy - previous.busy) / total) * 100;
+  return Math.min(100, Math.max(0, Math.round(percent)));
+}
+
+/**
+ * Parse a sysfs thermal zone reading (millidegrees) into °C, or null if it
+ * isn't a number
+ */
+export function parseThermalZone(text: string): number | null {
+  const millidegrees = Number(text.trim());
+  if (text.trim() === "" || !Number.isFinite(millidegrees)) return null;
+  return Math.round(millidegrees / 100) / 10;
+}
+
+/**
+ * Read the SoC temperature, or null where there's no thermal zone
+ */
+export async function readTemperature(path: string = THERMAL_ZONE_PATH): Promise<number | null> {
+  try {
+    return parseThermalZone(await readFile(path, "utf8"));
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Read the host's metrics. Pass the snapshot from the previous reading for
+ * CPU use since then; the snapshot to pass next time is returned with them.
+ */
+export async function readSystemMetrics(
+  previousCpu: CpuSnapshot | null
+): Promise<{ metrics: SystemMetrics; cpu: CpuSnapshot }> {
+  const cpu = takeCpuSnapshot();
+  const total = totalmem();
+  return {
+    metrics: {
+      hostname: hostname(),
+      cpuPercent: previousCpu ? cpuPercentBetween(previousCpu, cpu) : 0,
+      memoryPercent: total > 0 ? Math.round(((total - freemem()) / total) * 100) : 0,
+      temperatureC: await readTemperature(),
+    },
+    cpu,
+  };
+}
@@ -0,0 +1,27 @@
+/**
+ * Host system metrics types
+ */
+
+/**
+ * One reading of the machine the local server runs on
+ */
+export interface SystemMetrics {
+  /** Host name, as the machine reports it */
+  hostname: string;
+  /** CPU busy across all cores since the previous reading, 0-100 */
+  cpuPercent: number;
+  /** Memory in use (not counting reclaimable cache), 0-100 */
+  memoryPercent: number;
+  /** SoC temperature in °C, or null where the machine doesn't expose one */
+  temperatureC: number | null;
+}
+
+/**
+ * Cumulative CPU time across all cores, as read at one moment
+ */
+export interface CpuSnapshot {
+  /** Busy time, ms */
+  busy: number;
+  /** Busy plus idle time, ms */
+  total: number;
+}
@@ -0,0 +1,43 @@
+import { describe, it, expect } from "vitest";
+import { isTickerRefreshDue, toDisplayQuote, TICKER_REFRESH_MS } from "./history";
+
+const NOW = Date.UTC(2026, 9, 16, 15, 0);
+
+describe("isTickerRefreshDue", () => {
+  it("is due once the newest stored quote is old, or nothing is stored", () => {
+    expect(isTickerRefreshDue([NOW - 60_000, NOW - TICKER_REFRESH_MS], NOW)).toBe(false);
+    expect(isTickerRefreshDue([NOW - TICKER_REFRESH_MS, NOW - 2 * TICKER_REFRESH_MS], NOW)).toBe(true);
+    expect(isTickerRefreshDue([null, null], NOW)).toBe(true);
+  });
+
+  it("doesn't refetch every run for a symbol that never loads", () => {
+    expect(isTickerRefreshDue([NOW - 60_000, null], NOW)).toBe(false);
+  });
+});
+
+describe("toDisplayQuote", () => {
+  const history = [
+    { timestamp: NOW - 10 * 60_000, value: { price: 100, changePercent: 1 } },
+    { timestamp: NOW - 5 * 60_000, value: { price: 101, changePercent: 2 } },
+  ];
+
+  it("appends a fresh quote to the stored history", () => {
+    const fresh = { symbol: "AAPL", label: "AAPL", price: 102, changePercent: 3, timestamp: NOW };
+    expect(toDisplayQuote("AAPL", "AAPL", history, fresh)).toEqual({ ...fresh, history: [100, 101, 102] });
+  });
+
+  it("falls back to the newest stored quote", () => {
+    expect(toDisplayQuote("AAPL", "AAPL", history)).toEqual({
+      symbol: "AAPL",
+      label: "AAPL",
+      price: 101,
+      changePercent: 2,
+      timestamp: NOW - 5 * 60_000,
+      history: [100, 101],
+    });
+  });
+
+  it("returns null with nothing to show", () => {
+    expect(toDisplayQuote("AAPL", "AAPL", [])).toBeNull();
+  });
+});
@@ -0,0 +1,88 @@
+/**
+ * Ticker price history
+ *
+ * Each symbol's quotes are kept as a widget time series (see
+ * widgets/history-store.ts) for a day, which is what the sparkline shows.
+ * Stored quotes double as a cache: the quote APIs are only asked again once
+ * the newest stored quote is older than the refresh interval.
+ */
+
+import type { TimeSeriesPoint, WidgetHistoryConfig } from "../widgets/types.js";
+import type { TickerDisplayQuote, TickerQuote } from "./types.js";
+
+/** How often quotes are fetched (the free APIs are rate limited) */
+export const TICKER_REFRESH_MS = 5 * 60 * 1000;
+
+/** Hours of price history kept and drawn */
+export const TICKER_HISTORY_HOURS = 24;
+
+export const TICKER_HISTORY_CONFIG: WidgetHistoryConfig = {
+  enabled: true,
+  retentionHours: TICKER_HISTORY_HOURS,
+  backfillDepthHours: 0,
+  backfillThresholdMinutes: 0,
+  dedupeWindowMinutes: 5,
+  storageType: "time-series",
+  aggregateField: "price",
+};
+
+/** Stored value of one quote */
+export interface TickerHistoryValue {
+  price: number;
+  changePercent: number;
+}
+
+/**
+ * Widget id a symbol's history is stored under
+ */
+export function tickerHistoryId(symbol: string): string {
+  return `ticker-${symbol}`;
+}
+
+/**
+ * Whether quotes should be fetched again, given the newest stored quote of
+ * each symbol (null when a symbol has none). Goes by the newest of them, so
+ * a symbol that keeps failing is retried with the others rather than
+ * every run.
+ */
+export function isTickerRefreshDue(latest: Array<number | null>, now: number = Date.now()): boolean {
+  const stored = latest.filter((timestamp): timestamp is number => timestamp !== null);
+  return stored.length === 0 || now - Math.max(...stored) >= TICKER_REFRESH_MS;
+}
+
+/**
+ * A fetched quote as a time-series point
+ */
+export function quoteToTimeSeriesPoint(quote: TickerQuote): TimeSeriesPoint<TickerHistoryValue> {
+  return {
+    timestamp: quote.timestamp,
+    value: { price: quote.price, changePercent: quote.changePercent },
+  };
+}
+
+/**
+ * A symbol's display quote: the fresh quote if there is one, otherwise the
+ * newest stored point. Null when there's neither.
+ */
+export function toDisplayQuote(
+  symbol: string,
+  label: string,
+  history: TimeSeriesPoint<TickerHistoryValue>[],
+  fresh?: TickerQuote
+): TickerDisplayQuote | null {
+  const prices = history.map((p) => p.value.price);
+  if (fresh) {
+    return { ...fresh, history: [...prices, fresh.price] };
+  }
+
+  const latest = history[history.length - 1];
+  if (!latest) return null;
+  return {
+    symbol,
+    label,
+    price: latest.value.price,
+    changePercent: latest.value.changePercent,
+    timestamp: latest.timestamp,
+    history: prices,
+  };
+}
@@ -0,0 +1,142 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import {
+  fetchTickerQuotes,
+  normalizeTickerSettings,
+  parseCoinGeckoQuote,
+  parseTickerSymbol,
+  parseYahooQuote,
+  validateTickerSettings,
+  MAX_TICKER_SYMBOLS,
+} from "./quotes";
+
+describe("parseTickerSymbol", () => {
+  it("reads plain symbols as Yahoo Finance symbols", () => {
+    expect(parseTickerSymbol("AAPL")).toEqual({ source: "yahoo", id: "AAPL", label: "AAPL" });
+    expect(parseTickerSymbol("^GSPC")).toEqual({ source: "yahoo", id: "^GSPC", label: "GSPC" });
+    expect(parseTickerSymbol("BRK-B")?.label).toBe("BRK");
+    expect(parseTickerSymbol("GOOGL")?.label).toBe("GOOG");
+  });
+
+  it("reads coingecko: symbols as coin ids", () => {
+    expect(parseTickerSymbol("coingecko:bitcoin")).toEqual({ source: "coingecko", id: "bitcoin", label: "BTC" });
+    expect(parseTickerSymbol("coingecko:shiba-inu")?.label).toBe("SHIB");
+  });
+
+  it("rejects malformed symbols", () => {
+    expect(parseTickerSymbol("not a symbol")).toBeNull();
+    expect(parseTickerSymbol("aapl")).toBeNull();
+    expect(parseTickerSymbol("coingecko:")).toBeNull();
+    expect(parseTickerSymbol("coingecko:Bitcoin")).toBeNull();
+  });
+});
+
+describe("validateTickerSettings", () => {
+  it("accepts symbols with an optional sparkline flag, and null", () => {
+    expect(validateTickerSettings({ symbols: ["AAPL", "coingecko:ethereum"] })).toBeNull();
+    expect(validateTickerSettings({ symbols: ["AAPL"], sparkline: true })).toBeNull();
+    expect(validateTickerSettings(null)).toBeNull();
+  });
+
+  it("rejects empty, oversized or malformed symbol lists", () => {
+    expect(validateTickerSettings({ symbols: [] })).toMatch(/symbols/);
+    expect(validateTickerSettings({ symbols: Array(MAX_TICKER_SYMBOLS + 1).fill("AAPL") })).toMatch(/symbols/);
+    expect(validateTickerSettings({ symbols: ["AAPL", 42] })).toMatch(/invalid ticker symbol/);
+  });
+
+  it("rejects other fields and a non-boolean sparkline", () => {
+    expect(validateTickerSettings({ symbols: ["AAPL"], currency: "eur" })).toMatch(/unknown ticker fields/);
+    expect(validateTickerSettings({ symbols: ["AAPL"], sparkline: "yes" })).toMatch(/sparkline/);
+    expect(validateTickerSettings(["AAPL"])).toMatch(/object/);
+  });
+});
+
+describe("normalizeTickerSettings", () => {
+  it("drops duplicate symbols and a false sparkline", () => {
+    expect(normalizeTickerSettings({ symbols: ["AAPL", "MSFT", "AAPL"], sparkline: false })).toEqual({
+      symbols: ["AAPL", "MSFT"],
+    });
+  });
+});
+
+describe("parseYahooQuote", () => {
+  it("works out the change from the previous close", () => {
+    const quote = parseYahooQuote({
+      chart: { result: [{ meta: { regularMarketPrice: 110, chartPreviousClose: 100 } }] },
+    });
+    expect(quote?.price).toBe(110);
+    expect(quote?.changePercent).toBeCloseTo(10);
+  });
+
+  it("returns null without a price", () => {
+    expect(parseYahooQuote({ chart: { result: null } })).toBeNull();
+    expect(parseYahooQuote({})).toBeNull();
+  });
+});
+
+describe("parseCoinGeckoQuote", () => {
+  it("reads the USD price and 24h change", () => {
+    expect(parseCoinGeckoQuote({ bitcoin: { usd: 67000, usd_24h_change: -1.5 } }, "bitcoin")).toEqual({
+      price: 67000,
+      changePercent: -1.5,
+    });
+  });
+
+  it("returns null for a missing coin", () => {
+    expect(parseCoinGeckoQuote({}, "bitcoin")).toBeNull();
+  });
+});
+
+describe("fetchTickerQuotes", () => {
+  const originalFetch = global.fetch;
+  const mockFetch = vi.fn();
+
+  beforeEach(() => {
+    mockFetch.mockReset();
+    global.fetch = mockFetch as unknown as typeof fetch;
+    vi.spyOn(console, "error").mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+    vi.restoreAllMocks();
+  });
+
+  function respond(body: unknown, status = 200) {
+    return { ok: status < 400, status, json: async () => body };
+  }
+
+  it("asks CoinGecko once for every coin and Yahoo per symbol, keeping the configured order", async () => {
+    mockFetch.mockImplementation(async (url: string) => {
+      if (url.includes("coingecko")) {
+        return respond({
+          bitcoin: { usd: 67000, usd_24h_change: 2 },
+          ethereum: { usd: 3000, usd_24h_change: -1 },
+        });
+      }
+      return respond({ chart: { result: [{ meta: { regularMarketPrice: 200, chartPreviousClose: 200 } }] } });
+    });
+
+    const quotes = await fetchTickerQuotes(["coingecko:bitcoin", "AAPL", "coingecko:ethereum"], 1000);
+
+    expect(quotes.map((q) => [q.label, q.price, q.changePercent])).toEqual([
+      ["BTC", 67000, 2],
+      ["AAPL", 200, 0],
+      ["ETH", 3000, -1],
+    ]);
+    expect(quotes[0].timestamp).toBe(1000);
+    expect(mockFetch).toHaveBeenCalledTimes(2);
+    expect(mockFetch.mock.calls[0][0]).toContain("ids=bitcoin%2Cethereum");
+  });
+
+  it("leaves out symbols whose request fails", async () => {
+    mockFetch.mockImplementation(async (url: string) =>
+      url.includes("MSFT")
+        ? respond({}, 404)
+        : respond({ chart: { result: [{ meta: { regularMarketPrice: 200, chartPreviousClose: 190 } }] } })
+    );
+
+    const quotes = await fetchTickerQuotes(["MSFT", "AAPL"]);
+
+    expect(quotes.map((q) => q.symbol)).toEqual(["AAPL"]);
+  });
+});
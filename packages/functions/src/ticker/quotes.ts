@@ -0,0 +1,197 @@
+/**
+ * Stock and crypto quotes
+ *
+ * Stocks, indexes and funds come from Yahoo Finance's chart API, coins from
+ * CoinGecko's simple price API. Neither needs an API key. Coins are asked
+ * for in one request; each Yahoo symbol is its own request.
+ */
+
+import type { TickerQuote, TickerSettings, TickerSymbol } from "./types.js";
+
+const YAHOO_CHART_URL = "https://query1.finance.yahoo.com/v8/finance/chart";
+const COINGECKO_PRICE_URL = "https://api.coingecko.com/api/v3/simple/price";
+
+/** Prefix marking a CoinGecko coin id */
+const COINGECKO_PREFIX = "coingecko:";
+
+/** Most symbols one ticker page can list */
+export const MAX_TICKER_SYMBOLS = 8;
+
+/** Longest label that fits beside the price and change */
+const MAX_LABEL_LENGTH = 4;
+
+const YAHOO_SYMBOL = /^\^?[A-Z0-9][A-Z0-9.=-]{0,11}$/;
+const COINGECKO_ID = /^[a-z0-9][a-z0-9-]{0,49}$/;
+
+/** Ticker symbols for coins whose ids don't shorten well */
+const COIN_LABELS: Record<string, string> = {
+  bitcoin: "BTC",
+  ethereum: "ETH",
+  solana: "SOL",
+  dogecoin: "DOGE",
+  cardano: "ADA",
+  ripple: "XRP",
+  litecoin: "LTC",
+  polkadot: "DOT",
+  "avalanche-2": "AVAX",
+  chainlink: "LINK",
+  tether: "USDT",
+  "usd-coin": "USDC",
+};
+
+/**
+ * Parse a configured symbol: "coingecko:<coin id>" for CoinGecko, anything
+ * else as a Yahoo Finance symbol. Returns null for malformed symbols.
+ */
+export function parseTickerSymbol(symbol: string): TickerSymbol | null {
+  if (symbol.startsWith(COINGECKO_PREFIX)) {
+    const id = symbol.slice(COINGECKO_PREFIX.length);
+    if (!COINGECKO_ID.test(id)) return null;
+    const label = COIN_LABELS[id] ?? id.replace(/-/g, "").slice(0, MAX_LABEL_LENGTH).toUpperCase();
+    return { source: "coingecko", id, label };
+  }
+
+  if (!YAHOO_SYMBOL.test(symbol)) return null;
+  // "^GSPC" -> "GSPC", "BRK-B" -> "BRK", "EURUSD=X" -> "EURU"
+  const label = symbol.replace(/^\^/, "").split(/[.=-]/)[0].slice(0, MAX_LABEL_LENGTH);
+  return { source: "yahoo", id: symbol, label };
+}
+
+/**
+ * Validate ticker settings from a request body (null clears them).
+ * Returns an error message, or null if valid.
+ */
+export function validateTickerSettings(value: unknown): string | null {
+  if (value === null) return null;
+  if (typeof value !== "object" || Array.isArray(value)) {
+    return "ticker must be an object or null";
+  }
+  const { symbols, sparkline, ...rest } = value as Record<string, unknown>;
+  if (Object.keys(rest).length > 0) {
+    return `unknown ticker fields: ${Object.keys(rest).join(", ")}`;
+  }
+  if (!Array.isArray(symbols) || symbols.length === 0 || symbols.length > MAX_TICKER_SYMBOLS) {
+    return `ticker symbols must be a list of 1 to ${MAX_TICKER_SYMBOLS} symbols`;
+  }
+  for (const symbol of symbols) {
+    if (typeof symbol !== "string" || !parseTickerSymbol(symbol)) {
+      return `invalid ticker symbol: ${JSON.stringify(symbol)} (use a Yahoo symbol like "AAPL" or "coingecko:bitcoin")`;
+    }
+  }
+  if (sparkline !== undefined && typeof sparkline !== "boolean") {
+    return "ticker sparkline must be true or false";
+  }
+  return null;
+}
+
+/**
+ * Settings from a validated request body, without duplicate symbols
+ */
+export function normalizeTickerSettings(value: TickerSettings): TickerSettings {
+  return {
+    symbols: [...new Set(value.symbols)],
+    ...(value.sparkline && { sparkline: true }),
+  };
+}
+
+/**
+ * Yahoo Finance chart API response (the parts used)
+ */
+export interface YahooChartResponse {
+  chart?: {
+    result?: Array<{
+      meta?: {
+        regularMarketPrice?: number;
+        chartPreviousClose?: number;
+        previousClose?: number;
+      };
+    }> | null;
+  };
+}
+
+/**
+ * Price and change from a Yahoo chart response, or null if it has no price
+ */
+export function parseYahooQuote(response: YahooChartResponse): { price: number; changePercent: number } | null {
+  const meta = response.chart?.result?.[0]?.meta;
+  const price = meta?.regularMarketPrice;
+  if (typeof price !== "number" || !Number.isFinite(price)) return null;
+
+  const previous = meta?.chartPreviousClose ?? meta?.previousClose;
+  const changePercent = typeof previous === "number" && previous > 0 ? ((price - previous) / previous) * 100 : 0;
+  return { price, changePercent };
+}
+
+/**
+ * CoinGecko simple price response: coin id -> { usd, usd_24h_change }
+ */
+export type CoinGeckoPriceResponse = Record<string, { usd?: number; usd_24h_change?: number | null }>;
+
+/**
+ * Price and 24h change for one coin from a CoinGecko response, or null if
+ * the coin is missing
+ */
+export function parseCoinGeckoQuote(
+  response: CoinGeckoPriceResponse,
+  id: string
+): { price: number; changePercent: number } | null {
+  const coin = response[id];
+  if (typeof coin?.usd !== "number" || !Number.isFinite(coin.usd)) return null;
+  return { price: coin.usd, changePercent: coin.usd_24h_change ?? 0 };
+}
+
+async function fetchJson<T>(url: string): Promise<T> {
+  const response = await fetch(url, { headers: { Accept: "application/json" } });
+  if (!response.ok) {
+    throw new Error(`${new URL(url).host} returned ${response.status}`);
+  }
+  return (await response.json()) as T;
+}
+
+/**
+ * Fetch the latest quote for each symbol, in the order given. Symbols that
+ * fail to parse or fetch are left out, so one bad symbol doesn't blank the
+ * others.
+ */
+export async function fetchTickerQuotes(symbols: string[], now: number = Date.now()): Promise<TickerQuote[]> {
+  const parsed = symbols
+    .map((symbol) => ({ symbol, ticker: parseTickerSymbol(symbol) }))
+    .filter((s): s is { symbol: string; ticker: TickerSymbol } => s.ticker !== null);
+
+  const quotes = new Map<string, { price: number; changePercent: number }>();
+
+  const coins = parsed.filter((s) => s.ticker.source === "coingecko");
+  const coinRequest = async (): Promise<void> => {
+    if (coins.length === 0) return;
+    const ids = coins.map((s) => s.ticker.id).join(",");
+    const response = await fetchJson<CoinGeckoPriceResponse>(
+      `${COINGECKO_PRICE_URL}?ids=${encodeURIComponent(ids)}&vs_currencies=usd&include_24hr_change=true`
+    );
+    for (const { symbol, ticker } of coins) {
+      const quote = parseCoinGeckoQuote(response, ticker.id);
+      if (quote) quotes.set(symbol, quote);
+    }
+  };
+
+  const stockRequests = parsed
+    .filter((s) => s.ticker.source === "yahoo")
+    .map(async ({ symbol, ticker }) => {
+      const response = await fetchJson<YahooChartResponse>(
+        `${YAHOO_CHART_URL}/${encodeURIComponent(ticker.id)}?range=1d&interval=1d`
+      );
+      const quote = parseYahooQuote(response);
+      if (quote) quotes.set(symbol, quote);
+    });
+
+  const results = await Promise.allSettled([coinRequest(), ...stockRequests]);
+  for (const result of results) {
+    if (result.status === "rejected") {
+      console.error("Failed to fetch ticker quotes:", result.reason);
+    }
+  }
+
+  return parsed.flatMap(({ symbol, ticker }) => {
+    const quote = quotes.get(symbol);
+    return quote ? [{ symbol, label: ticker.label, ...quote, timestamp: now }] : [];
+  });
+}
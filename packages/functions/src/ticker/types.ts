@@ -0,0 +1,58 @@
+/**
+ * Stock and crypto ticker types
+ */
+
+/** Where a symbol's quotes come from */
+export type TickerSource = "yahoo" | "coingecko";
+
+/**
+ * A parsed ticker symbol. "AAPL" or "^GSPC" is a Yahoo Finance symbol;
+ * "coingecko:bitcoin" is a CoinGecko coin id.
+ */
+export interface TickerSymbol {
+  source: TickerSource;
+  /** Symbol or coin id as the source knows it */
+  id: string;
+  /** Short name shown on the display */
+  label: string;
+}
+
+/**
+ * Ticker settings, stored in the display config
+ */
+export interface TickerSettings {
+  /** Symbols to show, in order (see TickerSymbol) */
+  symbols: string[];
+  /** Draw a day's price history under each quote (default: false) */
+  sparkline?: boolean;
+}
+
+/**
+ * Latest price for a symbol
+ */
+export interface TickerQuote {
+  /** Symbol as configured */
+  symbol: string;
+  label: string;
+  price: number;
+  /** Change over the day (or last 24h for crypto), in percent */
+  changePercent: number;
+  /** When the quote was fetched (ms) */
+  timestamp: number;
+}
+
+/**
+ * A quote with its stored price history, for rendering
+ */
+export interface TickerDisplayQuote extends TickerQuote {
+  /** Prices over the past day, oldest first */
+  history: number[];
+}
+
+/**
+ * Everything the ticker widget draws
+ */
+export interface TickerDisplayData {
+  quotes: TickerDisplayQuote[];
+  sparkline: boolean;
+}
@@ -0,0 +1,154 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockAdd, mockGet, mockForward, mockEnqueue, mockResource } = vi.hoisted(() => ({
+  mockAdd: vi.fn(),
+  mockGet: vi.fn(),
+  mockForward: vi.fn(),
+  mockEnqueue: vi.fn(),
+  mockResource: {
+    NightscoutUrl: { value: "" },
+    NightscoutApiSecret: { value: "" },
+  },
+}));
+
+vi.mock("sst", () => ({ Resource: mockResource }));
+
+vi.mock("./manual-store.js", () => ({
+  addManualTreatment: mockAdd,
+  getManualTreatments: mockGet,
+  MANUAL_SOURCE: "manual",
+}));
+
+vi.mock("./nightscout.js", () => ({
+  forwardToNightscout: mockForward,
+}));
+
+vi.mock("../jobs/store.js", () => ({
+  enqueueJob: mockEnqueue,
+}));
+
+import { handler, parseTreatmentEntry } from "./api";
+
+function createEvent(method: string, body?: unknown): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("parseTreatmentEntry", () => {
+  const now = new Date("2026-03-02T12:00:00Z").getTime();
+
+  it("defaults the time to now", () => {
+    expect(parseTreatmentEntry({ type: "carbs", amount: 30 }, now)).toEqual({
+      timestamp: now,
+      type: "carbs",
+      value: 30,
+      source: "manual",
+    });
+  });
+
+  it("accepts ISO and epoch times", () => {
+    const iso = parseTreatmentEntry({ type: "insulin", amount: 2, time: "2026-03-02T11:00:00Z" }, now);
+    const epoch = parseTreatmentEntry({ type: "insulin", amount: 2, time: now - 3600000 }, now);
+    expect(iso).toEqual(epoch);
+  });
+
+  it("rejects bad types, amounts, and times", () => {
+    expect(parseTreatmentEntry({ type: "basal", amount: 1 }, now)).toMatch(/type/);
+    expect(parseTreatmentEntry({ type: "insulin", amount: 0 }, now)).toMatch(/positive/);
+    expect(parseTreatmentEntry({ type: "insulin", amount: 80 }, now)).toMatch(/exceeds/);
+    expect(parseTreatmentEntry({ type: "carbs", amount: 20, time: "soon" }, now)).toMatch(/ISO/);
+    expect(parseTreatmentEntry({ type: "carbs", amount: 20, time: now + 3600000 }, now)).toMatch(/future/);
+    expect(parseTreatmentEntry({ type: "carbs", amount: 20, time: now - 8 * 86400000 }, now)).toMatch(/7 days/);
+  });
+});
+
+describe("treatments API handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockResource.NightscoutUrl.value = "";
+    mockAdd.mockResolvedValue(undefined);
+    mockGet.mockResolvedValue([]);
+    mockForward.mockResolvedValue(undefined);
+    mockEnqueue.mockResolvedValue(undefined);
+  });
+
+  it("stores a valid entry without forwarding when Nightscout is not configured", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { type: "insulin", amount: 3 }));
+
+    expect(statusCode).toBe(201);
+    expect(body.forwardedToNightscout).toBe(false);
+    expect(mockAdd).toHaveBeenCalledWith(expect.objectContaining({ type: "insulin", value: 3 }));
+    expect(mockForward).not.toHaveBeenCalled();
+  });
+
+  it("forwards to Nightscout when configured", async () => {
+    mockResource.NightscoutUrl.value = "https://nightscout.example.com";
+
+    const { body } = await invoke(createEvent("POST", { type: "carbs", amount: 40 }));
+
+    expect(body.forwardedToNightscout).toBe(true);
+    expect(mockForward).toHaveBeenCalledOnce();
+  });
+
+  it("still stores the entry when forwarding fails", async () => {
+    mockResource.NightscoutUrl.value = "https://nightscout.example.com";
+    mockForward.mockRejectedValue(new Error("Nightscout returned 401"));
+    vi.spyOn(console, "error").mockImplementation(() => {});
+
+    const { statusCode, body } = await invoke(createEvent("POST", { type: "carbs", amount: 40 }));
+
+    expect(statusCode).toBe(201);
+    expect(body.forwardedToNightscout).toBe(false);
+    expect(mockAdd).toHaveBeenCalledOnce();
+  });
+
+  it("queues a retry when forwarding fails", async () => {
+    mockResource.NightscoutUrl.value = "https://nightscout.example.com";
+    mockForward.mockRejectedValue(new Error("Nightscout returned 503"));
+    vi.spyOn(console, "error").mockImplementation(() => {});
+
+    await invoke(createEvent("POST", { type: "carbs", amount: 40 }));
+
+    expect(mockEnqueue).toHaveBeenCalledWith("nightscoutForward", {
+      treatment: expect.objectContaining({ type: "carbs", value: 40 }),
+    });
+  });
+
+  it("stores another profile's entry without forwarding it", async () => {
+    mockResource.NightscoutUrl.value = "https://nightscout.example.com";
+    const event = {
+      ...createEvent("POST", { type: "carbs", amount: 40 }),
+      queryStringParameters: { profile: "household-2" },
+    };
+
+    const { body } = await invoke(event);
+
+    expect(mockAdd).toHaveBeenCalledWith(expect.objectContaining({ value: 40 }), "household-2");
+    expect(body.forwardedToNightscout).toBe(false);
+    expect(mockForward).not.toHaveBeenCalled();
+  });
+
+  it("rejects invalid entries", async () => {
+    const { statusCode } = await invoke(createEvent("POST", { type: "insulin", amount: -1 }));
+
+    expect(statusCode).toBe(400);
+    expect(mockAdd).not.toHaveBeenCalled();
+  });
+
+  it("lists recent manual entries on GET", async () => {
+    mockGet.mockResolvedValue([{ timestamp: 1, type: "carbs", value: 20, source: "manual" }]);
+
+    const { statusCode, body } = await invoke(createEvent("GET"));
+
+    expect(statusCode).toBe(200);
+    expect(body.treatments).toHaveLength(1);
+  });
+});
@@ -0,0 +1,138 @@
+/**
+ * Treatments API
+ *
+ * GET  /treatments - manual entries from the last 24 hours
+ * POST /treatments - add a manual carb or insulin entry
+ *
+ * Body: { "type": "insulin", "amount": 4.5, "time": "2026-03-02T12:30:00Z" }
+ * `time` is optional (defaults to now) and accepts ISO strings or epoch ms.
+ * Add `?profile=<id>` to use another household's entries. Only the default
+ * profile's entries are forwarded to Nightscout.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { Resource } from "sst";
+import type { GlookoTreatment } from "../glooko/types.js";
+import { addManualTreatment, getManualTreatments, MANUAL_SOURCE } from "./manual-store.js";
+import { forwardToNightscout } from "./nightscout.js";
+import { enqueueJob } from "../jobs/store.js";
+import { DEFAULT_PROFILE_ID, parseProfileParam } from "../profiles/profile.js";
+
+/** Sanity limits for a single entry */
+const MAX_AMOUNT = { insulin: 50, carbs: 300 } as const;
+
+/** Entries may be backdated up to 7 days */
+const MAX_AGE_MS = 7 * 24 * 60 * 60 * 1000;
+
+/** Allow small clock skew for "now" entries */
+const MAX_FUTURE_MS = 5 * 60 * 1000;
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * Parse and validate a treatment entry from a request body.
+ * Returns the treatment, or an error message.
+ */
+export function parseTreatmentEntry(
+  body: { type?: unknown; amount?: unknown; time?: unknown },
+  now: number = Date.now()
+): GlookoTreatment | string {
+  if (body.type !== "insulin" && body.type !== "carbs") {
+    return 'type must be "insulin" or "carbs"';
+  }
+
+  const amount = body.amount;
+  if (typeof amount !== "number" || !Number.isFinite(amount) || amount <= 0) {
+    return "amount must be a positive number";
+  }
+  if (amount > MAX_AMOUNT[body.type]) {
+    return `amount exceeds ${MAX_AMOUNT[body.type]} for ${body.type}`;
+  }
+
+  let timestamp = now;
+  if (body.time !== undefined) {
+    timestamp = typeof body.time === "number" ? body.time : Date.parse(String(body.time));
+    if (!Number.isFinite(timestamp)) {
+      return "time must be an ISO timestamp or epoch milliseconds";
+    }
+    if (timestamp > now + MAX_FUTURE_MS) {
+      return "time is in the future";
+    }
+    if (timestamp < now - MAX_AGE_MS) {
+      return "time is more than 7 days ago";
+    }
+  }
+
+  return { timestamp, type: body.type, value: amount, source: MANUAL_SOURCE };
+}
+
+/**
+ * Forward to Nightscout when configured. Failures are logged, not returned,
+ * since the entry is already stored for the display.
+ */
+async function maybeForward(treatment: GlookoTreatment): Promise<boolean> {
+  const url = Resource.NightscoutUrl.value;
+  if (!url) return false;
+
+  try {
+    await forwardToNightscout(treatment, { url, apiSecret: Resource.NightscoutApiSecret.value });
+    return true;
+  } catch (error) {
+    console.error("Nightscout forward failed:", error instanceof Error ? error.message : String(error));
+    // The entry is stored either way; the job worker retries the write-back
+    try {
+      await enqueueJob("nightscoutForward", { treatment });
+    } catch (queueError) {
+      console.error("Failed to queue Nightscout retry:", queueError);
+    }
+    return false;
+  }
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  const profile = parseProfileParam(event.queryStringParameters);
+  if (typeof profile !== "string") {
+    return json(400, profile);
+  }
+
+  if (method === "GET") {
+    const treatments = await getManualTreatments(Date.now() - DAY_MS, profile);
+    return json(200, { treatments });
+  }
+
+  if (method !== "POST") {
+    return json(405, { error: `Method ${method} not allowed` });
+  }
+
+  let body: { type?: unknown; amount?: unknown; time?: unknown };
+  try {
+    body = JSON.parse(event.body || "{}");
+  } catch {
+    return json(400, { error: "Invalid JSON" });
+  }
+
+  const result = parseTreatmentEntry(body);
+  if (typeof result === "string") {
+    return json(400, { error: result });
+  }
+
+  await addManualTreatment(result, profile);
+  // The Nightscout secrets belong to the default profile's household
+  const forwarded = profile === DEFAULT_PROFILE_ID && (await maybeForward(result));
+  console.log(`Manual ${result.type} entry: ${result.value} at ${new Date(result.timestamp).toISOString()} (nightscout=${forwarded})`);
+
+  return json(201, { treatment: result, forwardedToNightscout: forwarded });
+};
@@ -0,0 +1,36 @@
+import { describe, it, expect, vi } from "vitest";
+
+vi.mock("sst", () => ({
+  Resource: { SignageTable: { name: "test-table" } },
+}));
+
+import { mergeTreatments } from "./manual-store";
+import type { GlookoTreatment } from "../glooko/types.js";
+
+describe("mergeTreatments", () => {
+  const base = new Date("2026-03-02T12:00:00Z").getTime();
+  const minutes = (n: number) => n * 60 * 1000;
+
+  it("adds manual entries in time order", () => {
+    const imported: GlookoTreatment[] = [{ timestamp: base, type: "insulin", value: 2 }];
+    const manual: GlookoTreatment[] = [{ timestamp: base - minutes(60), type: "carbs", value: 30, source: "manual" }];
+
+    const merged = mergeTreatments(imported, manual);
+
+    expect(merged.map((t) => t.type)).toEqual(["carbs", "insulin"]);
+  });
+
+  it("drops a manual entry once Glooko imports a matching treatment", () => {
+    const imported: GlookoTreatment[] = [{ timestamp: base + minutes(5), type: "insulin", value: 4 }];
+    const manual: GlookoTreatment[] = [{ timestamp: base, type: "insulin", value: 4, source: "manual" }];
+
+    expect(mergeTreatments(imported, manual)).toEqual(imported);
+  });
+
+  it("keeps a manual entry of a different type at the same time", () => {
+    const imported: GlookoTreatment[] = [{ timestamp: base, type: "insulin", value: 4 }];
+    const manual: GlookoTreatment[] = [{ timestamp: base, type: "carbs", value: 45, source: "manual" }];
+
+    expect(mergeTreatments(imported, manual)).toHaveLength(2);
+  });
+});
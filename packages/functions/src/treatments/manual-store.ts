@@ -0,0 +1,94 @@
+/**
+ * Manual treatment store
+ * Carb and insulin entries posted through the treatments API, kept alongside
+ * the Glooko import so they show up on the display right away.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, PutCommand, QueryCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { GlookoTreatment } from "../glooko/types.js";
+import { DEFAULT_PROFILE_ID, profilePk } from "../profiles/profile.js";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+/** Partition key for manual entries */
+const MANUAL_TREATMENTS_PK = "MANUAL#TREATMENTS";
+
+/** Source tag on manual entries */
+export const MANUAL_SOURCE = "manual";
+
+/** Manual entries expire after 7 days (Glooko has the long-term record) */
+const RETENTION_SECONDS = 7 * 24 * 60 * 60;
+
+/** A Glooko entry this close to a manual one of the same type is the same treatment */
+const DUPLICATE_WINDOW_MS = 15 * 60 * 1000;
+
+/**
+ * Store a manual treatment entry.
+ */
+export async function addManualTreatment(
+  treatment: GlookoTreatment,
+  profileId: string = DEFAULT_PROFILE_ID
+): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        pk: profilePk(profileId, MANUAL_TREATMENTS_PK),
+        sk: `TS#${new Date(treatment.timestamp).toISOString()}#${treatment.type}`,
+        timestamp: treatment.timestamp,
+        type: treatment.type,
+        value: treatment.value,
+        source: MANUAL_SOURCE,
+        createdAt: new Date().toISOString(),
+        ttl: Math.floor(Date.now() / 1000) + RETENTION_SECONDS,
+      },
+    })
+  );
+}
+
+/**
+ * Get manual treatment entries since a timestamp, oldest first.
+ */
+export async function getManualTreatments(
+  since: number,
+  profileId: string = DEFAULT_PROFILE_ID
+): Promise<GlookoTreatment[]> {
+  const result = await ddb.send(
+    new QueryCommand({
+      TableName: Resource.SignageTable.name,
+      KeyConditionExpression: "pk = :pk AND sk >= :since",
+      ExpressionAttributeValues: {
+        ":pk": profilePk(profileId, MANUAL_TREATMENTS_PK),
+        ":since": `TS#${new Date(since).toISOString()}`,
+      },
+    })
+  );
+
+  return (result.Items || []).map((item) => ({
+    timestamp: item.timestamp as number,
+    type: item.type as GlookoTreatment["type"],
+    value: item.value as number,
+    source: MANUAL_SOURCE,
+  }));
+}
+
+/**
+ * Merge manual entries into imported treatments.
+ * A manual entry is dropped once Glooko has imported a treatment of the same
+ * type within 15 minutes of it, so a bolus isn't shown twice.
+ */
+export function mergeTreatments(
+  imported: GlookoTreatment[],
+  manual: GlookoTreatment[]
+): GlookoTreatment[] {
+  const pending = manual.filter(
+    (m) =>
+      !imported.some(
+        (t) => t.type === m.type && Math.abs(t.timestamp - m.timestamp) <= DUPLICATE_WINDOW_MS
+      )
+  );
+  return [...imported, ...pending].sort((a, b) => a.timestamp - b.timestamp);
+}
@@ -0,0 +1,56 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { createHash } from "crypto";
+import { forwardToNightscout, toNightscoutTreatment } from "./nightscout";
+
+describe("toNightscoutTreatment", () => {
+  it("maps insulin to a correction bolus", () => {
+    expect(toNightscoutTreatment({ timestamp: 0, type: "insulin", value: 3 })).toEqual({
+      eventType: "Correction Bolus",
+      created_at: "1970-01-01T00:00:00.000Z",
+      insulin: 3,
+      enteredBy: "signage",
+    });
+  });
+
+  it("maps carbs to a carb correction", () => {
+    expect(toNightscoutTreatment({ timestamp: 0, type: "carbs", value: 25 })).toMatchObject({
+      eventType: "Carb Correction",
+      carbs: 25,
+    });
+  });
+});
+
+describe("forwardToNightscout", () => {
+  const originalFetch = global.fetch;
+  const mockFetch = vi.fn();
+
+  beforeEach(() => {
+    mockFetch.mockReset();
+    global.fetch = mockFetch as unknown as typeof fetch;
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  it("posts to the treatments endpoint with a hashed secret", async () => {
+    mockFetch.mockResolvedValue({ ok: true, status: 200 });
+
+    await forwardToNightscout(
+      { timestamp: 0, type: "insulin", value: 1 },
+      { url: "https://nightscout.example.com/", apiSecret: "secret" }
+    );
+
+    const [url, init] = mockFetch.mock.calls[0];
+    expect(url).toBe("https://nightscout.example.com/api/v1/treatments");
+    expect(init.headers["api-secret"]).toBe(createHash("sha1").update("secret").digest("hex"));
+  });
+
+  it("throws on a non-OK response", async () => {
+    mockFetch.mockResolvedValue({ ok: false, status: 401 });
+
+    await expect(
+      forwardToNightscout({ timestamp: 0, type: "carbs", value: 10 }, { url: "https://nightscout.example.com", apiSecret: "x" })
+    ).rejects.toThrow("401");
+  });
+});
@@ -0,0 +1,61 @@
+/**
+ * Nightscout forwarding for manual treatments
+ * Optional: only active when the NightscoutUrl secret is set.
+ */
+
+import { createHash } from "crypto";
+import type { GlookoTreatment } from "../glooko/types.js";
+
+export interface NightscoutConfig {
+  /** Site URL, e.g. https://nightscout.example.com */
+  url: string;
+  /** API secret in plain text (hashed before sending) */
+  apiSecret: string;
+}
+
+/**
+ * Nightscout treatment document
+ */
+export interface NightscoutTreatment {
+  eventType: "Correction Bolus" | "Carb Correction";
+  created_at: string;
+  insulin?: number;
+  carbs?: number;
+  enteredBy: string;
+}
+
+/**
+ * Convert a treatment to a Nightscout treatment document.
+ */
+export function toNightscoutTreatment(treatment: GlookoTreatment): NightscoutTreatment {
+  const base = {
+    created_at: new Date(treatment.timestamp).toISOString(),
+    enteredBy: "signage",
+  };
+  return treatment.type === "insulin"
+    ? { ...base, eventType: "Correction Bolus", insulin: treatment.value }
+    : { ...base, eventType: "Carb Correction", carbs: treatment.value };
+}
+
+/**
+ * Post a treatment to Nightscout.
+ * Throws if Nightscout rejects the request.
+ */
+export async function forwardToNightscout(
+  treatment: GlookoTreatment,
+  config: NightscoutConfig
+): Promise<void> {
+  const url = `${config.url.replace(/\/+$/, "")}/api/v1/treatments`;
+  const response = await fetch(url, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      "api-secret": createHash("sha1").update(config.apiSecret).digest("hex"),
+    },
+    body: JSON.stringify([toNightscoutTreatment(treatment)]),
+  });
+
+  if (!response.ok) {
+    throw new Error(`Nightscout returned ${response.status}`);
+  }
+}
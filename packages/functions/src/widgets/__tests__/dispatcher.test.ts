@@ -1,11 +1,12 @@
 import { describe, it, expect, vi, beforeEach } from "vitest";
 
-const { mockDdbSend, mockHasActiveConnections, mockBroadcast, mockGetWidget } =
+const { mockDdbSend, mockHasActiveConnections, mockBroadcast, mockGetWidget, mockGetWidgetConfig } =
   vi.hoisted(() => ({
     mockDdbSend: vi.fn(),
     mockHasActiveConnections: vi.fn(),
     mockBroadcast: vi.fn(),
     mockGetWidget: vi.fn(),
+    mockGetWidgetConfig: vi.fn(),
   }));
 
 vi.mock("sst", () => ({
@@ -38,6 +39,10 @@ vi.mock("../registry", () => ({
   getWidget: mockGetWidget,
 }));
 
+vi.mock("../config-store", () => ({
+  getWidgetConfig: mockGetWidgetConfig,
+}));
+
 vi.mock("../history-store", () => ({
   needsBackfill: vi.fn().mockResolvedValue({ needed: false, gapMinutes: 0 }),
   storeDataPoints: vi.fn().mockResolvedValue({ stored: 0, batches: 0 }),
@@ -53,6 +58,7 @@ describe("dispatcher handler", () => {
     mockDdbSend.mockResolvedValue({});
     mockHasActiveConnections.mockResolvedValue(true);
     mockBroadcast.mockResolvedValue({ sent: 1, failed: 0 });
+    mockGetWidgetConfig.mockResolvedValue(null);
   });
 
   const createEvent = (ruleName: string): ScheduledEvent =>
@@ -208,4 +214,31 @@ describe("dispatcher handler", () => {
       );
     });
   });
+
+  describe("widget config", () => {
+    it("skips a disabled widget", async () => {
+      const mockWidget = { name: "clock", update: vi.fn() };
+      mockGetWidget.mockReturnValue(mockWidget);
+      mockGetWidgetConfig.mockResolvedValue({ widgetId: "clock", enabled: false });
+
+      await handler(createEvent("ClockWidget"));
+
+      expect(mockGetWidgetConfig).toHaveBeenCalledWith("clock");
+      expect(mockWidget.update).not.toHaveBeenCalled();
+      expect(mockBroadcast).not.toHaveBeenCalled();
+    });
+
+    it("passes the saved settings to the updater", async () => {
+      const mockWidget = {
+        name: "clock",
+        update: vi.fn().mockResolvedValue({ time: "12:00" }),
+      };
+      mockGetWidget.mockReturnValue(mockWidget);
+      mockGetWidgetConfig.mockResolvedValue({ widgetId: "clock", enabled: true, settings: { format: "24h" } });
+
+      await handler(createEvent("ClockWidget"));
+
+      expect(mockWidget.update).toHaveBeenCalledWith({ format: "24h" });
+    });
+  });
 });
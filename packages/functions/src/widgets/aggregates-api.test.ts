@@ -0,0 +1,80 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockQueryAggregates } = vi.hoisted(() => ({
+  mockQueryAggregates: vi.fn(),
+}));
+
+vi.mock("./history-store", () => ({
+  queryAggregates: mockQueryAggregates,
+}));
+
+import { handler, parseAggregatesQuery } from "./aggregates-api";
+
+const NOW = Date.UTC(2026, 9, 16, 12, 30);
+
+function createEvent(query?: Record<string, string>): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method: "GET" } },
+    queryStringParameters: query,
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("parseAggregatesQuery", () => {
+  it("defaults to a week of hours, ending with the current one", () => {
+    expect(parseAggregatesQuery({ widget: "bloodsugar" }, NOW)).toEqual({
+      widget: "bloodsugar",
+      resolution: "hour",
+      since: Date.UTC(2026, 9, 9, 13),
+    });
+  });
+
+  it("starts daily ranges at local midnight", () => {
+    const result = parseAggregatesQuery({ widget: "bloodsugar", resolution: "day", days: "1" }, NOW);
+    // Midnight in Los Angeles (PDT)
+    expect(result).toMatchObject({ resolution: "day", since: Date.UTC(2026, 9, 16, 7) });
+  });
+
+  it("rejects a missing widget, unknown resolution, and out-of-range days", () => {
+    expect(parseAggregatesQuery({}, NOW)).toMatch(/widget/);
+    expect(parseAggregatesQuery({ widget: "bloodsugar", resolution: "minute" }, NOW)).toMatch(/hour, day/);
+    expect(parseAggregatesQuery({ widget: "bloodsugar", days: "31" }, NOW)).toMatch(/1 to 30/);
+    expect(parseAggregatesQuery({ widget: "bloodsugar", resolution: "day", days: "90" }, NOW)).not.toBeTypeOf("string");
+    expect(parseAggregatesQuery({ widget: "bloodsugar", days: "1.5" }, NOW)).toMatch(/whole/);
+  });
+});
+
+describe("handler", () => {
+  beforeEach(() => {
+    mockQueryAggregates.mockReset();
+  });
+
+  it("returns the buckets without their running sums", async () => {
+    mockQueryAggregates.mockResolvedValue([
+      { start: NOW, min: 90, max: 150, avg: 118.5, sum: 1422, count: 12 },
+    ]);
+
+    const { statusCode, body } = await invoke(createEvent({ widget: "bloodsugar", resolution: "day" }));
+
+    expect(statusCode).toBe(200);
+    expect(mockQueryAggregates).toHaveBeenCalledWith("bloodsugar", "day", expect.any(Number));
+    expect(body).toEqual({
+      widget: "bloodsugar",
+      resolution: "day",
+      buckets: [{ start: NOW, min: 90, max: 150, avg: 118.5, count: 12 }],
+    });
+  });
+
+  it("returns 400 for a bad query", async () => {
+    const { statusCode, body } = await invoke(createEvent());
+
+    expect(statusCode).toBe(400);
+    expect(body.error).toMatch(/widget/);
+    expect(mockQueryAggregates).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,85 @@
+/**
+ * History Aggregates API
+ *
+ * GET /history/aggregates?widget=bloodsugar&resolution=day&days=90
+ *
+ * Returns a widget's hourly (`resolution=hour`, the default, up to 30 days)
+ * or daily (`resolution=day`, up to 400 days) min, max and average, oldest
+ * first, for long-range charts. `days` defaults to 7 and counts today.
+ * Days run from local midnight.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import { queryAggregates } from "./history-store";
+import {
+  DAILY_RETENTION_DAYS,
+  DAY_MS,
+  dayStart,
+  HOUR_MS,
+  hourStart,
+  HOURLY_RETENTION_DAYS,
+  isAggregateResolution,
+  AGGREGATE_RESOLUTIONS,
+  type AggregateResolution,
+} from "./aggregates";
+
+const DEFAULT_DAYS = 7;
+const MAX_WIDGET_LENGTH = 64;
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * Validate the query string.
+ * Returns the widget, resolution and start of the range, or an error message.
+ */
+export function parseAggregatesQuery(
+  query: Record<string, string | undefined> | undefined,
+  now: number = Date.now()
+): { widget: string; resolution: AggregateResolution; since: number } | string {
+  const widget = query?.widget?.trim();
+  if (!widget) {
+    return "widget is required, e.g. widget=bloodsugar";
+  }
+  if (widget.length > MAX_WIDGET_LENGTH) {
+    return `widget must be at most ${MAX_WIDGET_LENGTH} characters`;
+  }
+
+  const resolution = query?.resolution ?? "hour";
+  if (!isAggregateResolution(resolution)) {
+    return `resolution must be one of: ${AGGREGATE_RESOLUTIONS.join(", ")}`;
+  }
+
+  const maxDays = resolution === "hour" ? HOURLY_RETENTION_DAYS : DAILY_RETENTION_DAYS;
+  const days = Number(query?.days ?? DEFAULT_DAYS);
+  if (!Number.isInteger(days) || days < 1 || days > maxDays) {
+    return `days must be a whole number from 1 to ${maxDays} for ${resolution} resolution`;
+  }
+
+  // Whole buckets only, counting the current hour or day as the last
+  const start = now - days * DAY_MS;
+  const since = resolution === "hour" ? hourStart(start + HOUR_MS) : dayStart(start + DAY_MS);
+  return { widget, resolution, since };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const parsed = parseAggregatesQuery(event.queryStringParameters);
+  if (typeof parsed === "string") {
+    return json(400, { error: parsed });
+  }
+
+  const buckets = await queryAggregates(parsed.widget, parsed.resolution, parsed.since);
+  return json(200, {
+    widget: parsed.widget,
+    resolution: parsed.resolution,
+    buckets: buckets.map(({ start, min, max, avg, count }) => ({ start, min, max, avg, count })),
+  });
+};
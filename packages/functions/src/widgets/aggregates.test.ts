@@ -0,0 +1,84 @@
+import { describe, it, expect } from "vitest";
+import {
+  combineBuckets,
+  dayStart,
+  nextDayStart,
+  numericField,
+  summarizePoints,
+  touchedDays,
+  touchedHours,
+} from "./aggregates";
+
+const HOUR = Date.UTC(2026, 9, 16, 12);
+
+describe("summarizePoints", () => {
+  it("keeps the min, max and average of the field", () => {
+    const points = [
+      { timestamp: HOUR, value: { glucose: 100 } },
+      { timestamp: HOUR + 5 * 60000, value: { glucose: 130 } },
+      { timestamp: HOUR + 10 * 60000, value: { glucose: 121 } },
+    ];
+
+    expect(summarizePoints(points, "glucose", HOUR)).toEqual({
+      start: HOUR,
+      min: 100,
+      max: 130,
+      avg: 117,
+      sum: 351,
+      count: 3,
+    });
+  });
+
+  it("skips points without a number in the field", () => {
+    const points = [
+      { timestamp: HOUR, value: { glucose: 100 } },
+      { timestamp: HOUR + 60000, value: { glucose: "high" } },
+      { timestamp: HOUR + 120000, value: null },
+    ];
+
+    expect(summarizePoints(points, "glucose", HOUR)?.count).toBe(1);
+    expect(summarizePoints(points.slice(1), "glucose", HOUR)).toBeNull();
+  });
+});
+
+describe("combineBuckets", () => {
+  it("weights the average by each bucket's count", () => {
+    const hours = [
+      { start: HOUR, min: 90, max: 110, avg: 100, sum: 1200, count: 12 },
+      { start: HOUR + 3600000, min: 150, max: 210, avg: 180, sum: 360, count: 2 },
+    ];
+
+    expect(combineBuckets(hours, HOUR)).toEqual({
+      start: HOUR,
+      min: 90,
+      max: 210,
+      avg: 111.43,
+      sum: 1560,
+      count: 14,
+    });
+    expect(combineBuckets([], HOUR)).toBeNull();
+  });
+});
+
+describe("buckets", () => {
+  it("lists each hour once, oldest first", () => {
+    expect(touchedHours([HOUR + 3600000 + 5, HOUR + 10, HOUR + 20])).toEqual([HOUR, HOUR + 3600000]);
+  });
+
+  it("groups by local day", () => {
+    // 06:00 UTC is still the previous evening in Los Angeles
+    const days = touchedDays([Date.UTC(2026, 9, 16, 6), Date.UTC(2026, 9, 16, 8)], "America/Los_Angeles");
+    expect(days).toEqual([Date.UTC(2026, 9, 15, 7), Date.UTC(2026, 9, 16, 7)]);
+  });
+
+  it("finds the end of a 25-hour day", () => {
+    const start = dayStart(Date.UTC(2026, 10, 1, 12), "America/Los_Angeles");
+    expect(nextDayStart(start, "America/Los_Angeles") - start).toBe(25 * 3600000);
+  });
+
+  it("reads only finite numbers", () => {
+    expect(numericField({ price: 12.5 }, "price")).toBe(12.5);
+    expect(numericField({ price: NaN }, "price")).toBeNull();
+    expect(numericField(42, "price")).toBeNull();
+  });
+});
@@ -0,0 +1,121 @@
+/**
+ * Hourly and daily rollups of widget history
+ *
+ * Raw points are only kept for a widget's retentionHours (a day for blood
+ * sugar), and a week of them is thousands of rows. Widgets that name an
+ * aggregateField also keep the min, max and average of that field per hour
+ * and per local day, which is what long-range charts read instead.
+ *
+ * A bucket is rebuilt from what it covers (raw points for an hour, hourly
+ * buckets for a day) whenever points land in it, rather than incremented,
+ * so writing the same point twice can't count it twice.
+ */
+
+import { DEFAULT_TIMEZONE, startOfZonedDay } from "../rendering/zoned-time.js";
+import type { TimeSeriesPoint } from "./types";
+
+export type AggregateResolution = "hour" | "day";
+
+export const AGGREGATE_RESOLUTIONS: readonly AggregateResolution[] = ["hour", "day"];
+
+/** How long buckets are kept */
+export const HOURLY_RETENTION_DAYS = 30;
+export const DAILY_RETENTION_DAYS = 400;
+
+export const HOUR_MS = 60 * 60 * 1000;
+export const DAY_MS = 24 * HOUR_MS;
+
+/**
+ * Min, max and average of one field over an hour or a day
+ */
+export interface AggregateBucket {
+  /** Start of the hour, or local midnight of the day */
+  start: number;
+  min: number;
+  max: number;
+  avg: number;
+  /** Kept so days can be averaged from their hours */
+  sum: number;
+  count: number;
+}
+
+export function isAggregateResolution(value: unknown): value is AggregateResolution {
+  return AGGREGATE_RESOLUTIONS.includes(value as AggregateResolution);
+}
+
+export function hourStart(timestamp: number): number {
+  return Math.floor(timestamp / HOUR_MS) * HOUR_MS;
+}
+
+export function dayStart(timestamp: number, timezone: string = DEFAULT_TIMEZONE): number {
+  return startOfZonedDay(timestamp, timezone);
+}
+
+/**
+ * Local midnight ending the day that starts at `start` (days are 23 to 25 hours)
+ */
+export function nextDayStart(start: number, timezone: string = DEFAULT_TIMEZONE): number {
+  return startOfZonedDay(start + 26 * HOUR_MS, timezone);
+}
+
+/**
+ * Read a numeric field from a point's value, or null when it has none
+ */
+export function numericField(value: unknown, field: string): number | null {
+  if (typeof value !== "object" || value === null) return null;
+  const n = (value as Record<string, unknown>)[field];
+  return typeof n === "number" && Number.isFinite(n) ? n : null;
+}
+
+function bucket(start: number, min: number, max: number, sum: number, count: number): AggregateBucket {
+  return { start, min, max, avg: Math.round((sum / count) * 100) / 100, sum, count };
+}
+
+/**
+ * Summarize raw points into a bucket, or null when none has the field
+ */
+export function summarizePoints(
+  points: TimeSeriesPoint[],
+  field: string,
+  start: number
+): AggregateBucket | null {
+  const values = points
+    .map((p) => numericField(p.value, field))
+    .filter((v): v is number => v !== null);
+  if (values.length === 0) return null;
+  return bucket(
+    start,
+    Math.min(...values),
+    Math.max(...values),
+    values.reduce((a, b) => a + b, 0),
+    values.length
+  );
+}
+
+/**
+ * Combine smaller buckets (a day's hours) into one, or null when there are none
+ */
+export function combineBuckets(buckets: AggregateBucket[], start: number): AggregateBucket | null {
+  if (buckets.length === 0) return null;
+  return bucket(
+    start,
+    Math.min(...buckets.map((b) => b.min)),
+    Math.max(...buckets.map((b) => b.max)),
+    buckets.reduce((total, b) => total + b.sum, 0),
+    buckets.reduce((total, b) => total + b.count, 0)
+  );
+}
+
+/**
+ * Distinct hours the timestamps fall in, oldest first
+ */
+export function touchedHours(timestamps: number[]): number[] {
+  return [...new Set(timestamps.map(hourStart))].sort((a, b) => a - b);
+}
+
+/**
+ * Distinct local days the timestamps fall in, oldest first
+ */
+export function touchedDays(timestamps: number[], timezone: string = DEFAULT_TIMEZONE): number[] {
+  return [...new Set(timestamps.map((t) => dayStart(t, timezone)))].sort((a, b) => a - b);
+}
@@ -0,0 +1,119 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import type { APIGatewayProxyEventV2, APIGatewayProxyStructuredResultV2 } from "aws-lambda";
+
+const { mockGetWidgetConfig, mockListWidgetConfigs, mockSaveWidgetConfig, mockRecordEvent } = vi.hoisted(() => ({
+  mockGetWidgetConfig: vi.fn(),
+  mockListWidgetConfigs: vi.fn(),
+  mockSaveWidgetConfig: vi.fn(),
+  mockRecordEvent: vi.fn(),
+}));
+
+vi.mock("./config-store", () => ({
+  getWidgetConfig: mockGetWidgetConfig,
+  listWidgetConfigs: mockListWidgetConfigs,
+  saveWidgetConfig: mockSaveWidgetConfig,
+}));
+
+vi.mock("../events/store", () => ({
+  recordEvent: mockRecordEvent,
+}));
+
+vi.mock("./registry", () => {
+  const widgets: Record<string, { name: string; schedule: string }> = {
+    clock: { name: "Clock", schedule: "rate(1 minute)" },
+    bloodsugar: { name: "Blood Sugar", schedule: "rate(1 minute)" },
+  };
+  return {
+    getWidget: (id: string) => widgets[id],
+    getWidgetIds: () => Object.keys(widgets),
+  };
+});
+
+import { handler, parseWidgetConfigBody } from "./config-api";
+
+function createEvent(method: string, body?: unknown): APIGatewayProxyEventV2 {
+  return {
+    requestContext: { http: { method } },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  } as unknown as APIGatewayProxyEventV2;
+}
+
+async function invoke(event: APIGatewayProxyEventV2) {
+  const result = (await handler(event, {} as never, () => {})) as APIGatewayProxyStructuredResultV2;
+  return { statusCode: result.statusCode, body: JSON.parse(result.body as string) };
+}
+
+describe("parseWidgetConfigBody", () => {
+  it("accepts enabled and settings changes", () => {
+    expect(parseWidgetConfigBody({ widgetId: " clock ", enabled: false })).toEqual({
+      widgetId: "clock",
+      enabled: false,
+      settings: undefined,
+    });
+    expect(parseWidgetConfigBody({ widgetId: "clock", settings: null })).toMatchObject({ settings: null });
+  });
+
+  it("rejects unknown widgets and bad values", () => {
+    expect(parseWidgetConfigBody({})).toMatch(/widgetId/);
+    expect(parseWidgetConfigBody({ widgetId: "weather", enabled: true })).toMatch(/clock, bloodsugar/);
+    expect(parseWidgetConfigBody({ widgetId: "clock" })).toMatch(/Nothing to change/);
+    expect(parseWidgetConfigBody({ widgetId: "clock", enabled: "yes" })).toMatch(/true or false/);
+    expect(parseWidgetConfigBody({ widgetId: "clock", settings: [1] })).toMatch(/object/);
+    expect(parseWidgetConfigBody({ widgetId: "clock", settings: { text: "x".repeat(5000) } })).toMatch(/4096/);
+  });
+});
+
+describe("handler", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGetWidgetConfig.mockResolvedValue(null);
+    mockListWidgetConfigs.mockResolvedValue([]);
+    mockSaveWidgetConfig.mockResolvedValue(undefined);
+  });
+
+  it("lists every widget, enabled unless configured otherwise", async () => {
+    mockListWidgetConfigs.mockResolvedValue([{ widgetId: "clock", enabled: false, settings: { format: "24h" } }]);
+
+    const { statusCode, body } = await invoke(createEvent("GET"));
+
+    expect(statusCode).toBe(200);
+    expect(body.widgets).toEqual([
+      { widgetId: "clock", name: "Clock", schedule: "rate(1 minute)", enabled: false, settings: { format: "24h" } },
+      { widgetId: "bloodsugar", name: "Blood Sugar", schedule: "rate(1 minute)", enabled: true, settings: {} },
+    ]);
+  });
+
+  it("keeps the settings when only enabled changes", async () => {
+    mockGetWidgetConfig.mockResolvedValue({ widgetId: "clock", enabled: false, settings: { format: "24h" } });
+
+    const { statusCode, body } = await invoke(createEvent("POST", { widgetId: "clock", enabled: true }));
+
+    expect(statusCode).toBe(200);
+    expect(mockSaveWidgetConfig).toHaveBeenCalledWith({ widgetId: "clock", enabled: true, settings: { format: "24h" } });
+    expect(body).toEqual({ widgetId: "clock", enabled: true, settings: { format: "24h" } });
+    expect(mockRecordEvent).toHaveBeenCalledWith(
+      expect.objectContaining({ category: "config", subject: "widgets", detail: '{"widgetId":"clock","enabled":true}' })
+    );
+  });
+
+  it("removes the settings when they're null", async () => {
+    mockGetWidgetConfig.mockResolvedValue({ widgetId: "clock", enabled: false, settings: { format: "24h" } });
+
+    await invoke(createEvent("POST", { widgetId: "clock", settings: null }));
+
+    expect(mockSaveWidgetConfig).toHaveBeenCalledWith({ widgetId: "clock", enabled: false });
+  });
+
+  it("returns 400 for an unknown widget", async () => {
+    const { statusCode, body } = await invoke(createEvent("POST", { widgetId: "weather", enabled: true }));
+
+    expect(statusCode).toBe(400);
+    expect(body.error).toMatch(/Unknown widget/);
+    expect(mockSaveWidgetConfig).not.toHaveBeenCalled();
+  });
+
+  it("rejects other methods", async () => {
+    const { statusCode } = await invoke(createEvent("DELETE"));
+    expect(statusCode).toBe(405);
+  });
+});
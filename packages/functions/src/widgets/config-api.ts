@@ -0,0 +1,120 @@
+/**
+ * Widget Config API
+ *
+ * GET /widgets   - every registered widget with its schedule, whether it runs,
+ *                  and its settings
+ * POST /widgets  - change a widget's config
+ *                  { "widgetId": "clock", "enabled": false }
+ *                  { "widgetId": "bloodsugar", "settings": { "units": "mmol" } }
+ *                  (null settings removes them; omitted fields are left alone)
+ *
+ * The dispatcher reads the config on every run, so a change applies from the
+ * widget's next scheduled update without a deploy.
+ */
+
+import type { APIGatewayProxyHandlerV2, APIGatewayProxyResultV2 } from "aws-lambda";
+import type { WidgetConfig } from "@signage/core";
+import { getWidget, getWidgetIds } from "./registry";
+import { getWidgetConfig, listWidgetConfigs, saveWidgetConfig } from "./config-store";
+import { recordEvent } from "../events/store";
+import { configChangedEvent } from "../events/transitions";
+
+/** Largest settings object accepted, as JSON */
+const MAX_SETTINGS_BYTES = 4096;
+
+function json(statusCode: number, body: unknown): APIGatewayProxyResultV2 {
+  return {
+    statusCode,
+    headers: {
+      "Content-Type": "application/json",
+      "Access-Control-Allow-Origin": "*",
+    },
+    body: JSON.stringify(body),
+  };
+}
+
+/**
+ * Validate a config change.
+ * Returns the widget and its changes, or an error message.
+ */
+export function parseWidgetConfigBody(
+  body: Record<string, unknown>
+): { widgetId: string; enabled?: boolean; settings?: Record<string, unknown> | null } | string {
+  if (typeof body.widgetId !== "string" || body.widgetId.trim() === "") {
+    return "widgetId is required";
+  }
+  const widgetId = body.widgetId.trim();
+  if (!getWidget(widgetId)) {
+    return `Unknown widget "${widgetId}"; widgets are: ${getWidgetIds().join(", ")}`;
+  }
+  if (body.enabled === undefined && body.settings === undefined) {
+    return "Nothing to change: pass enabled or settings";
+  }
+  if (body.enabled !== undefined && typeof body.enabled !== "boolean") {
+    return "enabled must be true or false";
+  }
+  if (body.settings !== undefined && body.settings !== null) {
+    if (typeof body.settings !== "object" || Array.isArray(body.settings)) {
+      return "settings must be null or an object";
+    }
+    if (JSON.stringify(body.settings).length > MAX_SETTINGS_BYTES) {
+      return `settings must be at most ${MAX_SETTINGS_BYTES} bytes as JSON`;
+    }
+  }
+
+  return {
+    widgetId,
+    enabled: body.enabled as boolean | undefined,
+    settings: body.settings as Record<string, unknown> | null | undefined,
+  };
+}
+
+export const handler: APIGatewayProxyHandlerV2 = async (event) => {
+  const method = event.requestContext.http.method;
+
+  if (method === "GET") {
+    const configs = new Map((await listWidgetConfigs()).map((config) => [config.widgetId, config]));
+    const widgets = getWidgetIds().map((widgetId) => {
+      const widget = getWidget(widgetId);
+      const config = configs.get(widgetId);
+      return {
+        widgetId,
+        name: widget.name,
+        schedule: widget.schedule,
+        enabled: config?.enabled ?? true,
+        settings: config?.settings ?? {},
+      };
+    });
+    return json(200, { widgets });
+  }
+
+  if (method === "POST") {
+    let body: Record<string, unknown>;
+    try {
+      body = JSON.parse(event.body || "{}");
+    } catch {
+      return json(400, { error: "Invalid JSON" });
+    }
+    if (typeof body !== "object" || body === null || Array.isArray(body)) {
+      return json(400, { error: "Body must be a JSON object" });
+    }
+
+    const parsed = parseWidgetConfigBody(body);
+    if (typeof parsed === "string") {
+      return json(400, { error: parsed });
+    }
+
+    const current = await getWidgetConfig(parsed.widgetId);
+    const settings = parsed.settings === undefined ? current?.settings : (parsed.settings ?? undefined);
+    const config: WidgetConfig = {
+      widgetId: parsed.widgetId,
+      enabled: parsed.enabled ?? current?.enabled ?? true,
+      ...(settings ? { settings } : {}),
+    };
+    await saveWidgetConfig(config);
+    await recordEvent(configChangedEvent("widgets", body));
+    return json(200, { ...config, settings: config.settings ?? {} });
+  }
+
+  return json(405, { error: `Method ${method} not allowed` });
+};
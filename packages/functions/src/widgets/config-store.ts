@@ -0,0 +1,74 @@
+/**
+ * Widget config store
+ * Whether each widget runs, and the settings passed to its updater. One item
+ * per configured widget under a shared partition, so they can be listed
+ * together. A widget without an item runs with its defaults.
+ */
+
+import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
+import { DynamoDBDocumentClient, GetCommand, PutCommand, QueryCommand } from "@aws-sdk/lib-dynamodb";
+import { Resource } from "sst";
+import type { WidgetConfig } from "@signage/core";
+
+const client = new DynamoDBClient({});
+const ddb = DynamoDBDocumentClient.from(client);
+
+const CONFIG_PK = "WIDGET_CONFIG";
+
+function configSk(widgetId: string): string {
+  return `WIDGET#${widgetId}`;
+}
+
+function toConfig(item: Record<string, unknown>): WidgetConfig {
+  return {
+    widgetId: item.widgetId as string,
+    enabled: item.enabled !== false,
+    ...(item.settings ? { settings: item.settings as Record<string, unknown> } : {}),
+  };
+}
+
+/**
+ * Save a widget's config, replacing any earlier one
+ */
+export async function saveWidgetConfig(config: WidgetConfig, now: number = Date.now()): Promise<void> {
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        pk: CONFIG_PK,
+        sk: configSk(config.widgetId),
+        widgetId: config.widgetId,
+        enabled: config.enabled,
+        ...(config.settings ? { settings: config.settings } : {}),
+        updatedAt: now,
+      },
+    })
+  );
+}
+
+/**
+ * Get a widget's config, or null when it has none
+ */
+export async function getWidgetConfig(widgetId: string): Promise<WidgetConfig | null> {
+  const result = await ddb.send(
+    new GetCommand({
+      TableName: Resource.SignageTable.name,
+      Key: { pk: CONFIG_PK, sk: configSk(widgetId) },
+    })
+  );
+  return result.Item ? toConfig(result.Item) : null;
+}
+
+/**
+ * List every saved widget config
+ */
+export async function listWidgetConfigs(): Promise<WidgetConfig[]> {
+  const result = await ddb.send(
+    new QueryCommand({
+      TableName: Resource.SignageTable.name,
+      KeyConditionExpression: "pk = :pk",
+      ExpressionAttributeValues: { ":pk": CONFIG_PK },
+    })
+  );
+  return (result.Items ?? []).map(toConfig);
+}
@@ -1,6 +1,8 @@
 /**
  * Widget Dispatcher Lambda Handler
  * Runs widget updates on a schedule, but only if active connections exist.
+ * Each run reads the widget's config, so disabling a widget or changing its
+ * settings takes effect from its next update.
  */
 
 import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
@@ -12,6 +14,7 @@ import { broadcastWidgetUpdate } from "./broadcast";
 import { getWidget } from "./registry";
 import type { WidgetUpdaterWithHistory, TimeSeriesPoint } from "./types";
 import { needsBackfill, storeDataPoints, storeDataPoint } from "./history-store";
+import { getWidgetConfig } from "./config-store";
 
 const ddbClient = new DynamoDBClient({});
 const ddb = DynamoDBDocumentClient.from(ddbClient);
@@ -52,6 +55,12 @@ export const handler = async (event: ScheduledEvent): Promise<void> => {
     return;
   }
 
+  const config = await getWidgetConfig(widgetId);
+  if (config && !config.enabled) {
+    console.log(`Widget disabled, skipping update for widget: ${widgetId}`);
+    return;
+  }
+
   try {
     // Check if this widget supports history and needs backfill
     const historyWidget = widget as WidgetUpdaterWithHistory;
@@ -61,7 +70,7 @@ export const handler = async (event: ScheduledEvent): Promise<void> => {
 
     // Run the widget update
     console.log(`Running update for widget: ${widget.name}`);
-    const data = await widget.update();
+    const data = await widget.update(config?.settings);
 
     // Store data point if history is enabled
     if (historyWidget.historyConfig?.enabled && data && typeof data === "object") {
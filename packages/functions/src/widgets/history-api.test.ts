@@ -0,0 +1,45 @@
+import { describe, it, expect, vi } from "vitest";
+
+vi.mock("./history-store", () => ({
+  queryHistory: vi.fn(),
+}));
+
+import { dexcomFetchWindow, mergeHistoryPoints } from "./history-api";
+
+const now = Date.UTC(2026, 9, 16, 12);
+
+describe("dexcomFetchWindow", () => {
+  it("fetches the full day when nothing is stored", () => {
+    expect(dexcomFetchWindow(null, now)).toEqual({ minutes: 1440, maxCount: 300 });
+  });
+
+  it("fetches only the gap since the last stored reading", () => {
+    // Reading 3 minutes ago: minimum window
+    expect(dexcomFetchWindow(now - 3 * 60000, now)).toEqual({ minutes: 30, maxCount: 8 });
+    // Two-hour outage: the gap plus 10 minutes of overlap
+    expect(dexcomFetchWindow(now - 120 * 60000, now)).toEqual({ minutes: 130, maxCount: 28 });
+  });
+
+  it("never asks for more than a day", () => {
+    expect(dexcomFetchWindow(now - 3 * 24 * 60 * 60000, now)).toEqual({ minutes: 1440, maxCount: 290 });
+  });
+});
+
+describe("mergeHistoryPoints", () => {
+  it("orders by time and keeps one point per timestamp", () => {
+    const stored = [
+      { timestamp: 1, glucose: 100 },
+      { timestamp: 2, glucose: 110 },
+    ];
+    const fetched = [
+      { timestamp: 3, glucose: 130 },
+      { timestamp: 2, glucose: 112 },
+    ];
+
+    expect(mergeHistoryPoints(stored, fetched)).toEqual([
+      { timestamp: 1, glucose: 100 },
+      { timestamp: 2, glucose: 112 },
+      { timestamp: 3, glucose: 130 },
+    ]);
+  });
+});
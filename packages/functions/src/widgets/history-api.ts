@@ -4,6 +4,7 @@
  */
 
 import { queryHistory } from "./history-store";
+import { DEFAULT_PROFILE_ID } from "../profiles/profile";
 import type { TimeSeriesPoint } from "./types";
 
 /**
@@ -60,16 +61,28 @@ export interface BloodSugarHistoryResponse {
 /** Default history query window in hours */
 const DEFAULT_HOURS = 24;
 
+/** Most Dexcom will return in one history request */
+const DEXCOM_MAX_MINUTES = 1440;
+const DEXCOM_MAX_COUNT = 300;
+
+/** Overlap with stored history when fetching, so a late reading isn't missed */
+const FETCH_OVERLAP_MINUTES = 10;
+
+/** Smallest fetch window (matches the latest-reading request) */
+const MIN_FETCH_MINUTES = 30;
+
 /** Normal range boundaries for time-in-range calculation */
 const NORMAL_RANGE = { low: 70, high: 180 };
 
 /**
  * Get blood sugar history for charting.
  * @param hours Number of hours of history to fetch (default: 24)
+ * @param profileId Whose history (default: the default profile)
  * @returns History points with calculated statistics
  */
 export async function getBloodSugarHistory(
-  hours: number = DEFAULT_HOURS
+  hours: number = DEFAULT_HOURS,
+  profileId: string = DEFAULT_PROFILE_ID
 ): Promise<BloodSugarHistoryResponse> {
   const now = Date.now();
   const since = now - hours * 60 * 60 * 1000;
@@ -77,7 +90,8 @@ export async function getBloodSugarHistory(
   const points = await queryHistory<BloodSugarHistoryValue>(
     "bloodsugar",
     since,
-    now
+    now,
+    profileId
   );
 
   if (points.length === 0) {
@@ -104,6 +118,35 @@ export async function getBloodSugarHistory(
   };
 }
 
+/**
+ * How much to ask Dexcom for, given the newest stored reading: only the gap
+ * since then (plus some overlap), or the full day when nothing is stored.
+ */
+export function dexcomFetchWindow(
+  lastStoredAt: number | null,
+  now: number = Date.now()
+): { minutes: number; maxCount: number } {
+  if (lastStoredAt === null) {
+    return { minutes: DEXCOM_MAX_MINUTES, maxCount: DEXCOM_MAX_COUNT };
+  }
+  const gapMinutes = Math.ceil((now - lastStoredAt) / 60000) + FETCH_OVERLAP_MINUTES;
+  const minutes = Math.min(DEXCOM_MAX_MINUTES, Math.max(MIN_FETCH_MINUTES, gapMinutes));
+  // One reading per 5 minutes, with room for a backfilled extra
+  return { minutes, maxCount: Math.min(DEXCOM_MAX_COUNT, Math.ceil(minutes / 5) + 2) };
+}
+
+/**
+ * Merge stored and freshly fetched points, oldest first, one per timestamp.
+ * A fetched point replaces a stored one at the same time.
+ */
+export function mergeHistoryPoints<T extends { timestamp: number }>(stored: T[], fetched: T[]): T[] {
+  const byTimestamp = new Map<number, T>();
+  for (const point of [...stored, ...fetched]) {
+    byTimestamp.set(point.timestamp, point);
+  }
+  return [...byTimestamp.values()].sort((a, b) => a.timestamp - b.timestamp);
+}
+
 /**
  * Calculate statistics from blood sugar history points.
  */
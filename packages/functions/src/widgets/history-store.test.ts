@@ -1,4 +1,4 @@
-import { describe, it, expect, vi, beforeEach } from "vitest";
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
 import type { TimeSeriesPoint, WidgetHistoryConfig } from "./types";
 
 // Create mockSend before vi.mock hoisting
@@ -38,6 +38,7 @@ const {
   getHistoryMeta,
   needsBackfill,
   isDuplicate,
+  queryAggregates,
 } = await import("./history-store");
 
 const TEST_CONFIG: WidgetHistoryConfig = {
@@ -161,6 +162,94 @@ describe("storeDataPoints", () => {
 
     expect(result).toEqual({ stored: 60, batches: 3 });
   });
+
+  it("namespaces another profile's points and metadata", async () => {
+    mockSend.mockResolvedValueOnce({}).mockResolvedValueOnce({ Item: null }).mockResolvedValueOnce({});
+
+    await storeDataPoints("bloodsugar", [{ timestamp: 1737196200000, value: { glucose: 120 } }], TEST_CONFIG, "household-2");
+
+    const batch = mockSend.mock.calls[0][0].RequestItems["test-table"];
+    expect(batch[0].PutRequest.Item.pk).toBe("PROFILE#household-2#WIDGET#bloodsugar#HISTORY");
+    expect(mockSend.mock.calls[1][0].Key.pk).toBe("PROFILE#household-2#WIDGET#bloodsugar#HISTORY");
+    expect(mockSend.mock.calls[2][0].Item.pk).toBe("PROFILE#household-2#WIDGET#bloodsugar#HISTORY");
+  });
+});
+
+describe("aggregates", () => {
+  const hour = Date.UTC(2026, 9, 16, 12);
+  const config: WidgetHistoryConfig = { ...TEST_CONFIG, aggregateField: "glucose" };
+
+  beforeEach(() => {
+    mockSend.mockReset();
+    vi.useFakeTimers({ now: hour + 20 * 60000 });
+  });
+
+  afterEach(() => {
+    vi.useRealTimers();
+  });
+
+  it("rebuilds the point's hour from stored points, then its day from the hours", async () => {
+    mockSend
+      .mockResolvedValueOnce({}) // Put point
+      .mockResolvedValueOnce({ Item: null }) // Get meta
+      .mockResolvedValueOnce({}) // Put meta
+      .mockResolvedValueOnce({
+        Items: [
+          { timestamp: hour + 5 * 60000, value: { glucose: 110 } },
+          { timestamp: hour + 10 * 60000, value: { glucose: 130 } },
+        ],
+      }) // Query the hour's points
+      .mockResolvedValueOnce({}) // Put hourly bucket
+      .mockResolvedValueOnce({
+        Items: [
+          { start: hour - 3600000, min: 80, max: 100, avg: 90, sum: 1080, count: 12 },
+          { start: hour, min: 110, max: 130, avg: 120, sum: 240, count: 2 },
+        ],
+      }) // Query the day's hours
+      .mockResolvedValueOnce({}); // Put daily bucket
+
+    await storeDataPoint("bloodsugar", { timestamp: hour + 10 * 60000, value: { glucose: 130 } }, config);
+
+    const hourly = mockSend.mock.calls[4][0];
+    expect(hourly.Item).toMatchObject({
+      pk: "WIDGET#bloodsugar#HOURLY",
+      sk: "TS#2026-10-16T12:00:00.000Z",
+      min: 110,
+      max: 130,
+      count: 2,
+    });
+    const daily = mockSend.mock.calls[6][0];
+    expect(daily.Item).toMatchObject({
+      pk: "WIDGET#bloodsugar#DAILY",
+      sk: "TS#2026-10-16T07:00:00.000Z", // Midnight in Los Angeles
+      min: 80,
+      max: 130,
+      avg: 94.29,
+      count: 14,
+    });
+  });
+
+  it("leaves hours older than the retention window alone", async () => {
+    mockSend
+      .mockResolvedValueOnce({})
+      .mockResolvedValueOnce({ Item: null })
+      .mockResolvedValueOnce({});
+
+    await storeDataPoint("bloodsugar", { timestamp: hour - 48 * 3600000, value: { glucose: 130 } }, config);
+
+    expect(mockSend).toHaveBeenCalledTimes(3);
+  });
+
+  it("queries buckets by resolution", async () => {
+    mockSend.mockResolvedValueOnce({
+      Items: [{ pk: "x", sk: "y", start: hour, min: 1, max: 3, avg: 2, sum: 4, count: 2, ttl: 1 }],
+    });
+
+    const buckets = await queryAggregates("bloodsugar", "day", hour - 7 * 24 * 3600000, hour);
+
+    expect(mockSend.mock.calls[0][0].ExpressionAttributeValues[":pk"]).toBe("WIDGET#bloodsugar#DAILY");
+    expect(buckets).toEqual([{ start: hour, min: 1, max: 3, avg: 2, sum: 4, count: 2 }]);
+  });
 });
 
 describe("queryHistory", () => {
@@ -191,6 +280,16 @@ describe("queryHistory", () => {
     );
   });
 
+  it("queries another profile's history under its own key", async () => {
+    mockSend.mockResolvedValueOnce({ Items: [] });
+
+    await queryHistory("bloodsugar", 0, Date.now(), "household-2");
+
+    expect(mockSend.mock.calls[0][0].ExpressionAttributeValues[":pk"]).toBe(
+      "PROFILE#household-2#WIDGET#bloodsugar#HISTORY"
+    );
+  });
+
   it("returns formatted time series points", async () => {
     mockSend.mockResolvedValueOnce({
       Items: [
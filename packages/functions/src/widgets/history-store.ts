@@ -1,6 +1,9 @@
 /**
  * Widget History Store
  * DynamoDB operations for time-series data storage with TTL.
+ *
+ * Series that belong to a household (glucose history) are namespaced by
+ * profile; device-wide ones (ticker, network) use the default profile.
  */
 
 import { DynamoDBClient } from "@aws-sdk/client-dynamodb";
@@ -17,6 +20,19 @@ import type {
   WidgetHistoryConfig,
   WidgetHistoryMeta,
 } from "./types";
+import {
+  combineBuckets,
+  DAILY_RETENTION_DAYS,
+  HOUR_MS,
+  HOURLY_RETENTION_DAYS,
+  nextDayStart,
+  summarizePoints,
+  touchedDays,
+  touchedHours,
+  type AggregateBucket,
+  type AggregateResolution,
+} from "./aggregates";
+import { DEFAULT_PROFILE_ID, profilePk } from "../profiles/profile";
 
 const client = new DynamoDBClient({});
 const ddb = DynamoDBDocumentClient.from(client);
@@ -25,10 +41,10 @@ const ddb = DynamoDBDocumentClient.from(client);
 const BATCH_WRITE_LIMIT = 25;
 
 /**
- * Build the partition key for widget history.
+ * Build the partition key for a profile's widget history.
  */
-function historyPk(widgetId: string): string {
-  return `WIDGET#${widgetId}#HISTORY`;
+function historyPk(widgetId: string, profileId: string): string {
+  return profilePk(profileId, `WIDGET#${widgetId}#HISTORY`);
 }
 
 /**
@@ -51,9 +67,10 @@ export function calculateTTL(retentionHours: number): number {
 export async function storeDataPoint<T>(
   widgetId: string,
   point: TimeSeriesPoint<T>,
-  config: WidgetHistoryConfig
+  config: WidgetHistoryConfig,
+  profileId: string = DEFAULT_PROFILE_ID
 ): Promise<void> {
-  const pk = historyPk(widgetId);
+  const pk = historyPk(widgetId, profileId);
   const sk = timestampSk(point.timestamp);
 
   await ddb.send(
@@ -71,7 +88,8 @@ export async function storeDataPoint<T>(
   );
 
   // Update metadata with latest timestamp
-  await updateHistoryMeta(widgetId, point.timestamp);
+  await updateHistoryMeta(widgetId, point.timestamp, 1, profileId);
+  await refreshAggregates(widgetId, [point.timestamp], config, profileId);
 }
 
 /**
@@ -81,13 +99,14 @@ export async function storeDataPoint<T>(
 export async function storeDataPoints<T>(
   widgetId: string,
   points: TimeSeriesPoint<T>[],
-  config: WidgetHistoryConfig
+  config: WidgetHistoryConfig,
+  profileId: string = DEFAULT_PROFILE_ID
 ): Promise<{ stored: number; batches: number }> {
   if (points.length === 0) {
     return { stored: 0, batches: 0 };
   }
 
-  const pk = historyPk(widgetId);
+  const pk = historyPk(widgetId, profileId);
   const ttl = calculateTTL(config.retentionHours);
 
   // Build all put requests
@@ -120,7 +139,8 @@ export async function storeDataPoints<T>(
 
   // Update metadata with the latest timestamp from the batch
   const latestTimestamp = Math.max(...points.map((p) => p.timestamp));
-  await updateHistoryMeta(widgetId, latestTimestamp, points.length);
+  await updateHistoryMeta(widgetId, latestTimestamp, points.length, profileId);
+  await refreshAggregates(widgetId, points.map((p) => p.timestamp), config, profileId);
 
   return { stored: points.length, batches: batchCount };
 }
@@ -131,9 +151,10 @@ export async function storeDataPoints<T>(
 export async function queryHistory<T>(
   widgetId: string,
   since: number,
-  until: number = Date.now()
+  until: number = Date.now(),
+  profileId: string = DEFAULT_PROFILE_ID
 ): Promise<TimeSeriesPoint<T>[]> {
-  const pk = historyPk(widgetId);
+  const pk = historyPk(widgetId, profileId);
   const sinceSk = timestampSk(since);
   const untilSk = timestampSk(until);
 
@@ -161,12 +182,13 @@ export async function queryHistory<T>(
  * Get the history metadata record.
  */
 export async function getHistoryMeta(
-  widgetId: string
+  widgetId: string,
+  profileId: string = DEFAULT_PROFILE_ID
 ): Promise<WidgetHistoryMeta | null> {
   const result = await ddb.send(
     new GetCommand({
       TableName: Resource.SignageTable.name,
-      Key: { pk: historyPk(widgetId), sk: "META" },
+      Key: { pk: historyPk(widgetId, profileId), sk: "META" },
     })
   );
 
@@ -179,13 +201,14 @@ export async function getHistoryMeta(
 async function updateHistoryMeta(
   widgetId: string,
   latestTimestamp: number,
-  pointsAdded: number = 1
+  pointsAdded: number,
+  profileId: string
 ): Promise<void> {
-  const pk = historyPk(widgetId);
+  const pk = historyPk(widgetId, profileId);
   const now = Date.now();
 
   // Get existing meta to update totalPointsStored
-  const existing = await getHistoryMeta(widgetId);
+  const existing = await getHistoryMeta(widgetId, profileId);
   const newTotal = (existing?.totalPointsStored || 0) + pointsAdded;
 
   await ddb.send(
@@ -208,9 +231,10 @@ async function updateHistoryMeta(
  */
 export async function needsBackfill(
   widgetId: string,
-  config: WidgetHistoryConfig
+  config: WidgetHistoryConfig,
+  profileId: string = DEFAULT_PROFILE_ID
 ): Promise<{ needed: boolean; gapMinutes: number; since?: number }> {
-  const meta = await getHistoryMeta(widgetId);
+  const meta = await getHistoryMeta(widgetId, profileId);
 
   if (!meta?.lastDataPointAt) {
     // No history exists, need full backfill
@@ -239,14 +263,111 @@ export async function needsBackfill(
 export async function isDuplicate(
   widgetId: string,
   timestamp: number,
-  dedupeWindowMinutes: number
+  dedupeWindowMinutes: number,
+  profileId: string = DEFAULT_PROFILE_ID
 ): Promise<boolean> {
   const windowMs = dedupeWindowMinutes * 60 * 1000;
   const since = timestamp - windowMs;
   const until = timestamp + windowMs;
 
-  const existing = await queryHistory(widgetId, since, until);
+  const existing = await queryHistory(widgetId, since, until, profileId);
   return existing.some(
     (p) => Math.abs(p.timestamp - timestamp) < windowMs
   );
 }
+
+/**
+ * Build the partition key for a profile's hourly or daily buckets.
+ */
+function aggregatePk(widgetId: string, resolution: AggregateResolution, profileId: string): string {
+  return profilePk(profileId, `WIDGET#${widgetId}#${resolution === "hour" ? "HOURLY" : "DAILY"}`);
+}
+
+async function storeBucket(
+  widgetId: string,
+  resolution: AggregateResolution,
+  bucket: AggregateBucket,
+  profileId: string
+): Promise<void> {
+  const retentionDays = resolution === "hour" ? HOURLY_RETENTION_DAYS : DAILY_RETENTION_DAYS;
+  await ddb.send(
+    new PutCommand({
+      TableName: Resource.SignageTable.name,
+      Item: {
+        pk: aggregatePk(widgetId, resolution, profileId),
+        sk: timestampSk(bucket.start),
+        ...bucket,
+        ttl: Math.floor(bucket.start / 1000) + retentionDays * 24 * 3600,
+      },
+    })
+  );
+}
+
+/**
+ * Rebuild the hourly and daily buckets that newly stored points fall in.
+ * Hours whose raw points may already have expired are left as they are,
+ * since rebuilding them would drop what's no longer stored. Failures are
+ * logged: the raw points are stored either way.
+ */
+async function refreshAggregates(
+  widgetId: string,
+  timestamps: number[],
+  config: WidgetHistoryConfig,
+  profileId: string
+): Promise<void> {
+  const field = config.aggregateField;
+  if (!field) return;
+
+  const oldest = Date.now() - config.retentionHours * HOUR_MS;
+  const hours = touchedHours(timestamps).filter((start) => start >= oldest);
+  if (hours.length === 0) return;
+
+  try {
+    for (const start of hours) {
+      const points = await queryHistory(widgetId, start, start + HOUR_MS - 1, profileId);
+      const bucket = summarizePoints(points, field, start);
+      if (bucket) await storeBucket(widgetId, "hour", bucket, profileId);
+    }
+    for (const start of touchedDays(hours)) {
+      const hourly = await queryAggregates(widgetId, "hour", start, nextDayStart(start) - 1, profileId);
+      const bucket = combineBuckets(hourly, start);
+      if (bucket) await storeBucket(widgetId, "day", bucket, profileId);
+    }
+  } catch (error) {
+    console.error(`Failed to update ${widgetId} aggregates:`, error);
+  }
+}
+
+/**
+ * Query a widget's hourly or daily buckets that start within a time range,
+ * oldest first.
+ */
+export async function queryAggregates(
+  widgetId: string,
+  resolution: AggregateResolution,
+  since: number,
+  until: number = Date.now(),
+  profileId: string = DEFAULT_PROFILE_ID
+): Promise<AggregateBucket[]> {
+  const result = await ddb.send(
+    new QueryCommand({
+      TableName: Resource.SignageTable.name,
+      KeyConditionExpression: "pk = :pk AND sk BETWEEN :since AND :until",
+      ExpressionAttributeValues: {
+        ":pk": aggregatePk(widgetId, resolution, profileId),
+        ":since": timestampSk(since),
+        ":until": timestampSk(until),
+      },
+      ScanIndexForward: true,
+    })
+  );
+
+  return (result.Items || []).map((item) => ({
+    start: item.start as number,
+    min: item.min as number,
+    max: item.max as number,
+    avg: item.avg as number,
+    sum: item.sum as number,
+    count: item.count as number,
+  }));
+}
@@ -65,6 +65,11 @@ export interface WidgetHistoryConfig {
   dedupeWindowMinutes: number;
   /** Type of storage pattern */
   storageType: "time-series" | "content-cache";
+  /**
+   * Numeric field of each value to also keep hourly and daily min/max/avg
+   * of, for long-range charts (see aggregates.ts)
+   */
+  aggregateField?: string;
 }
 
 /**
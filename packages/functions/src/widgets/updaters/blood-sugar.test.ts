@@ -3,6 +3,7 @@ import {
   bloodSugarUpdater,
   classifyRange,
   mapTrendArrow,
+  calculateDelta,
   isStale,
   type BloodSugarData,
 } from "./blood-sugar";
@@ -81,6 +82,34 @@ describe("mapTrendArrow", () => {
     expect(mapTrendArrow("Unknown")).toBe("?");
     expect(mapTrendArrow("")).toBe("?");
   });
+
+  it("maps sensor glitch trends to distinct glyphs", () => {
+    expect(mapTrendArrow("NotComputable")).toBe("-");
+    expect(mapTrendArrow("RateOutOfRange")).toBe("⇅");
+  });
+});
+
+describe("calculateDelta", () => {
+  const reading = (Value: number, Trend: string) => ({
+    WT: "Date(1700000000000)",
+    ST: "Date(1700000000000)",
+    DT: "Date(1700000000000)",
+    Value,
+    Trend,
+  });
+
+  it("returns the change from the previous reading", () => {
+    expect(calculateDelta(reading(120, "Flat"), reading(115, "Flat"))).toBe(5);
+  });
+
+  it("returns 0 without a previous reading", () => {
+    expect(calculateDelta(reading(120, "Flat"))).toBe(0);
+  });
+
+  it("suppresses the delta when the trend is not computable", () => {
+    expect(calculateDelta(reading(180, "RateOutOfRange"), reading(120, "Flat"))).toBe(0);
+    expect(calculateDelta(reading(120, "NotComputable"), reading(110, "Flat"))).toBe(0);
+  });
 });
 
 describe("isStale", () => {
@@ -216,6 +245,18 @@ describe("bloodSugarUpdater.update", () => {
     expect(result.delta).toBe(10); // 130 - 120
   });
 
+  it("skips implausible readings from sensor glitches", async () => {
+    const now = Date.now();
+    vi.spyOn(console, "warn").mockImplementation(() => {});
+    mockDexcomResponses([
+      { Value: 0, Trend: "Flat", WT: `Date(${now})` },
+      { Value: 120, Trend: "Flat", WT: `Date(${now - 5 * 60 * 1000})` },
+    ]);
+
+    const result = (await bloodSugarUpdater.update()) as BloodSugarData;
+    expect(result.glucose).toBe(120);
+  });
+
   it("returns delta of 0 when only one reading available", async () => {
     const now = Date.now();
     mockDexcomResponses([
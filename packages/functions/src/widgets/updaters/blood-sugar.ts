@@ -14,12 +14,17 @@ import type {
 import {
   getSessionId,
   fetchGlucoseReadings,
+  isTrendComputable,
   parseDexcomTimestamp,
   type DexcomReading,
 } from "../../dexcom/client.js";
+import { DEXCOM_LOGIN_CALLS } from "../../dexcom/rate-limit.js";
+import { reserveDexcomCalls } from "../../dexcom/rate-limit-store.js";
+import { filterGlucoseReadings, recordRejections } from "../../ingest/sanity-filters.js";
 import { storeRecords, createDocClient } from "@diabetes/core";
 import type { CgmReading } from "@diabetes/core";
 import type { DynamoDBDocumentClient } from "@aws-sdk/lib-dynamodb";
+import { DEFAULT_PROFILE_ID, profileUserId } from "../../profiles/profile.js";
 
 /** Reusable DynamoDB document client (lazy-initialized) */
 let docClient: DynamoDBDocumentClient | null = null;
@@ -40,7 +45,7 @@ export interface BloodSugarData {
   trend: string;
   /** Display arrow character (→, ↗, ↑, ↘, ↓, etc.) */
   trendArrow: string;
-  /** Change from previous reading in mg/dL */
+  /** Change from previous reading in mg/dL (0 when the trend is not computable) */
   delta: number;
   /** Unix timestamp of reading in milliseconds */
   timestamp: number;
@@ -70,6 +75,8 @@ const TREND_ARROWS: Record<string, string> = {
   fortyfivedown: "↘",
   singledown: "↓",
   doubledown: "↓↓",
+  notcomputable: "-",
+  rateoutofrange: "⇅",
 };
 
 /**
@@ -92,6 +99,15 @@ export function mapTrendArrow(trend: string): string {
   return TREND_ARROWS[trend.toLowerCase()] ?? "?";
 }
 
+/**
+ * Calculate the change between readings, suppressed when the trend
+ * is not computable.
+ */
+export function calculateDelta(latest: DexcomReading, previous?: DexcomReading): number {
+  if (!previous || !isTrendComputable(latest.Trend)) return 0;
+  return latest.Value - previous.Value;
+}
+
 /**
  * Check if a reading timestamp is stale (>10 minutes old).
  */
@@ -106,26 +122,27 @@ function mgdlToMmol(mgdl: number): number {
   return Math.round((mgdl / 18.0182) * 10) / 10;
 }
 
-/** Blood sugar history configuration */
-const HISTORY_CONFIG: WidgetHistoryConfig = {
+/** Blood sugar history configuration (shared with the compositor's history sync) */
+export const HISTORY_CONFIG: WidgetHistoryConfig = {
   enabled: true,
   retentionHours: 24,
   backfillDepthHours: 24,
   backfillThresholdMinutes: 15,
   dedupeWindowMinutes: 5,
   storageType: "time-series",
+  aggregateField: "glucose",
 };
 
 /**
  * Convert a Dexcom reading to a time-series point for storage.
  */
-function readingToTimeSeriesPoint(
+export function readingToTimeSeriesPoint(
   reading: DexcomReading,
   prevReading?: DexcomReading
 ): TimeSeriesPoint<Pick<BloodSugarData, "glucose" | "glucoseMmol" | "rangeStatus">> {
   const mgdl = reading.Value;
   const timestamp = parseDexcomTimestamp(reading.WT);
-  const delta = prevReading ? mgdl - prevReading.Value : 0;
+  const delta = calculateDelta(reading, prevReading);
 
   return {
     timestamp,
@@ -155,9 +172,6 @@ function dexcomToCgmRecord(reading: DexcomReading): CgmReading {
   };
 }
 
-/** Default user ID for single-user system (consistent with other tools) */
-const DEFAULT_USER_ID = "john";
-
 /**
  * Store CGM readings for agent analysis (dual-write).
  * This writes readings to the same DynamoDB table but with different keys
@@ -173,7 +187,7 @@ async function storeCgmReadingsForAgent(readings: DexcomReading[]): Promise<void
     const result = await storeRecords(
       getDocClient(),
       tableName,
-      DEFAULT_USER_ID,
+      profileUserId(DEFAULT_PROFILE_ID),
       cgmRecords
     );
 
@@ -198,15 +212,22 @@ export const bloodSugarUpdater: WidgetUpdaterWithHistory = {
   historyConfig: HISTORY_CONFIG,
 
   async update(): Promise<BloodSugarData> {
+    // Logs in and fetches once, from the same budget as the compositor
+    if (!(await reserveDexcomCalls(DEXCOM_LOGIN_CALLS + 1))) {
+      throw new Error("Dexcom request budget spent");
+    }
     const sessionId = await getSessionId({
       username: Resource.DexcomUsername.value,
       password: Resource.DexcomPassword.value,
     });
 
     // Fetch latest 2 readings for delta calculation
-    const readings = await fetchGlucoseReadings(sessionId, 30, 2);
+    const { accepted: readings, rejected } = filterGlucoseReadings(
+      (await fetchGlucoseReadings(sessionId, 30, 2)) ?? []
+    );
+    recordRejections("dexcom", rejected);
 
-    if (!readings || readings.length === 0) {
+    if (readings.length === 0) {
       throw new Error("No glucose readings available");
     }
 
@@ -221,7 +242,7 @@ export const bloodSugarUpdater: WidgetUpdaterWithHistory = {
     const latestTimestamp = parseDexcomTimestamp(latest.WT);
 
     // Calculate delta (change from previous reading)
-    const delta = previous ? latestMgdl - previous.Value : 0;
+    const delta = calculateDelta(latest, previous);
 
     return {
       glucose: latestMgdl,
@@ -236,6 +257,9 @@ export const bloodSugarUpdater: WidgetUpdaterWithHistory = {
   },
 
   async fetchHistory(since: number, until: number): Promise<TimeSeriesPoint[]> {
+    if (!(await reserveDexcomCalls(DEXCOM_LOGIN_CALLS + 1))) {
+      throw new Error("Dexcom request budget spent");
+    }
     const sessionId = await getSessionId({
       username: Resource.DexcomUsername.value,
       password: Resource.DexcomPassword.value,
@@ -253,9 +277,12 @@ export const bloodSugarUpdater: WidgetUpdaterWithHistory = {
       `Fetching blood sugar history: ${clampedMinutes} minutes, maxCount=${maxCount}`
     );
 
-    const readings = await fetchGlucoseReadings(sessionId, clampedMinutes, maxCount);
+    const { accepted: readings, rejected } = filterGlucoseReadings(
+      (await fetchGlucoseReadings(sessionId, clampedMinutes, maxCount)) ?? []
+    );
+    recordRejections("dexcom-history", rejected);
 
-    if (!readings || readings.length === 0) {
+    if (readings.length === 0) {
       return [];
     }
 
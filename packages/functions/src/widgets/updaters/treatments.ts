@@ -14,6 +14,7 @@ import type {
   GlookoTreatmentsItem,
 } from "../../glooko/types.js";
 import { calculateTreatmentTotals } from "../../rendering/treatment-renderer.js";
+import { DEFAULT_PROFILE_ID, profileUserId } from "../../profiles/profile.js";
 
 /** Stale threshold: 6 hours in milliseconds */
 const STALE_THRESHOLD_MS = 6 * 60 * 60 * 1000;
@@ -64,9 +65,6 @@ async function fetchTreatmentData(): Promise<{
   }
 }
 
-/** Default user ID for daily insulin queries */
-const DEFAULT_USER_ID = "john";
-
 /**
  * Compute a date string N days before a given date string.
  * Uses calendar-day arithmetic to handle DST transitions correctly.
@@ -92,7 +90,7 @@ async function fetchDailyInsulinTotals(): Promise<Record<string, number>> {
     return await queryDailyInsulinByDateRange(
       docClient,
       Resource.SignageTable.name,
-      DEFAULT_USER_ID,
+      profileUserId(DEFAULT_PROFILE_ID),
       startDate,
       endDate
     );
@@ -0,0 +1,104 @@
+/**
+ * Snooze the display's glucose alerts, or see what's snoozed
+ *
+ * Usage:
+ *   pnpm alerts ack --url https://api.signage.example.com --token <token>
+ *   SIGNAGE_API_URL=https://api.signage.example.com SIGNAGE_API_TOKEN=<token> pnpm alerts ack --type high --minutes 60
+ *   pnpm alerts unack [--type high]
+ *   pnpm alerts status
+ *
+ * Options:
+ *   --url <url>        API base URL (default: $SIGNAGE_API_URL)
+ *   --token <token>    API token, for ack and unack (default: $SIGNAGE_API_TOKEN)
+ *   --type <type>      Alert type: urgentLow, urgentLowSoon, fallingFast,
+ *                      high, risingFast or signalLoss (default: every alert
+ *                      showing, or every snooze for unack)
+ *   --minutes <min>    Snooze length (default: 30; urgent alerts at most 60)
+ */
+
+import { parseArgs } from "node:util";
+
+const COMMANDS = ["ack", "unack", "status"];
+
+const { values, positionals } = parseArgs({
+  allowPositionals: true,
+  options: {
+    url: { type: "string" },
+    token: { type: "string" },
+    type: { type: "string" },
+    minutes: { type: "string" },
+  },
+});
+
+const command = positionals[0] ?? "status";
+if (!COMMANDS.includes(command)) {
+  console.error(`Unknown command "${command}" (use ${COMMANDS.join(", ")})`);
+  process.exit(1);
+}
+
+const baseUrl = values.url ?? process.env.SIGNAGE_API_URL;
+if (!baseUrl) {
+  console.error("Set --url or SIGNAGE_API_URL to the API base URL");
+  process.exit(1);
+}
+
+const token = values.token ?? process.env.SIGNAGE_API_TOKEN;
+if (command !== "status" && !token) {
+  console.error("Set --token or SIGNAGE_API_TOKEN to the API token");
+  process.exit(1);
+}
+
+const apiUrl = `${baseUrl.replace(/\/+$/, "")}/alerts`;
+
+function requestFor(name: string): { url: string; init: RequestInit } {
+  switch (name) {
+    case "ack":
+      return {
+        url: `${apiUrl}/ack`,
+        init: {
+          method: "POST",
+          body: JSON.stringify({
+            type: values.type,
+            minutes: values.minutes === undefined ? undefined : Number(values.minutes),
+          }),
+        },
+      };
+    case "unack":
+      return {
+        url: `${apiUrl}/ack${values.type ? `?type=${encodeURIComponent(values.type)}` : ""}`,
+        init: { method: "DELETE" },
+      };
+    default:
+      return { url: apiUrl, init: { method: "GET" } };
+  }
+}
+
+/**
+ * One line per snooze
+ */
+function describe(acknowledgments: Array<Record<string, unknown>>): string {
+  if (acknowledgments.length === 0) return "No alerts snoozed";
+  return acknowledgments
+    .map((a) => {
+      const baseline = a.glucose === undefined ? "" : `, back early past ${a.glucose} mg/dL`;
+      return `${a.type} snoozed until ${new Date(Number(a.until)).toLocaleTimeString()}${baseline}`;
+    })
+    .join("\n");
+}
+
+const { url, init } = requestFor(command);
+try {
+  const response = await fetch(url, {
+    ...init,
+    headers: { "Content-Type": "application/json", ...(token && { Authorization: `Bearer ${token}` }) },
+  });
+  const body = (await response.json()) as Record<string, unknown>;
+  if (!response.ok) {
+    console.error(`Alerts ${command} failed: ${response.status} ${body.error ?? ""}`);
+    process.exit(1);
+  }
+  console.log(describe(body.acknowledgments as Array<Record<string, unknown>>));
+} catch (error) {
+  console.error(`Could not reach ${url}: ${error instanceof Error ? error.message : String(error)}`);
+  process.exit(1);
+}
@@ -0,0 +1,103 @@
+/**
+ * Set the display's text banner, push values for it, or clear it
+ *
+ * Usage:
+ *   pnpm banner "Door: {{.sensor.front_door}}" --url https://api.signage.example.com
+ *   SIGNAGE_API_URL=https://api.signage.example.com pnpm banner --set sensor.front_door=OPEN
+ *   pnpm banner "BG {{.glucose.value}} {{.sensor.temp}}C" --set sensor.temp=21.5
+ *   pnpm banner --show
+ *   pnpm banner --clear
+ *   pnpm banner "Hello" --url http://localhost:8080    # the local server
+ *
+ * Options:
+ *   --url <url>          API base URL (default: $SIGNAGE_API_URL)
+ *   --set <path=value>   Push a value (repeatable); numbers and true/false
+ *                        are sent as such, an empty value removes it
+ *   --hide               Remove the template, keeping the values
+ *   --show               Show the banner instead
+ *   --clear              Remove the banner and its values instead
+ */
+
+import { parseArgs } from "node:util";
+
+const { values, positionals } = parseArgs({
+  allowPositionals: true,
+  options: {
+    url: { type: "string" },
+    set: { type: "string", multiple: true },
+    hide: { type: "boolean", default: false },
+    show: { type: "boolean", default: false },
+    clear: { type: "boolean", default: false },
+  },
+});
+
+const template = positionals.join(" ");
+const sets = values.set ?? [];
+if (!template && sets.length === 0 && !values.hide && !values.show && !values.clear) {
+  console.error('Give a template, e.g. pnpm banner "Door: {{.sensor.front_door}}" (or --set, --hide, --show, --clear)');
+  process.exit(1);
+}
+
+const baseUrl = values.url ?? process.env.SIGNAGE_API_URL;
+if (!baseUrl) {
+  console.error("Set --url or SIGNAGE_API_URL to the API base URL");
+  process.exit(1);
+}
+
+const url = `${baseUrl.replace(/\/+$/, "")}/banner`;
+
+/**
+ * Turn "sensor.front_door=OPEN" flags into nested values
+ */
+function parseSets(pairs: string[]): Record<string, unknown> {
+  const result: Record<string, unknown> = {};
+  for (const pair of pairs) {
+    const split = pair.indexOf("=");
+    if (split <= 0) {
+      console.error(`--set needs path=value, got "${pair}"`);
+      process.exit(1);
+    }
+    const path = pair.slice(0, split).split(".");
+    const raw = pair.slice(split + 1);
+    const value = raw === "" ? null : raw === "true" ? true : raw === "false" ? false : Number.isFinite(Number(raw)) ? Number(raw) : raw;
+
+    let group = result;
+    for (const name of path.slice(0, -1)) {
+      group[name] = (group[name] as Record<string, unknown> | undefined) ?? {};
+      group = group[name] as Record<string, unknown>;
+    }
+    group[path[path.length - 1]] = value;
+  }
+  return result;
+}
+
+function request(): RequestInit {
+  if (values.show) return { method: "GET" };
+  if (values.clear) return { method: "DELETE" };
+  return {
+    method: "POST",
+    body: JSON.stringify({
+      template: values.hide ? null : template || undefined,
+      values: sets.length > 0 ? parseSets(sets) : undefined,
+    }),
+  };
+}
+
+try {
+  const response = await fetch(url, { ...request(), headers: { "Content-Type": "application/json" } });
+  const body = (await response.json()) as Record<string, unknown>;
+  if (!response.ok) {
+    console.error(`Banner failed: ${response.status} ${body.error ?? ""}`);
+    process.exit(1);
+  }
+  if (values.clear) {
+    console.log("Banner cleared");
+  } else {
+    console.log(body.template ? `Template: ${body.template}` : "No template (nothing shows)");
+    console.log(`Values:   ${JSON.stringify(body.values)}`);
+    if (body.text) console.log(`Shows:    ${body.text}`);
+  }
+} catch (error) {
+  console.error(`Could not reach ${url}: ${error instanceof Error ? error.message : String(error)}`);
+  process.exit(1);
+}
@@ -1,10 +1,19 @@
 /**
  * Debug script to render current frame as ASCII
+ *
+ * Options:
+ *   --png <file>   Also save the frame as a PNG
+ *   --gif <file>   Also save a fade from the previous reading as an animated GIF
+ *   --scale <n>    Upscale factor for saved images (default: 8)
  */
 
+import { parseArgs } from "node:util";
+import { createTransitionFrames } from "@signage/core";
 import {
   generateCompositeFrame,
   frameToAsciiDetailed,
+  exportPng,
+  exportGif,
   type BloodSugarDisplayData,
 } from "@signage/functions/rendering";
 import type { TreatmentDisplayData, GlookoTreatment } from "@signage/functions/glooko/types";
@@ -79,3 +88,30 @@ const frame = generateCompositeFrame({
 
 // Output ASCII
 console.log(frameToAsciiDetailed(frame));
+
+const { values: args } = parseArgs({
+  options: {
+    png: { type: "string" },
+    gif: { type: "string" },
+    scale: { type: "string", default: "8" },
+  },
+});
+const scale = Number(args.scale) || 8;
+
+if (args.png) {
+  await exportPng(frame, args.png, { scale });
+  console.log(`Saved ${args.png}`);
+}
+
+if (args.gif) {
+  // Previous minute: 4 mg/dL lower, then fade to the current frame
+  const previousFrame = generateCompositeFrame({
+    bloodSugar: { ...sampleBloodSugar, glucose: sampleBloodSugar.glucose - sampleBloodSugar.delta },
+    bloodSugarHistory: { points: sampleHistory.slice(0, -1) },
+    timezone: "America/Los_Angeles",
+    treatments: sampleTreatmentData,
+  });
+  const frames = [previousFrame, ...createTransitionFrames(previousFrame, frame, { type: "fade", steps: 4 })];
+  await exportGif(frames, args.gif, { scale, frameDelayMs: 120 });
+  console.log(`Saved ${args.gif}`);
+}
@@ -0,0 +1,31 @@
+/**
+ * Device reachability for `pnpm health` and the server's /healthz
+ *
+ * A device is given the same way as the server's --device: a Pixoo's IP,
+ * or "awtrix:<ip>" for an AWTRIX clock.
+ */
+
+import { createPixooClient, createPixooGetTimeCommand } from "@signage/core";
+
+const PROBE_TIMEOUT_MS = 3000;
+
+/**
+ * Ask the device something cheap. Returns a short description with the
+ * response time; throws when the device doesn't answer.
+ */
+export async function probeDevice(device: string): Promise<string> {
+  const startedAt = Date.now();
+  if (device.startsWith("awtrix:")) {
+    const host = device.slice("awtrix:".length);
+    const response = await fetch(`http://${host}/api/stats`, { signal: AbortSignal.timeout(PROBE_TIMEOUT_MS) });
+    if (!response.ok) {
+      throw new Error(`AWTRIX at ${host} answered HTTP ${response.status}`);
+    }
+    return `AWTRIX at ${host} answered in ${Date.now() - startedAt}ms`;
+  }
+
+  // One try, no retries: a slow answer is what we want to know about
+  const client = createPixooClient(device, { retries: 0, timeoutMs: PROBE_TIMEOUT_MS, keepAlive: false });
+  await client.sendCommand(createPixooGetTimeCommand());
+  return `Pixoo at ${device} answered in ${Date.now() - startedAt}ms`;
+}
@@ -0,0 +1,66 @@
+/**
+ * Show the event log: alerts fired and cleared, devices online and offline,
+ * settings changes and buzzer sounds
+ *
+ * Usage:
+ *   pnpm events --url https://api.signage.example.com
+ *   SIGNAGE_API_URL=https://api.signage.example.com pnpm events --since 7d --category buzzer
+ *   pnpm events --since 2026-10-16T02:00:00Z --category alert
+ *
+ * Options:
+ *   --url <url>          API base URL (default: $SIGNAGE_API_URL)
+ *   --since <when>       Duration back ("12h", "7d") or ISO date (default: 24h)
+ *   --category <name>    alert, device, config or buzzer (default: all)
+ *   --limit <n>          Most events to show, newest first (default: 200)
+ */
+
+import { parseArgs } from "node:util";
+
+const { values } = parseArgs({
+  options: {
+    url: { type: "string" },
+    since: { type: "string", default: "24h" },
+    category: { type: "string" },
+    limit: { type: "string" },
+  },
+});
+
+const baseUrl = values.url ?? process.env.SIGNAGE_API_URL;
+if (!baseUrl) {
+  console.error("Set --url or SIGNAGE_API_URL to the API base URL");
+  process.exit(1);
+}
+
+const params = new URLSearchParams({ since: values.since });
+if (values.category) params.set("category", values.category);
+if (values.limit) params.set("limit", values.limit);
+const url = `${baseUrl.replace(/\/+$/, "")}/events?${params}`;
+
+interface LoggedEvent {
+  timestamp: number;
+  category: string;
+  type: string;
+  subject?: string;
+  detail?: string;
+}
+
+try {
+  const response = await fetch(url);
+  const body = (await response.json()) as { events?: LoggedEvent[]; error?: string };
+  if (!response.ok) {
+    console.error(`Events failed: ${response.status} ${body.error ?? ""}`);
+    process.exit(1);
+  }
+  const events = body.events ?? [];
+  if (events.length === 0) {
+    console.log("No events");
+  }
+  for (const event of events) {
+    const time = new Date(event.timestamp).toLocaleString();
+    const what = `${event.category} ${event.type}`.padEnd(16);
+    console.log(`${time}  ${what} ${event.subject ?? ""}${event.detail ? `  ${event.detail}` : ""}`);
+  }
+} catch (error) {
+  console.error(`Could not reach ${url}: ${error instanceof Error ? error.message : String(error)}`);
+  process.exit(1);
+}
@@ -0,0 +1,68 @@
+/**
+ * Export stored readings as CSV or JSON, e.g. for an endocrinologist
+ *
+ * Usage:
+ *   pnpm export --widget blood-sugar --since 30d --url https://api.signage.example.com --token <token> > glucose.csv
+ *   SIGNAGE_API_URL=https://api.signage.example.com SIGNAGE_API_TOKEN=<token> pnpm export --since 2026-09-01 --out glucose.csv
+ *   pnpm export --widget network --since 3h --format json
+ *
+ * Options:
+ *   --url <url>          API base URL (default: $SIGNAGE_API_URL)
+ *   --token <token>      API token (default: $SIGNAGE_API_TOKEN)
+ *   --widget <id>        blood-sugar (default; the long-term CGM readings), or
+ *                        any widget's stored history (network, ticker-AAPL)
+ *   --since <when>       Duration back ("30d", "12h") or ISO date, up to 90
+ *                        days (default: 30d)
+ *   --format <fmt>       csv (default) or json
+ *   --profile <id>       Another household's readings
+ *   --out <file>         Write to a file instead of stdout
+ */
+
+import { createWriteStream } from "node:fs";
+import { Readable, Writable } from "node:stream";
+import { pipeline } from "node:stream/promises";
+import type { ReadableStream } from "node:stream/web";
+import { parseArgs } from "node:util";
+
+const { values } = parseArgs({
+  options: {
+    url: { type: "string" },
+    token: { type: "string" },
+    widget: { type: "string", default: "blood-sugar" },
+    since: { type: "string", default: "30d" },
+    format: { type: "string", default: "csv" },
+    profile: { type: "string" },
+    out: { type: "string" },
+  },
+});
+
+const baseUrl = values.url ?? process.env.SIGNAGE_API_URL;
+if (!baseUrl) {
+  console.error("Set --url or SIGNAGE_API_URL to the API base URL");
+  process.exit(1);
+}
+
+const token = values.token ?? process.env.SIGNAGE_API_TOKEN;
+if (!token) {
+  console.error("Set --token or SIGNAGE_API_TOKEN to the API token");
+  process.exit(1);
+}
+
+const query = new URLSearchParams({ widget: values.widget, since: values.since, format: values.format });
+if (values.profile) query.set("profile", values.profile);
+const url = `${baseUrl.replace(/\/+$/, "")}/export?${query}`;
+
+try {
+  const response = await fetch(url, { headers: { Authorization: `Bearer ${token}` } });
+  if (!response.ok || !response.body) {
+    const body = (await response.json().catch(() => ({}))) as Record<string, unknown>;
+    console.error(`Export failed: ${response.status} ${body.error ?? ""}`);
+    process.exit(1);
+  }
+  const target: Writable = values.out ? createWriteStream(values.out) : process.stdout;
+  await pipeline(Readable.fromWeb(response.body as ReadableStream), target);
+  if (values.out) console.error(`Wrote ${values.out}`);
+} catch (error) {
+  console.error(`Could not export from ${url}: ${error instanceof Error ? error.message : String(error)}`);
+  process.exit(1);
+}
@@ -0,0 +1,72 @@
+/**
+ * Check that a display and the stack feeding it are working
+ *
+ * Probes the device on the local network, and with an API URL adds the
+ * deployed stack's own checks (storage, glucose freshness, Dexcom login,
+ * compositor sends). Exits 1 when the result is unhealthy, so it can run
+ * from cron or a monitoring agent.
+ *
+ * Usage:
+ *   pnpm health 192.168.1.50
+ *   pnpm health awtrix:192.168.1.51 --url https://api.signage.example.com --json
+ *
+ * Options:
+ *   --url <url>   API base URL (default: $SIGNAGE_API_URL); device only without one
+ *   --json        Print the report as JSON
+ */
+
+import { parseArgs } from "node:util";
+import { checkProbe, summarizeHealth, type HealthCheck } from "@signage/functions/health";
+import { probeDevice } from "./device-health.js";
+
+const { values, positionals } = parseArgs({
+  allowPositionals: true,
+  options: {
+    url: { type: "string" },
+    json: { type: "boolean", default: false },
+  },
+});
+
+const device = positionals[0];
+if (!device) {
+  console.error("Usage: pnpm health <device-ip | awtrix:ip> [--url <url>] [--json]");
+  process.exit(1);
+}
+
+/**
+ * The deployed stack's checks, or one failed check when it can't be asked
+ */
+async function fetchStackChecks(baseUrl: string): Promise<HealthCheck[]> {
+  const url = `${baseUrl.replace(/\/+$/, "")}/health`;
+  try {
+    // 503 still carries the report
+    const response = await fetch(url);
+    const body = (await response.json()) as { checks?: HealthCheck[] };
+    if (!Array.isArray(body.checks)) {
+      throw new Error(`unexpected response (HTTP ${response.status})`);
+    }
+    return body.checks;
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    return [{ name: "api", status: "fail", detail: `could not reach ${url}: ${message}` }];
+  }
+}
+
+const baseUrl = values.url ?? process.env.SIGNAGE_API_URL;
+const checks = [
+  await checkProbe("device", () => probeDevice(device)),
+  ...(baseUrl ? await fetchStackChecks(baseUrl) : []),
+];
+const report = summarizeHealth(checks);
+
+if (values.json) {
+  console.log(JSON.stringify(report, null, 2));
+} else {
+  const width = Math.max(...checks.map((c) => c.name.length));
+  for (const check of checks) {
+    console.log(`${check.status.padEnd(4)}  ${check.name.padEnd(width)}  ${check.detail}`);
+  }
+  console.log(`\n${report.status}${baseUrl ? "" : " (device only; set --url for the stack's checks)"}`);
+}
+
+process.exit(report.status === "unhealthy" ? 1 : 0);
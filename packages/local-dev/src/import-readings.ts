@@ -0,0 +1,102 @@
+/**
+ * Import glucose readings from a Dexcom Clarity or Nightscout export, so
+ * long-range statistics have history from day one
+ *
+ * Usage:
+ *   pnpm import-readings clarity-export.csv --url https://api.signage.example.com --token <token>
+ *   SIGNAGE_API_URL=https://api.signage.example.com SIGNAGE_API_TOKEN=<token> pnpm import-readings entries.json
+ *   pnpm import-readings clarity-export.csv --timezone Europe/Berlin --dry-run
+ *
+ * Options:
+ *   --url <url>          API base URL (default: $SIGNAGE_API_URL)
+ *   --token <token>      API token (default: $SIGNAGE_API_TOKEN)
+ *   --format <fmt>       clarity or nightscout (default: from the file)
+ *   --timezone <tz>      Zone Clarity times are in (default: America/Los_Angeles)
+ *   --profile <id>       Import into another household's readings
+ *   --dry-run            Parse and count, without sending anything
+ *
+ * Running it again is safe: readings already stored are skipped.
+ */
+
+import { readFile } from "node:fs/promises";
+import { parseArgs } from "node:util";
+import {
+  detectImportFormat,
+  isImportFormat,
+  MAX_IMPORT_READINGS,
+  parseImport,
+} from "@signage/functions/import";
+
+const { values, positionals } = parseArgs({
+  allowPositionals: true,
+  options: {
+    url: { type: "string" },
+    token: { type: "string" },
+    format: { type: "string" },
+    timezone: { type: "string" },
+    profile: { type: "string" },
+    "dry-run": { type: "boolean", default: false },
+  },
+});
+
+const file = positionals[0];
+if (!file) {
+  console.error("Give the export file, e.g. pnpm import-readings clarity-export.csv");
+  process.exit(1);
+}
+if (values.format !== undefined && !isImportFormat(values.format)) {
+  console.error("--format must be clarity or nightscout");
+  process.exit(1);
+}
+
+const text = await readFile(file, "utf8");
+const format = isImportFormat(values.format) ? values.format : detectImportFormat(text);
+const { readings, skipped } = parseImport(text, format, values.timezone);
+readings.sort((a, b) => a.timestamp - b.timestamp);
+
+if (readings.length === 0) {
+  console.error(`No readings found in ${file} (read as ${format})`);
+  process.exit(1);
+}
+const range = `${new Date(readings[0].timestamp).toISOString()} to ${new Date(readings[readings.length - 1].timestamp).toISOString()}`;
+console.log(`${file}: ${readings.length} ${format} readings, ${range}${skipped ? ` (${skipped} unusable rows skipped)` : ""}`);
+if (values["dry-run"]) process.exit(0);
+
+const baseUrl = values.url ?? process.env.SIGNAGE_API_URL;
+if (!baseUrl) {
+  console.error("Set --url or SIGNAGE_API_URL to the API base URL");
+  process.exit(1);
+}
+const token = values.token ?? process.env.SIGNAGE_API_TOKEN;
+if (!token) {
+  console.error("Set --token or SIGNAGE_API_TOKEN to the API token");
+  process.exit(1);
+}
+const query = values.profile ? `?${new URLSearchParams({ profile: values.profile })}` : "";
+const url = `${baseUrl.replace(/\/+$/, "")}/import${query}`;
+
+let written = 0;
+let duplicates = 0;
+try {
+  for (let i = 0; i < readings.length; i += MAX_IMPORT_READINGS) {
+    const batch = readings.slice(i, i + MAX_IMPORT_READINGS);
+    const response = await fetch(url, {
+      method: "POST",
+      headers: { "Content-Type": "application/json", Authorization: `Bearer ${token}` },
+      body: JSON.stringify({ source: format, readings: batch }),
+    });
+    const body = (await response.json()) as Record<string, unknown>;
+    if (!response.ok) {
+      console.error(`Import failed after ${written} readings: ${response.status} ${body.error ?? ""}`);
+      process.exit(1);
+    }
+    written += Number(body.written ?? 0);
+    duplicates += Number(body.duplicates ?? 0);
+    console.log(`  ${Math.min(i + batch.length, readings.length)}/${readings.length} sent`);
+  }
+} catch (error) {
+  console.error(`Could not reach ${url}: ${error instanceof Error ? error.message : String(error)}`);
+  process.exit(1);
+}
+
+console.log(`Imported ${written} readings (${duplicates} were already stored)`);
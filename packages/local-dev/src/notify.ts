@@ -0,0 +1,87 @@
+/**
+ * Show a notification on the display, or list or clear the queue
+ *
+ * Usage:
+ *   pnpm notify "Laundry done" --url https://api.signage.example.com
+ *   SIGNAGE_API_URL=https://api.signage.example.com pnpm notify "Door open" --priority high --seconds 30
+ *   pnpm notify --list
+ *   pnpm notify --clear
+ *   pnpm notify "Hello" --url http://localhost:8080    # the local server
+ *
+ * Options:
+ *   --url <url>          API base URL (default: $SIGNAGE_API_URL)
+ *   --priority <level>   low, normal (default) or high; high cuts in on
+ *                        the notification showing
+ *   --seconds <s>        How long it shows (default: 10)
+ *   --list               Show the queue instead
+ *   --clear              Drop every queued notification instead
+ */
+
+import { parseArgs } from "node:util";
+
+const { values, positionals } = parseArgs({
+  allowPositionals: true,
+  options: {
+    url: { type: "string" },
+    priority: { type: "string" },
+    seconds: { type: "string" },
+    list: { type: "boolean", default: false },
+    clear: { type: "boolean", default: false },
+  },
+});
+
+const text = positionals.join(" ");
+if (!text && !values.list && !values.clear) {
+  console.error('Give the text to show, e.g. pnpm notify "Laundry done" (or --list, --clear)');
+  process.exit(1);
+}
+
+const baseUrl = values.url ?? process.env.SIGNAGE_API_URL;
+if (!baseUrl) {
+  console.error("Set --url or SIGNAGE_API_URL to the API base URL");
+  process.exit(1);
+}
+
+const url = `${baseUrl.replace(/\/+$/, "")}/notify`;
+
+function request(): RequestInit {
+  if (values.list) return { method: "GET" };
+  if (values.clear) return { method: "DELETE" };
+  return {
+    method: "POST",
+    body: JSON.stringify({
+      text,
+      priority: values.priority,
+      seconds: values.seconds === undefined ? undefined : Number(values.seconds),
+    }),
+  };
+}
+
+/**
+ * One line per queued notification, the showing one marked
+ */
+function describeQueue(notifications: Array<Record<string, unknown>>): string {
+  if (notifications.length === 0) return "No notifications queued";
+  return notifications
+    .map((n) => `${n.shownAt ? "*" : " "} [${n.priority}] ${n.text} (${n.seconds}s)`)
+    .join("\n");
+}
+
+try {
+  const response = await fetch(url, { ...request(), headers: { "Content-Type": "application/json" } });
+  const body = (await response.json()) as Record<string, unknown>;
+  if (!response.ok) {
+    console.error(`Notify failed: ${response.status} ${body.error ?? ""}`);
+    process.exit(1);
+  }
+  if (values.list) {
+    console.log(describeQueue(body.notifications as Array<Record<string, unknown>>));
+  } else if (values.clear) {
+    console.log(`Cleared ${body.deleted} notification(s)`);
+  } else {
+    console.log(`Queued (${body.queued} in the queue)`);
+  }
+} catch (error) {
+  console.error(`Could not reach ${url}: ${error instanceof Error ? error.message : String(error)}`);
+  process.exit(1);
+}
@@ -0,0 +1,99 @@
+/**
+ * Start, pause, resume or stop the display's pomodoro timer
+ *
+ * Usage:
+ *   pnpm pomodoro start --url https://api.signage.example.com
+ *   SIGNAGE_API_URL=https://api.signage.example.com pnpm pomodoro start --focus 50 --break 10 --full
+ *   pnpm pomodoro pause | resume | stop | status
+ *   pnpm pomodoro status --url http://localhost:8080    # the local server
+ *
+ * Options:
+ *   --url <url>       API base URL (default: $SIGNAGE_API_URL)
+ *   --focus <min>     Focus minutes (default: 25)
+ *   --break <min>     Break minutes (default: 5)
+ *   --cycles <n>      Focus/break cycles before it stops (default: 4)
+ *   --full            Take over the whole display, not just the insight rows
+ *   --buzzer          Sound the device buzzer as each period ends
+ */
+
+import { parseArgs } from "node:util";
+
+const COMMANDS = ["start", "pause", "resume", "stop", "status"];
+
+const { values, positionals } = parseArgs({
+  allowPositionals: true,
+  options: {
+    url: { type: "string" },
+    focus: { type: "string" },
+    break: { type: "string" },
+    cycles: { type: "string" },
+    full: { type: "boolean", default: false },
+    buzzer: { type: "boolean", default: false },
+  },
+});
+
+const command = positionals[0] ?? "status";
+if (!COMMANDS.includes(command)) {
+  console.error(`Unknown command "${command}" (use ${COMMANDS.join(", ")})`);
+  process.exit(1);
+}
+
+const baseUrl = values.url ?? process.env.SIGNAGE_API_URL;
+if (!baseUrl) {
+  console.error("Set --url or SIGNAGE_API_URL to the API base URL");
+  process.exit(1);
+}
+
+const url = `${baseUrl.replace(/\/+$/, "")}/pomodoro`;
+
+function optionalNumber(value: string | undefined): number | undefined {
+  return value === undefined ? undefined : Number(value);
+}
+
+function requestFor(name: string): RequestInit {
+  switch (name) {
+    case "start":
+      return {
+        method: "POST",
+        body: JSON.stringify({
+          action: "start",
+          focusMinutes: optionalNumber(values.focus),
+          breakMinutes: optionalNumber(values.break),
+          cycles: optionalNumber(values.cycles),
+          display: values.full ? "full" : "region",
+          buzzer: values.buzzer,
+        }),
+      };
+    case "pause":
+    case "resume":
+      return { method: "POST", body: JSON.stringify({ action: name }) };
+    case "stop":
+      return { method: "DELETE" };
+    default:
+      return { method: "GET" };
+  }
+}
+
+/**
+ * One line for a GET /pomodoro response
+ */
+function describe(status: Record<string, unknown>): string {
+  if (!status.running) return "No pomodoro running";
+  const seconds = Number(status.remainingSeconds);
+  const left = `${Math.floor(seconds / 60)}:${String(seconds % 60).padStart(2, "0")}`;
+  const paused = status.paused ? " (paused)" : "";
+  return `${String(status.phase).toUpperCase()} ${status.cycle}/${status.cycles}, ${left} left${paused}`;
+}
+
+try {
+  const response = await fetch(url, { ...requestFor(command), headers: { "Content-Type": "application/json" } });
+  const body = (await response.json()) as Record<string, unknown>;
+  if (!response.ok) {
+    console.error(`Pomodoro ${command} failed: ${response.status} ${body.error ?? ""}`);
+    process.exit(1);
+  }
+  console.log(describe(body));
+} catch (error) {
+  console.error(`Could not reach ${url}: ${error instanceof Error ? error.message : String(error)}`);
+  process.exit(1);
+}
@@ -11,24 +11,144 @@
  * Usage:
  *   pnpm dev:local          # From repo root - starts server and web emulator
  *   pnpm dev:server         # Server only
+ *
+ *   SIGNAGE_COMPOSE_HOOK=./my-hook.sh pnpm dev:server
+ *                           # Run a compose hook script around each frame
+ *   SIGNAGE_MQTT_URL=mqtt://192.168.1.10/signage pnpm dev:server
+ *                           # Publish readings and frames to an MQTT broker
+ *   SIGNAGE_SPOTIFY_CLIENT_ID=... SIGNAGE_SPOTIFY_CLIENT_SECRET=... \
+ *   SIGNAGE_SPOTIFY_REFRESH_TOKEN=... pnpm dev:server
+ *                           # Show what Spotify is playing (see `pnpm spotify-auth`)
+ *
+ * Options:
+ *   --device <ip>           Also send frames to a Pixoo on the local network
+ *   --device sim            ...or to a simulated Pixoo in this terminal
+ *   --device awtrix:<ip>    ...or to an AWTRIX 3 clock (Ulanzi TC001), in
+ *                           the compact 32x8 layout
+ *   --sim-png <path>        Write the simulator's frames to a PNG instead
+ *   --model <model>         Pixoo panel model: pixoo64 (default), pixooMax, pixoo16
+ *   --render-every <s>      Seconds between frame renders (default: 1)
+ *   --clock-seconds <s>     Show seconds on the clock, stepping 1-5 at a time
+ *                           (renders at least that often)
+ *   --tz <zone>             IANA timezone for the clock and chart markers
+ *                           (default: America/Los_Angeles)
+ *   --moon                  Show the moon's phase by the clock at night
+ *   --smooth-chart          Anti-alias the glucose chart's line
+ *   --chart-fill            Shade the area under the glucose chart's line
+ *   --layout <name>         Layout to render (default: day)
+ *   --ping <host>           Time round trips to a host for the network
+ *                           layout, from this machine's network
+ *   --ping-download <url>   ...and download a small file every 15 minutes
+ *
+ * The system layout (--layout system) shows this machine's CPU, memory and
+ * temperature, read every few seconds while it's showing.
+ *
+ * Fetching, rendering and pushing run on their own schedules. Dexcom is
+ * fetched when the next reading should be available (every 5 minutes,
+ * sooner while one is late, backing off on errors), frames are rendered
+ * on the render interval and right after new data, and clients and the
+ * device only get a frame when it changed. Startup doesn't wait for the
+ * first fetch: frames show "NO DATA" until it lands.
+ *
+ * GET http://localhost:8080/healthz reports the device, Dexcom login and
+ * glucose freshness in the deployed /health format (503 when unhealthy).
+ * http://localhost:8080/pomodoro runs a pomodoro timer like the deployed
+ * /pomodoro, kept in memory (see `pnpm pomodoro`). http://localhost:8080/notify
+ * queues notifications like the deployed /notify (see `pnpm notify`), shown
+ * for their exact time since frames render every second.
+ * http://localhost:8080/banner sets the text banner like the deployed
+ * /banner (see `pnpm banner`), also in memory.
  */
 
+import { createServer, type IncomingMessage, type ServerResponse } from "node:http";
+import { parseArgs } from "node:util";
 import { WebSocketServer, WebSocket } from "ws";
-import { encodeFrameToBase64 } from "@signage/core";
+import {
+  encodeFrameToBase64,
+  createPixooClient,
+  createPixooDisplay,
+  createAwtrixDisplay,
+  createPixooBuzzerCommand,
+  isDisplayModel,
+  DISPLAY_MODELS,
+  PixooUnavailableError,
+  type Display,
+} from "@signage/core";
 // Import shared rendering code - same as production uses
 import {
   generateCompositeFrame,
+  renderCompactFrame,
   classifyRange,
+  isClockSeconds,
+  isTimezone,
+  getLayout,
+  isLayoutName,
+  DEFAULT_TIMEZONE,
+  MAX_CLOCK_SECONDS,
+  MIN_CLOCK_SECONDS,
   DISPLAY_WIDTH,
   DISPLAY_HEIGHT,
   type BloodSugarDisplayData,
   type ChartPoint,
+  type CompositorData,
 } from "@signage/functions/rendering";
+import { createScriptHook, runBeforeCompose, runAfterCompose, type ComposeHook } from "@signage/functions/hooks";
+import { publishMqtt, buildMqttMessages, mqttPrefixFromUrl } from "@signage/functions/mqtt";
+import { backoffDelayMs, nextDexcomFetchDelayMs } from "@signage/functions/dexcom";
+import {
+  checkDexcomAuth,
+  checkGlucoseFreshness,
+  healthStatusCode,
+  summarizeHealth,
+  type HealthCheck,
+  type HealthReport,
+} from "@signage/functions/health";
+import {
+  applyPomodoroRequest,
+  describePomodoro,
+  getPomodoroStatus,
+  isPomodoroBuzzDue,
+  isPomodoroFinished,
+  parsePomodoroRequest,
+  pomodoroIntervalsDone,
+  POMODORO_BUZZ,
+  type PomodoroTimer,
+} from "@signage/functions/pomodoro";
+import {
+  takeNetworkSample,
+  validateNetworkMonitorSettings,
+  NETWORK_HISTORY_HOURS,
+  type NetworkSample,
+} from "@signage/functions/network";
+import { readSystemMetrics, type CpuSnapshot, type SystemMetrics } from "@signage/functions/system";
+import { createSpotifyClient, type NowPlaying } from "@signage/functions/spotify";
+import {
+  advanceNotifications,
+  createNotification,
+  parseNotifyRequest,
+  MAX_QUEUED_NOTIFICATIONS,
+  type DisplayNotification,
+} from "@signage/functions/notifications";
+import {
+  applyBannerRequest,
+  bannerText,
+  parseBannerRequest,
+  templateFields,
+  type TextBanner,
+} from "@signage/functions/banner";
 import { runSetup, loadConfig, isInteractive, type LocalConfig } from "./setup.js";
+import { createPixooSimulator } from "./simulator.js";
 
 // Configuration
 const WS_PORT = 8080;
-const UPDATE_INTERVAL_MS = 1000; // 1 second for clock updates
+// Mock data has no upload schedule to follow
+const MOCK_FETCH_INTERVAL_MS = 60 * 1000;
+// Network samples are taken once a minute, like the compositor's runs
+const NETWORK_SAMPLE_INTERVAL_MS = 60 * 1000;
+// Host metrics are cheap to read, so the system page stays current
+const SYSTEM_METRICS_INTERVAL_MS = 5 * 1000;
+// Track changes show within a few seconds; progress runs on between polls
+const NOW_PLAYING_INTERVAL_MS = 10 * 1000;
 
 // Connected clients (in-memory instead of DynamoDB)
 const clients = new Set<WebSocket>();
@@ -38,12 +158,130 @@ let bloodSugarData: BloodSugarDisplayData | null = null;
 let bloodSugarHistory: ChartPoint[] = [];
 let useMockData = true;
 
+// Running pomodoro timer (in-memory instead of DynamoDB)
+let pomodoroTimer: PomodoroTimer | null = null;
+
+// Queued notifications (in-memory instead of DynamoDB)
+let notifications: DisplayNotification[] = [];
+
+// Text banner template and values (in-memory instead of DynamoDB)
+let textBanner: TextBanner | null = null;
+
+// Network samples for the network layout (in-memory instead of DynamoDB)
+let networkSamples: NetworkSample[] = [];
+
+// Latest host metrics for the system layout, and the CPU times they were read at
+let systemMetrics: SystemMetrics | null = null;
+let systemCpu: CpuSnapshot | null = null;
+
+// What Spotify is playing, when credentials are set
+const spotifyClientId = process.env.SIGNAGE_SPOTIFY_CLIENT_ID;
+const spotifyClientSecret = process.env.SIGNAGE_SPOTIFY_CLIENT_SECRET;
+const spotifyRefreshToken = process.env.SIGNAGE_SPOTIFY_REFRESH_TOKEN;
+const spotify =
+  spotifyClientId && spotifyClientSecret && spotifyRefreshToken
+    ? createSpotifyClient({
+        clientId: spotifyClientId,
+        clientSecret: spotifyClientSecret,
+        refreshToken: spotifyRefreshToken,
+      })
+    : null;
+let nowPlaying: NowPlaying | null = null;
+
+// Consecutive failed Dexcom fetches, for /healthz
+let dexcomFailures = 0;
+let dexcomLastError: string | undefined;
+
 // Frame cache - stores last broadcast frame for immediate send to new connections
 let cachedFrameData: string | null = null;
 
 // Credentials loaded from .env.local
 let config: LocalConfig = {};
 
+const { values: args } = parseArgs({
+  options: {
+    device: { type: "string" },
+    "sim-png": { type: "string" },
+    model: { type: "string", default: "pixoo64" },
+    "render-every": { type: "string", default: "1" },
+    "clock-seconds": { type: "string" },
+    tz: { type: "string", default: DEFAULT_TIMEZONE },
+    moon: { type: "boolean", default: false },
+    "smooth-chart": { type: "boolean", default: false },
+    "chart-fill": { type: "boolean", default: false },
+    layout: { type: "string" },
+    ping: { type: "string" },
+    "ping-download": { type: "string" },
+  },
+});
+
+if (args.layout !== undefined && !isLayoutName(args.layout)) {
+  console.error(`--layout must be a built-in layout name like "night"`);
+  process.exit(1);
+}
+const layout = getLayout(args.layout);
+
+if (args["ping-download"] !== undefined && args.ping === undefined) {
+  console.error("--ping-download needs --ping");
+  process.exit(1);
+}
+const networkMonitor = args.ping === undefined ? null : { host: args.ping, downloadUrl: args["ping-download"] };
+const networkError = networkMonitor && validateNetworkMonitorSettings(networkMonitor);
+if (networkError) {
+  console.error(`Invalid --ping or --ping-download: ${networkError}`);
+  process.exit(1);
+}
+
+if (!isTimezone(args.tz)) {
+  console.error(`--tz must be an IANA timezone like "Europe/Berlin"`);
+  process.exit(1);
+}
+
+const clockSeconds = args["clock-seconds"] === undefined ? undefined : Number(args["clock-seconds"]);
+if (clockSeconds !== undefined && !isClockSeconds(clockSeconds)) {
+  console.error(`--clock-seconds must be whole seconds from ${MIN_CLOCK_SECONDS} to ${MAX_CLOCK_SECONDS}`);
+  process.exit(1);
+}
+
+const renderEveryMs = Number(args["render-every"]) * 1000;
+if (!(renderEveryMs > 0)) {
+  console.error("--render-every must be a positive number of seconds");
+  process.exit(1);
+}
+// A seconds clock needs a frame for every step
+const renderIntervalMs = clockSeconds ? Math.min(renderEveryMs, clockSeconds * 1000) : renderEveryMs;
+
+if (!isDisplayModel(args.model)) {
+  console.error(`--model must be one of ${Object.keys(DISPLAY_MODELS).join(", ")}`);
+  process.exit(1);
+}
+
+// Optional device (real or simulated Pixoo, or an AWTRIX clock) that gets each new frame
+const awtrixHost = args.device?.startsWith("awtrix:") ? args.device.slice("awtrix:".length) : null;
+const pixoo =
+  args.device === "sim"
+    ? createPixooSimulator({ pngPath: args["sim-png"] })
+    : args.device && !awtrixHost
+      ? createPixooClient(args.device, {
+          onHealthChange: (health, error) => console.log(`Pixoo at ${args.device} is ${health}`, error ?? ""),
+        })
+      : null;
+const device: Display | null = awtrixHost
+  ? createAwtrixDisplay(awtrixHost)
+  : pixoo && createPixooDisplay(pixoo, args.model);
+let lastDeviceFrameData: string | null = null;
+// Outcome of the latest send, for /healthz
+let lastDeviceSend: { at: number; error?: string } | null = null;
+
+// Optional compose hook script (see @signage/functions/hooks)
+const composeHooks: ComposeHook[] = process.env.SIGNAGE_COMPOSE_HOOK
+  ? [createScriptHook(process.env.SIGNAGE_COMPOSE_HOOK)]
+  : [];
+
+// Optional MQTT broker (see @signage/functions/mqtt)
+const mqttUrl = process.env.SIGNAGE_MQTT_URL;
+let lastMqttFrameData: string | null = null;
+
 // Dexcom API (same as production)
 const DEXCOM_BASE_URL = "https://share2.dexcom.com/ShareWebServices/Services";
 const DEXCOM_APP_ID = "d89443d2-327c-4a6f-89e5-496bbb0317db";
@@ -179,6 +417,7 @@ async function fetchRealBloodSugar(): Promise<BloodSugarDisplayData | null> {
     const glucose = latest.Value;
     const delta = previous ? glucose - previous.Value : 0;
 
+    dexcomFailures = 0;
     return {
       glucose,
       trend: latest.Trend,
@@ -189,6 +428,8 @@ async function fetchRealBloodSugar(): Promise<BloodSugarDisplayData | null> {
     };
   } catch (error) {
     console.error("Failed to fetch Dexcom data:", error);
+    dexcomFailures += 1;
+    dexcomLastError = error instanceof Error ? error.message : String(error);
     return null;
   }
 }
@@ -286,43 +527,218 @@ async function fetchRealHistory(): Promise<ChartPoint[]> {
 }
 
 /**
- * Update blood sugar data (mock or real)
+ * Update blood sugar data (mock or real). Returns whether there is a new
+ * reading.
  */
-async function updateBloodSugar(): Promise<void> {
+async function updateBloodSugar(): Promise<boolean> {
   if (useMockData) {
     bloodSugarData = generateMockBloodSugar();
     bloodSugarHistory = generateMockHistory();
-  } else {
-    const realData = await fetchRealBloodSugar();
-    if (realData) {
-      bloodSugarData = realData;
-    }
-    // Fetch history less frequently (it's more expensive)
-    const realHistory = await fetchRealHistory();
-    if (realHistory.length > 0) {
-      bloodSugarHistory = realHistory;
+    return true;
+  }
+
+  const realData = await fetchRealBloodSugar();
+  if (!realData) {
+    return false;
+  }
+  // Kept even without a new reading, so staleness stays current
+  const isNew = realData.timestamp !== bloodSugarData?.timestamp;
+  bloodSugarData = realData;
+  if (!isNew) {
+    return false;
+  }
+  // History is the expensive call, and only changes with a new reading
+  const realHistory = await fetchRealHistory();
+  if (realHistory.length > 0) {
+    bloodSugarHistory = realHistory;
+  }
+  return true;
+}
+
+/**
+ * Fetch, then schedule the next fetch for when new data should be there
+ */
+async function fetchLoop(): Promise<void> {
+  if (await updateBloodSugar()) {
+    void renderFrame();
+  }
+  const delay = useMockData
+    ? MOCK_FETCH_INTERVAL_MS
+    : dexcomFailures > 0
+      ? backoffDelayMs(dexcomFailures)
+      : nextDexcomFetchDelayMs(bloodSugarData?.timestamp ?? null, Date.now());
+  setTimeout(() => void fetchLoop(), delay);
+}
+
+/**
+ * Take a network sample, keep the past few hours of them, and schedule the
+ * next one
+ */
+async function networkLoop(): Promise<void> {
+  if (!networkMonitor) return;
+  const now = Date.now();
+  const sample = await takeNetworkSample(networkMonitor, networkSamples, now);
+  networkSamples = [...networkSamples, sample].filter(
+    (s) => s.timestamp > now - NETWORK_HISTORY_HOURS * 60 * 60 * 1000
+  );
+  void renderFrame();
+  setTimeout(() => void networkLoop(), NETWORK_SAMPLE_INTERVAL_MS);
+}
+
+/**
+ * Read this machine's metrics on an interval, while the layout shows them
+ */
+async function systemMetricsLoop(): Promise<void> {
+  if (!layout.widgets.includes("system")) return;
+  const { metrics, cpu } = await readSystemMetrics(systemCpu);
+  // The first reading has no CPU interval to measure, so wait for the second
+  if (systemCpu) {
+    systemMetrics = metrics;
+    void renderFrame();
+  }
+  systemCpu = cpu;
+  setTimeout(() => void systemMetricsLoop(), SYSTEM_METRICS_INTERVAL_MS);
+}
+
+/**
+ * Poll Spotify for the playing track on an interval, keeping the last one
+ * through failed polls
+ */
+async function nowPlayingLoop(): Promise<void> {
+  if (!spotify) return;
+  try {
+    nowPlaying = await spotify.getNowPlaying();
+  } catch (error) {
+    console.error("Failed to fetch now playing:", error instanceof Error ? error.message : error);
+  }
+  setTimeout(() => void nowPlayingLoop(), NOW_PLAYING_INTERVAL_MS);
+}
+
+// A render still running (slow compose hook) makes the next tick skip
+let rendering = false;
+
+/**
+ * Render a frame unless one is already being rendered
+ */
+async function renderFrame(): Promise<void> {
+  if (rendering) return;
+  rendering = true;
+  try {
+    await broadcastFrame();
+  } catch (error) {
+    console.error("Failed to render frame:", error instanceof Error ? error.message : error);
+  } finally {
+    rendering = false;
+  }
+}
+
+/**
+ * Sound the device buzzer, and tell clients to, like the compositor's
+ * buzzer message
+ */
+function buzz(): void {
+  pixoo?.sendCommand(createPixooBuzzerCommand(POMODORO_BUZZ)).catch((error: unknown) => {
+    console.error("Failed to sound the buzzer:", error instanceof Error ? error.message : error);
+  });
+  const message = JSON.stringify({ type: "buzzer", payload: POMODORO_BUZZ, timestamp: Date.now() });
+  for (const client of clients) {
+    if (client.readyState === WebSocket.OPEN) {
+      client.send(message);
     }
   }
 }
 
+/**
+ * Buzz once for pomodoro periods that ended since the last frame, and drop
+ * a finished timer
+ */
+function advancePomodoro(now: number): void {
+  if (!pomodoroTimer) return;
+  if (isPomodoroBuzzDue(pomodoroTimer, now)) {
+    pomodoroTimer = { ...pomodoroTimer, buzzedIntervals: pomodoroIntervalsDone(pomodoroTimer, now) };
+    buzz();
+  }
+  if (isPomodoroFinished(pomodoroTimer, now)) {
+    pomodoroTimer = null;
+    console.log("Pomodoro finished");
+  }
+}
+
 /**
  * Broadcast frame to all connected clients
  * (Local WebSocket instead of API Gateway)
  */
-function broadcastFrame(): void {
-  // Use the SAME frame generation as production
-  const frame = generateCompositeFrame({
+async function broadcastFrame(): Promise<void> {
+  const now = Date.now();
+  advancePomodoro(now);
+  const { current: notification, queue } = advanceNotifications(notifications, now);
+  notifications = queue;
+
+  // Use the SAME frame generation (and hooks) as production
+  const data: CompositorData = await runBeforeCompose(composeHooks, {
     bloodSugar: bloodSugarData,
     bloodSugarHistory: { points: bloodSugarHistory },
-    timezone: "America/Los_Angeles",
+    // The browser gets frames the device is missing while its sends fail
+    glucoseStatus: {
+      dexcomUnreachable: !useMockData && dexcomFailures > 0,
+      deviceReconnecting: lastDeviceSend?.error !== undefined,
+    },
+    timezone: args.tz,
+    layout,
+    network: networkMonitor ? { host: networkMonitor.host, samples: networkSamples } : undefined,
+    system: systemMetrics,
+    clockSeconds,
+    moonPhase: args.moon,
+    chartAntialias: args["smooth-chart"],
+    chartFill: args["chart-fill"],
+    pomodoro: pomodoroTimer && getPomodoroStatus(pomodoroTimer, now),
+    nowPlaying,
+    notification,
+    textBanner: bannerText(textBanner, { bloodSugar: bloodSugarData }, now),
   });
+  const { frame, vetoedBy } = await runAfterCompose(composeHooks, generateCompositeFrame(data), data);
+  if (vetoedBy) return;
 
   const frameData = encodeFrameToBase64(frame);
 
+  // Clients already have an unchanged frame (new ones get it on connect)
+  const changed = frameData !== cachedFrameData;
+
   // Cache frame for new connections (even if no clients connected)
   cachedFrameData = frameData;
 
-  if (clients.size === 0) return;
+  // The device only needs a frame when it changed. AWTRIX clocks get the
+  // compact layout rather than the full frame squeezed to 32x8.
+  const deviceFrame = awtrixHost ? renderCompactFrame(data) : frame;
+  const deviceFrameData = awtrixHost ? encodeFrameToBase64(deviceFrame) : frameData;
+  if (device && deviceFrameData !== lastDeviceFrameData) {
+    lastDeviceFrameData = deviceFrameData;
+    device.sendFrame(deviceFrame).then(
+      () => {
+        lastDeviceSend = { at: Date.now() };
+      },
+      (error: unknown) => {
+        lastDeviceFrameData = null;
+        lastDeviceSend = { at: Date.now(), error: error instanceof Error ? error.message : String(error) };
+        if (!(error instanceof PixooUnavailableError)) {
+          console.error("Failed to send frame to device:", error instanceof Error ? error.message : error);
+        }
+      }
+    );
+  }
+
+  // Subscribers get the same state as production, once per change
+  if (mqttUrl && frameData !== lastMqttFrameData) {
+    lastMqttFrameData = frameData;
+    const prefix = mqttPrefixFromUrl(mqttUrl);
+    const messages = buildMqttMessages(prefix, { bloodSugar: data.bloodSugar, alerts: [], frame });
+    publishMqtt(mqttUrl, messages).catch((error: unknown) => {
+      lastMqttFrameData = null;
+      console.error("Failed to publish to MQTT:", error instanceof Error ? error.message : error);
+    });
+  }
+
+  if (!changed || clients.size === 0) return;
 
   const message = JSON.stringify({
     type: "frame",
@@ -343,6 +759,184 @@ function broadcastFrame(): void {
   }
 }
 
+/**
+ * Health report for /healthz. Nothing is stored locally, so there is no
+ * storage check, and the device is judged by its last send rather than
+ * probed (a probe could collide with a frame on a Pixoo).
+ */
+function healthReport(now: number): HealthReport {
+  const checks: HealthCheck[] = [];
+  if (device && !lastDeviceSend) {
+    checks.push({ name: "device", status: "warn", detail: "no frame sent yet" });
+  } else if (device && lastDeviceSend) {
+    const ago = Math.round((now - lastDeviceSend.at) / 1000);
+    checks.push(
+      lastDeviceSend.error
+        ? { name: "device", status: "fail", detail: `last send failed: ${lastDeviceSend.error}` }
+        : { name: "device", status: "pass", detail: `last frame sent ${ago}s ago` }
+    );
+  }
+  const backoff = dexcomFailures > 0 ? { failures: dexcomFailures, retryAt: 0, lastError: dexcomLastError } : null;
+  checks.push(
+    useMockData ? { name: "dexcom", status: "pass", detail: "mock data" } : checkDexcomAuth(backoff),
+    checkGlucoseFreshness(bloodSugarData?.timestamp ?? null, now)
+  );
+  return summarizeHealth(checks, now);
+}
+
+/**
+ * /pomodoro: the deployed endpoint's requests and responses, with the
+ * timer in memory
+ */
+async function handlePomodoro(req: IncomingMessage, res: ServerResponse): Promise<void> {
+  const reply = (statusCode: number, body: unknown) => {
+    res.writeHead(statusCode, { "Content-Type": "application/json" });
+    res.end(JSON.stringify(body));
+  };
+
+  if (req.method === "GET") {
+    reply(200, describePomodoro(pomodoroTimer));
+    return;
+  }
+  if (req.method === "DELETE") {
+    pomodoroTimer = null;
+    void renderFrame();
+    reply(200, { running: false });
+    return;
+  }
+  if (req.method !== "POST") {
+    reply(405, { error: `Method ${req.method} not allowed` });
+    return;
+  }
+
+  let body: Parameters<typeof parsePomodoroRequest>[0];
+  try {
+    const chunks: Buffer[] = [];
+    for await (const chunk of req) chunks.push(chunk as Buffer);
+    body = JSON.parse(Buffer.concat(chunks).toString() || "{}");
+  } catch {
+    reply(400, { error: "Invalid JSON" });
+    return;
+  }
+
+  const request = parsePomodoroRequest(body);
+  if (typeof request === "string") {
+    reply(400, { error: request });
+    return;
+  }
+  const timer = applyPomodoroRequest(pomodoroTimer, request);
+  if (!timer) {
+    reply(409, { error: "No pomodoro is running" });
+    return;
+  }
+  pomodoroTimer = timer;
+  void renderFrame();
+  reply(200, describePomodoro(timer));
+}
+
+/**
+ * /notify: the deployed endpoint's requests and responses, with the queue
+ * in memory
+ */
+async function handleNotify(req: IncomingMessage, res: ServerResponse): Promise<void> {
+  const reply = (statusCode: number, body: unknown) => {
+    res.writeHead(statusCode, { "Content-Type": "application/json" });
+    res.end(JSON.stringify(body));
+  };
+
+  if (req.method === "GET") {
+    reply(200, { notifications: advanceNotifications(notifications, Date.now()).queue });
+    return;
+  }
+  if (req.method === "DELETE") {
+    const id = new URL(req.url ?? "/", "http://localhost").searchParams.get("id");
+    const before = notifications.length;
+    notifications = id ? notifications.filter((n) => n.id !== id) : [];
+    void renderFrame();
+    reply(200, { deleted: before - notifications.length });
+    return;
+  }
+  if (req.method !== "POST") {
+    reply(405, { error: `Method ${req.method} not allowed` });
+    return;
+  }
+
+  let body: Parameters<typeof parseNotifyRequest>[0];
+  try {
+    const chunks: Buffer[] = [];
+    for await (const chunk of req) chunks.push(chunk as Buffer);
+    body = JSON.parse(Buffer.concat(chunks).toString() || "{}");
+  } catch {
+    reply(400, { error: "Invalid JSON" });
+    return;
+  }
+
+  const request = parseNotifyRequest(body);
+  if (typeof request === "string") {
+    reply(400, { error: request });
+    return;
+  }
+  if (notifications.length >= MAX_QUEUED_NOTIFICATIONS) {
+    reply(429, { error: `Queue is full (${MAX_QUEUED_NOTIFICATIONS} notifications)` });
+    return;
+  }
+  const notification = createNotification(request);
+  notifications = [...notifications, notification];
+  void renderFrame();
+  reply(200, { notification, queued: notifications.length });
+}
+
+/**
+ * /banner: the deployed endpoint's requests and responses, with the banner
+ * in memory
+ */
+async function handleBanner(req: IncomingMessage, res: ServerResponse): Promise<void> {
+  const reply = (statusCode: number, body: unknown) => {
+    res.writeHead(statusCode, { "Content-Type": "application/json" });
+    res.end(JSON.stringify(body));
+  };
+  const describe = () => ({
+    template: textBanner?.template ?? null,
+    fields: textBanner?.template ? templateFields(textBanner.template) : [],
+    values: textBanner?.values ?? {},
+    text: bannerText(textBanner, {}),
+  });
+
+  if (req.method === "GET") {
+    reply(200, describe());
+    return;
+  }
+  if (req.method === "DELETE") {
+    textBanner = null;
+    void renderFrame();
+    reply(200, describe());
+    return;
+  }
+  if (req.method !== "POST") {
+    reply(405, { error: `Method ${req.method} not allowed` });
+    return;
+  }
+
+  let body: Parameters<typeof parseBannerRequest>[0];
+  try {
+    const chunks: Buffer[] = [];
+    for await (const chunk of req) chunks.push(chunk as Buffer);
+    body = JSON.parse(Buffer.concat(chunks).toString() || "{}");
+  } catch {
+    reply(400, { error: "Invalid JSON" });
+    return;
+  }
+
+  const request = parseBannerRequest(body);
+  if (typeof request === "string") {
+    reply(400, { error: request });
+    return;
+  }
+  textBanner = applyBannerRequest(textBanner, request);
+  void renderFrame();
+  reply(200, describe());
+}
+
 /**
  * Start the local development server
  */
@@ -363,7 +957,31 @@ async function startServer(): Promise<void> {
     console.log("No Dexcom credentials - using mock blood sugar data");
   }
 
-  const wss = new WebSocketServer({ port: WS_PORT });
+  // Plain HTTP for /healthz, /pomodoro, /notify and /banner; WebSocket upgrades go to the same port
+  const server = createServer((req, res) => {
+    const path = req.url?.split("?")[0];
+    if (req.method === "GET" && path === "/healthz") {
+      const report = healthReport(Date.now());
+      res.writeHead(healthStatusCode(report), { "Content-Type": "application/json" });
+      res.end(JSON.stringify(report));
+      return;
+    }
+    if (path === "/pomodoro") {
+      void handlePomodoro(req, res);
+      return;
+    }
+    if (path === "/notify") {
+      void handleNotify(req, res);
+      return;
+    }
+    if (path === "/banner") {
+      void handleBanner(req, res);
+      return;
+    }
+    res.writeHead(404).end();
+  });
+  const wss = new WebSocketServer({ server });
+  server.listen(WS_PORT);
 
   wss.on("connection", (ws) => {
     console.log(`Client connected (total: ${clients.size + 1})`);
@@ -400,20 +1018,20 @@ async function startServer(): Promise<void> {
   console.log(`\n───────────────────────────────────────────`);
   console.log(`Local Development Server started!`);
   console.log(`WebSocket: ws://localhost:${WS_PORT}`);
+  console.log(`Health:    http://localhost:${WS_PORT}/healthz`);
   console.log(`───────────────────────────────────────────`);
   console.log(`\nOpen http://localhost:5173 in your browser`);
   console.log(`(Run 'pnpm dev:web' in another terminal if not already running)\n`);
   console.log(`To reconfigure credentials, delete .env.local and restart`);
 
-  // Initial blood sugar fetch and frame generation
-  await updateBloodSugar();
-  broadcastFrame(); // Generate initial cached frame
-
-  // Update frame every second (for clock)
-  setInterval(broadcastFrame, UPDATE_INTERVAL_MS);
-
-  // Update blood sugar every minute
-  setInterval(updateBloodSugar, 60 * 1000);
+  // Render right away (without data until the first fetch lands), then
+  // on the render interval; the fetch loop renders after new data too
+  void renderFrame();
+  setInterval(() => void renderFrame(), renderIntervalMs);
+  void fetchLoop();
+  void networkLoop();
+  void systemMetricsLoop();
+  void nowPlayingLoop();
 }
 
 startServer().catch(console.error);
@@ -0,0 +1,139 @@
+/**
+ * Simulated Pixoo
+ *
+ * Answers the device's HTTP commands in memory and shows each frame it
+ * receives in the terminal or as a PNG file. It plugs into the real client
+ * as its `fetch`, so channel setup, PicIDs, retries and the breaker behave
+ * exactly as they do against hardware.
+ */
+
+import { writeFileSync } from "fs";
+import {
+  createPixooClient,
+  decodeBase64ToPixels,
+  PIXOO_CUSTOM_CHANNEL,
+  type Frame,
+  type PixooClient,
+} from "@signage/core";
+import { encodePng } from "@signage/functions/rendering";
+
+/** What the simulated device currently holds */
+export interface SimulatedDeviceState {
+  channel: number;
+  screenOn: boolean;
+  brightness: number;
+  /** Last PicID accepted since the last reset */
+  picId: number;
+  frames: number;
+}
+
+type Command = { Command: string } & Record<string, unknown>;
+
+/** error_code the simulator answers a refused command with */
+const REFUSED = 1;
+
+/**
+ * Render a frame for a truecolor terminal: one character per two rows,
+ * upper pixel as the foreground of "▀", lower as the background
+ */
+export function frameToAnsi(frame: Frame, brightness = 100): string {
+  const scale = brightness / 100;
+  const color = (x: number, y: number) => {
+    const i = (y * frame.width + x) * 3;
+    return [0, 1, 2].map((c) => Math.round(frame.pixels[i + c] * scale)).join(";");
+  };
+  const lines: string[] = [];
+  for (let y = 0; y < frame.height; y += 2) {
+    let line = "";
+    for (let x = 0; x < frame.width; x++) {
+      const lower = y + 1 < frame.height ? color(x, y + 1) : "0;0;0";
+      line += `\x1b[38;2;${color(x, y)}m\x1b[48;2;${lower}m▀`;
+    }
+    lines.push(`${line}\x1b[0m`);
+  }
+  return lines.join("\n");
+}
+
+/**
+ * Create a simulated device. `onFrame` gets each frame the device would
+ * show; nothing is shown while the screen is off or on another channel.
+ */
+export function createSimulatedDevice(
+  onFrame: (frame: Frame, state: SimulatedDeviceState) => void,
+  now: () => number = Date.now
+): { fetch: typeof fetch; state: SimulatedDeviceState } {
+  const state: SimulatedDeviceState = {
+    channel: 0,
+    screenOn: true,
+    brightness: 100,
+    picId: 0,
+    frames: 0,
+  };
+
+  function handle(command: Command): Record<string, unknown> {
+    switch (command.Command) {
+      case "Draw/CommandList": {
+        for (const inner of (command.CommandList as Command[] | undefined) ?? []) {
+          const result = handle(inner);
+          if (result.error_code !== 0) return result;
+        }
+        return { error_code: 0 };
+      }
+      case "Channel/SetIndex":
+        state.channel = Number(command.SelectIndex);
+        return { error_code: 0 };
+      case "Draw/ResetHttpGifId":
+        state.picId = 0;
+        return { error_code: 0 };
+      case "Draw/SendHttpGif": {
+        // Like the device, only take a new, higher PicID
+        const picId = Number(command.PicID);
+        if (!(picId > state.picId)) return { error_code: REFUSED };
+        state.picId = picId;
+        const width = Number(command.PicWidth);
+        const frame = decodeBase64ToPixels(String(command.PicData), width, width);
+        state.frames += 1;
+        if (state.screenOn && state.channel === PIXOO_CUSTOM_CHANNEL) {
+          onFrame(frame, state);
+        }
+        return { error_code: 0 };
+      }
+      case "Channel/OnOffScreen":
+        state.screenOn = command.OnOff === 1;
+        return { error_code: 0 };
+      case "Channel/SetBrightness":
+        state.brightness = Number(command.Brightness);
+        return { error_code: 0 };
+      case "Device/GetDeviceTime":
+        return { error_code: 0, UTCTime: Math.floor(now() / 1000) };
+      default:
+        // Settings the simulator doesn't model are accepted and ignored
+        return { error_code: 0 };
+    }
+  }
+
+  const simulatedFetch = (async (_input: unknown, init?: { body?: unknown }) => {
+    const result = handle(JSON.parse(String(init?.body)) as Command);
+    return new Response(JSON.stringify(result), { status: 200, headers: { "Content-Type": "application/json" } });
+  }) as typeof fetch;
+
+  return { fetch: simulatedFetch, state };
+}
+
+/**
+ * Create a client for a simulated device that shows frames in the
+ * terminal, or writes them to `pngPath` when given
+ */
+export function createPixooSimulator(options: { pngPath?: string } = {}): PixooClient {
+  const { pngPath } = options;
+  if (!pngPath) process.stdout.write("\x1b[2J");
+  const device = createSimulatedDevice((frame, state) => {
+    if (pngPath) {
+      writeFileSync(pngPath, encodePng(frame, { scale: 4 }));
+    } else {
+      // Home the cursor and redraw in place
+      process.stdout.write(`\x1b[H${frameToAnsi(frame, state.brightness)}\n`);
+    }
+  });
+  return createPixooClient("simulator", { fetch: device.fetch, sleep: async () => {} });
+}
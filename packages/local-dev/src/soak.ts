@@ -0,0 +1,209 @@
+/**
+ * Soak test: run the render loop for weeks of simulated time
+ *
+ * Drives the production rendering code once per simulated minute with a
+ * fake glucose source, sends each frame (and its transition) to a
+ * simulated Pixoo through the real client, and samples memory, open
+ * handles and retained history as it goes. A leak that would take a week
+ * to hurt a warm Lambda or the always-on local server shows up in minutes.
+ *
+ * Usage:
+ *   pnpm soak
+ *   pnpm soak --days 14 --speed 1000x
+ *   pnpm soak --days 30 --speed max --max-growth-mb 8
+ *
+ * Options:
+ *   --days <n>            Simulated days to run (default: 14)
+ *   --speed <n>x | max    Simulated time per real time (default: 1000x)
+ *   --max-growth-mb <n>   Heap growth after the first day that fails the
+ *                         run (default: 16)
+ *
+ * Exits 1 when the heap grows more than the limit.
+ */
+
+import { parseArgs } from "node:util";
+import { setFlagsFromString } from "node:v8";
+import { runInNewContext } from "node:vm";
+import { setTimeout as sleep } from "node:timers/promises";
+import { createPixooClient, createTransitionFrames, type Frame } from "@signage/core";
+import {
+  generateCompositeFrame,
+  classifyRange,
+  DISPLAY_WIDTH,
+  DISPLAY_HEIGHT,
+  type ChartPoint,
+} from "@signage/functions/rendering";
+import { createSimulatedDevice } from "./simulator.js";
+
+const { values } = parseArgs({
+  options: {
+    days: { type: "string", default: "14" },
+    speed: { type: "string", default: "1000x" },
+    "max-growth-mb": { type: "string", default: "16" },
+  },
+});
+
+const days = Number(values.days);
+const speed = values.speed === "max" ? Infinity : Number(values.speed.replace(/x$/, ""));
+const maxGrowthMb = Number(values["max-growth-mb"]);
+if (!(days > 0) || !(speed > 0) || !(maxGrowthMb >= 0)) {
+  console.error("Usage: pnpm soak [--days <n>] [--speed <n>x|max] [--max-growth-mb <n>]");
+  process.exit(1);
+}
+
+const TICK_MS = 60 * 1000; // The compositor runs every minute
+const READING_INTERVAL_MS = 5 * 60 * 1000;
+const HISTORY_WINDOW_MS = 24 * 60 * 60 * 1000;
+const SAMPLE_INTERVAL_MS = 6 * 60 * 60 * 1000;
+const DAY_MS = 24 * 60 * 60 * 1000;
+const MB = 1024 * 1024;
+
+// Heap figures are only comparable after a full collection
+setFlagsFromString("--expose-gc");
+const gc = runInNewContext("gc") as () => void;
+
+// Simulated clock: the renderers read Date.now() and new Date()
+const RealDate = Date;
+const start = RealDate.UTC(2026, 0, 1, 8, 0, 0);
+let now = start;
+
+class SimulatedDate extends RealDate {
+  constructor(...args: unknown[]) {
+    if (args.length === 0) {
+      super(now);
+    } else {
+      super(...(args as [number]));
+    }
+  }
+
+  static now(): number {
+    return now;
+  }
+}
+globalThis.Date = SimulatedDate as unknown as DateConstructor;
+
+/**
+ * Fake CGM: a slow daily wave with meal bumps and a little noise
+ */
+function fakeGlucose(timestamp: number): number {
+  const hour = (timestamp % DAY_MS) / (60 * 60 * 1000);
+  const wave = Math.sin((hour / 24) * Math.PI * 2) * 30;
+  const meals = Math.max(0, Math.sin((hour / 6) * Math.PI * 2)) * 60;
+  const noise = Math.sin(timestamp / 7_919) * 6;
+  return Math.round(Math.max(40, Math.min(400, 115 + wave + meals + noise)));
+}
+
+/** Simulated device behind the real client, so channel setup and PicIDs are soaked too */
+const device = createSimulatedDevice((frame) => {
+  if (frame.pixels.length !== DISPLAY_WIDTH * DISPLAY_HEIGHT * 3) {
+    throw new Error(`Device got a frame of ${frame.pixels.length} bytes at ${new RealDate(now).toISOString()}`);
+  }
+});
+const client = createPixooClient("simulator", { fetch: device.fetch, retries: 0 });
+
+interface Sample {
+  day: number;
+  heapMb: number;
+  rssMb: number;
+  handles: number;
+  history: number;
+}
+
+function sample(history: ChartPoint[]): Sample {
+  gc();
+  const memory = process.memoryUsage();
+  return {
+    day: (now - start) / DAY_MS,
+    heapMb: memory.heapUsed / MB,
+    rssMb: memory.rss / MB,
+    handles: process.getActiveResourcesInfo().length,
+    history: history.length,
+  };
+}
+
+function formatSample(s: Sample): string {
+  return [
+    `day ${s.day.toFixed(2).padStart(6)}`,
+    `heap ${s.heapMb.toFixed(1).padStart(6)} MB`,
+    `rss ${s.rssMb.toFixed(1).padStart(6)} MB`,
+    `handles ${String(s.handles).padStart(3)}`,
+    `history ${String(s.history).padStart(4)}`,
+  ].join("  ");
+}
+
+async function soak(): Promise<void> {
+  const end = start + days * DAY_MS;
+  const history: ChartPoint[] = [];
+  const samples: Sample[] = [];
+  let previous: Frame | null = null;
+  const startedAt = RealDate.now();
+
+  console.log(`Soaking ${days} days at ${values.speed}...`);
+
+  for (; now < end; now += TICK_MS) {
+    if ((now - start) % READING_INTERVAL_MS === 0) {
+      history.push({ timestamp: now, glucose: fakeGlucose(now) });
+      while (history.length > 0 && history[0].timestamp < now - HISTORY_WINDOW_MS) {
+        history.shift();
+      }
+    }
+
+    const latest = history[history.length - 1];
+    const before = history[history.length - 2];
+    const frame = generateCompositeFrame({
+      bloodSugar: {
+        glucose: latest.glucose,
+        trend: "Flat",
+        delta: before ? latest.glucose - before.glucose : 0,
+        timestamp: latest.timestamp,
+        rangeStatus: classifyRange(latest.glucose),
+        isStale: false,
+      },
+      bloodSugarHistory: { points: history },
+      timezone: "America/Los_Angeles",
+    });
+
+    const transition = previous ? createTransitionFrames(previous, frame, { type: "fade", steps: 4 }) : [frame];
+    for (const step of transition) {
+      await client.sendFrame(step);
+    }
+    previous = frame;
+
+    if ((now - start) % SAMPLE_INTERVAL_MS === 0) {
+      const s = sample(history);
+      samples.push(s);
+      console.log(formatSample(s));
+    }
+
+    if (Number.isFinite(speed)) {
+      await sleep(TICK_MS / speed);
+    } else if ((now - start) % (60 * TICK_MS) === 0) {
+      // Let the event loop run at least once per simulated hour
+      await sleep(0);
+    }
+  }
+
+  const last = sample(history);
+  console.log(formatSample(last));
+
+  // The first day fills the history and warms the caches
+  const baseline = samples.find((s) => s.day >= 1) ?? samples[0];
+  const growthMb = last.heapMb - baseline.heapMb;
+  const handleGrowth = last.handles - baseline.handles;
+  const elapsed = (RealDate.now() - startedAt) / 1000;
+
+  console.log(`\n${device.state.frames} frames in ${elapsed.toFixed(0)}s`);
+  console.log(`Heap growth since day ${baseline.day.toFixed(0)}: ${growthMb.toFixed(1)} MB (limit ${maxGrowthMb} MB)`);
+  console.log(`Handle growth since day ${baseline.day.toFixed(0)}: ${handleGrowth}`);
+
+  if (growthMb > maxGrowthMb) {
+    console.error("FAIL: heap kept growing");
+    process.exit(1);
+  }
+  console.log("OK");
+}
+
+soak().catch((error) => {
+  console.error(error);
+  process.exit(1);
+});
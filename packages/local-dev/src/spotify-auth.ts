@@ -0,0 +1,101 @@
+/**
+ * Get a Spotify refresh token for the now-playing widget
+ *
+ * Runs the authorization code flow once, against a callback on this
+ * machine, and prints the refresh token to store as a secret. Add
+ * http://127.0.0.1:8888/callback as a redirect URI in your Spotify app
+ * first.
+ *
+ * Usage:
+ *   SPOTIFY_CLIENT_ID=... SPOTIFY_CLIENT_SECRET=... pnpm spotify-auth
+ *
+ * Then:
+ *   pnpm sst secret set SpotifyClientId <client id>
+ *   pnpm sst secret set SpotifyClientSecret <client secret>
+ *   pnpm sst secret set SpotifyRefreshToken <printed token>
+ */
+
+import { createServer } from "node:http";
+import { randomBytes } from "node:crypto";
+import { SPOTIFY_SCOPE } from "@signage/functions/spotify";
+
+const PORT = 8888;
+const REDIRECT_URI = `http://127.0.0.1:${PORT}/callback`;
+const AUTHORIZE_URL = "https://accounts.spotify.com/authorize";
+const TOKEN_URL = "https://accounts.spotify.com/api/token";
+
+const clientId = process.env.SPOTIFY_CLIENT_ID;
+const clientSecret = process.env.SPOTIFY_CLIENT_SECRET;
+if (!clientId || !clientSecret) {
+  console.error("Set SPOTIFY_CLIENT_ID and SPOTIFY_CLIENT_SECRET to your Spotify app's credentials");
+  process.exit(1);
+}
+
+const state = randomBytes(16).toString("hex");
+const authorizeUrl = new URL(AUTHORIZE_URL);
+authorizeUrl.searchParams.set("client_id", clientId);
+authorizeUrl.searchParams.set("response_type", "code");
+authorizeUrl.searchParams.set("redirect_uri", REDIRECT_URI);
+authorizeUrl.searchParams.set("scope", SPOTIFY_SCOPE);
+authorizeUrl.searchParams.set("state", state);
+
+/**
+ * Exchange the authorization code for tokens and return the refresh token
+ */
+async function exchangeCode(code: string): Promise<string> {
+  const response = await fetch(TOKEN_URL, {
+    method: "POST",
+    headers: {
+      Authorization: `Basic ${Buffer.from(`${clientId}:${clientSecret}`).toString("base64")}`,
+      "Content-Type": "application/x-www-form-urlencoded",
+    },
+    body: new URLSearchParams({ grant_type: "authorization_code", code, redirect_uri: REDIRECT_URI }).toString(),
+  });
+  if (!response.ok) {
+    throw new Error(`Token exchange failed: ${response.status} ${await response.text()}`);
+  }
+  const data = (await response.json()) as { refresh_token: string };
+  return data.refresh_token;
+}
+
+const server = createServer((req, res) => {
+  const url = new URL(req.url ?? "/", REDIRECT_URI);
+  if (url.pathname !== "/callback") {
+    res.writeHead(404).end();
+    return;
+  }
+
+  const finish = (status: number, message: string) => {
+    res.writeHead(status, { "Content-Type": "text/plain" }).end(message);
+    server.close();
+  };
+
+  if (url.searchParams.get("state") !== state) {
+    finish(400, "State mismatch - start again.");
+    process.exitCode = 1;
+    return;
+  }
+  const code = url.searchParams.get("code");
+  if (!code) {
+    finish(400, `Authorization failed: ${url.searchParams.get("error") ?? "no code"}`);
+    process.exitCode = 1;
+    return;
+  }
+
+  exchangeCode(code).then(
+    (refreshToken) => {
+      finish(200, "Done - you can close this window.");
+      console.log(`\nRefresh token:\n\n${refreshToken}\n`);
+      console.log("Store it with: pnpm sst secret set SpotifyRefreshToken <token>");
+    },
+    (error: unknown) => {
+      finish(500, "Token exchange failed - see the terminal.");
+      console.error(error instanceof Error ? error.message : error);
+      process.exitCode = 1;
+    }
+  );
+});
+
+server.listen(PORT, "127.0.0.1", () => {
+  console.log(`Open this URL and allow access:\n\n${authorizeUrl}\n`);
+});
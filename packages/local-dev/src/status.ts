@@ -0,0 +1,41 @@
+/**
+ * Print a one-screen status summary from the deployed stack
+ *
+ * Usage:
+ *   pnpm status --url https://api.signage.example.com
+ *   SIGNAGE_API_URL=https://api.signage.example.com pnpm status
+ *
+ * Options:
+ *   --url <url>   API base URL (default: $SIGNAGE_API_URL)
+ *   --json        Print the raw JSON summary instead
+ */
+
+import { parseArgs } from "node:util";
+
+const { values } = parseArgs({
+  options: {
+    url: { type: "string" },
+    json: { type: "boolean", default: false },
+  },
+});
+
+const baseUrl = values.url ?? process.env.SIGNAGE_API_URL;
+if (!baseUrl) {
+  console.error("Set --url or SIGNAGE_API_URL to the API base URL");
+  process.exit(1);
+}
+
+const url = `${baseUrl.replace(/\/+$/, "")}/status${values.json ? "" : "?format=text"}`;
+
+try {
+  const response = await fetch(url);
+  if (!response.ok) {
+    console.error(`Status request failed: ${response.status} ${await response.text()}`);
+    process.exit(1);
+  }
+  const body = await response.text();
+  console.log(values.json ? JSON.stringify(JSON.parse(body), null, 2) : body.trimEnd());
+} catch (error) {
+  console.error(`Could not reach ${url}: ${error instanceof Error ? error.message : String(error)}`);
+  process.exit(1);
+}
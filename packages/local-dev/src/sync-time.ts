@@ -0,0 +1,68 @@
+/**
+ * Set a Pixoo's clock and timezone from this machine
+ *
+ * The Pixoo's own clock (used by its built-in clock channels) drifts.
+ * This reads the device time, reports the drift, and corrects the clock
+ * when it is off by more than CLOCK_DRIFT_THRESHOLD_MS. The timezone is
+ * always set, since the device only stores a fixed offset.
+ *
+ * Usage:
+ *   pnpm sync-time 192.168.1.50
+ *   pnpm sync-time 192.168.1.50 --tz America/Los_Angeles --force
+ *
+ * Options:
+ *   --tz <zone>   IANA timezone (default: this machine's)
+ *   --force       Set the clock even when it is within the threshold
+ */
+
+import { parseArgs } from "node:util";
+import {
+  createPixooGetTimeCommand,
+  createPixooSetUtcCommand,
+  createPixooTimezoneCommand,
+  createPixooClient,
+  planClockSync,
+  type PixooDeviceTime,
+} from "@signage/core";
+
+const { values, positionals } = parseArgs({
+  allowPositionals: true,
+  options: {
+    tz: { type: "string" },
+    force: { type: "boolean", default: false },
+  },
+});
+
+const device = positionals[0];
+if (!device) {
+  console.error("Usage: pnpm sync-time <device-ip> [--tz <zone>] [--force]");
+  process.exit(1);
+}
+
+const timeZone = values.tz ?? Intl.DateTimeFormat().resolvedOptions().timeZone;
+const client = createPixooClient(device);
+
+try {
+  const timezoneCommand = createPixooTimezoneCommand(timeZone);
+  const deviceTime = await client.sendCommand<PixooDeviceTime>(createPixooGetTimeCommand());
+  const now = Date.now();
+  const plan = planClockSync(deviceTime, now);
+  if (!plan) {
+    throw new Error("device returned an unreadable time");
+  }
+
+  const drift = `${plan.driftMs > 0 ? "+" : ""}${(plan.driftMs / 1000).toFixed(1)}s`;
+  const command = plan.command ?? (values.force ? createPixooSetUtcCommand(now) : null);
+  if (command) {
+    await client.sendCommand(command);
+    console.log(`Clock was ${drift} off, corrected`);
+  } else {
+    console.log(`Clock is ${drift} off, within threshold`);
+  }
+
+  await client.sendCommand(timezoneCommand);
+  console.log(`Timezone set to ${timezoneCommand.TimeZoneValue} (${timeZone})`);
+} catch (error) {
+  console.error(`Could not sync ${device}: ${error instanceof Error ? error.message : String(error)}`);
+  process.exit(1);
+}
@@ -0,0 +1,113 @@
+/**
+ * List widgets, turn them on or off, or change their settings
+ *
+ * Usage:
+ *   pnpm widget list --url https://api.signage.example.com
+ *   SIGNAGE_API_URL=https://api.signage.example.com pnpm widget disable clock
+ *   pnpm widget enable clock
+ *   pnpm widget set bloodsugar units=mmol showTrend=true
+ *   pnpm widget reset bloodsugar                  # remove its settings
+ *
+ * Changes apply from the widget's next scheduled update.
+ *
+ * Options:
+ *   --url <url>          API base URL (default: $SIGNAGE_API_URL)
+ */
+
+import { parseArgs } from "node:util";
+
+const COMMANDS = ["list", "enable", "disable", "set", "reset"];
+
+const { values, positionals } = parseArgs({
+  allowPositionals: true,
+  options: {
+    url: { type: "string" },
+  },
+});
+
+const [command = "list", widgetId, ...pairs] = positionals;
+if (!COMMANDS.includes(command)) {
+  console.error(`Unknown command "${command}"; use one of: ${COMMANDS.join(", ")}`);
+  process.exit(1);
+}
+if (command !== "list" && !widgetId) {
+  console.error(`Give a widget, e.g. pnpm widget ${command} clock`);
+  process.exit(1);
+}
+if (command === "set" && pairs.length === 0) {
+  console.error("Give settings as key=value, e.g. pnpm widget set bloodsugar units=mmol");
+  process.exit(1);
+}
+
+const baseUrl = values.url ?? process.env.SIGNAGE_API_URL;
+if (!baseUrl) {
+  console.error("Set --url or SIGNAGE_API_URL to the API base URL");
+  process.exit(1);
+}
+
+const url = `${baseUrl.replace(/\/+$/, "")}/widgets`;
+
+/**
+ * Turn "units=mmol" arguments into settings; numbers and true/false are
+ * sent as such
+ */
+function parseSettings(args: string[]): Record<string, unknown> {
+  const settings: Record<string, unknown> = {};
+  for (const pair of args) {
+    const split = pair.indexOf("=");
+    if (split <= 0) {
+      console.error(`Settings need key=value, got "${pair}"`);
+      process.exit(1);
+    }
+    const raw = pair.slice(split + 1);
+    settings[pair.slice(0, split)] =
+      raw === "true" ? true : raw === "false" ? false : raw !== "" && Number.isFinite(Number(raw)) ? Number(raw) : raw;
+  }
+  return settings;
+}
+
+function request(): RequestInit {
+  if (command === "list") return { method: "GET" };
+  return {
+    method: "POST",
+    body: JSON.stringify({
+      widgetId,
+      ...(command === "enable" || command === "disable" ? { enabled: command === "enable" } : {}),
+      ...(command === "set" ? { settings: parseSettings(pairs) } : {}),
+      ...(command === "reset" ? { settings: null } : {}),
+    }),
+  };
+}
+
+interface WidgetSummary {
+  widgetId: string;
+  name?: string;
+  schedule?: string;
+  enabled: boolean;
+  settings: Record<string, unknown>;
+}
+
+function formatWidget(widget: WidgetSummary): string {
+  const settings = Object.keys(widget.settings).length > 0 ? `  ${JSON.stringify(widget.settings)}` : "";
+  const schedule = widget.schedule ? `  ${widget.schedule}` : "";
+  return `${widget.widgetId.padEnd(14)} ${widget.enabled ? "on " : "off"}${schedule}${settings}`;
+}
+
+try {
+  const response = await fetch(url, { ...request(), headers: { "Content-Type": "application/json" } });
+  const body = (await response.json()) as Record<string, unknown>;
+  if (!response.ok) {
+    console.error(`Widget ${command} failed: ${response.status} ${body.error ?? ""}`);
+    process.exit(1);
+  }
+  if (command === "list") {
+    for (const widget of body.widgets as WidgetSummary[]) {
+      console.log(formatWidget(widget));
+    }
+  } else {
+    console.log(formatWidget(body as unknown as WidgetSummary));
+  }
+} catch (error) {
+  console.error(`Could not reach ${url}: ${error instanceof Error ? error.message : String(error)}`);
+  process.exit(1);
+}
@@ -17,7 +17,8 @@ export function App() {
       <h1 style={{ marginBottom: "20px", fontSize: "24px", fontWeight: 300 }}>
         Signage Emulator
       </h1>
-      <PixelDisplay width={64} height={64} frame={frame} pixelSize={8} />
+      {/* Grayed while reconnecting, so an old frame can't pass for a current one */}
+      <PixelDisplay width={64} height={64} frame={frame} pixelSize={8} dimmed={status !== "connected"} />
       <p style={{ marginTop: "20px", fontSize: "14px", color, opacity: 0.9 }}>
         {text}
       </p>
@@ -107,6 +107,16 @@ describe("PixelDisplay", () => {
     expect(mockCanvasContext.drawImage).toHaveBeenCalled();
   });
 
+  it("draws a dimmed frame in gray", () => {
+    const frame = new Uint8Array(64 * 64 * 3).fill(0);
+    frame.set([0, 255, 0]);
+
+    render(<PixelDisplay width={64} height={64} frame={frame} pixelSize={8} dimmed />);
+
+    const imageData = mockCanvasContext.createImageData.mock.results[0].value;
+    expect(Array.from(imageData.data.slice(0, 4))).toEqual([60, 60, 60, 255]);
+  });
+
   it("handles different pixel sizes", () => {
     const { container } = render(
       <PixelDisplay width={32} height={32} frame={null} pixelSize={4} />
@@ -5,8 +5,13 @@ interface PixelDisplayProps {
   height: number;
   frame: Uint8Array | null;
   pixelSize?: number;
+  /** Show the frame in dim gray, e.g. while it may be out of date */
+  dimmed?: boolean;
 }
 
+/** Brightness of a dimmed frame */
+const DIMMED_LEVEL = 0.4;
+
 /**
  * Canvas-based pixel display component
  * Renders a grid of pixels from RGB frame data
@@ -16,6 +21,7 @@ export function PixelDisplay({
   height,
   frame,
   pixelSize = 8,
+  dimmed = false,
 }: PixelDisplayProps) {
   const canvasRef = useRef<HTMLCanvasElement>(null);
 
@@ -37,9 +43,15 @@ export function PixelDisplay({
     for (let i = 0; i < width * height; i++) {
       const srcOffset = i * 3;
       const dstOffset = i * 4;
-      imageData.data[dstOffset] = frame[srcOffset]; // R
-      imageData.data[dstOffset + 1] = frame[srcOffset + 1]; // G
-      imageData.data[dstOffset + 2] = frame[srcOffset + 2]; // B
+      if (dimmed) {
+        const [r, g, b] = frame.subarray(srcOffset, srcOffset + 3);
+        const gray = Math.round((0.3 * r + 0.59 * g + 0.11 * b) * DIMMED_LEVEL);
+        imageData.data.fill(gray, dstOffset, dstOffset + 3);
+      } else {
+        imageData.data[dstOffset] = frame[srcOffset]; // R
+        imageData.data[dstOffset + 1] = frame[srcOffset + 1]; // G
+        imageData.data[dstOffset + 2] = frame[srcOffset + 2]; // B
+      }
       imageData.data[dstOffset + 3] = 255; // A
     }
 
@@ -68,7 +80,7 @@ export function PixelDisplay({
       ctx.lineTo(canvas.width, y * pixelSize);
       ctx.stroke();
     }
-  }, [width, height, frame, pixelSize]);
+  }, [width, height, frame, pixelSize, dimmed]);
 
   return (
     <canvas
@@ -146,6 +146,77 @@ describe("useWebSocket", () => {
     expect(result.current.frame?.[0]).toBe(255); // Red channel
   });
 
+  it("plays transition frames before the final frame", () => {
+    const { result } = renderHook(() => useWebSocket("wss://test.example.com"));
+
+    act(() => {
+      getLastInstance().simulateOpen();
+    });
+
+    const encode = (bytes: number[]) => btoa(String.fromCharCode(...bytes));
+
+    act(() => {
+      getLastInstance().simulateMessage({
+        type: "frame",
+        payload: {
+          frame: { width: 1, height: 1, data: encode([30, 30, 30]) },
+          animation: { frames: [encode([10, 10, 10]), encode([20, 20, 20])], frameDelayMs: 100 },
+        },
+        timestamp: Date.now(),
+      });
+    });
+
+    act(() => {
+      vi.advanceTimersByTime(0);
+    });
+    expect(result.current.frame?.[0]).toBe(10);
+
+    act(() => {
+      vi.advanceTimersByTime(100);
+    });
+    expect(result.current.frame?.[0]).toBe(20);
+
+    act(() => {
+      vi.advanceTimersByTime(100);
+    });
+    expect(result.current.frame?.[0]).toBe(30);
+  });
+
+  it("applies clock ticks to the frame in turn", () => {
+    const { result } = renderHook(() => useWebSocket("wss://test.example.com"));
+
+    act(() => {
+      getLastInstance().simulateOpen();
+    });
+
+    const encode = (bytes: number[]) => btoa(String.fromCharCode(...bytes));
+
+    act(() => {
+      getLastInstance().simulateMessage({
+        type: "frame",
+        payload: {
+          frame: { width: 2, height: 1, data: encode([1, 1, 1, 2, 2, 2]) },
+          ticks: [
+            { delayMs: 1000, x: 1, y: 0, width: 1, height: 1, data: encode([3, 3, 3]) },
+            { delayMs: 2000, x: 0, y: 0, width: 1, height: 1, data: encode([4, 4, 4]) },
+          ],
+        },
+        timestamp: Date.now(),
+      });
+    });
+    expect(Array.from(result.current.frame ?? [])).toEqual([1, 1, 1, 2, 2, 2]);
+
+    act(() => {
+      vi.advanceTimersByTime(1000);
+    });
+    expect(Array.from(result.current.frame ?? [])).toEqual([1, 1, 1, 3, 3, 3]);
+
+    act(() => {
+      vi.advanceTimersByTime(1000);
+    });
+    expect(Array.from(result.current.frame ?? [])).toEqual([4, 4, 4, 3, 3, 3]);
+  });
+
   it("responds with pong when receiving ping", () => {
     renderHook(() => useWebSocket("wss://test.example.com"));
 
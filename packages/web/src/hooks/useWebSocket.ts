@@ -12,6 +12,23 @@ interface FramePayload {
     height: number;
     data: string; // Base64
   };
+  /** Optional transition frames to play before `frame` */
+  animation?: {
+    frames: string[]; // Base64
+    frameDelayMs: number;
+  };
+  /** Optional seconds clock ticks, each patching the frame before it */
+  ticks?: FramePatch[];
+}
+
+/** Changed rectangle of a clock tick, applied `delayMs` after the message arrives */
+interface FramePatch {
+  delayMs: number;
+  x: number;
+  y: number;
+  width: number;
+  height: number;
+  data: string; // Base64, just the rectangle
 }
 
 export type ConnectionStatus = "connected" | "connecting" | "disconnected";
@@ -29,6 +46,18 @@ function calculateBackoff(attempt: number): number {
   return Math.min(delay, MAX_BACKOFF_MS);
 }
 
+/**
+ * Copy of a frame with a patch's rectangle written in
+ */
+function applyPatch(frame: Uint8Array, frameWidth: number, patch: FramePatch, pixels: Uint8Array): Uint8Array {
+  const next = frame.slice();
+  for (let row = 0; row < patch.height; row++) {
+    const src = pixels.subarray(row * patch.width * 3, (row + 1) * patch.width * 3);
+    next.set(src, ((patch.y + row) * frameWidth + patch.x) * 3);
+  }
+  return next;
+}
+
 /**
  * WebSocket hook for connecting to the signage API
  * Features automatic reconnection with exponential backoff
@@ -40,6 +69,12 @@ export function useWebSocket(url: string | undefined) {
   const reconnectAttemptRef = useRef(0);
   const reconnectTimeoutRef = useRef<ReturnType<typeof setTimeout> | null>(null);
   const intentionalCloseRef = useRef(false);
+  const animationTimeoutsRef = useRef<ReturnType<typeof setTimeout>[]>([]);
+
+  const clearAnimation = useCallback(() => {
+    animationTimeoutsRef.current.forEach(clearTimeout);
+    animationTimeoutsRef.current = [];
+  }, []);
 
   const decodeBase64 = useCallback((base64: string): Uint8Array => {
     const binary = atob(base64);
@@ -89,7 +124,30 @@ export function useWebSocket(url: string | undefined) {
         if (message.type === "frame") {
           const payload = message.payload as FramePayload;
           const pixels = decodeBase64(payload.frame.data);
-          setFrame(pixels);
+          clearAnimation();
+
+          // Play transition frames first, then land on the final frame
+          const steps = payload.animation?.frames.map(decodeBase64) ?? [];
+          const delay = payload.animation?.frameDelayMs ?? 0;
+          if (steps.length === 0) {
+            setFrame(pixels);
+          } else {
+            [...steps, pixels].forEach((step, i) => {
+              animationTimeoutsRef.current.push(setTimeout(() => setFrame(step), i * delay));
+            });
+          }
+
+          // Clock ticks patch the final frame in turn, never before it lands
+          let current = pixels;
+          for (const patch of payload.ticks ?? []) {
+            const tickAt = Math.max(patch.delayMs, steps.length * delay);
+            animationTimeoutsRef.current.push(
+              setTimeout(() => {
+                current = applyPatch(current, payload.frame.width, patch, decodeBase64(patch.data));
+                setFrame(current);
+              }, tickAt)
+            );
+          }
         } else if (message.type === "ping") {
           const pong: WsMessage = {
             type: "pong",
@@ -126,7 +184,7 @@ export function useWebSocket(url: string | undefined) {
     ws.onerror = (error) => {
       console.error("WebSocket error:", error);
     };
-  }, [url, decodeBase64]);
+  }, [url, decodeBase64, clearAnimation]);
 
   // Initial connection and cleanup
   useEffect(() => {
@@ -135,6 +193,7 @@ export function useWebSocket(url: string | undefined) {
 
     return () => {
       intentionalCloseRef.current = true;
+      clearAnimation();
       if (reconnectTimeoutRef.current) {
         clearTimeout(reconnectTimeoutRef.current);
       }
@@ -142,7 +201,7 @@ export function useWebSocket(url: string | undefined) {
         wsRef.current.close();
       }
     };
-  }, [connect]);
+  }, [connect, clearAnimation]);
 
   // Reconnect on visibility change (tab focus)
   useEffect(() => {
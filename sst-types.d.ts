@@ -5,6 +5,14 @@
 
 declare module "sst" {
   export interface Resource {
+    ApiToken: {
+      type: "sst.sst.Secret";
+      value: string;
+    };
+    DexcomFollowers: {
+      type: "sst.sst.Secret";
+      value: string;
+    };
     DexcomPassword: {
       type: "sst.sst.Secret";
       value: string;
@@ -13,6 +21,18 @@ declare module "sst" {
       type: "sst.sst.Secret";
       value: string;
     };
+    MqttUrl: {
+      type: "sst.sst.Secret";
+      value: string;
+    };
+    NightscoutApiSecret: {
+      type: "sst.sst.Secret";
+      value: string;
+    };
+    NightscoutUrl: {
+      type: "sst.sst.Secret";
+      value: string;
+    };
     SignageApi: {
       managementEndpoint: string;
       type: "sst.aws.ApiGatewayWebSocket";
@@ -30,6 +50,18 @@ declare module "sst" {
       type: "sst.aws.StaticSite";
       url: string;
     };
+    SpotifyClientId: {
+      type: "sst.sst.Secret";
+      value: string;
+    };
+    SpotifyClientSecret: {
+      type: "sst.sst.Secret";
+      value: string;
+    };
+    SpotifyRefreshToken: {
+      type: "sst.sst.Secret";
+      value: string;
+    };
   }
   export const Resource: Resource;
 }